@@ -0,0 +1,130 @@
+package anomaly
+
+import (
+	"math"
+	"sort"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+)
+
+// madScale makes the median absolute deviation comparable to a standard deviation for normal data
+const madScale = 1.4826
+
+// hoursPerDay is the number of seasonal buckets of a baseline
+const hoursPerDay = 24
+
+// Detector scores values against the rolling statistics of their series.
+type Detector struct {
+	config config.AnomalyConfig
+}
+
+// NewDetector creates a new Detector.
+//
+// Parameters:
+// - config: the threshold, smoothing factor and sample sizes of the detection.
+//
+// Returns a pointer to a Detector.
+func NewDetector(config config.AnomalyConfig) *Detector {
+	return &Detector{config: config}
+}
+
+// Score returns an event for each method the value is anomalous under.
+//
+// The baseline must not include the measurement yet, baselines with fewer than
+// MinSamples values do not score anything.
+func (d *Detector) Score(b *domain.AnomalyBaseline, m domain.Measurement) []domain.AnomalyEvent {
+	if b.Count < d.config.MinSamples {
+		return nil
+	}
+	var events []domain.AnomalyEvent
+	add := func(method string, expected, deviation float64) {
+		if deviation <= 0 {
+			return
+		}
+		score := math.Abs(m.Value-expected) / deviation
+		if score <= d.config.Threshold {
+			return
+		}
+		events = append(events, domain.AnomalyEvent{
+			MeasurementID: m.ID,
+			ReadingID:     m.ReadingID,
+			DeviceID:      m.DeviceID,
+			Parameter:     m.Parameter,
+			Timestamp:     m.Timestamp,
+			Value:         m.Value,
+			Method:        method,
+			Score:         score,
+			Expected:      expected,
+		})
+	}
+
+	add(domain.AnomalyEWMA, b.EWMA, math.Sqrt(b.EWMVar))
+
+	center, mad := medianAndMAD(b.Window)
+	add(domain.AnomalyMAD, center, madScale*mad)
+
+	if len(b.Hourly) == hoursPerDay {
+		hour := b.Hourly[m.Timestamp.UTC().Hour()]
+		if hour.Count >= d.config.MinSeasonalSamples && hour.Count > 1 {
+			add(domain.AnomalySeasonal, hour.Mean, math.Sqrt(hour.M2/float64(hour.Count-1)))
+		}
+	}
+	return events
+}
+
+// Update adds the value of the measurement to the baseline.
+func (d *Detector) Update(b *domain.AnomalyBaseline, m domain.Measurement) {
+	if b.Count == 0 {
+		b.EWMA = m.Value
+		b.EWMVar = 0
+	} else {
+		diff := m.Value - b.EWMA
+		b.EWMA += d.config.Alpha * diff
+		b.EWMVar = (1 - d.config.Alpha) * (b.EWMVar + d.config.Alpha*diff*diff)
+	}
+	b.Count++
+
+	b.Window = append(b.Window, m.Value)
+	if d.config.WindowSize > 0 && len(b.Window) > d.config.WindowSize {
+		b.Window = b.Window[len(b.Window)-d.config.WindowSize:]
+	}
+
+	if len(b.Hourly) != hoursPerDay {
+		b.Hourly = make([]domain.HourlyStat, hoursPerDay)
+	}
+	// Welford's online mean and variance
+	hour := &b.Hourly[m.Timestamp.UTC().Hour()]
+	hour.Count++
+	delta := m.Value - hour.Mean
+	hour.Mean += delta / float64(hour.Count)
+	hour.M2 += delta * (m.Value - hour.Mean)
+
+	if m.Timestamp.After(b.UpdatedAt) {
+		b.UpdatedAt = m.Timestamp
+	}
+}
+
+// medianAndMAD returns the median of the values and their median absolute deviation.
+func medianAndMAD(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	center := median(values)
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - center)
+	}
+	return center, median(deviations)
+}
+
+// median returns the median of the values without modifying them.
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
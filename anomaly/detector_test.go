@@ -0,0 +1,69 @@
+package anomaly_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/anomaly"
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+var detectorConfig = config.AnomalyConfig{
+	Threshold:          5,
+	Alpha:              0.1,
+	WindowSize:         50,
+	MinSamples:         20,
+	MinSeasonalSamples: 3,
+}
+
+// baseline builds a baseline of COD values oscillating around 100 with one value per hour
+func baseline(d *anomaly.Detector, start time.Time, n int) *domain.AnomalyBaseline {
+	b := &domain.AnomalyBaseline{Parameter: domain.ParamCOD}
+	for i := 0; i < n; i++ {
+		d.Update(b, domain.Measurement{
+			Parameter: domain.ParamCOD,
+			Timestamp: start.Add(time.Duration(i) * time.Hour),
+			Value:     100 + float64(i%5) - 2,
+		})
+	}
+	return b
+}
+
+func TestDetectorScore(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := anomaly.NewDetector(detectorConfig)
+
+	t.Run("Not enough samples", func(t *testing.T) {
+		b := baseline(d, start, 10)
+		events := d.Score(b, domain.Measurement{Parameter: domain.ParamCOD, Timestamp: start, Value: 1000})
+		assert.Empty(t, events)
+	})
+	t.Run("Normal value", func(t *testing.T) {
+		b := baseline(d, start, 24*4)
+		events := d.Score(b, domain.Measurement{Parameter: domain.ParamCOD, Timestamp: start.Add(96 * time.Hour), Value: 101})
+		assert.Empty(t, events)
+	})
+	t.Run("Legal but abnormal value", func(t *testing.T) {
+		b := baseline(d, start, 24*4)
+		events := d.Score(b, domain.Measurement{Parameter: domain.ParamCOD, Timestamp: start.Add(96 * time.Hour), Value: 130})
+		methods := make([]string, 0, len(events))
+		for _, event := range events {
+			methods = append(methods, event.Method)
+			assert.Greater(t, event.Score, detectorConfig.Threshold)
+		}
+		assert.ElementsMatch(t, []string{domain.AnomalyEWMA, domain.AnomalyMAD, domain.AnomalySeasonal}, methods)
+	})
+}
+
+func TestDetectorUpdate(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := anomaly.NewDetector(detectorConfig)
+	b := baseline(d, start, 100)
+	assert.Equal(t, 100, b.Count)
+	assert.Len(t, b.Window, detectorConfig.WindowSize)
+	assert.Len(t, b.Hourly, 24)
+	assert.InDelta(t, 100, b.EWMA, 2)
+	assert.Equal(t, start.Add(99*time.Hour), b.UpdatedAt)
+}
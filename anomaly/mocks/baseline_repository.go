@@ -0,0 +1,79 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BaselineRepositoryInterface is an autogenerated mock type for the BaselineRepositoryInterface type
+type BaselineRepositoryInterface struct {
+	mock.Mock
+}
+
+// GetBaseline provides a mock function with given fields: ctx, deviceID, parameter
+func (_m *BaselineRepositoryInterface) GetBaseline(ctx context.Context, deviceID primitive.ObjectID, parameter string) (*domain.AnomalyBaseline, error) {
+	ret := _m.Called(ctx, deviceID, parameter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBaseline")
+	}
+
+	var r0 *domain.AnomalyBaseline
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID, string) (*domain.AnomalyBaseline, error)); ok {
+		return rf(ctx, deviceID, parameter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID, string) *domain.AnomalyBaseline); ok {
+		r0 = rf(ctx, deviceID, parameter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.AnomalyBaseline)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, primitive.ObjectID, string) error); ok {
+		r1 = rf(ctx, deviceID, parameter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveBaseline provides a mock function with given fields: ctx, b, expectedVersion
+func (_m *BaselineRepositoryInterface) SaveBaseline(ctx context.Context, b *domain.AnomalyBaseline, expectedVersion int64) error {
+	ret := _m.Called(ctx, b, expectedVersion)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveBaseline")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AnomalyBaseline, int64) error); ok {
+		r0 = rf(ctx, b, expectedVersion)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewBaselineRepositoryInterface creates a new instance of BaselineRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewBaselineRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *BaselineRepositoryInterface {
+	mock := &BaselineRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
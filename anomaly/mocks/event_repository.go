@@ -0,0 +1,77 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// EventRepositoryInterface is an autogenerated mock type for the EventRepositoryInterface type
+type EventRepositoryInterface struct {
+	mock.Mock
+}
+
+// CreateMany provides a mock function with given fields: ctx, events
+func (_m *EventRepositoryInterface) CreateMany(ctx context.Context, events []domain.AnomalyEvent) error {
+	ret := _m.Called(ctx, events)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateMany")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.AnomalyEvent) error); ok {
+		r0 = rf(ctx, events)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAll provides a mock function with given fields: ctx, filter, page, limit
+func (_m *EventRepositoryInterface) GetAll(ctx context.Context, filter domain.AnomalyFilter, page int, limit int) ([]domain.AnomalyEvent, error) {
+	ret := _m.Called(ctx, filter, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAll")
+	}
+
+	var r0 []domain.AnomalyEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.AnomalyFilter, int, int) ([]domain.AnomalyEvent, error)); ok {
+		return rf(ctx, filter, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.AnomalyFilter, int, int) []domain.AnomalyEvent); ok {
+		r0 = rf(ctx, filter, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.AnomalyEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.AnomalyFilter, int, int) error); ok {
+		r1 = rf(ctx, filter, page, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewEventRepositoryInterface creates a new instance of EventRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewEventRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *EventRepositoryInterface {
+	mock := &EventRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
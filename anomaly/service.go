@@ -0,0 +1,191 @@
+package anomaly
+
+import (
+	"context"
+	"errors"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+)
+
+// tracer starts the spans of the service methods
+var tracer = otel.Tracer("github.com/anggi-susanto/mrt-go/anomaly")
+
+// maxBaselineRetries bounds the attempts to update a baseline changed concurrently
+const maxBaselineRetries = 5
+
+// BaselineRepositoryInterface is the interface that wraps the GetBaseline and SaveBaseline methods.
+type BaselineRepositoryInterface interface {
+	GetBaseline(ctx context.Context, deviceID primitive.ObjectID, parameter string) (*domain.AnomalyBaseline, error)
+	SaveBaseline(ctx context.Context, b *domain.AnomalyBaseline, expectedVersion int64) error
+}
+
+// EventRepositoryInterface is the interface that wraps the CreateMany and GetAll methods.
+type EventRepositoryInterface interface {
+	CreateMany(ctx context.Context, events []domain.AnomalyEvent) error
+	GetAll(ctx context.Context, filter domain.AnomalyFilter, page, limit int) ([]domain.AnomalyEvent, error)
+}
+
+// Service maintains the per device, per parameter baselines and the anomaly events scored against them.
+type Service struct {
+	baselineRepository BaselineRepositoryInterface
+	eventRepository    EventRepositoryInterface
+	detector           *Detector
+}
+
+// NewService creates a new instance of the Service struct, initializing it with the provided dependencies.
+//
+// Parameters:
+// - baselineRepository: The BaselineRepositoryInterface implementation storing the rolling statistics.
+// - eventRepository: The EventRepositoryInterface implementation storing the anomaly events.
+// - config: the detection threshold, smoothing factor and sample sizes.
+//
+// Returns:
+// - A pointer to the newly created Service instance.
+func NewService(baselineRepository BaselineRepositoryInterface, eventRepository EventRepositoryInterface, config config.AnomalyConfig) *Service {
+	return &Service{
+		baselineRepository: baselineRepository,
+		eventRepository:    eventRepository,
+		detector:           NewDetector(config),
+	}
+}
+
+// baselineKey identifies the series of a baseline
+type baselineKey struct {
+	deviceID  primitive.ObjectID
+	parameter string
+}
+
+// Detect scores the measurements against their baselines.
+//
+// The methods a measurement is anomalous under are set in its Anomalies, the
+// events are returned to be recorded once the measurements are stored. The
+// stored baselines are left as they are, each measurement is scored against
+// them and the measurements of the batch before it.
+//
+// ctx - context.Context for the operation.
+// measurements - the measurements to score, measurements without device or taken during maintenance are skipped.
+// Returns the anomaly events and an error.
+func (s *Service) Detect(ctx context.Context, measurements []domain.Measurement) ([]domain.AnomalyEvent, error) {
+	ctx, span := tracer.Start(ctx, "anomaly.Service.Detect")
+	defer span.End()
+	baselines := map[baselineKey]*domain.AnomalyBaseline{}
+	var events []domain.AnomalyEvent
+
+	for i := range measurements {
+		m := &measurements[i]
+		m.Anomalies = nil
+		if m.DeviceID.IsZero() || m.Maintenance {
+			continue
+		}
+		key := baselineKey{deviceID: m.DeviceID, parameter: m.Parameter}
+		b, ok := baselines[key]
+		if !ok {
+			var err error
+			if b, err = s.baseline(ctx, key); err != nil {
+				return nil, err
+			}
+			baselines[key] = b
+		}
+
+		scored := s.detector.Score(b, *m)
+		for _, event := range scored {
+			m.Anomalies = append(m.Anomalies, event.Method)
+		}
+		events = append(events, scored...)
+		if learns(*m) {
+			s.detector.Update(b, *m)
+		}
+	}
+	return events, nil
+}
+
+// Record stores the anomaly events and adds the measurements to their baselines.
+//
+// It is called once the measurements are stored, so that measurements failing
+// to be stored do not move the baselines. Suspect measurements are left out of
+// the baselines. A baseline changed concurrently is read again and updated
+// from its new version.
+//
+// ctx - context.Context for the operation.
+// measurements - the stored measurements, as scored by Detect.
+// events - the events returned by Detect.
+// Returns an error if there was a problem storing the events or the baselines.
+func (s *Service) Record(ctx context.Context, measurements []domain.Measurement, events []domain.AnomalyEvent) error {
+	ctx, span := tracer.Start(ctx, "anomaly.Service.Record")
+	defer span.End()
+	if len(events) > 0 {
+		if err := s.eventRepository.CreateMany(ctx, events); err != nil {
+			return err
+		}
+	}
+
+	series := map[baselineKey][]domain.Measurement{}
+	var order []baselineKey
+	for _, m := range measurements {
+		if !learns(m) {
+			continue
+		}
+		key := baselineKey{deviceID: m.DeviceID, parameter: m.Parameter}
+		if _, ok := series[key]; !ok {
+			order = append(order, key)
+		}
+		series[key] = append(series[key], m)
+	}
+	for _, key := range order {
+		if err := s.updateBaseline(ctx, key, series[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateBaseline adds the measurements to the baseline of their series, retrying on version conflicts.
+func (s *Service) updateBaseline(ctx context.Context, key baselineKey, measurements []domain.Measurement) error {
+	for attempt := 1; ; attempt++ {
+		b, err := s.baseline(ctx, key)
+		if err != nil {
+			return err
+		}
+		expectedVersion := b.Version
+		for _, m := range measurements {
+			s.detector.Update(b, m)
+		}
+		b.Version++
+		err = s.baselineRepository.SaveBaseline(ctx, b, expectedVersion)
+		if errors.Is(err, domain.ErrBaselineVersion) && attempt < maxBaselineRetries {
+			continue
+		}
+		return err
+	}
+}
+
+// baseline retrieves the baseline of a series, an empty one if it has none yet.
+func (s *Service) baseline(ctx context.Context, key baselineKey) (*domain.AnomalyBaseline, error) {
+	b, err := s.baselineRepository.GetBaseline(ctx, key.deviceID, key.parameter)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		b = &domain.AnomalyBaseline{DeviceID: key.deviceID, Parameter: key.parameter}
+	}
+	return b, nil
+}
+
+// learns reports whether a measurement is added to the baseline of its series, suspect values
+// would teach it the faults of the sensor.
+func learns(m domain.Measurement) bool {
+	return !m.DeviceID.IsZero() && !m.Maintenance && m.Quality != domain.QualitySuspect
+}
+
+// GetAll retrieves the anomaly events matching the filter, newest first.
+//
+// ctx context.Context, filter domain.AnomalyFilter, page int, limit int
+// []domain.AnomalyEvent, error
+func (s *Service) GetAll(ctx context.Context, filter domain.AnomalyFilter, page, limit int) ([]domain.AnomalyEvent, error) {
+	ctx, span := tracer.Start(ctx, "anomaly.Service.GetAll")
+	defer span.End()
+	return s.eventRepository.GetAll(ctx, filter, page, limit)
+}
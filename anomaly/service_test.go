@@ -0,0 +1,127 @@
+package anomaly_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/anomaly"
+	"github.com/anggi-susanto/mrt-go/anomaly/mocks"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestServiceDetect(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	stored := baseline(anomaly.NewDetector(detectorConfig), start, 24*4)
+	stored.DeviceID = deviceID
+
+	t.Run("Anomalous value", func(t *testing.T) {
+		mockBaselineRepo := new(mocks.BaselineRepositoryInterface)
+		mockBaselineRepo.On("GetBaseline", mock.Anything, deviceID, domain.ParamCOD).Return(stored, nil).Once()
+		s := anomaly.NewService(mockBaselineRepo, new(mocks.EventRepositoryInterface), detectorConfig)
+		measurements := []domain.Measurement{
+			{ID: primitive.NewObjectID(), DeviceID: deviceID, Parameter: domain.ParamCOD, Timestamp: start.Add(96 * time.Hour), Value: 130},
+			{ID: primitive.NewObjectID(), DeviceID: deviceID, Parameter: domain.ParamCOD, Timestamp: start.Add(97 * time.Hour), Value: 99},
+		}
+		events, err := s.Detect(context.Background(), measurements)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, events)
+		assert.Equal(t, measurements[0].ID, events[0].MeasurementID)
+		assert.Contains(t, measurements[0].Anomalies, domain.AnomalyMAD)
+		assert.Empty(t, measurements[1].Anomalies)
+		mockBaselineRepo.AssertExpectations(t)
+		mockBaselineRepo.AssertNotCalled(t, "SaveBaseline")
+	})
+	t.Run("New series", func(t *testing.T) {
+		mockBaselineRepo := new(mocks.BaselineRepositoryInterface)
+		mockBaselineRepo.On("GetBaseline", mock.Anything, deviceID, domain.ParamPH).Return(nil, nil)
+		s := anomaly.NewService(mockBaselineRepo, new(mocks.EventRepositoryInterface), detectorConfig)
+		events, err := s.Detect(context.Background(), []domain.Measurement{{DeviceID: deviceID, Parameter: domain.ParamPH, Value: 7}})
+		assert.NoError(t, err)
+		assert.Empty(t, events)
+		mockBaselineRepo.AssertNotCalled(t, "SaveBaseline")
+	})
+	t.Run("Maintenance", func(t *testing.T) {
+		s := anomaly.NewService(new(mocks.BaselineRepositoryInterface), new(mocks.EventRepositoryInterface), detectorConfig)
+		measurements := []domain.Measurement{{DeviceID: deviceID, Parameter: domain.ParamCOD, Value: 1000, Maintenance: true, Anomalies: []string{domain.AnomalyMAD}}}
+		events, err := s.Detect(context.Background(), measurements)
+		assert.NoError(t, err)
+		assert.Empty(t, events)
+		assert.Empty(t, measurements[0].Anomalies)
+	})
+	t.Run("Error", func(t *testing.T) {
+		mockBaselineRepo := new(mocks.BaselineRepositoryInterface)
+		mockBaselineRepo.On("GetBaseline", mock.Anything, deviceID, domain.ParamPH).Return(nil, errors.New("error"))
+		s := anomaly.NewService(mockBaselineRepo, new(mocks.EventRepositoryInterface), detectorConfig)
+		_, err := s.Detect(context.Background(), []domain.Measurement{{DeviceID: deviceID, Parameter: domain.ParamPH, Value: 7}})
+		assert.Error(t, err)
+	})
+}
+
+func TestServiceRecord(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	measurements := []domain.Measurement{
+		{DeviceID: deviceID, Parameter: domain.ParamCOD, Value: 90, Quality: domain.QualityRaw},
+		{DeviceID: deviceID, Parameter: domain.ParamCOD, Value: 5000, Quality: domain.QualitySuspect},
+		{DeviceID: deviceID, Parameter: domain.ParamCOD, Value: 1000, Maintenance: true},
+		{Parameter: domain.ParamCOD, Value: 80},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		events := []domain.AnomalyEvent{{Parameter: domain.ParamCOD}}
+		mockBaselineRepo := new(mocks.BaselineRepositoryInterface)
+		mockEventRepo := new(mocks.EventRepositoryInterface)
+		mockEventRepo.On("CreateMany", mock.Anything, events).Return(nil).Once()
+		mockBaselineRepo.On("GetBaseline", mock.Anything, deviceID, domain.ParamCOD).Return(&domain.AnomalyBaseline{DeviceID: deviceID, Parameter: domain.ParamCOD, Count: 3, EWMA: 90, Version: 7}, nil).Once()
+		mockBaselineRepo.On("SaveBaseline", mock.Anything, mock.MatchedBy(func(b *domain.AnomalyBaseline) bool {
+			// Only the raw value is learned
+			return b.Count == 4 && b.Version == 8 && assert.ObjectsAreEqual([]float64{90}, b.Window)
+		}), int64(7)).Return(nil).Once()
+		s := anomaly.NewService(mockBaselineRepo, mockEventRepo, detectorConfig)
+		assert.NoError(t, s.Record(context.Background(), measurements, events))
+		mockEventRepo.AssertExpectations(t)
+		mockBaselineRepo.AssertExpectations(t)
+	})
+	t.Run("Version conflict", func(t *testing.T) {
+		mockBaselineRepo := new(mocks.BaselineRepositoryInterface)
+		mockBaselineRepo.On("GetBaseline", mock.Anything, deviceID, domain.ParamCOD).Return(nil, nil).Once()
+		mockBaselineRepo.On("SaveBaseline", mock.Anything, mock.Anything, int64(0)).Return(domain.ErrBaselineVersion).Once()
+		mockBaselineRepo.On("GetBaseline", mock.Anything, deviceID, domain.ParamCOD).Return(&domain.AnomalyBaseline{DeviceID: deviceID, Parameter: domain.ParamCOD, Count: 1, EWMA: 85, Version: 1}, nil).Once()
+		mockBaselineRepo.On("SaveBaseline", mock.Anything, mock.MatchedBy(func(b *domain.AnomalyBaseline) bool {
+			return b.Count == 2 && b.Version == 2
+		}), int64(1)).Return(nil).Once()
+		s := anomaly.NewService(mockBaselineRepo, new(mocks.EventRepositoryInterface), detectorConfig)
+		assert.NoError(t, s.Record(context.Background(), measurements, nil))
+		mockBaselineRepo.AssertExpectations(t)
+	})
+	t.Run("Retries exhausted", func(t *testing.T) {
+		mockBaselineRepo := new(mocks.BaselineRepositoryInterface)
+		mockBaselineRepo.On("GetBaseline", mock.Anything, deviceID, domain.ParamCOD).Return(nil, nil)
+		mockBaselineRepo.On("SaveBaseline", mock.Anything, mock.Anything, int64(0)).Return(domain.ErrBaselineVersion)
+		s := anomaly.NewService(mockBaselineRepo, new(mocks.EventRepositoryInterface), detectorConfig)
+		assert.ErrorIs(t, s.Record(context.Background(), measurements, nil), domain.ErrBaselineVersion)
+	})
+	t.Run("Event error", func(t *testing.T) {
+		mockBaselineRepo := new(mocks.BaselineRepositoryInterface)
+		mockEventRepo := new(mocks.EventRepositoryInterface)
+		mockEventRepo.On("CreateMany", mock.Anything, mock.Anything).Return(errors.New("error"))
+		s := anomaly.NewService(mockBaselineRepo, mockEventRepo, detectorConfig)
+		assert.Error(t, s.Record(context.Background(), measurements, []domain.AnomalyEvent{{Parameter: domain.ParamCOD}}))
+		mockBaselineRepo.AssertNotCalled(t, "SaveBaseline")
+	})
+}
+
+func TestServiceGetAll(t *testing.T) {
+	filter := domain.AnomalyFilter{Parameter: domain.ParamCOD}
+	mockEventRepo := new(mocks.EventRepositoryInterface)
+	mockEventRepo.On("GetAll", mock.Anything, filter, 1, 10).Return([]domain.AnomalyEvent{{Parameter: domain.ParamCOD}}, nil)
+	s := anomaly.NewService(new(mocks.BaselineRepositoryInterface), mockEventRepo, detectorConfig)
+	events, err := s.GetAll(context.Background(), filter, 1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+}
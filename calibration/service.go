@@ -0,0 +1,101 @@
+package calibration
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+)
+
+// tracer starts the spans of the service methods
+var tracer = otel.Tracer("github.com/anggi-susanto/mrt-go/calibration")
+
+// CalibrationRepositoryInterface is the interface that wraps the calibration storage methods.
+type CalibrationRepositoryInterface interface {
+	Create(ctx context.Context, c *domain.Calibration) error
+	GetBySensor(ctx context.Context, sensorID primitive.ObjectID, parameter string) ([]domain.Calibration, error)
+	GetOverdue(ctx context.Context, at time.Time, page, limit int) ([]domain.Calibration, error)
+}
+
+// SensorRepositoryInterface is the interface that wraps the GetByID method.
+type SensorRepositoryInterface interface {
+	GetByID(ctx context.Context, id string) (*domain.Sensor, error)
+}
+
+// Service is the interface that wraps the calibration records of the sensors.
+type Service struct {
+	calibrationRepository CalibrationRepositoryInterface
+	sensorRepository      SensorRepositoryInterface
+}
+
+// NewService creates a new instance of the Service struct, initializing it with the provided repositories.
+//
+// Parameters:
+// - calibrationRepository: The CalibrationRepositoryInterface implementation used by the Service.
+// - sensorRepository: The SensorRepositoryInterface implementation used to check the calibrated sensor exists.
+//
+// Returns:
+// - A pointer to the newly created Service instance.
+func NewService(calibrationRepository CalibrationRepositoryInterface, sensorRepository SensorRepositoryInterface) *Service {
+	return &Service{
+		calibrationRepository: calibrationRepository,
+		sensorRepository:      sensorRepository,
+	}
+}
+
+// Create records a calibration of a sensor.
+//
+// The calibration applies to the values the sensor reports from CalibratedAt
+// onwards, until the next calibration of the same parameter.
+//
+// ctx - context.Context for the operation.
+// c - the calibration to record.
+// Returns an error if the calibration is invalid, the sensor does not exist or there was a problem storing it.
+func (s *Service) Create(ctx context.Context, c *domain.Calibration) error {
+	ctx, span := tracer.Start(ctx, "calibration.Service.Create")
+	defer span.End()
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	sensor, err := s.sensorRepository.GetByID(ctx, c.SensorID.Hex())
+	if err != nil {
+		return err
+	}
+	if sensor == nil {
+		return fmt.Errorf("%w: sensor %s not found", domain.ErrInvalidCalibration, c.SensorID.Hex())
+	}
+	c.CreatedAt = domain.MyTime{Time: time.Now()}
+	return s.calibrationRepository.Create(ctx, c)
+}
+
+// GetHistory retrieves the calibrations of a sensor, latest first.
+//
+// ctx - context.Context for the operation.
+// sensorID - string representing the ID of the sensor.
+// parameter - the parameter code to narrow the history to, empty for every parameter.
+// Returns the calibrations and an error.
+func (s *Service) GetHistory(ctx context.Context, sensorID, parameter string) ([]domain.Calibration, error) {
+	ctx, span := tracer.Start(ctx, "calibration.Service.GetHistory")
+	defer span.End()
+	id, err := primitive.ObjectIDFromHex(sensorID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidCalibration, err)
+	}
+	return s.calibrationRepository.GetBySensor(ctx, id, parameter)
+}
+
+// GetOverdue retrieves the latest calibration of each sensor parameter whose next calibration was due before the given time.
+//
+// ctx - context.Context for the operation.
+// at - the reference time, usually now.
+// page - the page number for pagination.
+// limit - the maximum number of items to return per page.
+// Returns the overdue calibrations, most overdue first, and an error.
+func (s *Service) GetOverdue(ctx context.Context, at time.Time, page, limit int) ([]domain.Calibration, error) {
+	ctx, span := tracer.Start(ctx, "calibration.Service.GetOverdue")
+	defer span.End()
+	return s.calibrationRepository.GetOverdue(ctx, at, page, limit)
+}
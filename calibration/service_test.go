@@ -0,0 +1,116 @@
+package calibration_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/calibration"
+	"github.com/anggi-susanto/mrt-go/calibration/mocks"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestServiceCreate(t *testing.T) {
+	sensorID := primitive.NewObjectID()
+	calibratedAt := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	t.Run("Success", func(t *testing.T) {
+		c := domain.Calibration{
+			SensorID:     sensorID,
+			Parameter:    domain.ParamPH,
+			CalibratedAt: calibratedAt,
+			Technician:   "tech",
+			Standards:    []domain.ReferenceStandard{{Name: "pH 4", Value: 4}, {Name: "pH 7", Value: 7}},
+			Points:       []domain.CalibrationPoint{{Raw: 7.1, Reference: 7}, {Raw: 4.2, Reference: 4}},
+			NextDueAt:    calibratedAt.Add(30 * 24 * time.Hour),
+		}
+		mockRepo := new(mocks.CalibrationRepositoryInterface)
+		mockRepo.On("Create", mock.Anything, &c).Return(nil)
+		mockSensorRepo := new(mocks.SensorRepositoryInterface)
+		mockSensorRepo.On("GetByID", mock.Anything, sensorID.Hex()).Return(&domain.Sensor{ID: sensorID}, nil)
+		err := calibration.NewService(mockRepo, mockSensorRepo).Create(context.Background(), &c)
+		assert.NoError(t, err)
+		assert.Equal(t, 4.2, c.Points[0].Raw)
+		assert.False(t, c.CreatedAt.IsZero())
+		mockRepo.AssertExpectations(t)
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		for name, c := range map[string]domain.Calibration{
+			"No sensor":     {Parameter: domain.ParamPH, CalibratedAt: calibratedAt, Slope: 1},
+			"No parameter":  {SensorID: sensorID, CalibratedAt: calibratedAt, Slope: 1},
+			"No date":       {SensorID: sensorID, Parameter: domain.ParamPH, Slope: 1},
+			"No correction": {SensorID: sensorID, Parameter: domain.ParamPH, CalibratedAt: calibratedAt},
+			"One point":     {SensorID: sensorID, Parameter: domain.ParamPH, CalibratedAt: calibratedAt, Points: []domain.CalibrationPoint{{Raw: 7, Reference: 7}}},
+			"Duplicate raw": {SensorID: sensorID, Parameter: domain.ParamPH, CalibratedAt: calibratedAt, Points: []domain.CalibrationPoint{{Raw: 7, Reference: 7}, {Raw: 7, Reference: 4}}},
+			"Due before":    {SensorID: sensorID, Parameter: domain.ParamPH, CalibratedAt: calibratedAt, Slope: 1, NextDueAt: calibratedAt.Add(-time.Hour)},
+		} {
+			t.Run(name, func(t *testing.T) {
+				s := calibration.NewService(new(mocks.CalibrationRepositoryInterface), new(mocks.SensorRepositoryInterface))
+				assert.ErrorIs(t, s.Create(context.Background(), &c), domain.ErrInvalidCalibration)
+			})
+		}
+	})
+	t.Run("Unknown sensor", func(t *testing.T) {
+		c := domain.Calibration{SensorID: sensorID, Parameter: domain.ParamPH, CalibratedAt: calibratedAt, Slope: 1}
+		mockSensorRepo := new(mocks.SensorRepositoryInterface)
+		mockSensorRepo.On("GetByID", mock.Anything, sensorID.Hex()).Return(nil, nil)
+		s := calibration.NewService(new(mocks.CalibrationRepositoryInterface), mockSensorRepo)
+		assert.ErrorIs(t, s.Create(context.Background(), &c), domain.ErrInvalidCalibration)
+	})
+	t.Run("Error", func(t *testing.T) {
+		c := domain.Calibration{SensorID: sensorID, Parameter: domain.ParamPH, CalibratedAt: calibratedAt, Slope: 1}
+		mockRepo := new(mocks.CalibrationRepositoryInterface)
+		mockRepo.On("Create", mock.Anything, &c).Return(errors.New("error"))
+		mockSensorRepo := new(mocks.SensorRepositoryInterface)
+		mockSensorRepo.On("GetByID", mock.Anything, sensorID.Hex()).Return(&domain.Sensor{ID: sensorID}, nil)
+		assert.Error(t, calibration.NewService(mockRepo, mockSensorRepo).Create(context.Background(), &c))
+	})
+}
+
+func TestServiceGetHistory(t *testing.T) {
+	sensorID := primitive.NewObjectID()
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.CalibrationRepositoryInterface)
+		mockRepo.On("GetBySensor", mock.Anything, sensorID, domain.ParamPH).Return([]domain.Calibration{{SensorID: sensorID}}, nil)
+		calibrations, err := calibration.NewService(mockRepo, nil).GetHistory(context.Background(), sensorID.Hex(), domain.ParamPH)
+		assert.NoError(t, err)
+		assert.Len(t, calibrations, 1)
+	})
+	t.Run("Invalid ID", func(t *testing.T) {
+		_, err := calibration.NewService(nil, nil).GetHistory(context.Background(), "1", "")
+		assert.ErrorIs(t, err, domain.ErrInvalidCalibration)
+	})
+}
+
+func TestServiceGetOverdue(t *testing.T) {
+	at := time.Now()
+	mockRepo := new(mocks.CalibrationRepositoryInterface)
+	mockRepo.On("GetOverdue", mock.Anything, at, 1, 10).Return([]domain.Calibration{{Parameter: domain.ParamPH}}, nil)
+	calibrations, err := calibration.NewService(mockRepo, nil).GetOverdue(context.Background(), at, 1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, calibrations, 1)
+}
+
+func TestCalibrationApply(t *testing.T) {
+	t.Run("Offset and slope", func(t *testing.T) {
+		c := domain.Calibration{Slope: 1.02, Offset: -0.1}
+		assert.InDelta(t, 7.04, c.Apply(7), 1e-9)
+	})
+	t.Run("Curve", func(t *testing.T) {
+		c := domain.Calibration{Points: []domain.CalibrationPoint{{Raw: 4.2, Reference: 4}, {Raw: 7.1, Reference: 7}, {Raw: 10.3, Reference: 10}}}
+		assert.InDelta(t, 7, c.Apply(7.1), 1e-9)
+		assert.InDelta(t, 8.5, c.Apply(8.7), 1e-9)
+		// outside the curve the end segments are extrapolated
+		assert.InDelta(t, 3, c.Apply(4.2-2.9/3), 1e-9)
+		assert.InDelta(t, 11.5, c.Apply(11.9), 1e-9)
+	})
+	t.Run("Active", func(t *testing.T) {
+		at := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+		calibrations := []domain.Calibration{{Offset: 2, CalibratedAt: at.Add(time.Hour)}, {Offset: 1, CalibratedAt: at.Add(-time.Hour)}}
+		assert.Equal(t, 1.0, domain.ActiveCalibration(calibrations, at).Offset)
+		assert.Nil(t, domain.ActiveCalibration(calibrations, at.Add(-2*time.Hour)))
+	})
+}
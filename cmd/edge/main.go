@@ -0,0 +1,88 @@
+// Command edge is the store-and-forward agent of the depots.
+//
+// It collects readings locally, keeps them in an on-disk queue and forwards them to the central
+// API in batches once the link is up.
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/internal/edge"
+	"github.com/anggi-susanto/mrt-go/internal/metrics"
+	"github.com/anggi-susanto/mrt-go/internal/mqtt"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func main() {
+	hostname, _ := os.Hostname()
+	edgeConfig := config.EdgeConfig{
+		BatchSize:      500,
+		FlushInterval:  10 * time.Second,
+		MinBackoff:     time.Second,
+		MaxBackoff:     5 * time.Minute,
+		MaxSkew:        2 * time.Second,
+		RequestTimeout: 30 * time.Second,
+	}
+	flag.StringVar(&edgeConfig.APIURL, "api", "http://localhost:3000", "base URL of the central API")
+	flag.StringVar(&edgeConfig.AgentID, "agent", hostname, "ID of the agent, prefixes the dedup keys")
+	flag.StringVar(&edgeConfig.Username, "username", "", "username of the device credentials, its ID")
+	flag.StringVar(&edgeConfig.Password, "password", "", "password of the device credentials")
+	flag.StringVar(&edgeConfig.QueuePath, "queue", "edge-queue.db", "file of the on-disk queue")
+	flag.IntVar(&edgeConfig.MaxQueued, "max-queued", 1000000, "readings kept while offline, 0 keeps all")
+	flag.StringVar(&edgeConfig.MetricsAddress, "metrics", "", "address /metrics is served on, empty disables it")
+	deviceID := flag.String("device", "", "device ID the simulated readings are attributed to")
+	simulate := flag.Duration("simulate", 0, "interval of the simulated readings, 0 disables the simulator")
+	spool := flag.String("spool", "", "directory of the *.jsonl reading files, empty disables it")
+	broker := flag.String("mqtt", "", "address of the local MQTT broker, empty disables it")
+	topic := flag.String("mqtt-topic", "readings", "topic the local loggers publish their readings to")
+	flag.Parse()
+
+	queue, err := edge.OpenQueue(edgeConfig.QueuePath, edgeConfig.AgentID, edgeConfig.MaxQueued)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	defer queue.Close()
+
+	if edgeConfig.MetricsAddress != "" {
+		agentMetrics := metrics.New(config.MetricsConfig{})
+		agentMetrics.AddQueue("edge", func(context.Context) (int, error) {
+			return queue.Len(), nil
+		})
+		mux := http.NewServeMux()
+		mux.Handle(metrics.Endpoint, agentMetrics.Handler())
+		go func() {
+			logrus.Error(http.ListenAndServe(edgeConfig.MetricsAddress, mux))
+		}()
+	}
+
+	var sources []edge.Source
+	if *spool != "" {
+		sources = append(sources, edge.NewSpoolSource(*spool, 5*time.Second))
+	}
+	if *simulate > 0 {
+		device, err := primitive.ObjectIDFromHex(*deviceID)
+		if err != nil {
+			logrus.Fatalf("invalid device ID %q: %v", *deviceID, err)
+		}
+		sources = append(sources, edge.NewSimulatorSource(device, *simulate))
+	}
+	if *broker != "" {
+		local := mqtt.NewBroker(config.MQTTConfig{Broker: *broker, ClientID: "edge-" + edgeConfig.AgentID, QoS: 1})
+		edge.NewReadingHandler(local, *topic, queue)
+		local.Connect()
+		defer local.Disconnect()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	logrus.Infof("edge agent %s forwarding to %s, %d readings queued", edgeConfig.AgentID, edgeConfig.APIURL, queue.Len())
+	edge.NewAgent(queue, edge.NewForwarder(queue, edgeConfig), sources...).Run(ctx)
+}
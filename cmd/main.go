@@ -2,22 +2,60 @@ package main
 
 import (
 	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/gofiber/swagger"
 
+	"github.com/anggi-susanto/mrt-go/anomaly"
+	"github.com/anggi-susanto/mrt-go/calibration"
+	"github.com/anggi-susanto/mrt-go/command"
 	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/credential"
+	"github.com/anggi-susanto/mrt-go/device"
+	"github.com/anggi-susanto/mrt-go/diagnostics"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/ingest"
+	"github.com/anggi-susanto/mrt-go/labsample"
+	"github.com/anggi-susanto/mrt-go/lorawan"
+	"github.com/anggi-susanto/mrt-go/maintenance"
+	"github.com/anggi-susanto/mrt-go/measurement"
+	"github.com/anggi-susanto/mrt-go/polling"
+	"github.com/anggi-susanto/mrt-go/provisioning"
+	"github.com/anggi-susanto/mrt-go/psk"
+	"github.com/anggi-susanto/mrt-go/quality"
+	"github.com/anggi-susanto/mrt-go/sensor"
+	"github.com/anggi-susanto/mrt-go/sensortype"
+	"github.com/anggi-susanto/mrt-go/shadow"
+	"github.com/anggi-susanto/mrt-go/site"
+	"github.com/anggi-susanto/mrt-go/units"
+	"github.com/anggi-susanto/mrt-go/wastewater"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/sirupsen/logrus"
 
 	_ "github.com/anggi-susanto/mrt-go/docs"
+	"github.com/anggi-susanto/mrt-go/internal/coap"
+	"github.com/anggi-susanto/mrt-go/internal/graphql"
+	"github.com/anggi-susanto/mrt-go/internal/grpc"
+	"github.com/anggi-susanto/mrt-go/internal/health"
+	"github.com/anggi-susanto/mrt-go/internal/logging"
+	"github.com/anggi-susanto/mrt-go/internal/metrics"
+	"github.com/anggi-susanto/mrt-go/internal/modbus"
+	"github.com/anggi-susanto/mrt-go/internal/mqtt"
 	mongoRepo "github.com/anggi-susanto/mrt-go/internal/repository/mongo"
 	"github.com/anggi-susanto/mrt-go/internal/rest"
+	"github.com/anggi-susanto/mrt-go/internal/tracing"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// shutdownTimeout bounds the wait for the running requests when the API stops
+const shutdownTimeout = 30 * time.Second
+
 // @title MRT Waste Water API
 // @version 1.0
 // @description This is an API Document for MRT Waste Water
@@ -27,18 +65,134 @@ import (
 // @license.url https://www.gnu.org/licenses/gpl-3.0.html
 // @host localhost:3000
 // @BasePath /
+// @securityDefinitions.basic DeviceAuth
 func main() {
 	config := config.Config{
 		MongoConfig: config.MongoConfig{
-			Uri:                  "mongodb://localhost:27017",
-			Database:             "mrt",
-			WasteWaterCollection: "waste_water",
-			DeviceCollection:     "devices",
-			SensorCollection:     "sensors",
+			Uri:                     "mongodb://localhost:27017",
+			Database:                "mrt",
+			WasteWaterCollection:    "waste_water",
+			DeviceCollection:        "devices",
+			SensorCollection:        "sensors",
+			SiteCollection:          "sites",
+			SensorTypeCollection:    "sensor_types",
+			MeasurementCollection:   "measurements",
+			QualityReviewCollection: "quality_reviews",
+			AnomalyCollection:       "anomalies",
+			BaselineCollection:      "anomaly_baselines",
+			LabSampleCollection:     "lab_samples",
+			CalibrationCollection:   "calibrations",
+			MaintenanceCollection:   "work_orders",
+			ShadowCollection:        "device_shadows",
+			CommandCollection:       "device_commands",
+			ClaimCollection:         "provisioning_claims",
+			TemplateCollection:      "device_templates",
+			CredentialCollection:    "device_credentials",
+			IngestKeyCollection:     "ingest_keys",
+			CodecCollection:         "payload_codecs",
+			UplinkCollection:        "lorawan_uplinks",
+			IngestMappingCollection: "ingest_mappings",
+			PSKCollection:           "device_psks",
+			MigrationCollection:     "migrations",
+			IngestKeyRetention:      30 * 24 * time.Hour,
+		},
+		IngestionConfig: config.IngestionConfig{
+			RangeMode: domain.RangeModeFlag,
+		},
+		QualityConfig: config.QualityConfig{
+			HistorySize:   10,
+			SpikeFactor:   4,
+			FlatlineCount: 6,
+			MaxRateOfChange: map[string]float64{
+				domain.ParamTemperature:     2,
+				domain.ParamPH:              0.5,
+				domain.ParamDissolvedOxygen: 2,
+			},
+		},
+		AnomalyConfig: config.AnomalyConfig{
+			Threshold:          5,
+			Alpha:              0.1,
+			WindowSize:         100,
+			MinSamples:         30,
+			MinSeasonalSamples: 7,
+		},
+		DiagnosticsConfig: config.DiagnosticsConfig{
+			SampleSize:    50,
+			FlatlineCount: 12,
+			ZeroCount:     6,
+			MaxJump: map[string]float64{
+				domain.ParamPH:          2,
+				domain.ParamTemperature: 10,
+			},
+			DriftThreshold: 0.2,
+		},
+		MQTTConfig: config.MQTTConfig{
+			Broker:      "tcp://localhost:1883",
+			ClientID:    "mrt-api",
+			Username:    "mrt-api",
+			Password:    "mrt-api-secret",
+			TopicPrefix: "mrt/devices",
+			QoS:         1,
+			Timeout:     5 * time.Second,
+		},
+		CommandConfig: config.CommandConfig{
+			DefaultTTL:     time.Hour,
+			DefaultTimeout: 10 * time.Minute,
+			SweepInterval:  30 * time.Second,
+		},
+		ProvisioningConfig: config.ProvisioningConfig{
+			Broker:      "tcp://localhost:1883",
+			TopicPrefix: "mrt/devices",
+		},
+		ModbusConfig: config.ModbusConfig{
+			PollInterval:  time.Minute,
+			SweepInterval: 5 * time.Second,
+			Timeout:       3 * time.Second,
+		},
+		CoAPConfig: config.CoAPConfig{
+			DTLSAddress: ":5684",
+			Timeout:     5 * time.Second,
+			IdleTimeout: 10 * time.Minute,
+		},
+		GRPCConfig: config.GRPCConfig{
+			Address: ":50051",
+		},
+		MetricsConfig: config.MetricsConfig{
+			ParameterValues: false,
+		},
+		TracingConfig: config.TracingConfig{
+			Exporter:    tracing.ExporterOTLP,
+			Endpoint:    "localhost:4317",
+			Insecure:    true,
+			ServiceName: "mrt-api",
+			SampleRatio: 1,
+		},
+		LoggingConfig: config.LoggingConfig{
+			Level:            "info",
+			RequestIDHeader:  fiber.HeaderXRequestID,
+			TenantHeader:     "X-Tenant-ID",
+			UserHeader:       "X-User-ID",
+			SampleTick:       time.Second,
+			SampleFirst:      20,
+			SampleThereafter: 100,
 		},
+		HealthConfig: config.HealthConfig{
+			Timeout:     2 * time.Second,
+			WorkerStall: 5 * time.Minute,
+		},
+	}
+
+	if err := logging.Setup(config.LoggingConfig); err != nil {
+		logrus.Fatal(err)
 	}
 
-	mongoClient, err := mongo.Connect(context.Background(), options.Client().ApplyURI(config.MongoConfig.Uri))
+	shutdownTracing, err := tracing.Setup(context.Background(), config.TracingConfig)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	apiMetrics := metrics.New(config.MetricsConfig)
+	monitor := mongoRepo.Monitors(apiMetrics.CommandMonitor(), tracing.CommandMonitor())
+	mongoClient, err := mongo.Connect(context.Background(), options.Client().ApplyURI(config.MongoConfig.Uri).SetMonitor(monitor))
 	if err != nil {
 		logrus.Fatal(err)
 	}
@@ -48,30 +202,279 @@ func main() {
 		logrus.Fatal(err)
 	}
 
-	defer func() {
-		if err = mongoClient.Disconnect(context.Background()); err != nil {
-			logrus.Fatal(err)
-		}
-	}()
+	// SIGINT and SIGTERM stop the REST API and the background workers, the servers and connections
+	// are then closed at the end
+	signals, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	var workers sync.WaitGroup
 
 	// Start the server
 	app := fiber.New()
-	app.Use(logger.New())
+	// The access log comes first, it answers the errors the handlers return after the others saw them
+	app.Use(logging.Middleware(config.LoggingConfig))
+	app.Use(tracing.Middleware())
+	app.Use(apiMetrics.Middleware())
 	app.Use(cors.New())
 	app.Get("/docs/*", swagger.HandlerDefault)
 	app.Get("/", func(c *fiber.Ctx) error {
 		return c.SendString("MRT API is UP and RUNNING!")
 	})
+	metrics.NewHandler(app, apiMetrics)
+	// The probes check MongoDB, the MQTT broker and the background workers registered below
+	apiHealth := health.New(config.HealthConfig)
+	apiHealth.AddReadiness("mongo", func(ctx context.Context) error {
+		return mongoClient.Ping(ctx, nil)
+	})
+	health.NewHandler(app, apiHealth)
 
-	wasteWaterRepo := mongoRepo.NewWasteWaterRepository(mongoClient, &config.MongoConfig)
-	rest.NewWasteWaterHandler(app, wasteWaterRepo)
+	sensorRepo := mongoRepo.NewSensorRepository(mongoClient, &config.MongoConfig)
 
 	deviceRepo := mongoRepo.NewDeviceRepository(mongoClient, &config.MongoConfig)
-	rest.NewDeviceHandler(app, deviceRepo)
+	if err = deviceRepo.EnsureIndexes(context.Background()); err != nil {
+		logrus.Fatal(err)
+	}
 
-	sensorRepo := mongoRepo.NewSensorRepository(mongoClient, &config.MongoConfig)
-	rest.NewSensorHandler(app, sensorRepo)
+	// The devices authenticate with the credentials issued when they were provisioned, on the
+	// broker and on the REST ingest routes
+	credentialRepo := mongoRepo.NewCredentialRepository(mongoClient, &config.MongoConfig)
+	if err = credentialRepo.EnsureIndexes(context.Background()); err != nil {
+		logrus.Fatal(err)
+	}
+	credentialService := credential.NewService(credentialRepo, deviceRepo, config.MQTTConfig)
+	rest.NewMQTTAuthHandler(app, credentialService)
+	deviceAuth := rest.DeviceAuth(credentialService)
+
+	sensorTypeRepo := mongoRepo.NewSensorTypeRepository(mongoClient, &config.MongoConfig)
+	if err = sensorTypeRepo.EnsureIndexes(context.Background()); err != nil {
+		logrus.Fatal(err)
+	}
+	sensorTypeService := sensortype.NewService(sensorTypeRepo, sensorRepo)
+	if err = sensorTypeService.Seed(context.Background()); err != nil {
+		logrus.Fatal(err)
+	}
+	rest.NewSensorTypeHandler(app, sensorTypeService)
+
+	measurementRepo := mongoRepo.NewMeasurementRepository(mongoClient, &config.MongoConfig)
+	if err = measurementRepo.EnsureCollection(context.Background()); err != nil {
+		logrus.Fatal(err)
+	}
+	labSampleRepo := mongoRepo.NewLabSampleRepository(mongoClient, &config.MongoConfig)
+	if err = labSampleRepo.EnsureIndexes(context.Background()); err != nil {
+		logrus.Fatal(err)
+	}
+	labSampleService := labsample.NewService(labSampleRepo, deviceRepo, measurementRepo, units.Default)
+	diagnoser := diagnostics.NewDiagnoser(config.DiagnosticsConfig)
+	sensorService := sensor.NewService(sensorRepo, measurementRepo, labSampleService, diagnoser)
+	rest.NewSensorHandler(app, sensorService)
+
+	anomalyRepo := mongoRepo.NewAnomalyRepository(mongoClient, &config.MongoConfig)
+	if err = anomalyRepo.EnsureIndexes(context.Background()); err != nil {
+		logrus.Fatal(err)
+	}
+	anomalyService := anomaly.NewService(anomalyRepo, anomalyRepo, config.AnomalyConfig)
+	rest.NewAnomalyHandler(app, anomalyService)
+
+	calibrationRepo := mongoRepo.NewCalibrationRepository(mongoClient, &config.MongoConfig)
+	if err = calibrationRepo.EnsureIndexes(context.Background()); err != nil {
+		logrus.Fatal(err)
+	}
+	rest.NewCalibrationHandler(app, calibration.NewService(calibrationRepo, sensorRepo))
+
+	workOrderRepo := mongoRepo.NewWorkOrderRepository(mongoClient, &config.MongoConfig)
+	if err = workOrderRepo.EnsureIndexes(context.Background()); err != nil {
+		logrus.Fatal(err)
+	}
+	rest.NewMaintenanceHandler(app, maintenance.NewService(workOrderRepo, measurementRepo))
 
-	logrus.Fatal(app.Listen(":3000"))
+	qualityChecker := quality.NewChecker(config.QualityConfig)
+	// The GraphQL subscriptions and the metrics receive the readings stored by the pipeline
+	readingHub := graphql.NewHub()
+	apiMetrics.AddQueue("graphql_subscriptions", func(context.Context) (int, error) {
+		return readingHub.Pending(), nil
+	})
+	measurementService := measurement.NewService(measurementRepo, sensorTypeService, units.Default, qualityChecker, anomalyService, calibrationRepo, workOrderRepo, deviceRepo, measurement.Publishers{readingHub, apiMetrics}, config.IngestionConfig.RangeMode)
+	rest.NewMeasurementHandler(app, measurementService, units.Default, deviceAuth)
 
+	qualityReviewRepo := mongoRepo.NewQualityReviewRepository(mongoClient, &config.MongoConfig)
+	if err = qualityReviewRepo.EnsureIndexes(context.Background()); err != nil {
+		logrus.Fatal(err)
+	}
+	rest.NewQualityHandler(app, quality.NewService(measurementRepo, qualityReviewRepo))
+
+	wasteWaterRepo := mongoRepo.NewWasteWaterRepository(mongoClient, &config.MongoConfig)
+	migrated, err := wasteWaterRepo.MigrateLegacy(context.Background())
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	if migrated > 0 {
+		logrus.Infof("migrated %d waste water documents to measurements", migrated)
+	}
+	ingestKeyRepo := mongoRepo.NewIngestKeyRepository(mongoClient, &config.MongoConfig)
+	if err = ingestKeyRepo.EnsureIndexes(context.Background()); err != nil {
+		logrus.Fatal(err)
+	}
+	wasteWaterService := wastewater.NewService(wasteWaterRepo, measurementService, ingestKeyRepo)
+	rest.NewWasteWaterHandler(app, wasteWaterService, units.Default, deviceAuth)
+
+	codecRepo := mongoRepo.NewCodecRepository(mongoClient, &config.MongoConfig)
+	if err = codecRepo.EnsureIndexes(context.Background()); err != nil {
+		logrus.Fatal(err)
+	}
+	uplinkRepo := mongoRepo.NewUplinkRepository(mongoClient, &config.MongoConfig)
+	if err = uplinkRepo.EnsureIndexes(context.Background()); err != nil {
+		logrus.Fatal(err)
+	}
+	rest.NewLoRaWANHandler(app, lorawan.NewService(deviceRepo, codecRepo, uplinkRepo, wasteWaterService))
+
+	ingestMappingRepo := mongoRepo.NewIngestMappingRepository(mongoClient, &config.MongoConfig)
+	if err = ingestMappingRepo.EnsureIndexes(context.Background()); err != nil {
+		logrus.Fatal(err)
+	}
+	rest.NewIngestHandler(app, ingest.NewService(ingestMappingRepo, sensorRepo, wasteWaterService))
+
+	pollingService := polling.NewService(deviceRepo, sensorRepo, sensorTypeRepo, wasteWaterService, modbus.Dialer{Timeout: config.ModbusConfig.Timeout}, config.ModbusConfig)
+	rest.NewPollingHandler(app, pollingService)
+	// The Modbus instruments are polled on their schedule in the background
+	pollingHeartbeat := health.NewHeartbeat(config.HealthConfig.WorkerStall)
+	apiHealth.AddLiveness("modbus_polling", pollingHeartbeat.Check)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		ticker := time.NewTicker(config.ModbusConfig.SweepInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-signals.Done():
+				return
+			case now := <-ticker.C:
+				if _, err := pollingService.Sweep(signals, now); err != nil {
+					logrus.Error(err)
+				}
+				pollingHeartbeat.Beat(time.Now())
+			}
+		}
+	}()
+
+	deviceService := device.NewService(deviceRepo, wasteWaterRepo)
+	rest.NewDeviceHandler(app, deviceService)
+
+	// The gRPC API shares the services of the REST API on its own port
+	grpcServer := grpc.NewServer(config.GRPCConfig)
+	grpc.NewDeviceHandler(grpcServer, deviceService)
+	grpc.NewSensorHandler(grpcServer, sensorService)
+	grpc.NewWasteWaterHandler(grpcServer, wasteWaterService, measurementService)
+
+	broker := mqtt.NewBroker(config.MQTTConfig)
+	// Without the broker the commands, shadow deltas and MQTT readings of the devices are lost, the
+	// API gets no traffic until it reconnects
+	apiHealth.AddReadiness("mqtt", broker.Check)
+	pskRepo := mongoRepo.NewPSKRepository(mongoClient, &config.MongoConfig)
+	if err = pskRepo.EnsureIndexes(context.Background()); err != nil {
+		logrus.Fatal(err)
+	}
+	pskService := psk.NewService(pskRepo, deviceRepo)
+	rest.NewPSKHandler(app, pskService)
+	coapServer := coap.NewServer(config.CoAPConfig, pskService)
+	coap.NewReadingsHandler(coapServer, wasteWaterService)
+	shadowRepo := mongoRepo.NewShadowRepository(mongoClient, &config.MongoConfig)
+	if err = shadowRepo.EnsureIndexes(context.Background()); err != nil {
+		logrus.Fatal(err)
+	}
+	shadowService := shadow.NewService(shadowRepo, deviceRepo, shadow.Publishers{mqtt.NewDeltaPublisher(broker), coap.NewConfigPublisher(coapServer)})
+	rest.NewShadowHandler(app, shadowService)
+	mqtt.NewShadowHandler(broker, shadowService)
+	coap.NewShadowHandler(coapServer, shadowService)
+
+	commandRepo := mongoRepo.NewCommandRepository(mongoClient, &config.MongoConfig)
+	if err = commandRepo.EnsureIndexes(context.Background()); err != nil {
+		logrus.Fatal(err)
+	}
+	apiMetrics.AddQueue("device_commands", func(ctx context.Context) (int, error) {
+		return commandRepo.CountQueued(ctx, time.Now())
+	})
+	commandService := command.NewService(commandRepo, deviceRepo, mqtt.NewCommandPublisher(broker), config.CommandConfig)
+	rest.NewCommandHandler(app, commandService)
+	mqtt.NewCommandHandler(broker, commandService)
+	// Queued commands are sent again and overdue ones expired in the background
+	commandHeartbeat := health.NewHeartbeat(config.HealthConfig.WorkerStall)
+	apiHealth.AddLiveness("command_sweep", commandHeartbeat.Check)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		ticker := time.NewTicker(config.CommandConfig.SweepInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-signals.Done():
+				return
+			case now := <-ticker.C:
+				if _, err := commandService.Sweep(signals, now); err != nil {
+					logrus.Error(err)
+				}
+				commandHeartbeat.Beat(time.Now())
+			}
+		}
+	}()
+
+	claimRepo := mongoRepo.NewClaimRepository(mongoClient, &config.MongoConfig)
+	if err = claimRepo.EnsureIndexes(context.Background()); err != nil {
+		logrus.Fatal(err)
+	}
+	templateRepo := mongoRepo.NewTemplateRepository(mongoClient, &config.MongoConfig)
+	if err = templateRepo.EnsureIndexes(context.Background()); err != nil {
+		logrus.Fatal(err)
+	}
+	rest.NewProvisioningHandler(app, provisioning.NewService(claimRepo, templateRepo, deviceRepo, sensorRepo, credentialRepo, pskService, shadowService, config.ProvisioningConfig))
+
+	siteRepo := mongoRepo.NewSiteRepository(mongoClient, &config.MongoConfig)
+	if err = siteRepo.EnsureIndexes(context.Background()); err != nil {
+		logrus.Fatal(err)
+	}
+	rest.NewSiteHandler(app, site.NewService(siteRepo, deviceRepo))
+
+	schema, err := graphql.NewSchema(siteRepo, deviceRepo, sensorRepo, wasteWaterRepo, readingHub)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	graphql.NewHandler(app, schema)
+
+	rest.NewLabSampleHandler(app, labSampleService)
+
+	// The broker subscribes the handlers registered above once connected
+	broker.Connect()
+	// The devices on CoAP reach the handlers registered above
+	if err = coapServer.Start(); err != nil {
+		logrus.Fatal(err)
+	}
+	if err = grpcServer.Start(); err != nil {
+		logrus.Fatal(err)
+	}
+
+	go func() {
+		<-signals.Done()
+		logrus.Info("shutting down")
+		if err := app.ShutdownWithTimeout(shutdownTimeout); err != nil {
+			logrus.Error(err)
+		}
+	}()
+	if err = app.Listen(":3000"); err != nil {
+		logrus.Error(err)
+	}
+	// The workers also stop when the API could not listen, a running sweep is cancelled and waited for
+	stop()
+	workers.Wait()
+
+	// The servers stop before the broker and the database their handlers use
+	grpcServer.Close()
+	if err = coapServer.Close(); err != nil {
+		logrus.Error(err)
+	}
+	broker.Disconnect()
+	if err = mongoClient.Disconnect(context.Background()); err != nil {
+		logrus.Error(err)
+	}
+	// The spans of the shutdown are exported last
+	if err = shutdownTracing(context.Background()); err != nil {
+		logrus.Error(err)
+	}
 }
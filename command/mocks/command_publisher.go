@@ -0,0 +1,47 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// CommandPublisherInterface is an autogenerated mock type for the CommandPublisherInterface type
+type CommandPublisherInterface struct {
+	mock.Mock
+}
+
+// PublishCommand provides a mock function with given fields: ctx, c
+func (_m *CommandPublisherInterface) PublishCommand(ctx context.Context, c domain.Command) error {
+	ret := _m.Called(ctx, c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PublishCommand")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Command) error); ok {
+		r0 = rf(ctx, c)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewCommandPublisherInterface creates a new instance of CommandPublisherInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCommandPublisherInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CommandPublisherInterface {
+	mock := &CommandPublisherInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,185 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// CommandRepositoryInterface is an autogenerated mock type for the CommandRepositoryInterface type
+type CommandRepositoryInterface struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, c
+func (_m *CommandRepositoryInterface) Create(ctx context.Context, c *domain.Command) error {
+	ret := _m.Called(ctx, c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Command) error); ok {
+		r0 = rf(ctx, c)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ExpireOverdue provides a mock function with given fields: ctx, now
+func (_m *CommandRepositoryInterface) ExpireOverdue(ctx context.Context, now time.Time) (int64, error) {
+	ret := _m.Called(ctx, now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExpireOverdue")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int64, error)); ok {
+		return rf(ctx, now)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, now)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAll provides a mock function with given fields: ctx, filter, page, limit
+func (_m *CommandRepositoryInterface) GetAll(ctx context.Context, filter domain.CommandFilter, page int, limit int) ([]domain.Command, error) {
+	ret := _m.Called(ctx, filter, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAll")
+	}
+
+	var r0 []domain.Command
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.CommandFilter, int, int) ([]domain.Command, error)); ok {
+		return rf(ctx, filter, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.CommandFilter, int, int) []domain.Command); ok {
+		r0 = rf(ctx, filter, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Command)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.CommandFilter, int, int) error); ok {
+		r1 = rf(ctx, filter, page, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByID provides a mock function with given fields: ctx, id
+func (_m *CommandRepositoryInterface) GetByID(ctx context.Context, id string) (*domain.Command, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *domain.Command
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.Command, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.Command); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Command)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetQueued provides a mock function with given fields: ctx, now, limit
+func (_m *CommandRepositoryInterface) GetQueued(ctx context.Context, now time.Time, limit int) ([]domain.Command, error) {
+	ret := _m.Called(ctx, now, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetQueued")
+	}
+
+	var r0 []domain.Command
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) ([]domain.Command, error)); ok {
+		return rf(ctx, now, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) []domain.Command); ok {
+		r0 = rf(ctx, now, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Command)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int) error); ok {
+		r1 = rf(ctx, now, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateStatus provides a mock function with given fields: ctx, c, from
+func (_m *CommandRepositoryInterface) UpdateStatus(ctx context.Context, c *domain.Command, from []string) error {
+	ret := _m.Called(ctx, c, from)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateStatus")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Command, []string) error); ok {
+		r0 = rf(ctx, c, from)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewCommandRepositoryInterface creates a new instance of CommandRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCommandRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CommandRepositoryInterface {
+	mock := &CommandRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
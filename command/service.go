@@ -0,0 +1,223 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+)
+
+// tracer starts the spans of the service methods
+var tracer = otel.Tracer("github.com/anggi-susanto/mrt-go/command")
+
+// maxSweepCommands is the number of queued commands a sweep resends at most
+const maxSweepCommands = 100
+
+// CommandRepositoryInterface is the interface that wraps the command storage methods.
+type CommandRepositoryInterface interface {
+	Create(ctx context.Context, c *domain.Command) error
+	GetAll(ctx context.Context, filter domain.CommandFilter, page, limit int) ([]domain.Command, error)
+	GetByID(ctx context.Context, id string) (*domain.Command, error)
+	GetQueued(ctx context.Context, now time.Time, limit int) ([]domain.Command, error)
+	UpdateStatus(ctx context.Context, c *domain.Command, from []string) error
+	ExpireOverdue(ctx context.Context, now time.Time) (int64, error)
+}
+
+// DeviceRepositoryInterface is the interface that wraps the GetByID method.
+type DeviceRepositoryInterface interface {
+	GetByID(ctx context.Context, id string) (*domain.Device, error)
+}
+
+// CommandPublisherInterface is the interface that wraps the PublishCommand method.
+type CommandPublisherInterface interface {
+	PublishCommand(ctx context.Context, c domain.Command) error
+}
+
+// Service is the interface that wraps the device commands.
+type Service struct {
+	commandRepository CommandRepositoryInterface
+	deviceRepository  DeviceRepositoryInterface
+	publisher         CommandPublisherInterface
+	config            config.CommandConfig
+}
+
+// NewService creates a new instance of the Service struct, initializing it with the provided dependencies.
+//
+// Parameters:
+// - commandRepository: The CommandRepositoryInterface implementation used by the Service.
+// - deviceRepository: The DeviceRepositoryInterface implementation used to check the device exists.
+// - publisher: The CommandPublisherInterface implementation delivering the commands to the devices.
+// - config: the default TTL and result timeout of the commands.
+//
+// Returns:
+// - A pointer to the newly created Service instance.
+func NewService(commandRepository CommandRepositoryInterface, deviceRepository DeviceRepositoryInterface, publisher CommandPublisherInterface, config config.CommandConfig) *Service {
+	return &Service{
+		commandRepository: commandRepository,
+		deviceRepository:  deviceRepository,
+		publisher:         publisher,
+		config:            config,
+	}
+}
+
+// Create queues a command for a device and sends it.
+//
+// A command that cannot be sent stays queued and is sent again by the next sweep until it expires.
+//
+// ctx - context.Context for the operation.
+// deviceID - string representing the ID of the device.
+// request - the command name, parameters, TTL and timeout.
+// Returns the command, nil when the device does not exist, and an error.
+func (s *Service) Create(ctx context.Context, deviceID string, request domain.CommandRequest) (*domain.Command, error) {
+	ctx, span := tracer.Start(ctx, "command.Service.Create")
+	defer span.End()
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	device, err := s.deviceRepository.GetByID(ctx, deviceID)
+	if err != nil || device == nil {
+		return nil, err
+	}
+	if device.Status == domain.DeviceDecommissioned {
+		return nil, domain.ErrDeviceDecommissioned
+	}
+
+	now := time.Now()
+	ttl, timeout := s.config.DefaultTTL, int(s.config.DefaultTimeout.Seconds())
+	if request.TTL > 0 {
+		ttl = time.Duration(request.TTL) * time.Second
+	}
+	if request.Timeout > 0 {
+		timeout = request.Timeout
+	}
+	c := &domain.Command{
+		DeviceID:    device.ID,
+		Name:        request.Name,
+		Params:      request.Params,
+		Status:      domain.CommandQueued,
+		RequestedBy: request.RequestedBy,
+		Timeout:     timeout,
+		ExpiresAt:   now.Add(ttl),
+		CreatedAt:   domain.MyTime{Time: now},
+	}
+	if err = s.commandRepository.Create(ctx, c); err != nil {
+		return nil, err
+	}
+	// A command that could not be sent stays queued, the next sweep sends it again
+	_ = s.send(ctx, c)
+	return c, nil
+}
+
+// GetAll retrieves the command history of a device, newest first.
+//
+// ctx - context.Context for the operation.
+// deviceID - string representing the ID of the device.
+// status - only the commands in this status when not empty.
+// page - int representing the page number.
+// limit - int representing the maximum number of items per page.
+// Returns the commands, nil when the device does not exist, and an error.
+func (s *Service) GetAll(ctx context.Context, deviceID string, status string, page, limit int) ([]domain.Command, error) {
+	ctx, span := tracer.Start(ctx, "command.Service.GetAll")
+	defer span.End()
+	device, err := s.deviceRepository.GetByID(ctx, deviceID)
+	if err != nil || device == nil {
+		return nil, err
+	}
+	commands, err := s.commandRepository.GetAll(ctx, domain.CommandFilter{DeviceID: device.ID, Status: status}, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	if commands == nil {
+		commands = []domain.Command{}
+	}
+	return commands, nil
+}
+
+// GetByID retrieves a command of a device.
+//
+// ctx - context.Context for the operation.
+// deviceID - string representing the ID of the device.
+// id - string representing the ID of the command.
+// Returns the command, nil when it does not exist or belongs to another device, and an error.
+func (s *Service) GetByID(ctx context.Context, deviceID string, id string) (*domain.Command, error) {
+	ctx, span := tracer.Start(ctx, "command.Service.GetByID")
+	defer span.End()
+	c, err := s.commandRepository.GetByID(ctx, id)
+	if err != nil || c == nil {
+		return nil, err
+	}
+	if c.DeviceID.Hex() != deviceID {
+		return nil, nil
+	}
+	return c, nil
+}
+
+// Reply records the acknowledgement or the result a device reports for a command.
+//
+// ctx - context.Context for the operation.
+// deviceID - the device the reply was received from.
+// reply - the command ID, the reported status and the result.
+// Returns domain.ErrInvalidCommand when the command is unknown or belongs to another device and
+// domain.ErrCommandTransition when the command already expired or completed.
+func (s *Service) Reply(ctx context.Context, deviceID primitive.ObjectID, reply domain.CommandReply) (*domain.Command, error) {
+	ctx, span := tracer.Start(ctx, "command.Service.Reply")
+	defer span.End()
+	c, err := s.commandRepository.GetByID(ctx, reply.CommandID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	if c == nil || c.DeviceID != deviceID {
+		return nil, fmt.Errorf("%w: unknown command %s", domain.ErrInvalidCommand, reply.CommandID.Hex())
+	}
+	if err = c.Apply(reply, time.Now()); err != nil {
+		return nil, err
+	}
+	if err = s.commandRepository.UpdateStatus(ctx, c, domain.CommandSources(c.Status)); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Sweep expires the overdue commands and sends the queued ones again.
+//
+// ctx - context.Context for the operation.
+// now - the time the expiries and deadlines are checked against.
+// Returns the number of commands expired or timed out and the first error.
+func (s *Service) Sweep(ctx context.Context, now time.Time) (int64, error) {
+	ctx, span := tracer.Start(ctx, "command.Service.Sweep")
+	defer span.End()
+	expired, err := s.commandRepository.ExpireOverdue(ctx, now)
+	if err != nil {
+		return expired, err
+	}
+	queued, err := s.commandRepository.GetQueued(ctx, now, maxSweepCommands)
+	if err != nil {
+		return expired, err
+	}
+	for i := range queued {
+		if err = s.send(ctx, &queued[i]); err != nil && err != domain.ErrCommandTransition {
+			return expired, err
+		}
+	}
+	return expired, nil
+}
+
+// send publishes a queued command and marks it as sent.
+func (s *Service) send(ctx context.Context, c *domain.Command) error {
+	if err := s.publisher.PublishCommand(ctx, *c); err != nil {
+		return err
+	}
+	sent := *c
+	sent.Status = domain.CommandSent
+	sent.SentAt = time.Now()
+	// The device may have replied before the command is marked as sent
+	if err := s.commandRepository.UpdateStatus(ctx, &sent, []string{domain.CommandQueued}); err != nil {
+		return err
+	}
+	*c = sent
+	return nil
+}
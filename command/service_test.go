@@ -0,0 +1,151 @@
+package command_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/command"
+	"github.com/anggi-susanto/mrt-go/command/mocks"
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var commandConfig = config.CommandConfig{DefaultTTL: time.Hour, DefaultTimeout: 10 * time.Minute}
+
+func TestServiceCreate(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	request := domain.CommandRequest{Name: domain.CommandFlushSampler, Timeout: 60}
+	t.Run("Success", func(t *testing.T) {
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, deviceID.Hex()).Return(&domain.Device{ID: deviceID, Status: domain.DeviceActive}, nil)
+		mockRepo := new(mocks.CommandRepositoryInterface)
+		mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(c *domain.Command) bool {
+			return c.DeviceID == deviceID && c.Status == domain.CommandQueued && c.Timeout == 60 &&
+				time.Until(c.ExpiresAt) > 59*time.Minute
+		})).Return(nil)
+		mockRepo.On("UpdateStatus", mock.Anything, mock.MatchedBy(func(c *domain.Command) bool {
+			return c.Status == domain.CommandSent && !c.SentAt.IsZero()
+		}), []string{domain.CommandQueued}).Return(nil)
+		mockPublisher := new(mocks.CommandPublisherInterface)
+		mockPublisher.On("PublishCommand", mock.Anything, mock.Anything).Return(nil)
+		c, err := command.NewService(mockRepo, mockDeviceRepo, mockPublisher, commandConfig).Create(context.Background(), deviceID.Hex(), request)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.CommandSent, c.Status)
+		mockRepo.AssertExpectations(t)
+		mockPublisher.AssertExpectations(t)
+	})
+	t.Run("Broker unavailable", func(t *testing.T) {
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, deviceID.Hex()).Return(&domain.Device{ID: deviceID}, nil)
+		mockRepo := new(mocks.CommandRepositoryInterface)
+		mockRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+		mockPublisher := new(mocks.CommandPublisherInterface)
+		mockPublisher.On("PublishCommand", mock.Anything, mock.Anything).Return(errors.New("not connected"))
+		c, err := command.NewService(mockRepo, mockDeviceRepo, mockPublisher, commandConfig).Create(context.Background(), deviceID.Hex(), request)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.CommandQueued, c.Status)
+		mockRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("Decommissioned device", func(t *testing.T) {
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, deviceID.Hex()).Return(&domain.Device{ID: deviceID, Status: domain.DeviceDecommissioned}, nil)
+		_, err := command.NewService(nil, mockDeviceRepo, nil, commandConfig).Create(context.Background(), deviceID.Hex(), request)
+		assert.ErrorIs(t, err, domain.ErrDeviceDecommissioned)
+	})
+	t.Run("Device not found", func(t *testing.T) {
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, deviceID.Hex()).Return(nil, nil)
+		c, err := command.NewService(nil, mockDeviceRepo, nil, commandConfig).Create(context.Background(), deviceID.Hex(), request)
+		assert.NoError(t, err)
+		assert.Nil(t, c)
+	})
+	t.Run("No name", func(t *testing.T) {
+		_, err := command.NewService(nil, nil, nil, commandConfig).Create(context.Background(), deviceID.Hex(), domain.CommandRequest{})
+		assert.ErrorIs(t, err, domain.ErrInvalidCommand)
+	})
+}
+
+func TestServiceReply(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	commandID := primitive.NewObjectID()
+	sent := func() *domain.Command {
+		return &domain.Command{ID: commandID, DeviceID: deviceID, Status: domain.CommandSent, Timeout: 60}
+	}
+	t.Run("Acknowledged", func(t *testing.T) {
+		mockRepo := new(mocks.CommandRepositoryInterface)
+		mockRepo.On("GetByID", mock.Anything, commandID.Hex()).Return(sent(), nil)
+		mockRepo.On("UpdateStatus", mock.Anything, mock.Anything, mock.MatchedBy(func(from []string) bool {
+			return assert.ObjectsAreEqual([]string{domain.CommandQueued, domain.CommandSent}, from)
+		})).Return(nil)
+		c, err := command.NewService(mockRepo, nil, nil, commandConfig).Reply(context.Background(), deviceID, domain.CommandReply{CommandID: commandID, Status: domain.CommandAcknowledged})
+		assert.NoError(t, err)
+		assert.Equal(t, domain.CommandAcknowledged, c.Status)
+		assert.Equal(t, time.Minute, c.DeadlineAt.Sub(c.AcknowledgedAt))
+	})
+	t.Run("Result", func(t *testing.T) {
+		mockRepo := new(mocks.CommandRepositoryInterface)
+		mockRepo.On("GetByID", mock.Anything, commandID.Hex()).Return(sent(), nil)
+		mockRepo.On("UpdateStatus", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		reply := domain.CommandReply{CommandID: commandID, Status: domain.CommandFailed, Error: "sampler jammed"}
+		c, err := command.NewService(mockRepo, nil, nil, commandConfig).Reply(context.Background(), deviceID, reply)
+		assert.NoError(t, err)
+		assert.Equal(t, "sampler jammed", c.Error)
+		assert.False(t, c.CompletedAt.IsZero())
+	})
+	t.Run("Already expired", func(t *testing.T) {
+		mockRepo := new(mocks.CommandRepositoryInterface)
+		mockRepo.On("GetByID", mock.Anything, commandID.Hex()).Return(sent(), nil)
+		mockRepo.On("UpdateStatus", mock.Anything, mock.Anything, mock.Anything).Return(domain.ErrCommandTransition)
+		reply := domain.CommandReply{CommandID: commandID, Status: domain.CommandSucceeded}
+		_, err := command.NewService(mockRepo, nil, nil, commandConfig).Reply(context.Background(), deviceID, reply)
+		assert.ErrorIs(t, err, domain.ErrCommandTransition)
+	})
+	t.Run("Another device", func(t *testing.T) {
+		mockRepo := new(mocks.CommandRepositoryInterface)
+		mockRepo.On("GetByID", mock.Anything, commandID.Hex()).Return(sent(), nil)
+		reply := domain.CommandReply{CommandID: commandID, Status: domain.CommandSucceeded}
+		_, err := command.NewService(mockRepo, nil, nil, commandConfig).Reply(context.Background(), primitive.NewObjectID(), reply)
+		assert.ErrorIs(t, err, domain.ErrInvalidCommand)
+	})
+	t.Run("Invalid status", func(t *testing.T) {
+		mockRepo := new(mocks.CommandRepositoryInterface)
+		mockRepo.On("GetByID", mock.Anything, commandID.Hex()).Return(sent(), nil)
+		reply := domain.CommandReply{CommandID: commandID, Status: domain.CommandExpired}
+		_, err := command.NewService(mockRepo, nil, nil, commandConfig).Reply(context.Background(), deviceID, reply)
+		assert.ErrorIs(t, err, domain.ErrInvalidCommand)
+	})
+}
+
+func TestServiceSweep(t *testing.T) {
+	now := time.Now()
+	t.Run("Success", func(t *testing.T) {
+		queued := []domain.Command{
+			{ID: primitive.NewObjectID(), Status: domain.CommandQueued},
+			{ID: primitive.NewObjectID(), Status: domain.CommandQueued},
+		}
+		mockRepo := new(mocks.CommandRepositoryInterface)
+		mockRepo.On("ExpireOverdue", mock.Anything, now).Return(int64(3), nil)
+		mockRepo.On("GetQueued", mock.Anything, now, mock.Anything).Return(queued, nil)
+		// The first command was acknowledged before it was marked as sent
+		mockRepo.On("UpdateStatus", mock.Anything, mock.Anything, mock.Anything).Return(domain.ErrCommandTransition).Once()
+		mockRepo.On("UpdateStatus", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+		mockPublisher := new(mocks.CommandPublisherInterface)
+		mockPublisher.On("PublishCommand", mock.Anything, mock.Anything).Return(nil).Twice()
+		expired, err := command.NewService(mockRepo, nil, mockPublisher, commandConfig).Sweep(context.Background(), now)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), expired)
+		mockRepo.AssertExpectations(t)
+		mockPublisher.AssertExpectations(t)
+	})
+	t.Run("Error", func(t *testing.T) {
+		mockRepo := new(mocks.CommandRepositoryInterface)
+		mockRepo.On("ExpireOverdue", mock.Anything, now).Return(int64(0), errors.New("error"))
+		_, err := command.NewService(mockRepo, nil, nil, commandConfig).Sweep(context.Background(), now)
+		assert.Error(t, err)
+	})
+}
@@ -1,13 +1,219 @@
 package config
 
+import "time"
+
 type Config struct {
-	MongoConfig MongoConfig
+	MongoConfig        MongoConfig
+	IngestionConfig    IngestionConfig
+	QualityConfig      QualityConfig
+	AnomalyConfig      AnomalyConfig
+	DiagnosticsConfig  DiagnosticsConfig
+	MQTTConfig         MQTTConfig
+	CommandConfig      CommandConfig
+	ProvisioningConfig ProvisioningConfig
+	ModbusConfig       ModbusConfig
+	CoAPConfig         CoAPConfig
+	GRPCConfig         GRPCConfig
+	MetricsConfig      MetricsConfig
+	TracingConfig      TracingConfig
+	LoggingConfig      LoggingConfig
+	HealthConfig       HealthConfig
 }
 
 type MongoConfig struct {
-	Uri                  string
-	Database             string
-	WasteWaterCollection string
-	DeviceCollection     string
-	SensorCollection     string
+	Uri                     string
+	Database                string
+	WasteWaterCollection    string
+	DeviceCollection        string
+	SensorCollection        string
+	SiteCollection          string
+	SensorTypeCollection    string
+	MeasurementCollection   string
+	QualityReviewCollection string
+	AnomalyCollection       string
+	BaselineCollection      string
+	LabSampleCollection     string
+	CalibrationCollection   string
+	MaintenanceCollection   string
+	ShadowCollection        string
+	CommandCollection       string
+	ClaimCollection         string
+	TemplateCollection      string
+	CredentialCollection    string
+	IngestKeyCollection     string
+	CodecCollection         string
+	UplinkCollection        string
+	IngestMappingCollection string
+	PSKCollection           string
+	// MigrationCollection records the data migrations that completed
+	MigrationCollection string
+	// IngestKeyRetention is how long the dedup keys of the readings are remembered
+	IngestKeyRetention time.Duration
+}
+
+type IngestionConfig struct {
+	// RangeMode is either "reject" or "flag"
+	RangeMode string
+}
+
+type QualityConfig struct {
+	// HistorySize is the number of previous measurements of a parameter the checks look at
+	HistorySize int
+	// SpikeFactor is the number of standard deviations from the recent mean that counts as a spike
+	SpikeFactor float64
+	// FlatlineCount is the number of identical consecutive values that counts as a flatline
+	FlatlineCount int
+	// MaxRateOfChange is the largest plausible change per minute keyed by parameter code
+	MaxRateOfChange map[string]float64
+}
+
+type AnomalyConfig struct {
+	// Threshold is the score above which a value is anomalous
+	Threshold float64
+	// Alpha is the smoothing factor of the exponentially weighted moving average
+	Alpha float64
+	// WindowSize is the number of recent values the rolling median and MAD are computed on
+	WindowSize int
+	// MinSamples is the number of values a baseline needs before values are scored
+	MinSamples int
+	// MinSeasonalSamples is the number of values an hour of the day needs before it is scored
+	MinSeasonalSamples int
+}
+
+type DiagnosticsConfig struct {
+	// SampleSize is the number of recent measurements of a sensor the diagnostics look at
+	SampleSize int
+	// FlatlineCount is the number of identical consecutive values that counts as a flatlined signal
+	FlatlineCount int
+	// ZeroCount is the number of consecutive zero values that counts as a sensor stuck at zero
+	ZeroCount int
+	// MaxJump is the largest plausible change between consecutive values keyed by parameter code
+	MaxJump map[string]float64
+	// DriftThreshold is the relative change of the offset to the lab results or co-located sensors that counts as drift
+	DriftThreshold float64
+}
+
+type MQTTConfig struct {
+	// Broker is the address of the broker, e.g. tcp://localhost:1883
+	Broker   string
+	ClientID string
+	Username string
+	Password string
+	// TopicPrefix is prepended to every topic the API subscribes and publishes to
+	TopicPrefix string
+	// QoS is the quality of service of the subscriptions and publications
+	QoS byte
+	// Timeout bounds the wait for the broker to acknowledge a connection or a publication
+	Timeout time.Duration
+}
+
+type CommandConfig struct {
+	// DefaultTTL is how long a command waits for its device when the request gives no TTL
+	DefaultTTL time.Duration
+	// DefaultTimeout is how long an acknowledged command waits for its result when the request gives no timeout
+	DefaultTimeout time.Duration
+	// SweepInterval is how often queued commands are resent and overdue ones expired
+	SweepInterval time.Duration
+}
+
+type ProvisioningConfig struct {
+	// Broker is the broker address given to the devices, it may differ from the one the API connects to
+	Broker string
+	// TopicPrefix is the prefix the topics of a device start with, followed by the device ID
+	TopicPrefix string
+}
+
+type EdgeConfig struct {
+	// APIURL is the base URL of the central API the readings are forwarded to, e.g. http://localhost:3000
+	APIURL string
+	// AgentID prefixes the dedup keys of the readings, it must be unique per agent
+	AgentID string
+	// Username and Password are the credentials of the device the agent forwards the readings of,
+	// issued when the device was provisioned
+	Username string
+	Password string
+	// QueuePath is the file of the on-disk queue
+	QueuePath string
+	// MaxQueued is the number of readings kept while offline, the oldest are dropped beyond it, 0 keeps all
+	MaxQueued int
+	// BatchSize is the number of readings sent per request, at most 500
+	BatchSize int
+	// FlushInterval is how often the queue is forwarded while the API is reachable
+	FlushInterval time.Duration
+	// MinBackoff and MaxBackoff bound the wait before retrying once the API is unreachable
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// MaxSkew is the offset of the local clock to the server clock beyond which the timestamps are corrected
+	MaxSkew time.Duration
+	// RequestTimeout bounds each request to the API
+	RequestTimeout time.Duration
+	// MetricsAddress is the TCP address the metrics of the agent are served on, empty disables it
+	MetricsAddress string
+}
+
+type ModbusConfig struct {
+	// PollInterval is the time between two polls of a device whose endpoint gives no interval
+	PollInterval time.Duration
+	// SweepInterval is how often the devices due for a poll are looked for
+	SweepInterval time.Duration
+	// Timeout bounds the connection to an endpoint and each register read
+	Timeout time.Duration
+}
+
+type CoAPConfig struct {
+	// Address is the UDP address of plain CoAP, meant for development, empty disables it
+	Address string
+	// DTLSAddress is the UDP address of CoAP over DTLS with the pre-shared keys of the devices, empty disables it
+	DTLSAddress string
+	// Timeout bounds a DTLS handshake and the handling of a request
+	Timeout time.Duration
+	// IdleTimeout is how long a DTLS session without traffic is kept, its observations end with it
+	IdleTimeout time.Duration
+}
+
+type GRPCConfig struct {
+	// Address is the TCP address of the gRPC API, apart from the port of the REST API
+	Address string
+}
+
+type MetricsConfig struct {
+	// ParameterValues exposes the latest value of each parameter per device, one series per device and parameter
+	ParameterValues bool
+}
+
+type TracingConfig struct {
+	// Exporter is either "otlp", "stdout" or empty to record no spans
+	Exporter string
+	// Endpoint is the host:port of the OTLP gRPC receiver of the collector, e.g. localhost:4317
+	Endpoint string
+	// Insecure connects to the collector without TLS
+	Insecure bool
+	// ServiceName is the name the spans are reported under
+	ServiceName string
+	// SampleRatio is the share of the traces started by the API that are recorded, the traces of the callers follow their own sampling
+	SampleRatio float64
+}
+
+type LoggingConfig struct {
+	// Level is the level of the packages without a level of their own, e.g. "info"
+	Level string
+	// Levels is the level by package name, e.g. {"mongo": "debug", "http": "warn"}
+	Levels map[string]string
+	// RequestIDHeader is the header the request ID is read from and echoed in, X-Request-ID when empty
+	RequestIDHeader string
+	// TenantHeader and UserHeader are the headers the gateway in front of the API forwards the tenant and the user in, empty ignores them
+	TenantHeader string
+	UserHeader   string
+	// SampleTick, SampleFirst and SampleThereafter sample the access log of the successful requests: per route and tick
+	// the first SampleFirst requests are logged, then one in SampleThereafter. A SampleFirst of 0 logs every request
+	SampleTick       time.Duration
+	SampleFirst      int
+	SampleThereafter int
+}
+
+type HealthConfig struct {
+	// Timeout bounds each check of a probe, a component not answering in time is down
+	Timeout time.Duration
+	// WorkerStall is the time without a finished sweep after which a background worker is down, above its longest sweep
+	WorkerStall time.Duration
 }
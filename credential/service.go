@@ -0,0 +1,122 @@
+package credential
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+)
+
+// tracer starts the spans of the service methods
+var tracer = otel.Tracer("github.com/anggi-susanto/mrt-go/credential")
+
+// CredentialRepositoryInterface is the interface that wraps the GetByUsername method.
+type CredentialRepositoryInterface interface {
+	GetByUsername(ctx context.Context, username string) (*domain.DeviceCredential, error)
+}
+
+// DeviceRepositoryInterface is the interface that wraps the GetByID method.
+type DeviceRepositoryInterface interface {
+	GetByID(ctx context.Context, id string) (*domain.Device, error)
+}
+
+// Service is the interface that wraps the authentication of the devices with the credentials
+// issued when they are provisioned.
+type Service struct {
+	credentialRepository CredentialRepositoryInterface
+	deviceRepository     DeviceRepositoryInterface
+	broker               config.MQTTConfig
+}
+
+// NewService creates a new instance of the Service struct, initializing it with the provided dependencies.
+//
+// Parameters:
+// - credentialRepository: The CredentialRepositoryInterface implementation storing the device credentials.
+// - deviceRepository: The DeviceRepositoryInterface implementation used to check the device is in service.
+// - broker: the credentials the API connects to the broker with and the prefix of the device topics.
+//
+// Returns:
+// - A pointer to the newly created Service instance.
+func NewService(credentialRepository CredentialRepositoryInterface, deviceRepository DeviceRepositoryInterface, broker config.MQTTConfig) *Service {
+	return &Service{
+		credentialRepository: credentialRepository,
+		deviceRepository:     deviceRepository,
+		broker:               broker,
+	}
+}
+
+// Authenticate checks the credentials of a device.
+//
+// ctx - context.Context for the operation.
+// username - the username of the credentials, the hex ID of the device.
+// password - the password given to the device when it was provisioned.
+// Returns the ID of the device, the zero ID when the credentials are wrong or the device was
+// deleted or decommissioned, and an error.
+func (s *Service) Authenticate(ctx context.Context, username, password string) (primitive.ObjectID, error) {
+	ctx, span := tracer.Start(ctx, "credential.Service.Authenticate")
+	defer span.End()
+	if username == "" || password == "" {
+		return primitive.NilObjectID, nil
+	}
+	credential, err := s.credentialRepository.GetByUsername(ctx, username)
+	if err != nil || credential == nil || !credential.Matches(password) {
+		return primitive.NilObjectID, err
+	}
+	device, err := s.deviceRepository.GetByID(ctx, credential.DeviceID.Hex())
+	if err != nil || device == nil || device.Status == domain.DeviceDecommissioned {
+		return primitive.NilObjectID, err
+	}
+	return device.ID, nil
+}
+
+// BrokerLogin reports whether a client may connect to the broker.
+//
+// The API connects with its own credentials, a device with its credentials and its ID as client ID
+// so that it cannot take over the session of another device.
+//
+// ctx - context.Context for the operation.
+// username, password - the credentials the client connects with.
+// clientID - the MQTT client ID of the client.
+// Returns whether the client may connect and an error.
+func (s *Service) BrokerLogin(ctx context.Context, username, password, clientID string) (bool, error) {
+	ctx, span := tracer.Start(ctx, "credential.Service.BrokerLogin")
+	defer span.End()
+	if s.Superuser(username) {
+		return subtle.ConstantTimeCompare([]byte(password), []byte(s.broker.Password)) == 1, nil
+	}
+	if clientID != username {
+		return false, nil
+	}
+	deviceID, err := s.Authenticate(ctx, username, password)
+	if err != nil {
+		return false, err
+	}
+	return !deviceID.IsZero(), nil
+}
+
+// Superuser reports whether a broker client is the API, which reaches every topic.
+//
+// username - the username the client connected with.
+func (s *Service) Superuser(username string) bool {
+	return s.broker.Username != "" && s.broker.Password != "" && username == s.broker.Username
+}
+
+// Allowed reports whether a device may publish or subscribe to a topic, a device only reaches the
+// topics under the prefix followed by its ID.
+//
+// username - the username the device connected with, its hex ID.
+// topic - the topic or the subscription filter.
+func (s *Service) Allowed(username, topic string) bool {
+	if _, err := primitive.ObjectIDFromHex(username); err != nil {
+		return false
+	}
+	prefix := username + "/"
+	if s.broker.TopicPrefix != "" {
+		prefix = strings.TrimSuffix(s.broker.TopicPrefix, "/") + "/" + prefix
+	}
+	return strings.HasPrefix(topic, prefix)
+}
@@ -0,0 +1,103 @@
+package credential_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/credential"
+	"github.com/anggi-susanto/mrt-go/credential/mocks"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var brokerConfig = config.MQTTConfig{Username: "mrt-api", Password: "secret", TopicPrefix: "mrt/devices"}
+
+func TestServiceAuthenticate(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	stored := &domain.DeviceCredential{DeviceID: deviceID, Username: deviceID.Hex(), PasswordHash: domain.HashSecret("password")}
+	setup := func(device *domain.Device) *credential.Service {
+		mockRepo := new(mocks.CredentialRepositoryInterface)
+		mockRepo.On("GetByUsername", mock.Anything, deviceID.Hex()).Return(stored, nil)
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, deviceID.Hex()).Return(device, nil)
+		return credential.NewService(mockRepo, mockDeviceRepo, brokerConfig)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		id, err := setup(&domain.Device{ID: deviceID, Status: domain.DeviceActive}).Authenticate(context.Background(), deviceID.Hex(), "password")
+		assert.NoError(t, err)
+		assert.Equal(t, deviceID, id)
+	})
+	t.Run("Wrong password", func(t *testing.T) {
+		id, err := setup(&domain.Device{ID: deviceID}).Authenticate(context.Background(), deviceID.Hex(), "guess")
+		assert.NoError(t, err)
+		assert.True(t, id.IsZero())
+	})
+	t.Run("Decommissioned", func(t *testing.T) {
+		id, err := setup(&domain.Device{ID: deviceID, Status: domain.DeviceDecommissioned}).Authenticate(context.Background(), deviceID.Hex(), "password")
+		assert.NoError(t, err)
+		assert.True(t, id.IsZero())
+	})
+	t.Run("Device deleted", func(t *testing.T) {
+		id, err := setup(nil).Authenticate(context.Background(), deviceID.Hex(), "password")
+		assert.NoError(t, err)
+		assert.True(t, id.IsZero())
+	})
+	t.Run("Unknown username", func(t *testing.T) {
+		mockRepo := new(mocks.CredentialRepositoryInterface)
+		mockRepo.On("GetByUsername", mock.Anything, "unknown").Return(nil, nil)
+		id, err := credential.NewService(mockRepo, nil, brokerConfig).Authenticate(context.Background(), "unknown", "password")
+		assert.NoError(t, err)
+		assert.True(t, id.IsZero())
+	})
+	t.Run("Error", func(t *testing.T) {
+		mockRepo := new(mocks.CredentialRepositoryInterface)
+		mockRepo.On("GetByUsername", mock.Anything, mock.Anything).Return(nil, errors.New("error"))
+		_, err := credential.NewService(mockRepo, nil, brokerConfig).Authenticate(context.Background(), deviceID.Hex(), "password")
+		assert.Error(t, err)
+	})
+}
+
+func TestServiceBrokerLogin(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	mockRepo := new(mocks.CredentialRepositoryInterface)
+	mockRepo.On("GetByUsername", mock.Anything, deviceID.Hex()).Return(&domain.DeviceCredential{DeviceID: deviceID, PasswordHash: domain.HashSecret("password")}, nil)
+	mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+	mockDeviceRepo.On("GetByID", mock.Anything, deviceID.Hex()).Return(&domain.Device{ID: deviceID}, nil)
+	s := credential.NewService(mockRepo, mockDeviceRepo, brokerConfig)
+
+	for name, tc := range map[string]struct {
+		username, password, clientID string
+		allowed                      bool
+	}{
+		"API":                   {"mrt-api", "secret", "mrt-api", true},
+		"API wrong password":    {"mrt-api", "guess", "mrt-api", false},
+		"Device":                {deviceID.Hex(), "password", deviceID.Hex(), true},
+		"Device other clientID": {deviceID.Hex(), "password", primitive.NewObjectID().Hex(), false},
+		"Device wrong password": {deviceID.Hex(), "guess", deviceID.Hex(), false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			allowed, err := s.BrokerLogin(context.Background(), tc.username, tc.password, tc.clientID)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.allowed, allowed)
+		})
+	}
+}
+
+func TestServiceAllowed(t *testing.T) {
+	deviceID := primitive.NewObjectID().Hex()
+	s := credential.NewService(nil, nil, brokerConfig)
+	assert.True(t, s.Superuser("mrt-api"))
+	assert.False(t, s.Superuser(deviceID))
+	assert.True(t, s.Allowed(deviceID, "mrt/devices/"+deviceID+"/shadow/reported"))
+	assert.True(t, s.Allowed(deviceID, "mrt/devices/"+deviceID+"/#"))
+	assert.False(t, s.Allowed(deviceID, "mrt/devices/"+primitive.NewObjectID().Hex()+"/shadow/delta"))
+	assert.False(t, s.Allowed(deviceID, "mrt/devices/#"))
+	assert.False(t, s.Allowed(deviceID, "mrt/devices/+/shadow/delta"))
+	assert.False(t, s.Allowed("mrt-api", "mrt/devices/mrt-api/x"))
+	assert.False(t, credential.NewService(nil, nil, config.MQTTConfig{Username: "mrt-api"}).Superuser("mrt-api"))
+}
@@ -1,4 +1,4 @@
-// Code generated by mockery v2.42.1. DO NOT EDIT.
+// Code generated by mockery v2.53.3. DO NOT EDIT.
 
 package mocks
 
@@ -51,9 +51,9 @@ func (_m *DeviceRepositoryInterface) Delete(ctx context.Context, id string) erro
 	return r0
 }
 
-// GetAll provides a mock function with given fields: ctx, page, limit
-func (_m *DeviceRepositoryInterface) GetAll(ctx context.Context, page int, limit int) ([]domain.Device, error) {
-	ret := _m.Called(ctx, page, limit)
+// GetAll provides a mock function with given fields: ctx, filter, page, limit
+func (_m *DeviceRepositoryInterface) GetAll(ctx context.Context, filter domain.DeviceFilter, page int, limit int) ([]domain.Device, error) {
+	ret := _m.Called(ctx, filter, page, limit)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetAll")
@@ -61,19 +61,19 @@ func (_m *DeviceRepositoryInterface) GetAll(ctx context.Context, page int, limit
 
 	var r0 []domain.Device
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]domain.Device, error)); ok {
-		return rf(ctx, page, limit)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.DeviceFilter, int, int) ([]domain.Device, error)); ok {
+		return rf(ctx, filter, page, limit)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int, int) []domain.Device); ok {
-		r0 = rf(ctx, page, limit)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.DeviceFilter, int, int) []domain.Device); ok {
+		r0 = rf(ctx, filter, page, limit)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]domain.Device)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
-		r1 = rf(ctx, page, limit)
+	if rf, ok := ret.Get(1).(func(context.Context, domain.DeviceFilter, int, int) error); ok {
+		r1 = rf(ctx, filter, page, limit)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -111,6 +111,66 @@ func (_m *DeviceRepositoryInterface) GetByID(ctx context.Context, id string) (*d
 	return r0, r1
 }
 
+// GetInventory provides a mock function with given fields: ctx
+func (_m *DeviceRepositoryInterface) GetInventory(ctx context.Context) ([]domain.InventoryCount, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetInventory")
+	}
+
+	var r0 []domain.InventoryCount
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]domain.InventoryCount, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []domain.InventoryCount); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.InventoryCount)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetNear provides a mock function with given fields: ctx, lat, lng, radius, page, limit
+func (_m *DeviceRepositoryInterface) GetNear(ctx context.Context, lat float64, lng float64, radius float64, page int, limit int) ([]domain.Device, error) {
+	ret := _m.Called(ctx, lat, lng, radius, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetNear")
+	}
+
+	var r0 []domain.Device
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, float64, float64, float64, int, int) ([]domain.Device, error)); ok {
+		return rf(ctx, lat, lng, radius, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, float64, float64, float64, int, int) []domain.Device); ok {
+		r0 = rf(ctx, lat, lng, radius, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Device)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, float64, float64, float64, int, int) error); ok {
+		r1 = rf(ctx, lat, lng, radius, page, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: ctx, w
 func (_m *DeviceRepositoryInterface) Update(ctx context.Context, w *domain.Device) error {
 	ret := _m.Called(ctx, w)
@@ -0,0 +1,62 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReadingRepositoryInterface is an autogenerated mock type for the ReadingRepositoryInterface type
+type ReadingRepositoryInterface struct {
+	mock.Mock
+}
+
+// GetLatestByDeviceIDs provides a mock function with given fields: ctx, deviceIDs
+func (_m *ReadingRepositoryInterface) GetLatestByDeviceIDs(ctx context.Context, deviceIDs []primitive.ObjectID) ([]domain.WasteWaterData, error) {
+	ret := _m.Called(ctx, deviceIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLatestByDeviceIDs")
+	}
+
+	var r0 []domain.WasteWaterData
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []primitive.ObjectID) ([]domain.WasteWaterData, error)); ok {
+		return rf(ctx, deviceIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []primitive.ObjectID) []domain.WasteWaterData); ok {
+		r0 = rf(ctx, deviceIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.WasteWaterData)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []primitive.ObjectID) error); ok {
+		r1 = rf(ctx, deviceIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewReadingRepositoryInterface creates a new instance of ReadingRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewReadingRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ReadingRepositoryInterface {
+	mock := &ReadingRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
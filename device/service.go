@@ -2,35 +2,52 @@ package device
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
 )
 
+// tracer starts the spans of the service methods
+var tracer = otel.Tracer("github.com/anggi-susanto/mrt-go/device")
+
 // DeviceRepositoryInterface is an autogenerated interface for deviceRepository
 
 type DeviceRepositoryInterface interface {
 	Create(ctx context.Context, w *domain.DeviceRequest) error
-	GetAll(ctx context.Context, page, limit int) ([]domain.Device, error)
+	GetAll(ctx context.Context, filter domain.DeviceFilter, page, limit int) ([]domain.Device, error)
 	GetByID(ctx context.Context, id string) (*domain.Device, error)
 	Update(ctx context.Context, w *domain.Device) error
 	Delete(ctx context.Context, id string) error
+	GetNear(ctx context.Context, lat, lng, radius float64, page, limit int) ([]domain.Device, error)
+	GetInventory(ctx context.Context) ([]domain.InventoryCount, error)
+}
+
+// ReadingRepositoryInterface is the interface that wraps the GetLatestByDeviceIDs method.
+type ReadingRepositoryInterface interface {
+	GetLatestByDeviceIDs(ctx context.Context, deviceIDs []primitive.ObjectID) ([]domain.WasteWaterData, error)
 }
 
 // Service is the interface that wraps the Create, GetAll, GetByID, Update, and Delete methods.
 type Service struct {
-	deviceRepository DeviceRepositoryInterface
+	deviceRepository  DeviceRepositoryInterface
+	readingRepository ReadingRepositoryInterface
 }
 
-// NewService creates a new instance of the Service struct, initializing it with the provided DeviceRepositoryInterface.
+// NewService creates a new instance of the Service struct, initializing it with the provided repositories.
 //
 // Parameters:
 // - deviceRepository: The DeviceRepositoryInterface implementation used by the Service.
+// - readingRepository: The ReadingRepositoryInterface implementation used to attach latest readings.
 //
 // Returns:
 // - A pointer to the newly created Service instance.
-func NewService(deviceRepository DeviceRepositoryInterface) *Service {
+func NewService(deviceRepository DeviceRepositoryInterface, readingRepository ReadingRepositoryInterface) *Service {
 	return &Service{
-		deviceRepository: deviceRepository,
+		deviceRepository:  deviceRepository,
+		readingRepository: readingRepository,
 	}
 }
 
@@ -40,15 +57,32 @@ func NewService(deviceRepository DeviceRepositoryInterface) *Service {
 // w: The waste water data to be created.
 // Returns an error if there was a problem creating the record.
 func (s *Service) Create(ctx context.Context, w *domain.DeviceRequest) error {
+	ctx, span := tracer.Start(ctx, "device.Service.Create")
+	defer span.End()
+	if err := w.Validate(); err != nil {
+		return err
+	}
 	return s.deviceRepository.Create(ctx, w)
 }
 
-// GetAll retrieves all waste water data with pagination.
+// GetAll retrieves the devices matching the inventory filter with pagination.
 //
-// ctx context.Context, page int, limit int
+// ctx context.Context, filter domain.DeviceFilter, page int, limit int
 // []domain.DeviceData, error
-func (s *Service) GetAll(ctx context.Context, page, limit int) ([]domain.Device, error) {
-	return s.deviceRepository.GetAll(ctx, page, limit)
+func (s *Service) GetAll(ctx context.Context, filter domain.DeviceFilter, page, limit int) ([]domain.Device, error) {
+	ctx, span := tracer.Start(ctx, "device.Service.GetAll")
+	defer span.End()
+	return s.deviceRepository.GetAll(ctx, filter, page, limit)
+}
+
+// GetInventory counts the devices per lifecycle status and model.
+//
+// ctx - context.Context for the operation.
+// Returns the counts and an error.
+func (s *Service) GetInventory(ctx context.Context) ([]domain.InventoryCount, error) {
+	ctx, span := tracer.Start(ctx, "device.Service.GetInventory")
+	defer span.End()
+	return s.deviceRepository.GetInventory(ctx)
 }
 
 // GetByID retrieves a DeviceData by ID.
@@ -57,6 +91,8 @@ func (s *Service) GetAll(ctx context.Context, page, limit int) ([]domain.Device,
 // id - string representing the ID of the data.
 // Returns a pointer to domain.DeviceData and an error.
 func (s *Service) GetByID(ctx context.Context, id string) (*domain.Device, error) {
+	ctx, span := tracer.Start(ctx, "device.Service.GetByID")
+	defer span.End()
 	return s.deviceRepository.GetByID(ctx, id)
 }
 
@@ -66,14 +102,161 @@ func (s *Service) GetByID(ctx context.Context, id string) (*domain.Device, error
 // id - string representing the ID of the data to be deleted.
 // Returns an error if there was a problem deleting the data.
 func (s *Service) Delete(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "device.Service.Delete")
+	defer span.End()
 	return s.deviceRepository.Delete(ctx, id)
 }
 
 // Update updates a DeviceData.
 //
+// The install history of the device is kept, a changed status is applied as a lifecycle transition.
+// The site and location only change when the device is installed, an omitted site or location is kept.
+//
 // ctx - context.Context for the operation.
 // w - pointer to domain.DeviceData representing the data to be updated.
 // Returns an error if there was a problem updating the data.
 func (s *Service) Update(ctx context.Context, w *domain.Device) error {
+	ctx, span := tracer.Start(ctx, "device.Service.Update")
+	defer span.End()
+	if w.Location != nil {
+		if err := w.Location.Validate(); err != nil {
+			return err
+		}
+	}
+	if w.Modbus != nil {
+		if err := w.Modbus.Validate(); err != nil {
+			return err
+		}
+	}
+	devEUI, err := domain.NormalizeDevEUI(w.DevEUI)
+	if err != nil {
+		return err
+	}
+	w.DevEUI = devEUI
+	current, err := s.deviceRepository.GetByID(ctx, w.ID.Hex())
+	if err != nil {
+		return err
+	}
+	if current != nil {
+		status, siteID, location := w.Status, w.SiteID, w.Location
+		w.Status = current.Status
+		w.SiteID = current.SiteID
+		w.Location = current.Location
+		w.InstalledAt = current.InstalledAt
+		w.DecommissionedAt = current.DecommissionedAt
+		w.Installations = current.Installations
+		moved := (!siteID.IsZero() && siteID != current.SiteID) || (location != nil && !location.Equal(current.Location))
+		if status == "" {
+			status = current.Status
+		}
+		if moved && (status == current.Status || status != domain.DeviceInstalled) {
+			return fmt.Errorf("%w: site_id and location change by installing the device", domain.ErrDeviceTransition)
+		}
+		if status != current.Status {
+			if err = w.Transition(domain.DeviceTransition{Status: status, SiteID: siteID, Location: location}); err != nil {
+				return err
+			}
+		}
+	}
 	return s.deviceRepository.Update(ctx, w)
 }
+
+// Transition moves a device to another lifecycle status.
+//
+// ctx - context.Context for the operation.
+// id - string representing the ID of the device.
+// t - the requested status, with the site and location when the device is installed.
+// Returns the updated device, nil when it does not exist, and an error.
+func (s *Service) Transition(ctx context.Context, id string, t domain.DeviceTransition) (*domain.Device, error) {
+	ctx, span := tracer.Start(ctx, "device.Service.Transition")
+	defer span.End()
+	d, err := s.deviceRepository.GetByID(ctx, id)
+	if err != nil || d == nil {
+		return nil, err
+	}
+	if err = d.Transition(t); err != nil {
+		return nil, err
+	}
+	d.UpdatedAt = domain.MyTime{Time: time.Now()}
+	if err = s.deviceRepository.Update(ctx, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// GetNear retrieves the devices located within the query radius, nearest first.
+//
+// ctx - context.Context for the operation.
+// near - the center point and radius in meters.
+// Returns the matching devices and an error.
+func (s *Service) GetNear(ctx context.Context, near domain.NearQuery, page, limit int) ([]domain.Device, error) {
+	ctx, span := tracer.Start(ctx, "device.Service.GetNear")
+	defer span.End()
+	if err := near.Validate(); err != nil {
+		return nil, err
+	}
+	return s.deviceRepository.GetNear(ctx, near.Lat, near.Lng, near.Radius, page, limit)
+}
+
+// GetFeatureCollection returns the located devices as a GeoJSON FeatureCollection
+// with the latest waste water reading of each device in its properties.
+//
+// ctx - context.Context for the operation.
+// near - optional proximity filter, all devices are listed when nil.
+// Returns the feature collection and an error.
+func (s *Service) GetFeatureCollection(ctx context.Context, near *domain.NearQuery, page, limit int) (*domain.FeatureCollection, error) {
+	ctx, span := tracer.Start(ctx, "device.Service.GetFeatureCollection")
+	defer span.End()
+	var devices []domain.Device
+	var err error
+	if near != nil {
+		devices, err = s.GetNear(ctx, *near, page, limit)
+	} else {
+		devices, err = s.deviceRepository.GetAll(ctx, domain.DeviceFilter{}, page, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(devices))
+	for _, d := range devices {
+		if d.Location != nil {
+			ids = append(ids, d.ID)
+		}
+	}
+
+	latest := map[primitive.ObjectID]domain.WasteWaterData{}
+	if len(ids) > 0 {
+		readings, err := s.readingRepository.GetLatestByDeviceIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range readings {
+			latest[r.DeviceID] = r
+		}
+	}
+
+	collection := &domain.FeatureCollection{Type: domain.GeoJSONFeatureCollection, Features: []domain.Feature{}}
+	for _, d := range devices {
+		if d.Location == nil {
+			continue
+		}
+		properties := map[string]interface{}{
+			"name":        d.Name,
+			"description": d.Description,
+		}
+		if !d.SiteID.IsZero() {
+			properties["site_id"] = d.SiteID.Hex()
+		}
+		if reading, ok := latest[d.ID]; ok {
+			properties["latest_reading"] = reading
+		}
+		collection.Features = append(collection.Features, domain.Feature{
+			Type:       domain.GeoJSONFeature,
+			ID:         d.ID.Hex(),
+			Geometry:   d.Location,
+			Properties: properties,
+		})
+	}
+	return collection, nil
+}
@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/anggi-susanto/mrt-go/device"
 	"github.com/anggi-susanto/mrt-go/device/mocks"
 	"github.com/anggi-susanto/mrt-go/domain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func TestServiceCreate(t *testing.T) {
@@ -19,19 +21,125 @@ func TestServiceCreate(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
 		mockDeviceRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
-		s := device.NewService(mockDeviceRepo)
+		s := device.NewService(mockDeviceRepo, new(mocks.ReadingRepositoryInterface))
 		err := s.Create(context.Background(), &mockDevice)
 		assert.NoError(t, err)
 	})
 	t.Run("Error", func(t *testing.T) {
 		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
 		mockDeviceRepo.On("Create", mock.Anything, mock.Anything).Return(errors.New("error")).Once()
-		s := device.NewService(mockDeviceRepo)
+		s := device.NewService(mockDeviceRepo, new(mocks.ReadingRepositoryInterface))
 		err := s.Create(context.Background(), &mockDevice)
 		assert.Error(t, err)
 	})
 }
 
+func TestServiceCreateLifecycle(t *testing.T) {
+	t.Run("Procured by default", func(t *testing.T) {
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+		w := domain.DeviceRequest{Name: "device", SerialNumber: "SN-1", Model: "MRT-100"}
+		err := device.NewService(mockDeviceRepo, nil).Create(context.Background(), &w)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.DeviceProcured, w.Status)
+		assert.Empty(t, w.Installations)
+	})
+	t.Run("Installed at a site", func(t *testing.T) {
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+		siteID := primitive.NewObjectID()
+		w := domain.DeviceRequest{Name: "device", Status: domain.DeviceActive, SiteID: siteID}
+		err := device.NewService(mockDeviceRepo, nil).Create(context.Background(), &w)
+		assert.NoError(t, err)
+		assert.Len(t, w.Installations, 1)
+		assert.Equal(t, siteID, w.Installations[0].SiteID)
+		assert.False(t, w.InstalledAt.IsZero())
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		for name, w := range map[string]domain.DeviceRequest{
+			"Unknown status": {Status: "lost"},
+			"Decommissioned": {Status: domain.DeviceDecommissioned},
+			"No site":        {Status: domain.DeviceInstalled},
+		} {
+			t.Run(name, func(t *testing.T) {
+				err := device.NewService(new(mocks.DeviceRepositoryInterface), nil).Create(context.Background(), &w)
+				assert.ErrorIs(t, err, domain.ErrInvalidDevice)
+			})
+		}
+	})
+}
+
+func TestServiceTransition(t *testing.T) {
+	firstSite := primitive.NewObjectID()
+	secondSite := primitive.NewObjectID()
+	installedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	movedAt := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	newDevice := func() *domain.Device {
+		return &domain.Device{
+			ID:            primitive.NewObjectID(),
+			SiteID:        firstSite,
+			Status:        domain.DeviceActive,
+			InstalledAt:   installedAt,
+			Installations: []domain.Installation{{SiteID: firstSite, InstalledAt: installedAt}},
+		}
+	}
+	t.Run("Move to another site", func(t *testing.T) {
+		d := newDevice()
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, d.ID.Hex()).Return(d, nil)
+		mockDeviceRepo.On("Update", mock.Anything, d).Return(nil)
+		location := domain.NewGeoPoint(-6.19, 106.82)
+		moved, err := device.NewService(mockDeviceRepo, nil).Transition(context.Background(), d.ID.Hex(), domain.DeviceTransition{
+			Status: domain.DeviceInstalled, SiteID: secondSite, Location: location, At: movedAt,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, domain.DeviceInstalled, moved.Status)
+		assert.Equal(t, secondSite, moved.SiteID)
+		assert.Equal(t, location, moved.Location)
+		assert.Len(t, moved.Installations, 2)
+		assert.Equal(t, movedAt, moved.Installations[0].RemovedAt)
+		assert.Equal(t, secondSite, moved.Installations[1].SiteID)
+		assert.True(t, moved.Installations[1].RemovedAt.IsZero())
+		mockDeviceRepo.AssertExpectations(t)
+	})
+	t.Run("Decommission", func(t *testing.T) {
+		d := newDevice()
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, d.ID.Hex()).Return(d, nil)
+		mockDeviceRepo.On("Update", mock.Anything, d).Return(nil)
+		decommissioned, err := device.NewService(mockDeviceRepo, nil).Transition(context.Background(), d.ID.Hex(), domain.DeviceTransition{
+			Status: domain.DeviceDecommissioned, At: movedAt,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, movedAt, decommissioned.DecommissionedAt)
+		assert.Equal(t, movedAt, decommissioned.Installations[0].RemovedAt)
+		assert.True(t, decommissioned.AcceptsData(movedAt.Add(-time.Hour)))
+		assert.False(t, decommissioned.AcceptsData(movedAt))
+	})
+	t.Run("Not allowed", func(t *testing.T) {
+		d := newDevice()
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, d.ID.Hex()).Return(d, nil)
+		_, err := device.NewService(mockDeviceRepo, nil).Transition(context.Background(), d.ID.Hex(), domain.DeviceTransition{Status: domain.DeviceProcured})
+		assert.ErrorIs(t, err, domain.ErrDeviceTransition)
+		mockDeviceRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+	t.Run("Install without site", func(t *testing.T) {
+		d := &domain.Device{ID: primitive.NewObjectID(), Status: domain.DeviceProcured}
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, d.ID.Hex()).Return(d, nil)
+		_, err := device.NewService(mockDeviceRepo, nil).Transition(context.Background(), d.ID.Hex(), domain.DeviceTransition{Status: domain.DeviceInstalled})
+		assert.ErrorIs(t, err, domain.ErrInvalidDevice)
+	})
+	t.Run("Not found", func(t *testing.T) {
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, "1").Return(nil, nil)
+		d, err := device.NewService(mockDeviceRepo, nil).Transition(context.Background(), "1", domain.DeviceTransition{Status: domain.DeviceActive})
+		assert.NoError(t, err)
+		assert.Nil(t, d)
+	})
+}
+
 func TestServiceGetAll(t *testing.T) {
 	mockDevice := []domain.Device{
 		{
@@ -40,17 +148,17 @@ func TestServiceGetAll(t *testing.T) {
 	}
 	t.Run("Success", func(t *testing.T) {
 		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
-		mockDeviceRepo.On("GetAll", mock.Anything, mock.Anything, mock.Anything).Return(mockDevice, nil)
-		s := device.NewService(mockDeviceRepo)
-		data, err := s.GetAll(context.Background(), 1, 10)
+		mockDeviceRepo.On("GetAll", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(mockDevice, nil)
+		s := device.NewService(mockDeviceRepo, new(mocks.ReadingRepositoryInterface))
+		data, err := s.GetAll(context.Background(), domain.DeviceFilter{}, 1, 10)
 		assert.Len(t, data, len(mockDevice))
 		assert.NoError(t, err)
 	})
 	t.Run("Error", func(t *testing.T) {
 		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
-		mockDeviceRepo.On("GetAll", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("error")).Once()
-		s := device.NewService(mockDeviceRepo)
-		data, err := s.GetAll(context.Background(), 1, 10)
+		mockDeviceRepo.On("GetAll", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("error")).Once()
+		s := device.NewService(mockDeviceRepo, new(mocks.ReadingRepositoryInterface))
+		data, err := s.GetAll(context.Background(), domain.DeviceFilter{}, 1, 10)
 		assert.Nil(t, data)
 		assert.Error(t, err)
 	})
@@ -62,15 +170,99 @@ func TestServiceUpdate(t *testing.T) {
 	}
 	t.Run("Success", func(t *testing.T) {
 		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, mock.Anything).Return(nil, nil)
 		mockDeviceRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
-		s := device.NewService(mockDeviceRepo)
+		s := device.NewService(mockDeviceRepo, new(mocks.ReadingRepositoryInterface))
 		err := s.Update(context.Background(), &mockDevice)
 		assert.NoError(t, err)
 	})
+	t.Run("Lifecycle", func(t *testing.T) {
+		siteID := primitive.NewObjectID()
+		installedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		current := &domain.Device{
+			ID:            primitive.NewObjectID(),
+			SiteID:        siteID,
+			Status:        domain.DeviceInstalled,
+			InstalledAt:   installedAt,
+			Installations: []domain.Installation{{SiteID: siteID, InstalledAt: installedAt}},
+		}
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, current.ID.Hex()).Return(current, nil)
+		mockDeviceRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+		s := device.NewService(mockDeviceRepo, new(mocks.ReadingRepositoryInterface))
+		w := domain.Device{ID: current.ID, Name: "renamed", SiteID: siteID, Status: domain.DeviceActive}
+		err := s.Update(context.Background(), &w)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.DeviceActive, w.Status)
+		assert.Equal(t, installedAt, w.InstalledAt)
+		assert.Len(t, w.Installations, 1)
+	})
+	t.Run("Site kept", func(t *testing.T) {
+		siteID := primitive.NewObjectID()
+		location := domain.NewGeoPoint(-6.2, 106.8)
+		current := &domain.Device{ID: primitive.NewObjectID(), SiteID: siteID, Location: location, Status: domain.DeviceActive}
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, current.ID.Hex()).Return(current, nil)
+		mockDeviceRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+		s := device.NewService(mockDeviceRepo, new(mocks.ReadingRepositoryInterface))
+		w := domain.Device{ID: current.ID, Name: "renamed"}
+		err := s.Update(context.Background(), &w)
+		assert.NoError(t, err)
+		assert.Equal(t, siteID, w.SiteID)
+		assert.Equal(t, location, w.Location)
+		assert.Equal(t, domain.DeviceActive, w.Status)
+	})
+	t.Run("Moved by installing", func(t *testing.T) {
+		siteID := primitive.NewObjectID()
+		otherSiteID := primitive.NewObjectID()
+		current := &domain.Device{
+			ID:            primitive.NewObjectID(),
+			SiteID:        siteID,
+			Status:        domain.DeviceMaintenance,
+			Installations: []domain.Installation{{SiteID: siteID}},
+		}
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, current.ID.Hex()).Return(current, nil)
+		mockDeviceRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+		s := device.NewService(mockDeviceRepo, new(mocks.ReadingRepositoryInterface))
+		w := domain.Device{ID: current.ID, SiteID: otherSiteID, Status: domain.DeviceInstalled}
+		err := s.Update(context.Background(), &w)
+		assert.NoError(t, err)
+		assert.Equal(t, otherSiteID, w.SiteID)
+		if assert.Len(t, w.Installations, 2) {
+			assert.False(t, w.Installations[0].RemovedAt.IsZero())
+		}
+	})
+	t.Run("Moved without installing", func(t *testing.T) {
+		siteID := primitive.NewObjectID()
+		for name, current := range map[string]*domain.Device{
+			"Active":         {ID: primitive.NewObjectID(), SiteID: siteID, Status: domain.DeviceActive},
+			"Decommissioned": {ID: primitive.NewObjectID(), SiteID: siteID, Status: domain.DeviceDecommissioned},
+		} {
+			t.Run(name, func(t *testing.T) {
+				mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+				mockDeviceRepo.On("GetByID", mock.Anything, current.ID.Hex()).Return(current, nil)
+				s := device.NewService(mockDeviceRepo, new(mocks.ReadingRepositoryInterface))
+				err := s.Update(context.Background(), &domain.Device{ID: current.ID, Location: domain.NewGeoPoint(-6.2, 106.8)})
+				assert.ErrorIs(t, err, domain.ErrDeviceTransition)
+				mockDeviceRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+			})
+		}
+	})
+	t.Run("Invalid transition", func(t *testing.T) {
+		current := &domain.Device{ID: primitive.NewObjectID(), Status: domain.DeviceDecommissioned}
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, current.ID.Hex()).Return(current, nil)
+		s := device.NewService(mockDeviceRepo, new(mocks.ReadingRepositoryInterface))
+		err := s.Update(context.Background(), &domain.Device{ID: current.ID, Status: domain.DeviceActive})
+		assert.ErrorIs(t, err, domain.ErrDeviceTransition)
+		mockDeviceRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
 	t.Run("Error", func(t *testing.T) {
 		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, mock.Anything).Return(nil, nil)
 		mockDeviceRepo.On("Update", mock.Anything, mock.Anything).Return(errors.New("error")).Once()
-		s := device.NewService(mockDeviceRepo)
+		s := device.NewService(mockDeviceRepo, new(mocks.ReadingRepositoryInterface))
 		err := s.Update(context.Background(), &mockDevice)
 		assert.Error(t, err)
 	})
@@ -83,7 +275,7 @@ func TestServiceGetByID(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
 		mockDeviceRepo.On("GetByID", mock.Anything, mock.Anything, mock.Anything).Return(&mockDevice, nil)
-		s := device.NewService(mockDeviceRepo)
+		s := device.NewService(mockDeviceRepo, new(mocks.ReadingRepositoryInterface))
 		data, err := s.GetByID(context.Background(), "1")
 		assert.Equal(t, data.Name, mockDevice.Name)
 		assert.NoError(t, err)
@@ -91,7 +283,7 @@ func TestServiceGetByID(t *testing.T) {
 	t.Run("Error", func(t *testing.T) {
 		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
 		mockDeviceRepo.On("GetByID", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("error")).Once()
-		s := device.NewService(mockDeviceRepo)
+		s := device.NewService(mockDeviceRepo, new(mocks.ReadingRepositoryInterface))
 		data, err := s.GetByID(context.Background(), "1")
 		assert.Nil(t, data)
 		assert.Error(t, err)
@@ -102,15 +294,76 @@ func TestServiceDelete(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
 		mockDeviceRepo.On("Delete", mock.Anything, mock.Anything, mock.Anything).Return(nil)
-		s := device.NewService(mockDeviceRepo)
+		s := device.NewService(mockDeviceRepo, new(mocks.ReadingRepositoryInterface))
 		err := s.Delete(context.Background(), "1")
 		assert.NoError(t, err)
 	})
 	t.Run("Error", func(t *testing.T) {
 		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
 		mockDeviceRepo.On("Delete", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("error")).Once()
-		s := device.NewService(mockDeviceRepo)
+		s := device.NewService(mockDeviceRepo, new(mocks.ReadingRepositoryInterface))
 		err := s.Delete(context.Background(), "1")
 		assert.Error(t, err)
 	})
 }
+
+func TestServiceGetNear(t *testing.T) {
+	mockDevice := []domain.Device{
+		{
+			Name:     "device",
+			Location: domain.NewGeoPoint(-6.1935, 106.8227),
+		},
+	}
+	t.Run("Success", func(t *testing.T) {
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetNear", mock.Anything, -6.19, 106.82, 500.0, 1, 10).Return(mockDevice, nil)
+		s := device.NewService(mockDeviceRepo, new(mocks.ReadingRepositoryInterface))
+		data, err := s.GetNear(context.Background(), domain.NearQuery{Lat: -6.19, Lng: 106.82, Radius: 500}, 1, 10)
+		assert.Len(t, data, len(mockDevice))
+		assert.NoError(t, err)
+	})
+	t.Run("Invalid coordinates", func(t *testing.T) {
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		s := device.NewService(mockDeviceRepo, new(mocks.ReadingRepositoryInterface))
+		data, err := s.GetNear(context.Background(), domain.NearQuery{Lat: 95, Lng: 106.82, Radius: 500}, 1, 10)
+		assert.Nil(t, data)
+		assert.ErrorIs(t, err, domain.ErrInvalidGeometry)
+		mockDeviceRepo.AssertNotCalled(t, "GetNear")
+	})
+}
+
+func TestServiceGetFeatureCollection(t *testing.T) {
+	located := domain.Device{
+		ID:       primitive.NewObjectID(),
+		Name:     "located",
+		Location: domain.NewGeoPoint(-6.1935, 106.8227),
+	}
+	unlocated := domain.Device{
+		ID:   primitive.NewObjectID(),
+		Name: "unlocated",
+	}
+	t.Run("Success", func(t *testing.T) {
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockReadingRepo := new(mocks.ReadingRepositoryInterface)
+		mockDeviceRepo.On("GetAll", mock.Anything, domain.DeviceFilter{}, 1, 10).Return([]domain.Device{located, unlocated}, nil)
+		mockReadingRepo.On("GetLatestByDeviceIDs", mock.Anything, []primitive.ObjectID{located.ID}).
+			Return([]domain.WasteWaterData{{DeviceID: located.ID, PH: 7.1}}, nil)
+		s := device.NewService(mockDeviceRepo, mockReadingRepo)
+		data, err := s.GetFeatureCollection(context.Background(), nil, 1, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.GeoJSONFeatureCollection, data.Type)
+		assert.Len(t, data.Features, 1)
+		assert.Equal(t, located.ID.Hex(), data.Features[0].ID)
+		assert.Equal(t, 7.1, data.Features[0].Properties["latest_reading"].(domain.WasteWaterData).PH)
+	})
+	t.Run("Error", func(t *testing.T) {
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockReadingRepo := new(mocks.ReadingRepositoryInterface)
+		mockDeviceRepo.On("GetAll", mock.Anything, domain.DeviceFilter{}, 1, 10).Return([]domain.Device{located}, nil)
+		mockReadingRepo.On("GetLatestByDeviceIDs", mock.Anything, mock.Anything).Return(nil, errors.New("error"))
+		s := device.NewService(mockDeviceRepo, mockReadingRepo)
+		data, err := s.GetFeatureCollection(context.Background(), nil, 1, 10)
+		assert.Nil(t, data)
+		assert.Error(t, err)
+	})
+}
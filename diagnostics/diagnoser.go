@@ -0,0 +1,355 @@
+package diagnostics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// minDriftPairs is the number of readings shared with a co-located sensor needed before drift is detected
+const minDriftPairs = 6
+
+// minLabDriftPairs is the number of lab results paired with the sensor needed before drift is detected
+const minLabDriftPairs = 3
+
+// penalties is the number of points each diagnostic takes off the health score
+var penalties = map[string]float64{
+	domain.DiagnosticFlatline:     40,
+	domain.DiagnosticStuckAtZero:  50,
+	domain.DiagnosticJump:         10,
+	domain.DiagnosticDrift:        25,
+	domain.DiagnosticInconsistent: 20,
+}
+
+// Health score thresholds of the statuses
+const (
+	healthyScore  = 80
+	degradedScore = 50
+)
+
+// Diagnoser looks for sensor faults in the recent measurements of a device.
+type Diagnoser struct {
+	config config.DiagnosticsConfig
+}
+
+// NewDiagnoser creates a new Diagnoser.
+//
+// Parameters:
+// - config: the thresholds of the diagnostics, zero thresholds disable the matching diagnostic.
+//
+// Returns a pointer to a Diagnoser.
+func NewDiagnoser(config config.DiagnosticsConfig) *Diagnoser {
+	return &Diagnoser{config: config}
+}
+
+// SampleSize returns the number of recent measurements of the sensor Diagnose expects.
+func (d *Diagnoser) SampleSize() int {
+	return d.config.SampleSize
+}
+
+// Diagnose returns the health of a sensor.
+//
+// Measurements taken during maintenance or flagged suspect are left out of the fault checks, a
+// cleaning window is not a fault of the sensor. Drift is checked against the lab results first
+// and against the co-located sensors when there are too few lab results.
+//
+// sensorID: the sensor to diagnose.
+// measurements: the recent measurements of the device of the sensor, including those of the
+// co-located sensors, in any order.
+// lab: the lab results paired with measurements of the sensor by parameter, oldest first.
+func (d *Diagnoser) Diagnose(sensorID primitive.ObjectID, measurements []domain.Measurement, lab map[string][]domain.LabSensorPair) domain.SensorHealth {
+	sorted := make([]domain.Measurement, 0, len(measurements))
+	samples := 0
+	for _, m := range measurements {
+		if m.SensorID == sensorID {
+			samples++
+		}
+		if usable(m) {
+			sorted = append(sorted, m)
+		}
+	}
+	if samples == 0 {
+		return domain.SensorHealth{Status: domain.HealthUnknown}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	series := map[string][]domain.Measurement{}
+	var parameters []string
+	for _, m := range sorted {
+		if m.SensorID != sensorID {
+			continue
+		}
+		if _, ok := series[m.Parameter]; !ok {
+			parameters = append(parameters, m.Parameter)
+		}
+		series[m.Parameter] = append(series[m.Parameter], m)
+	}
+
+	var diagnostics []domain.SensorDiagnostic
+	for _, parameter := range parameters {
+		values := series[parameter]
+		if diagnostic, ok := d.stuckAtZero(values); ok {
+			diagnostics = append(diagnostics, diagnostic)
+		} else if diagnostic, ok := d.flatline(values); ok {
+			diagnostics = append(diagnostics, diagnostic)
+		}
+		if diagnostic, ok := d.jump(values); ok {
+			diagnostics = append(diagnostics, diagnostic)
+		}
+		if len(lab[parameter]) >= minLabDriftPairs {
+			continue
+		}
+		if diagnostic, ok := d.drift(sensorID, values, sorted); ok {
+			diagnostics = append(diagnostics, diagnostic)
+		}
+	}
+	labParameters := make([]string, 0, len(lab))
+	for parameter := range lab {
+		labParameters = append(labParameters, parameter)
+	}
+	sort.Strings(labParameters)
+	for _, parameter := range labParameters {
+		if diagnostic, ok := d.labDrift(parameter, lab[parameter]); ok {
+			diagnostics = append(diagnostics, diagnostic)
+		}
+	}
+	diagnostics = append(diagnostics, inconsistencies(series, sorted)...)
+
+	score := 100.0
+	for _, diagnostic := range diagnostics {
+		score -= penalties[diagnostic.Code]
+	}
+	score = math.Max(score, 0)
+	return domain.SensorHealth{
+		Score:       score,
+		Status:      status(score),
+		Samples:     samples,
+		Diagnostics: diagnostics,
+	}
+}
+
+// stuckAtZero reports whether the last ZeroCount values of the series are zero.
+func (d *Diagnoser) stuckAtZero(series []domain.Measurement) (domain.SensorDiagnostic, bool) {
+	if d.config.ZeroCount < 1 || len(series) < d.config.ZeroCount {
+		return domain.SensorDiagnostic{}, false
+	}
+	for _, m := range series[len(series)-d.config.ZeroCount:] {
+		if m.Value != 0 {
+			return domain.SensorDiagnostic{}, false
+		}
+	}
+	last := series[len(series)-1]
+	return domain.SensorDiagnostic{
+		Code:      domain.DiagnosticStuckAtZero,
+		Parameter: last.Parameter,
+		Message:   fmt.Sprintf("last %d values are zero", d.config.ZeroCount),
+		Timestamp: last.Timestamp,
+	}, true
+}
+
+// flatline reports whether the last FlatlineCount values of the series are identical.
+func (d *Diagnoser) flatline(series []domain.Measurement) (domain.SensorDiagnostic, bool) {
+	if d.config.FlatlineCount < 2 || len(series) < d.config.FlatlineCount {
+		return domain.SensorDiagnostic{}, false
+	}
+	last := series[len(series)-1]
+	for _, m := range series[len(series)-d.config.FlatlineCount:] {
+		if m.Value != last.Value {
+			return domain.SensorDiagnostic{}, false
+		}
+	}
+	return domain.SensorDiagnostic{
+		Code:      domain.DiagnosticFlatline,
+		Parameter: last.Parameter,
+		Message:   fmt.Sprintf("last %d values are %g", d.config.FlatlineCount, last.Value),
+		Timestamp: last.Timestamp,
+	}, true
+}
+
+// jump reports the changes between consecutive values of the series larger than the parameter allows.
+func (d *Diagnoser) jump(series []domain.Measurement) (domain.SensorDiagnostic, bool) {
+	maxJump, ok := d.config.MaxJump[series[0].Parameter]
+	if !ok {
+		return domain.SensorDiagnostic{}, false
+	}
+	count := 0
+	var latest domain.Measurement
+	for i := 1; i < len(series); i++ {
+		if math.Abs(series[i].Value-series[i-1].Value) > maxJump {
+			count++
+			latest = series[i]
+		}
+	}
+	if count == 0 {
+		return domain.SensorDiagnostic{}, false
+	}
+	return domain.SensorDiagnostic{
+		Code:      domain.DiagnosticJump,
+		Parameter: latest.Parameter,
+		Message:   fmt.Sprintf("%d changes larger than %g", count, maxJump),
+		Timestamp: latest.Timestamp,
+	}, true
+}
+
+// drift reports whether the offset of the series to the co-located sensors measuring the same
+// parameter changed by more than DriftThreshold of their level between the oldest and the newest
+// shared readings.
+func (d *Diagnoser) drift(sensorID primitive.ObjectID, series, measurements []domain.Measurement) (domain.SensorDiagnostic, bool) {
+	if d.config.DriftThreshold <= 0 {
+		return domain.SensorDiagnostic{}, false
+	}
+	parameter := series[0].Parameter
+	peers := map[primitive.ObjectID][]float64{}
+	for _, m := range measurements {
+		if m.Parameter == parameter && !m.SensorID.IsZero() && m.SensorID != sensorID {
+			peers[m.ReadingID] = append(peers[m.ReadingID], m.Value)
+		}
+	}
+
+	var offsets, levels []float64
+	var last time.Time
+	for _, m := range series {
+		values, ok := peers[m.ReadingID]
+		if !ok {
+			continue
+		}
+		peer := mean(values)
+		offsets = append(offsets, m.Value-peer)
+		levels = append(levels, math.Abs(peer))
+		last = m.Timestamp
+	}
+	if len(offsets) < minDriftPairs {
+		return domain.SensorDiagnostic{}, false
+	}
+	change, ok := d.offsetChange(offsets, levels)
+	if !ok {
+		return domain.SensorDiagnostic{}, false
+	}
+	return domain.SensorDiagnostic{
+		Code:      domain.DiagnosticDrift,
+		Parameter: parameter,
+		Message:   fmt.Sprintf("offset to co-located sensors changed by %.3g", change),
+		Timestamp: last,
+	}, true
+}
+
+// labDrift reports whether the offset of the sensor to the lab results of a parameter changed by
+// more than DriftThreshold of their level between the oldest and the newest results.
+func (d *Diagnoser) labDrift(parameter string, pairs []domain.LabSensorPair) (domain.SensorDiagnostic, bool) {
+	if d.config.DriftThreshold <= 0 || len(pairs) < minLabDriftPairs {
+		return domain.SensorDiagnostic{}, false
+	}
+	offsets := make([]float64, len(pairs))
+	levels := make([]float64, len(pairs))
+	for i, p := range pairs {
+		offsets[i] = p.SensorValue - p.LabValue
+		levels[i] = math.Abs(p.LabValue)
+	}
+	change, ok := d.offsetChange(offsets, levels)
+	if !ok {
+		return domain.SensorDiagnostic{}, false
+	}
+	return domain.SensorDiagnostic{
+		Code:      domain.DiagnosticDrift,
+		Parameter: parameter,
+		Message:   fmt.Sprintf("offset to lab results changed by %.3g", change),
+		Timestamp: pairs[len(pairs)-1].MeasuredAt,
+	}, true
+}
+
+// offsetChange returns the change between the mean of the first and the last third of the offsets
+// and whether it exceeds DriftThreshold of the mean level.
+func (d *Diagnoser) offsetChange(offsets, levels []float64) (float64, bool) {
+	level := mean(levels)
+	if level == 0 {
+		return 0, false
+	}
+	third := len(offsets) / 3
+	change := mean(offsets[len(offsets)-third:]) - mean(offsets[:third])
+	return change, math.Abs(change)/level > d.config.DriftThreshold
+}
+
+// usable reports whether a measurement reflects the sensor, values taken during maintenance or
+// flagged suspect are left out of the fault checks.
+func usable(m domain.Measurement) bool {
+	return !m.Maintenance && m.Quality != domain.QualitySuspect
+}
+
+// inconsistencies reports the parameter bounds involving a parameter of the sensor that are
+// broken by the readings of the device.
+func inconsistencies(series map[string][]domain.Measurement, measurements []domain.Measurement) []domain.SensorDiagnostic {
+	readings := map[primitive.ObjectID]map[string]domain.Measurement{}
+	var order []primitive.ObjectID
+	for _, m := range measurements {
+		if _, ok := readings[m.ReadingID]; !ok {
+			readings[m.ReadingID] = map[string]domain.Measurement{}
+			order = append(order, m.ReadingID)
+		}
+		readings[m.ReadingID][m.Parameter] = m
+	}
+
+	var diagnostics []domain.SensorDiagnostic
+	for _, bound := range domain.ParameterBounds {
+		_, lower := series[bound.Lower]
+		_, upper := series[bound.Upper]
+		if !lower && !upper {
+			continue
+		}
+		count := 0
+		var latest time.Time
+		for _, readingID := range order {
+			reading := readings[readingID]
+			l, ok := reading[bound.Lower]
+			if !ok {
+				continue
+			}
+			u, ok := reading[bound.Upper]
+			if !ok {
+				continue
+			}
+			if l.Value > u.Value {
+				count++
+				latest = l.Timestamp
+			}
+		}
+		if count == 0 {
+			continue
+		}
+		parameter := bound.Lower
+		if !lower {
+			parameter = bound.Upper
+		}
+		diagnostics = append(diagnostics, domain.SensorDiagnostic{
+			Code:      domain.DiagnosticInconsistent,
+			Parameter: parameter,
+			Message:   fmt.Sprintf("%s greater than %s in %d readings", bound.Lower, bound.Upper, count),
+			Timestamp: latest,
+		})
+	}
+	return diagnostics
+}
+
+// status returns the health status of a score.
+func status(score float64) string {
+	switch {
+	case score >= healthyScore:
+		return domain.HealthHealthy
+	case score >= degradedScore:
+		return domain.HealthDegraded
+	}
+	return domain.HealthFaulty
+}
+
+// mean returns the arithmetic mean of the values.
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
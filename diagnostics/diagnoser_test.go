@@ -0,0 +1,173 @@
+package diagnostics_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/diagnostics"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var diagnosticsConfig = config.DiagnosticsConfig{
+	SampleSize:     50,
+	FlatlineCount:  4,
+	ZeroCount:      3,
+	MaxJump:        map[string]float64{domain.ParamPH: 2},
+	DriftThreshold: 0.2,
+}
+
+var start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// reading returns one measurement per value sharing a reading ID, keyed by sensor
+func reading(i int, deviceID primitive.ObjectID, values map[primitive.ObjectID]map[string]float64) []domain.Measurement {
+	readingID := primitive.NewObjectID()
+	var measurements []domain.Measurement
+	for sensorID, parameters := range values {
+		for parameter, value := range parameters {
+			measurements = append(measurements, domain.Measurement{
+				ReadingID: readingID,
+				DeviceID:  deviceID,
+				SensorID:  sensorID,
+				Parameter: parameter,
+				Value:     value,
+				Timestamp: start.Add(time.Duration(i) * time.Hour),
+			})
+		}
+	}
+	return measurements
+}
+
+func codes(health domain.SensorHealth) []string {
+	var codes []string
+	for _, diagnostic := range health.Diagnostics {
+		codes = append(codes, diagnostic.Code)
+	}
+	return codes
+}
+
+func TestDiagnose(t *testing.T) {
+	d := diagnostics.NewDiagnoser(diagnosticsConfig)
+	deviceID := primitive.NewObjectID()
+	sensorID := primitive.NewObjectID()
+	peerID := primitive.NewObjectID()
+
+	t.Run("No measurements", func(t *testing.T) {
+		health := d.Diagnose(sensorID, nil, nil)
+		assert.Equal(t, domain.HealthUnknown, health.Status)
+		assert.Zero(t, health.Samples)
+	})
+	t.Run("Healthy", func(t *testing.T) {
+		var measurements []domain.Measurement
+		for i := 0; i < 12; i++ {
+			measurements = append(measurements, reading(i, deviceID, map[primitive.ObjectID]map[string]float64{
+				sensorID: {domain.ParamCOD: 100 + float64(i%3), domain.ParamBOD: 40 + float64(i%2)},
+				peerID:   {domain.ParamCOD: 101 + float64(i%3)},
+			})...)
+		}
+		health := d.Diagnose(sensorID, measurements, nil)
+		assert.Equal(t, domain.HealthHealthy, health.Status)
+		assert.Equal(t, float64(100), health.Score)
+		assert.Equal(t, 24, health.Samples)
+		assert.Empty(t, health.Diagnostics)
+	})
+	t.Run("Flatline", func(t *testing.T) {
+		var measurements []domain.Measurement
+		for i, value := range []float64{7, 7.1, 7.3, 7.3, 7.3, 7.3} {
+			measurements = append(measurements, reading(i, deviceID, map[primitive.ObjectID]map[string]float64{sensorID: {domain.ParamPH: value}})...)
+		}
+		health := d.Diagnose(sensorID, measurements, nil)
+		assert.Equal(t, []string{domain.DiagnosticFlatline}, codes(health))
+		assert.Equal(t, float64(60), health.Score)
+		assert.Equal(t, domain.HealthDegraded, health.Status)
+	})
+	t.Run("Stuck at zero", func(t *testing.T) {
+		var measurements []domain.Measurement
+		for i, value := range []float64{12, 11, 0, 0, 0, 0} {
+			measurements = append(measurements, reading(i, deviceID, map[primitive.ObjectID]map[string]float64{sensorID: {domain.ParamTurbidity: value}})...)
+		}
+		health := d.Diagnose(sensorID, measurements, nil)
+		assert.Equal(t, []string{domain.DiagnosticStuckAtZero}, codes(health))
+	})
+	t.Run("Jump", func(t *testing.T) {
+		var measurements []domain.Measurement
+		for i, value := range []float64{7, 7.1, 11, 7.2, 7} {
+			measurements = append(measurements, reading(i, deviceID, map[primitive.ObjectID]map[string]float64{sensorID: {domain.ParamPH: value}})...)
+		}
+		health := d.Diagnose(sensorID, measurements, nil)
+		assert.Equal(t, []string{domain.DiagnosticJump}, codes(health))
+		assert.Equal(t, start.Add(3*time.Hour), health.Diagnostics[0].Timestamp)
+	})
+	t.Run("Drift", func(t *testing.T) {
+		var measurements []domain.Measurement
+		for i := 0; i < 12; i++ {
+			measurements = append(measurements, reading(i, deviceID, map[primitive.ObjectID]map[string]float64{
+				sensorID: {domain.ParamCOD: 100 + float64(i)*5 + float64(i%2)},
+				peerID:   {domain.ParamCOD: 100 + float64(i%2)},
+			})...)
+		}
+		health := d.Diagnose(sensorID, measurements, nil)
+		assert.Equal(t, []string{domain.DiagnosticDrift}, codes(health))
+		assert.Equal(t, domain.ParamCOD, health.Diagnostics[0].Parameter)
+	})
+	t.Run("Lab drift", func(t *testing.T) {
+		var measurements []domain.Measurement
+		var pairs []domain.LabSensorPair
+		for i := 0; i < 6; i++ {
+			measurements = append(measurements, reading(i, deviceID, map[primitive.ObjectID]map[string]float64{
+				sensorID: {domain.ParamCOD: 100 + float64(i%2)},
+				peerID:   {domain.ParamCOD: 100 + float64(i%2)},
+			})...)
+			pairs = append(pairs, domain.LabSensorPair{
+				SampleID:    fmt.Sprintf("LAB-%d", i),
+				MeasuredAt:  start.Add(time.Duration(i) * time.Hour),
+				LabValue:    100 - float64(i)*10,
+				SensorValue: 100,
+			})
+		}
+		health := d.Diagnose(sensorID, measurements, map[string][]domain.LabSensorPair{domain.ParamCOD: pairs})
+		assert.Equal(t, []string{domain.DiagnosticDrift}, codes(health))
+		assert.Equal(t, domain.ParamCOD, health.Diagnostics[0].Parameter)
+		assert.Contains(t, health.Diagnostics[0].Message, "lab results")
+		assert.Equal(t, start.Add(5*time.Hour), health.Diagnostics[0].Timestamp)
+	})
+	t.Run("Too few lab results", func(t *testing.T) {
+		pairs := []domain.LabSensorPair{{LabValue: 100, SensorValue: 100}, {LabValue: 50, SensorValue: 100}}
+		measurements := reading(0, deviceID, map[primitive.ObjectID]map[string]float64{sensorID: {domain.ParamCOD: 100}})
+		health := d.Diagnose(sensorID, measurements, map[string][]domain.LabSensorPair{domain.ParamCOD: pairs})
+		assert.Empty(t, health.Diagnostics)
+	})
+	t.Run("Maintenance and suspect ignored", func(t *testing.T) {
+		var measurements []domain.Measurement
+		for i, value := range []float64{7, 7.1, 0, 0, 0, 0, 7.2} {
+			m := reading(i, deviceID, map[primitive.ObjectID]map[string]float64{sensorID: {domain.ParamPH: value}})
+			if value == 0 {
+				m[0].Maintenance = i%2 == 0
+				if !m[0].Maintenance {
+					m[0].Quality = domain.QualitySuspect
+				}
+			}
+			measurements = append(measurements, m...)
+		}
+		health := d.Diagnose(sensorID, measurements, nil)
+		assert.Equal(t, domain.HealthHealthy, health.Status)
+		assert.Empty(t, health.Diagnostics)
+		assert.Equal(t, 7, health.Samples)
+	})
+	t.Run("Inconsistent", func(t *testing.T) {
+		var measurements []domain.Measurement
+		for i := 0; i < 3; i++ {
+			measurements = append(measurements, reading(i, deviceID, map[primitive.ObjectID]map[string]float64{
+				sensorID: {domain.ParamBOD: 150 + float64(i), domain.ParamTOC: 20 + float64(i), domain.ParamDOC: 10 + float64(i)},
+				peerID:   {domain.ParamCOD: 100 + float64(i)},
+			})...)
+		}
+		health := d.Diagnose(sensorID, measurements, nil)
+		assert.Equal(t, []string{domain.DiagnosticInconsistent}, codes(health))
+		assert.Equal(t, domain.ParamBOD, health.Diagnostics[0].Parameter)
+		assert.Contains(t, health.Diagnostics[0].Message, "in 3 readings")
+	})
+}
@@ -1,17 +1,9 @@
-// Package docs GENERATED BY THE COMMAND ABOVE; DO NOT EDIT
-// This file was generated by swaggo/swag
+// Package docs Code generated by swaggo/swag. DO NOT EDIT
 package docs
 
-import (
-	"bytes"
-	"encoding/json"
-	"strings"
-	"text/template"
+import "github.com/swaggo/swag"
 
-	"github.com/swaggo/swag"
-)
-
-var doc = `{
+const docTemplate = `{
     "schemes": {{ marshal .Schemes }},
     "swagger": "2.0",
     "info": {
@@ -30,9 +22,9 @@ var doc = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/device": {
+        "/anomalies": {
             "get": {
-                "description": "get all device data",
+                "description": "get the anomaly events scored on incoming measurements, filtered by device, parameter and time range",
                 "consumes": [
                     "application/json"
                 ],
@@ -40,28 +32,63 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "device"
+                    "anomaly"
                 ],
-                "summary": "get all device data",
+                "summary": "get anomalies",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device ID",
+                        "name": "device_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Parameter code",
+                        "name": "parameter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the time range (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the time range (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "description": "Page number",
                         "name": "page",
-                        "in": "query",
-                        "required": true
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Device data",
+                        "description": "Anomaly events",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/domain.Device"
+                                "$ref": "#/definitions/domain.AnomalyEvent"
                             }
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -69,9 +96,11 @@ var doc = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "create device data",
+            }
+        },
+        "/calibration/overdue": {
+            "get": {
+                "description": "get the latest calibration of each sensor parameter whose next calibration was due before the given time, most overdue first",
                 "consumes": [
                     "application/json"
                 ],
@@ -79,25 +108,37 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "device"
+                    "sensor"
                 ],
-                "summary": "create device data",
+                "summary": "get overdue calibrations",
                 "parameters": [
                     {
-                        "description": "device data",
-                        "name": "waste_water",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.Device"
-                        }
+                        "type": "string",
+                        "description": "Reference time (RFC 3339), defaults to now",
+                        "name": "at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Device"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Calibration"
+                            }
                         }
                     },
                     "400": {
@@ -115,9 +156,9 @@ var doc = `{
                 }
             }
         },
-        "/device/{id}": {
+        "/device": {
             "get": {
-                "description": "get device data by id",
+                "description": "get all device data",
                 "consumes": [
                     "application/json"
                 ],
@@ -127,25 +168,58 @@ var doc = `{
                 "tags": [
                     "device"
                 ],
-                "summary": "get device data by id",
+                "summary": "get all device data",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Device data ID",
-                        "name": "id",
-                        "in": "path",
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Lifecycle status (procured, installed, active, maintenance, decommissioned)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Device model",
+                        "name": "model",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Site ID",
+                        "name": "site_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Center point formatted as lat,lng",
+                        "name": "near",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Search radius in meters, defaults to 1000",
+                        "name": "radius",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Device data",
                         "schema": {
-                            "$ref": "#/definitions/domain.Device"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Device"
+                            }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/rest.ResponseError"
                         }
@@ -158,8 +232,8 @@ var doc = `{
                     }
                 }
             },
-            "put": {
-                "description": "update device data",
+            "post": {
+                "description": "create device data",
                 "consumes": [
                     "application/json"
                 ],
@@ -169,7 +243,7 @@ var doc = `{
                 "tags": [
                     "device"
                 ],
-                "summary": "update device data",
+                "summary": "create device data",
                 "parameters": [
                     {
                         "description": "device data",
@@ -182,8 +256,8 @@ var doc = `{
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "$ref": "#/definitions/domain.Device"
                         }
@@ -194,12 +268,6 @@ var doc = `{
                             "$ref": "#/definitions/rest.ResponseError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/rest.ResponseError"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -207,9 +275,11 @@ var doc = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "delete device data",
+            }
+        },
+        "/device/geojson": {
+            "get": {
+                "description": "get located devices and their latest readings as a GeoJSON FeatureCollection",
                 "consumes": [
                     "application/json"
                 ],
@@ -219,22 +289,36 @@ var doc = `{
                 "tags": [
                     "device"
                 ],
-                "summary": "delete device data",
+                "summary": "get devices as GeoJSON",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "Device data ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Center point formatted as lat,lng",
+                        "name": "near",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Search radius in meters, defaults to 1000",
+                        "name": "radius",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": ""
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.FeatureCollection"
+                        }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/rest.ResponseError"
                         }
@@ -248,9 +332,9 @@ var doc = `{
                 }
             }
         },
-        "/sensor": {
+        "/device/inventory": {
             "get": {
-                "description": "get all sensor data",
+                "description": "count the devices per lifecycle status and model",
                 "consumes": [
                     "application/json"
                 ],
@@ -258,25 +342,16 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "sensor"
-                ],
-                "summary": "get all sensor data",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query",
-                        "required": true
-                    }
+                    "device"
                 ],
+                "summary": "get device inventory",
                 "responses": {
                     "200": {
-                        "description": "Sensor data",
+                        "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/domain.Sensor"
+                                "$ref": "#/definitions/domain.InventoryCount"
                             }
                         }
                     },
@@ -287,55 +362,11 @@ var doc = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "create sensor data",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "sensor"
-                ],
-                "summary": "create sensor data",
-                "parameters": [
-                    {
-                        "description": "sensor data",
-                        "name": "waste_water",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.Sensor"
-                        }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/domain.Sensor"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/rest.ResponseError"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/rest.ResponseError"
-                        }
-                    }
-                }
             }
         },
-        "/sensor/{id}": {
+        "/device/{id}": {
             "get": {
-                "description": "get sensor data by id",
+                "description": "get device data by id",
                 "consumes": [
                     "application/json"
                 ],
@@ -343,13 +374,13 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "sensor"
+                    "device"
                 ],
-                "summary": "get sensor data by id",
+                "summary": "get device data by id",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Sensor data ID",
+                        "description": "Device data ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -359,7 +390,7 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Sensor"
+                            "$ref": "#/definitions/domain.Device"
                         }
                     },
                     "404": {
@@ -377,7 +408,7 @@ var doc = `{
                 }
             },
             "put": {
-                "description": "update sensor data",
+                "description": "update device data, the site and location only change when the device is installed, use the transition endpoint to move it",
                 "consumes": [
                     "application/json"
                 ],
@@ -385,17 +416,17 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "sensor"
+                    "device"
                 ],
-                "summary": "update sensor data",
+                "summary": "update device data",
                 "parameters": [
                     {
-                        "description": "sensor data",
+                        "description": "device data",
                         "name": "waste_water",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.Sensor"
+                            "$ref": "#/definitions/domain.Device"
                         }
                     }
                 ],
@@ -403,7 +434,7 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Sensor"
+                            "$ref": "#/definitions/domain.Device"
                         }
                     },
                     "400": {
@@ -418,6 +449,12 @@ var doc = `{
                             "$ref": "#/definitions/rest.ResponseError"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -427,7 +464,7 @@ var doc = `{
                 }
             },
             "delete": {
-                "description": "delete sensor data",
+                "description": "delete device data",
                 "consumes": [
                     "application/json"
                 ],
@@ -435,13 +472,13 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "sensor"
+                    "device"
                 ],
-                "summary": "delete sensor data",
+                "summary": "delete device data",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Sensor data ID",
+                        "description": "Device data ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -449,7 +486,7 @@ var doc = `{
                 ],
                 "responses": {
                     "204": {
-                        "description": ""
+                        "description": "No Content"
                     },
                     "404": {
                         "description": "Not Found",
@@ -466,9 +503,9 @@ var doc = `{
                 }
             }
         },
-        "/waste-water": {
+        "/device/{id}/commands": {
             "get": {
-                "description": "get all waste water data",
+                "description": "get the commands sent to a device, newest first",
                 "consumes": [
                     "application/json"
                 ],
@@ -476,28 +513,52 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "waste water"
+                    "device command"
                 ],
-                "summary": "get all waste water data",
+                "summary": "get device command history",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Status (queued, sent, acknowledged, succeeded, failed, expired, timed_out)",
+                        "name": "status",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "description": "Page number",
                         "name": "page",
-                        "in": "query",
-                        "required": true
-                    }
-                ],
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "Waste water data",
+                        "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/domain.WasteWaterData"
+                                "$ref": "#/definitions/domain.Command"
                             }
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -507,7 +568,7 @@ var doc = `{
                 }
             },
             "post": {
-                "description": "create waste water data",
+                "description": "queue a one-off command such as flush_sampler, reboot or grab_sample and publish it to the device over MQTT; the command expires when the device does not acknowledge it within its TTL and times out when no result follows within its timeout",
                 "consumes": [
                     "application/json"
                 ],
@@ -515,17 +576,24 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "waste water"
+                    "device command"
                 ],
-                "summary": "create waste water data",
+                "summary": "send a command to a device",
                 "parameters": [
                     {
-                        "description": "waste water data",
-                        "name": "waste_water",
+                        "type": "string",
+                        "description": "Device ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "command name, parameters, TTL and timeout in seconds",
+                        "name": "command",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.WasteWaterData"
+                            "$ref": "#/definitions/domain.CommandRequest"
                         }
                     }
                 ],
@@ -533,7 +601,7 @@ var doc = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/domain.WasteWaterData"
+                            "$ref": "#/definitions/domain.Command"
                         }
                     },
                     "400": {
@@ -542,6 +610,18 @@ var doc = `{
                             "$ref": "#/definitions/rest.ResponseError"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -551,9 +631,9 @@ var doc = `{
                 }
             }
         },
-        "/waste-water/{id}": {
+        "/device/{id}/commands/{commandID}": {
             "get": {
-                "description": "get waste water data by id",
+                "description": "get a command with its delivery, acknowledgement and result",
                 "consumes": [
                     "application/json"
                 ],
@@ -561,23 +641,30 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "waste water"
+                    "device command"
                 ],
-                "summary": "get waste water data by id",
+                "summary": "get device command",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Waste water data ID",
+                        "description": "Device ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Command ID",
+                        "name": "commandID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.WasteWaterData"
+                            "$ref": "#/definitions/domain.Command"
                         }
                     },
                     "404": {
@@ -593,9 +680,155 @@ var doc = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/device/{id}/modbus/poll": {
+            "post": {
+                "description": "read the registers mapped by the sensor types of the device over its Modbus endpoint and store them as a waste water reading, e.g. to check a register map while commissioning",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "device"
+                ],
+                "summary": "poll a Modbus device",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WastewaterDataRequest"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/device/{id}/psk": {
+            "post": {
+                "description": "issue a new DTLS pre-shared key for CoAP to a device, replacing the previous one; the key is only returned here",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "device"
+                ],
+                "summary": "issue device pre-shared key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.PSKSecret"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/device/{id}/shadow": {
+            "get": {
+                "description": "get the desired and reported configuration of a device and the delta between them",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "device shadow"
+                ],
+                "summary": "get device shadow",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Shadow"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/device/{id}/shadow/desired": {
             "put": {
-                "description": "update waste water data",
+                "description": "merge keys into the desired configuration of a device, a null value removes a key; the resulting delta is delivered to the device over MQTT and to its CoAP observers",
                 "consumes": [
                     "application/json"
                 ],
@@ -603,17 +836,24 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "waste water"
+                    "device shadow"
                 ],
-                "summary": "update waste water data",
+                "summary": "update desired device configuration",
                 "parameters": [
                     {
-                        "description": "waste water data",
-                        "name": "waste_water",
+                        "type": "string",
+                        "description": "Device ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "desired keys and the shadow version they were made against",
+                        "name": "update",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.WasteWaterData"
+                            "$ref": "#/definitions/domain.ShadowUpdate"
                         }
                     }
                 ],
@@ -621,7 +861,7 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.WasteWaterData"
+                            "$ref": "#/definitions/domain.Shadow"
                         }
                     },
                     "400": {
@@ -636,6 +876,12 @@ var doc = `{
                             "$ref": "#/definitions/rest.ResponseError"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -643,9 +889,11 @@ var doc = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "delete waste water data",
+            }
+        },
+        "/device/{id}/transition": {
+            "post": {
+                "description": "move a device to another lifecycle status, installing it records a new entry in its install history",
                 "consumes": [
                     "application/json"
                 ],
@@ -653,21 +901,39 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "waste water"
+                    "device"
                 ],
-                "summary": "delete waste water data",
+                "summary": "change device lifecycle status",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Waste water data ID",
+                        "description": "Device data ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "requested status",
+                        "name": "transition",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.DeviceTransition"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": ""
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Device"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
                     },
                     "404": {
                         "description": "Not Found",
@@ -675,6 +941,12 @@ var doc = `{
                             "$ref": "#/definitions/rest.ResponseError"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -683,62 +955,5022 @@ var doc = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "domain.ColiformsData": {
-            "type": "object",
-            "properties": {
-                "E_coli": {
-                    "type": "number"
-                },
-                "fecal": {
-                    "type": "number"
-                },
-                "total": {
-                    "type": "number"
-                }
-            }
         },
-        "domain.Device": {
-            "type": "object",
-            "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "description": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "updated_at": {
-                    "type": "string"
+        "/device/{id}/uplinks": {
+            "get": {
+                "description": "get the LoRaWAN uplinks of a device with their radio metadata and decoded values, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lorawan"
+                ],
+                "summary": "get device uplinks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.UplinkRecord"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/graphql": {
+            "post": {
+                "description": "run a query of the GraphQL schema, e.g. a site with its devices, their sensors and latest readings; subscriptions use a WebSocket on the same path with the graphql-transport-ws subprotocol",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "graphql"
+                ],
+                "summary": "GraphQL query",
+                "parameters": [
+                    {
+                        "description": "query, variables and operation name",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/graphql.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/ingest/mappings": {
+            "get": {
+                "description": "get the mapping of every gateway",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ingest"
+                ],
+                "summary": "get ingest mappings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.IngestMapping"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/ingest/mappings/{source}": {
+            "put": {
+                "description": "set the devices, or sensors, and the parameters the names sent by a gateway are mapped onto",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ingest"
+                ],
+                "summary": "save an ingest mapping",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Gateway name",
+                        "name": "source",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "device and parameter mappings",
+                        "name": "mapping",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.IngestMapping"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.IngestMapping"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete the mapping of a gateway, its payloads are no longer accepted",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ingest"
+                ],
+                "summary": "delete an ingest mapping",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Gateway name",
+                        "name": "source",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/ingest/{source}/influx": {
+            "post": {
+                "description": "store the numeric fields of InfluxDB line protocol; the device is named by a tag and each field is mapped onto a parameter with the mapping of the gateway, fields are grouped into one reading per device and time and lines left out are reported with their number",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ingest"
+                ],
+                "summary": "ingest InfluxDB line protocol",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Gateway name",
+                        "name": "source",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timestamp precision (ns, us, ms, s)",
+                        "name": "precision",
+                        "in": "query"
+                    },
+                    {
+                        "description": "line protocol",
+                        "name": "lines",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.IngestResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/ingest/{source}/senml": {
+            "post": {
+                "description": "store the records of a SenML (RFC 8428) pack in JSON or CBOR; records are mapped onto devices and parameters with the mapping of the gateway and grouped into one reading per device and time, records left out are reported with their position",
+                "consumes": [
+                    "application/json",
+                    "application/senml+json",
+                    "application/senml+cbor"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ingest"
+                ],
+                "summary": "ingest a SenML pack",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Gateway name",
+                        "name": "source",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "SenML pack",
+                        "name": "pack",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.SenMLRecord"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.IngestResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/lab-sample": {
+            "get": {
+                "description": "get the lab samples, optionally of a site and sampling period",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lab sample"
+                ],
+                "summary": "get all lab sample data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Site ID",
+                        "name": "site_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the sampling period (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the sampling period (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Lab sample data",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.LabSample"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "create a lab sample with its results and chain-of-custody status",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lab sample"
+                ],
+                "summary": "create lab sample data",
+                "parameters": [
+                    {
+                        "description": "lab sample data",
+                        "name": "lab_sample",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.LabSampleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.LabSampleRequest"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/lab-sample/correlation": {
+            "get": {
+                "description": "pair the lab results of a site with the nearest sensor readings and report bias and regression statistics per parameter",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lab sample"
+                ],
+                "summary": "correlate lab samples with sensor readings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Site ID",
+                        "name": "site_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Parameter code",
+                        "name": "parameter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the sampling period (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the sampling period (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Largest time between a sample and its reading, e.g. 30m (default 1h)",
+                        "name": "max_gap",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.LabCorrelation"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/lab-sample/{id}": {
+            "get": {
+                "description": "get lab sample data by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lab sample"
+                ],
+                "summary": "get lab sample data by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Lab sample data ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.LabSample"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "update lab sample data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lab sample"
+                ],
+                "summary": "update lab sample data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Lab sample data ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "lab sample data",
+                        "name": "lab_sample",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.LabSample"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.LabSample"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete lab sample data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lab sample"
+                ],
+                "summary": "delete lab sample data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Lab sample data ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/lorawan/chirpstack": {
+            "post": {
+                "description": "webhook of a ChirpStack v4 HTTP integration with JSON marshaling; up events are decoded with the codec of the device model and stored with their radio metadata, other events are acknowledged and ignored",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lorawan"
+                ],
+                "summary": "receive a ChirpStack uplink",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event type, up for uplinks",
+                        "name": "event",
+                        "in": "query"
+                    },
+                    {
+                        "description": "up event",
+                        "name": "uplink",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.ChirpStackUplink"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "uplink stored with its reading",
+                        "schema": {
+                            "$ref": "#/definitions/domain.UplinkRecord"
+                        }
+                    },
+                    "202": {
+                        "description": "uplink stored without reading, see its error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.UplinkRecord"
+                        }
+                    },
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/lorawan/codecs": {
+            "get": {
+                "description": "get the payload codecs of every device model",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lorawan"
+                ],
+                "summary": "get payload codecs",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.PayloadCodec"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/lorawan/codecs/{model}": {
+            "put": {
+                "description": "set how the uplink payloads of the devices of a model are decoded, with Cayenne LPP channels or a byte layout",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lorawan"
+                ],
+                "summary": "save a payload codec",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device model",
+                        "name": "model",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "codec type with its channels or fields",
+                        "name": "codec",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.PayloadCodec"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.PayloadCodec"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/lorawan/tts": {
+            "post": {
+                "description": "webhook of The Things Stack with the JSON format; uplink messages are decoded with the codec of the device model and stored with their radio metadata, other messages are acknowledged and ignored",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lorawan"
+                ],
+                "summary": "receive a The Things Stack uplink",
+                "parameters": [
+                    {
+                        "description": "uplink message",
+                        "name": "uplink",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.TTSUplink"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "uplink stored with its reading",
+                        "schema": {
+                            "$ref": "#/definitions/domain.UplinkRecord"
+                        }
+                    },
+                    "202": {
+                        "description": "uplink stored without reading, see its error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.UplinkRecord"
+                        }
+                    },
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/maintenance/calendar": {
+            "get": {
+                "description": "list the scheduled work and the downtime windows of the work orders overlapping a period, ordered by start",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "summary": "get maintenance calendar",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device ID",
+                        "name": "device_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Status (planned, in_progress, done)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the period (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the period (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.CalendarEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/measurement": {
+            "get": {
+                "description": "get measurements filtered by device, sensor, parameter, quality and time range",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "measurement"
+                ],
+                "summary": "get measurements",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device ID",
+                        "name": "device_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sensor ID",
+                        "name": "sensor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Parameter code",
+                        "name": "parameter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated quality flags, e.g. validated,approved",
+                        "name": "quality",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the time range (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the time range (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unit system of the returned values (metric, imperial)",
+                        "name": "units",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Measurements",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Measurement"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "DeviceAuth": []
+                    }
+                ],
+                "description": "create a batch of measurements of the device authenticated with its credentials, any parameter code is accepted",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "measurement"
+                ],
+                "summary": "create measurements",
+                "parameters": [
+                    {
+                        "description": "measurements",
+                        "name": "measurements",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Measurement"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Measurement"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/measurement/review": {
+            "post": {
+                "description": "set the quality flag (suspect, invalid, validated, approved) of measurements with a reason, only validated measurements can be approved",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "measurement"
+                ],
+                "summary": "review measurement quality",
+                "parameters": [
+                    {
+                        "description": "quality review",
+                        "name": "review",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.QualityReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.QualityReview"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/measurement/{id}/review": {
+            "get": {
+                "description": "get the quality review audit trail of a measurement, oldest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "measurement"
+                ],
+                "summary": "get measurement quality reviews",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Measurement ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.QualityReview"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/mqtt/auth/acl": {
+            "post": {
+                "description": "called by the broker when a device publishes or subscribes, a device only reaches the topics under its own ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mqtt"
+                ],
+                "summary": "check MQTT topic access",
+                "parameters": [
+                    {
+                        "description": "username, clientid, topic and acc",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rest.MQTTAuthRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    }
+                }
+            }
+        },
+        "/mqtt/auth/superuser": {
+            "post": {
+                "description": "called by the broker to learn whether a client is the API, which reaches every topic",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mqtt"
+                ],
+                "summary": "check MQTT superuser",
+                "parameters": [
+                    {
+                        "description": "username",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rest.MQTTAuthRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    }
+                }
+            }
+        },
+        "/mqtt/auth/user": {
+            "post": {
+                "description": "called by the broker when a client connects: the API with its own credentials, a device with its provisioned credentials and its ID as client ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mqtt"
+                ],
+                "summary": "check MQTT client credentials",
+                "parameters": [
+                    {
+                        "description": "username, password and clientid",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rest.MQTTAuthRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/provisioning/claims": {
+            "get": {
+                "description": "get the provisioning claims oldest first, status=pending lists the approval queue",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "provisioning"
+                ],
+                "summary": "get provisioning claims",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Status (pending, approved, rejected, claimed)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.ProvisioningClaim"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "register a factory serial number and its claim token so that the device is provisioned without approval",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "provisioning"
+                ],
+                "summary": "pre-register a device",
+                "parameters": [
+                    {
+                        "description": "serial number, claim token and the name, model and site of the device",
+                        "name": "claim",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.ClaimRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProvisioningClaim"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/provisioning/claims/{id}/approve": {
+            "post": {
+                "description": "approve a device waiting in the approval queue, it is provisioned the next time it registers",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "provisioning"
+                ],
+                "summary": "approve a provisioning claim",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Claim ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "name, model and site of the device",
+                        "name": "decision",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ClaimDecision"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProvisioningClaim"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/provisioning/claims/{id}/reject": {
+            "post": {
+                "description": "reject a device waiting in the approval queue, its registrations are refused from then on",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "provisioning"
+                ],
+                "summary": "reject a provisioning claim",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Claim ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "who rejected the claim",
+                        "name": "decision",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ClaimDecision"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProvisioningClaim"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/provisioning/register": {
+            "post": {
+                "description": "called by a device with its factory serial number and one-time claim token; an approved claim creates the device, its sensors and credentials and returns its configuration, an unknown serial number is queued for approval and answered 202 until approved",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "provisioning"
+                ],
+                "summary": "register a device",
+                "parameters": [
+                    {
+                        "description": "serial number, claim token and hardware of the device",
+                        "name": "registration",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.RegistrationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Registration"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Registration"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/provisioning/templates": {
+            "get": {
+                "description": "get the templates of every device model",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "provisioning"
+                ],
+                "summary": "get device templates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.DeviceTemplate"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/provisioning/templates/{model}": {
+            "put": {
+                "description": "set the sensors created on and the initial configuration sent to the devices of a model when they register",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "provisioning"
+                ],
+                "summary": "save a device template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device model",
+                        "name": "model",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "sensors and configuration of the model",
+                        "name": "template",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.DeviceTemplate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.DeviceTemplate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sensor": {
+            "get": {
+                "description": "get all sensor data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sensor"
+                ],
+                "summary": "get all sensor data",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Sensor data",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Sensor"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "create sensor data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sensor"
+                ],
+                "summary": "create sensor data",
+                "parameters": [
+                    {
+                        "description": "sensor data",
+                        "name": "waste_water",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Sensor"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Sensor"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sensor-type": {
+            "get": {
+                "description": "get all sensor type data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sensor type"
+                ],
+                "summary": "get all sensor type data",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Sensor type data",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.SensorType"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "create sensor type data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sensor type"
+                ],
+                "summary": "create sensor type data",
+                "parameters": [
+                    {
+                        "description": "sensor type data",
+                        "name": "sensor_type",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.SensorTypeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SensorTypeRequest"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sensor-type/{id}": {
+            "get": {
+                "description": "get sensor type data by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sensor type"
+                ],
+                "summary": "get sensor type data by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sensor type data ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SensorType"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "update sensor type data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sensor type"
+                ],
+                "summary": "update sensor type data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sensor type data ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "sensor type data",
+                        "name": "sensor_type",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.SensorType"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SensorType"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete sensor type data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sensor type"
+                ],
+                "summary": "delete sensor type data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sensor type data ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sensor/{id}": {
+            "get": {
+                "description": "get sensor data by id with its health score and fault diagnostics",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sensor"
+                ],
+                "summary": "get sensor data by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sensor data ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Sensor"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "update sensor data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sensor"
+                ],
+                "summary": "update sensor data",
+                "parameters": [
+                    {
+                        "description": "sensor data",
+                        "name": "waste_water",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Sensor"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Sensor"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete sensor data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sensor"
+                ],
+                "summary": "delete sensor data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sensor data ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sensor/{id}/calibration": {
+            "get": {
+                "description": "get the calibration history of a sensor, latest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sensor"
+                ],
+                "summary": "get sensor calibrations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sensor ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Parameter code",
+                        "name": "parameter",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Calibration"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "record a calibration (offset/slope or multi-point curve) applied to the values the sensor reports from calibrated_at onwards",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sensor"
+                ],
+                "summary": "record sensor calibration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sensor ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "calibration",
+                        "name": "calibration",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Calibration"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Calibration"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/site": {
+            "get": {
+                "description": "get all site data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "site"
+                ],
+                "summary": "get all site data",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Site data",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Site"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "create site data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "site"
+                ],
+                "summary": "create site data",
+                "parameters": [
+                    {
+                        "description": "site data",
+                        "name": "site",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.SiteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SiteRequest"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/site/{id}": {
+            "get": {
+                "description": "get site data by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "site"
+                ],
+                "summary": "get site data by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Site data ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Site"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "update site data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "site"
+                ],
+                "summary": "update site data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Site data ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "site data",
+                        "name": "site",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Site"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Site"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete site data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "site"
+                ],
+                "summary": "delete site data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Site data ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/site/{id}/devices": {
+            "get": {
+                "description": "get the devices assigned to a site",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "site"
+                ],
+                "summary": "get devices of a site",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Site data ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Device data",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Device"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/waste-water": {
+            "get": {
+                "description": "get all waste water data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "waste water"
+                ],
+                "summary": "get all waste water data",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unit system of the returned values (metric, imperial)",
+                        "name": "units",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Waste water data",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.WasteWaterData"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "DeviceAuth": []
+                    }
+                ],
+                "description": "create waste water data of the device authenticated with its credentials, a reading without device_id belongs to that device",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "waste water"
+                ],
+                "summary": "create waste water data",
+                "parameters": [
+                    {
+                        "description": "waste water data",
+                        "name": "waste_water",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.WasteWaterData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WasteWaterData"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/waste-water/batch": {
+            "post": {
+                "security": [
+                    {
+                        "DeviceAuth": []
+                    }
+                ],
+                "description": "store up to 500 readings of the authenticated device at once and report the outcome of each, readings with a dedup key already stored are reported as duplicate; rejected readings are invalid or of another device while failed ones can be sent again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "waste water"
+                ],
+                "summary": "create a batch of waste water data",
+                "parameters": [
+                    {
+                        "description": "readings with their dedup keys",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.WasteWaterBatch"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WasteWaterBatchResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/waste-water/{id}": {
+            "get": {
+                "description": "get waste water data by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "waste water"
+                ],
+                "summary": "get waste water data by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Waste water data ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unit system of the returned values (metric, imperial)",
+                        "name": "units",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WasteWaterData"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "update waste water data, values are stored as sent without calibration; unchanged values keep their quality flag; changing or removing a value a reviewer flagged is refused",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "waste water"
+                ],
+                "summary": "update waste water data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Waste water data ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "waste water data",
+                        "name": "waste_water",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.WasteWaterData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WasteWaterData"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete waste water data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "waste water"
+                ],
+                "summary": "delete waste water data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Waste water data ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-order": {
+            "get": {
+                "description": "get the work orders, optionally of a device, a status and a period their schedule or downtime overlaps",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "summary": "get all work orders",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device ID",
+                        "name": "device_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Status (planned, in_progress, done)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the period (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the period (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.WorkOrder"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "record a maintenance work order, the readings of the device taken during its downtime are marked as maintenance affected",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "summary": "create work order",
+                "parameters": [
+                    {
+                        "description": "work order",
+                        "name": "work_order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.WorkOrder"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WorkOrder"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-order/{id}": {
+            "get": {
+                "description": "get work order by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "summary": "get work order by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Work order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WorkOrder"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "update a work order, the maintenance marks of its downtime are recomputed. The status moves one step at a time from planned to in_progress to done, the creation, start and completion times are kept",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "summary": "update work order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Work order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "work order",
+                        "name": "work_order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.WorkOrder"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WorkOrder"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete a work order and remove the maintenance marks of its downtime",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "summary": "delete work order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Work order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "domain.AnomalyEvent": {
+            "type": "object",
+            "properties": {
+                "device_id": {
+                    "type": "string"
+                },
+                "expected": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "measurement_id": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string",
+                    "example": "mad"
+                },
+                "parameter": {
+                    "type": "string",
+                    "example": "COD"
+                },
+                "reading_id": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "number",
+                    "example": 5.3
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.Attachment": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string",
+                    "example": "image/jpeg"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "probe.jpg"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "uploaded_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.BatchItemResult": {
+            "type": "object",
+            "properties": {
+                "dedup_key": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "created"
+                }
+            }
+        },
+        "domain.ByteField": {
+            "type": "object",
+            "properties": {
+                "byte_order": {
+                    "type": "string",
+                    "example": "big"
+                },
+                "data_type": {
+                    "type": "string",
+                    "example": "uint16"
+                },
+                "offset": {
+                    "type": "number"
+                },
+                "parameter": {
+                    "type": "string",
+                    "example": "pH"
+                },
+                "scale": {
+                    "type": "number",
+                    "example": 0.01
+                },
+                "start": {
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "domain.CalendarEntry": {
+            "type": "object",
+            "properties": {
+                "assignees": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "device_id": {
+                    "type": "string"
+                },
+                "downtime": {
+                    "type": "boolean"
+                },
+                "end": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "planned"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "cleaning"
+                },
+                "work_order_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Calibration": {
+            "type": "object",
+            "properties": {
+                "calibrated_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "next_due_at": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "number",
+                    "example": 0.05
+                },
+                "parameter": {
+                    "type": "string",
+                    "example": "pH"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.CalibrationPoint"
+                    }
+                },
+                "sensor_id": {
+                    "type": "string"
+                },
+                "slope": {
+                    "type": "number",
+                    "example": 1
+                },
+                "standards": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ReferenceStandard"
+                    }
+                },
+                "technician": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.CalibrationPoint": {
+            "type": "object",
+            "properties": {
+                "raw": {
+                    "type": "number",
+                    "example": 6.92
+                },
+                "reference": {
+                    "type": "number",
+                    "example": 7
+                }
+            }
+        },
+        "domain.ChirpStackUplink": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "string",
+                    "format": "base64"
+                },
+                "deduplicationId": {
+                    "type": "string"
+                },
+                "deviceInfo": {
+                    "type": "object",
+                    "properties": {
+                        "devEui": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "fCnt": {
+                    "type": "integer"
+                },
+                "fPort": {
+                    "type": "integer"
+                },
+                "rxInfo": {
+                    "type": "array",
+                    "items": {
+                        "type": "object",
+                        "properties": {
+                            "gatewayId": {
+                                "type": "string"
+                            },
+                            "rssi": {
+                                "type": "integer"
+                            },
+                            "snr": {
+                                "type": "number"
+                            }
+                        }
+                    }
+                },
+                "time": {
+                    "type": "string"
+                },
+                "txInfo": {
+                    "type": "object",
+                    "properties": {
+                        "frequency": {
+                            "type": "integer"
+                        },
+                        "modulation": {
+                            "type": "object",
+                            "properties": {
+                                "lora": {
+                                    "type": "object",
+                                    "properties": {
+                                        "spreadingFactor": {
+                                            "type": "integer"
+                                        }
+                                    }
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "domain.ClaimDecision": {
+            "type": "object",
+            "properties": {
+                "decided_by": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string",
+                    "example": "WQ-5"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "site_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ClaimRequest": {
+            "type": "object",
+            "properties": {
+                "claim_token": {
+                    "type": "string"
+                },
+                "decided_by": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string",
+                    "example": "WQ-5"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "serial_number": {
+                    "type": "string",
+                    "example": "MRT-WQ-000123"
+                },
+                "site_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ColiformsData": {
+            "type": "object",
+            "properties": {
+                "E_coli": {
+                    "type": "number"
+                },
+                "fecal": {
+                    "type": "number"
+                },
+                "total": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.Command": {
+            "type": "object",
+            "properties": {
+                "acknowledged_at": {
+                    "type": "string"
+                },
+                "completed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deadline_at": {
+                    "type": "string"
+                },
+                "device_id": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "flush_sampler"
+                },
+                "params": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "requested_by": {
+                    "type": "string"
+                },
+                "result": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "sent_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "queued"
+                },
+                "timeout": {
+                    "description": "Timeout is how long the device may take to report the result once acknowledged, in seconds",
+                    "type": "integer",
+                    "example": 600
+                }
+            }
+        },
+        "domain.CommandRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "grab_sample"
+                },
+                "params": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "requested_by": {
+                    "type": "string"
+                },
+                "timeout": {
+                    "type": "integer",
+                    "example": 600
+                },
+                "ttl": {
+                    "type": "integer",
+                    "example": 3600
+                }
+            }
+        },
+        "domain.Device": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "decommissioned_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "dev_eui": {
+                    "type": "string",
+                    "example": "70B3D57ED0001234"
+                },
+                "firmware": {
+                    "type": "string"
+                },
+                "hardware_revision": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "installations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Installation"
+                    }
+                },
+                "installed_at": {
+                    "type": "string"
+                },
+                "location": {
+                    "$ref": "#/definitions/domain.GeoPoint"
+                },
+                "modbus": {
+                    "$ref": "#/definitions/domain.ModbusEndpoint"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "serial_number": {
+                    "type": "string"
+                },
+                "site_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "active"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.DeviceConfig": {
+            "type": "object",
+            "properties": {
+                "broker": {
+                    "type": "string",
+                    "example": "tcp://mqtt.example.com:1883"
+                },
+                "desired": {
+                    "type": "object"
+                },
+                "topic_prefix": {
+                    "type": "string",
+                    "example": "mrt/devices/6650f1c2a1b2c3d4e5f60718"
+                }
+            }
+        },
+        "domain.DeviceCredentials": {
+            "type": "object",
+            "properties": {
+                "client_id": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "psk": {
+                    "type": "string"
+                },
+                "psk_identity": {
+                    "description": "PSKIdentity and PSK are the DTLS identity and hex encoded pre-shared key of CoAP",
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.DeviceMapping": {
+            "type": "object",
+            "properties": {
+                "device_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "urn:dev:mac:0024befffe804ff1:"
+                },
+                "sensor_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.DeviceTemplate": {
+            "type": "object",
+            "properties": {
+                "config": {
+                    "type": "object"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string",
+                    "example": "WQ-5"
+                },
+                "sensors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.TemplateSensor"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.DeviceTransition": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "location": {
+                    "$ref": "#/definitions/domain.GeoPoint"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "site_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "installed"
+                }
+            }
+        },
+        "domain.Feature": {
+            "type": "object",
+            "properties": {
+                "geometry": {
+                    "$ref": "#/definitions/domain.GeoPoint"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "properties": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "type": {
+                    "type": "string",
+                    "example": "Feature"
+                }
+            }
+        },
+        "domain.FeatureCollection": {
+            "type": "object",
+            "properties": {
+                "features": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Feature"
+                    }
+                },
+                "type": {
+                    "type": "string",
+                    "example": "FeatureCollection"
+                }
+            }
+        },
+        "domain.GeoPoint": {
+            "type": "object",
+            "properties": {
+                "coordinates": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    },
+                    "example": [
+                        106.8227,
+                        -6.1935
+                    ]
+                },
+                "type": {
+                    "type": "string",
+                    "example": "Point"
+                }
+            }
+        },
+        "domain.Geometry": {
+            "type": "object",
+            "properties": {
+                "coordinates": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "type": {
+                    "type": "string",
+                    "example": "Polygon"
+                }
+            }
+        },
+        "domain.IngestMapping": {
+            "type": "object",
+            "properties": {
+                "device_tag": {
+                    "type": "string",
+                    "example": "device"
+                },
+                "devices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DeviceMapping"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "parameters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ParameterMapping"
+                    }
+                },
+                "source": {
+                    "type": "string",
+                    "example": "depot-gw-1"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.IngestReadingResult": {
+            "type": "object",
+            "properties": {
+                "dedup_key": {
+                    "type": "string"
+                },
+                "device_id": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "records": {
+                    "description": "Records are the positions of the records in the pack, the line numbers for line protocol",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "created"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.IngestRecordError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "no parameter mapped to temp"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "urn:dev:mac:0024befffe804ff1:temp"
+                },
+                "record": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "domain.IngestResult": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.IngestRecordError"
+                    }
+                },
+                "readings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.IngestReadingResult"
+                    }
+                }
+            }
+        },
+        "domain.Installation": {
+            "type": "object",
+            "properties": {
+                "installed_at": {
+                    "type": "string"
+                },
+                "location": {
+                    "$ref": "#/definitions/domain.GeoPoint"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "removed_at": {
+                    "type": "string"
+                },
+                "site_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.InventoryCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "active"
+                }
+            }
+        },
+        "domain.LPPChannel": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "parameter": {
+                    "type": "string",
+                    "example": "Temperature"
+                }
+            }
+        },
+        "domain.LabCorrelation": {
+            "type": "object",
+            "properties": {
+                "bias": {
+                    "type": "number"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "intercept": {
+                    "type": "number"
+                },
+                "pairs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.LabSensorPair"
+                    }
+                },
+                "parameter": {
+                    "type": "string",
+                    "example": "BOD"
+                },
+                "r2": {
+                    "type": "number"
+                },
+                "relative_bias": {
+                    "type": "number"
+                },
+                "rmse": {
+                    "type": "number"
+                },
+                "slope": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string",
+                    "example": "mg/L"
+                }
+            }
+        },
+        "domain.LabResult": {
+            "type": "object",
+            "properties": {
+                "parameter": {
+                    "type": "string",
+                    "example": "BOD"
+                },
+                "unit": {
+                    "type": "string",
+                    "example": "mg/L"
+                },
+                "value": {
+                    "type": "number",
+                    "example": 12.5
+                }
+            }
+        },
+        "domain.LabSample": {
+            "type": "object",
+            "properties": {
+                "analysed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "custody_status": {
+                    "type": "string",
+                    "example": "received"
+                },
+                "device_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lab": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string",
+                    "example": "APHA 5210 B"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.LabResult"
+                    }
+                },
+                "sample_id": {
+                    "type": "string",
+                    "example": "LAB-2024-0001"
+                },
+                "sampled_at": {
+                    "type": "string"
+                },
+                "site_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.LabSampleRequest": {
+            "type": "object",
+            "properties": {
+                "analysed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "custody_status": {
+                    "type": "string",
+                    "example": "received"
+                },
+                "device_id": {
+                    "type": "string"
+                },
+                "lab": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string",
+                    "example": "APHA 5210 B"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.LabResult"
+                    }
+                },
+                "sample_id": {
+                    "type": "string",
+                    "example": "LAB-2024-0001"
+                },
+                "sampled_at": {
+                    "type": "string"
+                },
+                "site_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.LabSensorPair": {
+            "type": "object",
+            "properties": {
+                "device_id": {
+                    "type": "string"
+                },
+                "lab_value": {
+                    "type": "number"
+                },
+                "measured_at": {
+                    "type": "string"
+                },
+                "measurement_id": {
+                    "type": "string"
+                },
+                "sample_id": {
+                    "type": "string"
+                },
+                "sampled_at": {
+                    "type": "string"
+                },
+                "sensor_value": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.Measurement": {
+            "type": "object",
+            "properties": {
+                "anomalies": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "calibration_id": {
+                    "type": "string"
+                },
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "device_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "maintenance": {
+                    "type": "boolean"
+                },
+                "out_of_range": {
+                    "type": "boolean"
+                },
+                "parameter": {
+                    "type": "string",
+                    "example": "pH"
+                },
+                "quality": {
+                    "type": "string",
+                    "example": "raw"
+                },
+                "raw_value": {
+                    "type": "number",
+                    "example": 7.15
+                },
+                "reading_id": {
+                    "type": "string"
+                },
+                "sensor_id": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "unit": {
+                    "type": "string",
+                    "example": "pH"
+                },
+                "value": {
+                    "type": "number",
+                    "example": 7.2
+                },
+                "work_order_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ModbusEndpoint": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "192.168.1.20:502"
+                },
+                "interval": {
+                    "description": "Interval is the time between two polls in seconds, the configured default applies when zero",
+                    "type": "integer",
+                    "example": 60
+                },
+                "serial": {
+                    "description": "Serial is the line of an RTU endpoint",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ModbusSerial"
+                        }
+                    ]
+                },
+                "transport": {
+                    "type": "string",
+                    "example": "tcp"
+                },
+                "unit_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "domain.ModbusRegister": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "byte_order": {
+                    "type": "string",
+                    "example": "ABCD"
+                },
+                "data_type": {
+                    "type": "string",
+                    "example": "float32"
+                },
+                "offset": {
+                    "type": "number"
+                },
+                "parameter": {
+                    "type": "string",
+                    "example": "pH"
+                },
+                "scale": {
+                    "type": "number",
+                    "example": 0.01
+                },
+                "table": {
+                    "type": "string",
+                    "example": "holding"
+                }
+            }
+        },
+        "domain.ModbusSerial": {
+            "type": "object",
+            "properties": {
+                "baud_rate": {
+                    "type": "integer",
+                    "example": 9600
+                },
+                "data_bits": {
+                    "type": "integer",
+                    "example": 8
+                },
+                "parity": {
+                    "description": "Parity is N (none), E (even) or O (odd)",
+                    "type": "string",
+                    "example": "E"
+                },
+                "stop_bits": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "domain.PSKSecret": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "identity": {
+                    "type": "string",
+                    "example": "6650f1c2a1b2c3d4e5f60718"
+                },
+                "key": {
+                    "description": "Key is hex encoded",
+                    "type": "string",
+                    "example": "000102030405060708090a0b0c0d0e0f"
+                }
+            }
+        },
+        "domain.ParameterMapping": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "temp"
+                },
+                "parameter": {
+                    "type": "string",
+                    "example": "Temperature"
+                },
+                "unit": {
+                    "type": "string",
+                    "example": "°C"
+                }
+            }
+        },
+        "domain.ParameterSpec": {
+            "type": "object",
+            "properties": {
+                "accuracy": {
+                    "type": "number",
+                    "example": 0.1
+                },
+                "max": {
+                    "type": "number",
+                    "example": 14
+                },
+                "min": {
+                    "type": "number",
+                    "example": 0
+                },
+                "parameter": {
+                    "type": "string",
+                    "example": "pH"
+                },
+                "resolution": {
+                    "type": "number",
+                    "example": 0.01
+                },
+                "unit": {
+                    "type": "string",
+                    "example": "pH"
+                }
+            }
+        },
+        "domain.PayloadCodec": {
+            "type": "object",
+            "properties": {
+                "channels": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.LPPChannel"
+                    }
+                },
+                "f_port": {
+                    "description": "FPort is the port of the measurement uplinks, uplinks on other ports are not decoded, 0 decodes every port",
+                    "type": "integer",
+                    "example": 1
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ByteField"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string",
+                    "example": "OUT-LR1"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "cayenne_lpp"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ProvisioningClaim": {
+            "type": "object",
+            "properties": {
+                "claimed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "decided_at": {
+                    "type": "string"
+                },
+                "decided_by": {
+                    "type": "string"
+                },
+                "device_id": {
+                    "type": "string"
+                },
+                "firmware": {
+                    "type": "string"
+                },
+                "hardware_revision": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string",
+                    "example": "WQ-5"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "serial_number": {
+                    "type": "string",
+                    "example": "MRT-WQ-000123"
+                },
+                "site_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "pending"
+                }
+            }
+        },
+        "domain.QualityReview": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "string",
+                    "example": "suspect"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "measurement_id": {
+                    "type": "string"
+                },
+                "parameter": {
+                    "type": "string",
+                    "example": "pH"
+                },
+                "reading_id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "probe out of the water during cleaning"
+                },
+                "reviewer": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "invalid"
+                }
+            }
+        },
+        "domain.QualityReviewRequest": {
+            "type": "object",
+            "properties": {
+                "measurement_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "quality": {
+                    "type": "string",
+                    "example": "validated"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "reviewer": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.RadioMetadata": {
+            "type": "object",
+            "properties": {
+                "frequency": {
+                    "type": "integer",
+                    "example": 868100000
+                },
+                "gateway_id": {
+                    "type": "string",
+                    "example": "0016c001f153a14c"
+                },
+                "gateways": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "rssi": {
+                    "type": "integer",
+                    "example": -57
+                },
+                "snr": {
+                    "type": "number",
+                    "example": 9.5
+                },
+                "spreading_factor": {
+                    "type": "integer",
+                    "example": 7
+                }
+            }
+        },
+        "domain.ReferenceStandard": {
+            "type": "object",
+            "properties": {
+                "lot": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "pH 7.00 buffer"
+                },
+                "unit": {
+                    "type": "string",
+                    "example": "pH"
+                },
+                "value": {
+                    "type": "number",
+                    "example": 7
+                }
+            }
+        },
+        "domain.Registration": {
+            "type": "object",
+            "properties": {
+                "config": {
+                    "$ref": "#/definitions/domain.DeviceConfig"
+                },
+                "credentials": {
+                    "$ref": "#/definitions/domain.DeviceCredentials"
+                },
+                "device": {
+                    "$ref": "#/definitions/domain.Device"
+                },
+                "sensors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Sensor"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "claimed"
+                }
+            }
+        },
+        "domain.RegistrationRequest": {
+            "type": "object",
+            "properties": {
+                "claim_token": {
+                    "type": "string"
+                },
+                "firmware": {
+                    "type": "string",
+                    "example": "1.4.2"
+                },
+                "hardware_revision": {
+                    "type": "string",
+                    "example": "C"
+                },
+                "model": {
+                    "type": "string",
+                    "example": "WQ-5"
+                },
+                "serial_number": {
+                    "type": "string",
+                    "example": "MRT-WQ-000123"
+                }
+            }
+        },
+        "domain.SenMLRecord": {
+            "type": "object",
+            "properties": {
+                "bn": {
+                    "type": "string"
+                },
+                "bt": {
+                    "type": "number"
+                },
+                "bu": {
+                    "type": "string"
+                },
+                "bv": {
+                    "type": "number"
+                },
+                "bver": {
+                    "type": "integer"
+                },
+                "n": {
+                    "type": "string",
+                    "example": "temp"
+                },
+                "s": {
+                    "type": "number"
+                },
+                "t": {
+                    "type": "number"
+                },
+                "u": {
+                    "type": "string",
+                    "example": "Cel"
+                },
+                "ut": {
+                    "type": "number"
+                },
+                "v": {
+                    "type": "number",
+                    "example": 23.1
+                },
+                "vb": {
+                    "type": "boolean"
+                },
+                "vd": {
+                    "type": "string"
+                },
+                "vs": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Sensor": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "device_id": {
+                    "type": "string"
+                },
+                "health": {
+                    "$ref": "#/definitions/domain.SensorHealth"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "sensor_type_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.SensorDiagnostic": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "flatline"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "parameter": {
+                    "type": "string",
+                    "example": "COD"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.SensorHealth": {
+            "type": "object",
+            "properties": {
+                "diagnostics": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.SensorDiagnostic"
+                    }
+                },
+                "samples": {
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "number",
+                    "example": 85
+                },
+                "status": {
+                    "type": "string",
+                    "example": "healthy"
+                }
+            }
+        },
+        "domain.SensorType": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "ph-probe"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "manufacturer": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parameters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ParameterSpec"
+                    }
+                },
+                "registers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ModbusRegister"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.SensorTypeRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "ph-probe"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "manufacturer": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parameters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ParameterSpec"
+                    }
+                },
+                "registers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ModbusRegister"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Shadow": {
+            "type": "object",
+            "properties": {
+                "delta": {
+                    "$ref": "#/definitions/domain.ShadowState"
+                },
+                "desired": {
+                    "$ref": "#/definitions/domain.ShadowState"
+                },
+                "desired_updated_at": {
+                    "type": "string"
+                },
+                "device_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "reported": {
+                    "$ref": "#/definitions/domain.ShadowState"
+                },
+                "reported_updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.ShadowState": {
+            "type": "object",
+            "additionalProperties": true
+        },
+        "domain.ShadowUpdate": {
+            "type": "object",
+            "properties": {
+                "state": {
+                    "type": "object"
+                },
+                "version": {
+                    "type": "integer"
                 }
             }
         },
-        "domain.Sensor": {
+        "domain.Site": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "geometry": {
+                    "$ref": "#/definitions/domain.Geometry"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "operator": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.SiteRequest": {
             "type": "object",
             "properties": {
+                "address": {
+                    "type": "string"
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "geometry": {
+                    "$ref": "#/definitions/domain.Geometry"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "operator": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.TTSUplink": {
+            "type": "object",
+            "properties": {
+                "end_device_ids": {
+                    "type": "object",
+                    "properties": {
+                        "dev_eui": {
+                            "type": "string"
+                        },
+                        "device_id": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "received_at": {
+                    "type": "string"
+                },
+                "uplink_message": {
+                    "type": "object",
+                    "properties": {
+                        "f_cnt": {
+                            "type": "integer"
+                        },
+                        "f_port": {
+                            "type": "integer"
+                        },
+                        "frm_payload": {
+                            "type": "string",
+                            "format": "base64"
+                        },
+                        "received_at": {
+                            "type": "string"
+                        },
+                        "rx_metadata": {
+                            "type": "array",
+                            "items": {
+                                "type": "object",
+                                "properties": {
+                                    "gateway_ids": {
+                                        "type": "object",
+                                        "properties": {
+                                            "eui": {
+                                                "type": "string"
+                                            },
+                                            "gateway_id": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    },
+                                    "rssi": {
+                                        "type": "integer"
+                                    },
+                                    "snr": {
+                                        "type": "number"
+                                    }
+                                }
+                            }
+                        },
+                        "settings": {
+                            "type": "object",
+                            "properties": {
+                                "data_rate": {
+                                    "type": "object",
+                                    "properties": {
+                                        "lora": {
+                                            "type": "object",
+                                            "properties": {
+                                                "spreading_factor": {
+                                                    "type": "integer"
+                                                }
+                                            }
+                                        }
+                                    }
+                                },
+                                "frequency": {
+                                    "description": "Frequency is a string in the webhook messages",
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "domain.TemplateSensor": {
+            "type": "object",
+            "properties": {
                 "description": {
                     "type": "string"
                 },
+                "name": {
+                    "type": "string",
+                    "example": "pH probe"
+                },
+                "sensor_type_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.TimeWindow": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.UplinkRecord": {
+            "type": "object",
+            "properties": {
+                "dev_eui": {
+                    "type": "string",
+                    "example": "70B3D57ED0001234"
+                },
                 "device_id": {
                     "type": "string"
                 },
+                "error": {
+                    "type": "string"
+                },
+                "f_cnt": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "f_port": {
+                    "type": "integer",
+                    "example": 1
+                },
                 "id": {
                     "type": "string"
                 },
-                "name": {
+                "payload": {
+                    "type": "string",
+                    "format": "base64"
+                },
+                "radio": {
+                    "$ref": "#/definitions/domain.RadioMetadata"
+                },
+                "reading_id": {
                     "type": "string"
                 },
-                "updated_at": {
+                "received_at": {
+                    "type": "string"
+                },
+                "values": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
+        "domain.WasteWaterBatch": {
+            "type": "object",
+            "properties": {
+                "readings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.WastewaterDataRequest"
+                    }
+                }
+            }
+        },
+        "domain.WasteWaterBatchResult": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.BatchItemResult"
+                    }
+                },
+                "server_time": {
                     "type": "string"
                 }
             }
@@ -806,11 +6038,236 @@ var doc = `{
                 "_id": {
                     "type": "string"
                 },
+                "anomalies": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "device_id": {
+                    "type": "string"
+                },
+                "maintenance": {
+                    "type": "boolean"
+                },
+                "out_of_range": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "pH": {
+                    "type": "number"
+                },
+                "parameters": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "quality": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "units": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.WastewaterDataRequest": {
+            "type": "object",
+            "properties": {
+                "Ammonium": {
+                    "type": "number"
+                },
+                "BOD": {
+                    "type": "number"
+                },
+                "CDOM": {
+                    "type": "number"
+                },
+                "COD": {
+                    "type": "number"
+                },
+                "Chloride": {
+                    "type": "number"
+                },
+                "Coliforms": {
+                    "$ref": "#/definitions/domain.ColiformsData"
+                },
+                "Crude_Oils": {
+                    "type": "number"
+                },
+                "DOC": {
+                    "type": "number"
+                },
+                "Dissolved_Oxygen": {
+                    "type": "number"
+                },
+                "EC_Salinity_TDS": {
+                    "type": "number"
+                },
+                "Nitrate": {
+                    "type": "number"
+                },
+                "ORP_REDOX": {
+                    "type": "number"
+                },
+                "Optical_Brighteners": {
+                    "type": "number"
+                },
+                "Pressure": {
+                    "type": "number"
+                },
+                "Refined_Oils": {
+                    "type": "number"
+                },
+                "TOC": {
+                    "type": "number"
+                },
+                "Temperature": {
+                    "type": "number"
+                },
+                "Tryptophan": {
+                    "type": "number"
+                },
+                "Turbidity": {
+                    "type": "number"
+                },
+                "_id": {
+                    "type": "string"
+                },
+                "dedup_key": {
+                    "description": "DedupKey identifies a reading sent again, e.g. by an edge agent retrying, so that it is stored once",
+                    "type": "string"
+                },
+                "device_id": {
+                    "type": "string"
+                },
+                "out_of_range": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "pH": {
                     "type": "number"
                 },
+                "parameters": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
                 "timestamp": {
                     "type": "string"
+                },
+                "units": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.WorkOrder": {
+            "type": "object",
+            "properties": {
+                "assignees": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "attachments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Attachment"
+                    }
+                },
+                "completed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "device_id": {
+                    "type": "string"
+                },
+                "downtime": {
+                    "$ref": "#/definitions/domain.TimeWindow"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "schedule": {
+                    "$ref": "#/definitions/domain.TimeWindow"
+                },
+                "sensor_id": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "planned"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "cleaning"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "graphql.Request": {
+            "type": "object",
+            "properties": {
+                "operationName": {
+                    "type": "string"
+                },
+                "query": {
+                    "type": "string"
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": true
+                }
+            }
+        },
+        "rest.MQTTAuthRequest": {
+            "type": "object",
+            "properties": {
+                "acc": {
+                    "type": "integer"
+                },
+                "clientid": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "topic": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
                 }
             }
         },
@@ -822,59 +6279,28 @@ var doc = `{
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "DeviceAuth": {
+            "type": "basic"
+        }
     }
 }`
 
-type swaggerInfo struct {
-	Version     string
-	Host        string
-	BasePath    string
-	Schemes     []string
-	Title       string
-	Description string
-}
-
 // SwaggerInfo holds exported Swagger Info so clients can modify it
-var SwaggerInfo = swaggerInfo{
-	Version:     "1.0",
-	Host:        "localhost:3000",
-	BasePath:    "/",
-	Schemes:     []string{},
-	Title:       "MRT Waste Water API",
-	Description: "This is an API Document for MRT Waste Water",
-}
-
-type s struct{}
-
-func (s *s) ReadDoc() string {
-	sInfo := SwaggerInfo
-	sInfo.Description = strings.Replace(sInfo.Description, "\n", "\\n", -1)
-
-	t, err := template.New("swagger_info").Funcs(template.FuncMap{
-		"marshal": func(v interface{}) string {
-			a, _ := json.Marshal(v)
-			return string(a)
-		},
-		"escape": func(v interface{}) string {
-			// escape tabs
-			str := strings.Replace(v.(string), "\t", "\\t", -1)
-			// replace " with \", and if that results in \\", replace that with \\\"
-			str = strings.Replace(str, "\"", "\\\"", -1)
-			return strings.Replace(str, "\\\\\"", "\\\\\\\"", -1)
-		},
-	}).Parse(doc)
-	if err != nil {
-		return doc
-	}
-
-	var tpl bytes.Buffer
-	if err := t.Execute(&tpl, sInfo); err != nil {
-		return doc
-	}
-
-	return tpl.String()
+var SwaggerInfo = &swag.Spec{
+	Version:          "1.0",
+	Host:             "localhost:3000",
+	BasePath:         "/",
+	Schemes:          []string{},
+	Title:            "MRT Waste Water API",
+	Description:      "This is an API Document for MRT Waste Water",
+	InfoInstanceName: "swagger",
+	SwaggerTemplate:  docTemplate,
+	LeftDelim:        "{{",
+	RightDelim:       "}}",
 }
 
 func init() {
-	swag.Register("swagger", &s{})
+	swag.Register(SwaggerInfo.InstanceName(), SwaggerInfo)
 }
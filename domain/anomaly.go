@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Anomaly detection methods, each scores how far a value lies from its baseline
+const (
+	AnomalyEWMA     = "ewma"
+	AnomalyMAD      = "mad"
+	AnomalySeasonal = "seasonal"
+)
+
+// ErrBaselineVersion is returned when a baseline was saved against an outdated version of the baseline
+var ErrBaselineVersion = errors.New("baseline version conflict")
+
+// HourlyStat accumulates the mean and variance of the values seen at one hour of the day
+type HourlyStat struct {
+	Count int     `bson:"count" json:"count"`
+	Mean  float64 `bson:"mean" json:"mean"`
+	M2    float64 `bson:"m2" json:"m2"`
+}
+
+// AnomalyBaseline holds the rolling statistics of a parameter of a device, Version increases with every update
+type AnomalyBaseline struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	DeviceID  primitive.ObjectID `bson:"device_id" json:"device_id"`
+	Parameter string             `bson:"parameter" json:"parameter"`
+	Count     int                `bson:"count" json:"count"`
+	EWMA      float64            `bson:"ewma" json:"ewma"`
+	EWMVar    float64            `bson:"ewm_var" json:"ewm_var"`
+	Window    []float64          `bson:"window" json:"window"`
+	Hourly    []HourlyStat       `bson:"hourly" json:"hourly"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+	Version   int64              `bson:"version" json:"version"`
+}
+
+// AnomalyEvent records a measurement scored as anomalous by one of the detection methods
+type AnomalyEvent struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	MeasurementID primitive.ObjectID `bson:"measurement_id" json:"measurement_id"`
+	ReadingID     primitive.ObjectID `bson:"reading_id" json:"reading_id"`
+	DeviceID      primitive.ObjectID `bson:"device_id" json:"device_id"`
+	Parameter     string             `bson:"parameter" json:"parameter" example:"COD"`
+	Timestamp     time.Time          `bson:"timestamp" json:"timestamp"`
+	Value         float64            `bson:"value" json:"value"`
+	Method        string             `bson:"method" json:"method" example:"mad"`
+	Score         float64            `bson:"score" json:"score" example:"5.3"`
+	Expected      float64            `bson:"expected" json:"expected"`
+}
+
+// AnomalyFilter narrows an anomaly event query, zero values are ignored
+type AnomalyFilter struct {
+	DeviceID  primitive.ObjectID
+	Parameter string
+	From      time.Time
+	To        time.Time
+}
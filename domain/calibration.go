@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrInvalidCalibration is returned when a calibration has no sensor, parameter or date, or no usable correction
+var ErrInvalidCalibration = errors.New("invalid calibration")
+
+// ReferenceStandard is a buffer or reference solution used during a calibration
+type ReferenceStandard struct {
+	Name  string  `bson:"name" json:"name" example:"pH 7.00 buffer"`
+	Value float64 `bson:"value" json:"value" example:"7"`
+	Unit  string  `bson:"unit" json:"unit" example:"pH"`
+	Lot   string  `bson:"lot,omitempty" json:"lot,omitempty"`
+}
+
+// CalibrationPoint maps a raw sensor value to the reference value it should read
+type CalibrationPoint struct {
+	Raw       float64 `bson:"raw" json:"raw" example:"6.92"`
+	Reference float64 `bson:"reference" json:"reference" example:"7"`
+}
+
+// Calibration is a calibration event of a parameter of a sensor.
+//
+// The correction is either a multi-point curve, interpolated linearly between its
+// points, or Slope * raw + Offset when there are no points. Offset and points are
+// in the canonical unit of the parameter.
+type Calibration struct {
+	ID           primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	SensorID     primitive.ObjectID  `bson:"sensor_id" json:"sensor_id"`
+	Parameter    string              `bson:"parameter" json:"parameter" example:"pH"`
+	CalibratedAt time.Time           `bson:"calibrated_at" json:"calibrated_at"`
+	Technician   string              `bson:"technician" json:"technician"`
+	Standards    []ReferenceStandard `bson:"standards,omitempty" json:"standards,omitempty"`
+	Offset       float64             `bson:"offset" json:"offset" example:"0.05"`
+	Slope        float64             `bson:"slope" json:"slope" example:"1"`
+	Points       []CalibrationPoint  `bson:"points,omitempty" json:"points,omitempty"`
+	NextDueAt    time.Time           `bson:"next_due_at,omitempty" json:"next_due_at"`
+	Notes        string              `bson:"notes,omitempty" json:"notes,omitempty"`
+	CreatedAt    MyTime              `bson:"created_at" json:"created_at" swaggertype:"string" time_format:"2006-01-02 15:04:05"`
+}
+
+// Validate checks the calibration and sorts its points by raw value.
+func (c *Calibration) Validate() error {
+	if c.SensorID.IsZero() {
+		return fmt.Errorf("%w: sensor_id is required", ErrInvalidCalibration)
+	}
+	if c.Parameter == "" {
+		return fmt.Errorf("%w: parameter is required", ErrInvalidCalibration)
+	}
+	if c.CalibratedAt.IsZero() {
+		return fmt.Errorf("%w: calibrated_at is required", ErrInvalidCalibration)
+	}
+	if !c.NextDueAt.IsZero() && !c.NextDueAt.After(c.CalibratedAt) {
+		return fmt.Errorf("%w: next_due_at must be after calibrated_at", ErrInvalidCalibration)
+	}
+	switch len(c.Points) {
+	case 0:
+		if c.Slope == 0 {
+			return fmt.Errorf("%w: slope or points are required", ErrInvalidCalibration)
+		}
+	case 1:
+		return fmt.Errorf("%w: a curve needs at least two points", ErrInvalidCalibration)
+	default:
+		sort.Slice(c.Points, func(i, j int) bool { return c.Points[i].Raw < c.Points[j].Raw })
+		for i := 1; i < len(c.Points); i++ {
+			if c.Points[i].Raw == c.Points[i-1].Raw {
+				return fmt.Errorf("%w: duplicate raw value %g", ErrInvalidCalibration, c.Points[i].Raw)
+			}
+		}
+	}
+	return nil
+}
+
+// Apply returns the calibrated value of a raw value.
+//
+// Values outside the curve are extrapolated from its first or last segment.
+func (c *Calibration) Apply(raw float64) float64 {
+	if len(c.Points) < 2 {
+		return c.Slope*raw + c.Offset
+	}
+	i := sort.Search(len(c.Points), func(i int) bool { return c.Points[i].Raw >= raw })
+	switch {
+	case i == 0:
+		i = 1
+	case i == len(c.Points):
+		i = len(c.Points) - 1
+	}
+	lower, upper := c.Points[i-1], c.Points[i]
+	return lower.Reference + (raw-lower.Raw)*(upper.Reference-lower.Reference)/(upper.Raw-lower.Raw)
+}
+
+// ActiveCalibration returns the latest of the calibrations performed at or before the given time, nil if there is none.
+func ActiveCalibration(calibrations []Calibration, at time.Time) *Calibration {
+	var active *Calibration
+	for i := range calibrations {
+		c := &calibrations[i]
+		if c.CalibratedAt.After(at) {
+			continue
+		}
+		if active == nil || c.CalibratedAt.After(active.CalibratedAt) {
+			active = c
+		}
+	}
+	return active
+}
@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Well known commands, devices may support others
+const (
+	CommandFlushSampler = "flush_sampler"
+	CommandReboot       = "reboot"
+	CommandGrabSample   = "grab_sample"
+)
+
+// Command statuses, a command is queued, sent to the broker, acknowledged by the device and finally
+// succeeds or fails. A command not acknowledged before it expires is expired, an acknowledged command
+// without a result before its deadline is timed out.
+const (
+	CommandQueued       = "queued"
+	CommandSent         = "sent"
+	CommandAcknowledged = "acknowledged"
+	CommandSucceeded    = "succeeded"
+	CommandFailed       = "failed"
+	CommandExpired      = "expired"
+	CommandTimedOut     = "timed_out"
+)
+
+// ErrInvalidCommand is returned when a command has no name, a negative TTL or timeout, or a reply
+// has an unknown status or comes from another device
+var ErrInvalidCommand = errors.New("invalid command")
+
+// ErrCommandTransition is returned when a reply arrives for a command that can no longer move to its status
+var ErrCommandTransition = errors.New("command transition not allowed")
+
+// commandTransitions lists the statuses each status can move to, a device may report its result without acknowledging first
+var commandTransitions = map[string][]string{
+	CommandQueued:       {CommandSent, CommandAcknowledged, CommandSucceeded, CommandFailed, CommandExpired},
+	CommandSent:         {CommandAcknowledged, CommandSucceeded, CommandFailed, CommandExpired},
+	CommandAcknowledged: {CommandSucceeded, CommandFailed, CommandTimedOut},
+}
+
+// CommandSources returns the statuses a command can move to the given status from, in lifecycle order.
+func CommandSources(to string) []string {
+	var from []string
+	for _, status := range []string{CommandQueued, CommandSent, CommandAcknowledged} {
+		for _, target := range commandTransitions[status] {
+			if target == to {
+				from = append(from, status)
+			}
+		}
+	}
+	return from
+}
+
+// Command is a one-off action requested from a device
+type Command struct {
+	ID          primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	DeviceID    primitive.ObjectID     `bson:"device_id" json:"device_id"`
+	Name        string                 `bson:"name" json:"name" example:"flush_sampler"`
+	Params      map[string]interface{} `bson:"params,omitempty" json:"params,omitempty"`
+	Status      string                 `bson:"status" json:"status" example:"queued"`
+	RequestedBy string                 `bson:"requested_by,omitempty" json:"requested_by,omitempty"`
+	// Timeout is how long the device may take to report the result once acknowledged, in seconds
+	Timeout        int                    `bson:"timeout" json:"timeout" example:"600"`
+	ExpiresAt      time.Time              `bson:"expires_at" json:"expires_at"`
+	SentAt         time.Time              `bson:"sent_at,omitempty" json:"sent_at"`
+	AcknowledgedAt time.Time              `bson:"acknowledged_at,omitempty" json:"acknowledged_at"`
+	DeadlineAt     time.Time              `bson:"deadline_at,omitempty" json:"deadline_at"`
+	CompletedAt    time.Time              `bson:"completed_at,omitempty" json:"completed_at"`
+	Result         map[string]interface{} `bson:"result,omitempty" json:"result,omitempty"`
+	Error          string                 `bson:"error,omitempty" json:"error,omitempty"`
+	CreatedAt      MyTime                 `bson:"created_at" json:"created_at" swaggertype:"string" time_format:"2006-01-02 15:04:05"`
+}
+
+// CommandRequest is an operator request to run a command on a device.
+//
+// TTL is how long the command may wait for the device to pick it up and Timeout how long
+// the device may take to report its result once acknowledged, both in seconds.
+type CommandRequest struct {
+	Name        string                 `json:"name" example:"grab_sample"`
+	Params      map[string]interface{} `json:"params,omitempty"`
+	TTL         int                    `json:"ttl" example:"3600"`
+	Timeout     int                    `json:"timeout" example:"600"`
+	RequestedBy string                 `json:"requested_by"`
+}
+
+// Validate checks the request has a name and no negative TTL or timeout.
+func (r *CommandRequest) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidCommand)
+	}
+	if r.TTL < 0 || r.Timeout < 0 {
+		return fmt.Errorf("%w: ttl and timeout cannot be negative", ErrInvalidCommand)
+	}
+	return nil
+}
+
+// CommandReply is the message a device sends back when it acknowledges a command or reports its result
+type CommandReply struct {
+	CommandID primitive.ObjectID     `json:"id"`
+	Status    string                 `json:"status" example:"succeeded"`
+	Result    map[string]interface{} `json:"result,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+}
+
+// Apply records the reply on the command.
+//
+// Returns ErrInvalidCommand when the reply status cannot be reported by a device.
+func (c *Command) Apply(reply CommandReply, at time.Time) error {
+	switch reply.Status {
+	case CommandAcknowledged:
+		c.AcknowledgedAt = at
+		c.DeadlineAt = at.Add(time.Duration(c.Timeout) * time.Second)
+	case CommandSucceeded, CommandFailed:
+		c.CompletedAt = at
+		c.Result = reply.Result
+		c.Error = reply.Error
+	default:
+		return fmt.Errorf("%w: a device cannot report %q", ErrInvalidCommand, reply.Status)
+	}
+	c.Status = reply.Status
+	return nil
+}
+
+// CommandMessage is the message published to a device to run a command
+type CommandMessage struct {
+	ID        primitive.ObjectID     `json:"id"`
+	Name      string                 `json:"name"`
+	Params    map[string]interface{} `json:"params,omitempty"`
+	ExpiresAt time.Time              `json:"expires_at"`
+}
+
+// CommandFilter narrows a command history query, zero values are ignored
+type CommandFilter struct {
+	DeviceID primitive.ObjectID
+	Status   string
+}
@@ -1,24 +1,87 @@
 package domain
 
 import (
+	"fmt"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Device struct {
-	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Name        string             `bson:"name" json:"name"`
-	Description string             `bson:"description" json:"description"`
-	CreatedAt   MyTime             `bson:"created_at" json:"created_at" time_format:"2006-01-02T15:04:05"`
-	UpdatedAt   MyTime             `bson:"updated_at" json:"updated_at" time_format:"2006-01-02T15:04:05"`
+	ID               primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name             string             `bson:"name" json:"name"`
+	Description      string             `bson:"description" json:"description"`
+	SiteID           primitive.ObjectID `bson:"site_id,omitempty" json:"site_id,omitempty"`
+	Location         *GeoPoint          `bson:"location,omitempty" json:"location,omitempty"`
+	Status           string             `bson:"status,omitempty" json:"status" example:"active"`
+	SerialNumber     string             `bson:"serial_number,omitempty" json:"serial_number,omitempty"`
+	Model            string             `bson:"model,omitempty" json:"model,omitempty"`
+	Firmware         string             `bson:"firmware,omitempty" json:"firmware,omitempty"`
+	HardwareRevision string             `bson:"hardware_revision,omitempty" json:"hardware_revision,omitempty"`
+	InstalledAt      time.Time          `bson:"installed_at,omitempty" json:"installed_at"`
+	DecommissionedAt time.Time          `bson:"decommissioned_at,omitempty" json:"decommissioned_at"`
+	Installations    []Installation     `bson:"installations,omitempty" json:"installations,omitempty"`
+	Modbus           *ModbusEndpoint    `bson:"modbus,omitempty" json:"modbus,omitempty"`
+	DevEUI           string             `bson:"dev_eui,omitempty" json:"dev_eui,omitempty" example:"70B3D57ED0001234"`
+	CreatedAt        MyTime             `bson:"created_at" json:"created_at" swaggertype:"string" time_format:"2006-01-02T15:04:05"`
+	UpdatedAt        MyTime             `bson:"updated_at" json:"updated_at" swaggertype:"string" time_format:"2006-01-02T15:04:05"`
 }
 
 type DeviceRequest struct {
-	Name        string `bson:"name" json:"name"`
-	Description string `bson:"description" json:"description"`
-	CreatedAt   MyTime `bson:"created_at" json:"created_at" time_format:"2006-01-02T15:04:05"`
-	UpdatedAt   MyTime `bson:"updated_at" json:"updated_at" time_format:"2006-01-02T15:04:05"`
+	ID               primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	Name             string             `bson:"name" json:"name"`
+	Description      string             `bson:"description" json:"description"`
+	SiteID           primitive.ObjectID `bson:"site_id,omitempty" json:"site_id,omitempty"`
+	Location         *GeoPoint          `bson:"location,omitempty" json:"location,omitempty"`
+	Status           string             `bson:"status,omitempty" json:"status" example:"procured"`
+	SerialNumber     string             `bson:"serial_number,omitempty" json:"serial_number,omitempty"`
+	Model            string             `bson:"model,omitempty" json:"model,omitempty"`
+	Firmware         string             `bson:"firmware,omitempty" json:"firmware,omitempty"`
+	HardwareRevision string             `bson:"hardware_revision,omitempty" json:"hardware_revision,omitempty"`
+	InstalledAt      time.Time          `bson:"installed_at,omitempty" json:"installed_at"`
+	Installations    []Installation     `bson:"installations,omitempty" json:"installations,omitempty"`
+	Modbus           *ModbusEndpoint    `bson:"modbus,omitempty" json:"modbus,omitempty"`
+	DevEUI           string             `bson:"dev_eui,omitempty" json:"dev_eui,omitempty" example:"70B3D57ED0001234"`
+	CreatedAt        MyTime             `bson:"created_at" json:"created_at" swaggertype:"string" time_format:"2006-01-02T15:04:05"`
+	UpdatedAt        MyTime             `bson:"updated_at" json:"updated_at" swaggertype:"string" time_format:"2006-01-02T15:04:05"`
+}
+
+// Validate checks the location, the Modbus endpoint, the DevEUI and the lifecycle status of a new device and defaults the status to procured.
+//
+// A device created in the field with a site starts its install history at that site.
+func (r *DeviceRequest) Validate() error {
+	if r.Location != nil {
+		if err := r.Location.Validate(); err != nil {
+			return err
+		}
+	}
+	if r.Modbus != nil {
+		if err := r.Modbus.Validate(); err != nil {
+			return err
+		}
+	}
+	devEUI, err := NormalizeDevEUI(r.DevEUI)
+	if err != nil {
+		return err
+	}
+	r.DevEUI = devEUI
+	if r.Status == "" {
+		r.Status = DeviceProcured
+	}
+	switch r.Status {
+	case DeviceProcured:
+	case DeviceInstalled, DeviceActive, DeviceMaintenance:
+		if r.SiteID.IsZero() {
+			return fmt.Errorf("%w: site_id is required for a %s device", ErrInvalidDevice, r.Status)
+		}
+		if r.InstalledAt.IsZero() {
+			r.InstalledAt = time.Now()
+		}
+		r.Installations = []Installation{{SiteID: r.SiteID, Location: r.Location, InstalledAt: r.InstalledAt}}
+	default:
+		return fmt.Errorf("%w: a new device cannot be %q", ErrInvalidDevice, r.Status)
+	}
+	return nil
 }
 
 type MyTime struct {
@@ -32,6 +95,12 @@ func (m *MyTime) UnmarshalJSON(data []byte) error {
 	}
 	// Fractional seconds are handled implicitly by Parse.
 	tt, err := time.Parse(`"2006-01-02 15:04:05"`, string(data))
+	if err != nil {
+		// The API answers in RFC 3339, its own responses are accepted back
+		if rfc, rfcErr := time.Parse(`"`+time.RFC3339+`"`, string(data)); rfcErr == nil {
+			tt, err = rfc, nil
+		}
+	}
 	*m = MyTime{tt}
 	return err
 }
@@ -0,0 +1,164 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Lifecycle statuses of a device, a device is procured, installed at a site, put in service and finally decommissioned
+const (
+	DeviceProcured       = "procured"
+	DeviceInstalled      = "installed"
+	DeviceActive         = "active"
+	DeviceMaintenance    = "maintenance"
+	DeviceDecommissioned = "decommissioned"
+)
+
+// ErrInvalidDevice is returned when a device has an unknown status or is installed without a site
+var ErrInvalidDevice = errors.New("invalid device")
+
+// ErrDeviceTransition is returned when a device cannot move to the requested lifecycle status
+var ErrDeviceTransition = errors.New("device transition not allowed")
+
+// ErrDeviceDecommissioned is returned when data is ingested from a decommissioned device
+var ErrDeviceDecommissioned = errors.New("device is decommissioned")
+
+// deviceTransitions lists the statuses each lifecycle status can move to, installed can be
+// entered again to move the device to another site
+var deviceTransitions = map[string][]string{
+	DeviceProcured:    {DeviceInstalled, DeviceDecommissioned},
+	DeviceInstalled:   {DeviceInstalled, DeviceActive, DeviceMaintenance, DeviceProcured, DeviceDecommissioned},
+	DeviceActive:      {DeviceInstalled, DeviceMaintenance, DeviceDecommissioned},
+	DeviceMaintenance: {DeviceInstalled, DeviceActive, DeviceDecommissioned},
+}
+
+// IsDeviceStatus reports whether the status is a known lifecycle status.
+func IsDeviceStatus(status string) bool {
+	switch status {
+	case DeviceProcured, DeviceInstalled, DeviceActive, DeviceMaintenance, DeviceDecommissioned:
+		return true
+	}
+	return false
+}
+
+// CanDeviceTransition reports whether a device may move from one lifecycle status to another.
+//
+// Devices created before lifecycle management have no status and are treated as active.
+func CanDeviceTransition(from, to string) bool {
+	if from == "" {
+		from = DeviceActive
+	}
+	for _, status := range deviceTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Installation is a period a device spent installed at a site, a zero RemovedAt means the device is still there
+type Installation struct {
+	SiteID      primitive.ObjectID `bson:"site_id" json:"site_id"`
+	Location    *GeoPoint          `bson:"location,omitempty" json:"location,omitempty"`
+	InstalledAt time.Time          `bson:"installed_at" json:"installed_at"`
+	RemovedAt   time.Time          `bson:"removed_at,omitempty" json:"removed_at"`
+	Reason      string             `bson:"reason,omitempty" json:"reason,omitempty"`
+}
+
+// DeviceTransition is a request to move a device to another lifecycle status.
+//
+// SiteID and Location are used when the device is installed, At defaults to now.
+type DeviceTransition struct {
+	Status   string             `json:"status" example:"installed"`
+	SiteID   primitive.ObjectID `json:"site_id,omitempty"`
+	Location *GeoPoint          `json:"location,omitempty"`
+	At       time.Time          `json:"at"`
+	Reason   string             `json:"reason"`
+}
+
+// Transition moves the device to the requested status and keeps its install history.
+//
+// Installing the device closes its current installation and opens one at the new site.
+// Returning it to stock or decommissioning it closes its current installation.
+func (d *Device) Transition(t DeviceTransition) error {
+	if !IsDeviceStatus(t.Status) {
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidDevice, t.Status)
+	}
+	if !CanDeviceTransition(d.Status, t.Status) {
+		return fmt.Errorf("%w: %s from %s to %s", ErrDeviceTransition, d.ID.Hex(), d.Status, t.Status)
+	}
+	if t.Location != nil {
+		if err := t.Location.Validate(); err != nil {
+			return err
+		}
+	}
+	at := t.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	switch t.Status {
+	case DeviceInstalled:
+		siteID := t.SiteID
+		if siteID.IsZero() {
+			siteID = d.SiteID
+		}
+		if siteID.IsZero() {
+			return fmt.Errorf("%w: site_id is required to install a device", ErrInvalidDevice)
+		}
+		location := t.Location
+		if location == nil && siteID == d.SiteID {
+			location = d.Location
+		}
+		d.removeInstallation(at)
+		d.Installations = append(d.Installations, Installation{SiteID: siteID, Location: location, InstalledAt: at, Reason: t.Reason})
+		d.SiteID = siteID
+		d.Location = location
+		d.InstalledAt = at
+	case DeviceProcured:
+		d.removeInstallation(at)
+		d.SiteID = primitive.NilObjectID
+		d.Location = nil
+	case DeviceDecommissioned:
+		d.removeInstallation(at)
+		d.DecommissionedAt = at
+	}
+	d.Status = t.Status
+	return nil
+}
+
+// AcceptsData reports whether data taken at the given time may be ingested from the device.
+//
+// A decommissioned device only accepts data taken before its decommissioning.
+func (d *Device) AcceptsData(at time.Time) bool {
+	if d.Status != DeviceDecommissioned {
+		return true
+	}
+	return !d.DecommissionedAt.IsZero() && at.Before(d.DecommissionedAt)
+}
+
+// removeInstallation closes the open installation of the device.
+func (d *Device) removeInstallation(at time.Time) {
+	for i := range d.Installations {
+		if d.Installations[i].RemovedAt.IsZero() {
+			d.Installations[i].RemovedAt = at
+		}
+	}
+}
+
+// DeviceFilter narrows a device inventory query, zero values are ignored
+type DeviceFilter struct {
+	Status string
+	Model  string
+	SiteID primitive.ObjectID
+}
+
+// InventoryCount is the number of devices of a model in a lifecycle status
+type InventoryCount struct {
+	Status string `bson:"status" json:"status" example:"active"`
+	Model  string `bson:"model" json:"model"`
+	Count  int    `bson:"count" json:"count"`
+}
@@ -0,0 +1,50 @@
+package domain
+
+import "time"
+
+// Sensor diagnostics, each points at a fault of the sensor rather than a change of the process
+const (
+	DiagnosticFlatline     = "flatline"
+	DiagnosticStuckAtZero  = "stuck_at_zero"
+	DiagnosticJump         = "jump"
+	DiagnosticDrift        = "drift"
+	DiagnosticInconsistent = "inconsistent"
+)
+
+// Health statuses of a sensor derived from its health score
+const (
+	HealthUnknown  = "unknown"
+	HealthHealthy  = "healthy"
+	HealthDegraded = "degraded"
+	HealthFaulty   = "faulty"
+)
+
+// ParameterBound requires the value of Lower not to exceed the value of Upper in the same reading
+type ParameterBound struct {
+	Lower string
+	Upper string
+}
+
+// ParameterBounds are the physical orderings between parameters, e.g. the biodegradable part of
+// the organic load (BOD) cannot exceed the total chemically oxidisable load (COD)
+var ParameterBounds = []ParameterBound{
+	{Lower: ParamBOD, Upper: ParamCOD},
+	{Lower: ParamTOC, Upper: ParamCOD},
+	{Lower: ParamDOC, Upper: ParamTOC},
+}
+
+// SensorDiagnostic is a fault found in the recent measurements of a sensor
+type SensorDiagnostic struct {
+	Code      string    `json:"code" example:"flatline"`
+	Parameter string    `json:"parameter" example:"COD"`
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// SensorHealth summarises the diagnostics of a sensor in a score from 0 (faulty) to 100 (healthy)
+type SensorHealth struct {
+	Score       float64            `json:"score" example:"85"`
+	Status      string             `json:"status" example:"healthy"`
+	Samples     int                `json:"samples"`
+	Diagnostics []SensorDiagnostic `json:"diagnostics,omitempty"`
+}
@@ -0,0 +1,174 @@
+package domain
+
+import (
+	"errors"
+	"math"
+)
+
+// GeoJSON geometry types supported by sites and devices
+const (
+	GeoJSONPoint             = "Point"
+	GeoJSONPolygon           = "Polygon"
+	GeoJSONFeature           = "Feature"
+	GeoJSONFeatureCollection = "FeatureCollection"
+)
+
+// ErrInvalidGeometry is returned when a GeoJSON geometry is malformed
+var ErrInvalidGeometry = errors.New("invalid GeoJSON geometry")
+
+// GeoPoint represents a GeoJSON point, coordinates are ordered as [longitude, latitude]
+type GeoPoint struct {
+	Type        string    `bson:"type" json:"type" example:"Point"`
+	Coordinates []float64 `bson:"coordinates" json:"coordinates" example:"106.8227,-6.1935"`
+}
+
+// NewGeoPoint creates a GeoJSON point from a latitude and longitude pair.
+func NewGeoPoint(lat, lng float64) *GeoPoint {
+	return &GeoPoint{Type: GeoJSONPoint, Coordinates: []float64{lng, lat}}
+}
+
+// Validate checks that the point has a valid type and coordinate range.
+func (p *GeoPoint) Validate() error {
+	if p.Type != GeoJSONPoint || len(p.Coordinates) != 2 {
+		return ErrInvalidGeometry
+	}
+	return validatePosition(p.Coordinates)
+}
+
+// Equal reports whether two points are at the same position, a nil point only equals nil.
+func (p *GeoPoint) Equal(o *GeoPoint) bool {
+	if p == nil || o == nil {
+		return p == o
+	}
+	if p.Type != o.Type || len(p.Coordinates) != len(o.Coordinates) {
+		return false
+	}
+	for i := range p.Coordinates {
+		if p.Coordinates[i] != o.Coordinates[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Geometry represents a GeoJSON Point or Polygon.
+//
+// Coordinates holds [lng, lat] for a Point and a list of linear rings for a Polygon.
+type Geometry struct {
+	Type        string      `bson:"type" json:"type" example:"Polygon"`
+	Coordinates interface{} `bson:"coordinates" json:"coordinates" swaggertype:"array,number"`
+}
+
+// Validate checks that the geometry is a well formed GeoJSON Point or Polygon.
+func (g *Geometry) Validate() error {
+	switch g.Type {
+	case GeoJSONPoint:
+		position, ok := toPosition(g.Coordinates)
+		if !ok {
+			return ErrInvalidGeometry
+		}
+		return validatePosition(position)
+	case GeoJSONPolygon:
+		rings, ok := g.Coordinates.([]interface{})
+		if !ok || len(rings) == 0 {
+			return ErrInvalidGeometry
+		}
+		for _, ring := range rings {
+			if err := validateRing(ring); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return ErrInvalidGeometry
+	}
+}
+
+// NearQuery describes a proximity search around a point, radius is in meters
+type NearQuery struct {
+	Lat    float64
+	Lng    float64
+	Radius float64
+}
+
+// Validate checks that the query center is a valid position and the radius is positive.
+func (q *NearQuery) Validate() error {
+	if q.Radius <= 0 {
+		return ErrInvalidGeometry
+	}
+	return validatePosition([]float64{q.Lng, q.Lat})
+}
+
+// Feature represents a GeoJSON feature
+type Feature struct {
+	Type       string                 `json:"type" example:"Feature"`
+	ID         string                 `json:"id,omitempty"`
+	Geometry   *GeoPoint              `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// FeatureCollection represents a GeoJSON feature collection
+type FeatureCollection struct {
+	Type     string    `json:"type" example:"FeatureCollection"`
+	Features []Feature `json:"features"`
+}
+
+// validateRing checks that a polygon ring is closed and has at least four positions.
+func validateRing(ring interface{}) error {
+	positions, ok := ring.([]interface{})
+	if !ok || len(positions) < 4 {
+		return ErrInvalidGeometry
+	}
+	var first, last []float64
+	for i, p := range positions {
+		position, ok := toPosition(p)
+		if !ok {
+			return ErrInvalidGeometry
+		}
+		if err := validatePosition(position); err != nil {
+			return err
+		}
+		if i == 0 {
+			first = position
+		}
+		last = position
+	}
+	if first[0] != last[0] || first[1] != last[1] {
+		return ErrInvalidGeometry
+	}
+	return nil
+}
+
+// toPosition converts a decoded JSON or BSON array into a [lng, lat] position.
+func toPosition(v interface{}) ([]float64, bool) {
+	var values []interface{}
+	switch t := v.(type) {
+	case []float64:
+		return t, len(t) == 2
+	case []interface{}:
+		values = t
+	default:
+		return nil, false
+	}
+	if len(values) != 2 {
+		return nil, false
+	}
+	position := make([]float64, 0, 2)
+	for _, value := range values {
+		f, ok := value.(float64)
+		if !ok {
+			return nil, false
+		}
+		position = append(position, f)
+	}
+	return position, true
+}
+
+// validatePosition checks that a [lng, lat] position is within the WGS84 bounds.
+func validatePosition(position []float64) error {
+	lng, lat := position[0], position[1]
+	if math.IsNaN(lng) || math.IsNaN(lat) || lng < -180 || lng > 180 || lat < -90 || lat > 90 {
+		return ErrInvalidGeometry
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Content types of a SenML pack
+const (
+	SenMLJSON = "application/senml+json"
+	SenMLCBOR = "application/senml+cbor"
+)
+
+// DefaultDeviceTag is the line protocol tag holding the device name when the mapping sets none
+const DefaultDeviceTag = "device"
+
+// ErrInvalidIngest is returned when an ingest mapping or a whole gateway payload is invalid
+var ErrInvalidIngest = errors.New("invalid ingest data")
+
+// IngestMapping maps the names a third-party gateway sends onto devices and parameters.
+//
+// A SenML record is matched to the device whose name is the longest prefix of its resolved
+// name, the rest of the name is the parameter name. A line protocol point is matched to the
+// device named by its DeviceTag and each field to the parameter named measurement.field or field.
+type IngestMapping struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Source     string             `bson:"source" json:"source" example:"depot-gw-1"`
+	DeviceTag  string             `bson:"device_tag,omitempty" json:"device_tag,omitempty" example:"device"`
+	Devices    []DeviceMapping    `bson:"devices" json:"devices"`
+	Parameters []ParameterMapping `bson:"parameters,omitempty" json:"parameters,omitempty"`
+	UpdatedAt  MyTime             `bson:"updated_at" json:"updated_at" swaggertype:"string" time_format:"2006-01-02 15:04:05"`
+}
+
+// DeviceMapping maps a gateway name onto a device, directly or through one of its sensors
+type DeviceMapping struct {
+	Name     string             `bson:"name" json:"name" example:"urn:dev:mac:0024befffe804ff1:"`
+	DeviceID primitive.ObjectID `bson:"device_id,omitempty" json:"device_id,omitempty"`
+	SensorID primitive.ObjectID `bson:"sensor_id,omitempty" json:"sensor_id,omitempty"`
+}
+
+// ParameterMapping maps a gateway name onto a parameter.
+//
+// Unit is the unit of the values when the record carries none.
+type ParameterMapping struct {
+	Name      string `bson:"name" json:"name" example:"temp"`
+	Parameter string `bson:"parameter" json:"parameter" example:"Temperature"`
+	Unit      string `bson:"unit,omitempty" json:"unit,omitempty" example:"°C"`
+}
+
+// Validate checks the source and that every name is mapped once onto a device or a parameter.
+func (m *IngestMapping) Validate() error {
+	if m.Source == "" {
+		return fmt.Errorf("%w: source is required", ErrInvalidIngest)
+	}
+	if len(m.Devices) == 0 {
+		return fmt.Errorf("%w: a mapping needs devices", ErrInvalidIngest)
+	}
+	names := map[string]bool{}
+	for _, d := range m.Devices {
+		if d.Name == "" {
+			return fmt.Errorf("%w: a device mapping needs a name", ErrInvalidIngest)
+		}
+		if names[d.Name] {
+			return fmt.Errorf("%w: device %q is mapped twice", ErrInvalidIngest, d.Name)
+		}
+		names[d.Name] = true
+		if d.DeviceID.IsZero() == d.SensorID.IsZero() {
+			return fmt.Errorf("%w: device %q needs either a device_id or a sensor_id", ErrInvalidIngest, d.Name)
+		}
+	}
+	names = map[string]bool{}
+	for _, p := range m.Parameters {
+		if p.Name == "" || p.Parameter == "" {
+			return fmt.Errorf("%w: a parameter mapping needs a name and a parameter", ErrInvalidIngest)
+		}
+		if names[p.Name] {
+			return fmt.Errorf("%w: parameter %q is mapped twice", ErrInvalidIngest, p.Name)
+		}
+		names[p.Name] = true
+	}
+	return nil
+}
+
+// SenMLRecord is a record of a SenML pack (RFC 8428), base fields apply to the records that follow
+type SenMLRecord struct {
+	BaseName    *string  `json:"bn,omitempty"`
+	BaseTime    *float64 `json:"bt,omitempty"`
+	BaseUnit    *string  `json:"bu,omitempty"`
+	BaseValue   *float64 `json:"bv,omitempty"`
+	BaseVersion *int     `json:"bver,omitempty"`
+	Name        string   `json:"n,omitempty" example:"temp"`
+	Unit        string   `json:"u,omitempty" example:"Cel"`
+	Value       *float64 `json:"v,omitempty" example:"23.1"`
+	StringValue *string  `json:"vs,omitempty"`
+	BoolValue   *bool    `json:"vb,omitempty"`
+	DataValue   *string  `json:"vd,omitempty"`
+	Sum         *float64 `json:"s,omitempty"`
+	Time        float64  `json:"t,omitempty"`
+	UpdateTime  float64  `json:"ut,omitempty"`
+}
+
+// IngestResult is the outcome of a gateway payload, the readings stored and the records left out
+type IngestResult struct {
+	Readings []IngestReadingResult `json:"readings"`
+	Errors   []IngestRecordError   `json:"errors,omitempty"`
+}
+
+// IngestReadingResult is the outcome of the reading made of the records of a device at a time
+type IngestReadingResult struct {
+	BatchItemResult
+	DeviceID  primitive.ObjectID `json:"device_id"`
+	Timestamp time.Time          `json:"timestamp"`
+	// Records are the positions of the records in the pack, the line numbers for line protocol
+	Records []int `json:"records"`
+}
+
+// IngestRecordError is the reason a record was left out
+type IngestRecordError struct {
+	Record int    `json:"record" example:"3"`
+	Name   string `json:"name,omitempty" example:"urn:dev:mac:0024befffe804ff1:temp"`
+	Error  string `json:"error" example:"no parameter mapped to temp"`
+}
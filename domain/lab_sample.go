@@ -0,0 +1,153 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Chain-of-custody statuses of a lab sample, from collection in the field to the issued report
+const (
+	CustodyCollected = "collected"
+	CustodyInTransit = "in_transit"
+	CustodyReceived  = "received"
+	CustodyAnalysed  = "analysed"
+	CustodyReported  = "reported"
+	CustodyRejected  = "rejected"
+)
+
+// ErrInvalidLabSample is returned when a lab sample has no sample ID, site or sampling time, an
+// unknown custody status or an incomplete result
+var ErrInvalidLabSample = errors.New("invalid lab sample")
+
+// IsCustodyStatus reports whether the status is a known chain-of-custody status.
+func IsCustodyStatus(status string) bool {
+	switch status {
+	case CustodyCollected, CustodyInTransit, CustodyReceived, CustodyAnalysed, CustodyReported, CustodyRejected:
+		return true
+	}
+	return false
+}
+
+// LabResult is the value of a parameter reported by the laboratory
+type LabResult struct {
+	Parameter string  `bson:"parameter" json:"parameter" example:"BOD"`
+	Value     float64 `bson:"value" json:"value" example:"12.5"`
+	Unit      string  `bson:"unit" json:"unit" example:"mg/L"`
+}
+
+// LabSample represents a grab or composite sample analysed by an accredited laboratory
+type LabSample struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	SampleID      string             `bson:"sample_id" json:"sample_id" example:"LAB-2024-0001"`
+	SiteID        primitive.ObjectID `bson:"site_id" json:"site_id"`
+	DeviceID      primitive.ObjectID `bson:"device_id,omitempty" json:"device_id,omitempty"`
+	SampledAt     time.Time          `bson:"sampled_at" json:"sampled_at"`
+	AnalysedAt    time.Time          `bson:"analysed_at,omitempty" json:"analysed_at"`
+	Lab           string             `bson:"lab" json:"lab"`
+	Method        string             `bson:"method" json:"method" example:"APHA 5210 B"`
+	Results       []LabResult        `bson:"results" json:"results"`
+	CustodyStatus string             `bson:"custody_status" json:"custody_status" example:"received"`
+	CreatedAt     MyTime             `bson:"created_at" json:"created_at" swaggertype:"string" time_format:"2006-01-02 15:04:05"`
+	UpdatedAt     MyTime             `bson:"updated_at" json:"updated_at" swaggertype:"string" time_format:"2006-01-02 15:04:05"`
+}
+
+// Validate checks the lab sample and defaults its custody status to collected.
+func (s *LabSample) Validate() error {
+	return validateLabSample(s.SampleID, s.SiteID, s.SampledAt, s.AnalysedAt, &s.CustodyStatus, s.Results)
+}
+
+type LabSampleRequest struct {
+	SampleID      string             `bson:"sample_id" json:"sample_id" example:"LAB-2024-0001"`
+	SiteID        primitive.ObjectID `bson:"site_id" json:"site_id"`
+	DeviceID      primitive.ObjectID `bson:"device_id,omitempty" json:"device_id,omitempty"`
+	SampledAt     time.Time          `bson:"sampled_at" json:"sampled_at"`
+	AnalysedAt    time.Time          `bson:"analysed_at,omitempty" json:"analysed_at"`
+	Lab           string             `bson:"lab" json:"lab"`
+	Method        string             `bson:"method" json:"method" example:"APHA 5210 B"`
+	Results       []LabResult        `bson:"results" json:"results"`
+	CustodyStatus string             `bson:"custody_status" json:"custody_status" example:"received"`
+	CreatedAt     MyTime             `bson:"created_at" json:"created_at" swaggertype:"string" time_format:"2006-01-02 15:04:05"`
+	UpdatedAt     MyTime             `bson:"updated_at" json:"updated_at" swaggertype:"string" time_format:"2006-01-02 15:04:05"`
+}
+
+// Validate checks the lab sample and defaults its custody status to collected.
+func (s *LabSampleRequest) Validate() error {
+	return validateLabSample(s.SampleID, s.SiteID, s.SampledAt, s.AnalysedAt, &s.CustodyStatus, s.Results)
+}
+
+func validateLabSample(sampleID string, siteID primitive.ObjectID, sampledAt, analysedAt time.Time, custodyStatus *string, results []LabResult) error {
+	if sampleID == "" {
+		return fmt.Errorf("%w: sample_id is required", ErrInvalidLabSample)
+	}
+	if siteID.IsZero() {
+		return fmt.Errorf("%w: site_id is required", ErrInvalidLabSample)
+	}
+	if sampledAt.IsZero() {
+		return fmt.Errorf("%w: sampled_at is required", ErrInvalidLabSample)
+	}
+	if !analysedAt.IsZero() && analysedAt.Before(sampledAt) {
+		return fmt.Errorf("%w: analysed_at is before sampled_at", ErrInvalidLabSample)
+	}
+	if *custodyStatus == "" {
+		*custodyStatus = CustodyCollected
+	}
+	if !IsCustodyStatus(*custodyStatus) {
+		return fmt.Errorf("%w: unknown custody status %q", ErrInvalidLabSample, *custodyStatus)
+	}
+	for _, r := range results {
+		if r.Parameter == "" {
+			return fmt.Errorf("%w: parameter is required", ErrInvalidLabSample)
+		}
+	}
+	return nil
+}
+
+// LabSampleFilter narrows a lab sample query, zero values are ignored
+type LabSampleFilter struct {
+	SiteID   primitive.ObjectID
+	DeviceID primitive.ObjectID
+	From     time.Time
+	To       time.Time
+}
+
+// LabCorrelationFilter selects the lab samples paired with sensor readings
+type LabCorrelationFilter struct {
+	SiteID    primitive.ObjectID
+	Parameter string
+	From      time.Time
+	To        time.Time
+	// MaxGap is the largest time between a sample and the sensor reading it is paired with
+	MaxGap time.Duration
+}
+
+// LabSensorPair is a lab result paired with the sensor measurement nearest in time
+type LabSensorPair struct {
+	SampleID      string             `json:"sample_id"`
+	SampledAt     time.Time          `json:"sampled_at"`
+	MeasurementID primitive.ObjectID `json:"measurement_id"`
+	DeviceID      primitive.ObjectID `json:"device_id"`
+	MeasuredAt    time.Time          `json:"measured_at"`
+	LabValue      float64            `json:"lab_value"`
+	SensorValue   float64            `json:"sensor_value"`
+}
+
+// LabCorrelation reports the agreement of the sensors with the lab for one parameter.
+//
+// Bias is the mean of sensor minus lab values and RelativeBias that mean as a percentage of
+// the mean lab value. Slope, Intercept and R2 are the least squares fit of the sensor values
+// on the lab values.
+type LabCorrelation struct {
+	Parameter    string          `json:"parameter" example:"BOD"`
+	Unit         string          `json:"unit" example:"mg/L"`
+	Count        int             `json:"count"`
+	Bias         float64         `json:"bias"`
+	RelativeBias float64         `json:"relative_bias"`
+	RMSE         float64         `json:"rmse"`
+	Slope        float64         `json:"slope"`
+	Intercept    float64         `json:"intercept"`
+	R2           float64         `json:"r2"`
+	Pairs        []LabSensorPair `json:"pairs"`
+}
@@ -0,0 +1,324 @@
+package domain
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Payload codec types
+const (
+	// CodecCayenneLPP decodes Cayenne Low Power Payload frames
+	CodecCayenneLPP = "cayenne_lpp"
+	// CodecByteLayout decodes values at fixed positions of the payload
+	CodecByteLayout = "byte_layout"
+)
+
+// Data types of a byte layout field
+const (
+	ByteUint8   = "uint8"
+	ByteInt8    = "int8"
+	ByteUint16  = "uint16"
+	ByteInt16   = "int16"
+	ByteUint32  = "uint32"
+	ByteInt32   = "int32"
+	ByteFloat32 = "float32"
+)
+
+// Byte orders of a byte layout field
+const (
+	BigEndian    = "big"
+	LittleEndian = "little"
+)
+
+// ErrInvalidLoRaWAN is returned when a DevEUI, a payload codec or an uplink is invalid
+var ErrInvalidLoRaWAN = errors.New("invalid lorawan data")
+
+// NormalizeDevEUI returns the DevEUI as 16 upper case hex digits.
+//
+// Separators such as - and : are ignored, an empty DevEUI stays empty.
+func NormalizeDevEUI(devEUI string) (string, error) {
+	normalized := strings.ToUpper(strings.NewReplacer("-", "", ":", "", " ", "").Replace(devEUI))
+	if normalized == "" {
+		return "", nil
+	}
+	if _, err := hex.DecodeString(normalized); err != nil || len(normalized) != 16 {
+		return "", fmt.Errorf("%w: dev_eui %q is not 8 hex bytes", ErrInvalidLoRaWAN, devEUI)
+	}
+	return normalized, nil
+}
+
+// LPPChannel maps a Cayenne LPP channel onto a parameter.
+//
+// Values of multi-axis types get a suffix, _x, _y and _z for the accelerometer and the gyrometer,
+// _lat, _lng and _alt for GPS.
+type LPPChannel struct {
+	Channel   uint8  `bson:"channel" json:"channel" example:"1"`
+	Parameter string `bson:"parameter" json:"parameter" example:"Temperature"`
+}
+
+// ByteField maps bytes of a payload onto a parameter.
+//
+// The value is raw * scale + offset, a zero scale counts as 1.
+type ByteField struct {
+	Parameter string  `bson:"parameter" json:"parameter" example:"pH"`
+	Start     int     `bson:"start" json:"start" example:"0"`
+	DataType  string  `bson:"data_type" json:"data_type" example:"uint16"`
+	ByteOrder string  `bson:"byte_order,omitempty" json:"byte_order,omitempty" example:"big"`
+	Scale     float64 `bson:"scale,omitempty" json:"scale,omitempty" example:"0.01"`
+	Offset    float64 `bson:"offset,omitempty" json:"offset,omitempty"`
+}
+
+// Size returns the number of bytes of the field, 0 for an unknown data type.
+func (f ByteField) Size() int {
+	switch f.DataType {
+	case ByteUint8, ByteInt8:
+		return 1
+	case ByteUint16, ByteInt16:
+		return 2
+	case ByteUint32, ByteInt32, ByteFloat32:
+		return 4
+	}
+	return 0
+}
+
+// PayloadCodec decodes the uplinks of a device model.
+type PayloadCodec struct {
+	ID    primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Model string             `bson:"model" json:"model" example:"OUT-LR1"`
+	Type  string             `bson:"type" json:"type" example:"cayenne_lpp"`
+	// FPort is the port of the measurement uplinks, uplinks on other ports are not decoded, 0 decodes every port
+	FPort     int          `bson:"f_port,omitempty" json:"f_port,omitempty" example:"1"`
+	Channels  []LPPChannel `bson:"channels,omitempty" json:"channels,omitempty"`
+	Fields    []ByteField  `bson:"fields,omitempty" json:"fields,omitempty"`
+	UpdatedAt MyTime       `bson:"updated_at" json:"updated_at" swaggertype:"string" time_format:"2006-01-02 15:04:05"`
+}
+
+// Validate checks the model and the type of the codec and the mapping the type needs.
+func (c *PayloadCodec) Validate() error {
+	if c.Model == "" {
+		return fmt.Errorf("%w: model is required", ErrInvalidLoRaWAN)
+	}
+	if c.FPort < 0 || c.FPort > 255 {
+		return fmt.Errorf("%w: f_port must be between 0 and 255", ErrInvalidLoRaWAN)
+	}
+	switch c.Type {
+	case CodecCayenneLPP:
+		if len(c.Channels) == 0 {
+			return fmt.Errorf("%w: a cayenne_lpp codec needs channels", ErrInvalidLoRaWAN)
+		}
+		for _, channel := range c.Channels {
+			if channel.Parameter == "" {
+				return fmt.Errorf("%w: channel %d has no parameter", ErrInvalidLoRaWAN, channel.Channel)
+			}
+		}
+	case CodecByteLayout:
+		if len(c.Fields) == 0 {
+			return fmt.Errorf("%w: a byte_layout codec needs fields", ErrInvalidLoRaWAN)
+		}
+		for _, field := range c.Fields {
+			if field.Parameter == "" {
+				return fmt.Errorf("%w: a field has no parameter", ErrInvalidLoRaWAN)
+			}
+			if field.Size() == 0 {
+				return fmt.Errorf("%w: %s has an unknown data type %q", ErrInvalidLoRaWAN, field.Parameter, field.DataType)
+			}
+			if field.Start < 0 {
+				return fmt.Errorf("%w: %s starts before the payload", ErrInvalidLoRaWAN, field.Parameter)
+			}
+			switch field.ByteOrder {
+			case "", BigEndian, LittleEndian:
+			default:
+				return fmt.Errorf("%w: %s has an unknown byte order %q", ErrInvalidLoRaWAN, field.Parameter, field.ByteOrder)
+			}
+		}
+	default:
+		return fmt.Errorf("%w: unknown codec type %q", ErrInvalidLoRaWAN, c.Type)
+	}
+	return nil
+}
+
+// RadioMetadata describes how an uplink was received, the gateway being the one with the best signal
+type RadioMetadata struct {
+	GatewayID       string  `bson:"gateway_id,omitempty" json:"gateway_id,omitempty" example:"0016c001f153a14c"`
+	RSSI            int     `bson:"rssi" json:"rssi" example:"-57"`
+	SNR             float64 `bson:"snr" json:"snr" example:"9.5"`
+	Gateways        int     `bson:"gateways" json:"gateways" example:"2"`
+	Frequency       int64   `bson:"frequency,omitempty" json:"frequency,omitempty" example:"868100000"`
+	SpreadingFactor int     `bson:"spreading_factor,omitempty" json:"spreading_factor,omitempty" example:"7"`
+}
+
+// gatewayReception is the reception of an uplink by a gateway
+type gatewayReception struct {
+	gatewayID string
+	rssi      int
+	snr       float64
+}
+
+// newRadioMetadata summarizes the receptions by the gateway with the best RSSI.
+func newRadioMetadata(receptions []gatewayReception, frequency int64, spreadingFactor int) RadioMetadata {
+	radio := RadioMetadata{Gateways: len(receptions), Frequency: frequency, SpreadingFactor: spreadingFactor}
+	for i, r := range receptions {
+		if i == 0 || r.rssi > radio.RSSI {
+			radio.GatewayID, radio.RSSI, radio.SNR = r.gatewayID, r.rssi, r.snr
+		}
+	}
+	return radio
+}
+
+// Uplink is a LoRaWAN uplink as forwarded by a network server
+type Uplink struct {
+	// ID identifies the uplink across the retries of the webhook
+	ID         string
+	DevEUI     string
+	FPort      int
+	FCnt       uint32
+	Payload    []byte
+	ReceivedAt time.Time
+	Radio      RadioMetadata
+}
+
+// UplinkRecord is a stored uplink with its radio metadata and the reading decoded from it.
+//
+// Error tells why no reading was decoded.
+type UplinkRecord struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	DeviceID   primitive.ObjectID `bson:"device_id" json:"device_id"`
+	DevEUI     string             `bson:"dev_eui" json:"dev_eui" example:"70B3D57ED0001234"`
+	ReadingID  primitive.ObjectID `bson:"reading_id,omitempty" json:"reading_id,omitempty"`
+	FPort      int                `bson:"f_port" json:"f_port" example:"1"`
+	FCnt       uint32             `bson:"f_cnt" json:"f_cnt" example:"42"`
+	Payload    []byte             `bson:"payload" json:"payload" swaggertype:"string" format:"base64"`
+	Values     map[string]float64 `bson:"values,omitempty" json:"values,omitempty"`
+	Radio      RadioMetadata      `bson:"radio" json:"radio"`
+	Error      string             `bson:"error,omitempty" json:"error,omitempty"`
+	ReceivedAt time.Time          `bson:"received_at" json:"received_at"`
+}
+
+// ChirpStackUplink is the up event of a ChirpStack v4 HTTP integration
+type ChirpStackUplink struct {
+	DeduplicationID string    `json:"deduplicationId"`
+	Time            time.Time `json:"time"`
+	DeviceInfo      struct {
+		DevEUI string `json:"devEui"`
+	} `json:"deviceInfo"`
+	FCnt   uint32 `json:"fCnt"`
+	FPort  int    `json:"fPort"`
+	Data   []byte `json:"data" swaggertype:"string" format:"base64"`
+	RxInfo []struct {
+		GatewayID string  `json:"gatewayId"`
+		RSSI      int     `json:"rssi"`
+		SNR       float64 `json:"snr"`
+	} `json:"rxInfo"`
+	TxInfo struct {
+		Frequency  int64 `json:"frequency"`
+		Modulation struct {
+			LoRa struct {
+				SpreadingFactor int `json:"spreadingFactor"`
+			} `json:"lora"`
+		} `json:"modulation"`
+	} `json:"txInfo"`
+}
+
+// Uplink converts the event, an event without a time is stamped with the time it is received.
+func (e *ChirpStackUplink) Uplink() (*Uplink, error) {
+	devEUI, err := NormalizeDevEUI(e.DeviceInfo.DevEUI)
+	if err != nil {
+		return nil, err
+	}
+	if devEUI == "" {
+		return nil, fmt.Errorf("%w: deviceInfo.devEui is required", ErrInvalidLoRaWAN)
+	}
+	receptions := make([]gatewayReception, len(e.RxInfo))
+	for i, rx := range e.RxInfo {
+		receptions[i] = gatewayReception{gatewayID: rx.GatewayID, rssi: rx.RSSI, snr: rx.SNR}
+	}
+	u := &Uplink{
+		ID:         e.DeduplicationID,
+		DevEUI:     devEUI,
+		FPort:      e.FPort,
+		FCnt:       e.FCnt,
+		Payload:    e.Data,
+		ReceivedAt: e.Time,
+		Radio:      newRadioMetadata(receptions, e.TxInfo.Frequency, e.TxInfo.Modulation.LoRa.SpreadingFactor),
+	}
+	if u.ReceivedAt.IsZero() {
+		u.ReceivedAt = time.Now()
+	}
+	return u, nil
+}
+
+// TTSUplink is the uplink message of a The Things Stack webhook
+type TTSUplink struct {
+	EndDeviceIDs struct {
+		DeviceID string `json:"device_id"`
+		DevEUI   string `json:"dev_eui"`
+	} `json:"end_device_ids"`
+	ReceivedAt    time.Time `json:"received_at"`
+	UplinkMessage *struct {
+		FPort      int    `json:"f_port"`
+		FCnt       uint32 `json:"f_cnt"`
+		FRMPayload []byte `json:"frm_payload" swaggertype:"string" format:"base64"`
+		RxMetadata []struct {
+			GatewayIDs struct {
+				GatewayID string `json:"gateway_id"`
+				EUI       string `json:"eui"`
+			} `json:"gateway_ids"`
+			RSSI int     `json:"rssi"`
+			SNR  float64 `json:"snr"`
+		} `json:"rx_metadata"`
+		Settings struct {
+			DataRate struct {
+				LoRa struct {
+					SpreadingFactor int `json:"spreading_factor"`
+				} `json:"lora"`
+			} `json:"data_rate"`
+			// Frequency is a string in the webhook messages
+			Frequency string `json:"frequency"`
+		} `json:"settings"`
+		ReceivedAt time.Time `json:"received_at"`
+	} `json:"uplink_message"`
+}
+
+// Uplink converts the message, nil when it is not an uplink message.
+func (m *TTSUplink) Uplink() (*Uplink, error) {
+	if m.UplinkMessage == nil {
+		return nil, nil
+	}
+	devEUI, err := NormalizeDevEUI(m.EndDeviceIDs.DevEUI)
+	if err != nil {
+		return nil, err
+	}
+	if devEUI == "" {
+		return nil, fmt.Errorf("%w: end_device_ids.dev_eui is required", ErrInvalidLoRaWAN)
+	}
+	message := m.UplinkMessage
+	receptions := make([]gatewayReception, len(message.RxMetadata))
+	for i, rx := range message.RxMetadata {
+		receptions[i] = gatewayReception{gatewayID: rx.GatewayIDs.GatewayID, rssi: rx.RSSI, snr: rx.SNR}
+	}
+	frequency, _ := strconv.ParseInt(message.Settings.Frequency, 10, 64)
+	u := &Uplink{
+		DevEUI:     devEUI,
+		FPort:      message.FPort,
+		FCnt:       message.FCnt,
+		Payload:    message.FRMPayload,
+		ReceivedAt: message.ReceivedAt,
+		Radio:      newRadioMetadata(receptions, frequency, message.Settings.DataRate.LoRa.SpreadingFactor),
+	}
+	if u.ReceivedAt.IsZero() {
+		u.ReceivedAt = m.ReceivedAt
+	}
+	if !u.ReceivedAt.IsZero() {
+		// The webhook is retried with the same message, the frame counter alone restarts on every join
+		u.ID = fmt.Sprintf("%s-%d-%d", devEUI, u.FCnt, u.ReceivedAt.UnixNano())
+	} else {
+		u.ReceivedAt = time.Now()
+	}
+	return u, nil
+}
@@ -0,0 +1,160 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Work order types of the maintenance log
+const (
+	WorkOrderCleaning         = "cleaning"
+	WorkOrderProbeReplacement = "probe_replacement"
+	WorkOrderInspection       = "inspection"
+	WorkOrderRepair           = "repair"
+	WorkOrderOutage           = "outage"
+)
+
+// Work order statuses, a work order is planned, then in progress and finally done
+const (
+	WorkOrderPlanned    = "planned"
+	WorkOrderInProgress = "in_progress"
+	WorkOrderDone       = "done"
+)
+
+// ErrInvalidWorkOrder is returned when a work order has no device or schedule, an unknown type or
+// status, or an inconsistent time window
+var ErrInvalidWorkOrder = errors.New("invalid work order")
+
+// ErrWorkOrderTransition is returned when a work order status skips or reverses a step, or the
+// work order changed since it was read
+var ErrWorkOrderTransition = errors.New("work order transition not allowed")
+
+// workOrderSteps is the position of each status in the work order lifecycle
+var workOrderSteps = map[string]int{
+	WorkOrderPlanned:    0,
+	WorkOrderInProgress: 1,
+	WorkOrderDone:       2,
+}
+
+// CanAdvance reports whether a work order may move from one status to another, a status is kept
+// or moved one step forward.
+func CanAdvance(from, to string) bool {
+	step, ok := workOrderSteps[to]
+	if !ok {
+		return false
+	}
+	return step == workOrderSteps[from] || step == workOrderSteps[from]+1
+}
+
+// TimeWindow is a period of time, a zero End leaves the window open
+type TimeWindow struct {
+	Start time.Time `bson:"start" json:"start"`
+	End   time.Time `bson:"end,omitempty" json:"end"`
+}
+
+// Overlaps reports whether the window shares an instant with the period from-to, zero bounds are unbounded.
+func (w TimeWindow) Overlaps(from, to time.Time) bool {
+	if !to.IsZero() && w.Start.After(to) {
+		return false
+	}
+	return w.End.IsZero() || from.IsZero() || !w.End.Before(from)
+}
+
+// Contains reports whether the instant lies within the window.
+func (w TimeWindow) Contains(t time.Time) bool {
+	return w.Overlaps(t, t)
+}
+
+// Attachment describes a file attached to a work order, the file itself is stored elsewhere
+type Attachment struct {
+	Name        string    `bson:"name" json:"name" example:"probe.jpg"`
+	ContentType string    `bson:"content_type" json:"content_type" example:"image/jpeg"`
+	Size        int64     `bson:"size" json:"size"`
+	URL         string    `bson:"url" json:"url"`
+	UploadedAt  time.Time `bson:"uploaded_at" json:"uploaded_at"`
+}
+
+// WorkOrder is a maintenance task on a device.
+//
+// Readings of the device taken during its Downtime are marked as maintenance affected.
+type WorkOrder struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	DeviceID    primitive.ObjectID `bson:"device_id" json:"device_id"`
+	SensorID    primitive.ObjectID `bson:"sensor_id,omitempty" json:"sensor_id,omitempty"`
+	Type        string             `bson:"type" json:"type" example:"cleaning"`
+	Title       string             `bson:"title" json:"title"`
+	Description string             `bson:"description" json:"description"`
+	Status      string             `bson:"status" json:"status" example:"planned"`
+	Assignees   []string           `bson:"assignees,omitempty" json:"assignees,omitempty"`
+	Schedule    TimeWindow         `bson:"schedule" json:"schedule"`
+	Downtime    *TimeWindow        `bson:"downtime,omitempty" json:"downtime,omitempty"`
+	StartedAt   time.Time          `bson:"started_at,omitempty" json:"started_at"`
+	CompletedAt time.Time          `bson:"completed_at,omitempty" json:"completed_at"`
+	Attachments []Attachment       `bson:"attachments,omitempty" json:"attachments,omitempty"`
+	CreatedAt   MyTime             `bson:"created_at" json:"created_at" swaggertype:"string" time_format:"2006-01-02 15:04:05"`
+	UpdatedAt   MyTime             `bson:"updated_at" json:"updated_at" swaggertype:"string" time_format:"2006-01-02 15:04:05"`
+}
+
+// Validate checks the work order, defaults its status to planned and the end of its schedule to its start.
+func (w *WorkOrder) Validate() error {
+	if w.DeviceID.IsZero() {
+		return fmt.Errorf("%w: device_id is required", ErrInvalidWorkOrder)
+	}
+	switch w.Type {
+	case WorkOrderCleaning, WorkOrderProbeReplacement, WorkOrderInspection, WorkOrderRepair, WorkOrderOutage:
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidWorkOrder, w.Type)
+	}
+	if w.Status == "" {
+		w.Status = WorkOrderPlanned
+	}
+	switch w.Status {
+	case WorkOrderPlanned, WorkOrderInProgress, WorkOrderDone:
+	default:
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidWorkOrder, w.Status)
+	}
+	if w.Schedule.Start.IsZero() {
+		return fmt.Errorf("%w: schedule start is required", ErrInvalidWorkOrder)
+	}
+	if w.Schedule.End.IsZero() {
+		w.Schedule.End = w.Schedule.Start
+	}
+	if w.Schedule.End.Before(w.Schedule.Start) {
+		return fmt.Errorf("%w: schedule ends before it starts", ErrInvalidWorkOrder)
+	}
+	if w.Downtime != nil {
+		if w.Downtime.Start.IsZero() {
+			return fmt.Errorf("%w: downtime start is required", ErrInvalidWorkOrder)
+		}
+		if !w.Downtime.End.IsZero() && w.Downtime.End.Before(w.Downtime.Start) {
+			return fmt.Errorf("%w: downtime ends before it starts", ErrInvalidWorkOrder)
+		}
+	}
+	return nil
+}
+
+// MaintenanceFilter narrows a work order query, zero values are ignored.
+//
+// From and To select the work orders whose schedule or downtime overlaps the period.
+type MaintenanceFilter struct {
+	DeviceID primitive.ObjectID
+	Status   string
+	From     time.Time
+	To       time.Time
+}
+
+// CalendarEntry is a scheduled work or a downtime window of a work order in the maintenance calendar
+type CalendarEntry struct {
+	WorkOrderID primitive.ObjectID `json:"work_order_id"`
+	DeviceID    primitive.ObjectID `json:"device_id"`
+	Type        string             `json:"type" example:"cleaning"`
+	Title       string             `json:"title"`
+	Status      string             `json:"status" example:"planned"`
+	Assignees   []string           `json:"assignees,omitempty"`
+	Downtime    bool               `json:"downtime"`
+	Start       time.Time          `json:"start"`
+	End         time.Time          `json:"end"`
+}
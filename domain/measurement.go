@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrInvalidMeasurement is returned when a measurement has no parameter
+var ErrInvalidMeasurement = errors.New("invalid measurement")
+
+// ErrUnknownUnit is returned when a value is declared in a unit its parameter cannot be converted from
+var ErrUnknownUnit = errors.New("unknown unit")
+
+// ErrUnknownUnitSystem is returned when values are requested in a unit system that does not exist
+var ErrUnknownUnitSystem = errors.New("unknown unit system")
+
+// Measurement represents a single parameter value reported by a sensor.
+//
+// Measurements ingested together share a ReadingID, which is the ID of the
+// corresponding WasteWaterData in the /waste-water compatibility view. Value is
+// calibration corrected when the sensor has an active calibration, the value as
+// reported is then kept in RawValue. Maintenance marks values taken during the
+// downtime of a work order.
+type Measurement struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ReadingID     primitive.ObjectID `bson:"reading_id" json:"reading_id"`
+	Timestamp     time.Time          `bson:"timestamp" json:"timestamp"`
+	DeviceID      primitive.ObjectID `bson:"device_id" json:"device_id"`
+	SensorID      primitive.ObjectID `bson:"sensor_id,omitempty" json:"sensor_id,omitempty"`
+	Parameter     string             `bson:"parameter" json:"parameter" example:"pH"`
+	Value         float64            `bson:"value" json:"value" example:"7.2"`
+	RawValue      *float64           `bson:"raw_value,omitempty" json:"raw_value,omitempty" example:"7.15"`
+	CalibrationID primitive.ObjectID `bson:"calibration_id,omitempty" json:"calibration_id,omitempty"`
+	Unit          string             `bson:"unit" json:"unit" example:"pH"`
+	Quality       string             `bson:"quality" json:"quality" example:"raw"`
+	Checks        []string           `bson:"checks,omitempty" json:"checks,omitempty"`
+	Anomalies     []string           `bson:"anomalies,omitempty" json:"anomalies,omitempty"`
+	OutOfRange    bool               `bson:"out_of_range,omitempty" json:"out_of_range,omitempty"`
+	Maintenance   bool               `bson:"maintenance,omitempty" json:"maintenance,omitempty"`
+	WorkOrderID   primitive.ObjectID `bson:"work_order_id,omitempty" json:"work_order_id,omitempty"`
+}
+
+// MeasurementFilter narrows a measurement query, zero values are ignored
+type MeasurementFilter struct {
+	ReadingID primitive.ObjectID
+	DeviceID  primitive.ObjectID
+	SensorID  primitive.ObjectID
+	Parameter string
+	Quality   []string
+	From      time.Time
+	To        time.Time
+}
+
+// DeviceParameter is a parameter measured by one of the sensors of a device
+type DeviceParameter struct {
+	SensorID primitive.ObjectID
+	ParameterSpec
+}
+
+// NewWasteWaterData pivots the measurements of a reading into the wide waste water shape.
+//
+// Parameters without a WasteWaterData field are returned in Parameters and the
+// quality flag of each parameter in Quality.
+func NewWasteWaterData(readingID primitive.ObjectID, measurements []Measurement) WasteWaterData {
+	w := WasteWaterData{ID: readingID}
+	for i, m := range measurements {
+		if i == 0 {
+			w.DeviceID = m.DeviceID
+			w.Timestamp = m.Timestamp
+		}
+		if !w.SetValue(m.Parameter, m.Value) {
+			if w.Parameters == nil {
+				w.Parameters = map[string]float64{}
+			}
+			w.Parameters[m.Parameter] = m.Value
+		}
+		if m.OutOfRange {
+			w.OutOfRange = append(w.OutOfRange, m.Parameter)
+		}
+		if len(m.Anomalies) > 0 {
+			w.Anomalies = append(w.Anomalies, m.Parameter)
+		}
+		if m.Maintenance {
+			w.Maintenance = true
+		}
+		if m.Quality != "" {
+			if w.Quality == nil {
+				w.Quality = map[string]string{}
+			}
+			w.Quality[m.Parameter] = m.Quality
+		}
+	}
+	return w
+}
+
+// NewMeasurements unpivots a wide reading into one measurement per parameter.
+//
+// The WasteWaterData parameters in values come first in the order of the fields, followed by the
+// extra parameters, a parameter missing from values gets no measurement.
+func NewMeasurements(readingID, deviceID primitive.ObjectID, timestamp time.Time, values, extra map[string]float64) []Measurement {
+	measurements := make([]Measurement, 0, len(values)+len(extra))
+	for _, parameter := range WasteWaterParameters {
+		value, ok := values[parameter]
+		if !ok {
+			continue
+		}
+		measurements = append(measurements, Measurement{
+			ReadingID: readingID,
+			Timestamp: timestamp,
+			DeviceID:  deviceID,
+			Parameter: parameter,
+			Value:     value,
+		})
+	}
+	extraParameters := make([]string, 0, len(extra))
+	for parameter := range extra {
+		if _, ok := values[parameter]; !ok {
+			extraParameters = append(extraParameters, parameter)
+		}
+	}
+	sort.Strings(extraParameters)
+	for _, parameter := range extraParameters {
+		measurements = append(measurements, Measurement{
+			ReadingID: readingID,
+			Timestamp: timestamp,
+			DeviceID:  deviceID,
+			Parameter: parameter,
+			Value:     extra[parameter],
+		})
+	}
+	return measurements
+}
@@ -0,0 +1,225 @@
+package domain
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Modbus transports of a device
+const (
+	ModbusTCP = "tcp"
+	ModbusRTU = "rtu"
+)
+
+// Modbus register tables a value is read from
+const (
+	ModbusHolding = "holding"
+	ModbusInput   = "input"
+)
+
+// Modbus data types of a register value, the 32 and 64 bit types span 2 and 4 registers
+const (
+	ModbusUint16  = "uint16"
+	ModbusInt16   = "int16"
+	ModbusUint32  = "uint32"
+	ModbusInt32   = "int32"
+	ModbusFloat32 = "float32"
+	ModbusFloat64 = "float64"
+)
+
+// Modbus byte orders of a value, A being its most significant byte.
+//
+// ABCD is big-endian as in the Modbus specification, CDAB swaps the registers, BADC swaps the bytes
+// of each register and DCBA is little-endian.
+const (
+	ModbusABCD = "ABCD"
+	ModbusCDAB = "CDAB"
+	ModbusBADC = "BADC"
+	ModbusDCBA = "DCBA"
+)
+
+// ModbusMaxRegisters is the number of registers a single read returns at most
+const ModbusMaxRegisters = 125
+
+// ErrInvalidModbus is returned when a register map or a Modbus endpoint is inconsistent
+var ErrInvalidModbus = errors.New("invalid modbus configuration")
+
+// ModbusRegister maps registers of an instrument onto a parameter.
+//
+// The value is raw * scale + offset, a zero scale counts as 1.
+type ModbusRegister struct {
+	Parameter string  `bson:"parameter" json:"parameter" example:"pH"`
+	Address   uint16  `bson:"address" json:"address" example:"100"`
+	Table     string  `bson:"table,omitempty" json:"table,omitempty" example:"holding"`
+	DataType  string  `bson:"data_type" json:"data_type" example:"float32"`
+	ByteOrder string  `bson:"byte_order,omitempty" json:"byte_order,omitempty" example:"ABCD"`
+	Scale     float64 `bson:"scale,omitempty" json:"scale,omitempty" example:"0.01"`
+	Offset    float64 `bson:"offset,omitempty" json:"offset,omitempty"`
+}
+
+// Count returns the number of registers the value spans, 0 for an unknown data type.
+func (r ModbusRegister) Count() uint16 {
+	switch r.DataType {
+	case ModbusUint16, ModbusInt16:
+		return 1
+	case ModbusUint32, ModbusInt32, ModbusFloat32:
+		return 2
+	case ModbusFloat64:
+		return 4
+	}
+	return 0
+}
+
+// Validate checks the parameter, data type, table and byte order of the register.
+func (r ModbusRegister) Validate() error {
+	if r.Parameter == "" {
+		return fmt.Errorf("%w: parameter is required", ErrInvalidModbus)
+	}
+	if r.Count() == 0 {
+		return fmt.Errorf("%w: %s has an unknown data type %q", ErrInvalidModbus, r.Parameter, r.DataType)
+	}
+	switch r.Table {
+	case "", ModbusHolding, ModbusInput:
+	default:
+		return fmt.Errorf("%w: %s has an unknown table %q", ErrInvalidModbus, r.Parameter, r.Table)
+	}
+	switch r.ByteOrder {
+	case "", ModbusABCD, ModbusCDAB, ModbusBADC, ModbusDCBA:
+	default:
+		return fmt.Errorf("%w: %s has an unknown byte order %q", ErrInvalidModbus, r.Parameter, r.ByteOrder)
+	}
+	if int(r.Address)+int(r.Count()) > math.MaxUint16+1 {
+		return fmt.Errorf("%w: %s ends beyond the last register", ErrInvalidModbus, r.Parameter)
+	}
+	return nil
+}
+
+// Decode converts the registers of the value into the parameter value.
+//
+// registers: the Count registers starting at Address as read from the instrument.
+//
+// Returns the scaled value and an error if the registers do not match the data type.
+func (r ModbusRegister) Decode(registers []uint16) (float64, error) {
+	if len(registers) != int(r.Count()) || r.Count() == 0 {
+		return 0, fmt.Errorf("%w: %s needs %d registers, got %d", ErrInvalidModbus, r.Parameter, r.Count(), len(registers))
+	}
+	data := make([]byte, 2*len(registers))
+	for i, register := range registers {
+		binary.BigEndian.PutUint16(data[2*i:], register)
+	}
+	// Bring the bytes in ABCD order
+	switch r.ByteOrder {
+	case ModbusCDAB:
+		for i, j := 0, len(data)-2; i < j; i, j = i+2, j-2 {
+			data[i], data[i+1], data[j], data[j+1] = data[j], data[j+1], data[i], data[i+1]
+		}
+	case ModbusBADC:
+		for i := 0; i < len(data); i += 2 {
+			data[i], data[i+1] = data[i+1], data[i]
+		}
+	case ModbusDCBA:
+		for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
+			data[i], data[j] = data[j], data[i]
+		}
+	}
+
+	var raw float64
+	switch r.DataType {
+	case ModbusUint16:
+		raw = float64(binary.BigEndian.Uint16(data))
+	case ModbusInt16:
+		raw = float64(int16(binary.BigEndian.Uint16(data)))
+	case ModbusUint32:
+		raw = float64(binary.BigEndian.Uint32(data))
+	case ModbusInt32:
+		raw = float64(int32(binary.BigEndian.Uint32(data)))
+	case ModbusFloat32:
+		raw = float64(math.Float32frombits(binary.BigEndian.Uint32(data)))
+	case ModbusFloat64:
+		raw = math.Float64frombits(binary.BigEndian.Uint64(data))
+	}
+	if math.IsNaN(raw) || math.IsInf(raw, 0) {
+		return 0, fmt.Errorf("%w: %s read %v", ErrInvalidModbus, r.Parameter, raw)
+	}
+	scale := r.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	return raw*scale + r.Offset, nil
+}
+
+// ModbusEndpoint is how a device is polled over Modbus.
+//
+// Address is host:port for TCP and the serial device, e.g. /dev/ttyUSB0, for RTU. Devices sharing
+// an address are polled one after the other, over the line settings of the first one.
+type ModbusEndpoint struct {
+	Transport string `bson:"transport" json:"transport" example:"tcp"`
+	Address   string `bson:"address" json:"address" example:"192.168.1.20:502"`
+	UnitID    byte   `bson:"unit_id" json:"unit_id" example:"1"`
+	// Interval is the time between two polls in seconds, the configured default applies when zero
+	Interval int `bson:"interval,omitempty" json:"interval,omitempty" example:"60"`
+	// Serial is the line of an RTU endpoint
+	Serial ModbusSerial `bson:"serial,omitempty" json:"serial,omitempty"`
+}
+
+// ModbusSerial is the line settings of an RTU endpoint, a zero field takes the Modbus default of
+// 19200 baud, 8 data bits, even parity and 1 stop bit.
+type ModbusSerial struct {
+	BaudRate int `bson:"baud_rate,omitempty" json:"baud_rate,omitempty" example:"9600"`
+	DataBits int `bson:"data_bits,omitempty" json:"data_bits,omitempty" example:"8"`
+	// Parity is N (none), E (even) or O (odd)
+	Parity   string `bson:"parity,omitempty" json:"parity,omitempty" example:"E"`
+	StopBits int    `bson:"stop_bits,omitempty" json:"stop_bits,omitempty" example:"1"`
+}
+
+// Validate checks the transport and the address of the endpoint.
+func (e *ModbusEndpoint) Validate() error {
+	switch e.Transport {
+	case ModbusTCP, ModbusRTU:
+	default:
+		return fmt.Errorf("%w: unknown transport %q", ErrInvalidModbus, e.Transport)
+	}
+	if e.Address == "" {
+		return fmt.Errorf("%w: address is required", ErrInvalidModbus)
+	}
+	if e.Interval < 0 {
+		return fmt.Errorf("%w: interval must not be negative", ErrInvalidModbus)
+	}
+	return e.Serial.Validate()
+}
+
+// Validate checks the line settings.
+func (s *ModbusSerial) Validate() error {
+	if s.BaudRate < 0 {
+		return fmt.Errorf("%w: baud rate must not be negative", ErrInvalidModbus)
+	}
+	switch s.DataBits {
+	case 0, 7, 8:
+	default:
+		return fmt.Errorf("%w: data bits must be 7 or 8", ErrInvalidModbus)
+	}
+	switch s.Parity {
+	case "", "N", "E", "O":
+	default:
+		return fmt.Errorf("%w: unknown parity %q", ErrInvalidModbus, s.Parity)
+	}
+	switch s.StopBits {
+	case 0, 1, 2:
+	default:
+		return fmt.Errorf("%w: stop bits must be 1 or 2", ErrInvalidModbus)
+	}
+	return nil
+}
+
+// PollInterval returns the time between two polls of the endpoint.
+//
+// fallback: the interval used when the endpoint has none.
+func (e *ModbusEndpoint) PollInterval(fallback time.Duration) time.Duration {
+	if e.Interval > 0 {
+		return time.Duration(e.Interval) * time.Second
+	}
+	return fallback
+}
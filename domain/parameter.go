@@ -0,0 +1,381 @@
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Parameter codes of the values carried by WasteWaterData, they match the JSON field names
+const (
+	ParamBOD                = "BOD"
+	ParamCOD                = "COD"
+	ParamTOC                = "TOC"
+	ParamDOC                = "DOC"
+	ParamOpticalBrighteners = "Optical_Brighteners"
+	ParamAmmonium           = "Ammonium"
+	ParamDissolvedOxygen    = "Dissolved_Oxygen"
+	ParamNitrate            = "Nitrate"
+	ParamECSalinityTDS      = "EC_Salinity_TDS"
+	ParamPressure           = "Pressure"
+	ParamORPRedox           = "ORP_REDOX"
+	ParamTurbidity          = "Turbidity"
+	ParamChloride           = "Chloride"
+	ParamColiformsFecal     = "Coliforms.fecal"
+	ParamColiformsEColi     = "Coliforms.E_coli"
+	ParamColiformsTotal     = "Coliforms.total"
+	ParamCrudeOils          = "Crude_Oils"
+	ParamPH                 = "pH"
+	ParamTryptophan         = "Tryptophan"
+	ParamCDOM               = "CDOM"
+	ParamTemperature        = "Temperature"
+	ParamRefinedOils        = "Refined_Oils"
+)
+
+// WasteWaterParameters lists the parameter codes of the WasteWaterData fields in declaration order
+var WasteWaterParameters = []string{
+	ParamBOD,
+	ParamCOD,
+	ParamTOC,
+	ParamDOC,
+	ParamOpticalBrighteners,
+	ParamAmmonium,
+	ParamDissolvedOxygen,
+	ParamNitrate,
+	ParamECSalinityTDS,
+	ParamPressure,
+	ParamORPRedox,
+	ParamTurbidity,
+	ParamChloride,
+	ParamColiformsFecal,
+	ParamColiformsEColi,
+	ParamColiformsTotal,
+	ParamCrudeOils,
+	ParamPH,
+	ParamTryptophan,
+	ParamCDOM,
+	ParamTemperature,
+	ParamRefinedOils,
+}
+
+// Values returns the parameter values the request carries keyed by parameter code.
+func (w *WastewaterDataRequest) Values() map[string]float64 {
+	return w.present.filter(map[string]float64{
+		ParamBOD:                w.BOD,
+		ParamCOD:                w.COD,
+		ParamTOC:                w.TOC,
+		ParamDOC:                w.DOC,
+		ParamOpticalBrighteners: w.OpticalBrighteners,
+		ParamAmmonium:           w.Ammonium,
+		ParamDissolvedOxygen:    w.DissolvedOxygen,
+		ParamNitrate:            w.Nitrate,
+		ParamECSalinityTDS:      w.ECSalinityTDS,
+		ParamPressure:           w.Pressure,
+		ParamORPRedox:           w.ORPRedox,
+		ParamTurbidity:          w.Turbidity,
+		ParamChloride:           w.Chloride,
+		ParamColiformsFecal:     w.Coliforms.Fecal,
+		ParamColiformsEColi:     w.Coliforms.EColi,
+		ParamColiformsTotal:     w.Coliforms.Total,
+		ParamCrudeOils:          w.CrudeOils,
+		ParamPH:                 w.PH,
+		ParamTryptophan:         w.Tryptophan,
+		ParamCDOM:               w.CDOM,
+		ParamTemperature:        w.Temperature,
+		ParamRefinedOils:        w.RefinedOils,
+	})
+}
+
+// Values returns the parameter values the waste water data carries keyed by parameter code.
+func (w *WasteWaterData) Values() map[string]float64 {
+	return w.present.filter(map[string]float64{
+		ParamBOD:                w.BOD,
+		ParamCOD:                w.COD,
+		ParamTOC:                w.TOC,
+		ParamDOC:                w.DOC,
+		ParamOpticalBrighteners: w.OpticalBrighteners,
+		ParamAmmonium:           w.Ammonium,
+		ParamDissolvedOxygen:    w.DissolvedOxygen,
+		ParamNitrate:            w.Nitrate,
+		ParamECSalinityTDS:      w.ECSalinityTDS,
+		ParamPressure:           w.Pressure,
+		ParamORPRedox:           w.ORPRedox,
+		ParamTurbidity:          w.Turbidity,
+		ParamChloride:           w.Chloride,
+		ParamColiformsFecal:     w.Coliforms.Fecal,
+		ParamColiformsEColi:     w.Coliforms.EColi,
+		ParamColiformsTotal:     w.Coliforms.Total,
+		ParamCrudeOils:          w.CrudeOils,
+		ParamPH:                 w.PH,
+		ParamTryptophan:         w.Tryptophan,
+		ParamCDOM:               w.CDOM,
+		ParamTemperature:        w.Temperature,
+		ParamRefinedOils:        w.RefinedOils,
+	})
+}
+
+// SetValue sets the field matching the parameter code, it returns false for unknown parameters.
+//
+// Waste water data filled in with SetValue carries the parameters set only, the other fields
+// are absent rather than zero.
+func (w *WasteWaterData) SetValue(parameter string, value float64) bool {
+	switch parameter {
+	case ParamBOD:
+		w.BOD = value
+	case ParamCOD:
+		w.COD = value
+	case ParamTOC:
+		w.TOC = value
+	case ParamDOC:
+		w.DOC = value
+	case ParamOpticalBrighteners:
+		w.OpticalBrighteners = value
+	case ParamAmmonium:
+		w.Ammonium = value
+	case ParamDissolvedOxygen:
+		w.DissolvedOxygen = value
+	case ParamNitrate:
+		w.Nitrate = value
+	case ParamECSalinityTDS:
+		w.ECSalinityTDS = value
+	case ParamPressure:
+		w.Pressure = value
+	case ParamORPRedox:
+		w.ORPRedox = value
+	case ParamTurbidity:
+		w.Turbidity = value
+	case ParamChloride:
+		w.Chloride = value
+	case ParamColiformsFecal:
+		w.Coliforms.Fecal = value
+	case ParamColiformsEColi:
+		w.Coliforms.EColi = value
+	case ParamColiformsTotal:
+		w.Coliforms.Total = value
+	case ParamCrudeOils:
+		w.CrudeOils = value
+	case ParamPH:
+		w.PH = value
+	case ParamTryptophan:
+		w.Tryptophan = value
+	case ParamCDOM:
+		w.CDOM = value
+	case ParamTemperature:
+		w.Temperature = value
+	case ParamRefinedOils:
+		w.RefinedOils = value
+	default:
+		return false
+	}
+	if w.present == nil {
+		w.present = parameterSet{}
+	}
+	w.present[parameter] = true
+	return true
+}
+
+// SetValue sets the field of the request matching the parameter code, it returns false for unknown parameters.
+//
+// A request filled in with SetValue carries the parameters set only, the other fields are absent
+// rather than zero.
+func (w *WastewaterDataRequest) SetValue(parameter string, value float64) bool {
+	switch parameter {
+	case ParamBOD:
+		w.BOD = value
+	case ParamCOD:
+		w.COD = value
+	case ParamTOC:
+		w.TOC = value
+	case ParamDOC:
+		w.DOC = value
+	case ParamOpticalBrighteners:
+		w.OpticalBrighteners = value
+	case ParamAmmonium:
+		w.Ammonium = value
+	case ParamDissolvedOxygen:
+		w.DissolvedOxygen = value
+	case ParamNitrate:
+		w.Nitrate = value
+	case ParamECSalinityTDS:
+		w.ECSalinityTDS = value
+	case ParamPressure:
+		w.Pressure = value
+	case ParamORPRedox:
+		w.ORPRedox = value
+	case ParamTurbidity:
+		w.Turbidity = value
+	case ParamChloride:
+		w.Chloride = value
+	case ParamColiformsFecal:
+		w.Coliforms.Fecal = value
+	case ParamColiformsEColi:
+		w.Coliforms.EColi = value
+	case ParamColiformsTotal:
+		w.Coliforms.Total = value
+	case ParamCrudeOils:
+		w.CrudeOils = value
+	case ParamPH:
+		w.PH = value
+	case ParamTryptophan:
+		w.Tryptophan = value
+	case ParamCDOM:
+		w.CDOM = value
+	case ParamTemperature:
+		w.Temperature = value
+	case ParamRefinedOils:
+		w.RefinedOils = value
+	default:
+		return false
+	}
+	if w.present == nil {
+		w.present = parameterSet{}
+	}
+	w.present[parameter] = true
+	return true
+}
+
+// parameterSet is the set of the parameters a reading carries. It is nil for a reading filled in
+// field by field, e.g. a legacy document, which carries every parameter.
+type parameterSet map[string]bool
+
+// filter removes the values of the parameters the reading does not carry.
+func (p parameterSet) filter(values map[string]float64) map[string]float64 {
+	if p == nil {
+		return values
+	}
+	for parameter := range values {
+		if !p[parameter] {
+			delete(values, parameter)
+		}
+	}
+	return values
+}
+
+// UnmarshalJSON decodes a request and records the parameters it carries, a missing or null field is absent.
+func (w *WastewaterDataRequest) UnmarshalJSON(data []byte) error {
+	type request WastewaterDataRequest
+	decoded := request(*w)
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	present, err := decodeParameters(data)
+	if err != nil {
+		return err
+	}
+	*w = WastewaterDataRequest(decoded)
+	w.present = present
+	return nil
+}
+
+// MarshalJSON encodes a request without the parameters it does not carry.
+func (w WastewaterDataRequest) MarshalJSON() ([]byte, error) {
+	type request WastewaterDataRequest
+	data, err := json.Marshal(request(w))
+	if err != nil {
+		return nil, err
+	}
+	return encodeParameters(data, w.present)
+}
+
+// UnmarshalJSON decodes waste water data and records the parameters it carries, a missing or null field is absent.
+func (w *WasteWaterData) UnmarshalJSON(data []byte) error {
+	type wasteWaterData WasteWaterData
+	decoded := wasteWaterData(*w)
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	present, err := decodeParameters(data)
+	if err != nil {
+		return err
+	}
+	*w = WasteWaterData(decoded)
+	w.present = present
+	return nil
+}
+
+// MarshalJSON encodes waste water data without the parameters it does not carry.
+func (w WasteWaterData) MarshalJSON() ([]byte, error) {
+	type wasteWaterData WasteWaterData
+	data, err := json.Marshal(wasteWaterData(w))
+	if err != nil {
+		return nil, err
+	}
+	return encodeParameters(data, w.present)
+}
+
+// decodeParameters lists the parameters present in a JSON reading. The coliforms are nested,
+// e.g. Coliforms.fecal is the fecal field of the Coliforms object.
+func decodeParameters(data []byte) (parameterSet, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	present := parameterSet{}
+	for _, parameter := range WasteWaterParameters {
+		path := strings.SplitN(parameter, ".", 2)
+		raw, ok := field(fields, path[0])
+		if ok && len(path) == 2 {
+			var nested map[string]json.RawMessage
+			if err := json.Unmarshal(raw, &nested); err != nil {
+				return nil, err
+			}
+			raw, ok = field(nested, path[1])
+		}
+		if ok && string(raw) != "null" {
+			present[parameter] = true
+		}
+	}
+	return present, nil
+}
+
+// field looks a JSON field up by name, case insensitively like encoding/json does.
+func field(fields map[string]json.RawMessage, name string) (json.RawMessage, bool) {
+	if raw, ok := fields[name]; ok {
+		return raw, true
+	}
+	for key, raw := range fields {
+		if strings.EqualFold(key, name) {
+			return raw, true
+		}
+	}
+	return nil, false
+}
+
+// encodeParameters removes the parameters a reading does not carry from its JSON.
+func encodeParameters(data []byte, present parameterSet) ([]byte, error) {
+	if present == nil {
+		return data, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for _, parameter := range WasteWaterParameters {
+		if present[parameter] {
+			continue
+		}
+		path := strings.SplitN(parameter, ".", 2)
+		raw, ok := fields[path[0]]
+		if !ok {
+			continue
+		}
+		if len(path) == 1 {
+			delete(fields, parameter)
+			continue
+		}
+		var nested map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &nested); err != nil {
+			return nil, err
+		}
+		delete(nested, path[1])
+		if len(nested) == 0 {
+			delete(fields, path[0])
+			continue
+		}
+		kept, err := json.Marshal(nested)
+		if err != nil {
+			return nil, err
+		}
+		fields[path[0]] = kept
+	}
+	return json.Marshal(fields)
+}
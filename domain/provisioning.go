@@ -0,0 +1,176 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Provisioning claim statuses, a claim pre-registered by an admin is approved, a claim created by an
+// unknown device waits for approval and a claim is claimed once its device registered
+const (
+	ClaimPending  = "pending"
+	ClaimApproved = "approved"
+	ClaimRejected = "rejected"
+	ClaimClaimed  = "claimed"
+)
+
+// ErrInvalidProvisioning is returned when a claim, a registration or a template is missing required fields
+var ErrInvalidProvisioning = errors.New("invalid provisioning request")
+
+// ErrClaimRefused is returned when a device presents a wrong claim token or its serial number was rejected
+var ErrClaimRefused = errors.New("claim refused")
+
+// ErrClaimTransition is returned when a claim is decided twice or a claim token is used again
+var ErrClaimTransition = errors.New("claim transition not allowed")
+
+// ErrDeviceMismatch is returned when an authenticated device sends data on behalf of another device
+var ErrDeviceMismatch = errors.New("data of another device")
+
+// ProvisioningClaim ties a factory serial number and its one-time claim token to the device it provisions
+type ProvisioningClaim struct {
+	ID               primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	SerialNumber     string             `bson:"serial_number" json:"serial_number" example:"MRT-WQ-000123"`
+	TokenHash        string             `bson:"token_hash" json:"-"`
+	Status           string             `bson:"status" json:"status" example:"pending"`
+	Name             string             `bson:"name,omitempty" json:"name,omitempty"`
+	Model            string             `bson:"model,omitempty" json:"model,omitempty" example:"WQ-5"`
+	SiteID           primitive.ObjectID `bson:"site_id,omitempty" json:"site_id,omitempty"`
+	Firmware         string             `bson:"firmware,omitempty" json:"firmware,omitempty"`
+	HardwareRevision string             `bson:"hardware_revision,omitempty" json:"hardware_revision,omitempty"`
+	DeviceID         primitive.ObjectID `bson:"device_id,omitempty" json:"device_id,omitempty"`
+	DecidedBy        string             `bson:"decided_by,omitempty" json:"decided_by,omitempty"`
+	DecidedAt        time.Time          `bson:"decided_at,omitempty" json:"decided_at"`
+	ClaimedAt        time.Time          `bson:"claimed_at,omitempty" json:"claimed_at"`
+	CreatedAt        MyTime             `bson:"created_at" json:"created_at" swaggertype:"string" time_format:"2006-01-02 15:04:05"`
+}
+
+// Matches reports whether a claim token is the one of the claim.
+func (c *ProvisioningClaim) Matches(token string) bool {
+	return subtle.ConstantTimeCompare([]byte(c.TokenHash), []byte(HashSecret(token))) == 1
+}
+
+// ClaimRequest pre-registers a serial number and its claim token, e.g. from the factory shipping list
+type ClaimRequest struct {
+	SerialNumber string             `json:"serial_number" example:"MRT-WQ-000123"`
+	ClaimToken   string             `json:"claim_token"`
+	Name         string             `json:"name"`
+	Model        string             `json:"model" example:"WQ-5"`
+	SiteID       primitive.ObjectID `json:"site_id,omitempty"`
+	DecidedBy    string             `json:"decided_by"`
+}
+
+// Validate checks the request has a serial number and a claim token.
+func (r *ClaimRequest) Validate() error {
+	if r.SerialNumber == "" || r.ClaimToken == "" {
+		return fmt.Errorf("%w: serial_number and claim_token are required", ErrInvalidProvisioning)
+	}
+	return nil
+}
+
+// ClaimDecision approves or rejects a pending claim, the name, model and site override what the device reported
+type ClaimDecision struct {
+	Name      string             `json:"name"`
+	Model     string             `json:"model" example:"WQ-5"`
+	SiteID    primitive.ObjectID `json:"site_id,omitempty"`
+	DecidedBy string             `json:"decided_by"`
+}
+
+// RegistrationRequest is what a device presents to register itself
+type RegistrationRequest struct {
+	SerialNumber     string `json:"serial_number" example:"MRT-WQ-000123"`
+	ClaimToken       string `json:"claim_token"`
+	Model            string `json:"model" example:"WQ-5"`
+	Firmware         string `json:"firmware" example:"1.4.2"`
+	HardwareRevision string `json:"hardware_revision" example:"C"`
+}
+
+// Validate checks the request has a serial number and a claim token.
+func (r *RegistrationRequest) Validate() error {
+	if r.SerialNumber == "" || r.ClaimToken == "" {
+		return fmt.Errorf("%w: serial_number and claim_token are required", ErrInvalidProvisioning)
+	}
+	return nil
+}
+
+// Registration is the answer to a device registering itself.
+//
+// A pending registration only carries its status, the device registers again once approved.
+type Registration struct {
+	Status      string             `json:"status" example:"claimed"`
+	Device      *Device            `json:"device,omitempty"`
+	Sensors     []Sensor           `json:"sensors,omitempty"`
+	Credentials *DeviceCredentials `json:"credentials,omitempty"`
+	Config      *DeviceConfig      `json:"config,omitempty"`
+}
+
+// DeviceCredentials are the MQTT credentials of a device, the password is only given once
+type DeviceCredentials struct {
+	ClientID string `json:"client_id"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// PSKIdentity and PSK are the DTLS identity and hex encoded pre-shared key of CoAP
+	PSKIdentity string `json:"psk_identity"`
+	PSK         string `json:"psk"`
+}
+
+// DeviceCredential is the stored form of the credentials of a device
+type DeviceCredential struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	DeviceID     primitive.ObjectID `bson:"device_id" json:"device_id"`
+	Username     string             `bson:"username" json:"username"`
+	PasswordHash string             `bson:"password_hash" json:"-"`
+	CreatedAt    MyTime             `bson:"created_at" json:"created_at" swaggertype:"string" time_format:"2006-01-02 15:04:05"`
+}
+
+// Matches reports whether a password is the one of the credentials.
+func (c *DeviceCredential) Matches(password string) bool {
+	return subtle.ConstantTimeCompare([]byte(c.PasswordHash), []byte(HashSecret(password))) == 1
+}
+
+// DeviceConfig is the configuration a device receives when it registers
+type DeviceConfig struct {
+	Broker      string      `json:"broker" example:"tcp://mqtt.example.com:1883"`
+	TopicPrefix string      `json:"topic_prefix" example:"mrt/devices/6650f1c2a1b2c3d4e5f60718"`
+	Desired     ShadowState `json:"desired,omitempty" swaggertype:"object"`
+}
+
+// DeviceTemplate describes the sensors and the initial configuration of the devices of a model
+type DeviceTemplate struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Model     string             `bson:"model" json:"model" example:"WQ-5"`
+	Sensors   []TemplateSensor   `bson:"sensors" json:"sensors"`
+	Config    ShadowState        `bson:"config,omitempty" json:"config,omitempty" swaggertype:"object"`
+	UpdatedAt MyTime             `bson:"updated_at" json:"updated_at" swaggertype:"string" time_format:"2006-01-02 15:04:05"`
+}
+
+// TemplateSensor is a sensor created on every device of a template
+type TemplateSensor struct {
+	Name        string             `bson:"name" json:"name" example:"pH probe"`
+	Description string             `bson:"description,omitempty" json:"description,omitempty"`
+	TypeID      primitive.ObjectID `bson:"sensor_type_id,omitempty" json:"sensor_type_id,omitempty"`
+}
+
+// Validate checks the template has a model and named sensors.
+func (t *DeviceTemplate) Validate() error {
+	if t.Model == "" {
+		return fmt.Errorf("%w: model is required", ErrInvalidProvisioning)
+	}
+	for i, sensor := range t.Sensors {
+		if sensor.Name == "" {
+			return fmt.Errorf("%w: sensor %d has no name", ErrInvalidProvisioning, i)
+		}
+	}
+	return nil
+}
+
+// HashSecret returns the hex encoded SHA-256 of a claim token or a password.
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// DevicePSK is the pre-shared key a device authenticates with over CoAP/DTLS.
+//
+// The identity is the hex ID of the device, the key is kept since the server needs it for
+// every handshake but it is only returned when it is issued.
+type DevicePSK struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	DeviceID  primitive.ObjectID `bson:"device_id" json:"device_id"`
+	Identity  string             `bson:"identity" json:"identity"`
+	Key       []byte             `bson:"key" json:"-"`
+	CreatedAt MyTime             `bson:"created_at" json:"created_at" swaggertype:"string" time_format:"2006-01-02 15:04:05"`
+}
+
+// PSKSecret is a newly issued pre-shared key, given once
+type PSKSecret struct {
+	Identity string `json:"identity" example:"6650f1c2a1b2c3d4e5f60718"`
+	// Key is hex encoded
+	Key       string `json:"key" example:"000102030405060708090a0b0c0d0e0f"`
+	CreatedAt MyTime `json:"created_at" swaggertype:"string" time_format:"2006-01-02 15:04:05"`
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Quality flags of a measurement, raw and suspect are set on ingestion, the others by reviewers
+const (
+	QualityRaw       = "raw"
+	QualitySuspect   = "suspect"
+	QualityInvalid   = "invalid"
+	QualityValidated = "validated"
+	QualityApproved  = "approved"
+)
+
+// Automated quality checks run on ingestion, a failed check marks the measurement suspect
+const (
+	CheckRange        = "range"
+	CheckSpike        = "spike"
+	CheckFlatline     = "flatline"
+	CheckRateOfChange = "rate_of_change"
+)
+
+// ErrInvalidQuality is returned when a review has an unknown quality flag, no reason or no measurement
+var ErrInvalidQuality = errors.New("invalid quality review")
+
+// ErrQualityTransition is returned when a reviewer sets a quality flag the measurement cannot move to
+var ErrQualityTransition = errors.New("quality transition not allowed")
+
+// ErrReviewedMeasurement is returned when a reading update would change or remove a measurement a reviewer flagged
+var ErrReviewedMeasurement = errors.New("measurement already reviewed")
+
+// IsQuality reports whether the flag is a known quality flag.
+func IsQuality(quality string) bool {
+	switch quality {
+	case QualityRaw, QualitySuspect, QualityInvalid, QualityValidated, QualityApproved:
+		return true
+	}
+	return false
+}
+
+// Reviewed reports whether the quality flag was set by a reviewer rather than on ingestion.
+func Reviewed(quality string) bool {
+	return quality != "" && quality != QualityRaw && quality != QualitySuspect
+}
+
+// CanTransition reports whether a reviewer may move a measurement from one quality flag to another.
+//
+// Only validated measurements can be approved, and raw is reserved for ingestion.
+func CanTransition(from, to string) bool {
+	switch to {
+	case QualityRaw:
+		return false
+	case QualityApproved:
+		return from == QualityValidated || from == QualityApproved
+	}
+	return IsQuality(to)
+}
+
+// QualityReview is the audit record of a reviewer changing the quality flag of a measurement
+type QualityReview struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	MeasurementID primitive.ObjectID `bson:"measurement_id" json:"measurement_id"`
+	ReadingID     primitive.ObjectID `bson:"reading_id" json:"reading_id"`
+	Parameter     string             `bson:"parameter" json:"parameter" example:"pH"`
+	From          string             `bson:"from" json:"from" example:"suspect"`
+	To            string             `bson:"to" json:"to" example:"invalid"`
+	Reason        string             `bson:"reason" json:"reason" example:"probe out of the water during cleaning"`
+	Reviewer      string             `bson:"reviewer" json:"reviewer"`
+	CreatedAt     MyTime             `bson:"created_at" json:"created_at" swaggertype:"string" time_format:"2006-01-02 15:04:05"`
+}
+
+type QualityReviewRequest struct {
+	MeasurementIDs []primitive.ObjectID `json:"measurement_ids"`
+	Quality        string               `json:"quality" example:"validated"`
+	Reason         string               `json:"reason"`
+	Reviewer       string               `json:"reviewer"`
+}
+
+// Validate checks that the review names measurements, a known quality flag and a reason.
+func (r *QualityReviewRequest) Validate() error {
+	if len(r.MeasurementIDs) == 0 {
+		return fmt.Errorf("%w: measurement_ids is required", ErrInvalidQuality)
+	}
+	if !IsQuality(r.Quality) {
+		return fmt.Errorf("%w: unknown quality %q", ErrInvalidQuality, r.Quality)
+	}
+	if r.Reason == "" {
+		return fmt.Errorf("%w: reason is required", ErrInvalidQuality)
+	}
+	return nil
+}
@@ -9,14 +9,18 @@ type Sensor struct {
 	Name        string             `bson:"name" json:"name"`
 	Description string             `bson:"description" json:"description"`
 	DeviceID    primitive.ObjectID `bson:"device_id" json:"device_id"`
-	CreatedAt   MyTime             `bson:"created_at" json:"created_at" time_format:"2006-01-02 15:04:05" time_utc:"true"`
-	UpdatedAt   MyTime             `bson:"updated_at" json:"updated_at" time_format:"2006-01-02 15:04:05" time_utc:"true"`
+	TypeID      primitive.ObjectID `bson:"sensor_type_id,omitempty" json:"sensor_type_id,omitempty"`
+	CreatedAt   MyTime             `bson:"created_at" json:"created_at" swaggertype:"string" time_format:"2006-01-02 15:04:05" time_utc:"true"`
+	UpdatedAt   MyTime             `bson:"updated_at" json:"updated_at" swaggertype:"string" time_format:"2006-01-02 15:04:05" time_utc:"true"`
+	Health      *SensorHealth      `bson:"-" json:"health,omitempty"`
 }
 
 type SensorRequest struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"-"`
 	Name        string             `bson:"name" json:"name"`
 	Description string             `bson:"description" json:"description"`
 	DeviceID    primitive.ObjectID `bson:"device_id" json:"device_id"`
-	CreatedAt   MyTime             `bson:"created_at" json:"created_at" time_format:"2006-01-02 15:04:05" time_utc:"true"`
-	UpdatedAt   MyTime             `bson:"updated_at" json:"updated_at" time_format:"2006-01-02 15:04:05" time_utc:"true"`
+	TypeID      primitive.ObjectID `bson:"sensor_type_id,omitempty" json:"sensor_type_id,omitempty"`
+	CreatedAt   MyTime             `bson:"created_at" json:"created_at" swaggertype:"string" time_format:"2006-01-02 15:04:05" time_utc:"true"`
+	UpdatedAt   MyTime             `bson:"updated_at" json:"updated_at" swaggertype:"string" time_format:"2006-01-02 15:04:05" time_utc:"true"`
 }
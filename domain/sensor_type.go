@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Range handling modes applied at ingestion when a value falls outside its sensor range
+const (
+	RangeModeReject = "reject"
+	RangeModeFlag   = "flag"
+)
+
+// ErrOutOfRange is returned when a reading carries values outside the sensor range
+var ErrOutOfRange = errors.New("value outside sensor range")
+
+// ErrInvalidSensorType is returned when a sensor type has no code or an inconsistent parameter spec
+var ErrInvalidSensorType = errors.New("invalid sensor type")
+
+// ParameterSpec describes how a sensor model measures a single parameter
+type ParameterSpec struct {
+	Parameter  string  `bson:"parameter" json:"parameter" example:"pH"`
+	Unit       string  `bson:"unit" json:"unit" example:"pH"`
+	Resolution float64 `bson:"resolution" json:"resolution" example:"0.01"`
+	Min        float64 `bson:"min" json:"min" example:"0"`
+	Max        float64 `bson:"max" json:"max" example:"14"`
+	Accuracy   float64 `bson:"accuracy" json:"accuracy" example:"0.1"`
+}
+
+// InRange reports whether the value lies within the valid physical range of the parameter.
+func (p ParameterSpec) InRange(value float64) bool {
+	return value >= p.Min && value <= p.Max
+}
+
+// SensorType represents a sensor model in the catalog
+type SensorType struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Code         string             `bson:"code" json:"code" example:"ph-probe"`
+	Name         string             `bson:"name" json:"name"`
+	Manufacturer string             `bson:"manufacturer" json:"manufacturer"`
+	Model        string             `bson:"model" json:"model"`
+	Description  string             `bson:"description" json:"description"`
+	Parameters   []ParameterSpec    `bson:"parameters" json:"parameters"`
+	Registers    []ModbusRegister   `bson:"registers,omitempty" json:"registers,omitempty"`
+	CreatedAt    MyTime             `bson:"created_at" json:"created_at" swaggertype:"string" time_format:"2006-01-02 15:04:05"`
+	UpdatedAt    MyTime             `bson:"updated_at" json:"updated_at" swaggertype:"string" time_format:"2006-01-02 15:04:05"`
+}
+
+// Validate checks the sensor type code and parameter specs.
+func (t *SensorType) Validate() error {
+	return validateSensorType(t.Code, t.Parameters, t.Registers)
+}
+
+type SensorTypeRequest struct {
+	Code         string           `bson:"code" json:"code" example:"ph-probe"`
+	Name         string           `bson:"name" json:"name"`
+	Manufacturer string           `bson:"manufacturer" json:"manufacturer"`
+	Model        string           `bson:"model" json:"model"`
+	Description  string           `bson:"description" json:"description"`
+	Parameters   []ParameterSpec  `bson:"parameters" json:"parameters"`
+	Registers    []ModbusRegister `bson:"registers,omitempty" json:"registers,omitempty"`
+	CreatedAt    MyTime           `bson:"created_at" json:"created_at" swaggertype:"string" time_format:"2006-01-02 15:04:05"`
+	UpdatedAt    MyTime           `bson:"updated_at" json:"updated_at" swaggertype:"string" time_format:"2006-01-02 15:04:05"`
+}
+
+// Validate checks the sensor type code and parameter specs.
+func (t *SensorTypeRequest) Validate() error {
+	return validateSensorType(t.Code, t.Parameters, t.Registers)
+}
+
+// validateSensorType requires a code, for every parameter a code and a min not above its max, and valid Modbus registers.
+func validateSensorType(code string, parameters []ParameterSpec, registers []ModbusRegister) error {
+	if code == "" {
+		return fmt.Errorf("%w: code is required", ErrInvalidSensorType)
+	}
+	for _, p := range parameters {
+		if p.Parameter == "" {
+			return fmt.Errorf("%w: parameter is required", ErrInvalidSensorType)
+		}
+		if p.Min > p.Max {
+			return fmt.Errorf("%w: %s min is greater than max", ErrInvalidSensorType, p.Parameter)
+		}
+	}
+	for _, r := range registers {
+		if err := r.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RangeViolation describes a value found outside its sensor range
+type RangeViolation struct {
+	Parameter string  `json:"parameter"`
+	Value     float64 `json:"value"`
+	Min       float64 `json:"min"`
+	Max       float64 `json:"max"`
+}
+
+// String formats the violation for error messages.
+func (v RangeViolation) String() string {
+	return fmt.Sprintf("%s=%g outside [%g, %g]", v.Parameter, v.Value, v.Min, v.Max)
+}
@@ -0,0 +1,173 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrInvalidShadow is returned when a shadow update carries no state
+var ErrInvalidShadow = errors.New("invalid shadow update")
+
+// ErrShadowVersion is returned when a shadow update was made against an outdated version of the shadow
+var ErrShadowVersion = errors.New("shadow version conflict")
+
+// ShadowState is a configuration document of a device, e.g. its sampling interval and alarm thresholds
+type ShadowState map[string]interface{}
+
+// Shadow is the digital twin of a device.
+//
+// Desired holds the configuration operators want the device to run, Reported the configuration
+// the device last reported running. Version increases with every update of either document.
+type Shadow struct {
+	ID                primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	DeviceID          primitive.ObjectID `bson:"device_id" json:"device_id"`
+	Desired           ShadowState        `bson:"desired" json:"desired"`
+	Reported          ShadowState        `bson:"reported" json:"reported"`
+	Delta             ShadowState        `bson:"-" json:"delta,omitempty"`
+	Version           int64              `bson:"version" json:"version"`
+	DesiredUpdatedAt  time.Time          `bson:"desired_updated_at,omitempty" json:"desired_updated_at"`
+	ReportedUpdatedAt time.Time          `bson:"reported_updated_at,omitempty" json:"reported_updated_at"`
+}
+
+// ShadowUpdate is a partial update of a shadow state document.
+//
+// Keys of State are merged into the document, a null value removes the key. A non zero
+// Version must match the current version of the shadow for the update to apply.
+type ShadowUpdate struct {
+	State   ShadowState `json:"state" swaggertype:"object"`
+	Version int64       `json:"version"`
+}
+
+// Validate checks that the update carries a state.
+func (u *ShadowUpdate) Validate() error {
+	if len(u.State) == 0 {
+		return fmt.Errorf("%w: state is required", ErrInvalidShadow)
+	}
+	return nil
+}
+
+// ShadowDelta is the message delivered to a device when its desired and reported states differ
+type ShadowDelta struct {
+	DeviceID  primitive.ObjectID `json:"device_id"`
+	State     ShadowState        `json:"state"`
+	Version   int64              `json:"version"`
+	Timestamp time.Time          `json:"timestamp"`
+}
+
+// Merge applies a partial update to the state and returns the merged state.
+//
+// Nested documents are merged recursively and null values remove their key.
+func (s ShadowState) Merge(patch ShadowState) ShadowState {
+	merged := ShadowState{}
+	for key, value := range s {
+		merged[key] = value
+	}
+	for key, value := range patch {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		nested, ok := stateDocument(value)
+		if current, isDocument := stateDocument(merged[key]); ok && isDocument {
+			merged[key] = current.Merge(nested)
+			continue
+		}
+		if ok {
+			merged[key] = ShadowState{}.Merge(nested)
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
+}
+
+// Delta returns the desired keys whose value differs from the reported one, nested documents
+// are compared key by key.
+func Delta(desired, reported ShadowState) ShadowState {
+	delta := ShadowState{}
+	for key, want := range desired {
+		have, ok := reported[key]
+		wantDocument, isDocument := stateDocument(want)
+		haveDocument, wasDocument := stateDocument(have)
+		if ok && isDocument && wasDocument {
+			if nested := Delta(wantDocument, haveDocument); len(nested) > 0 {
+				delta[key] = nested
+			}
+			continue
+		}
+		if !ok || !stateEqual(want, have) {
+			delta[key] = want
+		}
+	}
+	return delta
+}
+
+// stateDocument returns the value as a state document when it is one.
+func stateDocument(value interface{}) (ShadowState, bool) {
+	switch v := value.(type) {
+	case ShadowState:
+		return v, true
+	case map[string]interface{}:
+		return ShadowState(v), true
+	case primitive.M:
+		return ShadowState(v), true
+	}
+	return nil, false
+}
+
+// stateEqual compares two state values, numbers are compared by value whatever their type
+// since JSON decodes them as float64 and BSON as int32, int64 or float64.
+func stateEqual(a, b interface{}) bool {
+	if x, ok := stateNumber(a); ok {
+		y, ok := stateNumber(b)
+		return ok && x == y
+	}
+	if x, ok := stateList(a); ok {
+		y, ok := stateList(b)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !stateEqual(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	if x, ok := stateDocument(a); ok {
+		y, ok := stateDocument(b)
+		return ok && len(x) == len(y) && len(Delta(x, y)) == 0
+	}
+	return a == b
+}
+
+// stateNumber returns the value as a float64 when it is a number.
+func stateNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// stateList returns the value as a list when it is one.
+func stateList(value interface{}) ([]interface{}, bool) {
+	switch v := value.(type) {
+	case []interface{}:
+		return v, true
+	case primitive.A:
+		return v, true
+	}
+	return nil, false
+}
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Site represents a monitoring station or plant where devices are installed
+type Site struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name      string             `bson:"name" json:"name"`
+	Address   string             `bson:"address" json:"address"`
+	Operator  string             `bson:"operator" json:"operator"`
+	Geometry  *Geometry          `bson:"geometry,omitempty" json:"geometry,omitempty"`
+	CreatedAt MyTime             `bson:"created_at" json:"created_at" swaggertype:"string" time_format:"2006-01-02 15:04:05"`
+	UpdatedAt MyTime             `bson:"updated_at" json:"updated_at" swaggertype:"string" time_format:"2006-01-02 15:04:05"`
+}
+
+type SiteRequest struct {
+	Name      string    `bson:"name" json:"name"`
+	Address   string    `bson:"address" json:"address"`
+	Operator  string    `bson:"operator" json:"operator"`
+	Geometry  *Geometry `bson:"geometry,omitempty" json:"geometry,omitempty"`
+	CreatedAt MyTime    `bson:"created_at" json:"created_at" swaggertype:"string" time_format:"2006-01-02 15:04:05"`
+	UpdatedAt MyTime    `bson:"updated_at" json:"updated_at" swaggertype:"string" time_format:"2006-01-02 15:04:05"`
+}
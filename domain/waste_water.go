@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"errors"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -9,6 +10,7 @@ import (
 // WasteWaterData represents waste water data
 type WasteWaterData struct {
 	ID                 primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty" `
+	DeviceID           primitive.ObjectID `json:"device_id,omitempty" bson:"device_id,omitempty"`
 	Timestamp          time.Time          `json:"timestamp" bson:"timestamp"`
 	BOD                float64            `json:"BOD" bson:"BOD"`
 	COD                float64            `json:"COD" bson:"COD"`
@@ -30,30 +32,45 @@ type WasteWaterData struct {
 	CDOM               float64            `json:"CDOM"`
 	Temperature        float64            `json:"Temperature"`
 	RefinedOils        float64            `json:"Refined_Oils"`
+	Parameters         map[string]float64 `json:"parameters,omitempty" bson:"parameters,omitempty"`
+	Units              map[string]string  `json:"units,omitempty" bson:"-"`
+	OutOfRange         []string           `json:"out_of_range,omitempty" bson:"out_of_range,omitempty"`
+	Quality            map[string]string  `json:"quality,omitempty" bson:"-"`
+	Anomalies          []string           `json:"anomalies,omitempty" bson:"-"`
+	Maintenance        bool               `json:"maintenance,omitempty" bson:"-"`
+	present            parameterSet
 }
 
 type WastewaterDataRequest struct {
-	Timestamp          time.Time     `json:"timestamp" bson:"timestamp"`
-	BOD                float64       `json:"BOD" bson:"BOD"`
-	COD                float64       `json:"COD" bson:"COD"`
-	TOC                float64       `json:"TOC" bson:"TOC"`
-	DOC                float64       `json:"DOC" bson:"DOC"`
-	OpticalBrighteners float64       `json:"Optical_Brighteners" bson:"Optical_Brighteners"`
-	Ammonium           float64       `json:"Ammonium"`
-	DissolvedOxygen    float64       `json:"Dissolved_Oxygen"`
-	Nitrate            float64       `json:"Nitrate"`
-	ECSalinityTDS      float64       `json:"EC_Salinity_TDS"`
-	Pressure           float64       `json:"Pressure"`
-	ORPRedox           float64       `json:"ORP_REDOX"`
-	Turbidity          float64       `json:"Turbidity"`
-	Chloride           float64       `json:"Chloride"`
-	Coliforms          ColiformsData `json:"Coliforms" bson:"Coliforms"`
-	CrudeOils          float64       `json:"Crude_Oils"`
-	PH                 float64       `json:"pH"`
-	Tryptophan         float64       `json:"Tryptophan"`
-	CDOM               float64       `json:"CDOM"`
-	Temperature        float64       `json:"Temperature"`
-	RefinedOils        float64       `json:"Refined_Oils"`
+	ID                 primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	DeviceID           primitive.ObjectID `json:"device_id,omitempty" bson:"device_id,omitempty"`
+	Timestamp          time.Time          `json:"timestamp" bson:"timestamp"`
+	BOD                float64            `json:"BOD" bson:"BOD"`
+	COD                float64            `json:"COD" bson:"COD"`
+	TOC                float64            `json:"TOC" bson:"TOC"`
+	DOC                float64            `json:"DOC" bson:"DOC"`
+	OpticalBrighteners float64            `json:"Optical_Brighteners" bson:"Optical_Brighteners"`
+	Ammonium           float64            `json:"Ammonium"`
+	DissolvedOxygen    float64            `json:"Dissolved_Oxygen"`
+	Nitrate            float64            `json:"Nitrate"`
+	ECSalinityTDS      float64            `json:"EC_Salinity_TDS"`
+	Pressure           float64            `json:"Pressure"`
+	ORPRedox           float64            `json:"ORP_REDOX"`
+	Turbidity          float64            `json:"Turbidity"`
+	Chloride           float64            `json:"Chloride"`
+	Coliforms          ColiformsData      `json:"Coliforms" bson:"Coliforms"`
+	CrudeOils          float64            `json:"Crude_Oils"`
+	PH                 float64            `json:"pH"`
+	Tryptophan         float64            `json:"Tryptophan"`
+	CDOM               float64            `json:"CDOM"`
+	Temperature        float64            `json:"Temperature"`
+	RefinedOils        float64            `json:"Refined_Oils"`
+	Parameters         map[string]float64 `json:"parameters,omitempty" bson:"parameters,omitempty"`
+	Units              map[string]string  `json:"units,omitempty" bson:"-"`
+	OutOfRange         []string           `json:"out_of_range,omitempty" bson:"out_of_range,omitempty"`
+	// DedupKey identifies a reading sent again, e.g. by an edge agent retrying, so that it is stored once
+	DedupKey string `json:"dedup_key,omitempty" bson:"-"`
+	present  parameterSet
 }
 
 // ColiformsData represents coliform data
@@ -62,3 +79,35 @@ type ColiformsData struct {
 	EColi float64 `json:"E_coli" bson:"E_coli"`
 	Total float64 `json:"total" bson:"total"`
 }
+
+// ErrDuplicateReading is returned when a reading with the same dedup key was already stored
+var ErrDuplicateReading = errors.New("duplicate reading")
+
+// Batch item statuses, a rejected reading is invalid and must not be sent again while a failed one can be retried
+const (
+	BatchCreated   = "created"
+	BatchDuplicate = "duplicate"
+	BatchRejected  = "rejected"
+	BatchFailed    = "failed"
+)
+
+// WasteWaterBatch is a batch of readings forwarded at once, e.g. by an edge agent catching up
+type WasteWaterBatch struct {
+	Readings []WastewaterDataRequest `json:"readings"`
+}
+
+// WasteWaterBatchResult is the outcome of each reading of a batch in order.
+//
+// ServerTime is the time the batch arrived, it lets the sender measure the offset of its clock.
+type WasteWaterBatchResult struct {
+	ServerTime time.Time         `json:"server_time"`
+	Items      []BatchItemResult `json:"items"`
+}
+
+// BatchItemResult is the outcome of a reading of a batch
+type BatchItemResult struct {
+	DedupKey string             `json:"dedup_key,omitempty"`
+	ID       primitive.ObjectID `json:"id,omitempty"`
+	Status   string             `json:"status" example:"created"`
+	Error    string             `json:"error,omitempty"`
+}
@@ -0,0 +1,161 @@
+package ingest
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+)
+
+// precisions are the durations of a timestamp unit of the precision= query parameter
+var precisions = map[string]time.Duration{
+	"":   time.Nanosecond,
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+}
+
+// errNotNumeric is returned for string and boolean field values
+var errNotNumeric = errors.New("value is not numeric")
+
+// parseLineProtocol parses InfluxDB line protocol, one record per numeric field.
+//
+// A line that cannot be parsed is reported as an error with its line number, counted from 1,
+// points without a timestamp are taken at now.
+func parseLineProtocol(data []byte, precision time.Duration, deviceTag string, now time.Time) ([]record, []domain.IngestRecordError) {
+	var (
+		records []record
+		errs    []domain.IngestRecordError
+	)
+	for i, line := range strings.Split(string(data), "\n") {
+		number := i + 1
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		sections := splitUnescaped(line, ' ', true)
+		if len(sections) < 2 || len(sections) > 3 {
+			errs = append(errs, domain.IngestRecordError{Record: number, Error: "a line is a measurement with tags, fields and an optional timestamp"})
+			continue
+		}
+
+		keys := splitUnescaped(sections[0], ',', false)
+		measurement := unescape(keys[0])
+		if measurement == "" {
+			errs = append(errs, domain.IngestRecordError{Record: number, Error: "measurement is required"})
+			continue
+		}
+		device := ""
+		malformed := false
+		for _, tag := range keys[1:] {
+			kv := splitUnescaped(tag, '=', false)
+			if len(kv) != 2 {
+				malformed = true
+				break
+			}
+			if unescape(kv[0]) == deviceTag {
+				device = unescape(kv[1])
+			}
+		}
+		if malformed {
+			errs = append(errs, domain.IngestRecordError{Record: number, Name: measurement, Error: "tags are key=value pairs"})
+			continue
+		}
+
+		at := now
+		if len(sections) == 3 {
+			ts, err := strconv.ParseInt(sections[2], 10, 64)
+			if err != nil {
+				errs = append(errs, domain.IngestRecordError{Record: number, Name: measurement, Error: fmt.Sprintf("invalid timestamp %q", sections[2])})
+				continue
+			}
+			// The time is counted in nanoseconds, a timestamp beyond their range would wrap around
+			if ts > math.MaxInt64/int64(precision) || ts < math.MinInt64/int64(precision) {
+				errs = append(errs, domain.IngestRecordError{Record: number, Name: measurement, Error: fmt.Sprintf("timestamp %d out of range", ts)})
+				continue
+			}
+			at = time.Unix(0, ts*int64(precision)).UTC()
+		}
+		if device == "" {
+			errs = append(errs, domain.IngestRecordError{Record: number, Name: measurement, Error: fmt.Sprintf("no %s tag", deviceTag)})
+			continue
+		}
+
+		for _, field := range splitUnescaped(sections[1], ',', true) {
+			kv := splitUnescaped(field, '=', true)
+			if len(kv) != 2 || kv[0] == "" {
+				errs = append(errs, domain.IngestRecordError{Record: number, Name: measurement, Error: fmt.Sprintf("invalid field %q", field)})
+				continue
+			}
+			key := unescape(kv[0])
+			name := measurement + "." + key
+			value, err := parseFieldValue(kv[1])
+			if err != nil {
+				errs = append(errs, domain.IngestRecordError{Record: number, Name: name, Error: err.Error()})
+				continue
+			}
+			records = append(records, record{index: number, name: name, device: device, parameters: []string{name, key}, value: value, time: at})
+		}
+	}
+	return records, errs
+}
+
+// parseFieldValue parses a float, integer or unsigned field value
+func parseFieldValue(s string) (float64, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		return 0, errNotNumeric
+	case strings.HasSuffix(s, "i"):
+		n, err := strconv.ParseInt(strings.TrimSuffix(s, "i"), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid integer %q", s)
+		}
+		return float64(n), nil
+	case strings.HasSuffix(s, "u"):
+		n, err := strconv.ParseUint(strings.TrimSuffix(s, "u"), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid unsigned integer %q", s)
+		}
+		return float64(n), nil
+	}
+	switch s {
+	case "t", "T", "true", "True", "TRUE", "f", "F", "false", "False", "FALSE":
+		return 0, errNotNumeric
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("invalid float %q", s)
+	}
+	return v, nil
+}
+
+// splitUnescaped splits s at the separators not escaped with a backslash, nor quoted when quotes is set
+func splitUnescaped(s string, sep byte, quotes bool) []string {
+	var (
+		parts  []string
+		start  int
+		quoted bool
+	)
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\':
+			i++
+		case c == '"' && quotes:
+			quoted = !quoted
+		case c == sep && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// unescape removes the backslashes escaping commas, equal signs and spaces of a name
+func unescape(s string) string {
+	return strings.NewReplacer(`\,`, ",", `\=`, "=", `\ `, " ").Replace(s)
+}
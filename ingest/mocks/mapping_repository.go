@@ -0,0 +1,126 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MappingRepositoryInterface is an autogenerated mock type for the MappingRepositoryInterface type
+type MappingRepositoryInterface struct {
+	mock.Mock
+}
+
+// Delete provides a mock function with given fields: ctx, source
+func (_m *MappingRepositoryInterface) Delete(ctx context.Context, source string) error {
+	ret := _m.Called(ctx, source)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, source)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAll provides a mock function with given fields: ctx
+func (_m *MappingRepositoryInterface) GetAll(ctx context.Context) ([]domain.IngestMapping, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAll")
+	}
+
+	var r0 []domain.IngestMapping
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]domain.IngestMapping, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []domain.IngestMapping); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.IngestMapping)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBySource provides a mock function with given fields: ctx, source
+func (_m *MappingRepositoryInterface) GetBySource(ctx context.Context, source string) (*domain.IngestMapping, error) {
+	ret := _m.Called(ctx, source)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBySource")
+	}
+
+	var r0 *domain.IngestMapping
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.IngestMapping, error)); ok {
+		return rf(ctx, source)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.IngestMapping); ok {
+		r0 = rf(ctx, source)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.IngestMapping)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, source)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Save provides a mock function with given fields: ctx, m
+func (_m *MappingRepositoryInterface) Save(ctx context.Context, m *domain.IngestMapping) error {
+	ret := _m.Called(ctx, m)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.IngestMapping) error); ok {
+		r0 = rf(ctx, m)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewMappingRepositoryInterface creates a new instance of MappingRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMappingRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MappingRepositoryInterface {
+	mock := &MappingRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
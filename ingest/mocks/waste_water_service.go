@@ -0,0 +1,48 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// WasteWaterServiceInterface is an autogenerated mock type for the WasteWaterServiceInterface type
+type WasteWaterServiceInterface struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, w
+func (_m *WasteWaterServiceInterface) Create(ctx context.Context, w *domain.WastewaterDataRequest) error {
+	ret := _m.Called(ctx, w)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.WastewaterDataRequest) error); ok {
+		r0 = rf(ctx, w)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewWasteWaterServiceInterface creates a new instance of WasteWaterServiceInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewWasteWaterServiceInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *WasteWaterServiceInterface {
+	mock := &WasteWaterServiceInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,219 @@
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/cbor"
+)
+
+// relativeTimeLimit is the time below which a SenML time is relative to now, 2**28 seconds
+const relativeTimeLimit = 1 << 28
+
+// SenML CBOR labels of the record fields (RFC 8428 section 6)
+const (
+	labelBaseVersion = -1
+	labelBaseName    = -2
+	labelBaseTime    = -3
+	labelBaseUnit    = -4
+	labelBaseValue   = -5
+	labelName        = 0
+	labelUnit        = 1
+	labelValue       = 2
+	labelStringValue = 3
+	labelBoolValue   = 4
+	labelSum         = 5
+	labelTime        = 6
+	labelUpdateTime  = 7
+	labelDataValue   = 8
+)
+
+// record is a numeric value of a gateway payload with the names it is mapped by
+type record struct {
+	// index is the position of the record in the pack, the line number for line protocol
+	index int
+	name  string
+	// device is the device name, empty when it is a prefix of the name as in SenML
+	device string
+	// parameters are the candidate parameter names, most specific first
+	parameters []string
+	value      float64
+	unit       string
+	time       time.Time
+}
+
+// parseSenMLJSON decodes a SenML pack in JSON.
+func parseSenMLJSON(data []byte) ([]domain.SenMLRecord, error) {
+	var pack []domain.SenMLRecord
+	if err := json.Unmarshal(data, &pack); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidIngest, err)
+	}
+	return pack, nil
+}
+
+// parseSenMLCBOR decodes a SenML pack in CBOR, unknown labels are ignored.
+func parseSenMLCBOR(data []byte) ([]domain.SenMLRecord, error) {
+	item, err := cbor.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidIngest, err)
+	}
+	items, ok := item.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: a senml pack is an array", domain.ErrInvalidIngest)
+	}
+	pack := make([]domain.SenMLRecord, 0, len(items))
+	for i, item := range items {
+		fields, ok := item.(map[any]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: record %d is not a map", domain.ErrInvalidIngest, i)
+		}
+		r := domain.SenMLRecord{}
+		for label, v := range fields {
+			l, ok := label.(int64)
+			if !ok {
+				continue
+			}
+			if err := setCBORField(&r, l, v); err != nil {
+				return nil, fmt.Errorf("%w: record %d: %v", domain.ErrInvalidIngest, i, err)
+			}
+		}
+		pack = append(pack, r)
+	}
+	return pack, nil
+}
+
+// setCBORField sets the field of a record with a CBOR label
+func setCBORField(r *domain.SenMLRecord, label int64, v any) error {
+	var ok bool
+	switch label {
+	case labelBaseName:
+		var s string
+		s, ok = v.(string)
+		r.BaseName = &s
+	case labelBaseUnit:
+		var s string
+		s, ok = v.(string)
+		r.BaseUnit = &s
+	case labelName:
+		r.Name, ok = v.(string)
+	case labelUnit:
+		r.Unit, ok = v.(string)
+	case labelStringValue:
+		var s string
+		s, ok = v.(string)
+		r.StringValue = &s
+	case labelDataValue:
+		var s string
+		s, ok = v.(string)
+		r.DataValue = &s
+	case labelBoolValue:
+		var b bool
+		b, ok = v.(bool)
+		r.BoolValue = &b
+	case labelBaseVersion:
+		var n float64
+		n, ok = cborNumber(v)
+		version := int(n)
+		r.BaseVersion = &version
+	case labelBaseTime, labelBaseValue, labelValue, labelSum:
+		var n float64
+		n, ok = cborNumber(v)
+		switch label {
+		case labelBaseTime:
+			r.BaseTime = &n
+		case labelBaseValue:
+			r.BaseValue = &n
+		case labelValue:
+			r.Value = &n
+		case labelSum:
+			r.Sum = &n
+		}
+	case labelTime:
+		r.Time, ok = cborNumber(v)
+	case labelUpdateTime:
+		r.UpdateTime, ok = cborNumber(v)
+	default:
+		return nil
+	}
+	if !ok {
+		return fmt.Errorf("label %d has a value of type %T", label, v)
+	}
+	return nil
+}
+
+// cborNumber converts a decoded CBOR integer or float
+func cborNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// resolveSenML applies the base fields of a pack to its records (RFC 8428 section 4.6).
+//
+// Records without a numeric value are reported as errors, times below 2**28 are relative to now.
+func resolveSenML(pack []domain.SenMLRecord, now time.Time) ([]record, []domain.IngestRecordError) {
+	var (
+		records   []record
+		errs      []domain.IngestRecordError
+		baseName  string
+		baseUnit  string
+		baseTime  float64
+		baseValue float64
+	)
+	for i, r := range pack {
+		if r.BaseName != nil {
+			baseName = *r.BaseName
+		}
+		if r.BaseUnit != nil {
+			baseUnit = *r.BaseUnit
+		}
+		if r.BaseTime != nil {
+			baseTime = *r.BaseTime
+		}
+		if r.BaseValue != nil {
+			baseValue = *r.BaseValue
+		}
+		name := baseName + r.Name
+		if name == "" {
+			errs = append(errs, domain.IngestRecordError{Record: i, Error: "record has no name"})
+			continue
+		}
+		if r.Value == nil {
+			reason := "record has no value"
+			if r.StringValue != nil || r.BoolValue != nil || r.DataValue != nil {
+				reason = "value is not numeric"
+			}
+			errs = append(errs, domain.IngestRecordError{Record: i, Name: name, Error: reason})
+			continue
+		}
+		value := baseValue + *r.Value
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			errs = append(errs, domain.IngestRecordError{Record: i, Name: name, Error: "value is not a finite number"})
+			continue
+		}
+		unit := r.Unit
+		if unit == "" {
+			unit = baseUnit
+		}
+		records = append(records, record{index: i, name: name, value: value, unit: unit, time: senMLTime(baseTime+r.Time, now)})
+	}
+	return records, errs
+}
+
+// senMLTime converts a resolved SenML time in seconds
+func senMLTime(t float64, now time.Time) time.Time {
+	if t < relativeTimeLimit {
+		return now.Add(time.Duration(t * float64(time.Second)))
+	}
+	sec, frac := math.Modf(t)
+	return time.Unix(int64(sec), int64(frac*1e9)).UTC()
+}
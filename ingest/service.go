@@ -0,0 +1,334 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+)
+
+// tracer starts the spans of the service methods
+var tracer = otel.Tracer("github.com/anggi-susanto/mrt-go/ingest")
+
+// MappingRepositoryInterface is the interface that wraps the ingest mapping storage methods.
+type MappingRepositoryInterface interface {
+	Save(ctx context.Context, m *domain.IngestMapping) error
+	GetAll(ctx context.Context) ([]domain.IngestMapping, error)
+	GetBySource(ctx context.Context, source string) (*domain.IngestMapping, error)
+	Delete(ctx context.Context, source string) error
+}
+
+// SensorRepositoryInterface is the interface that wraps the GetByID method.
+type SensorRepositoryInterface interface {
+	GetByID(ctx context.Context, id string) (*domain.Sensor, error)
+}
+
+// WasteWaterServiceInterface is the interface that wraps the Create method of the waste water ingestion.
+type WasteWaterServiceInterface interface {
+	Create(ctx context.Context, w *domain.WastewaterDataRequest) error
+}
+
+// Service is the interface that wraps the ingestion of third-party gateway formats.
+type Service struct {
+	mappingRepository MappingRepositoryInterface
+	sensorRepository  SensorRepositoryInterface
+	wasteWaterService WasteWaterServiceInterface
+}
+
+// NewService creates a new instance of the Service struct, initializing it with the provided repositories.
+//
+// Parameters:
+// - mappingRepository: The MappingRepositoryInterface implementation holding the mapping of each gateway.
+// - sensorRepository: The SensorRepositoryInterface implementation resolving the devices of mapped sensors.
+// - wasteWaterService: The WasteWaterServiceInterface implementation the readings are pushed through.
+//
+// Returns:
+// - A pointer to the newly created Service instance.
+func NewService(mappingRepository MappingRepositoryInterface, sensorRepository SensorRepositoryInterface, wasteWaterService WasteWaterServiceInterface) *Service {
+	return &Service{
+		mappingRepository: mappingRepository,
+		sensorRepository:  sensorRepository,
+		wasteWaterService: wasteWaterService,
+	}
+}
+
+// IngestSenML stores the records of a SenML pack sent by a gateway.
+//
+// ctx - context.Context for the operation.
+// source - the name of the gateway, the key of its mapping.
+// payload - the pack in JSON or CBOR.
+// contentType - domain.SenMLJSON or domain.SenMLCBOR.
+// Returns the outcome of each reading and record, nil when the source has no mapping, and an error
+// wrapping domain.ErrInvalidIngest when the pack cannot be decoded.
+func (s *Service) IngestSenML(ctx context.Context, source string, payload []byte, contentType string) (*domain.IngestResult, error) {
+	ctx, span := tracer.Start(ctx, "ingest.Service.IngestSenML")
+	defer span.End()
+	mapping, err := s.mappingRepository.GetBySource(ctx, source)
+	if err != nil || mapping == nil {
+		return nil, err
+	}
+	var pack []domain.SenMLRecord
+	switch contentType {
+	case domain.SenMLJSON:
+		pack, err = parseSenMLJSON(payload)
+	case domain.SenMLCBOR:
+		pack, err = parseSenMLCBOR(payload)
+	default:
+		err = fmt.Errorf("%w: unsupported content type %q", domain.ErrInvalidIngest, contentType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	records, errs := resolveSenML(pack, time.Now())
+	for i := range records {
+		records[i].device, records[i].parameters = splitName(mapping, records[i].name)
+	}
+	return s.ingest(ctx, "senml", mapping, records, errs)
+}
+
+// IngestLineProtocol stores the points of InfluxDB line protocol sent by a gateway.
+//
+// ctx - context.Context for the operation.
+// source - the name of the gateway, the key of its mapping.
+// payload - the lines.
+// precision - the unit of the timestamps, ns, us, ms or s, ns when empty.
+// Returns the outcome of each reading and record, nil when the source has no mapping, and an error
+// wrapping domain.ErrInvalidIngest when the precision is unknown.
+func (s *Service) IngestLineProtocol(ctx context.Context, source string, payload []byte, precision string) (*domain.IngestResult, error) {
+	ctx, span := tracer.Start(ctx, "ingest.Service.IngestLineProtocol")
+	defer span.End()
+	unit, ok := precisions[precision]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown precision %q", domain.ErrInvalidIngest, precision)
+	}
+	mapping, err := s.mappingRepository.GetBySource(ctx, source)
+	if err != nil || mapping == nil {
+		return nil, err
+	}
+	deviceTag := mapping.DeviceTag
+	if deviceTag == "" {
+		deviceTag = domain.DefaultDeviceTag
+	}
+	records, errs := parseLineProtocol(payload, unit, deviceTag, time.Now())
+	return s.ingest(ctx, "influx", mapping, records, errs)
+}
+
+// splitName splits a SenML name at the longest device name it starts with
+func splitName(mapping *domain.IngestMapping, name string) (string, []string) {
+	device := ""
+	for _, d := range mapping.Devices {
+		if strings.HasPrefix(name, d.Name) && len(d.Name) > len(device) {
+			device = d.Name
+		}
+	}
+	if device == "" {
+		return "", nil
+	}
+	return device, []string{strings.TrimPrefix(name, device)}
+}
+
+// reading groups the records of a device at a time
+type reading struct {
+	request    *domain.WastewaterDataRequest
+	records    []int
+	parameters []string
+}
+
+// ingest maps the records onto devices and parameters and stores one reading per device and time
+func (s *Service) ingest(ctx context.Context, format string, mapping *domain.IngestMapping, records []record, errs []domain.IngestRecordError) (*domain.IngestResult, error) {
+	devices := make(map[string]domain.DeviceMapping, len(mapping.Devices))
+	for _, d := range mapping.Devices {
+		devices[d.Name] = d
+	}
+	parameters := make(map[string]domain.ParameterMapping, len(mapping.Parameters))
+	for _, p := range mapping.Parameters {
+		parameters[p.Name] = p
+	}
+	sensorDevices := map[primitive.ObjectID]primitive.ObjectID{}
+
+	var readings []*reading
+	byKey := map[string]*reading{}
+	for _, r := range records {
+		d, ok := devices[r.device]
+		if !ok {
+			errs = append(errs, domain.IngestRecordError{Record: r.index, Name: r.name, Error: "no device mapped to " + r.name})
+			continue
+		}
+		deviceID := d.DeviceID
+		if deviceID.IsZero() {
+			id, err := s.sensorDevice(ctx, d.SensorID, sensorDevices)
+			if err != nil {
+				return nil, err
+			}
+			if id.IsZero() {
+				errs = append(errs, domain.IngestRecordError{Record: r.index, Name: r.name, Error: fmt.Sprintf("sensor %s of %s not found", d.SensorID.Hex(), d.Name)})
+				continue
+			}
+			deviceID = id
+		}
+		parameter, unit, ok := mapParameter(parameters, r.parameters)
+		if !ok {
+			errs = append(errs, domain.IngestRecordError{Record: r.index, Name: r.name, Error: "no parameter mapped to " + r.parameters[len(r.parameters)-1]})
+			continue
+		}
+		if r.unit != "" {
+			unit = r.unit
+		}
+
+		key := deviceID.Hex() + "@" + r.time.Format(time.RFC3339Nano)
+		g, ok := byKey[key]
+		if !ok {
+			g = &reading{request: &domain.WastewaterDataRequest{DeviceID: deviceID, Timestamp: r.time}}
+			byKey[key] = g
+			readings = append(readings, g)
+		}
+		if g.has(parameter) {
+			errs = append(errs, domain.IngestRecordError{Record: r.index, Name: r.name, Error: fmt.Sprintf("%s already has a value at %s", parameter, r.time.Format(time.RFC3339Nano))})
+			continue
+		}
+		if !g.request.SetValue(parameter, r.value) {
+			if g.request.Parameters == nil {
+				g.request.Parameters = map[string]float64{}
+			}
+			g.request.Parameters[parameter] = r.value
+		}
+		if unit != "" {
+			if g.request.Units == nil {
+				g.request.Units = map[string]string{}
+			}
+			g.request.Units[parameter] = unit
+		}
+		g.records = append(g.records, r.index)
+		g.parameters = append(g.parameters, parameter)
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Record < errs[j].Record })
+	result := &domain.IngestResult{Readings: make([]domain.IngestReadingResult, 0, len(readings)), Errors: errs}
+	for _, g := range readings {
+		w := g.request
+		w.DedupKey = g.dedupKey(format, mapping.Source)
+		item := domain.IngestReadingResult{
+			BatchItemResult: domain.BatchItemResult{DedupKey: w.DedupKey, Status: domain.BatchCreated},
+			DeviceID:        w.DeviceID,
+			Timestamp:       w.Timestamp,
+			Records:         g.records,
+		}
+		if err := s.wasteWaterService.Create(ctx, w); err != nil {
+			switch {
+			case errors.Is(err, domain.ErrDuplicateReading):
+				item.Status = domain.BatchDuplicate
+			case errors.Is(err, domain.ErrOutOfRange), errors.Is(err, domain.ErrInvalidMeasurement), errors.Is(err, domain.ErrUnknownUnit):
+				item.Status, item.Error = domain.BatchRejected, err.Error()
+			default:
+				item.Status, item.Error = domain.BatchFailed, err.Error()
+			}
+		}
+		item.ID = w.ID
+		result.Readings = append(result.Readings, item)
+	}
+	return result, nil
+}
+
+// has reports whether the reading already holds the parameter
+func (g *reading) has(parameter string) bool {
+	for _, p := range g.parameters {
+		if p == parameter {
+			return true
+		}
+	}
+	return false
+}
+
+// sensorDevice returns the device of a sensor, zero when the sensor does not exist
+func (s *Service) sensorDevice(ctx context.Context, sensorID primitive.ObjectID, cache map[primitive.ObjectID]primitive.ObjectID) (primitive.ObjectID, error) {
+	if id, ok := cache[sensorID]; ok {
+		return id, nil
+	}
+	sensor, err := s.sensorRepository.GetByID(ctx, sensorID.Hex())
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	if sensor != nil {
+		cache[sensorID] = sensor.DeviceID
+	} else {
+		cache[sensorID] = primitive.NilObjectID
+	}
+	return cache[sensorID], nil
+}
+
+// mapParameter returns the parameter and the unit of the first mapped name, a name that is a
+// waste water parameter code is taken as is
+func mapParameter(parameters map[string]domain.ParameterMapping, names []string) (string, string, bool) {
+	for _, name := range names {
+		if p, ok := parameters[name]; ok {
+			return p.Parameter, p.Unit, true
+		}
+	}
+	for _, name := range names {
+		for _, code := range domain.WasteWaterParameters {
+			if name == code {
+				return code, "", true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// dedupKey identifies the reading of a gateway so that a payload sent again is stored once
+func (g *reading) dedupKey(format, source string) string {
+	parameters := append([]string(nil), g.parameters...)
+	sort.Strings(parameters)
+	return fmt.Sprintf("%s-%s-%s-%d-%s", format, source, g.request.DeviceID.Hex(), g.request.Timestamp.UnixNano(), strings.Join(parameters, ","))
+}
+
+// SaveMapping creates or replaces the mapping of a gateway.
+//
+// ctx - context.Context for the operation.
+// mapping - the mapping, its source is the key.
+// Returns the stored mapping and an error wrapping domain.ErrInvalidIngest when the mapping is invalid.
+func (s *Service) SaveMapping(ctx context.Context, mapping domain.IngestMapping) (*domain.IngestMapping, error) {
+	ctx, span := tracer.Start(ctx, "ingest.Service.SaveMapping")
+	defer span.End()
+	if err := mapping.Validate(); err != nil {
+		return nil, err
+	}
+	mapping.UpdatedAt = domain.MyTime{Time: time.Now()}
+	if err := s.mappingRepository.Save(ctx, &mapping); err != nil {
+		return nil, err
+	}
+	return &mapping, nil
+}
+
+// GetMappings retrieves the mapping of every gateway.
+//
+// ctx - context.Context for the operation.
+// Returns the mappings and an error.
+func (s *Service) GetMappings(ctx context.Context) ([]domain.IngestMapping, error) {
+	ctx, span := tracer.Start(ctx, "ingest.Service.GetMappings")
+	defer span.End()
+	mappings, err := s.mappingRepository.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if mappings == nil {
+		mappings = []domain.IngestMapping{}
+	}
+	return mappings, nil
+}
+
+// DeleteMapping removes the mapping of a gateway.
+//
+// ctx - context.Context for the operation.
+// source - the name of the gateway.
+// Returns an error.
+func (s *Service) DeleteMapping(ctx context.Context, source string) error {
+	ctx, span := tracer.Start(ctx, "ingest.Service.DeleteMapping")
+	defer span.End()
+	return s.mappingRepository.Delete(ctx, source)
+}
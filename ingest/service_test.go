@@ -0,0 +1,199 @@
+package ingest_test
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/ingest"
+	"github.com/anggi-susanto/mrt-go/ingest/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	outfallID = primitive.NewObjectID()
+	inletID   = primitive.NewObjectID()
+	probeID   = primitive.NewObjectID()
+	at        = time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+)
+
+// gateway returns the mapping of a gateway sending outfall values by name and inlet values through a probe
+func gateway() *mocks.MappingRepositoryInterface {
+	mockMappingRepo := new(mocks.MappingRepositoryInterface)
+	mockMappingRepo.On("GetBySource", mock.Anything, "depot-gw-1").Return(&domain.IngestMapping{
+		Source: "depot-gw-1",
+		Devices: []domain.DeviceMapping{
+			{Name: "urn:dev:outfall:", DeviceID: outfallID},
+			{Name: "inlet", SensorID: probeID},
+		},
+		Parameters: []domain.ParameterMapping{
+			{Name: "temp", Parameter: domain.ParamTemperature},
+			{Name: "ph", Parameter: domain.ParamPH},
+			{Name: "water.cod", Parameter: domain.ParamCOD, Unit: "mg/L"},
+			{Name: "tss", Parameter: "TSS"},
+		},
+	}, nil)
+	mockMappingRepo.On("GetBySource", mock.Anything, mock.Anything).Return(nil, nil)
+	return mockMappingRepo
+}
+
+func probe() *mocks.SensorRepositoryInterface {
+	mockSensorRepo := new(mocks.SensorRepositoryInterface)
+	mockSensorRepo.On("GetByID", mock.Anything, probeID.Hex()).Return(&domain.Sensor{ID: probeID, DeviceID: inletID}, nil)
+	return mockSensorRepo
+}
+
+func TestServiceIngestSenML(t *testing.T) {
+	t.Run("JSON", func(t *testing.T) {
+		mockWasteWater := new(mocks.WasteWaterServiceInterface)
+		mockWasteWater.On("Create", mock.Anything, mock.MatchedBy(func(w *domain.WastewaterDataRequest) bool {
+			return w.DeviceID == outfallID && w.Timestamp.Equal(at) && w.Temperature == 23.5 && w.PH == 7.1 &&
+				w.Units[domain.ParamTemperature] == "Cel" && w.DedupKey != ""
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.WastewaterDataRequest).ID = primitive.NewObjectID()
+		}).Return(nil).Once()
+		mockWasteWater.On("Create", mock.Anything, mock.MatchedBy(func(w *domain.WastewaterDataRequest) bool {
+			return w.DeviceID == outfallID && w.Timestamp.Equal(at.Add(time.Minute)) && w.PH == 7.3
+		})).Return(domain.ErrOutOfRange).Once()
+
+		pack := `[
+			{"bn": "urn:dev:outfall:", "bt": 1714550400, "n": "temp", "u": "Cel", "v": 23.5},
+			{"n": "ph", "v": 7.1},
+			{"n": "ph", "t": 60, "v": 7.3},
+			{"n": "door", "vb": true},
+			{"n": "flow", "v": 12},
+			{"bn": "urn:dev:pump:", "n": "temp", "v": 20}
+		]`
+		service := ingest.NewService(gateway(), probe(), mockWasteWater)
+		result, err := service.IngestSenML(context.Background(), "depot-gw-1", []byte(pack), domain.SenMLJSON)
+		assert.NoError(t, err)
+		assert.Len(t, result.Readings, 2)
+		assert.Equal(t, domain.BatchCreated, result.Readings[0].Status)
+		assert.Equal(t, []int{0, 1}, result.Readings[0].Records)
+		assert.Equal(t, domain.BatchRejected, result.Readings[1].Status)
+		assert.Equal(t, []domain.IngestRecordError{
+			{Record: 3, Name: "urn:dev:outfall:door", Error: "value is not numeric"},
+			{Record: 4, Name: "urn:dev:outfall:flow", Error: "no parameter mapped to flow"},
+			{Record: 5, Name: "urn:dev:pump:temp", Error: "no device mapped to urn:dev:pump:temp"},
+		}, result.Errors)
+		mockWasteWater.AssertExpectations(t)
+	})
+
+	t.Run("CBOR", func(t *testing.T) {
+		mockWasteWater := new(mocks.WasteWaterServiceInterface)
+		mockWasteWater.On("Create", mock.Anything, mock.MatchedBy(func(w *domain.WastewaterDataRequest) bool {
+			return w.DeviceID == outfallID && w.Timestamp.Equal(at) && w.Temperature == 23.5
+		})).Return(nil)
+
+		// [{bn: "urn:dev:outfall:", n: "temp", u: "Cel", v: 23.5, bt: 1714550400}]
+		pack, _ := hex.DecodeString("81a5217075726e3a6465763a6f757466616c6c3a006474656d70016343656c02f94de0221a6631f680")
+		service := ingest.NewService(gateway(), probe(), mockWasteWater)
+		result, err := service.IngestSenML(context.Background(), "depot-gw-1", pack, domain.SenMLCBOR)
+		assert.NoError(t, err)
+		assert.Len(t, result.Readings, 1)
+		assert.Empty(t, result.Errors)
+		mockWasteWater.AssertExpectations(t)
+	})
+
+	t.Run("Malformed pack", func(t *testing.T) {
+		service := ingest.NewService(gateway(), probe(), new(mocks.WasteWaterServiceInterface))
+		_, err := service.IngestSenML(context.Background(), "depot-gw-1", []byte(`{"n": "temp"}`), domain.SenMLJSON)
+		assert.ErrorIs(t, err, domain.ErrInvalidIngest)
+	})
+
+	t.Run("Unknown source", func(t *testing.T) {
+		service := ingest.NewService(gateway(), probe(), new(mocks.WasteWaterServiceInterface))
+		result, err := service.IngestSenML(context.Background(), "depot-gw-9", []byte(`[]`), domain.SenMLJSON)
+		assert.NoError(t, err)
+		assert.Nil(t, result)
+	})
+}
+
+func TestServiceIngestLineProtocol(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockWasteWater := new(mocks.WasteWaterServiceInterface)
+		mockWasteWater.On("Create", mock.Anything, mock.MatchedBy(func(w *domain.WastewaterDataRequest) bool {
+			return w.DeviceID == inletID && w.Timestamp.Equal(at) && w.PH == 6.9 && w.COD == 85 &&
+				w.Parameters["TSS"] == 40 && w.Units[domain.ParamCOD] == "mg/L"
+		})).Return(domain.ErrDuplicateReading)
+
+		lines := "# depot inlet\n" +
+			"water,device=inlet,site=depot\\ 1 ph=6.9,cod=85i,tss=40u,state=\"ok\" 1714550400\n" +
+			"water,site=depot ph=7 1714550400\n" +
+			"water,device=inlet ph=\n"
+		service := ingest.NewService(gateway(), probe(), mockWasteWater)
+		result, err := service.IngestLineProtocol(context.Background(), "depot-gw-1", []byte(lines), "s")
+		assert.NoError(t, err)
+		assert.Len(t, result.Readings, 1)
+		assert.Equal(t, domain.BatchDuplicate, result.Readings[0].Status)
+		assert.Equal(t, []int{2, 2, 2}, result.Readings[0].Records)
+		assert.Equal(t, []domain.IngestRecordError{
+			{Record: 2, Name: "water.state", Error: "value is not numeric"},
+			{Record: 3, Name: "water", Error: "no device tag"},
+			{Record: 4, Name: "water.ph", Error: `invalid float ""`},
+		}, result.Errors)
+		mockWasteWater.AssertExpectations(t)
+	})
+
+	t.Run("Reading not stored", func(t *testing.T) {
+		mockWasteWater := new(mocks.WasteWaterServiceInterface)
+		mockWasteWater.On("Create", mock.Anything, mock.Anything).Return(errors.New("database error"))
+
+		service := ingest.NewService(gateway(), probe(), mockWasteWater)
+		result, err := service.IngestLineProtocol(context.Background(), "depot-gw-1", []byte("water,device=inlet ph=6.9 1714550400000"), "ms")
+		assert.NoError(t, err)
+		assert.Equal(t, domain.BatchFailed, result.Readings[0].Status)
+		assert.Equal(t, at, result.Readings[0].Timestamp)
+	})
+
+	t.Run("Timestamp out of range", func(t *testing.T) {
+		service := ingest.NewService(gateway(), probe(), new(mocks.WasteWaterServiceInterface))
+		result, err := service.IngestLineProtocol(context.Background(), "depot-gw-1", []byte("water,device=inlet ph=6.9 9300000000000\nwater,device=inlet ph=7 -9300000000"), "s")
+		assert.NoError(t, err)
+		assert.Empty(t, result.Readings)
+		assert.Equal(t, []domain.IngestRecordError{
+			{Record: 1, Name: "water", Error: "timestamp 9300000000000 out of range"},
+			{Record: 2, Name: "water", Error: "timestamp -9300000000 out of range"},
+		}, result.Errors)
+	})
+
+	t.Run("Unknown precision", func(t *testing.T) {
+		service := ingest.NewService(gateway(), probe(), new(mocks.WasteWaterServiceInterface))
+		_, err := service.IngestLineProtocol(context.Background(), "depot-gw-1", []byte("water,device=inlet ph=6.9"), "h")
+		assert.ErrorIs(t, err, domain.ErrInvalidIngest)
+	})
+}
+
+func TestServiceSaveMapping(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockMappingRepo := new(mocks.MappingRepositoryInterface)
+		mockMappingRepo.On("Save", mock.Anything, mock.MatchedBy(func(m *domain.IngestMapping) bool {
+			return m.Source == "depot-gw-1" && !m.UpdatedAt.IsZero()
+		})).Return(nil)
+
+		service := ingest.NewService(mockMappingRepo, new(mocks.SensorRepositoryInterface), new(mocks.WasteWaterServiceInterface))
+		_, err := service.SaveMapping(context.Background(), domain.IngestMapping{
+			Source:  "depot-gw-1",
+			Devices: []domain.DeviceMapping{{Name: "inlet", DeviceID: inletID}},
+		})
+		assert.NoError(t, err)
+		mockMappingRepo.AssertExpectations(t)
+	})
+
+	t.Run("Device and sensor", func(t *testing.T) {
+		mockMappingRepo := new(mocks.MappingRepositoryInterface)
+
+		service := ingest.NewService(mockMappingRepo, new(mocks.SensorRepositoryInterface), new(mocks.WasteWaterServiceInterface))
+		_, err := service.SaveMapping(context.Background(), domain.IngestMapping{
+			Source:  "depot-gw-1",
+			Devices: []domain.DeviceMapping{{Name: "inlet", DeviceID: inletID, SensorID: probeID}},
+		})
+		assert.ErrorIs(t, err, domain.ErrInvalidIngest)
+		mockMappingRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,346 @@
+// Package cbor decodes and encodes the subset of CBOR (RFC 8949) used by constrained devices.
+//
+// Integers decode to int64 or uint64 beyond its range, floats of every width to float64,
+// byte strings to []byte, text strings to string, arrays to []any and maps to map[any]any.
+// Tags are skipped and their content is returned.
+package cbor
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ErrMalformed is returned when the data is not well-formed CBOR
+var ErrMalformed = errors.New("malformed cbor")
+
+// maxDepth bounds the nesting of arrays and maps
+const maxDepth = 32
+
+// Major types of the initial byte
+const (
+	majorUnsigned = 0
+	majorNegative = 1
+	majorBytes    = 2
+	majorText     = 3
+	majorArray    = 4
+	majorMap      = 5
+	majorTag      = 6
+	majorSimple   = 7
+)
+
+// indefinite is the additional information of an indefinite length item
+const indefinite = 31
+
+// breakCode ends an indefinite length item
+const breakCode = 0xFF
+
+// Decode decodes a single CBOR data item.
+//
+// Parameters:
+// - data: the encoded item, with nothing after it.
+// Returns the decoded item and an error wrapping ErrMalformed.
+func Decode(data []byte) (any, error) {
+	d := &decoder{data: data}
+	v, err := d.item(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.data) {
+		return nil, fmt.Errorf("%w: %d bytes after the item", ErrMalformed, len(d.data)-d.pos)
+	}
+	return v, nil
+}
+
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+// next returns the next n bytes
+func (d *decoder) next(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, fmt.Errorf("%w: unexpected end of data", ErrMalformed)
+	}
+	b := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b, nil
+}
+
+// head reads the initial byte and the argument of an item
+func (d *decoder) head() (major byte, info byte, arg uint64, err error) {
+	b, err := d.next(1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	major, info = b[0]>>5, b[0]&0x1F
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info == 24:
+		b, err = d.next(1)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		return major, info, uint64(b[0]), nil
+	case info == 25:
+		b, err = d.next(2)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		return major, info, uint64(binary.BigEndian.Uint16(b)), nil
+	case info == 26:
+		b, err = d.next(4)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		return major, info, uint64(binary.BigEndian.Uint32(b)), nil
+	case info == 27:
+		b, err = d.next(8)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		return major, info, binary.BigEndian.Uint64(b), nil
+	case info == indefinite:
+		return major, info, 0, nil
+	}
+	return 0, 0, 0, fmt.Errorf("%w: reserved additional information %d", ErrMalformed, info)
+}
+
+// atBreak consumes the break code ending an indefinite length item
+func (d *decoder) atBreak() bool {
+	if d.pos < len(d.data) && d.data[d.pos] == breakCode {
+		d.pos++
+		return true
+	}
+	return false
+}
+
+func (d *decoder) item(depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("%w: nested deeper than %d", ErrMalformed, maxDepth)
+	}
+	major, info, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	if info == indefinite && (major == majorUnsigned || major == majorNegative || major == majorTag) {
+		return nil, fmt.Errorf("%w: indefinite length for major type %d", ErrMalformed, major)
+	}
+
+	switch major {
+	case majorUnsigned:
+		if arg > math.MaxInt64 {
+			return arg, nil
+		}
+		return int64(arg), nil
+	case majorNegative:
+		if arg > math.MaxInt64 {
+			return nil, fmt.Errorf("%w: negative integer out of range", ErrMalformed)
+		}
+		return -1 - int64(arg), nil
+	case majorBytes, majorText:
+		b, err := d.str(major, info, arg)
+		if err != nil {
+			return nil, err
+		}
+		if major == majorText {
+			return string(b), nil
+		}
+		return b, nil
+	case majorArray:
+		var items []any
+		for i := uint64(0); info == indefinite || i < arg; i++ {
+			if info == indefinite && d.atBreak() {
+				break
+			}
+			v, err := d.item(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, v)
+		}
+		return items, nil
+	case majorMap:
+		m := map[any]any{}
+		for i := uint64(0); info == indefinite || i < arg; i++ {
+			if info == indefinite && d.atBreak() {
+				break
+			}
+			k, err := d.item(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			switch k.(type) {
+			case []any, map[any]any, []byte:
+				return nil, fmt.Errorf("%w: map key of type %T", ErrMalformed, k)
+			}
+			v, err := d.item(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = v
+		}
+		return m, nil
+	case majorTag:
+		return d.item(depth + 1)
+	}
+	return d.simple(info, arg)
+}
+
+// str reads a definite or indefinite length byte or text string
+func (d *decoder) str(major, info byte, arg uint64) ([]byte, error) {
+	if info != indefinite {
+		return d.next(arg)
+	}
+	var b []byte
+	for !d.atBreak() {
+		chunkMajor, chunkInfo, chunkArg, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if chunkMajor != major || chunkInfo == indefinite {
+			return nil, fmt.Errorf("%w: invalid chunk of an indefinite length string", ErrMalformed)
+		}
+		chunk, err := d.next(chunkArg)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, chunk...)
+	}
+	return b, nil
+}
+
+// simple decodes the simple values and the floats
+func (d *decoder) simple(info byte, arg uint64) (any, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		return halfToFloat(uint16(arg)), nil
+	case 26:
+		return float64(math.Float32frombits(uint32(arg))), nil
+	case 27:
+		return math.Float64frombits(arg), nil
+	case indefinite:
+		return nil, fmt.Errorf("%w: unexpected break", ErrMalformed)
+	}
+	return nil, fmt.Errorf("%w: unsupported simple value %d", ErrMalformed, arg)
+}
+
+// halfToFloat converts an IEEE 754 half-precision float
+func halfToFloat(h uint16) float64 {
+	exp := int(h>>10) & 0x1F
+	mant := float64(h & 0x3FF)
+	var v float64
+	switch exp {
+	case 0:
+		v = math.Ldexp(mant, -24)
+	case 0x1F:
+		if mant == 0 {
+			v = math.Inf(1)
+		} else {
+			v = math.NaN()
+		}
+	default:
+		v = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		return -v
+	}
+	return v
+}
+
+// Encode encodes a value decoded from JSON, i.e. nil, bool, float64, string, []any and
+// map[string]any, integers and []byte are accepted as well.
+//
+// Whole floats are encoded as integers and map keys in sorted order so that the encoding is
+// deterministic.
+//
+// Parameters:
+// - v: the value to encode.
+// Returns the encoded item and an error for a value of another type.
+func Encode(v any) ([]byte, error) {
+	return appendItem(nil, v)
+}
+
+func appendItem(b []byte, v any) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return append(b, 0xF6), nil
+	case bool:
+		if v {
+			return append(b, 0xF5), nil
+		}
+		return append(b, 0xF4), nil
+	case int:
+		return appendInt(b, int64(v)), nil
+	case int64:
+		return appendInt(b, v), nil
+	case uint64:
+		return appendHead(b, majorUnsigned, v), nil
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<63 {
+			return appendInt(b, int64(v)), nil
+		}
+		b = append(b, 0xFB)
+		return binary.BigEndian.AppendUint64(b, math.Float64bits(v)), nil
+	case string:
+		return append(appendHead(b, majorText, uint64(len(v))), v...), nil
+	case []byte:
+		return append(appendHead(b, majorBytes, uint64(len(v))), v...), nil
+	case []any:
+		b = appendHead(b, majorArray, uint64(len(v)))
+		for _, item := range v {
+			var err error
+			if b, err = appendItem(b, item); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b = appendHead(b, majorMap, uint64(len(v)))
+		for _, k := range keys {
+			var err error
+			b = append(appendHead(b, majorText, uint64(len(k))), k...)
+			if b, err = appendItem(b, v[k]); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("cbor: cannot encode %T", v)
+}
+
+func appendInt(b []byte, v int64) []byte {
+	if v < 0 {
+		return appendHead(b, majorNegative, uint64(-1-v))
+	}
+	return appendHead(b, majorUnsigned, uint64(v))
+}
+
+// appendHead appends the initial byte and the argument of an item in the fewest bytes
+func appendHead(b []byte, major byte, arg uint64) []byte {
+	switch {
+	case arg < 24:
+		return append(b, major<<5|byte(arg))
+	case arg <= math.MaxUint8:
+		return append(b, major<<5|24, byte(arg))
+	case arg <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, major<<5|25), uint16(arg))
+	case arg <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, major<<5|26), uint32(arg))
+	}
+	return binary.BigEndian.AppendUint64(append(b, major<<5|27), arg)
+}
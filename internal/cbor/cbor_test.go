@@ -0,0 +1,84 @@
+package cbor_test
+
+import (
+	"encoding/hex"
+	"math"
+	"testing"
+
+	"github.com/anggi-susanto/mrt-go/internal/cbor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode(t *testing.T) {
+	// Examples of appendix A of RFC 8949
+	examples := []struct {
+		encoded string
+		decoded any
+	}{
+		{"00", int64(0)},
+		{"1903e8", int64(1000)},
+		{"1bffffffffffffffff", uint64(math.MaxUint64)},
+		{"3903e7", int64(-1000)},
+		{"f93e00", 1.5},
+		{"f9c400", -4.0},
+		{"fa47c35000", 100000.0},
+		{"fb3ff199999999999a", 1.1},
+		{"f4", false},
+		{"f6", nil},
+		{"6449455446", "IETF"},
+		{"4401020304", []byte{1, 2, 3, 4}},
+		{"8301820203820405", []any{int64(1), []any{int64(2), int64(3)}, []any{int64(4), int64(5)}}},
+		{"a26161016162820203", map[any]any{"a": int64(1), "b": []any{int64(2), int64(3)}}},
+		{"c074323031332d30332d32315432303a30343a30305a", "2013-03-21T20:04:00Z"},
+		{"7f657374726561646d696e67ff", "streaming"},
+		{"9f018202039f0405ffff", []any{int64(1), []any{int64(2), int64(3)}, []any{int64(4), int64(5)}}},
+		{"bf61610161629f0203ffff", map[any]any{"a": int64(1), "b": []any{int64(2), int64(3)}}},
+	}
+	for _, e := range examples {
+		t.Run(e.encoded, func(t *testing.T) {
+			data, err := hex.DecodeString(e.encoded)
+			assert.NoError(t, err)
+			v, err := cbor.Decode(data)
+			assert.NoError(t, err)
+			assert.Equal(t, e.decoded, v)
+		})
+	}
+
+	t.Run("Malformed", func(t *testing.T) {
+		for _, encoded := range []string{"", "19", "6449", "830102", "ff", "0001", "a1810000"} {
+			data, _ := hex.DecodeString(encoded)
+			_, err := cbor.Decode(data)
+			assert.ErrorIs(t, err, cbor.ErrMalformed, encoded)
+		}
+	})
+}
+
+func TestEncode(t *testing.T) {
+	examples := []struct {
+		value   any
+		encoded string
+	}{
+		{0.0, "00"},
+		{1000.0, "1903e8"},
+		{int64(-1000), "3903e7"},
+		{1.1, "fb3ff199999999999a"},
+		{false, "f4"},
+		{nil, "f6"},
+		{"IETF", "6449455446"},
+		{[]byte{1, 2, 3, 4}, "4401020304"},
+		{[]any{1.0, []any{2.0, 3.0}}, "8201820203"},
+		{map[string]any{"b": []any{2.0, 3.0}, "a": 1.0}, "a26161016162820203"},
+	}
+	for _, e := range examples {
+		t.Run(e.encoded, func(t *testing.T) {
+			data, err := cbor.Encode(e.value)
+			assert.NoError(t, err)
+			assert.Equal(t, e.encoded, hex.EncodeToString(data))
+		})
+	}
+
+	t.Run("Unsupported type", func(t *testing.T) {
+		_, err := cbor.Encode(struct{}{})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,325 @@
+// Package coap serves the device facing resources over CoAP (RFC 7252), plain or over DTLS with
+// a pre-shared key per device, with observe (RFC 7641) for the resources pushed to the devices.
+package coap
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrMalformed is returned when a datagram is not a well-formed CoAP message
+var ErrMalformed = errors.New("malformed coap message")
+
+// Type is the type of a message
+type Type uint8
+
+// Message types
+const (
+	Confirmable     Type = 0
+	NonConfirmable  Type = 1
+	Acknowledgement Type = 2
+	Reset           Type = 3
+)
+
+// Code is the method of a request or the class and detail of a response, e.g. 2.05 is 69
+type Code uint8
+
+// Method and response codes
+const (
+	Empty                    Code = 0
+	GET                      Code = 1
+	POST                     Code = 2
+	PUT                      Code = 3
+	DELETE                   Code = 4
+	Created                  Code = 2<<5 | 1
+	Deleted                  Code = 2<<5 | 2
+	Valid                    Code = 2<<5 | 3
+	Changed                  Code = 2<<5 | 4
+	Content                  Code = 2<<5 | 5
+	BadRequest               Code = 4<<5 | 0
+	Unauthorized             Code = 4<<5 | 1
+	BadOption                Code = 4<<5 | 2
+	NotFound                 Code = 4<<5 | 4
+	MethodNotAllowed         Code = 4<<5 | 5
+	NotAcceptable            Code = 4<<5 | 6
+	Conflict                 Code = 4<<5 | 9
+	RequestEntityTooLarge    Code = 4<<5 | 13
+	UnsupportedContentFormat Code = 4<<5 | 15
+	UnprocessableEntity      Code = 4<<5 | 22
+	InternalServerError      Code = 5<<5 | 0
+	ServiceUnavailable       Code = 5<<5 | 3
+)
+
+// String returns the code in the c.dd notation
+func (c Code) String() string {
+	return fmt.Sprintf("%d.%02d", c>>5, c&0x1F)
+}
+
+// IsRequest reports whether the code is a method
+func (c Code) IsRequest() bool {
+	return c >= 1 && c < 32
+}
+
+// Option numbers
+const (
+	OptionIfMatch       = 1
+	OptionUriHost       = 3
+	OptionETag          = 4
+	OptionIfNoneMatch   = 5
+	OptionObserve       = 6
+	OptionUriPort       = 7
+	OptionLocationPath  = 8
+	OptionUriPath       = 11
+	OptionContentFormat = 12
+	OptionMaxAge        = 14
+	OptionUriQuery      = 15
+	OptionAccept        = 17
+	OptionLocationQuery = 20
+	OptionSize1         = 60
+)
+
+// Content formats
+const (
+	FormatTextPlain = 0
+	FormatJSON      = 50
+	FormatCBOR      = 60
+)
+
+// NoFormat is the content format of a message without the option
+const NoFormat = -1
+
+// payloadMarker separates the options from the payload
+const payloadMarker = 0xFF
+
+// Option is an option of a message
+type Option struct {
+	Number uint16
+	Value  []byte
+}
+
+// Message is a CoAP message
+type Message struct {
+	Type      Type
+	Code      Code
+	MessageID uint16
+	Token     []byte
+	Options   []Option
+	Payload   []byte
+}
+
+// Unmarshal parses a datagram.
+//
+// Parameters:
+// - data: the datagram.
+// Returns the message and an error wrapping ErrMalformed.
+func Unmarshal(data []byte) (*Message, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("%w: shorter than the header", ErrMalformed)
+	}
+	if version := data[0] >> 6; version != 1 {
+		return nil, fmt.Errorf("%w: version %d", ErrMalformed, version)
+	}
+	tokenLength := int(data[0] & 0x0F)
+	if tokenLength > 8 || len(data) < 4+tokenLength {
+		return nil, fmt.Errorf("%w: invalid token length %d", ErrMalformed, tokenLength)
+	}
+	m := &Message{
+		Type:      Type(data[0] >> 4 & 0x03),
+		Code:      Code(data[1]),
+		MessageID: binary.BigEndian.Uint16(data[2:4]),
+		Token:     append([]byte(nil), data[4:4+tokenLength]...),
+	}
+	rest := data[4+tokenLength:]
+	number := 0
+	for len(rest) > 0 {
+		if rest[0] == payloadMarker {
+			if len(rest) == 1 {
+				return nil, fmt.Errorf("%w: payload marker without payload", ErrMalformed)
+			}
+			m.Payload = append([]byte(nil), rest[1:]...)
+			break
+		}
+		delta, length := int(rest[0]>>4), int(rest[0]&0x0F)
+		rest = rest[1:]
+		var err error
+		if delta, rest, err = extended(delta, rest); err != nil {
+			return nil, err
+		}
+		if length, rest, err = extended(length, rest); err != nil {
+			return nil, err
+		}
+		if len(rest) < length {
+			return nil, fmt.Errorf("%w: option value beyond the datagram", ErrMalformed)
+		}
+		number += delta
+		if number > 0xFFFF {
+			return nil, fmt.Errorf("%w: option number out of range", ErrMalformed)
+		}
+		m.Options = append(m.Options, Option{Number: uint16(number), Value: append([]byte(nil), rest[:length]...)})
+		rest = rest[length:]
+	}
+	if m.Code == Empty && (len(m.Token) > 0 || len(m.Options) > 0 || len(m.Payload) > 0) {
+		return nil, fmt.Errorf("%w: empty message with content", ErrMalformed)
+	}
+	return m, nil
+}
+
+// extended reads the extended option delta or length of a nibble
+func extended(nibble int, rest []byte) (int, []byte, error) {
+	switch nibble {
+	case 13:
+		if len(rest) < 1 {
+			return 0, nil, fmt.Errorf("%w: truncated option", ErrMalformed)
+		}
+		return int(rest[0]) + 13, rest[1:], nil
+	case 14:
+		if len(rest) < 2 {
+			return 0, nil, fmt.Errorf("%w: truncated option", ErrMalformed)
+		}
+		return int(binary.BigEndian.Uint16(rest)) + 269, rest[2:], nil
+	case 15:
+		return 0, nil, fmt.Errorf("%w: reserved option nibble", ErrMalformed)
+	}
+	return nibble, rest, nil
+}
+
+// Marshal encodes the message, the options are sorted by number.
+func (m *Message) Marshal() []byte {
+	data := make([]byte, 4, 4+len(m.Token)+len(m.Payload)+16)
+	data[0] = 1<<6 | byte(m.Type)<<4 | byte(len(m.Token))
+	data[1] = byte(m.Code)
+	binary.BigEndian.PutUint16(data[2:], m.MessageID)
+	data = append(data, m.Token...)
+
+	options := append([]Option(nil), m.Options...)
+	sort.SliceStable(options, func(i, j int) bool { return options[i].Number < options[j].Number })
+	previous := 0
+	for _, o := range options {
+		delta, deltaExt := nibble(int(o.Number) - previous)
+		length, lengthExt := nibble(len(o.Value))
+		data = append(data, byte(delta<<4|length))
+		data = append(data, deltaExt...)
+		data = append(data, lengthExt...)
+		data = append(data, o.Value...)
+		previous = int(o.Number)
+	}
+	if len(m.Payload) > 0 {
+		data = append(data, payloadMarker)
+		data = append(data, m.Payload...)
+	}
+	return data
+}
+
+// nibble returns the nibble and the extended bytes of an option delta or length
+func nibble(n int) (int, []byte) {
+	switch {
+	case n < 13:
+		return n, nil
+	case n < 269:
+		return 13, []byte{byte(n - 13)}
+	}
+	ext := make([]byte, 2)
+	binary.BigEndian.PutUint16(ext, uint16(n-269))
+	return 14, ext
+}
+
+// Option returns the value of the first option with the number, nil when there is none
+func (m *Message) Option(number uint16) []byte {
+	for _, o := range m.Options {
+		if o.Number == number {
+			return o.Value
+		}
+	}
+	return nil
+}
+
+// HasOption reports whether the message carries the option
+func (m *Message) HasOption(number uint16) bool {
+	for _, o := range m.Options {
+		if o.Number == number {
+			return true
+		}
+	}
+	return false
+}
+
+// Uint returns the value of a uint option, fallback when the message does not carry it
+func (m *Message) Uint(number uint16, fallback int) int {
+	for _, o := range m.Options {
+		if o.Number == number {
+			return int(decodeUint(o.Value))
+		}
+	}
+	return fallback
+}
+
+// Path returns the Uri-Path of a request joined with /
+func (m *Message) Path() string {
+	var segments []string
+	for _, o := range m.Options {
+		if o.Number == OptionUriPath {
+			segments = append(segments, string(o.Value))
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// Query returns the value of a key=value Uri-Query of a request
+func (m *Message) Query(key string) string {
+	for _, o := range m.Options {
+		if o.Number == OptionUriQuery {
+			if k, v, ok := strings.Cut(string(o.Value), "="); ok && k == key {
+				return v
+			}
+		}
+	}
+	return ""
+}
+
+// SetUint sets a uint option, replacing the option with the same number
+func (m *Message) SetUint(number uint16, value uint32) {
+	m.Remove(number)
+	m.Options = append(m.Options, Option{Number: number, Value: encodeUint(value)})
+}
+
+// SetPath sets the Uri-Path options of a request from a path joined with /
+func (m *Message) SetPath(path string) {
+	m.Remove(OptionUriPath)
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if segment != "" {
+			m.Options = append(m.Options, Option{Number: OptionUriPath, Value: []byte(segment)})
+		}
+	}
+}
+
+// Remove removes the options with the number
+func (m *Message) Remove(number uint16) {
+	options := m.Options[:0]
+	for _, o := range m.Options {
+		if o.Number != number {
+			options = append(options, o)
+		}
+	}
+	m.Options = options
+}
+
+// encodeUint encodes a uint option value in the fewest bytes, zero is empty
+func encodeUint(v uint32) []byte {
+	var b []byte
+	for ; v > 0; v >>= 8 {
+		b = append([]byte{byte(v)}, b...)
+	}
+	return b
+}
+
+// decodeUint decodes a uint option value of up to 4 bytes
+func decodeUint(b []byte) uint32 {
+	var v uint32
+	for _, c := range b {
+		v = v<<8 | uint32(c)
+	}
+	return v
+}
@@ -0,0 +1,58 @@
+package coap_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/anggi-susanto/mrt-go/internal/coap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessage(t *testing.T) {
+	t.Run("Round trip", func(t *testing.T) {
+		m := &coap.Message{Type: coap.Confirmable, Code: coap.POST, MessageID: 0x1234, Token: []byte{0xCA, 0xFE}, Payload: []byte(`{"pH":7.1}`)}
+		m.SetPath("/readings")
+		m.SetUint(coap.OptionContentFormat, coap.FormatJSON)
+		m.Options = append(m.Options, coap.Option{Number: coap.OptionUriQuery, Value: []byte("device=logger-1")})
+		// An option number above 269 needs the two byte extended delta
+		m.Options = append(m.Options, coap.Option{Number: 2049, Value: []byte("x")})
+
+		decoded, err := coap.Unmarshal(m.Marshal())
+		assert.NoError(t, err)
+		assert.Equal(t, coap.Confirmable, decoded.Type)
+		assert.Equal(t, coap.POST, decoded.Code)
+		assert.Equal(t, uint16(0x1234), decoded.MessageID)
+		assert.Equal(t, []byte{0xCA, 0xFE}, decoded.Token)
+		assert.Equal(t, "readings", decoded.Path())
+		assert.Equal(t, "logger-1", decoded.Query("device"))
+		assert.Equal(t, coap.FormatJSON, decoded.Uint(coap.OptionContentFormat, coap.NoFormat))
+		assert.Equal(t, coap.NoFormat, decoded.Uint(coap.OptionAccept, coap.NoFormat))
+		assert.Equal(t, []byte("x"), decoded.Option(2049))
+		assert.Equal(t, m.Payload, decoded.Payload)
+	})
+
+	t.Run("RFC 7252 example", func(t *testing.T) {
+		// GET /temperature, confirmable, message ID 0x7d34, no token
+		data, _ := hex.DecodeString("40017d34bb74656d7065726174757265")
+		m, err := coap.Unmarshal(data)
+		assert.NoError(t, err)
+		assert.Equal(t, coap.GET, m.Code)
+		assert.Equal(t, "temperature", m.Path())
+		assert.Equal(t, data, m.Marshal())
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		for _, encoded := range []string{"4001", "80017d34", "49017d34", "40017d34ff", "40017d34f0", "40007d34ff00"} {
+			data, _ := hex.DecodeString(encoded)
+			_, err := coap.Unmarshal(data)
+			assert.ErrorIs(t, err, coap.ErrMalformed, encoded)
+		}
+	})
+
+	t.Run("Code", func(t *testing.T) {
+		assert.Equal(t, "2.05", coap.Content.String())
+		assert.Equal(t, "4.15", coap.UnsupportedContentFormat.String())
+		assert.True(t, coap.PUT.IsRequest())
+		assert.False(t, coap.Changed.IsRequest())
+	})
+}
@@ -0,0 +1,37 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	coap "github.com/anggi-susanto/mrt-go/internal/coap"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Router is an autogenerated mock type for the Router type
+type Router struct {
+	mock.Mock
+}
+
+// Handle provides a mock function with given fields: path, handler
+func (_m *Router) Handle(path string, handler coap.Handler) {
+	_m.Called(path, handler)
+}
+
+// Notify provides a mock function with given fields: path, deviceID
+func (_m *Router) Notify(path string, deviceID string) {
+	_m.Called(path, deviceID)
+}
+
+// NewRouter creates a new instance of Router. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRouter(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Router {
+	mock := &Router{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
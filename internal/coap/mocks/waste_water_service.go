@@ -0,0 +1,47 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// WasteWaterService is an autogenerated mock type for the WasteWaterService type
+type WasteWaterService struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, w
+func (_m *WasteWaterService) Create(ctx context.Context, w *domain.WastewaterDataRequest) error {
+	ret := _m.Called(ctx, w)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.WastewaterDataRequest) error); ok {
+		r0 = rf(ctx, w)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewWasteWaterService creates a new instance of WasteWaterService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewWasteWaterService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *WasteWaterService {
+	mock := &WasteWaterService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
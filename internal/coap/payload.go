@@ -0,0 +1,138 @@
+package coap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/cbor"
+)
+
+// errUnsupportedFormat is returned for a payload that is neither JSON nor CBOR
+var errUnsupportedFormat = errors.New("unsupported content format")
+
+// decodeDocument decodes a JSON or CBOR payload into a JSON document, a payload without
+// content format is taken as JSON.
+func decodeDocument(r *Request) (map[string]any, error) {
+	switch r.ContentFormat {
+	case FormatJSON, NoFormat:
+		document := map[string]any{}
+		if err := json.Unmarshal(r.Payload, &document); err != nil {
+			return nil, err
+		}
+		return document, nil
+	case FormatCBOR:
+		item, err := cbor.Decode(r.Payload)
+		if err != nil {
+			return nil, err
+		}
+		value, err := jsonValue(item)
+		if err != nil {
+			return nil, err
+		}
+		document, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: payload is not a map", cbor.ErrMalformed)
+		}
+		return document, nil
+	}
+	return nil, fmt.Errorf("%w %d", errUnsupportedFormat, r.ContentFormat)
+}
+
+// jsonValue converts a decoded CBOR item into the value JSON would have decoded, the map keys
+// must be text and the numbers become float64.
+func jsonValue(item any) (any, error) {
+	switch v := item.(type) {
+	case int64:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case []any:
+		values := make([]any, len(v))
+		for i := range v {
+			value, err := jsonValue(v[i])
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return values, nil
+	case map[any]any:
+		document := make(map[string]any, len(v))
+		for key, item := range v {
+			name, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: map key of type %T", cbor.ErrMalformed, key)
+			}
+			value, err := jsonValue(item)
+			if err != nil {
+				return nil, err
+			}
+			document[name] = value
+		}
+		return document, nil
+	case []byte:
+		return nil, fmt.Errorf("%w: byte strings are not supported", cbor.ErrMalformed)
+	}
+	return item, nil
+}
+
+// decode decodes a JSON document into v.
+func decode(document map[string]any, v any) error {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// invalid answers a payload that could not be decoded.
+func invalid(err error) *Response {
+	code := BadRequest
+	if errors.Is(err, errUnsupportedFormat) {
+		code = UnsupportedContentFormat
+	}
+	return &Response{Code: code, ContentFormat: NoFormat, Payload: []byte(err.Error())}
+}
+
+// respond encodes v in the format the request accepts, JSON unless it asks for CBOR.
+func respond(r *Request, code Code, v any) *Response {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return failure(err)
+	}
+	switch r.Accept {
+	case FormatJSON, NoFormat:
+		return &Response{Code: code, ContentFormat: FormatJSON, Payload: data}
+	case FormatCBOR:
+		var value any
+		if err = json.Unmarshal(data, &value); err != nil {
+			return failure(err)
+		}
+		if data, err = cbor.Encode(value); err != nil {
+			return failure(err)
+		}
+		return &Response{Code: code, ContentFormat: FormatCBOR, Payload: data}
+	}
+	return &Response{Code: NotAcceptable, ContentFormat: NoFormat}
+}
+
+// failure answers an error with its code and the error as diagnostic payload.
+func failure(err error) *Response {
+	return &Response{Code: codeFromError(err), ContentFormat: NoFormat, Payload: []byte(err.Error())}
+}
+
+// codeFromError maps domain validation errors to 4.00, 4.09 or 4.22 and anything else to 5.00.
+func codeFromError(err error) Code {
+	switch {
+	case errors.Is(err, domain.ErrInvalidMeasurement), errors.Is(err, domain.ErrUnknownUnit),
+		errors.Is(err, domain.ErrInvalidDevice), errors.Is(err, domain.ErrInvalidShadow):
+		return BadRequest
+	case errors.Is(err, domain.ErrDeviceDecommissioned), errors.Is(err, domain.ErrShadowVersion):
+		return Conflict
+	case errors.Is(err, domain.ErrOutOfRange):
+		return UnprocessableEntity
+	}
+	return InternalServerError
+}
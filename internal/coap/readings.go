@@ -0,0 +1,90 @@
+package coap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReadingsPath is the resource the devices post their readings to
+const ReadingsPath = "readings"
+
+// WasteWaterService is the interface that wraps the Create method.
+type WasteWaterService interface {
+	Create(ctx context.Context, w *domain.WastewaterDataRequest) error
+}
+
+// ReadingsHandler is the handler of the readings of the devices
+type ReadingsHandler struct {
+	service WasteWaterService
+}
+
+// ReadingResult is the answer to a stored reading
+type ReadingResult struct {
+	ID         primitive.ObjectID `json:"id"`
+	OutOfRange []string           `json:"out_of_range,omitempty"`
+}
+
+// NewReadingsHandler registers the readings handler on the server.
+//
+// Devices post a domain.WastewaterDataRequest to /readings in JSON or CBOR, the device is the
+// one the request authenticated as.
+//
+// Parameters:
+// - router: The server the handler is registered on.
+// - service: The WasteWaterService instance.
+//
+// Return type: None.
+func NewReadingsHandler(router Router, service WasteWaterService) {
+	handler := &ReadingsHandler{service: service}
+	router.Handle(ReadingsPath, handler.Create)
+}
+
+// Create stores a reading of a device.
+//
+// A numeric timestamp is taken as Unix seconds and a reading without timestamp is stamped on
+// receipt. The answer is 2.01 with the reading ID, or 2.04 with the stored ID when the dedup key
+// was already seen.
+//
+// ctx: the context for the operation.
+// r: the request of the device.
+//
+// Returns the response to the device.
+func (h *ReadingsHandler) Create(ctx context.Context, r *Request) *Response {
+	if r.Method != POST {
+		return &Response{Code: MethodNotAllowed, ContentFormat: NoFormat}
+	}
+	deviceID, err := primitive.ObjectIDFromHex(r.DeviceID)
+	if err != nil {
+		return invalid(fmt.Errorf("%w: %s", domain.ErrInvalidDevice, err))
+	}
+	document, err := decodeDocument(r)
+	if err != nil {
+		return invalid(err)
+	}
+	switch timestamp := document["timestamp"].(type) {
+	case nil:
+		document["timestamp"] = time.Now().UTC()
+	case float64:
+		seconds := int64(timestamp)
+		document["timestamp"] = time.Unix(seconds, int64((timestamp-float64(seconds))*1e9)).UTC()
+	}
+	w := &domain.WastewaterDataRequest{}
+	if err = decode(document, w); err != nil {
+		return invalid(err)
+	}
+	w.DeviceID = deviceID
+
+	code := Created
+	if err = h.service.Create(ctx, w); err != nil {
+		if !errors.Is(err, domain.ErrDuplicateReading) {
+			return failure(err)
+		}
+		code = Changed
+	}
+	return respond(r, code, ReadingResult{ID: w.ID, OutOfRange: w.OutOfRange})
+}
@@ -0,0 +1,90 @@
+package coap_test
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/cbor"
+	"github.com/anggi-susanto/mrt-go/internal/coap"
+	"github.com/anggi-susanto/mrt-go/internal/coap/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// registeredHandler registers the handlers on a mocked router and returns the handler of the path.
+func registeredHandler(t *testing.T, path string, register func(router coap.Router)) coap.Handler {
+	var handler coap.Handler
+	router := new(mocks.Router)
+	router.On("Handle", path, mock.Anything).Run(func(args mock.Arguments) {
+		handler = args.Get(1).(coap.Handler)
+	}).Return()
+	register(router)
+	router.AssertExpectations(t)
+	return handler
+}
+
+func TestReadingsHandlerCreate(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	readingID := primitive.NewObjectID()
+	post := func(service coap.WasteWaterService, format int, payload []byte) *coap.Response {
+		handler := registeredHandler(t, coap.ReadingsPath, func(router coap.Router) { coap.NewReadingsHandler(router, service) })
+		return handler(context.Background(), &coap.Request{Method: coap.POST, DeviceID: deviceID.Hex(), ContentFormat: format, Accept: coap.NoFormat, Payload: payload})
+	}
+	t.Run("JSON", func(t *testing.T) {
+		mockService := new(mocks.WasteWaterService)
+		mockService.On("Create", mock.Anything, mock.MatchedBy(func(w *domain.WastewaterDataRequest) bool {
+			return w.DeviceID == deviceID && w.PH == 7.1 && w.Timestamp.Equal(time.Unix(1714550400, 0))
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.WastewaterDataRequest).ID = readingID
+		}).Return(nil)
+
+		// The device in the payload is ignored, the reading belongs to the authenticated device
+		response := post(mockService, coap.FormatJSON, []byte(`{"device_id": "663200000000000000000009", "timestamp": 1714550400, "pH": 7.1}`))
+		assert.Equal(t, coap.Created, response.Code)
+		assert.Equal(t, coap.FormatJSON, response.ContentFormat)
+		result := coap.ReadingResult{}
+		assert.NoError(t, json.Unmarshal(response.Payload, &result))
+		assert.Equal(t, readingID, result.ID)
+	})
+	t.Run("CBOR", func(t *testing.T) {
+		mockService := new(mocks.WasteWaterService)
+		mockService.On("Create", mock.Anything, mock.MatchedBy(func(w *domain.WastewaterDataRequest) bool {
+			return w.DeviceID == deviceID && w.Temperature == 23.5 && w.DedupKey == "r1" && time.Since(w.Timestamp) < time.Minute
+		})).Return(nil)
+
+		payload, _ := cbor.Encode(map[string]any{"Temperature": 23.5, "dedup_key": "r1"})
+		assert.Equal(t, coap.Created, post(mockService, coap.FormatCBOR, payload).Code)
+		mockService.AssertExpectations(t)
+	})
+	t.Run("Duplicate", func(t *testing.T) {
+		mockService := new(mocks.WasteWaterService)
+		mockService.On("Create", mock.Anything, mock.Anything).Return(domain.ErrDuplicateReading)
+		assert.Equal(t, coap.Changed, post(mockService, coap.FormatJSON, []byte(`{"pH": 7.1, "dedup_key": "r1"}`)).Code)
+	})
+	t.Run("Out of range", func(t *testing.T) {
+		mockService := new(mocks.WasteWaterService)
+		mockService.On("Create", mock.Anything, mock.Anything).Return(domain.ErrOutOfRange)
+		response := post(mockService, coap.FormatJSON, []byte(`{"pH": 17}`))
+		assert.Equal(t, coap.UnprocessableEntity, response.Code)
+		assert.Equal(t, domain.ErrOutOfRange.Error(), string(response.Payload))
+	})
+	t.Run("Malformed CBOR", func(t *testing.T) {
+		// A map keyed by integers
+		payload, _ := hex.DecodeString("a10102")
+		assert.Equal(t, coap.BadRequest, post(new(mocks.WasteWaterService), coap.FormatCBOR, payload).Code)
+	})
+	t.Run("Unsupported format", func(t *testing.T) {
+		assert.Equal(t, coap.UnsupportedContentFormat, post(new(mocks.WasteWaterService), coap.FormatTextPlain, []byte("pH=7.1")).Code)
+	})
+	t.Run("Service error", func(t *testing.T) {
+		mockService := new(mocks.WasteWaterService)
+		mockService.On("Create", mock.Anything, mock.Anything).Return(errors.New("database error"))
+		assert.Equal(t, coap.InternalServerError, post(mockService, coap.FormatJSON, []byte(`{"pH": 7.1}`)).Code)
+	})
+}
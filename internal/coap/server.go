@@ -0,0 +1,509 @@
+package coap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/internal/logging"
+	"github.com/pion/dtls/v2"
+	"github.com/pion/dtls/v2/pkg/protocol"
+	"github.com/pion/dtls/v2/pkg/protocol/recordlayer"
+	"github.com/pion/transport/v2/udp"
+)
+
+// log is the logger of the package, its level is configured under "coap"
+var log = logging.Logger("coap")
+
+// exchangeLifetime is how long the response to a request is kept to answer its retransmissions (RFC 7252 section 4.8.2)
+const exchangeLifetime = 247 * time.Second
+
+// maxDatagram is the largest datagram read, CoAP without block-wise transfer stays well below it
+const maxDatagram = 1500
+
+// identityHint is the PSK identity hint sent to the devices
+const identityHint = "mrt"
+
+// Request is a request of a device
+type Request struct {
+	Method Code
+	Path   string
+	// DeviceID is the PSK identity of the device, or its device query over plain CoAP
+	DeviceID      string
+	ContentFormat int
+	Accept        int
+	Payload       []byte
+	Message       *Message
+}
+
+// Response is the answer to a request, ContentFormat is NoFormat without payload
+type Response struct {
+	Code          Code
+	ContentFormat int
+	Payload       []byte
+}
+
+// Handler processes a request on a path
+type Handler func(ctx context.Context, r *Request) *Response
+
+// Router is the interface that wraps the Handle and Notify methods of the server.
+type Router interface {
+	Handle(path string, handler Handler)
+	Notify(path, deviceID string)
+}
+
+// KeyStoreInterface is the interface that wraps the PSK method.
+type KeyStoreInterface interface {
+	PSK(ctx context.Context, identity string) ([]byte, error)
+}
+
+// peer is the remote end of a request, a UDP address or a DTLS session
+type peer interface {
+	key() string
+	identity() string
+	write(data []byte) error
+}
+
+// observer is a device observing a resource
+type observer struct {
+	peer     peer
+	token    []byte
+	path     string
+	deviceID string
+	accept   int
+	sequence uint32
+	// messageID is the ID of the last notification, a reset with this ID cancels the observation
+	messageID uint16
+}
+
+// exchange is the response kept for the retransmissions of a request
+type exchange struct {
+	response []byte
+	expires  time.Time
+}
+
+// Server is the CoAP endpoint shared by the device facing handlers.
+//
+// Confirmable requests are answered with piggybacked responses and their retransmissions with
+// the same response. Notifications of observed resources are non-confirmable, a device cancels
+// an observation with a reset or a GET with observe 1.
+type Server struct {
+	config    config.CoAPConfig
+	keys      KeyStoreInterface
+	messageID atomic.Uint32
+
+	mu        sync.Mutex
+	routes    map[string]Handler
+	observers map[string]*observer
+	exchanges map[string]exchange
+	swept     time.Time
+	closers   []func() error
+}
+
+// NewServer creates a new Server, Start must be called once the handlers are registered.
+//
+// Parameters:
+// - config: the plain and DTLS addresses and the timeouts.
+// - keys: the store of the pre-shared keys of the devices, keyed by identity.
+// Returns a pointer to a Server.
+func NewServer(config config.CoAPConfig, keys KeyStoreInterface) *Server {
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = 10 * time.Minute
+	}
+	s := &Server{
+		config:    config,
+		keys:      keys,
+		routes:    map[string]Handler{},
+		observers: map[string]*observer{},
+		exchanges: map[string]exchange{},
+	}
+	s.messageID.Store(uint32(rand.Intn(1 << 16)))
+	return s
+}
+
+// Handle registers the handler of a path, e.g. readings.
+//
+// path: the Uri-Path segments joined with /.
+// handler: the function processing the requests.
+func (s *Server) Handle(path string, handler Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes[path] = handler
+}
+
+// Start listens on the configured addresses, an empty address is not listened on.
+//
+// Returns an error if an address cannot be listened on.
+func (s *Server) Start() error {
+	if s.config.Address != "" {
+		conn, err := net.ListenPacket("udp", s.config.Address)
+		if err != nil {
+			log.Error(err)
+			return err
+		}
+		go s.Serve(conn)
+	}
+	if s.config.DTLSAddress != "" {
+		addr, err := net.ResolveUDPAddr("udp", s.config.DTLSAddress)
+		if err != nil {
+			log.Error(err)
+			return err
+		}
+		listener, err := (&udp.ListenConfig{AcceptFilter: isHandshake}).Listen("udp", addr)
+		if err != nil {
+			log.Error(err)
+			return err
+		}
+		go s.ServeDTLS(listener)
+	}
+	return nil
+}
+
+// Close stops listening, the observations end with it.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	closers := s.closers
+	s.closers = nil
+	s.observers = map[string]*observer{}
+	s.mu.Unlock()
+
+	var errs []error
+	for _, closer := range closers {
+		errs = append(errs, closer())
+	}
+	return errors.Join(errs...)
+}
+
+// Serve answers plain CoAP on a packet connection until it is closed.
+//
+// Without DTLS a device names itself with a device=<id> Uri-Query, it is meant for development.
+func (s *Server) Serve(conn net.PacketConn) {
+	s.addCloser(conn.Close)
+	buffer := make([]byte, maxDatagram)
+	for {
+		n, addr, err := conn.ReadFrom(buffer)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Error(err)
+			}
+			return
+		}
+		s.receive(&udpPeer{conn: conn, addr: addr}, append([]byte(nil), buffer[:n]...))
+	}
+}
+
+// ServeDTLS answers CoAP over DTLS on the sessions of a UDP listener until it is closed.
+//
+// The devices authenticate with their pre-shared key, the PSK identity names the device.
+func (s *Server) ServeDTLS(listener net.Listener) {
+	s.addCloser(listener.Close)
+	dtlsConfig := &dtls.Config{
+		PSK: func(identity []byte) ([]byte, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+			defer cancel()
+			key, err := s.keys.PSK(ctx, string(identity))
+			if err != nil {
+				return nil, err
+			}
+			if key == nil {
+				return nil, fmt.Errorf("unknown psk identity %q", identity)
+			}
+			return key, nil
+		},
+		PSKIdentityHint: []byte(identityHint),
+		CipherSuites: []dtls.CipherSuiteID{
+			dtls.TLS_PSK_WITH_AES_128_CCM_8,
+			dtls.TLS_PSK_WITH_AES_128_CCM,
+			dtls.TLS_PSK_WITH_AES_128_GCM_SHA256,
+			dtls.TLS_PSK_WITH_AES_128_CBC_SHA256,
+		},
+		ExtendedMasterSecret: dtls.RequestExtendedMasterSecret,
+		ConnectContextMaker: func() (context.Context, func()) {
+			return context.WithTimeout(context.Background(), s.config.Timeout)
+		},
+	}
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Error(err)
+			}
+			return
+		}
+		// The handshake runs apart so that a slow device does not hold up the others
+		go s.session(conn, dtlsConfig)
+	}
+}
+
+// session performs the handshake of a device and answers its requests until it goes idle.
+func (s *Server) session(conn net.Conn, dtlsConfig *dtls.Config) {
+	session, err := dtls.Server(conn, dtlsConfig)
+	if err != nil {
+		log.Warnf("coap dtls handshake with %s: %v", conn.RemoteAddr(), err)
+		conn.Close()
+		return
+	}
+	p := &dtlsPeer{conn: session, id: string(session.ConnectionState().IdentityHint)}
+	defer func() {
+		session.Close()
+		s.forget(p)
+	}()
+	buffer := make([]byte, maxDatagram)
+	for {
+		if err := session.SetReadDeadline(time.Now().Add(s.config.IdleTimeout)); err != nil {
+			return
+		}
+		n, err := session.Read(buffer)
+		if err != nil {
+			return
+		}
+		s.receive(p, append([]byte(nil), buffer[:n]...))
+	}
+}
+
+// receive processes a datagram of a peer.
+func (s *Server) receive(p peer, data []byte) {
+	m, err := Unmarshal(data)
+	if err != nil {
+		// A malformed confirmable message is rejected, anything else is ignored
+		if len(data) >= 4 && data[0]>>6 == 1 && Type(data[0]>>4&0x03) == Confirmable {
+			s.send(p, &Message{Type: Reset, MessageID: uint16(data[2])<<8 | uint16(data[3])})
+		}
+		return
+	}
+	switch {
+	case m.Type == Reset:
+		s.cancel(p, m.MessageID)
+	case m.Type == Acknowledgement:
+	case m.Code == Empty:
+		// An empty confirmable message is a ping
+		if m.Type == Confirmable {
+			s.send(p, &Message{Type: Reset, MessageID: m.MessageID})
+		}
+	case m.Code.IsRequest():
+		s.request(p, m)
+	}
+}
+
+// request answers a request, or its retransmission with the response already sent.
+func (s *Server) request(p peer, m *Message) {
+	exchangeKey := p.key() + "#" + strconv.Itoa(int(m.MessageID))
+	s.mu.Lock()
+	previous, seen := s.exchanges[exchangeKey]
+	handler := s.routes[m.Path()]
+	s.mu.Unlock()
+	if seen {
+		if previous.response != nil {
+			if err := p.write(previous.response); err != nil {
+				log.Error(err)
+			}
+		}
+		return
+	}
+
+	r := &Request{
+		Method:        m.Code,
+		Path:          m.Path(),
+		DeviceID:      p.identity(),
+		ContentFormat: m.Uint(OptionContentFormat, NoFormat),
+		Accept:        m.Uint(OptionAccept, NoFormat),
+		Payload:       m.Payload,
+		Message:       m,
+	}
+	if r.DeviceID == "" {
+		r.DeviceID = m.Query("device")
+	}
+
+	var response *Response
+	switch {
+	case handler == nil:
+		response = &Response{Code: NotFound, ContentFormat: NoFormat}
+	case r.DeviceID == "":
+		response = &Response{Code: Unauthorized, ContentFormat: NoFormat}
+	default:
+		ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+		response = handler(ctx, r)
+		cancel()
+	}
+
+	reply := &Message{Type: NonConfirmable, Code: response.Code, MessageID: s.nextMessageID(), Token: m.Token, Payload: response.Payload}
+	if m.Type == Confirmable {
+		reply.Type, reply.MessageID = Acknowledgement, m.MessageID
+	}
+	if response.ContentFormat != NoFormat {
+		reply.SetUint(OptionContentFormat, uint32(response.ContentFormat))
+	}
+	if r.Method == GET && m.HasOption(OptionObserve) {
+		s.observe(p, r, m, reply, response)
+	}
+
+	data := reply.Marshal()
+	s.remember(exchangeKey, data)
+	if err := p.write(data); err != nil {
+		log.Error(err)
+	}
+}
+
+// observe registers or cancels the observation of a GET request with the observe option.
+func (s *Server) observe(p peer, r *Request, m *Message, reply *Message, response *Response) {
+	key := p.key() + "#" + string(m.Token)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if m.Uint(OptionObserve, 0) != 0 || response.Code>>5 != 2 {
+		delete(s.observers, key)
+		return
+	}
+	o := &observer{peer: p, token: m.Token, path: r.Path, deviceID: r.DeviceID, accept: r.Accept, sequence: 1}
+	s.observers[key] = o
+	reply.SetUint(OptionObserve, o.sequence)
+}
+
+// Notify sends the current representation of a resource to the devices observing it.
+//
+// The handler of the path is called again for each observation, a response that is not a
+// success ends the observation.
+//
+// path: the Uri-Path of the resource.
+// deviceID: the device whose representation changed.
+func (s *Server) Notify(path, deviceID string) {
+	s.mu.Lock()
+	handler := s.routes[path]
+	var observers []*observer
+	for _, o := range s.observers {
+		if o.path == path && o.deviceID == deviceID {
+			observers = append(observers, o)
+		}
+	}
+	s.mu.Unlock()
+	if handler == nil {
+		return
+	}
+
+	for _, o := range observers {
+		ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+		response := handler(ctx, &Request{Method: GET, Path: path, DeviceID: deviceID, ContentFormat: NoFormat, Accept: o.accept})
+		cancel()
+
+		notification := &Message{Type: NonConfirmable, Code: response.Code, MessageID: s.nextMessageID(), Token: o.token, Payload: response.Payload}
+		if response.ContentFormat != NoFormat {
+			notification.SetUint(OptionContentFormat, uint32(response.ContentFormat))
+		}
+		s.mu.Lock()
+		if response.Code>>5 == 2 {
+			// Sequence numbers are 24 bits (RFC 7641 section 4.4)
+			o.sequence = (o.sequence + 1) & 0xFFFFFF
+			o.messageID = notification.MessageID
+			notification.SetUint(OptionObserve, o.sequence)
+		} else {
+			delete(s.observers, o.peer.key()+"#"+string(o.token))
+		}
+		s.mu.Unlock()
+		s.send(o.peer, notification)
+	}
+}
+
+// cancel ends the observation whose last notification a device reset.
+func (s *Server) cancel(p peer, messageID uint16) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, o := range s.observers {
+		if o.peer.key() == p.key() && o.messageID == messageID {
+			delete(s.observers, key)
+		}
+	}
+}
+
+// forget ends the observations of a peer that went away.
+func (s *Server) forget(p peer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, o := range s.observers {
+		if o.peer == p {
+			delete(s.observers, key)
+		}
+	}
+}
+
+// remember keeps the response to a request for its retransmissions and drops the expired ones.
+func (s *Server) remember(key string, response []byte) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.exchanges[key] = exchange{response: response, expires: now.Add(exchangeLifetime)}
+	if now.Sub(s.swept) < time.Minute {
+		return
+	}
+	s.swept = now
+	for k, e := range s.exchanges {
+		if now.After(e.expires) {
+			delete(s.exchanges, k)
+		}
+	}
+}
+
+// send writes a message to a peer.
+func (s *Server) send(p peer, m *Message) {
+	if err := p.write(m.Marshal()); err != nil {
+		log.Error(err)
+	}
+}
+
+// nextMessageID returns the ID of a message the server initiates
+func (s *Server) nextMessageID() uint16 {
+	return uint16(s.messageID.Add(1))
+}
+
+func (s *Server) addCloser(closer func() error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closers = append(s.closers, closer)
+}
+
+// isHandshake reports whether the first packet of a new session is a DTLS handshake
+func isHandshake(packet []byte) bool {
+	records, err := recordlayer.UnpackDatagram(packet)
+	if err != nil || len(records) < 1 {
+		return false
+	}
+	header := &recordlayer.Header{}
+	if err := header.Unmarshal(records[0]); err != nil {
+		return false
+	}
+	return header.ContentType == protocol.ContentTypeHandshake
+}
+
+// udpPeer is a device talking plain CoAP
+type udpPeer struct {
+	conn net.PacketConn
+	addr net.Addr
+}
+
+func (p *udpPeer) key() string      { return "udp/" + p.addr.String() }
+func (p *udpPeer) identity() string { return "" }
+func (p *udpPeer) write(data []byte) error {
+	_, err := p.conn.WriteTo(data, p.addr)
+	return err
+}
+
+// dtlsPeer is a device talking CoAP over a DTLS session
+type dtlsPeer struct {
+	conn net.Conn
+	id   string
+}
+
+func (p *dtlsPeer) key() string      { return "dtls/" + p.conn.RemoteAddr().String() }
+func (p *dtlsPeer) identity() string { return p.id }
+func (p *dtlsPeer) write(data []byte) error {
+	_, err := p.conn.Write(data)
+	return err
+}
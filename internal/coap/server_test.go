@@ -0,0 +1,188 @@
+package coap_test
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/internal/coap"
+	"github.com/anggi-susanto/mrt-go/internal/coap/mocks"
+	"github.com/pion/dtls/v2"
+	"github.com/pion/transport/v2/udp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// serve starts a server on a loopback UDP socket and returns a client connected to it.
+func serve(t *testing.T, server *coap.Server) net.Conn {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.Serve(conn)
+	t.Cleanup(func() { server.Close() })
+	client, err := net.Dial("udp", conn.LocalAddr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// roundTrip sends a message and returns the next message received.
+func roundTrip(t *testing.T, client net.Conn, m *coap.Message) *coap.Message {
+	_, err := client.Write(m.Marshal())
+	require.NoError(t, err)
+	return receive(t, client)
+}
+
+// receive returns the next message received, nil after a short wait.
+func receive(t *testing.T, client net.Conn) *coap.Message {
+	buffer := make([]byte, 1500)
+	require.NoError(t, client.SetReadDeadline(time.Now().Add(500*time.Millisecond)))
+	n, err := client.Read(buffer)
+	if err != nil {
+		return nil
+	}
+	m, err := coap.Unmarshal(buffer[:n])
+	require.NoError(t, err)
+	return m
+}
+
+func request(method coap.Code, path string, messageID uint16) *coap.Message {
+	m := &coap.Message{Type: coap.Confirmable, Code: method, MessageID: messageID, Token: []byte{byte(messageID)}}
+	m.SetPath(path)
+	m.Options = append(m.Options, coap.Option{Number: coap.OptionUriQuery, Value: []byte("device=logger-1")})
+	return m
+}
+
+func TestServerRequests(t *testing.T) {
+	server := coap.NewServer(config.CoAPConfig{}, new(mocks.KeyStoreInterface))
+	var calls atomic.Int32
+	server.Handle("readings", func(ctx context.Context, r *coap.Request) *coap.Response {
+		calls.Add(1)
+		return &coap.Response{Code: coap.Created, ContentFormat: coap.FormatJSON, Payload: []byte(r.DeviceID)}
+	})
+	client := serve(t, server)
+
+	t.Run("Piggybacked response", func(t *testing.T) {
+		reply := roundTrip(t, client, request(coap.POST, "readings", 1))
+		require.NotNil(t, reply)
+		assert.Equal(t, coap.Acknowledgement, reply.Type)
+		assert.Equal(t, uint16(1), reply.MessageID)
+		assert.Equal(t, []byte{1}, reply.Token)
+		assert.Equal(t, coap.Created, reply.Code)
+		assert.Equal(t, coap.FormatJSON, reply.Uint(coap.OptionContentFormat, coap.NoFormat))
+		assert.Equal(t, "logger-1", string(reply.Payload))
+	})
+	t.Run("Retransmission", func(t *testing.T) {
+		before := calls.Load()
+		first := roundTrip(t, client, request(coap.POST, "readings", 2))
+		again := roundTrip(t, client, request(coap.POST, "readings", 2))
+		assert.Equal(t, first, again)
+		assert.Equal(t, before+1, calls.Load())
+	})
+	t.Run("Non-confirmable", func(t *testing.T) {
+		m := request(coap.POST, "readings", 3)
+		m.Type = coap.NonConfirmable
+		reply := roundTrip(t, client, m)
+		require.NotNil(t, reply)
+		assert.Equal(t, coap.NonConfirmable, reply.Type)
+		assert.Equal(t, []byte{3}, reply.Token)
+	})
+	t.Run("Unknown path", func(t *testing.T) {
+		assert.Equal(t, coap.NotFound, roundTrip(t, client, request(coap.GET, "firmware", 4)).Code)
+	})
+	t.Run("No device", func(t *testing.T) {
+		m := request(coap.POST, "readings", 5)
+		m.Remove(coap.OptionUriQuery)
+		assert.Equal(t, coap.Unauthorized, roundTrip(t, client, m).Code)
+	})
+	t.Run("Ping", func(t *testing.T) {
+		reply := roundTrip(t, client, &coap.Message{Type: coap.Confirmable, MessageID: 6})
+		require.NotNil(t, reply)
+		assert.Equal(t, coap.Reset, reply.Type)
+		assert.Equal(t, uint16(6), reply.MessageID)
+	})
+}
+
+func TestServerObserve(t *testing.T) {
+	server := coap.NewServer(config.CoAPConfig{}, new(mocks.KeyStoreInterface))
+	var version atomic.Int32
+	server.Handle("config", func(ctx context.Context, r *coap.Request) *coap.Response {
+		return &coap.Response{Code: coap.Content, ContentFormat: coap.FormatJSON, Payload: []byte{byte('0' + version.Load())}}
+	})
+	client := serve(t, server)
+
+	observe := request(coap.GET, "config", 10)
+	observe.SetUint(coap.OptionObserve, 0)
+	reply := roundTrip(t, client, observe)
+	require.NotNil(t, reply)
+	assert.True(t, reply.HasOption(coap.OptionObserve))
+	assert.Equal(t, "0", string(reply.Payload))
+
+	version.Store(1)
+	server.Notify("config", "logger-2")
+	assert.Nil(t, receive(t, client), "another device is not notified")
+
+	server.Notify("config", "logger-1")
+	notification := receive(t, client)
+	require.NotNil(t, notification)
+	assert.Equal(t, coap.NonConfirmable, notification.Type)
+	assert.Equal(t, []byte{10}, notification.Token)
+	assert.Equal(t, "1", string(notification.Payload))
+	assert.Greater(t, notification.Uint(coap.OptionObserve, 0), reply.Uint(coap.OptionObserve, 0))
+
+	// The device is no longer interested and resets the notification
+	_, err := client.Write((&coap.Message{Type: coap.Reset, MessageID: notification.MessageID}).Marshal())
+	require.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+	server.Notify("config", "logger-1")
+	assert.Nil(t, receive(t, client))
+}
+
+func TestServerDTLS(t *testing.T) {
+	identity := "663200000000000000000001"
+	key := []byte("0123456789abcdef")
+	keys := new(mocks.KeyStoreInterface)
+	keys.On("PSK", mock.Anything, identity).Return(key, nil)
+	keys.On("PSK", mock.Anything, mock.Anything).Return(nil, nil)
+
+	server := coap.NewServer(config.CoAPConfig{}, keys)
+	server.Handle("readings", func(ctx context.Context, r *coap.Request) *coap.Response {
+		return &coap.Response{Code: coap.Created, ContentFormat: coap.NoFormat, Payload: []byte(r.DeviceID)}
+	})
+	listener, err := (&udp.ListenConfig{}).Listen("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	go server.ServeDTLS(listener)
+	t.Cleanup(func() { server.Close() })
+
+	dial := func(identity string) (net.Conn, error) {
+		return dtls.Dial("udp", listener.Addr().(*net.UDPAddr), &dtls.Config{
+			PSK:             func([]byte) ([]byte, error) { return key, nil },
+			PSKIdentityHint: []byte(identity),
+			CipherSuites:    []dtls.CipherSuiteID{dtls.TLS_PSK_WITH_AES_128_CCM_8},
+		})
+	}
+
+	t.Run("Identity names the device", func(t *testing.T) {
+		client, err := dial(identity)
+		require.NoError(t, err)
+		defer client.Close()
+		m := &coap.Message{Type: coap.Confirmable, Code: coap.POST, MessageID: 1}
+		m.SetPath("readings")
+		// The device query of plain CoAP is ignored over DTLS
+		m.Options = append(m.Options, coap.Option{Number: coap.OptionUriQuery, Value: []byte("device=logger-1")})
+		reply := roundTrip(t, client, m)
+		require.NotNil(t, reply)
+		assert.Equal(t, coap.Created, reply.Code)
+		assert.Equal(t, identity, string(reply.Payload))
+	})
+	t.Run("Unknown identity", func(t *testing.T) {
+		client, err := dial("663200000000000000000002")
+		if err == nil {
+			client.Close()
+		}
+		assert.Error(t, err)
+	})
+}
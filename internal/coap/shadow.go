@@ -0,0 +1,115 @@
+package coap
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ConfigPath is the resource of the configuration of a device, its shadow delta
+const ConfigPath = "config"
+
+// ShadowService is the interface that wraps the Get and UpdateReported methods.
+type ShadowService interface {
+	Get(ctx context.Context, deviceID string) (*domain.Shadow, error)
+	UpdateReported(ctx context.Context, deviceID primitive.ObjectID, update domain.ShadowUpdate) (*domain.Shadow, error)
+}
+
+// ShadowHandler is the handler of the configuration of the devices
+type ShadowHandler struct {
+	service ShadowService
+}
+
+// NewShadowHandler registers the configuration handler on the server.
+//
+// Devices GET /config for the domain.ShadowDelta still to apply, with observe to be notified
+// of every change, and PUT the state they run as a domain.ShadowUpdate.
+//
+// Parameters:
+// - router: The server the handler is registered on.
+// - service: The ShadowService instance.
+//
+// Return type: None.
+func NewShadowHandler(router Router, service ShadowService) {
+	handler := &ShadowHandler{service: service}
+	router.Handle(ConfigPath, handler.Config)
+}
+
+// Config answers the delta of a device or stores the state it reports.
+//
+// ctx: the context for the operation.
+// r: the request of the device.
+//
+// Returns the response to the device.
+func (h *ShadowHandler) Config(ctx context.Context, r *Request) *Response {
+	deviceID, err := primitive.ObjectIDFromHex(r.DeviceID)
+	if err != nil {
+		return invalid(fmt.Errorf("%w: %s", domain.ErrInvalidShadow, err))
+	}
+	switch r.Method {
+	case GET:
+		shadow, err := h.service.Get(ctx, deviceID.Hex())
+		if err != nil {
+			return failure(err)
+		}
+		if shadow == nil {
+			return &Response{Code: NotFound, ContentFormat: NoFormat}
+		}
+		return respond(r, Content, delta(shadow))
+	case PUT:
+		document, err := decodeDocument(r)
+		if err != nil {
+			return invalid(err)
+		}
+		update := domain.ShadowUpdate{}
+		if err = decode(document, &update); err != nil {
+			return invalid(err)
+		}
+		shadow, err := h.service.UpdateReported(ctx, deviceID, update)
+		if err != nil {
+			return failure(err)
+		}
+		return respond(r, Changed, delta(shadow))
+	}
+	return &Response{Code: MethodNotAllowed, ContentFormat: NoFormat}
+}
+
+// delta returns the delta message of a shadow
+func delta(shadow *domain.Shadow) domain.ShadowDelta {
+	state := shadow.Delta
+	if state == nil {
+		state = domain.ShadowState{}
+	}
+	return domain.ShadowDelta{DeviceID: shadow.DeviceID, State: state, Version: shadow.Version, Timestamp: time.Now()}
+}
+
+// ConfigPublisher notifies the devices observing their configuration
+type ConfigPublisher struct {
+	router Router
+}
+
+// NewConfigPublisher creates a new ConfigPublisher.
+//
+// Parameters:
+// - router: The server the observers are notified on.
+// Returns a pointer to a ConfigPublisher.
+func NewConfigPublisher(router Router) *ConfigPublisher {
+	return &ConfigPublisher{router: router}
+}
+
+// PublishDelta notifies the observers of the configuration of the device of the delta.
+//
+// The notification carries the delta read again from the shadow, a device that is not
+// observing gets it on its next GET.
+//
+// ctx: the context for the operation.
+// delta: the delta to deliver.
+//
+// Returns nil, a device that cannot be notified keeps the delta in its shadow.
+func (p *ConfigPublisher) PublishDelta(ctx context.Context, delta domain.ShadowDelta) error {
+	p.router.Notify(ConfigPath, delta.DeviceID.Hex())
+	return nil
+}
@@ -0,0 +1,81 @@
+package coap_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/cbor"
+	"github.com/anggi-susanto/mrt-go/internal/coap"
+	"github.com/anggi-susanto/mrt-go/internal/coap/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestShadowHandlerConfig(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	shadow := &domain.Shadow{DeviceID: deviceID, Delta: domain.ShadowState{"interval": 60.0}, Version: 3}
+	call := func(service coap.ShadowService, r *coap.Request) *coap.Response {
+		handler := registeredHandler(t, coap.ConfigPath, func(router coap.Router) { coap.NewShadowHandler(router, service) })
+		r.DeviceID = deviceID.Hex()
+		return handler(context.Background(), r)
+	}
+	t.Run("Get", func(t *testing.T) {
+		mockService := new(mocks.ShadowService)
+		mockService.On("Get", mock.Anything, deviceID.Hex()).Return(shadow, nil)
+		response := call(mockService, &coap.Request{Method: coap.GET, Accept: coap.NoFormat})
+		assert.Equal(t, coap.Content, response.Code)
+		delta := domain.ShadowDelta{}
+		assert.NoError(t, json.Unmarshal(response.Payload, &delta))
+		assert.Equal(t, domain.ShadowState{"interval": 60.0}, delta.State)
+		assert.Equal(t, int64(3), delta.Version)
+	})
+	t.Run("Get CBOR", func(t *testing.T) {
+		mockService := new(mocks.ShadowService)
+		mockService.On("Get", mock.Anything, deviceID.Hex()).Return(shadow, nil)
+		response := call(mockService, &coap.Request{Method: coap.GET, Accept: coap.FormatCBOR})
+		assert.Equal(t, coap.FormatCBOR, response.ContentFormat)
+		decoded, err := cbor.Decode(response.Payload)
+		assert.NoError(t, err)
+		assert.Equal(t, map[any]any{"interval": int64(60)}, decoded.(map[any]any)["state"])
+	})
+	t.Run("Not acceptable", func(t *testing.T) {
+		mockService := new(mocks.ShadowService)
+		mockService.On("Get", mock.Anything, deviceID.Hex()).Return(shadow, nil)
+		assert.Equal(t, coap.NotAcceptable, call(mockService, &coap.Request{Method: coap.GET, Accept: coap.FormatTextPlain}).Code)
+	})
+	t.Run("Device not found", func(t *testing.T) {
+		mockService := new(mocks.ShadowService)
+		mockService.On("Get", mock.Anything, deviceID.Hex()).Return(nil, nil)
+		assert.Equal(t, coap.NotFound, call(mockService, &coap.Request{Method: coap.GET, Accept: coap.NoFormat}).Code)
+	})
+	t.Run("Put reported", func(t *testing.T) {
+		mockService := new(mocks.ShadowService)
+		mockService.On("UpdateReported", mock.Anything, deviceID, domain.ShadowUpdate{State: domain.ShadowState{"interval": 60.0}}).
+			Return(&domain.Shadow{DeviceID: deviceID, Version: 4}, nil)
+		payload, _ := cbor.Encode(map[string]any{"state": map[string]any{"interval": 60.0}})
+		response := call(mockService, &coap.Request{Method: coap.PUT, ContentFormat: coap.FormatCBOR, Accept: coap.NoFormat, Payload: payload})
+		assert.Equal(t, coap.Changed, response.Code)
+		mockService.AssertExpectations(t)
+	})
+	t.Run("Invalid report", func(t *testing.T) {
+		mockService := new(mocks.ShadowService)
+		mockService.On("UpdateReported", mock.Anything, deviceID, mock.Anything).Return(nil, domain.ErrInvalidShadow)
+		response := call(mockService, &coap.Request{Method: coap.PUT, ContentFormat: coap.FormatJSON, Accept: coap.NoFormat, Payload: []byte(`{"state": {}}`)})
+		assert.Equal(t, coap.BadRequest, response.Code)
+	})
+	t.Run("Method not allowed", func(t *testing.T) {
+		assert.Equal(t, coap.MethodNotAllowed, call(new(mocks.ShadowService), &coap.Request{Method: coap.DELETE}).Code)
+	})
+}
+
+func TestConfigPublisherPublishDelta(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	router := new(mocks.Router)
+	router.On("Notify", coap.ConfigPath, deviceID.Hex()).Return()
+	delta := domain.ShadowDelta{DeviceID: deviceID, State: domain.ShadowState{"interval": 60.0}}
+	assert.NoError(t, coap.NewConfigPublisher(router).PublishDelta(context.Background(), delta))
+	router.AssertExpectations(t)
+}
@@ -0,0 +1,50 @@
+package edge
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Agent runs the local sources and the forwarder of an edge agent.
+type Agent struct {
+	queue     *Queue
+	sources   []Source
+	forwarder *Forwarder
+}
+
+// NewAgent creates a new Agent.
+//
+// Parameters:
+// - queue: the on-disk queue the sources write to.
+// - forwarder: the forwarder reading the queue.
+// - sources: the local sources of the readings.
+// Returns:
+// - A pointer to the newly created Agent instance.
+func NewAgent(queue *Queue, forwarder *Forwarder, sources ...Source) *Agent {
+	return &Agent{queue: queue, sources: sources, forwarder: forwarder}
+}
+
+// Run runs the sources and the forwarder until the context is done.
+//
+// The readings still queued then are forwarded by the next run.
+func (a *Agent) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, source := range a.sources {
+		wg.Add(1)
+		go func(source Source) {
+			defer wg.Done()
+			if err := source.Run(ctx, a.queue); err != nil {
+				logrus.Error(err)
+			}
+		}(source)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		a.forwarder.Run(ctx)
+	}()
+	wg.Wait()
+	logrus.Infof("edge agent stopped, %d readings queued", a.queue.Len())
+}
@@ -0,0 +1,237 @@
+package edge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	batchEndpoint = "/waste-water/batch"
+	// maxBatchSize is the largest batch the API accepts
+	maxBatchSize = 500
+)
+
+// errRejectedBatch is returned by post when the API refuses the batch as a whole
+var errRejectedBatch = errors.New("batch rejected")
+
+// Forwarder sends the queued readings to the central API in batches.
+type Forwarder struct {
+	queue  *Queue
+	client *http.Client
+	config config.EdgeConfig
+}
+
+// NewForwarder creates a new Forwarder.
+//
+// Parameters:
+// - queue: the queue of the readings to forward.
+// - config: the API URL, batch size, intervals and clock skew tolerance.
+// Returns:
+// - A pointer to the newly created Forwarder instance.
+func NewForwarder(queue *Queue, config config.EdgeConfig) *Forwarder {
+	if config.BatchSize <= 0 || config.BatchSize > maxBatchSize {
+		config.BatchSize = maxBatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 10 * time.Second
+	}
+	if config.MinBackoff <= 0 {
+		config.MinBackoff = time.Second
+	}
+	if config.MaxBackoff < config.MinBackoff {
+		config.MaxBackoff = config.MinBackoff
+	}
+	config.APIURL = strings.TrimSuffix(config.APIURL, "/")
+	return &Forwarder{
+		queue:  queue,
+		client: &http.Client{Timeout: config.RequestTimeout},
+		config: config,
+	}
+}
+
+// Run forwards the queue every flush interval until the context is done.
+//
+// Failed attempts are retried with an exponential backoff, the readings stay queued meanwhile.
+//
+// ctx: the context stopping the forwarder.
+func (f *Forwarder) Run(ctx context.Context) {
+	backoff := f.config.MinBackoff
+	for {
+		wait := f.config.FlushInterval
+		if _, err := f.Flush(ctx); err != nil {
+			logrus.Warnf("edge forwarding failed, %d readings queued, retrying in %s: %v", f.queue.Len(), backoff, err)
+			wait = backoff
+			backoff *= 2
+			if backoff > f.config.MaxBackoff {
+				backoff = f.config.MaxBackoff
+			}
+		} else {
+			backoff = f.config.MinBackoff
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Flush sends the queued readings until the queue is empty.
+//
+// The clock offset is measured again first while readings are queued, readings the API stored,
+// already had or rejected are removed from the queue while failed ones are kept for the next flush. The readings
+// of a batch the API rejects as a whole are sent again one at a time, only those rejected on their
+// own are dropped.
+//
+// ctx: the context for the requests.
+//
+// Returns the number of readings removed from the queue and an error if a batch could not be sent.
+func (f *Forwarder) Flush(ctx context.Context) (int, error) {
+	if _, ok := f.queue.ClockOffset(); !ok || f.queue.Len() > 0 {
+		// An empty batch is answered at once, its round trip measures the clock offset without the
+		// time the API spends storing readings
+		if _, err := f.send(ctx, nil); err != nil {
+			return 0, err
+		}
+	}
+	forwarded := 0
+	for {
+		entries, err := f.queue.Peek(f.config.BatchSize)
+		if err != nil || len(entries) == 0 {
+			return forwarded, err
+		}
+		readings := make([]domain.WastewaterDataRequest, len(entries))
+		for i, entry := range entries {
+			readings[i] = f.correct(entry.Reading)
+		}
+
+		result, err := f.send(ctx, readings)
+		if errors.Is(err, errRejectedBatch) {
+			// Sending the same batch again would be refused again, the readings refused on their own are found one at a time
+			logrus.Warnf("edge batch of %d readings rejected, sending them one at a time: %v", len(entries), err)
+			result = f.sendEach(ctx, readings)
+		} else if err != nil {
+			return forwarded, err
+		}
+		if len(result.Items) != len(entries) {
+			return forwarded, fmt.Errorf("batch of %d readings answered with %d results", len(entries), len(result.Items))
+		}
+
+		var done []uint64
+		failed := 0
+		for i, item := range result.Items {
+			switch item.Status {
+			case domain.BatchFailed:
+				failed++
+				continue
+			case domain.BatchRejected:
+				if item.Error != "" {
+					logrus.Errorf("edge reading %s rejected: %s", item.DedupKey, item.Error)
+				}
+			}
+			done = append(done, entries[i].Seq)
+		}
+		if err = f.queue.Remove(done...); err != nil {
+			return forwarded, err
+		}
+		forwarded += len(done)
+		if failed > 0 {
+			return forwarded, fmt.Errorf("%d readings failed to be stored", failed)
+		}
+	}
+}
+
+// sendEach sends the readings one at a time and gathers their results.
+//
+// A reading the API refuses is rejected. When a reading cannot be sent it and the following ones
+// are failed, they stay queued.
+func (f *Forwarder) sendEach(ctx context.Context, readings []domain.WastewaterDataRequest) *domain.WasteWaterBatchResult {
+	result := &domain.WasteWaterBatchResult{}
+	for i, reading := range readings {
+		single, err := f.send(ctx, []domain.WastewaterDataRequest{reading})
+		if err == nil && len(single.Items) != 1 {
+			err = fmt.Errorf("reading answered with %d results", len(single.Items))
+		}
+		switch {
+		case errors.Is(err, errRejectedBatch):
+			result.Items = append(result.Items, domain.BatchItemResult{DedupKey: reading.DedupKey, Status: domain.BatchRejected, Error: err.Error()})
+		case err != nil:
+			for _, r := range readings[i:] {
+				result.Items = append(result.Items, domain.BatchItemResult{DedupKey: r.DedupKey, Status: domain.BatchFailed, Error: err.Error()})
+			}
+			return result
+		default:
+			result.Items = append(result.Items, single.Items[0])
+		}
+	}
+	return result
+}
+
+// correct shifts the timestamp of a reading by the clock offset when it exceeds the tolerated skew.
+func (f *Forwarder) correct(reading domain.WastewaterDataRequest) domain.WastewaterDataRequest {
+	offset, ok := f.queue.ClockOffset()
+	if !ok || (offset <= f.config.MaxSkew && offset >= -f.config.MaxSkew) {
+		return reading
+	}
+	reading.Timestamp = reading.Timestamp.Add(offset)
+	return reading
+}
+
+// send posts a batch, the clock offset is measured from the server time of the answer to an empty batch.
+func (f *Forwarder) send(ctx context.Context, readings []domain.WastewaterDataRequest) (*domain.WasteWaterBatchResult, error) {
+	if readings == nil {
+		readings = []domain.WastewaterDataRequest{}
+	}
+	body, err := json.Marshal(domain.WasteWaterBatch{Readings: readings})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.config.APIURL+batchEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(f.config.Username, f.config.Password)
+
+	sent := time.Now()
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	received := time.Now()
+
+	if resp.StatusCode != http.StatusOK {
+		message := struct {
+			Message string `json:"message"`
+		}{}
+		_ = json.NewDecoder(resp.Body).Decode(&message)
+		if resp.StatusCode == http.StatusBadRequest {
+			return nil, fmt.Errorf("%w: %s", errRejectedBatch, message.Message)
+		}
+		return nil, fmt.Errorf("api answered %d: %s", resp.StatusCode, message.Message)
+	}
+	result := &domain.WasteWaterBatchResult{}
+	if err = json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return nil, err
+	}
+
+	if len(readings) == 0 && !result.ServerTime.IsZero() {
+		// The server read its clock about halfway through the round trip
+		local := sent.Add(received.Sub(sent) / 2)
+		if err = f.queue.SetClockOffset(result.ServerTime.Sub(local)); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,144 @@
+package edge_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/edge"
+	"github.com/stretchr/testify/assert"
+)
+
+// batchServer answers the batches with the status returned by status for each reading and a server clock ahead by skew
+func batchServer(t *testing.T, skew time.Duration, status func(domain.WastewaterDataRequest) string) (*httptest.Server, *[]domain.WasteWaterBatch) {
+	var batches []domain.WasteWaterBatch
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/waste-water/batch", r.URL.Path)
+		var batch domain.WasteWaterBatch
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&batch))
+		batches = append(batches, batch)
+		result := domain.WasteWaterBatchResult{ServerTime: time.Now().Add(skew), Items: []domain.BatchItemResult{}}
+		for _, reading := range batch.Readings {
+			result.Items = append(result.Items, domain.BatchItemResult{DedupKey: reading.DedupKey, Status: status(reading)})
+		}
+		_ = json.NewEncoder(w).Encode(result)
+	}))
+	t.Cleanup(server.Close)
+	return server, &batches
+}
+
+func TestForwarderFlush(t *testing.T) {
+	created := func(domain.WastewaterDataRequest) string { return domain.BatchCreated }
+	t.Run("Batches", func(t *testing.T) {
+		server, batches := batchServer(t, 0, created)
+		queue := openQueue(t, 0)
+		for i := 0; i < 5; i++ {
+			assert.NoError(t, queue.Push(domain.WastewaterDataRequest{PH: 7}))
+		}
+		forwarder := edge.NewForwarder(queue, config.EdgeConfig{APIURL: server.URL, BatchSize: 2, MaxSkew: time.Minute})
+		forwarded, err := forwarder.Flush(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 5, forwarded)
+		assert.Equal(t, 0, queue.Len())
+		// The clock is synchronised by an empty batch first
+		assert.Len(t, *batches, 4)
+		assert.Empty(t, (*batches)[0].Readings)
+		assert.Len(t, (*batches)[1].Readings, 2)
+		assert.NotEmpty(t, (*batches)[1].Readings[0].DedupKey)
+	})
+	t.Run("Failed readings kept", func(t *testing.T) {
+		server, _ := batchServer(t, 0, func(r domain.WastewaterDataRequest) string {
+			switch r.PH {
+			case 6:
+				return domain.BatchFailed
+			case 7:
+				return domain.BatchRejected
+			}
+			return domain.BatchDuplicate
+		})
+		queue := openQueue(t, 0)
+		assert.NoError(t, queue.Push(domain.WastewaterDataRequest{PH: 6}, domain.WastewaterDataRequest{PH: 7}, domain.WastewaterDataRequest{PH: 8}))
+		forwarded, err := edge.NewForwarder(queue, config.EdgeConfig{APIURL: server.URL}).Flush(context.Background())
+		assert.Error(t, err)
+		assert.Equal(t, 2, forwarded)
+		entries, _ := queue.Peek(10)
+		assert.Len(t, entries, 1)
+		assert.Equal(t, 6.0, entries[0].Reading.PH)
+	})
+	t.Run("Rejected batch sent one at a time", func(t *testing.T) {
+		var sizes []int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var batch domain.WasteWaterBatch
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&batch))
+			sizes = append(sizes, len(batch.Readings))
+			result := domain.WasteWaterBatchResult{Items: []domain.BatchItemResult{}}
+			for _, reading := range batch.Readings {
+				// The API refuses the whole batch when one of its readings cannot be parsed
+				if reading.PH > 14 {
+					w.WriteHeader(http.StatusBadRequest)
+					_ = json.NewEncoder(w).Encode(map[string]string{"message": "invalid reading"})
+					return
+				}
+				result.Items = append(result.Items, domain.BatchItemResult{DedupKey: reading.DedupKey, Status: domain.BatchCreated})
+			}
+			_ = json.NewEncoder(w).Encode(result)
+		}))
+		t.Cleanup(server.Close)
+		queue := openQueue(t, 0)
+		assert.NoError(t, queue.SetClockOffset(0))
+		assert.NoError(t, queue.Push(domain.WastewaterDataRequest{PH: 6}, domain.WastewaterDataRequest{PH: 99}, domain.WastewaterDataRequest{PH: 8}))
+		forwarded, err := edge.NewForwarder(queue, config.EdgeConfig{APIURL: server.URL}).Flush(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 3, forwarded)
+		assert.Equal(t, 0, queue.Len())
+		assert.Equal(t, []int{0, 3, 1, 1, 1}, sizes)
+	})
+	t.Run("Clock skew corrected", func(t *testing.T) {
+		server, batches := batchServer(t, time.Hour, created)
+		queue := openQueue(t, 0)
+		at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		assert.NoError(t, queue.Push(domain.WastewaterDataRequest{Timestamp: at}))
+		_, err := edge.NewForwarder(queue, config.EdgeConfig{APIURL: server.URL, MaxSkew: time.Minute}).Flush(context.Background())
+		assert.NoError(t, err)
+		sent := (*batches)[1].Readings[0].Timestamp
+		assert.WithinDuration(t, at.Add(time.Hour), sent, time.Second)
+	})
+	t.Run("Clock offset measured with the empty batch", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var batch domain.WasteWaterBatch
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&batch))
+			result := domain.WasteWaterBatchResult{ServerTime: time.Now(), Items: []domain.BatchItemResult{}}
+			for _, reading := range batch.Readings {
+				// A slow batch answers a server time far from the round trip midpoint
+				result.ServerTime = time.Now().Add(time.Hour)
+				result.Items = append(result.Items, domain.BatchItemResult{DedupKey: reading.DedupKey, Status: domain.BatchCreated})
+			}
+			_ = json.NewEncoder(w).Encode(result)
+		}))
+		defer server.Close()
+		queue := openQueue(t, 0)
+		assert.NoError(t, queue.Push(domain.WastewaterDataRequest{PH: 7}))
+		_, err := edge.NewForwarder(queue, config.EdgeConfig{APIURL: server.URL}).Flush(context.Background())
+		assert.NoError(t, err)
+		offset, ok := queue.ClockOffset()
+		assert.True(t, ok)
+		assert.Less(t, offset, time.Second)
+	})
+	t.Run("API unreachable", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+		queue := openQueue(t, 0)
+		assert.NoError(t, queue.SetClockOffset(0))
+		assert.NoError(t, queue.Push(domain.WastewaterDataRequest{PH: 7}))
+		_, err := edge.NewForwarder(queue, config.EdgeConfig{APIURL: server.URL}).Flush(context.Background())
+		assert.Error(t, err)
+		assert.Equal(t, 1, queue.Len())
+	})
+}
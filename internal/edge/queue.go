@@ -0,0 +1,231 @@
+package edge
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/sirupsen/logrus"
+	bolt "go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	readingsBucket = []byte("readings")
+	metaBucket     = []byte("meta")
+	offsetKey      = []byte("clock_offset")
+	countKey       = []byte("count")
+)
+
+// Entry is a reading waiting in the queue
+type Entry struct {
+	Seq     uint64
+	Reading domain.WastewaterDataRequest
+}
+
+// Queue is the on-disk queue of the readings waiting to be forwarded.
+//
+// Readings survive restarts and power cuts, each gets a dedup key when queued so that the API
+// stores it once however many times it is sent.
+type Queue struct {
+	db         *bolt.DB
+	agentID    string
+	maxEntries int
+}
+
+// OpenQueue opens or creates the queue file.
+//
+// Parameters:
+// - path: the file of the queue.
+// - agentID: the prefix of the dedup keys, unique per agent.
+// - maxEntries: the number of readings kept at most, the oldest are dropped beyond it, 0 keeps all.
+// Returns a pointer to a Queue and an error if the file could not be opened.
+func OpenQueue(path string, agentID string, maxEntries int) (*Queue, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(readingsBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(metaBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Queue{db: db, agentID: agentID, maxEntries: maxEntries}, nil
+}
+
+// Close closes the queue file.
+func (q *Queue) Close() error {
+	return q.db.Close()
+}
+
+// Push appends readings to the queue.
+//
+// A reading without a timestamp is stamped with the local clock and a reading without a dedup key gets one.
+//
+// readings: the readings to queue.
+//
+// Returns an error if the readings could not be written, none is queued then.
+func (q *Queue) Push(readings ...domain.WastewaterDataRequest) error {
+	return q.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(readingsBucket)
+		for _, reading := range readings {
+			if reading.Timestamp.IsZero() {
+				reading.Timestamp = time.Now()
+			}
+			if reading.DedupKey == "" {
+				// An ObjectID stays unique when the queue file is lost, unlike the bucket sequence
+				reading.DedupKey = q.agentID + "-" + primitive.NewObjectID().Hex()
+			}
+			seq, err := bucket.NextSequence()
+			if err != nil {
+				return err
+			}
+			value, err := json.Marshal(reading)
+			if err != nil {
+				return err
+			}
+			if err = bucket.Put(itob(seq), value); err != nil {
+				return err
+			}
+		}
+		return q.trim(tx, len(readings))
+	})
+}
+
+// Peek returns the oldest readings without removing them.
+//
+// n: the number of readings to return at most.
+//
+// Returns the entries oldest first and an error if the queue could not be read.
+func (q *Queue) Peek(n int) ([]Entry, error) {
+	var entries []Entry
+	err := q.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(readingsBucket).Cursor()
+		for key, value := cursor.First(); key != nil && len(entries) < n; key, value = cursor.Next() {
+			entry := Entry{Seq: binary.BigEndian.Uint64(key)}
+			if err := json.Unmarshal(value, &entry.Reading); err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// Remove deletes forwarded readings from the queue.
+//
+// seqs: the sequence numbers of the entries to delete.
+//
+// Returns an error if the readings could not be deleted.
+func (q *Queue) Remove(seqs ...uint64) error {
+	return q.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(readingsBucket)
+		removed := 0
+		for _, seq := range seqs {
+			if bucket.Get(itob(seq)) == nil {
+				continue
+			}
+			if err := bucket.Delete(itob(seq)); err != nil {
+				return err
+			}
+			removed++
+		}
+		return addCount(tx, -removed)
+	})
+}
+
+// Len returns the number of readings waiting in the queue.
+func (q *Queue) Len() int {
+	n := 0
+	_ = q.db.View(func(tx *bolt.Tx) error {
+		n = count(tx)
+		return nil
+	})
+	return n
+}
+
+// ClockOffset returns the last measured offset of the server clock to the local clock.
+//
+// Returns the offset and whether one was ever measured.
+func (q *Queue) ClockOffset() (time.Duration, bool) {
+	var offset time.Duration
+	found := false
+	_ = q.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(metaBucket).Get(offsetKey)
+		if value == nil {
+			return nil
+		}
+		n, err := strconv.ParseInt(string(value), 10, 64)
+		if err != nil {
+			return err
+		}
+		offset, found = time.Duration(n), true
+		return nil
+	})
+	return offset, found
+}
+
+// SetClockOffset stores the offset of the server clock to the local clock.
+//
+// offset: the duration to add to the local clock to get the server clock.
+//
+// Returns an error if the offset could not be written.
+func (q *Queue) SetClockOffset(offset time.Duration) error {
+	return q.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(metaBucket).Put(offsetKey, []byte(strconv.FormatInt(int64(offset), 10)))
+	})
+}
+
+// trim counts the pushed readings and drops the oldest ones beyond the capacity of the queue.
+func (q *Queue) trim(tx *bolt.Tx, pushed int) error {
+	if err := addCount(tx, pushed); err != nil {
+		return err
+	}
+	excess := count(tx) - q.maxEntries
+	if q.maxEntries <= 0 || excess <= 0 {
+		return nil
+	}
+	logrus.Warnf("edge queue full, dropping the %d oldest readings", excess)
+	cursor := tx.Bucket(readingsBucket).Cursor()
+	dropped := 0
+	for key, _ := cursor.First(); key != nil && dropped < excess; key, _ = cursor.First() {
+		if err := cursor.Delete(); err != nil {
+			return err
+		}
+		dropped++
+	}
+	return addCount(tx, -dropped)
+}
+
+// count returns the number of readings in the queue, bucket statistics miss the writes of the running transaction.
+func count(tx *bolt.Tx) int {
+	value := tx.Bucket(metaBucket).Get(countKey)
+	if value == nil {
+		return 0
+	}
+	return int(binary.BigEndian.Uint64(value))
+}
+
+// addCount adds to the number of readings in the queue.
+func addCount(tx *bolt.Tx, delta int) error {
+	if delta == 0 {
+		return nil
+	}
+	return tx.Bucket(metaBucket).Put(countKey, itob(uint64(count(tx)+delta)))
+}
+
+// itob encodes a sequence number as a key sorting in order.
+func itob(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
@@ -0,0 +1,67 @@
+package edge_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/edge"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openQueue(t *testing.T, maxEntries int) *edge.Queue {
+	queue, err := edge.OpenQueue(filepath.Join(t.TempDir(), "queue.db"), "depot-1", maxEntries)
+	require.NoError(t, err)
+	t.Cleanup(func() { queue.Close() })
+	return queue
+}
+
+func TestQueue(t *testing.T) {
+	t.Run("Push and remove", func(t *testing.T) {
+		queue := openQueue(t, 0)
+		assert.NoError(t, queue.Push(domain.WastewaterDataRequest{PH: 7}, domain.WastewaterDataRequest{PH: 8, DedupKey: "given"}))
+		assert.Equal(t, 2, queue.Len())
+
+		entries, err := queue.Peek(10)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 2)
+		assert.Equal(t, 7.0, entries[0].Reading.PH)
+		assert.True(t, strings.HasPrefix(entries[0].Reading.DedupKey, "depot-1-"))
+		assert.False(t, entries[0].Reading.Timestamp.IsZero())
+		assert.Equal(t, "given", entries[1].Reading.DedupKey)
+
+		assert.NoError(t, queue.Remove(entries[0].Seq, entries[0].Seq))
+		assert.Equal(t, 1, queue.Len())
+		entries, _ = queue.Peek(10)
+		assert.Equal(t, 8.0, entries[0].Reading.PH)
+	})
+	t.Run("Survives a restart", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "queue.db")
+		queue, err := edge.OpenQueue(path, "depot-1", 0)
+		require.NoError(t, err)
+		assert.NoError(t, queue.Push(domain.WastewaterDataRequest{PH: 7}))
+		assert.NoError(t, queue.SetClockOffset(-3*time.Second))
+		assert.NoError(t, queue.Close())
+
+		queue, err = edge.OpenQueue(path, "depot-1", 0)
+		require.NoError(t, err)
+		defer queue.Close()
+		assert.Equal(t, 1, queue.Len())
+		offset, ok := queue.ClockOffset()
+		assert.True(t, ok)
+		assert.Equal(t, -3*time.Second, offset)
+	})
+	t.Run("Oldest dropped when full", func(t *testing.T) {
+		queue := openQueue(t, 2)
+		for _, ph := range []float64{6, 7, 8} {
+			assert.NoError(t, queue.Push(domain.WastewaterDataRequest{PH: ph}))
+		}
+		entries, _ := queue.Peek(10)
+		assert.Len(t, entries, 2)
+		assert.Equal(t, 7.0, entries[0].Reading.PH)
+		assert.Equal(t, 2, queue.Len())
+	})
+}
@@ -0,0 +1,200 @@
+package edge
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/mqtt"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Sink is the interface that wraps the Push method of the queue.
+type Sink interface {
+	Push(readings ...domain.WastewaterDataRequest) error
+}
+
+// Source is the interface that wraps the Run method of the local reading collectors.
+type Source interface {
+	// Run collects readings into the sink until the context is done
+	Run(ctx context.Context, sink Sink) error
+}
+
+// SpoolSource collects the readings written to files by the local loggers.
+//
+// Each *.jsonl file of the directory holds a reading per line, a file is renamed to *.done once
+// queued so that loggers write to temporary names and rename them when complete.
+type SpoolSource struct {
+	dir      string
+	interval time.Duration
+}
+
+// NewSpoolSource creates a new SpoolSource.
+//
+// Parameters:
+// - dir: the directory the loggers write to.
+// - interval: how often the directory is scanned.
+// Returns:
+// - A pointer to the newly created SpoolSource instance.
+func NewSpoolSource(dir string, interval time.Duration) *SpoolSource {
+	return &SpoolSource{dir: dir, interval: interval}
+}
+
+// Run scans the directory every interval until the context is done.
+func (s *SpoolSource) Run(ctx context.Context, sink Sink) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Scan(sink); err != nil {
+			logrus.Error(err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Scan queues the readings of the complete files of the directory.
+//
+// sink: the queue of the readings.
+//
+// Returns an error if a file could not be read or queued, the file is scanned again next time.
+func (s *SpoolSource) Scan(sink Sink) error {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.jsonl"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		readings, err := readSpoolFile(file)
+		if err != nil {
+			return err
+		}
+		// The readings of a file are queued at once so that a crash never queues part of it
+		if err = sink.Push(readings...); err != nil {
+			return err
+		}
+		if err = os.Rename(file, file+".done"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readSpoolFile decodes the readings of a file, a line that is not a reading is skipped.
+func readSpoolFile(file string) ([]domain.WastewaterDataRequest, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var readings []domain.WastewaterDataRequest
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var reading domain.WastewaterDataRequest
+		if err = json.Unmarshal(scanner.Bytes(), &reading); err != nil {
+			logrus.Warnf("edge skipping line %d of %s: %v", line, file, err)
+			continue
+		}
+		readings = append(readings, reading)
+	}
+	return readings, scanner.Err()
+}
+
+// NewReadingHandler registers the handler of the readings published on the local broker.
+//
+// Parameters:
+// - router: the local broker.
+// - topic: the topic the local loggers publish their readings to, relative to the topic prefix.
+// - sink: the queue of the readings.
+func NewReadingHandler(router mqtt.Router, topic string, sink Sink) {
+	router.Handle(topic, func(_ context.Context, topic string, payload []byte) error {
+		var reading domain.WastewaterDataRequest
+		if err := json.Unmarshal(payload, &reading); err != nil {
+			return fmt.Errorf("invalid reading on %s: %w", topic, err)
+		}
+		return sink.Push(reading)
+	})
+}
+
+// SimulatorSource generates readings drifting around typical values in place of a serial or Modbus
+// instrument, for trying the agent out without hardware.
+type SimulatorSource struct {
+	deviceID primitive.ObjectID
+	interval time.Duration
+	rand     *rand.Rand
+	values   map[string]float64
+}
+
+// NewSimulatorSource creates a new SimulatorSource.
+//
+// Parameters:
+// - deviceID: the device the readings are attributed to.
+// - interval: the time between two readings.
+// Returns:
+// - A pointer to the newly created SimulatorSource instance.
+func NewSimulatorSource(deviceID primitive.ObjectID, interval time.Duration) *SimulatorSource {
+	return &SimulatorSource{
+		deviceID: deviceID,
+		interval: interval,
+		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		values: map[string]float64{
+			domain.ParamBOD:             20,
+			domain.ParamCOD:             60,
+			domain.ParamTOC:             15,
+			domain.ParamDissolvedOxygen: 6,
+			domain.ParamAmmonium:        1.5,
+			domain.ParamNitrate:         8,
+			domain.ParamTurbidity:       4,
+			domain.ParamPH:              7.2,
+			domain.ParamTemperature:     18,
+		},
+	}
+}
+
+// Run generates a reading every interval until the context is done.
+func (s *SimulatorSource) Run(ctx context.Context, sink Sink) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := sink.Push(s.Next()); err != nil {
+				logrus.Error(err)
+			}
+		}
+	}
+}
+
+// Next returns the next simulated reading, each value moves by up to 2% of itself.
+//
+// The reading carries the simulated parameters only.
+func (s *SimulatorSource) Next() domain.WastewaterDataRequest {
+	reading := domain.WastewaterDataRequest{DeviceID: s.deviceID, Timestamp: time.Now()}
+	for _, parameter := range domain.WasteWaterParameters {
+		value, ok := s.values[parameter]
+		if !ok {
+			continue
+		}
+		value += value * (s.rand.Float64() - 0.5) * 0.04
+		s.values[parameter] = value
+		reading.SetValue(parameter, value)
+	}
+	return reading
+}
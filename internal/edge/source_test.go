@@ -0,0 +1,45 @@
+package edge_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/edge"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSpoolSourceScan(t *testing.T) {
+	dir := t.TempDir()
+	content := `{"device_id":"65a000000000000000000001","pH":7.1}
+not a reading
+
+{"pH":7.3}
+`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.jsonl"), []byte(content), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "b.jsonl.tmp"), []byte(`{"pH":9}`), 0o600))
+
+	queue := openQueue(t, 0)
+	assert.NoError(t, edge.NewSpoolSource(dir, time.Second).Scan(queue))
+	assert.Equal(t, 2, queue.Len())
+	assert.FileExists(t, filepath.Join(dir, "a.jsonl.done"))
+	assert.FileExists(t, filepath.Join(dir, "b.jsonl.tmp"))
+
+	// A scanned file is not queued again
+	assert.NoError(t, edge.NewSpoolSource(dir, time.Second).Scan(queue))
+	assert.Equal(t, 2, queue.Len())
+}
+
+func TestSimulatorSourceNext(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	simulator := edge.NewSimulatorSource(deviceID, time.Second)
+	first, second := simulator.Next(), simulator.Next()
+	assert.Equal(t, deviceID, first.DeviceID)
+	assert.InDelta(t, first.PH, second.PH, first.PH*0.02)
+	assert.False(t, second.Timestamp.IsZero())
+	assert.Len(t, second.Values(), 9)
+	assert.NotContains(t, second.Values(), domain.ParamCrudeOils)
+}
@@ -0,0 +1,167 @@
+// Package graphql serves the sites, devices, sensors and readings as a GraphQL schema for the
+// dashboards, which fetch a site with its devices, sensors and latest readings in one request.
+//
+// The relationships are resolved with batch loaders so that a query costs one repository call
+// per relationship rather than one per object, and the readingAdded subscription is fed by the
+// Hub the ingestion pipeline publishes to.
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	gographql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Request is a GraphQL request as posted to the endpoint or sent in a subscribe message.
+type Request struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	OperationName string                 `json:"operationName,omitempty"`
+}
+
+// Do executes a query.
+//
+// ctx: the context for the operation.
+// req: the query and its variables.
+//
+// Returns the result, the errors of the query are in the result.
+func (s *Schema) Do(ctx context.Context, req Request) *gographql.Result {
+	return gographql.Do(gographql.Params{
+		Schema:         s.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        withLoaders(ctx, s.newLoaders()),
+	})
+}
+
+// Subscribe executes a subscription, or a query which then has a single result.
+//
+// ctx: the lifetime of the subscription, the channel is closed once it is done.
+// req: the subscription and its variables.
+//
+// Returns the channel of the results.
+func (s *Schema) Subscribe(ctx context.Context, req Request) chan *gographql.Result {
+	if !isSubscription(req) {
+		results := make(chan *gographql.Result, 1)
+		results <- s.Do(ctx, req)
+		close(results)
+		return results
+	}
+	return gographql.Subscribe(gographql.Params{
+		Schema:         s.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        withLoaders(ctx, s.newLoaders()),
+	})
+}
+
+// isSubscription reports whether the operation of the request is a subscription, the
+// requests which cannot be parsed are left to the executor to report.
+func isSubscription(req Request) bool {
+	document, err := parser.Parse(parser.ParseParams{Source: req.Query})
+	if err != nil {
+		return true
+	}
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if req.OperationName == "" || (operation.Name != nil && operation.Name.Value == req.OperationName) {
+			return operation.Operation == ast.OperationTypeSubscription
+		}
+	}
+	return true
+}
+
+// resolve returns a resolver reading a field of a source of type T.
+func resolve[T any](field func(T) interface{}) gographql.FieldResolveFn {
+	return func(p gographql.ResolveParams) (interface{}, error) {
+		source, ok := p.Source.(T)
+		if !ok {
+			return nil, nil
+		}
+		return field(source), nil
+	}
+}
+
+// pointers returns pointers to the items, the sources of the object types are pointers.
+func pointers[T any](items []T) []*T {
+	list := make([]*T, len(items))
+	for i := range items {
+		list[i] = &items[i]
+	}
+	return list
+}
+
+// emptyList resolves a thunk of a list to an empty list when the key has no value.
+func emptyList(thunk func() (interface{}, error)) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		v, err := thunk()
+		if v == nil && err == nil {
+			return []interface{}{}, nil
+		}
+		return v, err
+	}
+}
+
+// timeOrNil resolves the zero time to null
+func timeOrNil(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
+
+// pagination returns the page and limit arguments of a list, 1 and 10 when not positive
+func pagination(args map[string]interface{}) (int, int) {
+	page, _ := args["page"].(int)
+	limit, _ := args["limit"].(int)
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	return page, limit
+}
+
+func stringArg(args map[string]interface{}, name string) string {
+	s, _ := args[name].(string)
+	return s
+}
+
+func stringsArg(args map[string]interface{}, name string) []string {
+	items, _ := args[name].([]interface{})
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// idArg parses the ObjectID of an ID argument.
+func idArg(args map[string]interface{}, name string) (primitive.ObjectID, error) {
+	return objectID(name, stringArg(args, name))
+}
+
+func objectID(name, hex string) (primitive.ObjectID, error) {
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		return primitive.NilObjectID, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return id, nil
+}
+
+func errUnknownStatus(status string) error {
+	return fmt.Errorf("unknown status %s", status)
+}
@@ -0,0 +1,216 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	gographql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// Endpoint is the path of the GraphQL API, queries are posted to it and subscriptions open a WebSocket on it
+const Endpoint = "/graphql"
+
+// Subprotocol is the WebSocket subprotocol of the subscriptions, graphql-transport-ws of the graphql-ws library
+const Subprotocol = "graphql-transport-ws"
+
+// Messages of the graphql-transport-ws protocol
+const (
+	messageConnectionInit = "connection_init"
+	messageConnectionAck  = "connection_ack"
+	messagePing           = "ping"
+	messagePong           = "pong"
+	messageSubscribe      = "subscribe"
+	messageNext           = "next"
+	messageError          = "error"
+	messageComplete       = "complete"
+)
+
+// Close codes of the graphql-transport-ws protocol
+const (
+	closeBadRequest     = 4400
+	closeUnauthorized   = 4401
+	closeDuplicateID    = 4409
+	closeTooManyInits   = 4429
+	closeInternalServer = 1011
+)
+
+// message is a message of the graphql-transport-ws protocol
+type message struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// operation is a running subscription of a WebSocket
+type operation struct {
+	cancel context.CancelFunc
+}
+
+// Handler is the handler for Schema
+type Handler struct {
+	schema *Schema
+}
+
+// NewHandler initializes a new Handler with the provided Fiber app and Schema.
+//
+// Parameters:
+// - app: The Fiber app instance.
+// - schema: The Schema instance.
+//
+// Return type: None.
+func NewHandler(app *fiber.App, schema *Schema) {
+	handler := &Handler{schema: schema}
+	app.Post(Endpoint, handler.Query)
+	app.Get(Endpoint, handler.upgrade, websocket.New(handler.Subscribe, websocket.Config{Subprotocols: []string{Subprotocol}}))
+}
+
+// Query handles a GraphQL query.
+//
+// @Summary GraphQL query
+// @Description run a query of the GraphQL schema, e.g. a site with its devices, their sensors and latest readings; subscriptions use a WebSocket on the same path with the graphql-transport-ws subprotocol
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Param request body Request true "query, variables and operation name"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /graphql [post]
+func (h *Handler) Query(ctx *fiber.Ctx) error {
+	req := Request{}
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&gographql.Result{Errors: gqlerrors.FormatErrors(err)})
+	}
+	// The errors of the query are part of the result, as of GraphQL over HTTP
+	return ctx.JSON(h.schema.Do(ctx.UserContext(), req))
+}
+
+// upgrade refuses the GET requests which are not a WebSocket upgrade.
+func (h *Handler) upgrade(ctx *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(ctx) {
+		return fiber.ErrUpgradeRequired
+	}
+	return ctx.Next()
+}
+
+// Subscribe serves the subscriptions of a WebSocket with the graphql-transport-ws protocol.
+//
+// conn: the WebSocket, it is closed on return.
+func (h *Handler) Subscribe(conn *websocket.Conn) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var writeMu sync.Mutex
+	write := func(m message) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return conn.WriteJSON(m)
+	}
+	closeWith := func(code int, reason string) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason))
+	}
+
+	var mu sync.Mutex
+	operations := map[string]*operation{}
+	acknowledged := false
+	for {
+		m := message{}
+		if err := conn.ReadJSON(&m); err != nil {
+			if _, ok := err.(*json.SyntaxError); ok {
+				closeWith(closeBadRequest, "Invalid message received")
+			}
+			return
+		}
+		switch m.Type {
+		case messageConnectionInit:
+			if acknowledged {
+				closeWith(closeTooManyInits, "Too many initialisation requests")
+				return
+			}
+			acknowledged = true
+			if err := write(message{Type: messageConnectionAck}); err != nil {
+				return
+			}
+		case messagePing:
+			if err := write(message{Type: messagePong}); err != nil {
+				return
+			}
+		case messagePong:
+		case messageSubscribe:
+			if !acknowledged {
+				closeWith(closeUnauthorized, "Unauthorized")
+				return
+			}
+			req := Request{}
+			if m.ID == "" || json.Unmarshal(m.Payload, &req) != nil {
+				closeWith(closeBadRequest, "Invalid subscribe message")
+				return
+			}
+			mu.Lock()
+			if _, ok := operations[m.ID]; ok {
+				mu.Unlock()
+				closeWith(closeDuplicateID, "Subscriber for "+m.ID+" already exists")
+				return
+			}
+			opCtx, opCancel := context.WithCancel(ctx)
+			op := &operation{cancel: opCancel}
+			operations[m.ID] = op
+			mu.Unlock()
+
+			go func(id string) {
+				results := h.schema.Subscribe(opCtx, req)
+				defer func() {
+					op.cancel()
+					mu.Lock()
+					// The ID may have been completed and used again by then
+					if operations[id] == op {
+						delete(operations, id)
+					}
+					mu.Unlock()
+					// The executor stops once it has handed over its pending result
+					go func() {
+						for range results {
+						}
+					}()
+				}()
+				completed := true
+				for result := range results {
+					// A request refused before running reports its errors alone
+					if result.Data == nil && result.HasErrors() {
+						payload, _ := json.Marshal(result.Errors)
+						_ = write(message{ID: id, Type: messageError, Payload: payload})
+						completed = false
+						break
+					}
+					payload, err := json.Marshal(result)
+					if err != nil {
+						closeWith(closeInternalServer, err.Error())
+						return
+					}
+					if err = write(message{ID: id, Type: messageNext, Payload: payload}); err != nil {
+						return
+					}
+				}
+				// The client completing the subscription expects no complete message
+				if completed && opCtx.Err() == nil {
+					_ = write(message{ID: id, Type: messageComplete})
+				}
+			}(m.ID)
+		case messageComplete:
+			mu.Lock()
+			if op, ok := operations[m.ID]; ok {
+				op.cancel()
+				delete(operations, m.ID)
+			}
+			mu.Unlock()
+		default:
+			closeWith(closeBadRequest, "Unknown message type "+m.Type)
+			return
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package graphql_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/graphql"
+	fastws "github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	contentType     = "Content-Type"
+	applicationJson = "application/json"
+)
+
+func TestHandlerQuery(t *testing.T) {
+	siteID := primitive.NewObjectID()
+	t.Run("Success", func(t *testing.T) {
+		app := fiber.New()
+		schema, repos := newSchema(t, nil)
+		repos.sites.On("GetAll", mock.Anything, 1, 10).Return([]domain.Site{{ID: siteID, Name: "North"}}, nil)
+		graphql.NewHandler(app, schema)
+
+		body, _ := json.Marshal(graphql.Request{Query: `{ sites { id name } }`})
+		req := httptest.NewRequest(fiber.MethodPost, graphql.Endpoint, bytes.NewReader(body))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		b, _ := io.ReadAll(resp.Body)
+		assert.JSONEq(t, `{"data": {"sites": [{"id": "`+siteID.Hex()+`", "name": "North"}]}}`, string(b))
+	})
+	t.Run("Query error", func(t *testing.T) {
+		app := fiber.New()
+		schema, _ := newSchema(t, nil)
+		graphql.NewHandler(app, schema)
+
+		body, _ := json.Marshal(graphql.Request{Query: `{ unknown }`})
+		req := httptest.NewRequest(fiber.MethodPost, graphql.Endpoint, bytes.NewReader(body))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		result := map[string]interface{}{}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		assert.NotEmpty(t, result["errors"])
+	})
+	t.Run("Invalid body", func(t *testing.T) {
+		app := fiber.New()
+		schema, _ := newSchema(t, nil)
+		graphql.NewHandler(app, schema)
+
+		req := httptest.NewRequest(fiber.MethodPost, graphql.Endpoint, bytes.NewReader([]byte(`{`)))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+	t.Run("GET without upgrade", func(t *testing.T) {
+		app := fiber.New()
+		schema, _ := newSchema(t, nil)
+		graphql.NewHandler(app, schema)
+
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, graphql.Endpoint, nil))
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusUpgradeRequired, resp.StatusCode)
+	})
+}
+
+// dialSubscriptions serves the handler on a loopback listener and opens a WebSocket to it.
+func dialSubscriptions(t *testing.T, schema *graphql.Schema) *fastws.Conn {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	graphql.NewHandler(app, schema)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go app.Listener(listener)
+	t.Cleanup(func() { app.Shutdown() })
+
+	dialer := fastws.Dialer{Subprotocols: []string{graphql.Subprotocol}, HandshakeTimeout: time.Second}
+	conn, _, err := dialer.Dial("ws://"+listener.Addr().String()+graphql.Endpoint, nil)
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	assert.Equal(t, graphql.Subprotocol, conn.Subprotocol())
+	return conn
+}
+
+// wsMessage is a message of the graphql-transport-ws protocol as seen by the client
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+func readMessage(t *testing.T, conn *fastws.Conn) wsMessage {
+	m := wsMessage{}
+	assert.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	assert.NoError(t, conn.ReadJSON(&m))
+	return m
+}
+
+func TestHandlerSubscribe(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	readingID := primitive.NewObjectID()
+
+	t.Run("Reading added", func(t *testing.T) {
+		hub := graphql.NewHub()
+		schema, repos := newSchema(t, hub)
+		repos.devices.On("GetByIDs", mock.Anything, []primitive.ObjectID{deviceID}).Return([]domain.Device{{ID: deviceID, Name: "Inlet"}}, nil)
+		conn := dialSubscriptions(t, schema)
+
+		assert.NoError(t, conn.WriteJSON(wsMessage{Type: "connection_init"}))
+		assert.Equal(t, "connection_ack", readMessage(t, conn).Type)
+
+		payload, _ := json.Marshal(graphql.Request{
+			Query:     `subscription($devices: [ID!]) { readingAdded(deviceIds: $devices) { id device { name } values(parameters: ["pH"]) { value } } }`,
+			Variables: map[string]interface{}{"devices": []string{deviceID.Hex()}},
+		})
+		assert.NoError(t, conn.WriteJSON(wsMessage{ID: "1", Type: "subscribe", Payload: payload}))
+		// The subscription is registered once the executor runs
+		assert.Eventually(t, func() bool {
+			hub.PublishMeasurements(context.Background(), []domain.Measurement{{ReadingID: readingID, DeviceID: deviceID, Parameter: domain.ParamPH, Value: 7.2}})
+			m := wsMessage{}
+			_ = conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+			if conn.ReadJSON(&m) != nil {
+				return false
+			}
+			assert.Equal(t, "next", m.Type)
+			assert.Equal(t, "1", m.ID)
+			assert.JSONEq(t, `{"data": {"readingAdded": {"id": "`+readingID.Hex()+`", "device": {"name": "Inlet"}, "values": [{"value": 7.2}]}}}`, string(m.Payload))
+			return true
+		}, 2*time.Second, 10*time.Millisecond)
+
+		assert.NoError(t, conn.WriteJSON(wsMessage{ID: "1", Type: "complete"}))
+		assert.NoError(t, conn.WriteJSON(wsMessage{Type: "ping"}))
+		assert.Equal(t, "pong", readMessage(t, conn).Type)
+	})
+	t.Run("Query", func(t *testing.T) {
+		schema, repos := newSchema(t, nil)
+		repos.sites.On("GetAll", mock.Anything, 1, 10).Return([]domain.Site{{Name: "North"}}, nil)
+		conn := dialSubscriptions(t, schema)
+
+		assert.NoError(t, conn.WriteJSON(wsMessage{Type: "connection_init"}))
+		assert.Equal(t, "connection_ack", readMessage(t, conn).Type)
+		payload, _ := json.Marshal(graphql.Request{Query: `{ sites { name } }`})
+		assert.NoError(t, conn.WriteJSON(wsMessage{ID: "q", Type: "subscribe", Payload: payload}))
+
+		m := readMessage(t, conn)
+		assert.Equal(t, "next", m.Type)
+		assert.JSONEq(t, `{"data": {"sites": [{"name": "North"}]}}`, string(m.Payload))
+		assert.Equal(t, wsMessage{ID: "q", Type: "complete"}, readMessage(t, conn))
+	})
+	t.Run("Invalid query", func(t *testing.T) {
+		schema, _ := newSchema(t, nil)
+		conn := dialSubscriptions(t, schema)
+
+		assert.NoError(t, conn.WriteJSON(wsMessage{Type: "connection_init"}))
+		assert.Equal(t, "connection_ack", readMessage(t, conn).Type)
+		payload, _ := json.Marshal(graphql.Request{Query: `subscription { unknown }`})
+		assert.NoError(t, conn.WriteJSON(wsMessage{ID: "1", Type: "subscribe", Payload: payload}))
+		assert.Equal(t, "error", readMessage(t, conn).Type)
+	})
+	t.Run("Subscribe before init", func(t *testing.T) {
+		schema, _ := newSchema(t, nil)
+		conn := dialSubscriptions(t, schema)
+
+		payload, _ := json.Marshal(graphql.Request{Query: `{ sites { name } }`})
+		assert.NoError(t, conn.WriteJSON(wsMessage{ID: "1", Type: "subscribe", Payload: payload}))
+		_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, _, err := conn.ReadMessage()
+		assert.True(t, fastws.IsCloseError(err, 4401), err)
+	})
+}
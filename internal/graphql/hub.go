@@ -0,0 +1,101 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// subscriptionBuffer is the number of readings a subscriber may lag behind before readings are dropped
+const subscriptionBuffer = 64
+
+// Hub fans the readings stored by the ingestion pipeline out to the subscriptions, it is
+// the measurement.ReadingPublisherInterface of the pipeline.
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+}
+
+// subscriber receives the readings of its devices, of every device when it names none
+type subscriber struct {
+	devices  map[primitive.ObjectID]bool
+	readings chan domain.WasteWaterData
+}
+
+// NewHub creates a new Hub without subscribers.
+func NewHub() *Hub {
+	return &Hub{subscribers: map[*subscriber]struct{}{}}
+}
+
+// Subscribe returns the readings stored from now on, the channel is closed once ctx is done.
+//
+// ctx: the lifetime of the subscription.
+// deviceIDs: the devices to receive the readings of, every device when empty.
+//
+// Returns the channel of the readings.
+func (h *Hub) Subscribe(ctx context.Context, deviceIDs []primitive.ObjectID) <-chan domain.WasteWaterData {
+	s := &subscriber{readings: make(chan domain.WasteWaterData, subscriptionBuffer)}
+	if len(deviceIDs) > 0 {
+		s.devices = map[primitive.ObjectID]bool{}
+		for _, id := range deviceIDs {
+			s.devices[id] = true
+		}
+	}
+	h.mu.Lock()
+	h.subscribers[s] = struct{}{}
+	h.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		h.mu.Lock()
+		delete(h.subscribers, s)
+		close(s.readings)
+		h.mu.Unlock()
+	}()
+	return s.readings
+}
+
+// PublishMeasurements pivots the measurements into readings and hands them to the subscribers.
+//
+// A subscriber lagging behind misses the readings its buffer cannot hold rather than slowing down ingestion.
+//
+// ctx: the context for the operation.
+// measurements: the stored measurements.
+func (h *Hub) PublishMeasurements(ctx context.Context, measurements []domain.Measurement) {
+	var readingIDs []primitive.ObjectID
+	groups := map[primitive.ObjectID][]domain.Measurement{}
+	for _, m := range measurements {
+		if _, ok := groups[m.ReadingID]; !ok {
+			readingIDs = append(readingIDs, m.ReadingID)
+		}
+		groups[m.ReadingID] = append(groups[m.ReadingID], m)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, id := range readingIDs {
+		reading := domain.NewWasteWaterData(id, groups[id])
+		for s := range h.subscribers {
+			if s.devices != nil && !s.devices[reading.DeviceID] {
+				continue
+			}
+			select {
+			case s.readings <- reading:
+			default:
+			}
+		}
+	}
+}
+
+// Pending returns the number of readings waiting in the buffers of the subscribers.
+func (h *Hub) Pending() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	pending := 0
+	for s := range h.subscribers {
+		pending += len(s.readings)
+	}
+	return pending
+}
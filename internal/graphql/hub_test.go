@@ -0,0 +1,54 @@
+package graphql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/graphql"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestHub(t *testing.T) {
+	device1, device2 := primitive.NewObjectID(), primitive.NewObjectID()
+	reading1, reading2 := primitive.NewObjectID(), primitive.NewObjectID()
+	measurements := []domain.Measurement{
+		{ReadingID: reading1, DeviceID: device1, Parameter: domain.ParamPH, Value: 7.2},
+		{ReadingID: reading2, DeviceID: device2, Parameter: domain.ParamPH, Value: 6.9},
+		{ReadingID: reading1, DeviceID: device1, Parameter: domain.ParamTemperature, Value: 23.5},
+	}
+
+	t.Run("Device filter", func(t *testing.T) {
+		hub := graphql.NewHub()
+		ctx, cancel := context.WithCancel(context.Background())
+		all := hub.Subscribe(ctx, nil)
+		one := hub.Subscribe(ctx, []primitive.ObjectID{device1})
+		hub.PublishMeasurements(context.Background(), measurements)
+
+		reading := <-one
+		assert.Equal(t, reading1, reading.ID)
+		assert.Equal(t, 7.2, reading.PH)
+		assert.Equal(t, 23.5, reading.Temperature)
+		assert.Equal(t, reading1, (<-all).ID)
+		assert.Equal(t, reading2, (<-all).ID)
+
+		cancel()
+		assert.Eventually(t, func() bool {
+			_, open := <-one
+			return !open
+		}, time.Second, 10*time.Millisecond)
+	})
+	t.Run("Slow subscriber", func(t *testing.T) {
+		hub := graphql.NewHub()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		readings := hub.Subscribe(ctx, nil)
+		for i := 0; i < 100; i++ {
+			hub.PublishMeasurements(context.Background(), []domain.Measurement{{ReadingID: primitive.NewObjectID(), Parameter: domain.ParamPH}})
+		}
+		assert.Equal(t, 64, len(readings))
+		assert.Equal(t, 64, hub.Pending())
+	})
+}
@@ -0,0 +1,114 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// batchFn loads the values of a batch of keys, keys without a value are left out of the map
+type batchFn[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// loader batches the keys requested while a level of the query is resolved into one call of
+// its batch function and caches the values for the rest of the query.
+//
+// Resolvers call load and return the thunk, the executor calls the thunks of a level once every
+// field of the level was resolved so the first thunk loads the keys of all of them.
+type loader[K comparable, V any] struct {
+	batch   batchFn[K, V]
+	mu      sync.Mutex
+	pending []K
+	entries map[K]entry[V]
+}
+
+// entry is the outcome of loading a key
+type entry[V any] struct {
+	value V
+	found bool
+	err   error
+}
+
+func newLoader[K comparable, V any](batch batchFn[K, V]) *loader[K, V] {
+	return &loader[K, V]{batch: batch, entries: map[K]entry[V]{}}
+}
+
+// load queues the key and returns a thunk resolving to its value, nil when it has none.
+func (l *loader[K, V]) load(ctx context.Context, key K) func() (interface{}, error) {
+	l.mu.Lock()
+	if _, loaded := l.entries[key]; !loaded && !l.queued(key) {
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		e := l.get(ctx, key)
+		if e.err != nil || !e.found {
+			return nil, e.err
+		}
+		return e.value, nil
+	}
+}
+
+// get returns the entry of a key, loading the pending keys first.
+func (l *loader[K, V]) get(ctx context.Context, key K) entry[V] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.pending) > 0 {
+		keys := l.pending
+		l.pending = nil
+		values, err := l.batch(ctx, keys)
+		for _, k := range keys {
+			v, found := values[k]
+			l.entries[k] = entry[V]{value: v, found: found, err: err}
+		}
+	}
+	return l.entries[key]
+}
+
+// queued reports whether the key waits for the next batch, l.mu must be held.
+func (l *loader[K, V]) queued(key K) bool {
+	for _, k := range l.pending {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// reset forgets the loaded entries, a subscription resets its loaders before each event.
+func (l *loader[K, V]) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = map[K]entry[V]{}
+}
+
+// loaders are the batch loaders of a query, one per relationship
+type loaders struct {
+	sites         *loader[primitive.ObjectID, *domain.Site]
+	devices       *loader[primitive.ObjectID, *domain.Device]
+	siteDevices   *loader[primitive.ObjectID, []*domain.Device]
+	deviceSensors *loader[primitive.ObjectID, []*domain.Sensor]
+	latest        *loader[primitive.ObjectID, *domain.WasteWaterData]
+}
+
+func (l *loaders) reset() {
+	l.sites.reset()
+	l.devices.reset()
+	l.siteDevices.reset()
+	l.deviceSensors.reset()
+	l.latest.reset()
+}
+
+type loadersKey struct{}
+
+// withLoaders returns a context carrying the loaders of a query
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+// loadersOf returns the loaders of the query of the context
+func loadersOf(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
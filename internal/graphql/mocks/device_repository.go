@@ -0,0 +1,122 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DeviceRepository is an autogenerated mock type for the DeviceRepository type
+type DeviceRepository struct {
+	mock.Mock
+}
+
+// GetAll provides a mock function with given fields: ctx, filter, page, limit
+func (_m *DeviceRepository) GetAll(ctx context.Context, filter domain.DeviceFilter, page int, limit int) ([]domain.Device, error) {
+	ret := _m.Called(ctx, filter, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAll")
+	}
+
+	var r0 []domain.Device
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.DeviceFilter, int, int) ([]domain.Device, error)); ok {
+		return rf(ctx, filter, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.DeviceFilter, int, int) []domain.Device); ok {
+		r0 = rf(ctx, filter, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Device)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.DeviceFilter, int, int) error); ok {
+		r1 = rf(ctx, filter, page, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByIDs provides a mock function with given fields: ctx, ids
+func (_m *DeviceRepository) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]domain.Device, error) {
+	ret := _m.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByIDs")
+	}
+
+	var r0 []domain.Device
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []primitive.ObjectID) ([]domain.Device, error)); ok {
+		return rf(ctx, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []primitive.ObjectID) []domain.Device); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Device)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []primitive.ObjectID) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBySiteIDs provides a mock function with given fields: ctx, siteIDs
+func (_m *DeviceRepository) GetBySiteIDs(ctx context.Context, siteIDs []primitive.ObjectID) ([]domain.Device, error) {
+	ret := _m.Called(ctx, siteIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBySiteIDs")
+	}
+
+	var r0 []domain.Device
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []primitive.ObjectID) ([]domain.Device, error)); ok {
+		return rf(ctx, siteIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []primitive.ObjectID) []domain.Device); ok {
+		r0 = rf(ctx, siteIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Device)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []primitive.ObjectID) error); ok {
+		r1 = rf(ctx, siteIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewDeviceRepository creates a new instance of DeviceRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewDeviceRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *DeviceRepository {
+	mock := &DeviceRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,122 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReadingRepository is an autogenerated mock type for the ReadingRepository type
+type ReadingRepository struct {
+	mock.Mock
+}
+
+// GetAll provides a mock function with given fields: ctx, page, limit
+func (_m *ReadingRepository) GetAll(ctx context.Context, page int, limit int) ([]domain.WasteWaterData, error) {
+	ret := _m.Called(ctx, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAll")
+	}
+
+	var r0 []domain.WasteWaterData
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]domain.WasteWaterData, error)); ok {
+		return rf(ctx, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) []domain.WasteWaterData); ok {
+		r0 = rf(ctx, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.WasteWaterData)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, page, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByID provides a mock function with given fields: ctx, id
+func (_m *ReadingRepository) GetByID(ctx context.Context, id string) (*domain.WasteWaterData, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *domain.WasteWaterData
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.WasteWaterData, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.WasteWaterData); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.WasteWaterData)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLatestByDeviceIDs provides a mock function with given fields: ctx, deviceIDs
+func (_m *ReadingRepository) GetLatestByDeviceIDs(ctx context.Context, deviceIDs []primitive.ObjectID) ([]domain.WasteWaterData, error) {
+	ret := _m.Called(ctx, deviceIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLatestByDeviceIDs")
+	}
+
+	var r0 []domain.WasteWaterData
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []primitive.ObjectID) ([]domain.WasteWaterData, error)); ok {
+		return rf(ctx, deviceIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []primitive.ObjectID) []domain.WasteWaterData); ok {
+		r0 = rf(ctx, deviceIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.WasteWaterData)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []primitive.ObjectID) error); ok {
+		r1 = rf(ctx, deviceIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewReadingRepository creates a new instance of ReadingRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewReadingRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ReadingRepository {
+	mock := &ReadingRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
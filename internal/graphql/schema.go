@@ -0,0 +1,504 @@
+package graphql
+
+import (
+	"context"
+	"sort"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	gographql "github.com/graphql-go/graphql"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SiteRepository is the interface that wraps the site reads of the schema.
+type SiteRepository interface {
+	GetAll(ctx context.Context, page, limit int) ([]domain.Site, error)
+	GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]domain.Site, error)
+}
+
+// DeviceRepository is the interface that wraps the device reads of the schema.
+type DeviceRepository interface {
+	GetAll(ctx context.Context, filter domain.DeviceFilter, page, limit int) ([]domain.Device, error)
+	GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]domain.Device, error)
+	GetBySiteIDs(ctx context.Context, siteIDs []primitive.ObjectID) ([]domain.Device, error)
+}
+
+// SensorRepository is the interface that wraps the sensor reads of the schema.
+type SensorRepository interface {
+	GetAll(ctx context.Context, page, limit int) ([]domain.Sensor, error)
+	GetByID(ctx context.Context, id string) (*domain.Sensor, error)
+	GetByDeviceIDs(ctx context.Context, deviceIDs []primitive.ObjectID) ([]domain.Sensor, error)
+}
+
+// ReadingRepository is the interface that wraps the waste water data reads of the schema.
+type ReadingRepository interface {
+	GetAll(ctx context.Context, page, limit int) ([]domain.WasteWaterData, error)
+	GetByID(ctx context.Context, id string) (*domain.WasteWaterData, error)
+	GetLatestByDeviceIDs(ctx context.Context, deviceIDs []primitive.ObjectID) ([]domain.WasteWaterData, error)
+}
+
+// ReadingSubscriber is the interface that wraps the Subscribe method of the Hub.
+type ReadingSubscriber interface {
+	Subscribe(ctx context.Context, deviceIDs []primitive.ObjectID) <-chan domain.WasteWaterData
+}
+
+// Schema is the GraphQL schema of the sites, devices, sensors and readings.
+type Schema struct {
+	schema   gographql.Schema
+	sites    SiteRepository
+	devices  DeviceRepository
+	sensors  SensorRepository
+	readings ReadingRepository
+	hub      ReadingSubscriber
+}
+
+// paginationArgs are the arguments of the root lists, as the page and limit query parameters of the REST API
+var paginationArgs = gographql.FieldConfigArgument{
+	"page":  &gographql.ArgumentConfig{Type: gographql.Int, DefaultValue: 1},
+	"limit": &gographql.ArgumentConfig{Type: gographql.Int, DefaultValue: 10},
+}
+
+// NewSchema builds the schema reading from the repositories.
+//
+// Parameters:
+// - sites: The SiteRepository instance.
+// - devices: The DeviceRepository instance.
+// - sensors: The SensorRepository instance.
+// - readings: The ReadingRepository instance.
+// - hub: The ReadingSubscriber the readingAdded subscriptions listen to.
+//
+// Returns the schema and an error if it is invalid.
+func NewSchema(sites SiteRepository, devices DeviceRepository, sensors SensorRepository, readings ReadingRepository, hub ReadingSubscriber) (*Schema, error) {
+	s := &Schema{sites: sites, devices: devices, sensors: sensors, readings: readings, hub: hub}
+
+	locationType := gographql.NewObject(gographql.ObjectConfig{
+		Name: "Location",
+		Fields: gographql.Fields{
+			"latitude":  &gographql.Field{Type: gographql.NewNonNull(gographql.Float), Resolve: resolve(func(p *domain.GeoPoint) interface{} { return p.Coordinates[1] })},
+			"longitude": &gographql.Field{Type: gographql.NewNonNull(gographql.Float), Resolve: resolve(func(p *domain.GeoPoint) interface{} { return p.Coordinates[0] })},
+		},
+	})
+	valueType := gographql.NewObject(gographql.ObjectConfig{
+		Name:        "ParameterValue",
+		Description: "A parameter of a reading",
+		Fields: gographql.Fields{
+			"parameter":  &gographql.Field{Type: gographql.NewNonNull(gographql.String)},
+			"value":      &gographql.Field{Type: gographql.NewNonNull(gographql.Float)},
+			"quality":    &gographql.Field{Type: gographql.String},
+			"outOfRange": &gographql.Field{Type: gographql.NewNonNull(gographql.Boolean)},
+			"anomaly":    &gographql.Field{Type: gographql.NewNonNull(gographql.Boolean)},
+		},
+	})
+	// The types reference each other, the relationship fields are added below
+	siteType := gographql.NewObject(gographql.ObjectConfig{
+		Name: "Site",
+		Fields: gographql.Fields{
+			"id":        &gographql.Field{Type: gographql.NewNonNull(gographql.ID), Resolve: resolve(func(x *domain.Site) interface{} { return x.ID.Hex() })},
+			"name":      &gographql.Field{Type: gographql.NewNonNull(gographql.String), Resolve: resolve(func(x *domain.Site) interface{} { return x.Name })},
+			"address":   &gographql.Field{Type: gographql.String, Resolve: resolve(func(x *domain.Site) interface{} { return x.Address })},
+			"operator":  &gographql.Field{Type: gographql.String, Resolve: resolve(func(x *domain.Site) interface{} { return x.Operator })},
+			"createdAt": &gographql.Field{Type: gographql.DateTime, Resolve: resolve(func(x *domain.Site) interface{} { return x.CreatedAt.Time })},
+			"updatedAt": &gographql.Field{Type: gographql.DateTime, Resolve: resolve(func(x *domain.Site) interface{} { return x.UpdatedAt.Time })},
+		},
+	})
+	deviceType := gographql.NewObject(gographql.ObjectConfig{
+		Name: "Device",
+		Fields: gographql.Fields{
+			"id":               &gographql.Field{Type: gographql.NewNonNull(gographql.ID), Resolve: resolve(func(x *domain.Device) interface{} { return x.ID.Hex() })},
+			"name":             &gographql.Field{Type: gographql.NewNonNull(gographql.String), Resolve: resolve(func(x *domain.Device) interface{} { return x.Name })},
+			"description":      &gographql.Field{Type: gographql.String, Resolve: resolve(func(x *domain.Device) interface{} { return x.Description })},
+			"status":           &gographql.Field{Type: gographql.String, Resolve: resolve(func(x *domain.Device) interface{} { return x.Status })},
+			"serialNumber":     &gographql.Field{Type: gographql.String, Resolve: resolve(func(x *domain.Device) interface{} { return x.SerialNumber })},
+			"model":            &gographql.Field{Type: gographql.String, Resolve: resolve(func(x *domain.Device) interface{} { return x.Model })},
+			"firmware":         &gographql.Field{Type: gographql.String, Resolve: resolve(func(x *domain.Device) interface{} { return x.Firmware })},
+			"hardwareRevision": &gographql.Field{Type: gographql.String, Resolve: resolve(func(x *domain.Device) interface{} { return x.HardwareRevision })},
+			"devEui":           &gographql.Field{Type: gographql.String, Resolve: resolve(func(x *domain.Device) interface{} { return x.DevEUI })},
+			"location": &gographql.Field{Type: locationType, Resolve: resolve(func(x *domain.Device) interface{} {
+				if x.Location == nil || len(x.Location.Coordinates) != 2 {
+					return nil
+				}
+				return x.Location
+			})},
+			"installedAt":      &gographql.Field{Type: gographql.DateTime, Resolve: resolve(func(x *domain.Device) interface{} { return timeOrNil(x.InstalledAt) })},
+			"decommissionedAt": &gographql.Field{Type: gographql.DateTime, Resolve: resolve(func(x *domain.Device) interface{} { return timeOrNil(x.DecommissionedAt) })},
+			"createdAt":        &gographql.Field{Type: gographql.DateTime, Resolve: resolve(func(x *domain.Device) interface{} { return x.CreatedAt.Time })},
+			"updatedAt":        &gographql.Field{Type: gographql.DateTime, Resolve: resolve(func(x *domain.Device) interface{} { return x.UpdatedAt.Time })},
+		},
+	})
+	sensorType := gographql.NewObject(gographql.ObjectConfig{
+		Name: "Sensor",
+		Fields: gographql.Fields{
+			"id":          &gographql.Field{Type: gographql.NewNonNull(gographql.ID), Resolve: resolve(func(x *domain.Sensor) interface{} { return x.ID.Hex() })},
+			"name":        &gographql.Field{Type: gographql.NewNonNull(gographql.String), Resolve: resolve(func(x *domain.Sensor) interface{} { return x.Name })},
+			"description": &gographql.Field{Type: gographql.String, Resolve: resolve(func(x *domain.Sensor) interface{} { return x.Description })},
+			"sensorTypeId": &gographql.Field{Type: gographql.ID, Resolve: resolve(func(x *domain.Sensor) interface{} {
+				if x.TypeID.IsZero() {
+					return nil
+				}
+				return x.TypeID.Hex()
+			})},
+			"createdAt": &gographql.Field{Type: gographql.DateTime, Resolve: resolve(func(x *domain.Sensor) interface{} { return x.CreatedAt.Time })},
+			"updatedAt": &gographql.Field{Type: gographql.DateTime, Resolve: resolve(func(x *domain.Sensor) interface{} { return x.UpdatedAt.Time })},
+		},
+	})
+	readingType := gographql.NewObject(gographql.ObjectConfig{
+		Name:        "WasteWaterData",
+		Description: "A reading of a device, the parameters measured together",
+		Fields: gographql.Fields{
+			"id":          &gographql.Field{Type: gographql.NewNonNull(gographql.ID), Resolve: resolve(func(x *domain.WasteWaterData) interface{} { return x.ID.Hex() })},
+			"timestamp":   &gographql.Field{Type: gographql.NewNonNull(gographql.DateTime), Resolve: resolve(func(x *domain.WasteWaterData) interface{} { return x.Timestamp })},
+			"maintenance": &gographql.Field{Type: gographql.NewNonNull(gographql.Boolean), Resolve: resolve(func(x *domain.WasteWaterData) interface{} { return x.Maintenance })},
+			"values": &gographql.Field{
+				Type:        gographql.NewNonNull(gographql.NewList(gographql.NewNonNull(valueType))),
+				Description: "The parameters of the reading, only the given ones when parameters is set",
+				Args: gographql.FieldConfigArgument{
+					"parameters": &gographql.ArgumentConfig{Type: gographql.NewList(gographql.NewNonNull(gographql.String))},
+				},
+				Resolve: func(p gographql.ResolveParams) (interface{}, error) {
+					w, ok := p.Source.(*domain.WasteWaterData)
+					if !ok {
+						return nil, nil
+					}
+					return parameterValues(w, stringsArg(p.Args, "parameters")), nil
+				},
+			},
+		},
+	})
+
+	siteType.AddFieldConfig("devices", &gographql.Field{
+		Type: gographql.NewNonNull(gographql.NewList(gographql.NewNonNull(deviceType))),
+		Resolve: func(p gographql.ResolveParams) (interface{}, error) {
+			site, ok := p.Source.(*domain.Site)
+			if !ok {
+				return nil, nil
+			}
+			return emptyList(loadersOf(p.Context).siteDevices.load(p.Context, site.ID)), nil
+		},
+	})
+	deviceType.AddFieldConfig("site", &gographql.Field{
+		Type: siteType,
+		Resolve: func(p gographql.ResolveParams) (interface{}, error) {
+			device, ok := p.Source.(*domain.Device)
+			if !ok || device.SiteID.IsZero() {
+				return nil, nil
+			}
+			return loadersOf(p.Context).sites.load(p.Context, device.SiteID), nil
+		},
+	})
+	deviceType.AddFieldConfig("sensors", &gographql.Field{
+		Type: gographql.NewNonNull(gographql.NewList(gographql.NewNonNull(sensorType))),
+		Resolve: func(p gographql.ResolveParams) (interface{}, error) {
+			device, ok := p.Source.(*domain.Device)
+			if !ok {
+				return nil, nil
+			}
+			return emptyList(loadersOf(p.Context).deviceSensors.load(p.Context, device.ID)), nil
+		},
+	})
+	deviceType.AddFieldConfig("latestReading", &gographql.Field{
+		Type: readingType,
+		Resolve: func(p gographql.ResolveParams) (interface{}, error) {
+			device, ok := p.Source.(*domain.Device)
+			if !ok {
+				return nil, nil
+			}
+			return loadersOf(p.Context).latest.load(p.Context, device.ID), nil
+		},
+	})
+	sensorType.AddFieldConfig("device", &gographql.Field{
+		Type: deviceType,
+		Resolve: func(p gographql.ResolveParams) (interface{}, error) {
+			sensor, ok := p.Source.(*domain.Sensor)
+			if !ok {
+				return nil, nil
+			}
+			return loadersOf(p.Context).devices.load(p.Context, sensor.DeviceID), nil
+		},
+	})
+	readingType.AddFieldConfig("device", &gographql.Field{
+		Type: deviceType,
+		Resolve: func(p gographql.ResolveParams) (interface{}, error) {
+			reading, ok := p.Source.(*domain.WasteWaterData)
+			if !ok || reading.DeviceID.IsZero() {
+				return nil, nil
+			}
+			return loadersOf(p.Context).devices.load(p.Context, reading.DeviceID), nil
+		},
+	})
+
+	queryType := gographql.NewObject(gographql.ObjectConfig{
+		Name: "Query",
+		Fields: gographql.Fields{
+			"site": &gographql.Field{
+				Type: siteType,
+				Args: gographql.FieldConfigArgument{"id": &gographql.ArgumentConfig{Type: gographql.NewNonNull(gographql.ID)}},
+				Resolve: func(p gographql.ResolveParams) (interface{}, error) {
+					id, err := idArg(p.Args, "id")
+					if err != nil {
+						return nil, err
+					}
+					return loadersOf(p.Context).sites.load(p.Context, id), nil
+				},
+			},
+			"sites": &gographql.Field{
+				Type: gographql.NewNonNull(gographql.NewList(gographql.NewNonNull(siteType))),
+				Args: paginationArgs,
+				Resolve: func(p gographql.ResolveParams) (interface{}, error) {
+					page, limit := pagination(p.Args)
+					sites, err := s.sites.GetAll(p.Context, page, limit)
+					return pointers(sites), err
+				},
+			},
+			"device": &gographql.Field{
+				Type: deviceType,
+				Args: gographql.FieldConfigArgument{"id": &gographql.ArgumentConfig{Type: gographql.NewNonNull(gographql.ID)}},
+				Resolve: func(p gographql.ResolveParams) (interface{}, error) {
+					id, err := idArg(p.Args, "id")
+					if err != nil {
+						return nil, err
+					}
+					return loadersOf(p.Context).devices.load(p.Context, id), nil
+				},
+			},
+			"devices": &gographql.Field{
+				Type:        gographql.NewNonNull(gographql.NewList(gographql.NewNonNull(deviceType))),
+				Description: "The devices matching the inventory filter",
+				Args: gographql.FieldConfigArgument{
+					"page":   paginationArgs["page"],
+					"limit":  paginationArgs["limit"],
+					"status": &gographql.ArgumentConfig{Type: gographql.String},
+					"model":  &gographql.ArgumentConfig{Type: gographql.String},
+					"siteId": &gographql.ArgumentConfig{Type: gographql.ID},
+				},
+				Resolve: func(p gographql.ResolveParams) (interface{}, error) {
+					filter := domain.DeviceFilter{Status: stringArg(p.Args, "status"), Model: stringArg(p.Args, "model")}
+					if filter.Status != "" && !domain.IsDeviceStatus(filter.Status) {
+						return nil, errUnknownStatus(filter.Status)
+					}
+					if _, ok := p.Args["siteId"]; ok {
+						siteID, err := idArg(p.Args, "siteId")
+						if err != nil {
+							return nil, err
+						}
+						filter.SiteID = siteID
+					}
+					page, limit := pagination(p.Args)
+					devices, err := s.devices.GetAll(p.Context, filter, page, limit)
+					return pointers(devices), err
+				},
+			},
+			"sensor": &gographql.Field{
+				Type: sensorType,
+				Args: gographql.FieldConfigArgument{"id": &gographql.ArgumentConfig{Type: gographql.NewNonNull(gographql.ID)}},
+				Resolve: func(p gographql.ResolveParams) (interface{}, error) {
+					id, err := idArg(p.Args, "id")
+					if err != nil {
+						return nil, err
+					}
+					sensor, err := s.sensors.GetByID(p.Context, id.Hex())
+					if err != nil || sensor == nil {
+						return nil, err
+					}
+					return sensor, nil
+				},
+			},
+			"sensors": &gographql.Field{
+				Type: gographql.NewNonNull(gographql.NewList(gographql.NewNonNull(sensorType))),
+				Args: paginationArgs,
+				Resolve: func(p gographql.ResolveParams) (interface{}, error) {
+					page, limit := pagination(p.Args)
+					sensors, err := s.sensors.GetAll(p.Context, page, limit)
+					return pointers(sensors), err
+				},
+			},
+			"reading": &gographql.Field{
+				Type: readingType,
+				Args: gographql.FieldConfigArgument{"id": &gographql.ArgumentConfig{Type: gographql.NewNonNull(gographql.ID)}},
+				Resolve: func(p gographql.ResolveParams) (interface{}, error) {
+					id, err := idArg(p.Args, "id")
+					if err != nil {
+						return nil, err
+					}
+					reading, err := s.readings.GetByID(p.Context, id.Hex())
+					if err != nil || reading == nil {
+						return nil, err
+					}
+					return reading, nil
+				},
+			},
+			"readings": &gographql.Field{
+				Type:        gographql.NewNonNull(gographql.NewList(gographql.NewNonNull(readingType))),
+				Description: "The readings of every device, newest first",
+				Args:        paginationArgs,
+				Resolve: func(p gographql.ResolveParams) (interface{}, error) {
+					page, limit := pagination(p.Args)
+					readings, err := s.readings.GetAll(p.Context, page, limit)
+					return pointers(readings), err
+				},
+			},
+		},
+	})
+
+	subscriptionType := gographql.NewObject(gographql.ObjectConfig{
+		Name: "Subscription",
+		Fields: gographql.Fields{
+			"readingAdded": &gographql.Field{
+				Type:        gographql.NewNonNull(readingType),
+				Description: "The readings stored from now on, of the given devices or of every device",
+				Args: gographql.FieldConfigArgument{
+					"deviceIds": &gographql.ArgumentConfig{Type: gographql.NewList(gographql.NewNonNull(gographql.ID))},
+				},
+				Subscribe: func(p gographql.ResolveParams) (interface{}, error) {
+					var deviceIDs []primitive.ObjectID
+					for _, hex := range stringsArg(p.Args, "deviceIds") {
+						id, err := objectID("deviceIds", hex)
+						if err != nil {
+							return nil, err
+						}
+						deviceIDs = append(deviceIDs, id)
+					}
+					readings := s.hub.Subscribe(p.Context, deviceIDs)
+					events := make(chan interface{})
+					go func() {
+						defer close(events)
+						for reading := range readings {
+							reading := reading
+							// Each event is a query of its own, the related objects are loaded again
+							loadersOf(p.Context).reset()
+							select {
+							case events <- &reading:
+							case <-p.Context.Done():
+								return
+							}
+						}
+					}()
+					return events, nil
+				},
+				Resolve: func(p gographql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+			},
+		},
+	})
+
+	schema, err := gographql.NewSchema(gographql.SchemaConfig{
+		Query:        queryType,
+		Subscription: subscriptionType,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.schema = schema
+	return s, nil
+}
+
+// newLoaders creates the loaders of a query.
+func (s *Schema) newLoaders() *loaders {
+	return &loaders{
+		sites: newLoader(func(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]*domain.Site, error) {
+			sites, err := s.sites.GetByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			values := make(map[primitive.ObjectID]*domain.Site, len(sites))
+			for i := range sites {
+				values[sites[i].ID] = &sites[i]
+			}
+			return values, nil
+		}),
+		devices: newLoader(func(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]*domain.Device, error) {
+			devices, err := s.devices.GetByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			values := make(map[primitive.ObjectID]*domain.Device, len(devices))
+			for i := range devices {
+				values[devices[i].ID] = &devices[i]
+			}
+			return values, nil
+		}),
+		siteDevices: newLoader(func(ctx context.Context, siteIDs []primitive.ObjectID) (map[primitive.ObjectID][]*domain.Device, error) {
+			devices, err := s.devices.GetBySiteIDs(ctx, siteIDs)
+			if err != nil {
+				return nil, err
+			}
+			values := map[primitive.ObjectID][]*domain.Device{}
+			for i := range devices {
+				values[devices[i].SiteID] = append(values[devices[i].SiteID], &devices[i])
+			}
+			return values, nil
+		}),
+		deviceSensors: newLoader(func(ctx context.Context, deviceIDs []primitive.ObjectID) (map[primitive.ObjectID][]*domain.Sensor, error) {
+			sensors, err := s.sensors.GetByDeviceIDs(ctx, deviceIDs)
+			if err != nil {
+				return nil, err
+			}
+			values := map[primitive.ObjectID][]*domain.Sensor{}
+			for i := range sensors {
+				values[sensors[i].DeviceID] = append(values[sensors[i].DeviceID], &sensors[i])
+			}
+			return values, nil
+		}),
+		latest: newLoader(func(ctx context.Context, deviceIDs []primitive.ObjectID) (map[primitive.ObjectID]*domain.WasteWaterData, error) {
+			readings, err := s.readings.GetLatestByDeviceIDs(ctx, deviceIDs)
+			if err != nil {
+				return nil, err
+			}
+			values := make(map[primitive.ObjectID]*domain.WasteWaterData, len(readings))
+			for i := range readings {
+				values[readings[i].DeviceID] = &readings[i]
+			}
+			return values, nil
+		}),
+	}
+}
+
+// parameterValue is a parameter of a reading as resolved by the ParameterValue type
+type parameterValue struct {
+	Parameter  string  `json:"parameter"`
+	Value      float64 `json:"value"`
+	Quality    string  `json:"quality"`
+	OutOfRange bool    `json:"outOfRange"`
+	Anomaly    bool    `json:"anomaly"`
+}
+
+// parameterValues lists the parameters of a reading in the order of the waste water fields followed
+// by the other parameters by name, only the given ones when parameters is not empty.
+func parameterValues(w *domain.WasteWaterData, parameters []string) []parameterValue {
+	values := w.Values()
+	names := append([]string{}, domain.WasteWaterParameters...)
+	extra := make([]string, 0, len(w.Parameters))
+	for parameter, value := range w.Parameters {
+		values[parameter] = value
+		extra = append(extra, parameter)
+	}
+	sort.Strings(extra)
+	names = append(names, extra...)
+	if len(parameters) > 0 {
+		names = parameters
+	}
+
+	list := make([]parameterValue, 0, len(names))
+	for _, parameter := range names {
+		value, ok := values[parameter]
+		if !ok {
+			continue
+		}
+		list = append(list, parameterValue{
+			Parameter:  parameter,
+			Value:      value,
+			Quality:    w.Quality[parameter],
+			OutOfRange: contains(w.OutOfRange, parameter),
+			Anomaly:    contains(w.Anomalies, parameter),
+		})
+	}
+	return list
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
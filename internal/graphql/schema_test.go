@@ -0,0 +1,171 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/graphql"
+	"github.com/anggi-susanto/mrt-go/internal/graphql/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// repositories are the mocked repositories of a schema
+type repositories struct {
+	sites    *mocks.SiteRepository
+	devices  *mocks.DeviceRepository
+	sensors  *mocks.SensorRepository
+	readings *mocks.ReadingRepository
+}
+
+func newSchema(t *testing.T, hub *graphql.Hub) (*graphql.Schema, repositories) {
+	repos := repositories{new(mocks.SiteRepository), new(mocks.DeviceRepository), new(mocks.SensorRepository), new(mocks.ReadingRepository)}
+	if hub == nil {
+		hub = graphql.NewHub()
+	}
+	schema, err := graphql.NewSchema(repos.sites, repos.devices, repos.sensors, repos.readings, hub)
+	assert.NoError(t, err)
+	return schema, repos
+}
+
+// data returns the data of a result as JSON
+func data(t *testing.T, v interface{}) string {
+	b, err := json.Marshal(v)
+	assert.NoError(t, err)
+	return string(b)
+}
+
+// sameIDs matches a slice of ObjectIDs holding the given ones in any order
+func sameIDs(ids ...primitive.ObjectID) interface{} {
+	hex := func(ids []primitive.ObjectID) []string {
+		list := make([]string, len(ids))
+		for i, id := range ids {
+			list[i] = id.Hex()
+		}
+		sort.Strings(list)
+		return list
+	}
+	return mock.MatchedBy(func(got []primitive.ObjectID) bool {
+		return assert.ObjectsAreEqual(hex(ids), hex(got))
+	})
+}
+
+func TestSchemaDo(t *testing.T) {
+	siteA, siteB := primitive.NewObjectID(), primitive.NewObjectID()
+	device1, device2, device3 := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	readingID := primitive.NewObjectID()
+	timestamp := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+
+	t.Run("Batched relationships", func(t *testing.T) {
+		schema, repos := newSchema(t, nil)
+		repos.sites.On("GetAll", mock.Anything, 1, 2).Return([]domain.Site{{ID: siteA, Name: "North"}, {ID: siteB, Name: "South"}}, nil).Once()
+		repos.devices.On("GetBySiteIDs", mock.Anything, sameIDs(siteA, siteB)).Return([]domain.Device{
+			{ID: device1, Name: "Inlet", SiteID: siteA},
+			{ID: device2, Name: "Outlet", SiteID: siteA},
+			{ID: device3, Name: "Basin", SiteID: siteB},
+		}, nil).Once()
+		repos.sensors.On("GetByDeviceIDs", mock.Anything, sameIDs(device1, device2, device3)).Return([]domain.Sensor{
+			{Name: "pH probe", DeviceID: device1},
+			{Name: "Thermometer", DeviceID: device3},
+		}, nil).Once()
+		repos.readings.On("GetLatestByDeviceIDs", mock.Anything, sameIDs(device1, device2, device3)).Return([]domain.WasteWaterData{
+			{ID: readingID, DeviceID: device1, Timestamp: timestamp, PH: 7.2, Quality: map[string]string{domain.ParamPH: domain.QualityRaw}},
+		}, nil).Once()
+		repos.sites.On("GetByIDs", mock.Anything, sameIDs(siteA, siteB)).Return([]domain.Site{{ID: siteA, Name: "North"}, {ID: siteB, Name: "South"}}, nil).Once()
+
+		result := schema.Do(context.Background(), graphql.Request{Query: `{
+			sites(limit: 2) {
+				name
+				devices {
+					name
+					site { name }
+					sensors { name }
+					latestReading { timestamp values(parameters: ["pH"]) { parameter value quality outOfRange } }
+				}
+			}
+		}`})
+		assert.Empty(t, result.Errors)
+		assert.JSONEq(t, `{"sites": [
+			{"name": "North", "devices": [
+				{"name": "Inlet", "site": {"name": "North"}, "sensors": [{"name": "pH probe"}],
+				 "latestReading": {"timestamp": "2024-05-01T08:00:00Z", "values": [{"parameter": "pH", "value": 7.2, "quality": "raw", "outOfRange": false}]}},
+				{"name": "Outlet", "site": {"name": "North"}, "sensors": [], "latestReading": null}
+			]},
+			{"name": "South", "devices": [
+				{"name": "Basin", "site": {"name": "South"}, "sensors": [{"name": "Thermometer"}], "latestReading": null}
+			]}
+		]}`, data(t, result.Data))
+		repos.sites.AssertExpectations(t)
+		repos.devices.AssertExpectations(t)
+		repos.sensors.AssertExpectations(t)
+		repos.readings.AssertExpectations(t)
+	})
+	t.Run("Device filter", func(t *testing.T) {
+		schema, repos := newSchema(t, nil)
+		repos.devices.On("GetAll", mock.Anything, domain.DeviceFilter{Status: domain.DeviceActive, SiteID: siteA}, 2, 5).
+			Return([]domain.Device{{ID: device1, Name: "Inlet", Location: domain.NewGeoPoint(-6.2, 106.8)}}, nil)
+
+		result := schema.Do(context.Background(), graphql.Request{
+			Query:     `query($site: ID) { devices(status: "active", siteId: $site, page: 2, limit: 5) { id location { latitude longitude } installedAt } }`,
+			Variables: map[string]interface{}{"site": siteA.Hex()},
+		})
+		assert.Empty(t, result.Errors)
+		assert.JSONEq(t, `{"devices": [{"id": "`+device1.Hex()+`", "location": {"latitude": -6.2, "longitude": 106.8}, "installedAt": null}]}`, data(t, result.Data))
+	})
+	t.Run("Unknown status", func(t *testing.T) {
+		schema, _ := newSchema(t, nil)
+		result := schema.Do(context.Background(), graphql.Request{Query: `{ devices(status: "lost") { id } }`})
+		assert.Len(t, result.Errors, 1)
+	})
+	t.Run("Reading with its device", func(t *testing.T) {
+		schema, repos := newSchema(t, nil)
+		repos.readings.On("GetByID", mock.Anything, readingID.Hex()).Return(&domain.WasteWaterData{
+			ID:         readingID,
+			DeviceID:   device1,
+			PH:         9.5,
+			Parameters: map[string]float64{"Phosphate": 0.4},
+			OutOfRange: []string{domain.ParamPH},
+		}, nil)
+		repos.devices.On("GetByIDs", mock.Anything, []primitive.ObjectID{device1}).Return([]domain.Device{{ID: device1, Name: "Inlet"}}, nil).Once()
+
+		result := schema.Do(context.Background(), graphql.Request{Query: `{ reading(id: "` + readingID.Hex() + `") {
+			device { name }
+			values(parameters: ["pH", "Phosphate", "Unknown"]) { parameter value outOfRange }
+		} }`})
+		assert.Empty(t, result.Errors)
+		assert.JSONEq(t, `{"reading": {"device": {"name": "Inlet"}, "values": [
+			{"parameter": "pH", "value": 9.5, "outOfRange": true},
+			{"parameter": "Phosphate", "value": 0.4, "outOfRange": false}
+		]}}`, data(t, result.Data))
+		repos.devices.AssertExpectations(t)
+	})
+	t.Run("Not found", func(t *testing.T) {
+		schema, repos := newSchema(t, nil)
+		repos.devices.On("GetByIDs", mock.Anything, []primitive.ObjectID{device1}).Return(nil, nil)
+
+		result := schema.Do(context.Background(), graphql.Request{Query: `{ device(id: "` + device1.Hex() + `") { name } }`})
+		assert.Empty(t, result.Errors)
+		assert.JSONEq(t, `{"device": null}`, data(t, result.Data))
+	})
+	t.Run("Invalid ID", func(t *testing.T) {
+		schema, _ := newSchema(t, nil)
+		result := schema.Do(context.Background(), graphql.Request{Query: `{ sensor(id: "invalid") { name } }`})
+		assert.Len(t, result.Errors, 1)
+	})
+	t.Run("Loader error", func(t *testing.T) {
+		schema, repos := newSchema(t, nil)
+		repos.sensors.On("GetAll", mock.Anything, 1, 10).Return([]domain.Sensor{{Name: "pH probe", DeviceID: device1}, {Name: "Thermometer", DeviceID: device2}}, nil)
+		repos.devices.On("GetByIDs", mock.Anything, sameIDs(device1, device2)).Return(nil, errors.New("database error")).Once()
+
+		result := schema.Do(context.Background(), graphql.Request{Query: `{ sensors { name device { name } } }`})
+		assert.Len(t, result.Errors, 2)
+		assert.Equal(t, "database error", result.Errors[0].Message)
+		repos.devices.AssertExpectations(t)
+	})
+}
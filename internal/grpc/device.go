@@ -0,0 +1,235 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/grpc/pb"
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// DeviceService is the interface that wraps the device methods shared with the REST API.
+type DeviceService interface {
+	Create(ctx context.Context, w *domain.DeviceRequest) error
+	GetAll(ctx context.Context, filter domain.DeviceFilter, page int, limit int) ([]domain.Device, error)
+	GetByID(ctx context.Context, id string) (*domain.Device, error)
+	Update(ctx context.Context, w *domain.Device) error
+	Delete(ctx context.Context, id string) error
+}
+
+// DeviceHandler is the handler for DeviceService
+type DeviceHandler struct {
+	pb.UnimplementedDeviceServiceServer
+	service DeviceService
+}
+
+// NewDeviceHandler registers the mrt.v1.DeviceService on the server.
+//
+// Parameters:
+// - registrar: The server the service is registered on.
+// - service: The DeviceService instance.
+//
+// Return type: None.
+func NewDeviceHandler(registrar gogrpc.ServiceRegistrar, service DeviceService) {
+	pb.RegisterDeviceServiceServer(registrar, &DeviceHandler{service: service})
+}
+
+// GetDevice retrieves a device by ID.
+//
+// ctx: the context for the operation.
+// req: the ID of the device.
+//
+// Returns the device, NotFound when it does not exist.
+func (h *DeviceHandler) GetDevice(ctx context.Context, req *pb.GetDeviceRequest) (*pb.Device, error) {
+	id, err := requiredID("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	device, err := h.service.GetByID(ctx, id.Hex())
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	if device == nil {
+		return nil, errNotFound
+	}
+	return devicePB(device), nil
+}
+
+// ListDevices retrieves a page of the devices matching the inventory filter.
+//
+// ctx: the context for the operation.
+// req: the page and the filter.
+//
+// Returns the devices of the page.
+func (h *DeviceHandler) ListDevices(ctx context.Context, req *pb.ListDevicesRequest) (*pb.ListDevicesResponse, error) {
+	filter := domain.DeviceFilter{Status: req.GetStatus(), Model: req.GetModel()}
+	if filter.Status != "" && !domain.IsDeviceStatus(filter.Status) {
+		return nil, status.Error(codes.InvalidArgument, "unknown status "+filter.Status)
+	}
+	var err error
+	if filter.SiteID, err = objectID("site_id", req.GetSiteId()); err != nil {
+		return nil, err
+	}
+	page, limit := pagination(req.GetPage(), req.GetLimit())
+	devices, err := h.service.GetAll(ctx, filter, page, limit)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	resp := &pb.ListDevicesResponse{Devices: make([]*pb.Device, 0, len(devices))}
+	for i := range devices {
+		resp.Devices = append(resp.Devices, devicePB(&devices[i]))
+	}
+	return resp, nil
+}
+
+// CreateDevice creates a device.
+//
+// ctx: the context for the operation.
+// req: the device, its status defaults to procured.
+//
+// Returns the created device with its ID.
+func (h *DeviceHandler) CreateDevice(ctx context.Context, req *pb.Device) (*pb.Device, error) {
+	siteID, err := objectID("site_id", req.GetSiteId())
+	if err != nil {
+		return nil, err
+	}
+	w := &domain.DeviceRequest{
+		Name:             req.GetName(),
+		Description:      req.GetDescription(),
+		SiteID:           siteID,
+		Location:         geoPoint(req.GetLocation()),
+		Status:           req.GetStatus(),
+		SerialNumber:     req.GetSerialNumber(),
+		Model:            req.GetModel(),
+		Firmware:         req.GetFirmware(),
+		HardwareRevision: req.GetHardwareRevision(),
+		DevEUI:           req.GetDevEui(),
+		InstalledAt:      timeOf(req.GetInstalledAt()),
+	}
+	if err = h.service.Create(ctx, w); err != nil {
+		return nil, statusFromError(err)
+	}
+	return devicePB(&domain.Device{
+		ID:               w.ID,
+		Name:             w.Name,
+		Description:      w.Description,
+		SiteID:           w.SiteID,
+		Location:         w.Location,
+		Status:           w.Status,
+		SerialNumber:     w.SerialNumber,
+		Model:            w.Model,
+		Firmware:         w.Firmware,
+		HardwareRevision: w.HardwareRevision,
+		DevEUI:           w.DevEUI,
+		InstalledAt:      w.InstalledAt,
+		CreatedAt:        w.CreatedAt,
+		UpdatedAt:        w.UpdatedAt,
+	}), nil
+}
+
+// UpdateDevice updates a device.
+//
+// ctx: the context for the operation.
+// req: the device with its ID.
+//
+// Returns the updated device.
+func (h *DeviceHandler) UpdateDevice(ctx context.Context, req *pb.Device) (*pb.Device, error) {
+	id, err := requiredID("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	siteID, err := objectID("site_id", req.GetSiteId())
+	if err != nil {
+		return nil, err
+	}
+	w := &domain.Device{
+		ID:               id,
+		Name:             req.GetName(),
+		Description:      req.GetDescription(),
+		SiteID:           siteID,
+		Location:         geoPoint(req.GetLocation()),
+		Status:           req.GetStatus(),
+		SerialNumber:     req.GetSerialNumber(),
+		Model:            req.GetModel(),
+		Firmware:         req.GetFirmware(),
+		HardwareRevision: req.GetHardwareRevision(),
+		DevEUI:           req.GetDevEui(),
+		UpdatedAt:        domain.MyTime{Time: time.Now()},
+	}
+	if err = h.service.Update(ctx, w); err != nil {
+		return nil, statusFromError(err)
+	}
+	return devicePB(w), nil
+}
+
+// DeleteDevice deletes a device.
+//
+// ctx: the context for the operation.
+// req: the ID of the device.
+//
+// Returns an error if the device could not be deleted.
+func (h *DeviceHandler) DeleteDevice(ctx context.Context, req *pb.DeleteDeviceRequest) (*emptypb.Empty, error) {
+	id, err := requiredID("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if err = h.service.Delete(ctx, id.Hex()); err != nil {
+		return nil, statusFromError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// devicePB converts a device to its message
+func devicePB(d *domain.Device) *pb.Device {
+	device := &pb.Device{
+		Id:               d.ID.Hex(),
+		Name:             d.Name,
+		Description:      d.Description,
+		Status:           d.Status,
+		SerialNumber:     d.SerialNumber,
+		Model:            d.Model,
+		Firmware:         d.Firmware,
+		HardwareRevision: d.HardwareRevision,
+		DevEui:           d.DevEUI,
+		InstalledAt:      timestamp(d.InstalledAt),
+		DecommissionedAt: timestamp(d.DecommissionedAt),
+		CreatedAt:        timestamp(d.CreatedAt.Time),
+		UpdatedAt:        timestamp(d.UpdatedAt.Time),
+	}
+	if !d.SiteID.IsZero() {
+		device.SiteId = d.SiteID.Hex()
+	}
+	if d.Location != nil && len(d.Location.Coordinates) == 2 {
+		device.Location = &pb.Location{Latitude: d.Location.Coordinates[1], Longitude: d.Location.Coordinates[0]}
+	}
+	return device
+}
+
+// geoPoint converts a location to a GeoJSON point, nil without location
+func geoPoint(l *pb.Location) *domain.GeoPoint {
+	if l == nil {
+		return nil
+	}
+	return domain.NewGeoPoint(l.GetLatitude(), l.GetLongitude())
+}
+
+// timestamp converts a time to its message, nil for the zero time
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// timeOf converts a timestamp message to a time, the zero time when unset
+func timeOf(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
@@ -0,0 +1,145 @@
+package grpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/grpc"
+	"github.com/anggi-susanto/mrt-go/internal/grpc/mocks"
+	"github.com/anggi-susanto/mrt-go/internal/grpc/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func deviceClient(t *testing.T, service grpc.DeviceService) pb.DeviceServiceClient {
+	return pb.NewDeviceServiceClient(dial(t, func(server *grpc.Server) { grpc.NewDeviceHandler(server, service) }))
+}
+
+func TestDeviceHandlerGetDevice(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(mocks.DeviceService)
+		mockService.On("GetByID", mock.Anything, deviceID.Hex()).Return(&domain.Device{
+			ID:       deviceID,
+			Name:     "Inlet",
+			Status:   domain.DeviceActive,
+			Location: &domain.GeoPoint{Type: "Point", Coordinates: []float64{106.8, -6.2}},
+		}, nil)
+
+		device, err := deviceClient(t, mockService).GetDevice(context.Background(), &pb.GetDeviceRequest{Id: deviceID.Hex()})
+		assert.NoError(t, err)
+		assert.Equal(t, deviceID.Hex(), device.Id)
+		assert.Equal(t, "Inlet", device.Name)
+		assert.Equal(t, domain.DeviceActive, device.Status)
+		assert.Equal(t, -6.2, device.Location.Latitude)
+		assert.Equal(t, 106.8, device.Location.Longitude)
+		assert.Nil(t, device.InstalledAt)
+		mockService.AssertExpectations(t)
+	})
+	t.Run("Not found", func(t *testing.T) {
+		mockService := new(mocks.DeviceService)
+		mockService.On("GetByID", mock.Anything, deviceID.Hex()).Return(nil, nil)
+
+		_, err := deviceClient(t, mockService).GetDevice(context.Background(), &pb.GetDeviceRequest{Id: deviceID.Hex()})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+	t.Run("Invalid ID", func(t *testing.T) {
+		_, err := deviceClient(t, new(mocks.DeviceService)).GetDevice(context.Background(), &pb.GetDeviceRequest{Id: "invalid"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestDeviceHandlerListDevices(t *testing.T) {
+	siteID := primitive.NewObjectID()
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(mocks.DeviceService)
+		mockService.On("GetAll", mock.Anything, domain.DeviceFilter{Status: domain.DeviceActive, SiteID: siteID}, 2, 10).
+			Return([]domain.Device{{ID: primitive.NewObjectID()}, {ID: primitive.NewObjectID()}}, nil)
+
+		resp, err := deviceClient(t, mockService).ListDevices(context.Background(), &pb.ListDevicesRequest{Page: 2, Status: domain.DeviceActive, SiteId: siteID.Hex()})
+		assert.NoError(t, err)
+		assert.Len(t, resp.Devices, 2)
+		mockService.AssertExpectations(t)
+	})
+	t.Run("Unknown status", func(t *testing.T) {
+		_, err := deviceClient(t, new(mocks.DeviceService)).ListDevices(context.Background(), &pb.ListDevicesRequest{Status: "lost"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+	t.Run("Error", func(t *testing.T) {
+		mockService := new(mocks.DeviceService)
+		mockService.On("GetAll", mock.Anything, domain.DeviceFilter{}, 1, 10).Return(nil, errors.New("database error"))
+
+		_, err := deviceClient(t, mockService).ListDevices(context.Background(), &pb.ListDevicesRequest{})
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+}
+
+func TestDeviceHandlerCreateDevice(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		deviceID := primitive.NewObjectID()
+		mockService := new(mocks.DeviceService)
+		mockService.On("Create", mock.Anything, mock.MatchedBy(func(w *domain.DeviceRequest) bool {
+			return w.Name == "Outlet" && w.Location.Coordinates[0] == 106.8 && w.Location.Coordinates[1] == -6.2
+		})).Run(func(args mock.Arguments) {
+			w := args.Get(1).(*domain.DeviceRequest)
+			w.ID, w.Status = deviceID, domain.DeviceProcured
+		}).Return(nil)
+
+		device, err := deviceClient(t, mockService).CreateDevice(context.Background(), &pb.Device{
+			Name:     "Outlet",
+			Location: &pb.Location{Latitude: -6.2, Longitude: 106.8},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, deviceID.Hex(), device.Id)
+		assert.Equal(t, domain.DeviceProcured, device.Status)
+		mockService.AssertExpectations(t)
+	})
+	t.Run("Invalid device", func(t *testing.T) {
+		mockService := new(mocks.DeviceService)
+		mockService.On("Create", mock.Anything, mock.Anything).Return(domain.ErrInvalidGeometry)
+
+		_, err := deviceClient(t, mockService).CreateDevice(context.Background(), &pb.Device{Name: "Outlet"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestDeviceHandlerUpdateDevice(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(mocks.DeviceService)
+		mockService.On("Update", mock.Anything, mock.MatchedBy(func(d *domain.Device) bool {
+			return d.ID == deviceID && d.Name == "Outlet"
+		})).Return(nil)
+
+		device, err := deviceClient(t, mockService).UpdateDevice(context.Background(), &pb.Device{Id: deviceID.Hex(), Name: "Outlet"})
+		assert.NoError(t, err)
+		assert.Equal(t, deviceID.Hex(), device.Id)
+		mockService.AssertExpectations(t)
+	})
+	t.Run("Missing ID", func(t *testing.T) {
+		_, err := deviceClient(t, new(mocks.DeviceService)).UpdateDevice(context.Background(), &pb.Device{Name: "Outlet"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+	t.Run("Invalid transition", func(t *testing.T) {
+		mockService := new(mocks.DeviceService)
+		mockService.On("Update", mock.Anything, mock.Anything).Return(domain.ErrDeviceTransition)
+
+		_, err := deviceClient(t, mockService).UpdateDevice(context.Background(), &pb.Device{Id: deviceID.Hex(), Status: domain.DeviceProcured})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+}
+
+func TestDeviceHandlerDeleteDevice(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	mockService := new(mocks.DeviceService)
+	mockService.On("Delete", mock.Anything, deviceID.Hex()).Return(nil)
+
+	_, err := deviceClient(t, mockService).DeleteDevice(context.Background(), &pb.DeleteDeviceRequest{Id: deviceID.Hex()})
+	assert.NoError(t, err)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,67 @@
+package grpc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errNotFound answers a missing resource
+var errNotFound = status.Error(codes.NotFound, "Data not found")
+
+// statusFromError maps domain validation errors to InvalidArgument, FailedPrecondition or
+// PermissionDenied and anything else to Internal, as statusFromError of the REST API.
+func statusFromError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	code := codes.Internal
+	switch {
+	case errors.Is(err, domain.ErrInvalidGeometry), errors.Is(err, domain.ErrInvalidSensorType),
+		errors.Is(err, domain.ErrInvalidMeasurement), errors.Is(err, domain.ErrUnknownUnit),
+		errors.Is(err, domain.ErrInvalidDevice), errors.Is(err, domain.ErrInvalidModbus),
+		errors.Is(err, domain.ErrInvalidLoRaWAN), errors.Is(err, domain.ErrOutOfRange):
+		code = codes.InvalidArgument
+	case errors.Is(err, domain.ErrDeviceTransition), errors.Is(err, domain.ErrDeviceDecommissioned),
+		errors.Is(err, domain.ErrReviewedMeasurement):
+		code = codes.FailedPrecondition
+	case errors.Is(err, domain.ErrClaimRefused):
+		code = codes.PermissionDenied
+	}
+	return status.Error(code, err.Error())
+}
+
+// objectID parses the hex ObjectID of a request field, an empty ID is the zero ObjectID.
+func objectID(field, id string) (primitive.ObjectID, error) {
+	if id == "" {
+		return primitive.NilObjectID, nil
+	}
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, status.Error(codes.InvalidArgument, fmt.Sprintf("invalid %s: %s", field, err))
+	}
+	return objectID, nil
+}
+
+// requiredID parses the hex ObjectID of a required request field.
+func requiredID(field, id string) (primitive.ObjectID, error) {
+	if id == "" {
+		return primitive.NilObjectID, status.Error(codes.InvalidArgument, field+" is required")
+	}
+	return objectID(field, id)
+}
+
+// pagination returns the page and limit of a list request, 1 and 10 when unset
+func pagination(page, limit int32) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	return int(page), int(limit)
+}
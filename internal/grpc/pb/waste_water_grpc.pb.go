@@ -0,0 +1,280 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: mrt/v1/waste_water.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	WasteWaterService_GetReading_FullMethodName         = "/mrt.v1.WasteWaterService/GetReading"
+	WasteWaterService_ListReadings_FullMethodName       = "/mrt.v1.WasteWaterService/ListReadings"
+	WasteWaterService_CreateReading_FullMethodName      = "/mrt.v1.WasteWaterService/CreateReading"
+	WasteWaterService_StreamMeasurements_FullMethodName = "/mrt.v1.WasteWaterService/StreamMeasurements"
+	WasteWaterService_IngestReadings_FullMethodName     = "/mrt.v1.WasteWaterService/IngestReadings"
+)
+
+// WasteWaterServiceClient is the client API for WasteWaterService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// WasteWaterService exposes the readings of the devices, as /waste-water and /measurement of the REST API.
+type WasteWaterServiceClient interface {
+	GetReading(ctx context.Context, in *GetReadingRequest, opts ...grpc.CallOption) (*Reading, error)
+	ListReadings(ctx context.Context, in *ListReadingsRequest, opts ...grpc.CallOption) (*ListReadingsResponse, error)
+	CreateReading(ctx context.Context, in *Reading, opts ...grpc.CallOption) (*ReadingResult, error)
+	// StreamMeasurements streams the measurements matching the filter, newest first.
+	StreamMeasurements(ctx context.Context, in *StreamMeasurementsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Measurement], error)
+	// IngestReadings stores the readings sent on the stream and answers the outcome of each once
+	// the stream is closed; readings whose dedup key was already stored are reported as duplicate.
+	IngestReadings(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[Reading, IngestReadingsResponse], error)
+}
+
+type wasteWaterServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWasteWaterServiceClient(cc grpc.ClientConnInterface) WasteWaterServiceClient {
+	return &wasteWaterServiceClient{cc}
+}
+
+func (c *wasteWaterServiceClient) GetReading(ctx context.Context, in *GetReadingRequest, opts ...grpc.CallOption) (*Reading, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Reading)
+	err := c.cc.Invoke(ctx, WasteWaterService_GetReading_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *wasteWaterServiceClient) ListReadings(ctx context.Context, in *ListReadingsRequest, opts ...grpc.CallOption) (*ListReadingsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListReadingsResponse)
+	err := c.cc.Invoke(ctx, WasteWaterService_ListReadings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *wasteWaterServiceClient) CreateReading(ctx context.Context, in *Reading, opts ...grpc.CallOption) (*ReadingResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReadingResult)
+	err := c.cc.Invoke(ctx, WasteWaterService_CreateReading_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *wasteWaterServiceClient) StreamMeasurements(ctx context.Context, in *StreamMeasurementsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Measurement], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &WasteWaterService_ServiceDesc.Streams[0], WasteWaterService_StreamMeasurements_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamMeasurementsRequest, Measurement]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WasteWaterService_StreamMeasurementsClient = grpc.ServerStreamingClient[Measurement]
+
+func (c *wasteWaterServiceClient) IngestReadings(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[Reading, IngestReadingsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &WasteWaterService_ServiceDesc.Streams[1], WasteWaterService_IngestReadings_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[Reading, IngestReadingsResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WasteWaterService_IngestReadingsClient = grpc.ClientStreamingClient[Reading, IngestReadingsResponse]
+
+// WasteWaterServiceServer is the server API for WasteWaterService service.
+// All implementations must embed UnimplementedWasteWaterServiceServer
+// for forward compatibility.
+//
+// WasteWaterService exposes the readings of the devices, as /waste-water and /measurement of the REST API.
+type WasteWaterServiceServer interface {
+	GetReading(context.Context, *GetReadingRequest) (*Reading, error)
+	ListReadings(context.Context, *ListReadingsRequest) (*ListReadingsResponse, error)
+	CreateReading(context.Context, *Reading) (*ReadingResult, error)
+	// StreamMeasurements streams the measurements matching the filter, newest first.
+	StreamMeasurements(*StreamMeasurementsRequest, grpc.ServerStreamingServer[Measurement]) error
+	// IngestReadings stores the readings sent on the stream and answers the outcome of each once
+	// the stream is closed; readings whose dedup key was already stored are reported as duplicate.
+	IngestReadings(grpc.ClientStreamingServer[Reading, IngestReadingsResponse]) error
+	mustEmbedUnimplementedWasteWaterServiceServer()
+}
+
+// UnimplementedWasteWaterServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedWasteWaterServiceServer struct{}
+
+func (UnimplementedWasteWaterServiceServer) GetReading(context.Context, *GetReadingRequest) (*Reading, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReading not implemented")
+}
+func (UnimplementedWasteWaterServiceServer) ListReadings(context.Context, *ListReadingsRequest) (*ListReadingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReadings not implemented")
+}
+func (UnimplementedWasteWaterServiceServer) CreateReading(context.Context, *Reading) (*ReadingResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateReading not implemented")
+}
+func (UnimplementedWasteWaterServiceServer) StreamMeasurements(*StreamMeasurementsRequest, grpc.ServerStreamingServer[Measurement]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamMeasurements not implemented")
+}
+func (UnimplementedWasteWaterServiceServer) IngestReadings(grpc.ClientStreamingServer[Reading, IngestReadingsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method IngestReadings not implemented")
+}
+func (UnimplementedWasteWaterServiceServer) mustEmbedUnimplementedWasteWaterServiceServer() {}
+func (UnimplementedWasteWaterServiceServer) testEmbeddedByValue()                           {}
+
+// UnsafeWasteWaterServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to WasteWaterServiceServer will
+// result in compilation errors.
+type UnsafeWasteWaterServiceServer interface {
+	mustEmbedUnimplementedWasteWaterServiceServer()
+}
+
+func RegisterWasteWaterServiceServer(s grpc.ServiceRegistrar, srv WasteWaterServiceServer) {
+	// If the following call pancis, it indicates UnimplementedWasteWaterServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&WasteWaterService_ServiceDesc, srv)
+}
+
+func _WasteWaterService_GetReading_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReadingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WasteWaterServiceServer).GetReading(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WasteWaterService_GetReading_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WasteWaterServiceServer).GetReading(ctx, req.(*GetReadingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WasteWaterService_ListReadings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReadingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WasteWaterServiceServer).ListReadings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WasteWaterService_ListReadings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WasteWaterServiceServer).ListReadings(ctx, req.(*ListReadingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WasteWaterService_CreateReading_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Reading)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WasteWaterServiceServer).CreateReading(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WasteWaterService_CreateReading_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WasteWaterServiceServer).CreateReading(ctx, req.(*Reading))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WasteWaterService_StreamMeasurements_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamMeasurementsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WasteWaterServiceServer).StreamMeasurements(m, &grpc.GenericServerStream[StreamMeasurementsRequest, Measurement]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WasteWaterService_StreamMeasurementsServer = grpc.ServerStreamingServer[Measurement]
+
+func _WasteWaterService_IngestReadings_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(WasteWaterServiceServer).IngestReadings(&grpc.GenericServerStream[Reading, IngestReadingsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WasteWaterService_IngestReadingsServer = grpc.ClientStreamingServer[Reading, IngestReadingsResponse]
+
+// WasteWaterService_ServiceDesc is the grpc.ServiceDesc for WasteWaterService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var WasteWaterService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mrt.v1.WasteWaterService",
+	HandlerType: (*WasteWaterServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetReading",
+			Handler:    _WasteWaterService_GetReading_Handler,
+		},
+		{
+			MethodName: "ListReadings",
+			Handler:    _WasteWaterService_ListReadings_Handler,
+		},
+		{
+			MethodName: "CreateReading",
+			Handler:    _WasteWaterService_CreateReading_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamMeasurements",
+			Handler:       _WasteWaterService_StreamMeasurements_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "IngestReadings",
+			Handler:       _WasteWaterService_IngestReadings_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "mrt/v1/waste_water.proto",
+}
@@ -0,0 +1,175 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/grpc/pb"
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// SensorService is the interface that wraps the sensor methods shared with the REST API.
+type SensorService interface {
+	Create(ctx context.Context, w *domain.SensorRequest) error
+	GetAll(ctx context.Context, page int, limit int) ([]domain.Sensor, error)
+	GetByID(ctx context.Context, id string) (*domain.Sensor, error)
+	Update(ctx context.Context, w *domain.Sensor) error
+	Delete(ctx context.Context, id string) error
+}
+
+// SensorHandler is the handler for SensorService
+type SensorHandler struct {
+	pb.UnimplementedSensorServiceServer
+	service SensorService
+}
+
+// NewSensorHandler registers the mrt.v1.SensorService on the server.
+//
+// Parameters:
+// - registrar: The server the service is registered on.
+// - service: The SensorService instance.
+//
+// Return type: None.
+func NewSensorHandler(registrar gogrpc.ServiceRegistrar, service SensorService) {
+	pb.RegisterSensorServiceServer(registrar, &SensorHandler{service: service})
+}
+
+// GetSensor retrieves a sensor by ID.
+//
+// ctx: the context for the operation.
+// req: the ID of the sensor.
+//
+// Returns the sensor, NotFound when it does not exist.
+func (h *SensorHandler) GetSensor(ctx context.Context, req *pb.GetSensorRequest) (*pb.Sensor, error) {
+	id, err := requiredID("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	sensor, err := h.service.GetByID(ctx, id.Hex())
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	if sensor == nil {
+		return nil, errNotFound
+	}
+	return sensorPB(sensor), nil
+}
+
+// ListSensors retrieves a page of the sensors.
+//
+// ctx: the context for the operation.
+// req: the page.
+//
+// Returns the sensors of the page.
+func (h *SensorHandler) ListSensors(ctx context.Context, req *pb.ListSensorsRequest) (*pb.ListSensorsResponse, error) {
+	page, limit := pagination(req.GetPage(), req.GetLimit())
+	sensors, err := h.service.GetAll(ctx, page, limit)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	resp := &pb.ListSensorsResponse{Sensors: make([]*pb.Sensor, 0, len(sensors))}
+	for i := range sensors {
+		resp.Sensors = append(resp.Sensors, sensorPB(&sensors[i]))
+	}
+	return resp, nil
+}
+
+// CreateSensor creates a sensor of a device.
+//
+// ctx: the context for the operation.
+// req: the sensor.
+//
+// Returns the created sensor with its ID.
+func (h *SensorHandler) CreateSensor(ctx context.Context, req *pb.Sensor) (*pb.Sensor, error) {
+	sensor, err := sensorOf(req)
+	if err != nil {
+		return nil, err
+	}
+	now := domain.MyTime{Time: time.Now()}
+	w := &domain.SensorRequest{
+		Name:        sensor.Name,
+		Description: sensor.Description,
+		DeviceID:    sensor.DeviceID,
+		TypeID:      sensor.TypeID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err = h.service.Create(ctx, w); err != nil {
+		return nil, statusFromError(err)
+	}
+	sensor.ID, sensor.CreatedAt, sensor.UpdatedAt = w.ID, w.CreatedAt, w.UpdatedAt
+	return sensorPB(sensor), nil
+}
+
+// UpdateSensor updates a sensor.
+//
+// ctx: the context for the operation.
+// req: the sensor with its ID.
+//
+// Returns the updated sensor.
+func (h *SensorHandler) UpdateSensor(ctx context.Context, req *pb.Sensor) (*pb.Sensor, error) {
+	if _, err := requiredID("id", req.GetId()); err != nil {
+		return nil, err
+	}
+	sensor, err := sensorOf(req)
+	if err != nil {
+		return nil, err
+	}
+	sensor.UpdatedAt = domain.MyTime{Time: time.Now()}
+	if err = h.service.Update(ctx, sensor); err != nil {
+		return nil, statusFromError(err)
+	}
+	return sensorPB(sensor), nil
+}
+
+// DeleteSensor deletes a sensor.
+//
+// ctx: the context for the operation.
+// req: the ID of the sensor.
+//
+// Returns an error if the sensor could not be deleted.
+func (h *SensorHandler) DeleteSensor(ctx context.Context, req *pb.DeleteSensorRequest) (*emptypb.Empty, error) {
+	id, err := requiredID("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if err = h.service.Delete(ctx, id.Hex()); err != nil {
+		return nil, statusFromError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// sensorOf converts a sensor message, the device is required
+func sensorOf(req *pb.Sensor) (*domain.Sensor, error) {
+	id, err := objectID("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	deviceID, err := requiredID("device_id", req.GetDeviceId())
+	if err != nil {
+		return nil, err
+	}
+	typeID, err := objectID("sensor_type_id", req.GetSensorTypeId())
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Sensor{ID: id, Name: req.GetName(), Description: req.GetDescription(), DeviceID: deviceID, TypeID: typeID}, nil
+}
+
+// sensorPB converts a sensor to its message
+func sensorPB(s *domain.Sensor) *pb.Sensor {
+	sensor := &pb.Sensor{
+		Id:          s.ID.Hex(),
+		Name:        s.Name,
+		Description: s.Description,
+		DeviceId:    s.DeviceID.Hex(),
+		CreatedAt:   timestamp(s.CreatedAt.Time),
+		UpdatedAt:   timestamp(s.UpdatedAt.Time),
+	}
+	if !s.TypeID.IsZero() {
+		sensor.SensorTypeId = s.TypeID.Hex()
+	}
+	return sensor
+}
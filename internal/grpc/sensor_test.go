@@ -0,0 +1,107 @@
+package grpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/grpc"
+	"github.com/anggi-susanto/mrt-go/internal/grpc/mocks"
+	"github.com/anggi-susanto/mrt-go/internal/grpc/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func sensorClient(t *testing.T, service grpc.SensorService) pb.SensorServiceClient {
+	return pb.NewSensorServiceClient(dial(t, func(server *grpc.Server) { grpc.NewSensorHandler(server, service) }))
+}
+
+func TestSensorHandlerGetSensor(t *testing.T) {
+	sensorID := primitive.NewObjectID()
+	deviceID := primitive.NewObjectID()
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(mocks.SensorService)
+		mockService.On("GetByID", mock.Anything, sensorID.Hex()).Return(&domain.Sensor{ID: sensorID, Name: "pH probe", DeviceID: deviceID}, nil)
+
+		sensor, err := sensorClient(t, mockService).GetSensor(context.Background(), &pb.GetSensorRequest{Id: sensorID.Hex()})
+		assert.NoError(t, err)
+		assert.Equal(t, sensorID.Hex(), sensor.Id)
+		assert.Equal(t, deviceID.Hex(), sensor.DeviceId)
+		assert.Empty(t, sensor.SensorTypeId)
+	})
+	t.Run("Not found", func(t *testing.T) {
+		mockService := new(mocks.SensorService)
+		mockService.On("GetByID", mock.Anything, sensorID.Hex()).Return(nil, nil)
+
+		_, err := sensorClient(t, mockService).GetSensor(context.Background(), &pb.GetSensorRequest{Id: sensorID.Hex()})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestSensorHandlerListSensors(t *testing.T) {
+	mockService := new(mocks.SensorService)
+	mockService.On("GetAll", mock.Anything, 1, 10).Return([]domain.Sensor{{ID: primitive.NewObjectID()}}, nil)
+
+	resp, err := sensorClient(t, mockService).ListSensors(context.Background(), &pb.ListSensorsRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Sensors, 1)
+	mockService.AssertExpectations(t)
+}
+
+func TestSensorHandlerCreateSensor(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	typeID := primitive.NewObjectID()
+	t.Run("Success", func(t *testing.T) {
+		sensorID := primitive.NewObjectID()
+		mockService := new(mocks.SensorService)
+		mockService.On("Create", mock.Anything, mock.MatchedBy(func(w *domain.SensorRequest) bool {
+			return w.Name == "pH probe" && w.DeviceID == deviceID && w.TypeID == typeID
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.SensorRequest).ID = sensorID
+		}).Return(nil)
+
+		sensor, err := sensorClient(t, mockService).CreateSensor(context.Background(), &pb.Sensor{Name: "pH probe", DeviceId: deviceID.Hex(), SensorTypeId: typeID.Hex()})
+		assert.NoError(t, err)
+		assert.Equal(t, sensorID.Hex(), sensor.Id)
+		assert.NotNil(t, sensor.CreatedAt)
+		mockService.AssertExpectations(t)
+	})
+	t.Run("Missing device", func(t *testing.T) {
+		_, err := sensorClient(t, new(mocks.SensorService)).CreateSensor(context.Background(), &pb.Sensor{Name: "pH probe"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+	t.Run("Invalid sensor type", func(t *testing.T) {
+		mockService := new(mocks.SensorService)
+		mockService.On("Create", mock.Anything, mock.Anything).Return(domain.ErrInvalidSensorType)
+
+		_, err := sensorClient(t, mockService).CreateSensor(context.Background(), &pb.Sensor{Name: "pH probe", DeviceId: deviceID.Hex()})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestSensorHandlerUpdateSensor(t *testing.T) {
+	sensorID := primitive.NewObjectID()
+	deviceID := primitive.NewObjectID()
+	mockService := new(mocks.SensorService)
+	mockService.On("Update", mock.Anything, mock.MatchedBy(func(s *domain.Sensor) bool {
+		return s.ID == sensorID && s.DeviceID == deviceID
+	})).Return(nil)
+
+	sensor, err := sensorClient(t, mockService).UpdateSensor(context.Background(), &pb.Sensor{Id: sensorID.Hex(), DeviceId: deviceID.Hex()})
+	assert.NoError(t, err)
+	assert.Equal(t, sensorID.Hex(), sensor.Id)
+	mockService.AssertExpectations(t)
+}
+
+func TestSensorHandlerDeleteSensor(t *testing.T) {
+	sensorID := primitive.NewObjectID()
+	mockService := new(mocks.SensorService)
+	mockService.On("Delete", mock.Anything, sensorID.Hex()).Return(nil)
+
+	_, err := sensorClient(t, mockService).DeleteSensor(context.Background(), &pb.DeleteSensorRequest{Id: sensorID.Hex()})
+	assert.NoError(t, err)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,107 @@
+// Package grpc serves the device, sensor and waste water services over gRPC for the internal
+// data platform, next to the REST API and on its own port. The protobuf definitions are in
+// proto/mrt/v1 and the generated code in the pb package.
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/internal/logging"
+	"github.com/sirupsen/logrus"
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// log is the logger of the package, its level is configured under "grpc"
+var log = logging.Logger("grpc")
+
+// Server is the gRPC endpoint shared by the handlers, it is the registrar they register on.
+type Server struct {
+	config config.GRPCConfig
+	server *gogrpc.Server
+}
+
+// NewServer creates a new Server, Start must be called once the handlers are registered.
+//
+// Parameters:
+// - config: the address to listen on.
+// Returns a pointer to a Server.
+func NewServer(config config.GRPCConfig) *Server {
+	return &Server{
+		config: config,
+		server: gogrpc.NewServer(
+			gogrpc.ChainUnaryInterceptor(logUnary),
+			gogrpc.ChainStreamInterceptor(logStream),
+		),
+	}
+}
+
+// RegisterService registers a service implementation, it makes Server a gogrpc.ServiceRegistrar.
+func (s *Server) RegisterService(desc *gogrpc.ServiceDesc, impl any) {
+	s.server.RegisterService(desc, impl)
+}
+
+// Start listens on the configured address and serves in the background, the services are
+// listed by server reflection for tools such as grpcurl.
+//
+// Returns an error if the address cannot be listened on.
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.config.Address)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	return s.Serve(listener)
+}
+
+// Serve serves on a listener in the background.
+//
+// Returns nil, serving errors are logged.
+func (s *Server) Serve(listener net.Listener) error {
+	reflection.Register(s.server)
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, gogrpc.ErrServerStopped) {
+			log.Error(err)
+		}
+	}()
+	return nil
+}
+
+// Close stops accepting calls and waits for the running ones to finish.
+func (s *Server) Close() {
+	s.server.GracefulStop()
+}
+
+// logUnary logs the outcome of a call like the request logger of the REST API.
+func logUnary(ctx context.Context, req any, info *gogrpc.UnaryServerInfo, handler gogrpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	logCall(ctx, info.FullMethod, start, err)
+	return resp, err
+}
+
+// logStream logs the outcome of a streaming call once the stream ends.
+func logStream(srv any, stream gogrpc.ServerStream, info *gogrpc.StreamServerInfo, handler gogrpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, stream)
+	logCall(stream.Context(), info.FullMethod, start, err)
+	return err
+}
+
+func logCall(ctx context.Context, method string, start time.Time, err error) {
+	entry := log.WithContext(ctx).WithFields(logrus.Fields{
+		"method":   method,
+		"code":     status.Code(err).String(),
+		"duration": time.Since(start),
+	})
+	if err != nil {
+		entry.Warn(err)
+		return
+	}
+	entry.Info("grpc call")
+}
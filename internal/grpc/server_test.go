@@ -0,0 +1,45 @@
+package grpc_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/internal/grpc"
+	"github.com/stretchr/testify/assert"
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dial serves the handlers registered by register on an in-memory listener and returns a client connection to it.
+func dial(t *testing.T, register func(server *grpc.Server)) *gogrpc.ClientConn {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(config.GRPCConfig{})
+	register(server)
+	assert.NoError(t, server.Serve(listener))
+
+	conn, err := gogrpc.NewClient("passthrough:///bufconn",
+		gogrpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		gogrpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+		server.Close()
+	})
+	return conn
+}
+
+func TestServerStart(t *testing.T) {
+	t.Run("Listening", func(t *testing.T) {
+		server := grpc.NewServer(config.GRPCConfig{Address: "127.0.0.1:0"})
+		assert.NoError(t, server.Start())
+		server.Close()
+	})
+	t.Run("Invalid address", func(t *testing.T) {
+		server := grpc.NewServer(config.GRPCConfig{Address: "invalid:address:0"})
+		assert.Error(t, server.Start())
+	})
+}
@@ -0,0 +1,259 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/grpc/pb"
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// streamPageSize is the number of measurements read from the store at once while streaming
+const streamPageSize = 500
+
+// WasteWaterService is the interface that wraps the waste water methods shared with the REST API.
+type WasteWaterService interface {
+	Create(ctx context.Context, w *domain.WastewaterDataRequest) error
+	GetAll(ctx context.Context, page, limit int) ([]domain.WasteWaterData, error)
+	GetByID(ctx context.Context, id string) (*domain.WasteWaterData, error)
+}
+
+// MeasurementService is the interface that wraps the measurement query shared with the REST API.
+type MeasurementService interface {
+	GetAll(ctx context.Context, filter domain.MeasurementFilter, page, limit int) ([]domain.Measurement, error)
+}
+
+// WasteWaterHandler is the handler for WasteWaterService
+type WasteWaterHandler struct {
+	pb.UnimplementedWasteWaterServiceServer
+	service      WasteWaterService
+	measurements MeasurementService
+}
+
+// NewWasteWaterHandler registers the mrt.v1.WasteWaterService on the server.
+//
+// Parameters:
+// - registrar: The server the service is registered on.
+// - service: The WasteWaterService instance.
+// - measurements: The MeasurementService streamed from.
+//
+// Return type: None.
+func NewWasteWaterHandler(registrar gogrpc.ServiceRegistrar, service WasteWaterService, measurements MeasurementService) {
+	pb.RegisterWasteWaterServiceServer(registrar, &WasteWaterHandler{service: service, measurements: measurements})
+}
+
+// GetReading retrieves a reading by ID.
+//
+// ctx: the context for the operation.
+// req: the ID of the reading.
+//
+// Returns the reading, NotFound when it does not exist.
+func (h *WasteWaterHandler) GetReading(ctx context.Context, req *pb.GetReadingRequest) (*pb.Reading, error) {
+	id, err := requiredID("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	w, err := h.service.GetByID(ctx, id.Hex())
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	if w == nil {
+		return nil, errNotFound
+	}
+	return readingPB(w), nil
+}
+
+// ListReadings retrieves a page of the readings.
+//
+// ctx: the context for the operation.
+// req: the page.
+//
+// Returns the readings of the page.
+func (h *WasteWaterHandler) ListReadings(ctx context.Context, req *pb.ListReadingsRequest) (*pb.ListReadingsResponse, error) {
+	page, limit := pagination(req.GetPage(), req.GetLimit())
+	readings, err := h.service.GetAll(ctx, page, limit)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	resp := &pb.ListReadingsResponse{Readings: make([]*pb.Reading, 0, len(readings))}
+	for i := range readings {
+		resp.Readings = append(resp.Readings, readingPB(&readings[i]))
+	}
+	return resp, nil
+}
+
+// CreateReading stores a reading.
+//
+// ctx: the context for the operation.
+// req: the reading.
+//
+// Returns the outcome, a reading sent again with its dedup key is a duplicate.
+func (h *WasteWaterHandler) CreateReading(ctx context.Context, req *pb.Reading) (*pb.ReadingResult, error) {
+	w, err := readingOf(req)
+	if err != nil {
+		return nil, err
+	}
+	result := &pb.ReadingResult{DedupKey: w.DedupKey, Status: domain.BatchCreated}
+	if err = h.service.Create(ctx, w); err != nil {
+		if !errors.Is(err, domain.ErrDuplicateReading) {
+			return nil, statusFromError(err)
+		}
+		result.Status = domain.BatchDuplicate
+	}
+	result.Id, result.OutOfRange = w.ID.Hex(), w.OutOfRange
+	return result, nil
+}
+
+// StreamMeasurements sends the measurements matching the filter, newest first.
+//
+// req: the filter, from and to bound the time range.
+// stream: the stream the measurements are sent on.
+//
+// Returns an error if the measurements could not be read or sent.
+func (h *WasteWaterHandler) StreamMeasurements(req *pb.StreamMeasurementsRequest, stream pb.WasteWaterService_StreamMeasurementsServer) error {
+	filter := domain.MeasurementFilter{Parameter: req.GetParameter(), Quality: req.GetQuality()}
+	var err error
+	if filter.DeviceID, err = objectID("device_id", req.GetDeviceId()); err != nil {
+		return err
+	}
+	if filter.SensorID, err = objectID("sensor_id", req.GetSensorId()); err != nil {
+		return err
+	}
+	filter.From, filter.To = timeOf(req.GetFrom()), timeOf(req.GetTo())
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return status.Error(codes.InvalidArgument, "to is before from")
+	}
+
+	ctx := stream.Context()
+	for page := 1; ; page++ {
+		measurements, err := h.measurements.GetAll(ctx, filter, page, streamPageSize)
+		if err != nil {
+			return statusFromError(err)
+		}
+		for i := range measurements {
+			if err = stream.Send(measurementPB(&measurements[i])); err != nil {
+				return err
+			}
+		}
+		if len(measurements) < streamPageSize {
+			return nil
+		}
+		if err = ctx.Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+	}
+}
+
+// IngestReadings stores the readings received on the stream and reports the outcome of each.
+//
+// Rejected readings are invalid while failed ones can be sent again, as for the REST batches.
+//
+// stream: the stream the readings are received on.
+//
+// Returns an error if the stream broke.
+func (h *WasteWaterHandler) IngestReadings(stream pb.WasteWaterService_IngestReadingsServer) error {
+	ctx := stream.Context()
+	resp := &pb.IngestReadingsResponse{}
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			resp.ServerTime = timestamppb.Now()
+			return stream.SendAndClose(resp)
+		}
+		if err != nil {
+			return err
+		}
+		result := &pb.ReadingResult{DedupKey: req.GetDedupKey(), Status: domain.BatchCreated}
+		w, err := readingOf(req)
+		if err == nil {
+			err = h.service.Create(ctx, w)
+			result.Id, result.OutOfRange = w.ID.Hex(), w.OutOfRange
+		}
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrDuplicateReading):
+				result.Status = domain.BatchDuplicate
+			case status.Code(statusFromError(err)) != codes.Internal:
+				result.Status, result.Error = domain.BatchRejected, status.Convert(statusFromError(err)).Message()
+			default:
+				result.Status, result.Error = domain.BatchFailed, err.Error()
+			}
+		}
+		resp.Results = append(resp.Results, result)
+	}
+}
+
+// readingOf converts a reading message, parameters without a field are kept in Parameters
+func readingOf(req *pb.Reading) (*domain.WastewaterDataRequest, error) {
+	deviceID, err := objectID("device_id", req.GetDeviceId())
+	if err != nil {
+		return nil, err
+	}
+	w := &domain.WastewaterDataRequest{
+		DeviceID: deviceID,
+		Units:    req.GetUnits(),
+		DedupKey: req.GetDedupKey(),
+	}
+	if ts := timeOf(req.GetTimestamp()); !ts.IsZero() {
+		w.Timestamp = ts
+	} else {
+		w.Timestamp = time.Now().UTC()
+	}
+	for parameter, value := range req.GetValues() {
+		if !w.SetValue(parameter, value) {
+			if w.Parameters == nil {
+				w.Parameters = map[string]float64{}
+			}
+			w.Parameters[parameter] = value
+		}
+	}
+	return w, nil
+}
+
+// readingPB converts a reading to its message
+func readingPB(w *domain.WasteWaterData) *pb.Reading {
+	values := w.Values()
+	for parameter, value := range w.Parameters {
+		values[parameter] = value
+	}
+	reading := &pb.Reading{
+		Id:          w.ID.Hex(),
+		Timestamp:   timestamp(w.Timestamp),
+		Values:      values,
+		Units:       w.Units,
+		OutOfRange:  w.OutOfRange,
+		Quality:     w.Quality,
+		Anomalies:   w.Anomalies,
+		Maintenance: w.Maintenance,
+	}
+	if !w.DeviceID.IsZero() {
+		reading.DeviceId = w.DeviceID.Hex()
+	}
+	return reading
+}
+
+// measurementPB converts a measurement to its message
+func measurementPB(m *domain.Measurement) *pb.Measurement {
+	measurement := &pb.Measurement{
+		Id:          m.ID.Hex(),
+		ReadingId:   m.ReadingID.Hex(),
+		Timestamp:   timestamp(m.Timestamp),
+		DeviceId:    m.DeviceID.Hex(),
+		Parameter:   m.Parameter,
+		Value:       m.Value,
+		Unit:        m.Unit,
+		Quality:     m.Quality,
+		OutOfRange:  m.OutOfRange,
+		Maintenance: m.Maintenance,
+	}
+	if !m.SensorID.IsZero() {
+		measurement.SensorId = m.SensorID.Hex()
+	}
+	return measurement
+}
@@ -0,0 +1,204 @@
+package grpc_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/grpc"
+	"github.com/anggi-susanto/mrt-go/internal/grpc/mocks"
+	"github.com/anggi-susanto/mrt-go/internal/grpc/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func wasteWaterClient(t *testing.T, service grpc.WasteWaterService, measurements grpc.MeasurementService) pb.WasteWaterServiceClient {
+	return pb.NewWasteWaterServiceClient(dial(t, func(server *grpc.Server) { grpc.NewWasteWaterHandler(server, service, measurements) }))
+}
+
+func TestWasteWaterHandlerGetReading(t *testing.T) {
+	readingID := primitive.NewObjectID()
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(mocks.WasteWaterService)
+		mockService.On("GetByID", mock.Anything, readingID.Hex()).Return(&domain.WasteWaterData{
+			ID:         readingID,
+			PH:         7.2,
+			Parameters: map[string]float64{"Phosphate": 0.4},
+			OutOfRange: []string{domain.ParamPH},
+		}, nil)
+
+		reading, err := wasteWaterClient(t, mockService, nil).GetReading(context.Background(), &pb.GetReadingRequest{Id: readingID.Hex()})
+		assert.NoError(t, err)
+		assert.Equal(t, readingID.Hex(), reading.Id)
+		assert.Equal(t, 7.2, reading.Values[domain.ParamPH])
+		assert.Equal(t, 0.4, reading.Values["Phosphate"])
+		assert.Equal(t, []string{domain.ParamPH}, reading.OutOfRange)
+	})
+	t.Run("Not found", func(t *testing.T) {
+		mockService := new(mocks.WasteWaterService)
+		mockService.On("GetByID", mock.Anything, readingID.Hex()).Return(nil, nil)
+
+		_, err := wasteWaterClient(t, mockService, nil).GetReading(context.Background(), &pb.GetReadingRequest{Id: readingID.Hex()})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestWasteWaterHandlerListReadings(t *testing.T) {
+	mockService := new(mocks.WasteWaterService)
+	mockService.On("GetAll", mock.Anything, 3, 20).Return([]domain.WasteWaterData{{ID: primitive.NewObjectID()}}, nil)
+
+	resp, err := wasteWaterClient(t, mockService, nil).ListReadings(context.Background(), &pb.ListReadingsRequest{Page: 3, Limit: 20})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Readings, 1)
+	mockService.AssertExpectations(t)
+}
+
+func TestWasteWaterHandlerCreateReading(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	readingID := primitive.NewObjectID()
+	timestamp := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(mocks.WasteWaterService)
+		mockService.On("Create", mock.Anything, mock.MatchedBy(func(w *domain.WastewaterDataRequest) bool {
+			return w.DeviceID == deviceID && w.Timestamp.Equal(timestamp) && w.PH == 9.5 &&
+				w.Parameters["Phosphate"] == 0.4 && w.Units[domain.ParamTemperature] == "degF"
+		})).Run(func(args mock.Arguments) {
+			w := args.Get(1).(*domain.WastewaterDataRequest)
+			w.ID, w.OutOfRange = readingID, []string{domain.ParamPH}
+		}).Return(nil)
+
+		result, err := wasteWaterClient(t, mockService, nil).CreateReading(context.Background(), &pb.Reading{
+			DeviceId:  deviceID.Hex(),
+			Timestamp: timestamppb.New(timestamp),
+			Values:    map[string]float64{domain.ParamPH: 9.5, "Phosphate": 0.4},
+			Units:     map[string]string{domain.ParamTemperature: "degF"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, readingID.Hex(), result.Id)
+		assert.Equal(t, domain.BatchCreated, result.Status)
+		assert.Equal(t, []string{domain.ParamPH}, result.OutOfRange)
+		mockService.AssertExpectations(t)
+	})
+	t.Run("Duplicate", func(t *testing.T) {
+		mockService := new(mocks.WasteWaterService)
+		mockService.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.WastewaterDataRequest).ID = readingID
+		}).Return(domain.ErrDuplicateReading)
+
+		result, err := wasteWaterClient(t, mockService, nil).CreateReading(context.Background(), &pb.Reading{DedupKey: "r1"})
+		assert.NoError(t, err)
+		assert.Equal(t, readingID.Hex(), result.Id)
+		assert.Equal(t, domain.BatchDuplicate, result.Status)
+	})
+	t.Run("Out of range", func(t *testing.T) {
+		mockService := new(mocks.WasteWaterService)
+		mockService.On("Create", mock.Anything, mock.Anything).Return(domain.ErrOutOfRange)
+
+		_, err := wasteWaterClient(t, mockService, nil).CreateReading(context.Background(), &pb.Reading{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestWasteWaterHandlerStreamMeasurements(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	filter := domain.MeasurementFilter{DeviceID: deviceID, Parameter: domain.ParamPH, From: from, To: to}
+	page := func(n int) []domain.Measurement {
+		measurements := make([]domain.Measurement, n)
+		for i := range measurements {
+			measurements[i] = domain.Measurement{ID: primitive.NewObjectID(), DeviceID: deviceID, Parameter: domain.ParamPH, Value: 7}
+		}
+		return measurements
+	}
+	receive := func(stream pb.WasteWaterService_StreamMeasurementsClient) (int, error) {
+		n := 0
+		for {
+			_, err := stream.Recv()
+			if err == io.EOF {
+				return n, nil
+			}
+			if err != nil {
+				return n, err
+			}
+			n++
+		}
+	}
+	t.Run("Pages", func(t *testing.T) {
+		mockMeasurements := new(mocks.MeasurementService)
+		mockMeasurements.On("GetAll", mock.Anything, filter, 1, 500).Return(page(500), nil)
+		mockMeasurements.On("GetAll", mock.Anything, filter, 2, 500).Return(page(3), nil)
+
+		stream, err := wasteWaterClient(t, nil, mockMeasurements).StreamMeasurements(context.Background(), &pb.StreamMeasurementsRequest{
+			DeviceId:  deviceID.Hex(),
+			Parameter: domain.ParamPH,
+			From:      timestamppb.New(from),
+			To:        timestamppb.New(to),
+		})
+		assert.NoError(t, err)
+		n, err := receive(stream)
+		assert.NoError(t, err)
+		assert.Equal(t, 503, n)
+		mockMeasurements.AssertExpectations(t)
+	})
+	t.Run("Invalid range", func(t *testing.T) {
+		stream, err := wasteWaterClient(t, nil, new(mocks.MeasurementService)).StreamMeasurements(context.Background(), &pb.StreamMeasurementsRequest{
+			From: timestamppb.New(to),
+			To:   timestamppb.New(from),
+		})
+		assert.NoError(t, err)
+		_, err = receive(stream)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+	t.Run("Error", func(t *testing.T) {
+		mockMeasurements := new(mocks.MeasurementService)
+		mockMeasurements.On("GetAll", mock.Anything, domain.MeasurementFilter{}, 1, 500).Return(nil, errors.New("database error"))
+
+		stream, err := wasteWaterClient(t, nil, mockMeasurements).StreamMeasurements(context.Background(), &pb.StreamMeasurementsRequest{})
+		assert.NoError(t, err)
+		_, err = receive(stream)
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+}
+
+func TestWasteWaterHandlerIngestReadings(t *testing.T) {
+	mockService := new(mocks.WasteWaterService)
+	mockService.On("Create", mock.Anything, mock.MatchedBy(func(w *domain.WastewaterDataRequest) bool { return w.DedupKey == "r1" })).
+		Run(func(args mock.Arguments) { args.Get(1).(*domain.WastewaterDataRequest).ID = primitive.NewObjectID() }).Return(nil)
+	mockService.On("Create", mock.Anything, mock.MatchedBy(func(w *domain.WastewaterDataRequest) bool { return w.DedupKey == "r2" })).
+		Return(domain.ErrDuplicateReading)
+	mockService.On("Create", mock.Anything, mock.MatchedBy(func(w *domain.WastewaterDataRequest) bool { return w.DedupKey == "r3" })).
+		Return(domain.ErrOutOfRange)
+	mockService.On("Create", mock.Anything, mock.MatchedBy(func(w *domain.WastewaterDataRequest) bool { return w.DedupKey == "r4" })).
+		Return(errors.New("database error"))
+
+	stream, err := wasteWaterClient(t, mockService, nil).IngestReadings(context.Background())
+	assert.NoError(t, err)
+	for _, reading := range []*pb.Reading{
+		{DedupKey: "r1"},
+		{DedupKey: "r2"},
+		{DedupKey: "r3"},
+		{DedupKey: "r4"},
+		{DedupKey: "r5", DeviceId: "invalid"},
+	} {
+		assert.NoError(t, stream.Send(reading))
+	}
+	resp, err := stream.CloseAndRecv()
+	assert.NoError(t, err)
+	assert.NotNil(t, resp.ServerTime)
+	statuses := []string{}
+	for _, result := range resp.Results {
+		statuses = append(statuses, result.Status)
+	}
+	assert.Equal(t, []string{domain.BatchCreated, domain.BatchDuplicate, domain.BatchRejected, domain.BatchFailed, domain.BatchRejected}, statuses)
+	assert.NotEmpty(t, resp.Results[0].Id)
+	assert.Equal(t, "database error", resp.Results[3].Error)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,207 @@
+// Package health serves the liveness and readiness probes of the API on /healthz and /readyz.
+//
+// The liveness probe checks the background workers, a worker that stopped sweeping means the
+// process has to be restarted. The readiness probe checks the workers and the dependencies of the
+// API, MongoDB and the MQTT broker, the API should get no traffic while a required one is down.
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// LivenessEndpoint is the route of the liveness probe
+	LivenessEndpoint = "/healthz"
+	// ReadinessEndpoint is the route of the readiness probe
+	ReadinessEndpoint = "/readyz"
+)
+
+const (
+	// StatusUp is the status of a healthy component, and of a probe whose components are all up
+	StatusUp = "up"
+	// StatusDown is the status of a failed component, and of a probe with a required component down
+	StatusDown = "down"
+	// StatusDegraded is the status of a probe with only optional components down, it still passes
+	StatusDegraded = "degraded"
+)
+
+// defaultTimeout bounds each check when the configuration has no timeout
+const defaultTimeout = 2 * time.Second
+
+// Check returns an error when a component is not healthy.
+type Check func(ctx context.Context) error
+
+// ComponentStatus is the outcome of the check of a component.
+type ComponentStatus struct {
+	Status    string  `json:"status"`
+	Optional  bool    `json:"optional,omitempty"`
+	Error     string  `json:"error,omitempty"`
+	LatencyMs float64 `json:"latency_ms"`
+}
+
+// Report is the outcome of a probe.
+type Report struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentStatus `json:"components"`
+}
+
+// component is a check registered under a name
+type component struct {
+	name     string
+	check    Check
+	optional bool
+}
+
+// Health holds the checks of the probes.
+type Health struct {
+	timeout time.Duration
+	mu      sync.Mutex
+	live    []component
+	ready   []component
+}
+
+// New creates a new Health without checks.
+//
+// Parameters:
+// - cfg: The health configuration, it bounds the duration of each check.
+//
+// Returns:
+// - A pointer to the newly created Health instance.
+func New(cfg config.HealthConfig) *Health {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &Health{timeout: timeout}
+}
+
+// AddLiveness adds a check to both probes, the process is restarted when it fails.
+//
+// name: the name of the component in the report.
+// check: the check of the component.
+func (h *Health) AddLiveness(name string, check Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.live = append(h.live, component{name: name, check: check})
+	h.ready = append(h.ready, component{name: name, check: check})
+}
+
+// AddReadiness adds a check to the readiness probe, the API gets no traffic while it fails.
+//
+// name: the name of the component in the report.
+// check: the check of the component.
+func (h *Health) AddReadiness(name string, check Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.ready = append(h.ready, component{name: name, check: check})
+}
+
+// AddOptional adds a check to the readiness probe that is reported but does not fail it, the
+// probe is degraded while it fails.
+//
+// name: the name of the component in the report.
+// check: the check of the component.
+func (h *Health) AddOptional(name string, check Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.ready = append(h.ready, component{name: name, check: check, optional: true})
+}
+
+// Live runs the checks of the liveness probe.
+func (h *Health) Live(ctx context.Context) Report {
+	h.mu.Lock()
+	components := append([]component(nil), h.live...)
+	h.mu.Unlock()
+	return h.run(ctx, components)
+}
+
+// Ready runs the checks of the readiness probe.
+func (h *Health) Ready(ctx context.Context) Report {
+	h.mu.Lock()
+	components := append([]component(nil), h.ready...)
+	h.mu.Unlock()
+	return h.run(ctx, components)
+}
+
+// run checks the components concurrently, each within the timeout.
+func (h *Health) run(ctx context.Context, components []component) Report {
+	statuses := make([]ComponentStatus, len(components))
+	var wg sync.WaitGroup
+	for i, c := range components {
+		wg.Add(1)
+		go func(i int, c component) {
+			defer wg.Done()
+			statuses[i] = h.check(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Components: make(map[string]ComponentStatus, len(components))}
+	for i, c := range components {
+		report.Components[c.name] = statuses[i]
+		if statuses[i].Status == StatusUp {
+			continue
+		}
+		if !c.optional {
+			report.Status = StatusDown
+		} else if report.Status == StatusUp {
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+// check runs the check of a component within the timeout.
+func (h *Health) check(ctx context.Context, c component) ComponentStatus {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+	start := time.Now()
+	done := make(chan error, 1)
+	// A check ignoring its context must not hold the probe up
+	go func() { done <- c.check(ctx) }()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	status := ComponentStatus{
+		Status:    StatusUp,
+		Optional:  c.optional,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		status.Status = StatusDown
+		status.Error = err.Error()
+	}
+	return status
+}
+
+// NewHandler registers the probes on the Fiber app.
+//
+// A probe answers 200 when up or degraded and 503 when down, with the report as JSON.
+//
+// app: the Fiber app.
+// h: the checks of the probes.
+func NewHandler(app *fiber.App, h *Health) {
+	app.Get(LivenessEndpoint, func(c *fiber.Ctx) error {
+		return respond(c, h.Live(c.UserContext()))
+	})
+	app.Get(ReadinessEndpoint, func(c *fiber.Ctx) error {
+		return respond(c, h.Ready(c.UserContext()))
+	})
+}
+
+// respond sends a report with the status code of the probe
+func respond(c *fiber.Ctx, report Report) error {
+	status := fiber.StatusOK
+	if report.Status == StatusDown {
+		status = fiber.StatusServiceUnavailable
+	}
+	return c.Status(status).JSON(report)
+}
@@ -0,0 +1,125 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/internal/health"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// up is the check of a healthy component
+func up(context.Context) error {
+	return nil
+}
+
+// probe requests a probe of the app and decodes its report
+func probe(t *testing.T, app *fiber.App, endpoint string) (int, health.Report) {
+	resp, err := app.Test(httptest.NewRequest("GET", endpoint, nil))
+	require.NoError(t, err)
+	var report health.Report
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	return resp.StatusCode, report
+}
+
+func TestNewHandler(t *testing.T) {
+	t.Run("Up", func(t *testing.T) {
+		h := health.New(config.HealthConfig{})
+		h.AddLiveness("worker", up)
+		h.AddReadiness("mongo", up)
+		app := fiber.New()
+		health.NewHandler(app, h)
+
+		status, report := probe(t, app, health.LivenessEndpoint)
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, health.StatusUp, report.Status)
+		assert.Equal(t, []string{"worker"}, keys(report))
+
+		status, report = probe(t, app, health.ReadinessEndpoint)
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, health.StatusUp, report.Status)
+		assert.ElementsMatch(t, []string{"worker", "mongo"}, keys(report))
+	})
+	t.Run("Required down", func(t *testing.T) {
+		h := health.New(config.HealthConfig{})
+		h.AddLiveness("worker", up)
+		h.AddReadiness("mongo", func(context.Context) error { return errors.New("server selection timeout") })
+		app := fiber.New()
+		health.NewHandler(app, h)
+
+		status, report := probe(t, app, health.LivenessEndpoint)
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, health.StatusUp, report.Status)
+
+		status, report = probe(t, app, health.ReadinessEndpoint)
+		assert.Equal(t, fiber.StatusServiceUnavailable, status)
+		assert.Equal(t, health.StatusDown, report.Status)
+		assert.Equal(t, health.StatusDown, report.Components["mongo"].Status)
+		assert.Equal(t, "server selection timeout", report.Components["mongo"].Error)
+		assert.Equal(t, health.StatusUp, report.Components["worker"].Status)
+	})
+	t.Run("Optional down", func(t *testing.T) {
+		h := health.New(config.HealthConfig{})
+		h.AddReadiness("mongo", up)
+		h.AddOptional("mqtt", func(context.Context) error { return errors.New("not connected") })
+		app := fiber.New()
+		health.NewHandler(app, h)
+
+		status, report := probe(t, app, health.ReadinessEndpoint)
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, health.StatusDegraded, report.Status)
+		assert.Equal(t, health.ComponentStatus{Status: health.StatusDown, Optional: true, Error: "not connected"}, withoutLatency(report.Components["mqtt"]))
+	})
+}
+
+func TestReady(t *testing.T) {
+	t.Run("Timeout", func(t *testing.T) {
+		h := health.New(config.HealthConfig{Timeout: 10 * time.Millisecond})
+		block := make(chan struct{})
+		defer close(block)
+		// The check ignores its context, the probe must not wait for it
+		h.AddReadiness("stuck", func(context.Context) error {
+			<-block
+			return nil
+		})
+
+		report := h.Ready(context.Background())
+		assert.Equal(t, health.StatusDown, report.Status)
+		assert.Equal(t, context.DeadlineExceeded.Error(), report.Components["stuck"].Error)
+	})
+}
+
+func TestHeartbeat(t *testing.T) {
+	heartbeat := health.NewHeartbeat(20 * time.Millisecond)
+	assert.NoError(t, heartbeat.Check(context.Background()))
+
+	time.Sleep(30 * time.Millisecond)
+	assert.ErrorContains(t, heartbeat.Check(context.Background()), "no sweep for")
+
+	heartbeat.Beat(time.Now())
+	assert.NoError(t, heartbeat.Check(context.Background()))
+	heartbeat.Beat(time.Now().Add(-time.Hour))
+	assert.NoError(t, heartbeat.Check(context.Background()))
+}
+
+// keys returns the names of the components of a report
+func keys(report health.Report) []string {
+	var names []string
+	for name := range report.Components {
+		names = append(names, name)
+	}
+	return names
+}
+
+// withoutLatency clears the latency of a status to compare it
+func withoutLatency(status health.ComponentStatus) health.ComponentStatus {
+	status.LatencyMs = 0
+	return status
+}
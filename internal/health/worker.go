@@ -0,0 +1,46 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Heartbeat tells whether a background worker is still running its sweeps.
+type Heartbeat struct {
+	maxAge time.Duration
+	mu     sync.Mutex
+	last   time.Time
+}
+
+// NewHeartbeat creates a new Heartbeat, started now.
+//
+// maxAge: the time without beat after which the worker is down, a few sweep intervals.
+// Returns a pointer to a Heartbeat.
+func NewHeartbeat(maxAge time.Duration) *Heartbeat {
+	return &Heartbeat{maxAge: maxAge, last: time.Now()}
+}
+
+// Beat records a sweep of the worker, failed or not, the failures of its dependencies are
+// reported by their own checks.
+//
+// now: the time of the sweep.
+func (h *Heartbeat) Beat(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if now.After(h.last) {
+		h.last = now
+	}
+}
+
+// Check returns an error when the worker missed its beats for longer than the max age.
+func (h *Heartbeat) Check(context.Context) error {
+	h.mu.Lock()
+	last := h.last
+	h.mu.Unlock()
+	if age := time.Since(last); age > h.maxAge {
+		return fmt.Errorf("no sweep for %s", age.Round(time.Second))
+	}
+	return nil
+}
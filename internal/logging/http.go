@@ -0,0 +1,96 @@
+package logging
+
+import (
+	"errors"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Middleware gives each request an ID and its fields, and logs it once answered.
+//
+// The ID is taken from the request ID header, X-Request-ID by default, a new one is generated
+// when the request has none, and echoed in the response. The ID, method, path, tenant and user
+// are in the user context of the request, the handlers pass ctx.UserContext() on for the lines
+// of the services and repositories to carry them.
+//
+// The access log line has the route, status and latency too. The lines of the successful
+// requests are sampled per route, the failed ones are all logged. Errors returned by the
+// handlers are answered by the error handler of the app, like the Fiber logger middleware does.
+// It must be the first middleware so that the others see the errors and the request fields.
+//
+// Parameters:
+// - cfg: the request ID, tenant and user headers and the sampling of the access log.
+// Returns a fiber.Handler.
+func Middleware(cfg config.LoggingConfig) fiber.Handler {
+	access := Logger("http")
+	sampler := NewSampler(cfg.SampleTick, cfg.SampleFirst, cfg.SampleThereafter)
+	header := cfg.RequestIDHeader
+	if header == "" {
+		header = fiber.HeaderXRequestID
+	}
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		// The headers are backed by the request buffer, which is reused once the request is done
+		requestID := utils.CopyString(c.Get(header))
+		if requestID == "" {
+			requestID = utils.UUIDv4()
+		}
+		c.Set(header, requestID)
+		fields := logrus.Fields{
+			FieldRequestID: requestID,
+			FieldMethod:    utils.CopyString(c.Method()),
+			FieldPath:      utils.CopyString(c.Path()),
+		}
+		if tenant := c.Get(cfg.TenantHeader); cfg.TenantHeader != "" && tenant != "" {
+			fields[FieldTenant] = utils.CopyString(tenant)
+		}
+		if user := c.Get(cfg.UserHeader); cfg.UserHeader != "" && user != "" {
+			fields[FieldUser] = utils.CopyString(user)
+		}
+		c.SetUserContext(WithFields(c.UserContext(), fields))
+
+		err := c.Next()
+
+		route := c.Route().Path
+		if err != nil {
+			var fiberErr *fiber.Error
+			// The router answers 404 and 405 itself when no route matches, the handlers of the API never do
+			if errors.As(err, &fiberErr) && (fiberErr.Code == fiber.StatusNotFound || fiberErr.Code == fiber.StatusMethodNotAllowed) {
+				route = ""
+			}
+			if handlerErr := c.App().ErrorHandler(c, err); handlerErr != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+		status := c.Response().StatusCode()
+		if status < fiber.StatusBadRequest && !sampler.Allow(fields[FieldMethod].(string)+" "+route) {
+			return nil
+		}
+
+		// The user context has the span of the request by now
+		entry := access.WithContext(c.UserContext()).WithFields(logrus.Fields{
+			"status":     status,
+			"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+			"ip":         c.IP(),
+		})
+		if route != "" {
+			entry = entry.WithField(FieldRoute, route)
+		}
+		if err != nil {
+			entry = entry.WithError(err)
+		}
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			entry.Error("request failed")
+		case status >= fiber.StatusBadRequest:
+			entry.Warn("request rejected")
+		default:
+			entry.Info("request")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,178 @@
+// Package logging sets up the structured JSON logs of the API: a logger per package with a
+// level of its own, the request fields carried by the context of a request down to the
+// repositories, and a sampled access log.
+//
+// A package logs through its logger with the context of the operation, e.g.
+//
+//	var log = logging.Logger("mongo")
+//	log.WithContext(ctx).Error(err)
+//
+// and the line gets the request ID, method and path, device, tenant, user and trace fields the
+// context carries. The access log line of a request has its route too.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The fields of the log lines
+const (
+	FieldLogger    = "logger"
+	FieldRequestID = "request_id"
+	FieldMethod    = "method"
+	FieldPath      = "path"
+	FieldRoute     = "route"
+	FieldDeviceID  = "device_id"
+	FieldTenant    = "tenant"
+	FieldUser      = "user"
+	FieldTraceID   = "trace_id"
+	FieldSpanID    = "span_id"
+)
+
+var (
+	mu sync.Mutex
+	// level is the level of the packages without a level of their own
+	level = logrus.InfoLevel
+	// levels are the levels of the packages by name
+	levels = map[string]logrus.Level{}
+	// loggers are the loggers handed out by name, Setup reconfigures them
+	loggers = map[string]*logrus.Logger{}
+)
+
+// Setup formats the logs as JSON and sets the default level and the levels of the packages.
+//
+// The standard logrus logger is set up too, with the default level, for the code logging through it.
+//
+// Parameters:
+// - cfg: the default level and the levels by package name, e.g. {"mongo": "debug"}.
+//
+// Returns an error if a level is unknown, nothing is changed then.
+func Setup(cfg config.LoggingConfig) error {
+	defaultLevel := logrus.InfoLevel
+	if cfg.Level != "" {
+		parsed, err := logrus.ParseLevel(cfg.Level)
+		if err != nil {
+			return err
+		}
+		defaultLevel = parsed
+	}
+	packageLevels := map[string]logrus.Level{}
+	for name, l := range cfg.Levels {
+		parsed, err := logrus.ParseLevel(l)
+		if err != nil {
+			return fmt.Errorf("level of %s: %w", name, err)
+		}
+		packageLevels[name] = parsed
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	level = defaultLevel
+	levels = packageLevels
+	for name, logger := range loggers {
+		configure(logger, name)
+	}
+	configure(logrus.StandardLogger(), "")
+	return nil
+}
+
+// Logger returns the logger of a package, the same one for the same name.
+//
+// Its lines have the name in the logger field and it logs at the level configured for the name,
+// the default level when there is none. It can be created before Setup, which reconfigures it.
+//
+// Parameters:
+// - name: the name of the package, e.g. mongo.
+//
+// Returns a pointer to a logrus.Logger.
+func Logger(name string) *logrus.Logger {
+	mu.Lock()
+	defer mu.Unlock()
+	if logger, ok := loggers[name]; ok {
+		return logger
+	}
+	logger := logrus.New()
+	configure(logger, name)
+	loggers[name] = logger
+	return logger
+}
+
+// configure sets the format, level and context hook of a logger, mu must be held.
+func configure(logger *logrus.Logger, name string) {
+	logger.SetFormatter(&logrus.JSONFormatter{TimestampFormat: time.RFC3339Nano})
+	l, ok := levels[name]
+	if !ok {
+		l = level
+	}
+	logger.SetLevel(l)
+	hooks := logrus.LevelHooks{}
+	hooks.Add(contextHook{name: name})
+	logger.ReplaceHooks(hooks)
+}
+
+// fieldsKey is the context key of the request fields
+type fieldsKey struct{}
+
+// WithFields returns a copy of ctx carrying the fields along with the ones ctx already carries.
+//
+// Parameters:
+// - ctx: the context of the operation.
+// - fields: the fields to add, e.g. the device ID, they replace the fields of the same name.
+//
+// Returns the context carrying the fields.
+func WithFields(ctx context.Context, fields logrus.Fields) context.Context {
+	merged := logrus.Fields{}
+	for key, value := range FieldsOf(ctx) {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// FieldsOf returns the fields ctx carries, nil when it carries none. They must not be modified.
+func FieldsOf(ctx context.Context) logrus.Fields {
+	fields, _ := ctx.Value(fieldsKey{}).(logrus.Fields)
+	return fields
+}
+
+// contextHook adds the name of the logger, the fields of the context and the trace of the context to the lines.
+type contextHook struct {
+	name string
+}
+
+// Levels returns every level, each line gets the fields.
+func (h contextHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire adds the fields to a line, the fields of the line itself take precedence.
+func (h contextHook) Fire(entry *logrus.Entry) error {
+	add := func(key string, value interface{}) {
+		if _, ok := entry.Data[key]; !ok {
+			entry.Data[key] = value
+		}
+	}
+	if h.name != "" {
+		add(FieldLogger, h.name)
+	}
+	if entry.Context == nil {
+		return nil
+	}
+	for key, value := range FieldsOf(entry.Context) {
+		add(key, value)
+	}
+	if span := trace.SpanContextFromContext(entry.Context); span.IsValid() {
+		add(FieldTraceID, span.TraceID().String())
+		add(FieldSpanID, span.SpanID().String())
+	}
+	return nil
+}
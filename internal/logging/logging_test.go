@@ -0,0 +1,169 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/internal/logging"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// capture sends the lines of a logger to a buffer
+func capture(t *testing.T, name string) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	logger := logging.Logger(name)
+	logger.SetOutput(buf)
+	t.Cleanup(func() { logger.SetOutput(os.Stderr) })
+	return buf
+}
+
+// lines decodes the JSON lines of a buffer
+func lines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var decoded []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		fields := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal([]byte(line), &fields))
+		decoded = append(decoded, fields)
+	}
+	return decoded
+}
+
+func TestSetup(t *testing.T) {
+	t.Run("Levels", func(t *testing.T) {
+		buf := capture(t, "quiet")
+		require.NoError(t, logging.Setup(config.LoggingConfig{Level: "debug", Levels: map[string]string{"quiet": "warn"}}))
+		defer func() { require.NoError(t, logging.Setup(config.LoggingConfig{})) }()
+
+		logging.Logger("quiet").Info("dropped")
+		logging.Logger("quiet").Warn("kept")
+		assert.Equal(t, logrus.DebugLevel, logging.Logger("verbose").GetLevel())
+
+		logged := lines(t, buf)
+		require.Len(t, logged, 1)
+		assert.Equal(t, "kept", logged[0]["msg"])
+		assert.Equal(t, "quiet", logged[0]["logger"])
+	})
+	t.Run("Unknown level", func(t *testing.T) {
+		err := logging.Setup(config.LoggingConfig{Levels: map[string]string{"mongo": "loud"}})
+		assert.ErrorContains(t, err, "level of mongo")
+	})
+}
+
+func TestWithFields(t *testing.T) {
+	buf := capture(t, "fields")
+	ctx := logging.WithFields(context.Background(), logrus.Fields{logging.FieldRequestID: "req-1", logging.FieldDeviceID: "old"})
+	ctx = logging.WithFields(ctx, logrus.Fields{logging.FieldDeviceID: "dev-1"})
+	ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1}, SpanID: trace.SpanID{2}, TraceFlags: trace.FlagsSampled,
+	}))
+
+	logging.Logger("fields").WithContext(ctx).WithField(logging.FieldRequestID, "own").Error("failed")
+
+	logged := lines(t, buf)
+	require.Len(t, logged, 1)
+	assert.Equal(t, "own", logged[0]["request_id"])
+	assert.Equal(t, "dev-1", logged[0]["device_id"])
+	assert.Equal(t, "01000000000000000000000000000000", logged[0]["trace_id"])
+	assert.Equal(t, "0200000000000000", logged[0]["span_id"])
+}
+
+func TestMiddleware(t *testing.T) {
+	buf := capture(t, "http")
+	app := fiber.New()
+	app.Use(logging.Middleware(config.LoggingConfig{
+		TenantHeader: "X-Tenant-ID", UserHeader: "X-User-ID",
+		SampleTick: time.Hour, SampleFirst: 1,
+	}))
+	var fields logrus.Fields
+	app.Get("/devices/:id", func(c *fiber.Ctx) error {
+		fields = logging.FieldsOf(c.UserContext())
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Post("/devices", func(c *fiber.Ctx) error {
+		return errors.New("database down")
+	})
+
+	t.Run("Request ID", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/devices/42", nil)
+		req.Header.Set("X-Request-ID", "req-1")
+		req.Header.Set("X-Tenant-ID", "depot-a")
+		req.Header.Set("X-User-ID", "operator")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		assert.Equal(t, "req-1", resp.Header.Get("X-Request-ID"))
+		assert.Equal(t, logrus.Fields{"request_id": "req-1", "method": "GET", "path": "/devices/42", "tenant": "depot-a", "user": "operator"}, fields)
+		logged := lines(t, buf)
+		require.Len(t, logged, 1)
+		assert.Equal(t, "req-1", logged[0]["request_id"])
+		assert.Equal(t, "/devices/:id", logged[0]["route"])
+		assert.Equal(t, float64(200), logged[0]["status"])
+		assert.Equal(t, "info", logged[0]["level"])
+	})
+	t.Run("Generated ID and sampling", func(t *testing.T) {
+		buf.Reset()
+		resp, err := app.Test(httptest.NewRequest("GET", "/devices/43", nil))
+		require.NoError(t, err)
+
+		assert.NotEmpty(t, resp.Header.Get("X-Request-ID"))
+		assert.Equal(t, resp.Header.Get("X-Request-ID"), fields["request_id"])
+		assert.Empty(t, buf.String())
+	})
+	t.Run("Errors", func(t *testing.T) {
+		buf.Reset()
+		resp, err := app.Test(httptest.NewRequest("POST", "/devices", nil))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+		resp, err = app.Test(httptest.NewRequest("GET", "/unknown", nil))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+		logged := lines(t, buf)
+		require.Len(t, logged, 2)
+		assert.Equal(t, "error", logged[0]["level"])
+		assert.Equal(t, "database down", logged[0]["error"])
+		assert.Equal(t, "/devices", logged[0]["route"])
+		assert.Equal(t, "warning", logged[1]["level"])
+		assert.NotContains(t, logged[1], "route")
+	})
+}
+
+func TestSampler(t *testing.T) {
+	t.Run("First and thereafter", func(t *testing.T) {
+		sampler := logging.NewSampler(time.Hour, 2, 3)
+		var allowed []bool
+		for i := 0; i < 8; i++ {
+			allowed = append(allowed, sampler.Allow("POST /waste-water"))
+		}
+		assert.Equal(t, []bool{true, true, false, false, true, false, false, true}, allowed)
+		assert.True(t, sampler.Allow("GET /devices"))
+	})
+	t.Run("Tick", func(t *testing.T) {
+		sampler := logging.NewSampler(10*time.Millisecond, 1, 0)
+		assert.True(t, sampler.Allow("POST /waste-water"))
+		assert.False(t, sampler.Allow("POST /waste-water"))
+		time.Sleep(20 * time.Millisecond)
+		assert.True(t, sampler.Allow("POST /waste-water"))
+	})
+	t.Run("Disabled", func(t *testing.T) {
+		sampler := logging.NewSampler(time.Hour, 0, 0)
+		for i := 0; i < 5; i++ {
+			assert.True(t, sampler.Allow("POST /waste-water"))
+		}
+	})
+}
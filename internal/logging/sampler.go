@@ -0,0 +1,56 @@
+package logging
+
+import (
+	"sync"
+	"time"
+)
+
+// Sampler thins out high-volume logs: per key and per tick the first lines are logged, then one
+// in every thereafter lines.
+type Sampler struct {
+	tick       time.Duration
+	first      int
+	thereafter int
+
+	mu     sync.Mutex
+	counts map[string]*sampleCount
+}
+
+// sampleCount is the number of lines of a key since the start of its tick
+type sampleCount struct {
+	start time.Time
+	n     int
+}
+
+// NewSampler creates a new Sampler.
+//
+// Parameters:
+// - tick: the period the lines are counted over.
+// - first: the number of lines of a key logged per tick, 0 logs every line.
+// - thereafter: one in every thereafter lines beyond first is logged, 0 logs none of them.
+// Returns a pointer to a Sampler.
+func NewSampler(tick time.Duration, first, thereafter int) *Sampler {
+	return &Sampler{tick: tick, first: first, thereafter: thereafter, counts: map[string]*sampleCount{}}
+}
+
+// Allow counts a line of a key and tells whether it is logged.
+//
+// key: the kind of line, e.g. the route of a request. The keys must be few, each is counted apart.
+func (s *Sampler) Allow(key string) bool {
+	if s.first <= 0 {
+		return true
+	}
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count, ok := s.counts[key]
+	if !ok || now.Sub(count.start) >= s.tick {
+		count = &sampleCount{start: now}
+		s.counts[key] = count
+	}
+	count.n++
+	if count.n <= s.first {
+		return true
+	}
+	return s.thereafter > 0 && (count.n-s.first)%s.thereafter == 0
+}
@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// unmatchedRoute labels the requests no route matched, so that unknown paths do not each get a series
+const unmatchedRoute = "unmatched"
+
+// Middleware counts and times the requests by method, route and status.
+//
+// The route is the path the request was matched against, e.g. /devices/:id, rather than the
+// path requested. It must be registered before the routes it measures.
+func (m *Metrics) Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		route := c.Route().Path
+		// The error is turned into the response by the error handler only after the middleware returns
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+				// The router answers 404 and 405 itself when no route matches, the handlers of the API never do
+				if status == fiber.StatusNotFound || status == fiber.StatusMethodNotAllowed {
+					route = unmatchedRoute
+				}
+			}
+		}
+
+		// The method is backed by the request buffer, which is reused once the request is done
+		labels := []string{utils.CopyString(c.Method()), route, strconv.Itoa(status)}
+		m.requests.WithLabelValues(labels...).Inc()
+		m.requestDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PublishMeasurements counts the stored readings and measurements per device and, when enabled,
+// sets the latest value of each parameter. It is a measurement.ReadingPublisherInterface of the pipeline.
+//
+// ctx: the context for the operation.
+// measurements: the stored measurements.
+func (m *Metrics) PublishMeasurements(ctx context.Context, measurements []domain.Measurement) {
+	readings := map[primitive.ObjectID]bool{}
+	for _, measurement := range measurements {
+		deviceID := measurement.DeviceID.Hex()
+		if !readings[measurement.ReadingID] {
+			readings[measurement.ReadingID] = true
+			m.readings.WithLabelValues(deviceID).Inc()
+		}
+		m.measurements.WithLabelValues(deviceID).Inc()
+		if m.values != nil {
+			m.values.WithLabelValues(deviceID, measurement.Parameter).Set(measurement.Value)
+		}
+	}
+}
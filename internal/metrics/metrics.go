@@ -0,0 +1,119 @@
+// Package metrics exposes the Prometheus metrics of the API on /metrics.
+//
+// It counts and times the HTTP requests by route and the MongoDB commands by collection,
+// counts the ingested readings per device, reports the depth of the queues and optionally
+// the latest value of each parameter per device.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/internal/logging"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// log is the logger of the package, its level is configured under "metrics"
+var log = logging.Logger("metrics")
+
+// Endpoint is the route the metrics are scraped from
+const Endpoint = "/metrics"
+
+// namespace prefixes the name of every metric of the API
+const namespace = "mrt"
+
+// Metrics holds the collectors of the API on a registry of its own.
+type Metrics struct {
+	registry        *prometheus.Registry
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	mongoDuration   *prometheus.HistogramVec
+	mongoErrors     *prometheus.CounterVec
+	readings        *prometheus.CounterVec
+	measurements    *prometheus.CounterVec
+	values          *prometheus.GaugeVec
+	queues          *queueCollector
+	monitor         *commandMonitor
+}
+
+// New creates the collectors and registers them along with the Go runtime and process collectors.
+//
+// Parameters:
+// - cfg: The metrics configuration, it tells whether the latest parameter values are exposed.
+//
+// Returns:
+// - A pointer to the newly created Metrics instance.
+func New(cfg config.MetricsConfig) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Duration of the HTTP requests by method, route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		mongoDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "mongo_command_duration_seconds",
+			Help:      "Duration of the MongoDB commands by collection and command.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		}, []string{"collection", "command"}),
+		mongoErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "mongo_command_errors_total",
+			Help:      "Number of failed MongoDB commands by collection and command.",
+		}, []string{"collection", "command"}),
+		readings: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "readings_ingested_total",
+			Help:      "Number of readings stored by the ingestion pipeline by device.",
+		}, []string{"device_id"}),
+		measurements: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "measurements_ingested_total",
+			Help:      "Number of measurements stored by the ingestion pipeline by device.",
+		}, []string{"device_id"}),
+		queues: &queueCollector{desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "queue_depth"),
+			"Number of items waiting in a queue.",
+			[]string{"queue"}, nil,
+		)},
+	}
+	m.monitor = &commandMonitor{metrics: m}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests, m.requestDuration, m.mongoDuration, m.mongoErrors, m.readings, m.measurements, m.queues,
+	)
+	if cfg.ParameterValues {
+		m.values = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "parameter_value",
+			Help:      "Latest value of a parameter by device, in the unit the parameter is stored in.",
+		}, []string{"device_id", "parameter"})
+		m.registry.MustRegister(m.values)
+	}
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// NewHandler registers the metrics endpoint on the Fiber app.
+//
+// app: the Fiber app.
+// m: the metrics to serve.
+func NewHandler(app *fiber.App, m *Metrics) {
+	app.Get(Endpoint, adaptor.HTTPHandler(m.Handler()))
+}
@@ -0,0 +1,128 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/metrics"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// newApp returns an app measured by m, serving its metrics
+func newApp(m *metrics.Metrics) *fiber.App {
+	app := fiber.New()
+	app.Use(m.Middleware())
+	metrics.NewHandler(app, m)
+	return app
+}
+
+// scrape returns the metrics served by the app
+func scrape(t *testing.T, app *fiber.App) string {
+	resp, err := app.Test(httptest.NewRequest("GET", metrics.Endpoint, nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMiddleware(t *testing.T) {
+	m := metrics.New(config.MetricsConfig{})
+	app := newApp(m)
+	app.Get("/devices/:id", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Post("/devices", func(c *fiber.Ctx) error {
+		return errors.New("database down")
+	})
+
+	for _, request := range []struct{ method, path string }{
+		{"GET", "/devices/1"},
+		{"GET", "/devices/2"},
+		{"POST", "/devices"},
+		{"GET", "/unknown/path"},
+	} {
+		_, err := app.Test(httptest.NewRequest(request.method, request.path, nil))
+		require.NoError(t, err)
+	}
+
+	body := scrape(t, app)
+	assert.Contains(t, body, `mrt_http_requests_total{method="GET",route="/devices/:id",status="200"} 2`)
+	assert.Contains(t, body, `mrt_http_requests_total{method="POST",route="/devices",status="500"} 1`)
+	assert.Contains(t, body, `mrt_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, body, `mrt_http_request_duration_seconds_count{method="GET",route="/devices/:id",status="200"} 2`)
+}
+
+func TestCommandMonitor(t *testing.T) {
+	m := metrics.New(config.MetricsConfig{})
+	monitor := m.CommandMonitor()
+	ctx := context.Background()
+
+	command, err := bson.Marshal(bson.D{{Key: "find", Value: "devices"}, {Key: "filter", Value: bson.D{}}})
+	require.NoError(t, err)
+	monitor.Started(ctx, &event.CommandStartedEvent{Command: command, CommandName: "find", RequestID: 1})
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 1, Duration: 3 * time.Millisecond}})
+
+	command, err = bson.Marshal(bson.D{{Key: "getMore", Value: int64(42)}, {Key: "collection", Value: "measurements"}})
+	require.NoError(t, err)
+	monitor.Started(ctx, &event.CommandStartedEvent{Command: command, CommandName: "getMore", RequestID: 2})
+	monitor.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "getMore", RequestID: 2, Duration: time.Second}})
+
+	body := scrape(t, newApp(m))
+	assert.Contains(t, body, `mrt_mongo_command_duration_seconds_count{collection="devices",command="find"} 1`)
+	assert.Contains(t, body, `mrt_mongo_command_duration_seconds_count{collection="measurements",command="getMore"} 1`)
+	assert.Contains(t, body, `mrt_mongo_command_errors_total{collection="measurements",command="getMore"} 1`)
+	assert.NotContains(t, body, `mrt_mongo_command_errors_total{collection="devices"`)
+}
+
+func TestPublishMeasurements(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	readingID := primitive.NewObjectID()
+	measurements := []domain.Measurement{
+		{ReadingID: readingID, DeviceID: deviceID, Parameter: domain.ParamPH, Value: 7.2},
+		{ReadingID: readingID, DeviceID: deviceID, Parameter: domain.ParamTemperature, Value: 23.5},
+	}
+
+	t.Run("Counts", func(t *testing.T) {
+		m := metrics.New(config.MetricsConfig{})
+		m.PublishMeasurements(context.Background(), measurements)
+
+		body := scrape(t, newApp(m))
+		assert.Contains(t, body, `mrt_readings_ingested_total{device_id="`+deviceID.Hex()+`"} 1`)
+		assert.Contains(t, body, `mrt_measurements_ingested_total{device_id="`+deviceID.Hex()+`"} 2`)
+		assert.NotContains(t, body, "mrt_parameter_value")
+	})
+	t.Run("Parameter values", func(t *testing.T) {
+		m := metrics.New(config.MetricsConfig{ParameterValues: true})
+		m.PublishMeasurements(context.Background(), measurements)
+
+		body := scrape(t, newApp(m))
+		assert.Contains(t, body, `mrt_parameter_value{device_id="`+deviceID.Hex()+`",parameter="pH"} 7.2`)
+		assert.Contains(t, body, `mrt_parameter_value{device_id="`+deviceID.Hex()+`",parameter="Temperature"} 23.5`)
+	})
+}
+
+func TestAddQueue(t *testing.T) {
+	m := metrics.New(config.MetricsConfig{})
+	m.AddQueue("device_commands", func(context.Context) (int, error) {
+		return 3, nil
+	})
+	m.AddQueue("edge", func(context.Context) (int, error) {
+		return 0, errors.New("queue closed")
+	})
+
+	body := scrape(t, newApp(m))
+	assert.Contains(t, body, `mrt_queue_depth{queue="device_commands"} 3`)
+	assert.NotContains(t, body, `queue="edge"`)
+}
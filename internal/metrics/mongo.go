@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// commandMonitor times the MongoDB commands, each repository uses a collection of its own so
+// the collection tells the repository and the command its operation.
+type commandMonitor struct {
+	metrics *Metrics
+	// inFlight keeps the labels of the commands in flight by request ID, the finished events only carry the command name
+	inFlight sync.Map
+}
+
+// commandLabels are the labels of a command in flight
+type commandLabels struct {
+	collection string
+	command    string
+}
+
+// CommandMonitor returns the monitor to set on the MongoDB client options.
+func (m *Metrics) CommandMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started:   m.monitor.started,
+		Succeeded: m.monitor.succeeded,
+		Failed:    m.monitor.failed,
+	}
+}
+
+// started keeps the labels of a command until it finishes.
+func (cm *commandMonitor) started(_ context.Context, e *event.CommandStartedEvent) {
+	cm.inFlight.Store(e.RequestID, commandLabels{collection: collectionOf(e.CommandName, e.Command), command: e.CommandName})
+}
+
+// succeeded times a command that succeeded.
+func (cm *commandMonitor) succeeded(_ context.Context, e *event.CommandSucceededEvent) {
+	cm.observe(e.CommandFinishedEvent, false)
+}
+
+// failed times and counts a command that failed.
+func (cm *commandMonitor) failed(_ context.Context, e *event.CommandFailedEvent) {
+	cm.observe(e.CommandFinishedEvent, true)
+}
+
+// observe records the duration of a finished command and counts it when it failed.
+func (cm *commandMonitor) observe(e event.CommandFinishedEvent, failed bool) {
+	labels := commandLabels{command: e.CommandName}
+	if started, ok := cm.inFlight.LoadAndDelete(e.RequestID); ok {
+		labels = started.(commandLabels)
+	}
+	cm.metrics.mongoDuration.WithLabelValues(labels.collection, labels.command).Observe(e.Duration.Seconds())
+	if failed {
+		cm.metrics.mongoErrors.WithLabelValues(labels.collection, labels.command).Inc()
+	}
+}
+
+// collectionOf returns the collection a command runs on, empty for the commands on the database.
+//
+// The collection is the value of the command name for most commands, e.g. {find: "devices"},
+// getMore names it in a field of its own.
+func collectionOf(commandName string, command bson.Raw) string {
+	key := commandName
+	if commandName == "getMore" {
+		key = "collection"
+	}
+	value, err := command.LookupErr(key)
+	if err != nil {
+		return ""
+	}
+	collection, ok := value.StringValueOK()
+	if !ok {
+		return ""
+	}
+	return collection
+}
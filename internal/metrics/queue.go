@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// queueTimeout bounds the lookup of the depth of each queue on a scrape
+const queueTimeout = 5 * time.Second
+
+// DepthFunc returns the number of items waiting in a queue.
+type DepthFunc func(ctx context.Context) (int, error)
+
+// queueCollector looks the depth of the queues up on each scrape.
+type queueCollector struct {
+	desc   *prometheus.Desc
+	mu     sync.Mutex
+	names  []string
+	depths map[string]DepthFunc
+}
+
+// AddQueue reports the depth of a queue as mrt_queue_depth{queue="name"}.
+//
+// A queue whose depth cannot be looked up is left out of the scrape.
+//
+// name: the name of the queue.
+// depth: the lookup of the depth.
+func (m *Metrics) AddQueue(name string, depth DepthFunc) {
+	m.queues.mu.Lock()
+	defer m.queues.mu.Unlock()
+	if m.queues.depths == nil {
+		m.queues.depths = map[string]DepthFunc{}
+	}
+	if _, ok := m.queues.depths[name]; !ok {
+		m.queues.names = append(m.queues.names, name)
+	}
+	m.queues.depths[name] = depth
+}
+
+// Describe sends the descriptor of the queue depth.
+func (q *queueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- q.desc
+}
+
+// Collect looks the depth of each queue up.
+func (q *queueCollector) Collect(ch chan<- prometheus.Metric) {
+	q.mu.Lock()
+	names := append([]string(nil), q.names...)
+	depths := make([]DepthFunc, len(names))
+	for i, name := range names {
+		depths[i] = q.depths[name]
+	}
+	q.mu.Unlock()
+
+	for i, name := range names {
+		ctx, cancel := context.WithTimeout(context.Background(), queueTimeout)
+		depth, err := depths[i](ctx)
+		cancel()
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(q.desc, prometheus.GaugeValue, float64(depth), name)
+	}
+}
@@ -0,0 +1,224 @@
+package modbus
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/goburrow/serial"
+)
+
+// Function codes of the register reads
+const (
+	readHoldingRegisters = 0x03
+	readInputRegisters   = 0x04
+)
+
+// ErrException is returned when the instrument answers a request with a Modbus exception
+var ErrException = errors.New("modbus exception")
+
+// framer encodes the requests and decodes the responses of a transport.
+type framer interface {
+	// send writes the PDU of a request to the unit
+	send(w io.Writer, unitID byte, pdu []byte) error
+	// receive reads the PDU of the response
+	receive(r io.Reader, unitID byte) ([]byte, error)
+}
+
+// deadliner is a connection bounding its requests with a deadline, a serial port bounds each read
+// with its own timeout instead.
+type deadliner interface {
+	SetDeadline(t time.Time) error
+}
+
+// Client reads the registers of Modbus instruments over TCP or RTU.
+//
+// A client is safe for concurrent use, requests are sent one at a time as a serial line requires.
+type Client struct {
+	mu      sync.Mutex
+	conn    io.ReadWriteCloser
+	framer  framer
+	timeout time.Duration
+}
+
+// Dial opens a connection to a Modbus endpoint.
+//
+// Parameters:
+// - endpoint: the transport, the address (host:port for TCP, the serial device for RTU) and the
+// line settings of an RTU endpoint.
+// - timeout: the time a request waits for its response at most, for RTU the time each read waits
+// for the next bytes.
+// Returns a pointer to a Client and an error if the endpoint could not be opened.
+func Dial(endpoint domain.ModbusEndpoint, timeout time.Duration) (*Client, error) {
+	switch endpoint.Transport {
+	case domain.ModbusTCP:
+		c, err := net.DialTimeout("tcp", endpoint.Address, timeout)
+		if err != nil {
+			return nil, err
+		}
+		return &Client{conn: c, framer: &tcpFramer{}, timeout: timeout}, nil
+	case domain.ModbusRTU:
+		if err := endpoint.Serial.Validate(); err != nil {
+			return nil, err
+		}
+		// A silent unit fails the read once the timeout elapsed instead of blocking the line
+		port, err := serial.Open(&serial.Config{
+			Address:  endpoint.Address,
+			BaudRate: endpoint.Serial.BaudRate,
+			DataBits: endpoint.Serial.DataBits,
+			Parity:   endpoint.Serial.Parity,
+			StopBits: endpoint.Serial.StopBits,
+			Timeout:  timeout,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &Client{conn: port, framer: rtuFramer{}, timeout: timeout}, nil
+	}
+	return nil, fmt.Errorf("%w: unknown transport %q", domain.ErrInvalidModbus, endpoint.Transport)
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// ReadRegisters reads consecutive registers of a unit.
+//
+// Parameters:
+// - unitID: the address of the instrument on the bus or behind the gateway.
+// - table: domain.ModbusHolding or domain.ModbusInput, empty reads the holding registers.
+// - address: the first register.
+// - count: the number of registers, at most domain.ModbusMaxRegisters.
+// Returns the registers and an error, ErrException when the instrument refused the request.
+func (c *Client) ReadRegisters(unitID byte, table string, address, count uint16) ([]uint16, error) {
+	function := byte(readHoldingRegisters)
+	if table == domain.ModbusInput {
+		function = readInputRegisters
+	}
+	if count == 0 || count > domain.ModbusMaxRegisters {
+		return nil, fmt.Errorf("%w: cannot read %d registers at once", domain.ErrInvalidModbus, count)
+	}
+	pdu := []byte{function, 0, 0, 0, 0}
+	binary.BigEndian.PutUint16(pdu[1:], address)
+	binary.BigEndian.PutUint16(pdu[3:], count)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d, ok := c.conn.(deadliner); ok && c.timeout > 0 {
+		if err := d.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.framer.send(c.conn, unitID, pdu); err != nil {
+		return nil, err
+	}
+	response, err := c.framer.receive(c.conn, unitID)
+	if err != nil {
+		return nil, err
+	}
+
+	if response[0] == function|0x80 {
+		return nil, fmt.Errorf("%w: code %d", ErrException, response[1])
+	}
+	if response[0] != function || int(response[1]) != 2*int(count) || len(response) != 2+2*int(count) {
+		return nil, fmt.Errorf("unexpected modbus response % x", response)
+	}
+	registers := make([]uint16, count)
+	for i := range registers {
+		registers[i] = binary.BigEndian.Uint16(response[2+2*i:])
+	}
+	return registers, nil
+}
+
+// tcpFramer frames the PDUs with the MBAP header of Modbus TCP.
+type tcpFramer struct {
+	transaction uint16
+}
+
+func (f *tcpFramer) send(w io.Writer, unitID byte, pdu []byte) error {
+	f.transaction++
+	adu := make([]byte, 7, 7+len(pdu))
+	binary.BigEndian.PutUint16(adu, f.transaction)
+	// The protocol identifier is 0 for Modbus, the length counts the unit ID and the PDU
+	binary.BigEndian.PutUint16(adu[4:], uint16(1+len(pdu)))
+	adu[6] = unitID
+	_, err := w.Write(append(adu, pdu...))
+	return err
+}
+
+func (f *tcpFramer) receive(r io.Reader, unitID byte) ([]byte, error) {
+	for {
+		header := make([]byte, 7)
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, err
+		}
+		length := binary.BigEndian.Uint16(header[4:])
+		if length < 3 || length > 254 {
+			return nil, fmt.Errorf("invalid modbus tcp length %d", length)
+		}
+		pdu := make([]byte, length-1)
+		if _, err := io.ReadFull(r, pdu); err != nil {
+			return nil, err
+		}
+		// A late answer to a request that timed out is skipped
+		if binary.BigEndian.Uint16(header) == f.transaction && header[6] == unitID {
+			return pdu, nil
+		}
+	}
+}
+
+// rtuFramer frames the PDUs with the unit ID and the CRC of Modbus RTU.
+type rtuFramer struct{}
+
+func (rtuFramer) send(w io.Writer, unitID byte, pdu []byte) error {
+	adu := append([]byte{unitID}, pdu...)
+	crc := crc16(adu)
+	_, err := w.Write(append(adu, byte(crc), byte(crc>>8)))
+	return err
+}
+
+func (rtuFramer) receive(r io.Reader, unitID byte) ([]byte, error) {
+	// The unit ID, the function code and either the byte count or the exception code
+	adu := make([]byte, 3, 5+255)
+	if _, err := io.ReadFull(r, adu); err != nil {
+		return nil, err
+	}
+	rest := 2
+	if adu[1]&0x80 == 0 {
+		rest += int(adu[2])
+	}
+	adu = adu[:3+rest]
+	if _, err := io.ReadFull(r, adu[3:]); err != nil {
+		return nil, err
+	}
+	n := len(adu) - 2
+	if crc := crc16(adu[:n]); byte(crc) != adu[n] || byte(crc>>8) != adu[n+1] {
+		return nil, errors.New("invalid modbus rtu crc")
+	}
+	if adu[0] != unitID {
+		return nil, fmt.Errorf("modbus rtu answer from unit %d instead of %d", adu[0], unitID)
+	}
+	return adu[1:n], nil
+}
+
+// crc16 computes the Modbus CRC of a frame.
+func crc16(data []byte) uint16 {
+	crc := uint16(0xffff)
+	for _, b := range data {
+		crc ^= uint16(b)
+		for i := 0; i < 8; i++ {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0xa001
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return crc
+}
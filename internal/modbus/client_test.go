@@ -0,0 +1,59 @@
+package modbus_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/modbus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientReadRegisters(t *testing.T) {
+	simulator, err := modbus.NewSimulator("127.0.0.1:0")
+	require.NoError(t, err)
+	defer simulator.Close()
+	bits := math.Float32bits(7.25)
+	simulator.SetRegisters(domain.ModbusHolding, 100, uint16(bits>>16), uint16(bits))
+	simulator.SetRegisters(domain.ModbusInput, 0, 1850)
+
+	client, err := modbus.Dial(domain.ModbusEndpoint{Transport: domain.ModbusTCP, Address: simulator.Address()}, time.Second)
+	require.NoError(t, err)
+	defer client.Close()
+
+	t.Run("Holding registers", func(t *testing.T) {
+		registers, err := client.ReadRegisters(1, domain.ModbusHolding, 100, 2)
+		assert.NoError(t, err)
+		value, err := domain.ModbusRegister{Parameter: domain.ParamPH, DataType: domain.ModbusFloat32}.Decode(registers)
+		assert.NoError(t, err)
+		assert.Equal(t, 7.25, value)
+	})
+	t.Run("Input registers", func(t *testing.T) {
+		registers, err := client.ReadRegisters(1, domain.ModbusInput, 0, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []uint16{1850}, registers)
+	})
+	t.Run("Exception", func(t *testing.T) {
+		_, err := client.ReadRegisters(1, domain.ModbusHolding, 500, 1)
+		assert.ErrorIs(t, err, modbus.ErrException)
+		// The connection is still usable after an exception
+		_, err = client.ReadRegisters(1, domain.ModbusInput, 0, 1)
+		assert.NoError(t, err)
+	})
+	t.Run("Too many registers", func(t *testing.T) {
+		_, err := client.ReadRegisters(1, domain.ModbusHolding, 0, 126)
+		assert.ErrorIs(t, err, domain.ErrInvalidModbus)
+	})
+}
+
+func TestDialUnknownTransport(t *testing.T) {
+	_, err := modbus.Dial(domain.ModbusEndpoint{Transport: "udp", Address: "127.0.0.1:502"}, time.Second)
+	assert.ErrorIs(t, err, domain.ErrInvalidModbus)
+}
+
+func TestDialInvalidSerialLine(t *testing.T) {
+	_, err := modbus.Dial(domain.ModbusEndpoint{Transport: domain.ModbusRTU, Address: "/dev/ttyUSB0", Serial: domain.ModbusSerial{Parity: "X"}}, time.Second)
+	assert.ErrorIs(t, err, domain.ErrInvalidModbus)
+}
@@ -0,0 +1,27 @@
+package modbus
+
+import (
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/polling"
+)
+
+// Dialer opens the Modbus connections of the polling.
+type Dialer struct {
+	// Timeout bounds the connection and each register read
+	Timeout time.Duration
+}
+
+// Dial opens a connection to a Modbus endpoint.
+//
+// endpoint: the transport, address and line settings of the endpoint.
+//
+// Returns the connection and an error if the endpoint could not be opened.
+func (d Dialer) Dial(endpoint domain.ModbusEndpoint) (polling.RegisterReaderInterface, error) {
+	client, err := Dial(endpoint, d.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
@@ -0,0 +1,149 @@
+package modbus
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+)
+
+// Exception codes answered by the simulator
+const (
+	illegalFunction = 0x01
+	illegalAddress  = 0x02
+)
+
+// Simulator is a Modbus TCP server answering register reads from memory, for testing the polling
+// against an instrument without hardware.
+//
+// Every unit ID reads the same registers, a register never set is an illegal address.
+type Simulator struct {
+	mu        sync.Mutex
+	registers map[string]map[uint16]uint16
+	listener  net.Listener
+}
+
+// NewSimulator creates a new Simulator listening on a TCP address.
+//
+// Parameters:
+// - address: the address to listen on, e.g. 127.0.0.1:0 for a free port.
+// Returns a pointer to a Simulator and an error if the address could not be listened on.
+func NewSimulator(address string) (*Simulator, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	s := &Simulator{
+		registers: map[string]map[uint16]uint16{domain.ModbusHolding: {}, domain.ModbusInput: {}},
+		listener:  listener,
+	}
+	go s.serve()
+	return s, nil
+}
+
+// Address returns the address the simulator listens on.
+func (s *Simulator) Address() string {
+	return s.listener.Addr().String()
+}
+
+// Close stops the simulator.
+func (s *Simulator) Close() error {
+	return s.listener.Close()
+}
+
+// SetRegisters sets consecutive registers.
+//
+// table: domain.ModbusHolding or domain.ModbusInput.
+// address: the first register.
+// values: the register values.
+func (s *Simulator) SetRegisters(table string, address uint16, values ...uint16) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, value := range values {
+		s.registers[table][address+uint16(i)] = value
+	}
+}
+
+// serve accepts connections until the simulator is closed.
+func (s *Simulator) serve() {
+	for {
+		c, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(c)
+	}
+}
+
+// handle answers the requests of a connection.
+func (s *Simulator) handle(c net.Conn) {
+	defer c.Close()
+	for {
+		header := make([]byte, 7)
+		if _, err := io.ReadFull(c, header); err != nil {
+			return
+		}
+		length := binary.BigEndian.Uint16(header[4:])
+		if length < 2 {
+			return
+		}
+		pdu := make([]byte, length-1)
+		if _, err := io.ReadFull(c, pdu); err != nil {
+			return
+		}
+		response := s.answer(pdu)
+		binary.BigEndian.PutUint16(header[4:], uint16(1+len(response)))
+		if _, err := c.Write(append(header, response...)); err != nil {
+			return
+		}
+	}
+}
+
+// answer returns the response PDU of a request PDU.
+func (s *Simulator) answer(pdu []byte) []byte {
+	var table string
+	switch pdu[0] {
+	case readHoldingRegisters:
+		table = domain.ModbusHolding
+	case readInputRegisters:
+		table = domain.ModbusInput
+	default:
+		return []byte{pdu[0] | 0x80, illegalFunction}
+	}
+	if len(pdu) != 5 {
+		return []byte{pdu[0] | 0x80, illegalAddress}
+	}
+	address := binary.BigEndian.Uint16(pdu[1:])
+	count := binary.BigEndian.Uint16(pdu[3:])
+	registers, err := s.read(table, address, count)
+	if err != nil {
+		return []byte{pdu[0] | 0x80, illegalAddress}
+	}
+	response := make([]byte, 2+2*len(registers))
+	response[0], response[1] = pdu[0], byte(2*len(registers))
+	for i, register := range registers {
+		binary.BigEndian.PutUint16(response[2+2*i:], register)
+	}
+	return response
+}
+
+// read returns consecutive registers, an error if one was never set.
+func (s *Simulator) read(table string, address, count uint16) ([]uint16, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if count == 0 || count > domain.ModbusMaxRegisters {
+		return nil, errors.New("invalid count")
+	}
+	registers := make([]uint16, count)
+	for i := range registers {
+		value, ok := s.registers[table][address+uint16(i)]
+		if !ok {
+			return nil, errors.New("illegal address")
+		}
+		registers[i] = value
+	}
+	return registers, nil
+}
@@ -0,0 +1,159 @@
+package mqtt
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/internal/logging"
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/sirupsen/logrus"
+)
+
+// log is the logger of the package, its level is configured under "mqtt"
+var log = logging.Logger("mqtt")
+
+// Handler processes a message received on a topic, the topic is relative to the topic prefix
+type Handler func(ctx context.Context, topic string, payload []byte) error
+
+// Router is the interface that wraps the Handle and Publish methods of the broker.
+type Router interface {
+	Handle(topic string, handler Handler)
+	Publish(topic string, retained bool, payload []byte) error
+}
+
+// Broker is the MQTT connection shared by the device facing handlers.
+//
+// Topics given to Handle and Publish are relative to the configured topic prefix.
+type Broker struct {
+	client  paho.Client
+	config  config.MQTTConfig
+	mu      sync.Mutex
+	routes  map[string]Handler
+	timeout time.Duration
+}
+
+// NewBroker creates a new Broker, Connect must be called once the handlers are registered.
+//
+// Parameters:
+// - config: the broker address, credentials, topic prefix and QoS.
+// Returns a pointer to a Broker.
+func NewBroker(config config.MQTTConfig) *Broker {
+	b := &Broker{
+		config:  config,
+		routes:  map[string]Handler{},
+		timeout: config.Timeout,
+	}
+	if b.timeout <= 0 {
+		b.timeout = 5 * time.Second
+	}
+
+	options := paho.NewClientOptions().
+		AddBroker(config.Broker).
+		SetClientID(config.ClientID).
+		SetUsername(config.Username).
+		SetPassword(config.Password).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetConnectionLostHandler(func(_ paho.Client, err error) {
+			log.Warnf("mqtt connection lost: %v", err)
+		}).
+		// Subscriptions are made again on every connection since the session is not persisted
+		SetOnConnectHandler(b.subscribe)
+	b.client = paho.NewClient(options)
+	return b
+}
+
+// Handle registers the handler of a topic, the topic may contain + and # wildcards.
+//
+// topic: the topic relative to the topic prefix.
+// handler: the function processing the messages.
+func (b *Broker) Handle(topic string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.routes[topic] = handler
+}
+
+// Publish sends a message to a topic.
+//
+// topic: the topic relative to the topic prefix.
+// retained: whether the broker keeps the message for the next subscribers.
+// payload: the message body, an empty retained payload clears the retained message.
+//
+// Returns an error if the message was refused, a message still pending after the timeout stays
+// queued until the connection is back.
+func (b *Broker) Publish(topic string, retained bool, payload []byte) error {
+	token := b.client.Publish(b.topic(topic), b.config.QoS, retained, payload)
+	if !token.WaitTimeout(b.timeout) {
+		log.Warnf("mqtt publication to %s queued until the broker is reachable", b.topic(topic))
+		return nil
+	}
+	if err := token.Error(); err != nil {
+		log.Error(err)
+		return err
+	}
+	return nil
+}
+
+// Connect connects to the broker and subscribes the registered handlers.
+//
+// The broker keeps retrying in the background when it cannot be reached in time, messages
+// published meanwhile are sent once connected.
+func (b *Broker) Connect() {
+	token := b.client.Connect()
+	if !token.WaitTimeout(b.timeout) {
+		log.Warnf("mqtt broker %s not reachable yet, retrying in the background", b.config.Broker)
+		return
+	}
+	if err := token.Error(); err != nil {
+		log.Error(err)
+	}
+}
+
+// Disconnect closes the connection to the broker.
+func (b *Broker) Disconnect() {
+	b.client.Disconnect(uint(b.timeout.Milliseconds()))
+}
+
+// Check returns an error while the connection to the broker is down, e.g. while reconnecting.
+func (b *Broker) Check(context.Context) error {
+	if !b.client.IsConnectionOpen() {
+		return fmt.Errorf("not connected to mqtt broker %s", b.config.Broker)
+	}
+	return nil
+}
+
+// subscribe subscribes every registered handler.
+func (b *Broker) subscribe(client paho.Client) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for topic, handler := range b.routes {
+		handler := handler
+		token := client.Subscribe(b.topic(topic), b.config.QoS, func(_ paho.Client, msg paho.Message) {
+			topic := strings.TrimPrefix(msg.Topic(), b.prefix())
+			ctx := logging.WithFields(context.Background(), logrus.Fields{"topic": msg.Topic()})
+			if err := handler(ctx, topic, msg.Payload()); err != nil {
+				log.WithContext(ctx).Errorf("mqtt %s: %v", msg.Topic(), err)
+			}
+		})
+		if token.WaitTimeout(b.timeout) && token.Error() != nil {
+			log.Error(token.Error())
+		}
+	}
+}
+
+// topic prefixes a relative topic.
+func (b *Broker) topic(topic string) string {
+	return b.prefix() + topic
+}
+
+// prefix returns the topic prefix followed by a separator, or nothing when there is no prefix.
+func (b *Broker) prefix() string {
+	if b.config.TopicPrefix == "" {
+		return ""
+	}
+	return strings.TrimSuffix(b.config.TopicPrefix, "/") + "/"
+}
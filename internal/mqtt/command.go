@@ -0,0 +1,98 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Command topics relative to the topic prefix, the device ID is the first level of the topic
+const (
+	CommandReplyTopic = "+/commands/reply"
+	commandSuffix     = "/commands"
+)
+
+// CommandService is the interface that wraps the Reply method.
+type CommandService interface {
+	Reply(ctx context.Context, deviceID primitive.ObjectID, reply domain.CommandReply) (*domain.Command, error)
+}
+
+// CommandHandler is the handler of the command replies of the devices
+type CommandHandler struct {
+	service CommandService
+}
+
+// NewCommandHandler registers the command reply handler on the broker.
+//
+// Devices publish a domain.CommandReply to {device_id}/commands/reply when they acknowledge a
+// command and again when they report its result.
+//
+// Parameters:
+// - router: The broker the handler subscribes on.
+// - service: The CommandService instance.
+//
+// Return type: None.
+func NewCommandHandler(router Router, service CommandService) {
+	handler := &CommandHandler{service: service}
+	router.Handle(CommandReplyTopic, handler.Reply)
+}
+
+// Reply records the acknowledgement or the result of a command.
+//
+// ctx: the context for the operation.
+// topic: the topic the reply was received on.
+// payload: the domain.CommandReply JSON document.
+//
+// Returns an error if the topic or the payload is invalid or the reply could not be stored.
+func (h *CommandHandler) Reply(ctx context.Context, topic string, payload []byte) error {
+	deviceID, err := primitive.ObjectIDFromHex(strings.SplitN(topic, "/", 2)[0])
+	if err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidCommand, err)
+	}
+	reply := domain.CommandReply{}
+	if err = json.Unmarshal(payload, &reply); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidCommand, err)
+	}
+	_, err = h.service.Reply(ctx, deviceID, reply)
+	return err
+}
+
+// CommandPublisher delivers the commands to the devices
+type CommandPublisher struct {
+	router Router
+}
+
+// NewCommandPublisher creates a new CommandPublisher.
+//
+// Parameters:
+// - router: The broker the commands are published on.
+// Returns a pointer to a CommandPublisher.
+func NewCommandPublisher(router Router) *CommandPublisher {
+	return &CommandPublisher{router: router}
+}
+
+// PublishCommand publishes a command to {device_id}/commands as a domain.CommandMessage.
+//
+// Commands are not retained, a device must not run a command again when it reconnects, and
+// devices ignore the commands received after their expiry.
+//
+// ctx: the context for the operation.
+// c: the command to deliver.
+//
+// Returns an error if the command could not be published.
+func (p *CommandPublisher) PublishCommand(ctx context.Context, c domain.Command) error {
+	payload, err := json.Marshal(domain.CommandMessage{
+		ID:        c.ID,
+		Name:      c.Name,
+		Params:    c.Params,
+		ExpiresAt: c.ExpiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	return p.router.Publish(c.DeviceID.Hex()+commandSuffix, false, payload)
+}
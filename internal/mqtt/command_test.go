@@ -0,0 +1,80 @@
+package mqtt_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/mqtt"
+	"github.com/anggi-susanto/mrt-go/internal/mqtt/mocks"
+)
+
+// registeredCommandHandler registers the command handler on a mocked router and returns the handler of the reply topic.
+func registeredCommandHandler(t *testing.T, service mqtt.CommandService) mqtt.Handler {
+	var handler mqtt.Handler
+	router := new(mocks.Router)
+	router.On("Handle", mqtt.CommandReplyTopic, mock.Anything).Run(func(args mock.Arguments) {
+		handler = args.Get(1).(mqtt.Handler)
+	}).Return()
+	mqtt.NewCommandHandler(router, service)
+	router.AssertExpectations(t)
+	return handler
+}
+
+func TestCommandHandlerReply(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	commandID := primitive.NewObjectID()
+	topic := deviceID.Hex() + "/commands/reply"
+	payload := []byte(`{"id":"` + commandID.Hex() + `","status":"succeeded","result":{"volume":250}}`)
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(mocks.CommandService)
+		mockService.On("Reply", mock.Anything, deviceID, domain.CommandReply{
+			CommandID: commandID,
+			Status:    domain.CommandSucceeded,
+			Result:    map[string]interface{}{"volume": 250.0},
+		}).Return(&domain.Command{}, nil)
+		handler := registeredCommandHandler(t, mockService)
+		assert.NoError(t, handler(context.Background(), topic, payload))
+		mockService.AssertExpectations(t)
+	})
+	t.Run("Invalid device", func(t *testing.T) {
+		handler := registeredCommandHandler(t, new(mocks.CommandService))
+		assert.ErrorIs(t, handler(context.Background(), "logger-1/commands/reply", payload), domain.ErrInvalidCommand)
+	})
+	t.Run("Invalid payload", func(t *testing.T) {
+		handler := registeredCommandHandler(t, new(mocks.CommandService))
+		assert.ErrorIs(t, handler(context.Background(), topic, []byte(`done`)), domain.ErrInvalidCommand)
+	})
+	t.Run("Error", func(t *testing.T) {
+		mockService := new(mocks.CommandService)
+		mockService.On("Reply", mock.Anything, deviceID, mock.Anything).Return(nil, errors.New("error"))
+		handler := registeredCommandHandler(t, mockService)
+		assert.Error(t, handler(context.Background(), topic, payload))
+	})
+}
+
+func TestCommandPublisherPublishCommand(t *testing.T) {
+	c := domain.Command{
+		ID:        primitive.NewObjectID(),
+		DeviceID:  primitive.NewObjectID(),
+		Name:      domain.CommandGrabSample,
+		Params:    map[string]interface{}{"bottle": 3.0},
+		Status:    domain.CommandQueued,
+		ExpiresAt: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+	}
+	router := new(mocks.Router)
+	router.On("Publish", c.DeviceID.Hex()+"/commands", false, mock.MatchedBy(func(payload []byte) bool {
+		message := domain.CommandMessage{}
+		return json.Unmarshal(payload, &message) == nil && message.ID == c.ID &&
+			message.Name == c.Name && message.Params["bottle"] == 3.0 && message.ExpiresAt.Equal(c.ExpiresAt)
+	})).Return(nil)
+	assert.NoError(t, mqtt.NewCommandPublisher(router).PublishCommand(context.Background(), c))
+	router.AssertExpectations(t)
+}
@@ -0,0 +1,61 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CommandService is an autogenerated mock type for the CommandService type
+type CommandService struct {
+	mock.Mock
+}
+
+// Reply provides a mock function with given fields: ctx, deviceID, reply
+func (_m *CommandService) Reply(ctx context.Context, deviceID primitive.ObjectID, reply domain.CommandReply) (*domain.Command, error) {
+	ret := _m.Called(ctx, deviceID, reply)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reply")
+	}
+
+	var r0 *domain.Command
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID, domain.CommandReply) (*domain.Command, error)); ok {
+		return rf(ctx, deviceID, reply)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID, domain.CommandReply) *domain.Command); ok {
+		r0 = rf(ctx, deviceID, reply)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Command)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, primitive.ObjectID, domain.CommandReply) error); ok {
+		r1 = rf(ctx, deviceID, reply)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewCommandService creates a new instance of CommandService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCommandService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CommandService {
+	mock := &CommandService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,50 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	mqtt "github.com/anggi-susanto/mrt-go/internal/mqtt"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Router is an autogenerated mock type for the Router type
+type Router struct {
+	mock.Mock
+}
+
+// Handle provides a mock function with given fields: topic, handler
+func (_m *Router) Handle(topic string, handler mqtt.Handler) {
+	_m.Called(topic, handler)
+}
+
+// Publish provides a mock function with given fields: topic, retained, payload
+func (_m *Router) Publish(topic string, retained bool, payload []byte) error {
+	ret := _m.Called(topic, retained, payload)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Publish")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, bool, []byte) error); ok {
+		r0 = rf(topic, retained, payload)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewRouter creates a new instance of Router. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRouter(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Router {
+	mock := &Router{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
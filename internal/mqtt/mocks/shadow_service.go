@@ -0,0 +1,61 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ShadowService is an autogenerated mock type for the ShadowService type
+type ShadowService struct {
+	mock.Mock
+}
+
+// UpdateReported provides a mock function with given fields: ctx, deviceID, update
+func (_m *ShadowService) UpdateReported(ctx context.Context, deviceID primitive.ObjectID, update domain.ShadowUpdate) (*domain.Shadow, error) {
+	ret := _m.Called(ctx, deviceID, update)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateReported")
+	}
+
+	var r0 *domain.Shadow
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID, domain.ShadowUpdate) (*domain.Shadow, error)); ok {
+		return rf(ctx, deviceID, update)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID, domain.ShadowUpdate) *domain.Shadow); ok {
+		r0 = rf(ctx, deviceID, update)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Shadow)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, primitive.ObjectID, domain.ShadowUpdate) error); ok {
+		r1 = rf(ctx, deviceID, update)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewShadowService creates a new instance of ShadowService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewShadowService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ShadowService {
+	mock := &ShadowService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,96 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Shadow topics relative to the topic prefix, the device ID is the first level of the topic
+const (
+	ShadowReportedTopic = "+/shadow/reported"
+	shadowDeltaSuffix   = "/shadow/delta"
+)
+
+// ShadowService is the interface that wraps the UpdateReported method.
+type ShadowService interface {
+	UpdateReported(ctx context.Context, deviceID primitive.ObjectID, update domain.ShadowUpdate) (*domain.Shadow, error)
+}
+
+// ShadowHandler is the handler of the shadow reports of the devices
+type ShadowHandler struct {
+	service ShadowService
+}
+
+// NewShadowHandler registers the shadow report handler on the broker.
+//
+// Devices publish their reported state as a domain.ShadowUpdate to {device_id}/shadow/reported.
+//
+// Parameters:
+// - router: The broker the handler subscribes on.
+// - service: The ShadowService instance.
+//
+// Return type: None.
+func NewShadowHandler(router Router, service ShadowService) {
+	handler := &ShadowHandler{service: service}
+	router.Handle(ShadowReportedTopic, handler.Reported)
+}
+
+// Reported stores the state a device reports.
+//
+// ctx: the context for the operation.
+// topic: the topic the report was received on.
+// payload: the domain.ShadowUpdate JSON document.
+//
+// Returns an error if the topic or the payload is invalid or the report could not be stored.
+func (h *ShadowHandler) Reported(ctx context.Context, topic string, payload []byte) error {
+	deviceID, err := primitive.ObjectIDFromHex(strings.SplitN(topic, "/", 2)[0])
+	if err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidShadow, err)
+	}
+	update := domain.ShadowUpdate{}
+	if err = json.Unmarshal(payload, &update); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidShadow, err)
+	}
+	_, err = h.service.UpdateReported(ctx, deviceID, update)
+	return err
+}
+
+// DeltaPublisher delivers the shadow deltas to the devices
+type DeltaPublisher struct {
+	router Router
+}
+
+// NewDeltaPublisher creates a new DeltaPublisher.
+//
+// Parameters:
+// - router: The broker the deltas are published on.
+// Returns a pointer to a DeltaPublisher.
+func NewDeltaPublisher(router Router) *DeltaPublisher {
+	return &DeltaPublisher{router: router}
+}
+
+// PublishDelta publishes the delta of a device to {device_id}/shadow/delta.
+//
+// The delta is retained so that a logger waking up later still receives it, an empty delta
+// clears the retained message.
+//
+// ctx: the context for the operation.
+// delta: the delta to deliver.
+//
+// Returns an error if the delta could not be published.
+func (p *DeltaPublisher) PublishDelta(ctx context.Context, delta domain.ShadowDelta) error {
+	topic := delta.DeviceID.Hex() + shadowDeltaSuffix
+	if len(delta.State) == 0 {
+		return p.router.Publish(topic, true, nil)
+	}
+	payload, err := json.Marshal(delta)
+	if err != nil {
+		return err
+	}
+	return p.router.Publish(topic, true, payload)
+}
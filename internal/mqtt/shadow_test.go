@@ -0,0 +1,76 @@
+package mqtt_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/mqtt"
+	"github.com/anggi-susanto/mrt-go/internal/mqtt/mocks"
+)
+
+// registeredHandler registers the shadow handler on a mocked router and returns the handler of the reported topic.
+func registeredHandler(t *testing.T, service mqtt.ShadowService) mqtt.Handler {
+	var handler mqtt.Handler
+	router := new(mocks.Router)
+	router.On("Handle", mqtt.ShadowReportedTopic, mock.Anything).Run(func(args mock.Arguments) {
+		handler = args.Get(1).(mqtt.Handler)
+	}).Return()
+	mqtt.NewShadowHandler(router, service)
+	router.AssertExpectations(t)
+	return handler
+}
+
+func TestShadowHandlerReported(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	topic := deviceID.Hex() + "/shadow/reported"
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(mocks.ShadowService)
+		mockService.On("UpdateReported", mock.Anything, deviceID, domain.ShadowUpdate{State: domain.ShadowState{"interval": 60.0}}).Return(&domain.Shadow{}, nil)
+		handler := registeredHandler(t, mockService)
+		assert.NoError(t, handler(context.Background(), topic, []byte(`{"state":{"interval":60}}`)))
+		mockService.AssertExpectations(t)
+	})
+	t.Run("Invalid device", func(t *testing.T) {
+		handler := registeredHandler(t, new(mocks.ShadowService))
+		err := handler(context.Background(), "logger-1/shadow/reported", []byte(`{"state":{"interval":60}}`))
+		assert.ErrorIs(t, err, domain.ErrInvalidShadow)
+	})
+	t.Run("Invalid payload", func(t *testing.T) {
+		handler := registeredHandler(t, new(mocks.ShadowService))
+		assert.ErrorIs(t, handler(context.Background(), topic, []byte(`interval=60`)), domain.ErrInvalidShadow)
+	})
+	t.Run("Error", func(t *testing.T) {
+		mockService := new(mocks.ShadowService)
+		mockService.On("UpdateReported", mock.Anything, deviceID, mock.Anything).Return(nil, errors.New("error"))
+		handler := registeredHandler(t, mockService)
+		assert.Error(t, handler(context.Background(), topic, []byte(`{"state":{"interval":60}}`)))
+	})
+}
+
+func TestDeltaPublisherPublishDelta(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	topic := deviceID.Hex() + "/shadow/delta"
+	t.Run("Delta", func(t *testing.T) {
+		router := new(mocks.Router)
+		router.On("Publish", topic, true, mock.MatchedBy(func(payload []byte) bool {
+			delta := domain.ShadowDelta{}
+			return json.Unmarshal(payload, &delta) == nil && delta.State["interval"] == 60.0 && delta.Version == 3
+		})).Return(nil)
+		delta := domain.ShadowDelta{DeviceID: deviceID, State: domain.ShadowState{"interval": 60.0}, Version: 3}
+		assert.NoError(t, mqtt.NewDeltaPublisher(router).PublishDelta(context.Background(), delta))
+		router.AssertExpectations(t)
+	})
+	t.Run("Caught up", func(t *testing.T) {
+		router := new(mocks.Router)
+		router.On("Publish", topic, true, []byte(nil)).Return(nil)
+		assert.NoError(t, mqtt.NewDeltaPublisher(router).PublishDelta(context.Background(), domain.ShadowDelta{DeviceID: deviceID}))
+		router.AssertExpectations(t)
+	})
+}
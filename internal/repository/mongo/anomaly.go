@@ -0,0 +1,193 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AnomalyRepository is the implementation of the BaselineRepositoryInterface and the EventRepositoryInterface.
+type AnomalyRepository struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+	baselines  *mongo.Collection
+}
+
+// NewAnomalyRepository creates a new AnomalyRepository.
+//
+// The AnomalyRepository is used to interact with the anomaly event and baseline collections in the database.
+//
+// Parameters:
+// - client: a pointer to a mongo.Client.
+// - config: a pointer to a config.MongoConfig.
+// Returns a pointer to an AnomalyRepository.
+func NewAnomalyRepository(client *mongo.Client, config *config.MongoConfig) *AnomalyRepository {
+	// Get the collections from the database
+	database := client.Database(config.Database)
+
+	return &AnomalyRepository{
+		// The client used to interact with the database
+		client: client,
+		// The collection holding the anomaly events
+		collection: database.Collection(config.AnomalyCollection),
+		// The collection holding the rolling statistics of each series
+		baselines: database.Collection(config.BaselineCollection),
+	}
+}
+
+// EnsureIndexes creates the unique index on the baseline series and the event query indexes.
+//
+// ctx: the context for the operation.
+//
+// Returns an error if the indexes could not be created.
+func (r *AnomalyRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "mongo.AnomalyRepository.EnsureIndexes")
+	defer span.End()
+	_, err := r.baselines.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "device_id", Value: 1}, {Key: "parameter", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	_, err = r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "device_id", Value: 1}, {Key: "timestamp", Value: -1}}},
+		{Keys: bson.D{{Key: "measurement_id", Value: 1}}},
+	})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// GetBaseline retrieves the baseline of a parameter of a device.
+//
+// ctx: the context for the operation.
+// deviceID: the device of the series.
+// parameter: the parameter code of the series.
+//
+// Returns the baseline, nil if the series has none yet, and an error, if any.
+func (r *AnomalyRepository) GetBaseline(ctx context.Context, deviceID primitive.ObjectID, parameter string) (*domain.AnomalyBaseline, error) {
+	ctx, span := tracer.Start(ctx, "mongo.AnomalyRepository.GetBaseline")
+	defer span.End()
+	var baseline domain.AnomalyBaseline
+	err := r.baselines.FindOne(ctx, bson.M{"device_id": deviceID, "parameter": parameter}).Decode(&baseline)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return &baseline, nil
+}
+
+// SaveBaseline updates the baseline of its series if it is still at the expected version.
+//
+// The baseline is created when the expected version is 0 and the series has no baseline yet.
+//
+// ctx: the context for the operation.
+// b: the baseline to store.
+// expectedVersion: the version the baseline was read at.
+//
+// Returns domain.ErrBaselineVersion when the baseline was changed in the meantime, or any other error.
+func (r *AnomalyRepository) SaveBaseline(ctx context.Context, b *domain.AnomalyBaseline, expectedVersion int64) error {
+	ctx, span := tracer.Start(ctx, "mongo.AnomalyRepository.SaveBaseline")
+	defer span.End()
+	filter := bson.M{"device_id": b.DeviceID, "parameter": b.Parameter, "version": expectedVersion}
+	if expectedVersion == 0 {
+		// Baselines saved before versioning have no version field
+		filter["version"] = bson.M{"$in": bson.A{0, nil}}
+	}
+	update := bson.D{{Key: "$set", Value: bson.M{
+		"count":      b.Count,
+		"ewma":       b.EWMA,
+		"ewm_var":    b.EWMVar,
+		"window":     b.Window,
+		"hourly":     b.Hourly,
+		"updated_at": b.UpdatedAt,
+		"version":    b.Version,
+	}}}
+	options := options.Update().SetUpsert(expectedVersion == 0)
+
+	result, err := r.baselines.UpdateOne(ctx, filter, update, options)
+	if err != nil {
+		// The upsert of a new baseline collides with one created in the meantime
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrBaselineVersion
+		}
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	if result.MatchedCount == 0 && result.UpsertedCount == 0 {
+		return domain.ErrBaselineVersion
+	}
+	return nil
+}
+
+// CreateMany adds the anomaly events to the database.
+//
+// ctx: the context in which the operation is performed.
+// events: the events to be stored.
+//
+// Returns an error if the operation was not successful.
+func (r *AnomalyRepository) CreateMany(ctx context.Context, events []domain.AnomalyEvent) error {
+	ctx, span := tracer.Start(ctx, "mongo.AnomalyRepository.CreateMany")
+	defer span.End()
+	if len(events) == 0 {
+		return nil
+	}
+	documents := make([]interface{}, 0, len(events))
+	for _, event := range events {
+		documents = append(documents, event)
+	}
+	if _, err := r.collection.InsertMany(ctx, documents); err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// GetAll retrieves the anomaly events matching the filter with pagination, newest first.
+//
+// ctx: the context for the operation.
+// filter: the device, parameter and time range to match.
+// page: the page number for pagination.
+// limit: the maximum number of items to return per page.
+//
+// Returns a list of anomaly events and an error, if any.
+func (r *AnomalyRepository) GetAll(ctx context.Context, filter domain.AnomalyFilter, page, limit int) ([]domain.AnomalyEvent, error) {
+	ctx, span := tracer.Start(ctx, "mongo.AnomalyRepository.GetAll")
+	defer span.End()
+	skip := (page - 1) * limit
+	options := options.Find().
+		SetSort(bson.D{{Key: "timestamp", Value: -1}}).
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit))
+
+	query := measurementQuery(domain.MeasurementFilter{
+		DeviceID:  filter.DeviceID,
+		Parameter: filter.Parameter,
+		From:      filter.From,
+		To:        filter.To,
+	})
+	cursor, err := r.collection.Find(ctx, query, options)
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	var events []domain.AnomalyEvent
+	if err = cursor.All(ctx, &events); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return events, nil
+}
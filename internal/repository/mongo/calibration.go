@@ -0,0 +1,146 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CalibrationRepository is the implementation of the CalibrationRepositoryInterface.
+type CalibrationRepository struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+}
+
+// NewCalibrationRepository creates a new CalibrationRepository.
+//
+// The CalibrationRepository is used to interact with the sensor calibration collection in the database.
+//
+// Parameters:
+// - client: a pointer to a mongo.Client.
+// - config: a pointer to a config.MongoConfig.
+// Returns a pointer to a CalibrationRepository.
+func NewCalibrationRepository(client *mongo.Client, config *config.MongoConfig) *CalibrationRepository {
+	// Get the collection from the database
+	collection := client.Database(config.Database).Collection(config.CalibrationCollection)
+
+	return &CalibrationRepository{
+		// The client used to interact with the database
+		client: client,
+		// The collection to interact with
+		collection: collection,
+	}
+}
+
+// EnsureIndexes creates the index on the calibrated sensor parameter.
+//
+// ctx: the context for the operation.
+//
+// Returns an error if the index could not be created.
+func (r *CalibrationRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "mongo.CalibrationRepository.EnsureIndexes")
+	defer span.End()
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "sensor_id", Value: 1}, {Key: "parameter", Value: 1}, {Key: "calibrated_at", Value: -1}},
+	})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// Create adds a new calibration to the database and sets its ID.
+//
+// ctx: the context in which the operation is performed.
+// c: the calibration to be stored.
+//
+// Returns an error if the operation was not successful.
+func (r *CalibrationRepository) Create(ctx context.Context, c *domain.Calibration) error {
+	ctx, span := tracer.Start(ctx, "mongo.CalibrationRepository.Create")
+	defer span.End()
+	result, err := r.collection.InsertOne(ctx, c)
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		c.ID = id
+	}
+	return nil
+}
+
+// GetBySensor retrieves the calibrations of a sensor, latest first.
+//
+// ctx: the context for the operation.
+// sensorID: the calibrated sensor.
+// parameter: the calibrated parameter, empty for every parameter of the sensor.
+//
+// Returns a list of calibrations and an error, if any.
+func (r *CalibrationRepository) GetBySensor(ctx context.Context, sensorID primitive.ObjectID, parameter string) ([]domain.Calibration, error) {
+	ctx, span := tracer.Start(ctx, "mongo.CalibrationRepository.GetBySensor")
+	defer span.End()
+	filter := bson.M{"sensor_id": sensorID}
+	if parameter != "" {
+		filter["parameter"] = parameter
+	}
+	options := options.Find().SetSort(bson.D{{Key: "calibrated_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, options)
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	var calibrations []domain.Calibration
+	if err = cursor.All(ctx, &calibrations); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return calibrations, nil
+}
+
+// GetOverdue retrieves the latest calibration of each sensor parameter when it was due before the given time.
+//
+// ctx: the context for the operation.
+// at: the reference time.
+// page: the page number for pagination.
+// limit: the maximum number of items to return per page.
+//
+// Returns a list of calibrations, most overdue first, and an error, if any.
+func (r *CalibrationRepository) GetOverdue(ctx context.Context, at time.Time, page, limit int) ([]domain.Calibration, error) {
+	ctx, span := tracer.Start(ctx, "mongo.CalibrationRepository.GetOverdue")
+	defer span.End()
+	skip := (page - 1) * limit
+	pipeline := mongo.Pipeline{
+		{{Key: "$sort", Value: bson.D{{Key: "calibrated_at", Value: -1}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "sensor_id", Value: "$sensor_id"}, {Key: "parameter", Value: "$parameter"}}},
+			{Key: "latest", Value: bson.M{"$first": "$$ROOT"}},
+		}}},
+		{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$latest"}}},
+		{{Key: "$match", Value: bson.M{"next_due_at": bson.M{"$lt": at}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "next_due_at", Value: 1}}}},
+		{{Key: "$skip", Value: int64(skip)}},
+		{{Key: "$limit", Value: int64(limit)}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	var calibrations []domain.Calibration
+	if err = cursor.All(ctx, &calibrations); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return calibrations, nil
+}
@@ -0,0 +1,242 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CommandRepository is the implementation of the CommandRepositoryInterface.
+type CommandRepository struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+}
+
+// NewCommandRepository creates a new CommandRepository.
+//
+// The CommandRepository is used to interact with the device command collection in the database.
+//
+// Parameters:
+// - client: a pointer to a mongo.Client.
+// - config: a pointer to a config.MongoConfig.
+// Returns a pointer to a CommandRepository.
+func NewCommandRepository(client *mongo.Client, config *config.MongoConfig) *CommandRepository {
+	// Get the collection from the database
+	collection := client.Database(config.Database).Collection(config.CommandCollection)
+
+	return &CommandRepository{
+		// The client used to interact with the database
+		client: client,
+		// The collection to interact with
+		collection: collection,
+	}
+}
+
+// EnsureIndexes creates the indexes on the command history of a device and on the deadlines the sweeper looks at.
+//
+// ctx: the context for the operation.
+//
+// Returns an error if the indexes could not be created.
+func (r *CommandRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "mongo.CommandRepository.EnsureIndexes")
+	defer span.End()
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "device_id", Value: 1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "expires_at", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "deadline_at", Value: 1}}},
+	})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// Create inserts a new command into the collection.
+//
+// ctx: the context in which the operation is performed.
+// c: the command to be stored.
+//
+// Returns an error if the operation was not successful.
+func (r *CommandRepository) Create(ctx context.Context, c *domain.Command) error {
+	ctx, span := tracer.Start(ctx, "mongo.CommandRepository.Create")
+	defer span.End()
+	result, err := r.collection.InsertOne(ctx, c)
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		c.ID = id
+	}
+	return nil
+}
+
+// GetAll retrieves the commands matching the filter with pagination, newest first.
+//
+// ctx: the context for the operation.
+// filter: the device and status of the commands.
+// page: the page number for pagination.
+// limit: the maximum number of commands to retrieve.
+//
+// Returns a slice of commands and an error, if any.
+func (r *CommandRepository) GetAll(ctx context.Context, filter domain.CommandFilter, page, limit int) ([]domain.Command, error) {
+	ctx, span := tracer.Start(ctx, "mongo.CommandRepository.GetAll")
+	defer span.End()
+	query := bson.M{}
+	if !filter.DeviceID.IsZero() {
+		query["device_id"] = filter.DeviceID
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	skip := (page - 1) * limit
+	options := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit))
+
+	return r.find(ctx, query, options)
+}
+
+// GetByID retrieves a command by its ID.
+//
+// ctx: the context for the operation.
+// id: the ID of the command.
+//
+// Returns the command, nil when it does not exist, and an error, if any.
+func (r *CommandRepository) GetByID(ctx context.Context, id string) (*domain.Command, error) {
+	ctx, span := tracer.Start(ctx, "mongo.CommandRepository.GetByID")
+	defer span.End()
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	var c domain.Command
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&c); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return &c, nil
+}
+
+// GetQueued retrieves the queued commands that have not expired yet, oldest first.
+//
+// ctx: the context for the operation.
+// now: the time the expiry is checked against.
+// limit: the maximum number of commands to retrieve.
+//
+// Returns a slice of commands and an error, if any.
+func (r *CommandRepository) GetQueued(ctx context.Context, now time.Time, limit int) ([]domain.Command, error) {
+	ctx, span := tracer.Start(ctx, "mongo.CommandRepository.GetQueued")
+	defer span.End()
+	query := bson.M{"status": domain.CommandQueued, "expires_at": bson.M{"$gt": now}}
+	options := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	return r.find(ctx, query, options)
+}
+
+// CountQueued counts the queued commands that have not expired yet.
+//
+// ctx: the context for the operation.
+// now: the time the expiry is checked against.
+//
+// Returns the number of commands and an error, if any.
+func (r *CommandRepository) CountQueued(ctx context.Context, now time.Time) (int, error) {
+	ctx, span := tracer.Start(ctx, "mongo.CommandRepository.CountQueued")
+	defer span.End()
+	count, err := r.collection.CountDocuments(ctx, bson.M{"status": domain.CommandQueued, "expires_at": bson.M{"$gt": now}})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// UpdateStatus stores the status of a command if it is still in one of the given statuses.
+//
+// ctx: the context for the operation.
+// c: the command with its new status and timestamps.
+// from: the statuses the stored command may be in.
+//
+// Returns domain.ErrCommandTransition when the command moved on in the meantime, or any other error.
+func (r *CommandRepository) UpdateStatus(ctx context.Context, c *domain.Command, from []string) error {
+	ctx, span := tracer.Start(ctx, "mongo.CommandRepository.UpdateStatus")
+	defer span.End()
+	filter := bson.M{"_id": c.ID, "status": bson.M{"$in": from}}
+	update := bson.D{{Key: "$set", Value: bson.M{
+		"status":          c.Status,
+		"sent_at":         c.SentAt,
+		"acknowledged_at": c.AcknowledgedAt,
+		"deadline_at":     c.DeadlineAt,
+		"completed_at":    c.CompletedAt,
+		"result":          c.Result,
+		"error":           c.Error,
+	}}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrCommandTransition
+	}
+	return nil
+}
+
+// ExpireOverdue expires the commands not acknowledged before their expiry and times out the
+// acknowledged commands without a result before their deadline.
+//
+// ctx: the context for the operation.
+// now: the time the expiries and deadlines are checked against.
+//
+// Returns the number of commands changed and an error, if any.
+func (r *CommandRepository) ExpireOverdue(ctx context.Context, now time.Time) (int64, error) {
+	ctx, span := tracer.Start(ctx, "mongo.CommandRepository.ExpireOverdue")
+	defer span.End()
+	expired, err := r.collection.UpdateMany(ctx,
+		bson.M{"status": bson.M{"$in": domain.CommandSources(domain.CommandExpired)}, "expires_at": bson.M{"$lte": now}},
+		bson.D{{Key: "$set", Value: bson.M{"status": domain.CommandExpired, "completed_at": now}}},
+	)
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return 0, err
+	}
+	timedOut, err := r.collection.UpdateMany(ctx,
+		bson.M{"status": bson.M{"$in": domain.CommandSources(domain.CommandTimedOut)}, "deadline_at": bson.M{"$lte": now}},
+		bson.D{{Key: "$set", Value: bson.M{"status": domain.CommandTimedOut, "completed_at": now}}},
+	)
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return expired.ModifiedCount, err
+	}
+	return expired.ModifiedCount + timedOut.ModifiedCount, nil
+}
+
+// find runs a query and decodes the commands.
+func (r *CommandRepository) find(ctx context.Context, query bson.M, options *options.FindOptions) ([]domain.Command, error) {
+	cursor, err := r.collection.Find(ctx, query, options)
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	var commands []domain.Command
+	if err = cursor.All(ctx, &commands); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return commands, nil
+}
@@ -0,0 +1,117 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CredentialRepository is the implementation of the CredentialRepositoryInterface.
+type CredentialRepository struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+}
+
+// NewCredentialRepository creates a new CredentialRepository.
+//
+// The CredentialRepository is used to interact with the device credential collection in the database.
+//
+// Parameters:
+// - client: a pointer to a mongo.Client.
+// - config: a pointer to a config.MongoConfig.
+// Returns a pointer to a CredentialRepository.
+func NewCredentialRepository(client *mongo.Client, config *config.MongoConfig) *CredentialRepository {
+	// Get the collection from the database
+	collection := client.Database(config.Database).Collection(config.CredentialCollection)
+
+	return &CredentialRepository{
+		// The client used to interact with the database
+		client: client,
+		// The collection to interact with
+		collection: collection,
+	}
+}
+
+// EnsureIndexes creates the unique index on the username of the credentials.
+//
+// ctx: the context for the operation.
+//
+// Returns an error if the index could not be created.
+func (r *CredentialRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "mongo.CredentialRepository.EnsureIndexes")
+	defer span.End()
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "username", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// Create inserts the credentials of a device into the collection.
+//
+// ctx: the context in which the operation is performed.
+// c: the credentials to be stored.
+//
+// Returns an error if the operation was not successful.
+func (r *CredentialRepository) Create(ctx context.Context, c *domain.DeviceCredential) error {
+	ctx, span := tracer.Start(ctx, "mongo.CredentialRepository.Create")
+	defer span.End()
+	result, err := r.collection.InsertOne(ctx, c)
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		c.ID = id
+	}
+	return nil
+}
+
+// GetByUsername retrieves the credentials with the given username.
+//
+// ctx: the context in which the operation is performed.
+// username: the username of the credentials.
+//
+// Returns the credentials, nil when there are none, and an error, if any.
+func (r *CredentialRepository) GetByUsername(ctx context.Context, username string) (*domain.DeviceCredential, error) {
+	ctx, span := tracer.Start(ctx, "mongo.CredentialRepository.GetByUsername")
+	defer span.End()
+	var c domain.DeviceCredential
+	if err := r.collection.FindOne(ctx, bson.M{"username": username}).Decode(&c); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Delete removes the credentials with the given ID.
+//
+// ctx: the context in which the operation is performed.
+// id: the ID of the credentials.
+//
+// Returns an error if the operation was not successful.
+func (r *CredentialRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "mongo.CredentialRepository.Delete")
+	defer span.End()
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	if _, err = r.collection.DeleteOne(ctx, bson.M{"_id": objectID}); err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
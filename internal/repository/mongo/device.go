@@ -5,7 +5,6 @@ import (
 
 	"github.com/anggi-susanto/mrt-go/config"
 	"github.com/anggi-susanto/mrt-go/domain"
-	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -38,6 +37,35 @@ func NewDeviceRepository(client *mongo.Client, config *config.MongoConfig) *Devi
 	}
 }
 
+// EnsureIndexes creates the 2dsphere index on the device location, the site and status indexes
+// and the unique indexes on the serial number and the DevEUI.
+//
+// ctx: the context for the operation.
+//
+// Returns an error if the indexes could not be created.
+func (r *DeviceRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "mongo.DeviceRepository.EnsureIndexes")
+	defer span.End()
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "location", Value: "2dsphere"}}},
+		{Keys: bson.D{{Key: "site_id", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "model", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "serial_number", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"serial_number": bson.M{"$exists": true}}),
+		},
+		{
+			Keys:    bson.D{{Key: "dev_eui", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"dev_eui": bson.M{"$exists": true}}),
+		},
+	})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
 // Create adds a new waste water record to the database.
 //
 // ctx: the context in which the operation is performed.
@@ -45,42 +73,64 @@ func NewDeviceRepository(client *mongo.Client, config *config.MongoConfig) *Devi
 //
 // Returns an error if the operation was not successful.
 func (r *DeviceRepository) Create(ctx context.Context, w *domain.DeviceRequest) error {
+	ctx, span := tracer.Start(ctx, "mongo.DeviceRepository.Create")
+	defer span.End()
 	// Insert the new waste water data into the database
-	_, err := r.collection.InsertOne(ctx, w)
+	result, err := r.collection.InsertOne(ctx, w)
 	if err != nil {
 		// Log the error and return it
-		logrus.Error(err)
+		log.WithContext(ctx).Error(err)
 		return err
 	}
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		w.ID = id
+	}
 	return nil
 }
 
-// GetAll retrieves all waste water data with pagination from the DeviceRepository.
+// GetAll retrieves the devices matching the inventory filter with pagination from the DeviceRepository.
 //
 // ctx: the context for the operation.
+// filter: the status, model and site to match.
 // page: the page number for pagination.
 // limit: the maximum number of items to return per page.
 //
 // Returns a list of waste water data and an error, if any.
-func (r *DeviceRepository) GetAll(ctx context.Context, page, limit int) ([]domain.Device, error) {
+func (r *DeviceRepository) GetAll(ctx context.Context, filter domain.DeviceFilter, page, limit int) ([]domain.Device, error) {
+	ctx, span := tracer.Start(ctx, "mongo.DeviceRepository.GetAll")
+	defer span.End()
 	// Calculate the skip value based on the page and limit values
 	skip := (page - 1) * limit
 
-	// Define the filter and options for the query
-	filter := bson.D{} // empty filter to retrieve all documents
+	// Define the query and options, zero filter values match every document
+	query := bson.M{}
+	switch filter.Status {
+	case "":
+	case domain.DeviceActive:
+		// Devices created before lifecycle management have no status and are active
+		query["status"] = bson.M{"$in": bson.A{domain.DeviceActive, nil}}
+	default:
+		query["status"] = filter.Status
+	}
+	if filter.Model != "" {
+		query["model"] = filter.Model
+	}
+	if !filter.SiteID.IsZero() {
+		query["site_id"] = filter.SiteID
+	}
 	options := options.Find().SetSkip(int64(skip)).SetLimit(int64(limit))
 
 	// Execute the query and get a cursor
-	cursor, err := r.collection.Find(ctx, filter, options)
+	cursor, err := r.collection.Find(ctx, query, options)
 	if err != nil {
-		logrus.Error(err)
+		log.WithContext(ctx).Error(err)
 		return nil, err
 	}
 
 	// Decode all the documents in the cursor into a slice of WasteWaterData
 	var wastes []domain.Device
 	if err = cursor.All(ctx, &wastes); err != nil {
-		logrus.Error(err)
+		log.WithContext(ctx).Error(err)
 		return nil, err
 	}
 
@@ -100,6 +150,8 @@ func (r *DeviceRepository) GetAll(ctx context.Context, page, limit int) ([]domai
 //	*domain.WasteWaterData - pointer to the retrieved WasteWaterData
 //	error - nil if successful, error if not found or any other error occurs
 func (r *DeviceRepository) GetByID(ctx context.Context, id string) (*domain.Device, error) {
+	ctx, span := tracer.Start(ctx, "mongo.DeviceRepository.GetByID")
+	defer span.End()
 	// Define the filter for querying the document by its ID
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -126,6 +178,8 @@ func (r *DeviceRepository) GetByID(ctx context.Context, id string) (*domain.Devi
 //
 // Returns an error if the operation was not successful.
 func (r *DeviceRepository) Update(ctx context.Context, w *domain.Device) error {
+	ctx, span := tracer.Start(ctx, "mongo.DeviceRepository.Update")
+	defer span.End()
 	// Define the filter for querying the document by its ID
 	objectID, err := primitive.ObjectIDFromHex(w.ID.Hex())
 	if err != nil {
@@ -140,7 +194,7 @@ func (r *DeviceRepository) Update(ctx context.Context, w *domain.Device) error {
 	_, err = r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
 		// Log the error and return it
-		logrus.Error(err)
+		log.WithContext(ctx).Error(err)
 		return err
 	}
 
@@ -152,6 +206,8 @@ func (r *DeviceRepository) Update(ctx context.Context, w *domain.Device) error {
 // If the ID is not found, it returns a MongoDB exception with the NotFound error code.
 // It returns an error if any other error occurs.
 func (r *DeviceRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "mongo.DeviceRepository.Delete")
+	defer span.End()
 	// Define the filter for querying the document by its ID
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -164,10 +220,216 @@ func (r *DeviceRepository) Delete(ctx context.Context, id string) error {
 
 	// If an error occurs, log it and return it
 	if err != nil {
-		logrus.Error(err)
+		log.WithContext(ctx).Error(err)
 		return err
 	}
 
 	// Return a nil error if the operation was successful
 	return nil
 }
+
+// GetBySiteID retrieves the devices assigned to a site with pagination.
+//
+// ctx: the context for the operation.
+// siteID: the ID of the site.
+// page: the page number for pagination.
+// limit: the maximum number of items to return per page.
+//
+// Returns a list of devices and an error, if any.
+func (r *DeviceRepository) GetBySiteID(ctx context.Context, siteID string, page, limit int) ([]domain.Device, error) {
+	ctx, span := tracer.Start(ctx, "mongo.DeviceRepository.GetBySiteID")
+	defer span.End()
+	objectID, err := primitive.ObjectIDFromHex(siteID)
+	if err != nil {
+		return nil, err
+	}
+	skip := (page - 1) * limit
+	filter := bson.M{"site_id": objectID}
+	options := options.Find().SetSkip(int64(skip)).SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, options)
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	var devices []domain.Device
+	if err = cursor.All(ctx, &devices); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return devices, nil
+}
+
+// GetByIDs retrieves the devices with the given IDs.
+//
+// ctx: the context for the operation.
+// ids: the IDs of the devices.
+//
+// Returns the devices found, in no particular order, and an error, if any.
+func (r *DeviceRepository) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]domain.Device, error) {
+	ctx, span := tracer.Start(ctx, "mongo.DeviceRepository.GetByIDs")
+	defer span.End()
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	var devices []domain.Device
+	if err = cursor.All(ctx, &devices); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return devices, nil
+}
+
+// GetBySiteIDs retrieves the devices assigned to any of the given sites.
+//
+// ctx: the context for the operation.
+// siteIDs: the IDs of the sites.
+//
+// Returns the devices sorted by name and an error, if any.
+func (r *DeviceRepository) GetBySiteIDs(ctx context.Context, siteIDs []primitive.ObjectID) ([]domain.Device, error) {
+	ctx, span := tracer.Start(ctx, "mongo.DeviceRepository.GetBySiteIDs")
+	defer span.End()
+	filter := bson.M{"site_id": bson.M{"$in": siteIDs}}
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	var devices []domain.Device
+	if err = cursor.All(ctx, &devices); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return devices, nil
+}
+
+// GetModbusDevices retrieves the installed and active devices having a Modbus endpoint.
+//
+// ctx: the context for the operation.
+//
+// Returns a list of devices and an error, if any.
+func (r *DeviceRepository) GetModbusDevices(ctx context.Context) ([]domain.Device, error) {
+	ctx, span := tracer.Start(ctx, "mongo.DeviceRepository.GetModbusDevices")
+	defer span.End()
+	filter := bson.M{
+		"modbus": bson.M{"$type": "object"},
+		// Devices created before lifecycle management have no status and are active
+		"status": bson.M{"$in": bson.A{domain.DeviceInstalled, domain.DeviceActive, nil}},
+	}
+
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	var devices []domain.Device
+	if err = cursor.All(ctx, &devices); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return devices, nil
+}
+
+// GetByDevEUI retrieves the device of a LoRaWAN DevEUI.
+//
+// ctx: the context for the operation.
+// devEUI: the normalized DevEUI.
+//
+// Returns the device, nil when no device has the DevEUI, and an error, if any.
+func (r *DeviceRepository) GetByDevEUI(ctx context.Context, devEUI string) (*domain.Device, error) {
+	ctx, span := tracer.Start(ctx, "mongo.DeviceRepository.GetByDevEUI")
+	defer span.End()
+	var device domain.Device
+	if err := r.collection.FindOne(ctx, bson.M{"dev_eui": devEUI}).Decode(&device); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return &device, nil
+}
+
+// GetNear retrieves the devices located within radius meters of a point, nearest first.
+//
+// ctx: the context for the operation.
+// lat, lng: the coordinates of the center point.
+// radius: the maximum distance in meters.
+// page: the page number for pagination.
+// limit: the maximum number of items to return per page.
+//
+// Returns a list of devices and an error, if any.
+func (r *DeviceRepository) GetNear(ctx context.Context, lat, lng, radius float64, page, limit int) ([]domain.Device, error) {
+	ctx, span := tracer.Start(ctx, "mongo.DeviceRepository.GetNear")
+	defer span.End()
+	skip := (page - 1) * limit
+	filter := bson.M{
+		"location": bson.M{
+			"$nearSphere": bson.M{
+				"$geometry":    domain.NewGeoPoint(lat, lng),
+				"$maxDistance": radius,
+			},
+		},
+	}
+	options := options.Find().SetSkip(int64(skip)).SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, options)
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	var devices []domain.Device
+	if err = cursor.All(ctx, &devices); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return devices, nil
+}
+
+// GetInventory counts the devices per lifecycle status and model.
+//
+// Devices created before lifecycle management have no status and are counted as active.
+//
+// ctx: the context for the operation.
+//
+// Returns the counts ordered by status and model and an error, if any.
+func (r *DeviceRepository) GetInventory(ctx context.Context) ([]domain.InventoryCount, error) {
+	ctx, span := tracer.Start(ctx, "mongo.DeviceRepository.GetInventory")
+	defer span.End()
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{
+				{Key: "status", Value: bson.M{"$ifNull": bson.A{"$status", domain.DeviceActive}}},
+				{Key: "model", Value: bson.M{"$ifNull": bson.A{"$model", ""}}},
+			}},
+			{Key: "count", Value: bson.M{"$sum": 1}},
+		}}},
+		{{Key: "$project", Value: bson.D{
+			{Key: "_id", Value: 0},
+			{Key: "status", Value: "$_id.status"},
+			{Key: "model", Value: "$_id.model"},
+			{Key: "count", Value: 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "status", Value: 1}, {Key: "model", Value: 1}}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	var counts []domain.InventoryCount
+	if err = cursor.All(ctx, &counts); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return counts, nil
+}
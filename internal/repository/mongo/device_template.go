@@ -0,0 +1,119 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TemplateRepository is the implementation of the TemplateRepositoryInterface.
+type TemplateRepository struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+}
+
+// NewTemplateRepository creates a new TemplateRepository.
+//
+// The TemplateRepository is used to interact with the device template collection in the database.
+//
+// Parameters:
+// - client: a pointer to a mongo.Client.
+// - config: a pointer to a config.MongoConfig.
+// Returns a pointer to a TemplateRepository.
+func NewTemplateRepository(client *mongo.Client, config *config.MongoConfig) *TemplateRepository {
+	// Get the collection from the database
+	collection := client.Database(config.Database).Collection(config.TemplateCollection)
+
+	return &TemplateRepository{
+		// The client used to interact with the database
+		client: client,
+		// The collection to interact with
+		collection: collection,
+	}
+}
+
+// EnsureIndexes creates the unique index on the model of the templates.
+//
+// ctx: the context for the operation.
+//
+// Returns an error if the index could not be created.
+func (r *TemplateRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "mongo.TemplateRepository.EnsureIndexes")
+	defer span.End()
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "model", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// Save creates or replaces the template of a model.
+//
+// ctx: the context for the operation.
+// t: the template to store.
+//
+// Returns an error if the operation was not successful.
+func (r *TemplateRepository) Save(ctx context.Context, t *domain.DeviceTemplate) error {
+	ctx, span := tracer.Start(ctx, "mongo.TemplateRepository.Save")
+	defer span.End()
+	options := options.FindOneAndReplace().SetUpsert(true).SetReturnDocument(options.After)
+	// The stored template keeps its ID
+	replacement := *t
+	replacement.ID = primitive.NilObjectID
+	if err := r.collection.FindOneAndReplace(ctx, bson.M{"model": t.Model}, replacement, options).Decode(t); err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// GetAll retrieves the templates of every model.
+//
+// ctx: the context for the operation.
+//
+// Returns a slice of templates and an error, if any.
+func (r *TemplateRepository) GetAll(ctx context.Context) ([]domain.DeviceTemplate, error) {
+	ctx, span := tracer.Start(ctx, "mongo.TemplateRepository.GetAll")
+	defer span.End()
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "model", Value: 1}}))
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	var templates []domain.DeviceTemplate
+	if err = cursor.All(ctx, &templates); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return templates, nil
+}
+
+// GetByModel retrieves the template of a model.
+//
+// ctx: the context for the operation.
+// model: the device model.
+//
+// Returns the template, nil when the model has none, and an error, if any.
+func (r *TemplateRepository) GetByModel(ctx context.Context, model string) (*domain.DeviceTemplate, error) {
+	ctx, span := tracer.Start(ctx, "mongo.TemplateRepository.GetByModel")
+	defer span.End()
+	var t domain.DeviceTemplate
+	if err := r.collection.FindOne(ctx, bson.M{"model": model}).Decode(&t); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,110 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ingestKey is the stored dedup key of a reading
+type ingestKey struct {
+	Key       string             `bson:"_id"`
+	ReadingID primitive.ObjectID `bson:"reading_id"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
+
+// IngestKeyRepository is the implementation of the IngestKeyRepositoryInterface.
+//
+// The measurements are stored in a time series collection which cannot have unique indexes, the
+// dedup keys of the readings are kept apart to store each reading once.
+type IngestKeyRepository struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+	retention  time.Duration
+}
+
+// NewIngestKeyRepository creates a new IngestKeyRepository.
+//
+// Parameters:
+// - client: a pointer to a mongo.Client.
+// - config: a pointer to a config.MongoConfig.
+// Returns a pointer to an IngestKeyRepository.
+func NewIngestKeyRepository(client *mongo.Client, config *config.MongoConfig) *IngestKeyRepository {
+	// Get the collection from the database
+	collection := client.Database(config.Database).Collection(config.IngestKeyCollection)
+
+	return &IngestKeyRepository{
+		// The client used to interact with the database
+		client: client,
+		// The collection to interact with
+		collection: collection,
+		// How long a key is remembered
+		retention: config.IngestKeyRetention,
+	}
+}
+
+// EnsureIndexes creates the TTL index forgetting the keys after the retention.
+//
+// ctx: the context for the operation.
+//
+// Returns an error if the index could not be created.
+func (r *IngestKeyRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "mongo.IngestKeyRepository.EnsureIndexes")
+	defer span.End()
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "created_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(r.retention.Seconds())),
+	})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// Claim records the dedup key of a reading about to be stored.
+//
+// ctx: the context for the operation.
+// key: the dedup key of the reading.
+// readingID: the ID the reading will be stored with.
+//
+// Returns the ID of the reading already stored with the key, or a zero ID when the key is new, and an error, if any.
+func (r *IngestKeyRepository) Claim(ctx context.Context, key string, readingID primitive.ObjectID) (primitive.ObjectID, error) {
+	ctx, span := tracer.Start(ctx, "mongo.IngestKeyRepository.Claim")
+	defer span.End()
+	_, err := r.collection.InsertOne(ctx, ingestKey{Key: key, ReadingID: readingID, CreatedAt: time.Now()})
+	if err == nil {
+		return primitive.NilObjectID, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		log.WithContext(ctx).Error(err)
+		return primitive.NilObjectID, err
+	}
+	var existing ingestKey
+	if err = r.collection.FindOne(ctx, bson.M{"_id": key}).Decode(&existing); err != nil {
+		log.WithContext(ctx).Error(err)
+		return primitive.NilObjectID, err
+	}
+	return existing.ReadingID, nil
+}
+
+// Release forgets the dedup key of a reading that could not be stored.
+//
+// ctx: the context for the operation.
+// key: the dedup key of the reading.
+//
+// Returns an error if the key could not be deleted.
+func (r *IngestKeyRepository) Release(ctx context.Context, key string) error {
+	ctx, span := tracer.Start(ctx, "mongo.IngestKeyRepository.Release")
+	defer span.End()
+	if _, err := r.collection.DeleteOne(ctx, bson.M{"_id": key}); err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// IngestMappingRepository is the implementation of the IngestMappingRepositoryInterface.
+type IngestMappingRepository struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+}
+
+// NewIngestMappingRepository creates a new IngestMappingRepository.
+//
+// The IngestMappingRepository is used to interact with the ingest mapping collection in the database.
+//
+// Parameters:
+// - client: a pointer to a mongo.Client.
+// - config: a pointer to a config.MongoConfig.
+// Returns a pointer to an IngestMappingRepository.
+func NewIngestMappingRepository(client *mongo.Client, config *config.MongoConfig) *IngestMappingRepository {
+	// Get the collection from the database
+	collection := client.Database(config.Database).Collection(config.IngestMappingCollection)
+
+	return &IngestMappingRepository{
+		// The client used to interact with the database
+		client: client,
+		// The collection to interact with
+		collection: collection,
+	}
+}
+
+// EnsureIndexes creates the unique index on the source of the mappings.
+//
+// ctx: the context for the operation.
+//
+// Returns an error if the index could not be created.
+func (r *IngestMappingRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "mongo.IngestMappingRepository.EnsureIndexes")
+	defer span.End()
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "source", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// Save creates or replaces the mapping of a gateway.
+//
+// ctx: the context for the operation.
+// m: the mapping to store.
+//
+// Returns an error if the operation was not successful.
+func (r *IngestMappingRepository) Save(ctx context.Context, m *domain.IngestMapping) error {
+	ctx, span := tracer.Start(ctx, "mongo.IngestMappingRepository.Save")
+	defer span.End()
+	options := options.FindOneAndReplace().SetUpsert(true).SetReturnDocument(options.After)
+	// The stored mapping keeps its ID
+	replacement := *m
+	replacement.ID = primitive.NilObjectID
+	if err := r.collection.FindOneAndReplace(ctx, bson.M{"source": m.Source}, replacement, options).Decode(m); err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// GetAll retrieves the mappings of every gateway.
+//
+// ctx: the context for the operation.
+//
+// Returns a slice of mappings and an error, if any.
+func (r *IngestMappingRepository) GetAll(ctx context.Context) ([]domain.IngestMapping, error) {
+	ctx, span := tracer.Start(ctx, "mongo.IngestMappingRepository.GetAll")
+	defer span.End()
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "source", Value: 1}}))
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	var mappings []domain.IngestMapping
+	if err = cursor.All(ctx, &mappings); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return mappings, nil
+}
+
+// GetBySource retrieves the mapping of a gateway.
+//
+// ctx: the context for the operation.
+// source: the name of the gateway.
+//
+// Returns the mapping, nil when the gateway has none, and an error, if any.
+func (r *IngestMappingRepository) GetBySource(ctx context.Context, source string) (*domain.IngestMapping, error) {
+	ctx, span := tracer.Start(ctx, "mongo.IngestMappingRepository.GetBySource")
+	defer span.End()
+	var m domain.IngestMapping
+	if err := r.collection.FindOne(ctx, bson.M{"source": source}).Decode(&m); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Delete removes the mapping of a gateway.
+//
+// ctx: the context for the operation.
+// source: the name of the gateway.
+//
+// Returns an error if the operation was not successful.
+func (r *IngestMappingRepository) Delete(ctx context.Context, source string) error {
+	ctx, span := tracer.Start(ctx, "mongo.IngestMappingRepository.Delete")
+	defer span.End()
+	if _, err := r.collection.DeleteOne(ctx, bson.M{"source": source}); err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LabSampleRepository is the implementation of the LabSampleRepositoryInterface.
+type LabSampleRepository struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+}
+
+// NewLabSampleRepository creates a new LabSampleRepository.
+//
+// The LabSampleRepository is used to interact with the lab sample collection in the database.
+//
+// Parameters:
+// - client: a pointer to a mongo.Client.
+// - config: a pointer to a config.MongoConfig.
+// Returns a pointer to a LabSampleRepository.
+func NewLabSampleRepository(client *mongo.Client, config *config.MongoConfig) *LabSampleRepository {
+	// Get the collection from the database
+	collection := client.Database(config.Database).Collection(config.LabSampleCollection)
+
+	return &LabSampleRepository{
+		// The client used to interact with the database
+		client: client,
+		// The collection to interact with
+		collection: collection,
+	}
+}
+
+// EnsureIndexes creates the unique index on the sample ID and the index on the site sampling time.
+//
+// ctx: the context for the operation.
+//
+// Returns an error if the indexes could not be created.
+func (r *LabSampleRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "mongo.LabSampleRepository.EnsureIndexes")
+	defer span.End()
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "sample_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "site_id", Value: 1}, {Key: "sampled_at", Value: -1}}},
+		{Keys: bson.D{{Key: "device_id", Value: 1}, {Key: "sampled_at", Value: -1}}},
+	})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// Create adds a new lab sample record to the database.
+//
+// ctx: the context in which the operation is performed.
+// w: the lab sample request to be stored.
+//
+// Returns an error if the operation was not successful.
+func (r *LabSampleRepository) Create(ctx context.Context, w *domain.LabSampleRequest) error {
+	ctx, span := tracer.Start(ctx, "mongo.LabSampleRepository.Create")
+	defer span.End()
+	// Insert the new lab sample into the database
+	_, err := r.collection.InsertOne(ctx, w)
+	if err != nil {
+		// Log the error and return it
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// GetAll retrieves the lab samples matching the filter with pagination, latest sampled first.
+//
+// ctx: the context for the operation.
+// filter: the site and sampling period to match.
+// page: the page number for pagination.
+// limit: the maximum number of items to return per page.
+//
+// Returns a list of lab samples and an error, if any.
+func (r *LabSampleRepository) GetAll(ctx context.Context, filter domain.LabSampleFilter, page, limit int) ([]domain.LabSample, error) {
+	ctx, span := tracer.Start(ctx, "mongo.LabSampleRepository.GetAll")
+	defer span.End()
+	skip := (page - 1) * limit
+	options := options.Find().
+		SetSort(bson.D{{Key: "sampled_at", Value: -1}}).
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit))
+
+	query := bson.M{}
+	if !filter.SiteID.IsZero() {
+		query["site_id"] = filter.SiteID
+	}
+	if !filter.DeviceID.IsZero() {
+		query["device_id"] = filter.DeviceID
+	}
+	sampledAt := bson.M{}
+	if !filter.From.IsZero() {
+		sampledAt["$gte"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		sampledAt["$lte"] = filter.To
+	}
+	if len(sampledAt) > 0 {
+		query["sampled_at"] = sampledAt
+	}
+
+	cursor, err := r.collection.Find(ctx, query, options)
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	var samples []domain.LabSample
+	if err = cursor.All(ctx, &samples); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return samples, nil
+}
+
+// GetByID retrieves a LabSample document by its ID.
+//
+// Parameters:
+//
+//	ctx - context.Context: the context for the operation
+//	id - string: the ID of the document to retrieve
+//
+// Returns:
+//
+//	*domain.LabSample - pointer to the retrieved LabSample
+//	error - nil if successful, error if not found or any other error occurs
+func (r *LabSampleRepository) GetByID(ctx context.Context, id string) (*domain.LabSample, error) {
+	ctx, span := tracer.Start(ctx, "mongo.LabSampleRepository.GetByID")
+	defer span.End()
+	// Define the filter for querying the document by its ID
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	filter := bson.M{"_id": objectID}
+	// Use the FindOne function to retrieve the document
+	var sample domain.LabSample
+	if err := r.collection.FindOne(ctx, filter).Decode(&sample); err != nil {
+		// Return nil and the error if the document was not found
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// Return the pointer to the LabSample and a nil error
+	return &sample, nil
+}
+
+// Update updates a LabSample in the LabSampleRepository.
+//
+// ctx: the context for the operation.
+// w: a pointer to the LabSample to update.
+//
+// Returns an error if the operation was not successful.
+func (r *LabSampleRepository) Update(ctx context.Context, w *domain.LabSample) error {
+	ctx, span := tracer.Start(ctx, "mongo.LabSampleRepository.Update")
+	defer span.End()
+	// Define the filter for querying the document by its ID
+	filter := bson.M{"_id": w.ID}
+
+	// Define the update operation for the document
+	update := bson.D{{Key: "$set", Value: w}}
+
+	// Use the UpdateOne function to update the document
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		// Log the error and return it
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+
+	// Return a nil error if the operation was successful
+	return nil
+}
+
+// Delete removes a single document from the LabSampleRepository collection using the provided context and ID.
+// It returns an error if any other error occurs.
+func (r *LabSampleRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "mongo.LabSampleRepository.Delete")
+	defer span.End()
+	// Define the filter for querying the document by its ID
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	filter := bson.M{"_id": objectID}
+
+	// Use the DeleteOne function to delete the document
+	_, err = r.collection.DeleteOne(ctx, filter)
+
+	// If an error occurs, log it and return it
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+
+	// Return a nil error if the operation was successful
+	return nil
+}
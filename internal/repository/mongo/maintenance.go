@@ -0,0 +1,205 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// WorkOrderRepository is the implementation of the WorkOrderRepositoryInterface.
+type WorkOrderRepository struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+}
+
+// NewWorkOrderRepository creates a new WorkOrderRepository.
+//
+// The WorkOrderRepository is used to interact with the maintenance work order collection in the database.
+//
+// Parameters:
+// - client: a pointer to a mongo.Client.
+// - config: a pointer to a config.MongoConfig.
+// Returns a pointer to a WorkOrderRepository.
+func NewWorkOrderRepository(client *mongo.Client, config *config.MongoConfig) *WorkOrderRepository {
+	// Get the collection from the database
+	collection := client.Database(config.Database).Collection(config.MaintenanceCollection)
+
+	return &WorkOrderRepository{
+		// The client used to interact with the database
+		client: client,
+		// The collection to interact with
+		collection: collection,
+	}
+}
+
+// EnsureIndexes creates the indexes on the schedule and the downtime of the device work orders.
+//
+// ctx: the context for the operation.
+//
+// Returns an error if the indexes could not be created.
+func (r *WorkOrderRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "mongo.WorkOrderRepository.EnsureIndexes")
+	defer span.End()
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "device_id", Value: 1}, {Key: "schedule.start", Value: 1}}},
+		{Keys: bson.D{{Key: "device_id", Value: 1}, {Key: "downtime.start", Value: 1}}},
+	})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// Create adds a new work order to the database and sets its ID.
+//
+// ctx: the context in which the operation is performed.
+// w: the work order to be stored.
+//
+// Returns an error if the operation was not successful.
+func (r *WorkOrderRepository) Create(ctx context.Context, w *domain.WorkOrder) error {
+	ctx, span := tracer.Start(ctx, "mongo.WorkOrderRepository.Create")
+	defer span.End()
+	result, err := r.collection.InsertOne(ctx, w)
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		w.ID = id
+	}
+	return nil
+}
+
+// GetAll retrieves the work orders matching the filter with pagination, earliest scheduled first.
+//
+// ctx: the context for the operation.
+// filter: the device, status and the period the schedule or the downtime overlaps.
+// page: the page number for pagination.
+// limit: the maximum number of items to return per page.
+//
+// Returns a list of work orders and an error, if any.
+func (r *WorkOrderRepository) GetAll(ctx context.Context, filter domain.MaintenanceFilter, page, limit int) ([]domain.WorkOrder, error) {
+	ctx, span := tracer.Start(ctx, "mongo.WorkOrderRepository.GetAll")
+	defer span.End()
+	skip := (page - 1) * limit
+	options := options.Find().
+		SetSort(bson.D{{Key: "schedule.start", Value: 1}}).
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, workOrderQuery(filter), options)
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	var workOrders []domain.WorkOrder
+	if err = cursor.All(ctx, &workOrders); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return workOrders, nil
+}
+
+// GetByID retrieves a work order by its ID.
+//
+// ctx: the context for the operation.
+// id: the ID of the work order.
+//
+// Returns the work order, nil when it does not exist, and an error, if any.
+func (r *WorkOrderRepository) GetByID(ctx context.Context, id string) (*domain.WorkOrder, error) {
+	ctx, span := tracer.Start(ctx, "mongo.WorkOrderRepository.GetByID")
+	defer span.End()
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	var w domain.WorkOrder
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&w); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return &w, nil
+}
+
+// Update replaces a work order if it still has the status it was read with.
+//
+// ctx: the context for the operation.
+// w: a pointer to the work order to update.
+// status: the status the work order was read with.
+//
+// Returns domain.ErrWorkOrderTransition when the work order was deleted or its status changed,
+// or an error if the operation was not successful.
+func (r *WorkOrderRepository) Update(ctx context.Context, w *domain.WorkOrder, status string) error {
+	ctx, span := tracer.Start(ctx, "mongo.WorkOrderRepository.Update")
+	defer span.End()
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": w.ID, "status": status}, w)
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrWorkOrderTransition
+	}
+	return nil
+}
+
+// Delete removes a work order by its ID.
+//
+// ctx: the context for the operation.
+// id: the ID of the work order.
+//
+// Returns an error if the operation was not successful.
+func (r *WorkOrderRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "mongo.WorkOrderRepository.Delete")
+	defer span.End()
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	if _, err = r.collection.DeleteOne(ctx, bson.M{"_id": objectID}); err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// workOrderQuery builds the query document of a maintenance filter.
+func workOrderQuery(filter domain.MaintenanceFilter) bson.M {
+	query := bson.M{}
+	if !filter.DeviceID.IsZero() {
+		query["device_id"] = filter.DeviceID
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	if filter.From.IsZero() && filter.To.IsZero() {
+		return query
+	}
+
+	schedule := bson.M{}
+	downtime := bson.M{"downtime": bson.M{"$exists": true}}
+	if !filter.To.IsZero() {
+		schedule["schedule.start"] = bson.M{"$lte": filter.To}
+		downtime["downtime.start"] = bson.M{"$lte": filter.To}
+	}
+	if !filter.From.IsZero() {
+		schedule["schedule.end"] = bson.M{"$gte": filter.From}
+		// An open downtime overlaps every later period
+		downtime["$or"] = bson.A{
+			bson.M{"downtime.end": bson.M{"$exists": false}},
+			bson.M{"downtime.end": bson.M{"$gte": filter.From}},
+		}
+	}
+	query["$or"] = bson.A{schedule, downtime}
+	return query
+}
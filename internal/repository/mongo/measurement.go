@@ -0,0 +1,343 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MeasurementRepository is the implementation of the MeasurementRepositoryInterface.
+type MeasurementRepository struct {
+	client     *mongo.Client
+	database   *mongo.Database
+	collection *mongo.Collection
+}
+
+// NewMeasurementRepository creates a new MeasurementRepository.
+//
+// The MeasurementRepository is used to interact with the measurement time series collection in the database.
+//
+// Parameters:
+// - client: a pointer to a mongo.Client.
+// - config: a pointer to a config.MongoConfig.
+// Returns a pointer to a MeasurementRepository.
+func NewMeasurementRepository(client *mongo.Client, config *config.MongoConfig) *MeasurementRepository {
+	// Get the database and the collection
+	database := client.Database(config.Database)
+
+	return &MeasurementRepository{
+		// The client used to interact with the database
+		client: client,
+		// The database holding the collection
+		database: database,
+		// The collection to interact with
+		collection: database.Collection(config.MeasurementCollection),
+	}
+}
+
+// EnsureCollection creates the measurement time series collection and its indexes.
+//
+// The collection uses timestamp as time field and device_id as meta field.
+//
+// ctx: the context for the operation.
+//
+// Returns an error if the collection or the indexes could not be created.
+func (r *MeasurementRepository) EnsureCollection(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "mongo.MeasurementRepository.EnsureCollection")
+	defer span.End()
+	names, err := r.database.ListCollectionNames(ctx, bson.M{"name": r.collection.Name()})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	if len(names) == 0 {
+		timeSeries := options.TimeSeries().
+			SetTimeField("timestamp").
+			SetMetaField("device_id").
+			SetGranularity("minutes")
+		if err = r.database.CreateCollection(ctx, r.collection.Name(), options.CreateCollection().SetTimeSeriesOptions(timeSeries)); err != nil {
+			log.WithContext(ctx).Error(err)
+			return err
+		}
+	}
+
+	_, err = r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "reading_id", Value: 1}}},
+		{Keys: bson.D{{Key: "timestamp", Value: -1}, {Key: "reading_id", Value: -1}}},
+		{Keys: bson.D{{Key: "device_id", Value: 1}, {Key: "parameter", Value: 1}, {Key: "timestamp", Value: -1}}},
+		{Keys: bson.D{{Key: "sensor_id", Value: 1}, {Key: "timestamp", Value: -1}}},
+	})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// InsertMany adds the measurements to the database.
+//
+// ctx: the context in which the operation is performed.
+// measurements: the measurements to be stored.
+//
+// Returns an error if the operation was not successful.
+func (r *MeasurementRepository) InsertMany(ctx context.Context, measurements []domain.Measurement) error {
+	ctx, span := tracer.Start(ctx, "mongo.MeasurementRepository.InsertMany")
+	defer span.End()
+	if len(measurements) == 0 {
+		return nil
+	}
+	documents := make([]interface{}, 0, len(measurements))
+	for _, m := range measurements {
+		documents = append(documents, m)
+	}
+	if _, err := r.collection.InsertMany(ctx, documents); err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// GetAll retrieves the measurements matching the filter with pagination, newest first.
+//
+// ctx: the context for the operation.
+// filter: the device, sensor, parameter and time range to match.
+// page: the page number for pagination.
+// limit: the maximum number of items to return per page.
+//
+// Returns a list of measurements and an error, if any.
+func (r *MeasurementRepository) GetAll(ctx context.Context, filter domain.MeasurementFilter, page, limit int) ([]domain.Measurement, error) {
+	ctx, span := tracer.Start(ctx, "mongo.MeasurementRepository.GetAll")
+	defer span.End()
+	skip := (page - 1) * limit
+	options := options.Find().
+		SetSort(bson.D{{Key: "timestamp", Value: -1}}).
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, measurementQuery(filter), options)
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	var measurements []domain.Measurement
+	if err = cursor.All(ctx, &measurements); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return measurements, nil
+}
+
+// GetFirst retrieves the oldest measurement matching the filter.
+//
+// ctx: the context for the operation.
+// filter: the device, sensor, parameter and time range to match.
+//
+// Returns the measurement, nil if none matches, and an error, if any.
+func (r *MeasurementRepository) GetFirst(ctx context.Context, filter domain.MeasurementFilter) (*domain.Measurement, error) {
+	ctx, span := tracer.Start(ctx, "mongo.MeasurementRepository.GetFirst")
+	defer span.End()
+	options := options.FindOne().SetSort(bson.D{{Key: "timestamp", Value: 1}})
+
+	var measurement domain.Measurement
+	err := r.collection.FindOne(ctx, measurementQuery(filter), options).Decode(&measurement)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return &measurement, nil
+}
+
+// GetRecent retrieves the latest measurements of a device parameter taken before the given time, newest first.
+//
+// ctx: the context for the operation.
+// deviceID: the device of the series.
+// parameter: the parameter code of the series.
+// before: the exclusive upper bound of the timestamps.
+// limit: the maximum number of measurements to return.
+//
+// Returns a list of measurements and an error, if any.
+func (r *MeasurementRepository) GetRecent(ctx context.Context, deviceID primitive.ObjectID, parameter string, before time.Time, limit int) ([]domain.Measurement, error) {
+	ctx, span := tracer.Start(ctx, "mongo.MeasurementRepository.GetRecent")
+	defer span.End()
+	filter := bson.M{"device_id": deviceID, "parameter": parameter, "timestamp": bson.M{"$lt": before}}
+	options := options.Find().SetSort(bson.D{{Key: "timestamp", Value: -1}}).SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, options)
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	var measurements []domain.Measurement
+	if err = cursor.All(ctx, &measurements); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return measurements, nil
+}
+
+// GetByIDs retrieves the measurements matching the given IDs.
+//
+// ctx: the context for the operation.
+// ids: the IDs of the measurements.
+//
+// Returns a list of measurements and an error, if any.
+func (r *MeasurementRepository) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]domain.Measurement, error) {
+	ctx, span := tracer.Start(ctx, "mongo.MeasurementRepository.GetByIDs")
+	defer span.End()
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	var measurements []domain.Measurement
+	if err = cursor.All(ctx, &measurements); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return measurements, nil
+}
+
+// UpdateQuality sets the quality flag of the measurements matching the given IDs.
+//
+// Updating measurement fields of a time series collection requires MongoDB 7.0 or later.
+//
+// ctx: the context for the operation.
+// ids: the IDs of the measurements.
+// quality: the new quality flag.
+//
+// Returns an error if the operation was not successful.
+func (r *MeasurementRepository) UpdateQuality(ctx context.Context, ids []primitive.ObjectID, quality string) error {
+	ctx, span := tracer.Start(ctx, "mongo.MeasurementRepository.UpdateQuality")
+	defer span.End()
+	filter := bson.M{"_id": bson.M{"$in": ids}}
+	update := bson.D{{Key: "$set", Value: bson.M{"quality": quality}}}
+	if _, err := r.collection.UpdateMany(ctx, filter, update); err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// SetMaintenance marks the measurements of a device taken during a downtime window as maintenance affected.
+//
+// Updating measurement fields of a time series collection requires MongoDB 7.0 or later.
+//
+// ctx: the context for the operation.
+// deviceID: the device under maintenance.
+// window: the downtime window, a zero end leaves it open.
+// workOrderID: the work order causing the downtime.
+//
+// Returns an error if the operation was not successful.
+func (r *MeasurementRepository) SetMaintenance(ctx context.Context, deviceID primitive.ObjectID, window domain.TimeWindow, workOrderID primitive.ObjectID) error {
+	ctx, span := tracer.Start(ctx, "mongo.MeasurementRepository.SetMaintenance")
+	defer span.End()
+	filter := measurementQuery(domain.MeasurementFilter{DeviceID: deviceID, From: window.Start, To: window.End})
+	update := bson.D{{Key: "$set", Value: bson.M{"maintenance": true, "work_order_id": workOrderID}}}
+	if _, err := r.collection.UpdateMany(ctx, filter, update); err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// ClearMaintenance removes the maintenance mark a work order set on measurements.
+//
+// ctx: the context for the operation.
+// workOrderID: the work order whose downtime no longer applies.
+//
+// Returns an error if the operation was not successful.
+func (r *MeasurementRepository) ClearMaintenance(ctx context.Context, workOrderID primitive.ObjectID) error {
+	ctx, span := tracer.Start(ctx, "mongo.MeasurementRepository.ClearMaintenance")
+	defer span.End()
+	filter := bson.M{"work_order_id": workOrderID}
+	update := bson.D{{Key: "$unset", Value: bson.M{"maintenance": "", "work_order_id": ""}}}
+	if _, err := r.collection.UpdateMany(ctx, filter, update); err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// revisedMeasurement is a measurement stored with the revision of the reading update that wrote it
+type revisedMeasurement struct {
+	domain.Measurement `bson:",inline"`
+	Revision           primitive.ObjectID `bson:"revision"`
+}
+
+// ReplaceReading stores the given measurements of a reading and then deletes its previous ones.
+//
+// The replacements keep the IDs of the measurements they replace, so they are told apart by a
+// revision stamped on them. A failed insert removes the replacements it stored and leaves the
+// previous measurements as they were, readers may see both for the time of the update.
+//
+// ctx: the context for the operation.
+// readingID: the reading to replace.
+// measurements: the new measurements of the reading.
+//
+// Returns an error if the operation was not successful.
+func (r *MeasurementRepository) ReplaceReading(ctx context.Context, readingID primitive.ObjectID, measurements []domain.Measurement) error {
+	ctx, span := tracer.Start(ctx, "mongo.MeasurementRepository.ReplaceReading")
+	defer span.End()
+	revision := primitive.NewObjectID()
+	if len(measurements) > 0 {
+		documents := make([]interface{}, 0, len(measurements))
+		for _, m := range measurements {
+			documents = append(documents, revisedMeasurement{Measurement: m, Revision: revision})
+		}
+		if _, err := r.collection.InsertMany(ctx, documents); err != nil {
+			log.WithContext(ctx).Error(err)
+			if _, cleanupErr := r.collection.DeleteMany(ctx, bson.M{"reading_id": readingID, "revision": revision}); cleanupErr != nil {
+				log.WithContext(ctx).Error(cleanupErr)
+			}
+			return err
+		}
+	}
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"reading_id": readingID, "revision": bson.M{"$ne": revision}}); err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// measurementQuery builds the query document of a measurement filter.
+func measurementQuery(filter domain.MeasurementFilter) bson.M {
+	query := bson.M{}
+	if !filter.ReadingID.IsZero() {
+		query["reading_id"] = filter.ReadingID
+	}
+	if !filter.DeviceID.IsZero() {
+		query["device_id"] = filter.DeviceID
+	}
+	if !filter.SensorID.IsZero() {
+		query["sensor_id"] = filter.SensorID
+	}
+	if filter.Parameter != "" {
+		query["parameter"] = filter.Parameter
+	}
+	if len(filter.Quality) > 0 {
+		query["quality"] = bson.M{"$in": filter.Quality}
+	}
+	timestamp := bson.M{}
+	if !filter.From.IsZero() {
+		timestamp["$gte"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		timestamp["$lte"] = filter.To
+	}
+	if len(timestamp) > 0 {
+		query["timestamp"] = timestamp
+	}
+	return query
+}
@@ -0,0 +1,46 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/anggi-susanto/mrt-go/internal/logging"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+)
+
+// log is the logger of the repositories, its level is configured under "mongo"
+var log = logging.Logger("mongo")
+
+// tracer starts the spans of the repository methods, the commands they send are spans of the tracing monitor
+var tracer = otel.Tracer("github.com/anggi-susanto/mrt-go/internal/repository/mongo")
+
+// Monitors returns a command monitor notifying each of the monitors in turn, the client takes a single one.
+//
+// Parameters:
+// - monitors: the monitors to notify, e.g. the metrics and the tracing ones.
+// Returns a pointer to an event.CommandMonitor.
+func Monitors(monitors ...*event.CommandMonitor) *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			for _, m := range monitors {
+				if m.Started != nil {
+					m.Started(ctx, e)
+				}
+			}
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			for _, m := range monitors {
+				if m.Succeeded != nil {
+					m.Succeeded(ctx, e)
+				}
+			}
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			for _, m := range monitors {
+				if m.Failed != nil {
+					m.Failed(ctx, e)
+				}
+			}
+		},
+	}
+}
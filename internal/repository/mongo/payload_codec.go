@@ -0,0 +1,119 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CodecRepository is the implementation of the CodecRepositoryInterface.
+type CodecRepository struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+}
+
+// NewCodecRepository creates a new CodecRepository.
+//
+// The CodecRepository is used to interact with the payload codec collection in the database.
+//
+// Parameters:
+// - client: a pointer to a mongo.Client.
+// - config: a pointer to a config.MongoConfig.
+// Returns a pointer to a CodecRepository.
+func NewCodecRepository(client *mongo.Client, config *config.MongoConfig) *CodecRepository {
+	// Get the collection from the database
+	collection := client.Database(config.Database).Collection(config.CodecCollection)
+
+	return &CodecRepository{
+		// The client used to interact with the database
+		client: client,
+		// The collection to interact with
+		collection: collection,
+	}
+}
+
+// EnsureIndexes creates the unique index on the model of the codecs.
+//
+// ctx: the context for the operation.
+//
+// Returns an error if the index could not be created.
+func (r *CodecRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "mongo.CodecRepository.EnsureIndexes")
+	defer span.End()
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "model", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// Save creates or replaces the codec of a model.
+//
+// ctx: the context for the operation.
+// c: the codec to store.
+//
+// Returns an error if the operation was not successful.
+func (r *CodecRepository) Save(ctx context.Context, c *domain.PayloadCodec) error {
+	ctx, span := tracer.Start(ctx, "mongo.CodecRepository.Save")
+	defer span.End()
+	options := options.FindOneAndReplace().SetUpsert(true).SetReturnDocument(options.After)
+	// The stored codec keeps its ID
+	replacement := *c
+	replacement.ID = primitive.NilObjectID
+	if err := r.collection.FindOneAndReplace(ctx, bson.M{"model": c.Model}, replacement, options).Decode(c); err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// GetAll retrieves the codecs of every model.
+//
+// ctx: the context for the operation.
+//
+// Returns a slice of codecs and an error, if any.
+func (r *CodecRepository) GetAll(ctx context.Context) ([]domain.PayloadCodec, error) {
+	ctx, span := tracer.Start(ctx, "mongo.CodecRepository.GetAll")
+	defer span.End()
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "model", Value: 1}}))
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	var codecs []domain.PayloadCodec
+	if err = cursor.All(ctx, &codecs); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return codecs, nil
+}
+
+// GetByModel retrieves the codec of a model.
+//
+// ctx: the context for the operation.
+// model: the device model.
+//
+// Returns the codec, nil when the model has none, and an error, if any.
+func (r *CodecRepository) GetByModel(ctx context.Context, model string) (*domain.PayloadCodec, error) {
+	ctx, span := tracer.Start(ctx, "mongo.CodecRepository.GetByModel")
+	defer span.End()
+	var c domain.PayloadCodec
+	if err := r.collection.FindOne(ctx, bson.M{"model": model}).Decode(&c); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return &c, nil
+}
@@ -0,0 +1,177 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ClaimRepository is the implementation of the ClaimRepositoryInterface.
+type ClaimRepository struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+}
+
+// NewClaimRepository creates a new ClaimRepository.
+//
+// The ClaimRepository is used to interact with the provisioning claim collection in the database.
+//
+// Parameters:
+// - client: a pointer to a mongo.Client.
+// - config: a pointer to a config.MongoConfig.
+// Returns a pointer to a ClaimRepository.
+func NewClaimRepository(client *mongo.Client, config *config.MongoConfig) *ClaimRepository {
+	// Get the collection from the database
+	collection := client.Database(config.Database).Collection(config.ClaimCollection)
+
+	return &ClaimRepository{
+		// The client used to interact with the database
+		client: client,
+		// The collection to interact with
+		collection: collection,
+	}
+}
+
+// EnsureIndexes creates the unique index on the serial number and the index on the approval queue.
+//
+// ctx: the context for the operation.
+//
+// Returns an error if the indexes could not be created.
+func (r *ClaimRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "mongo.ClaimRepository.EnsureIndexes")
+	defer span.End()
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "serial_number", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "_id", Value: 1}}},
+	})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// Create inserts a new claim into the collection.
+//
+// ctx: the context in which the operation is performed.
+// c: the claim to be stored.
+//
+// Returns domain.ErrClaimTransition when the serial number already has a claim, or any other error.
+func (r *ClaimRepository) Create(ctx context.Context, c *domain.ProvisioningClaim) error {
+	ctx, span := tracer.Start(ctx, "mongo.ClaimRepository.Create")
+	defer span.End()
+	result, err := r.collection.InsertOne(ctx, c)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrClaimTransition
+		}
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		c.ID = id
+	}
+	return nil
+}
+
+// GetAll retrieves the claims in a status with pagination, oldest first so the approval queue is worked in order.
+//
+// ctx: the context for the operation.
+// status: the status of the claims, all claims when empty.
+// page: the page number for pagination.
+// limit: the maximum number of claims to retrieve.
+//
+// Returns a slice of claims and an error, if any.
+func (r *ClaimRepository) GetAll(ctx context.Context, status string, page, limit int) ([]domain.ProvisioningClaim, error) {
+	ctx, span := tracer.Start(ctx, "mongo.ClaimRepository.GetAll")
+	defer span.End()
+	query := bson.M{}
+	if status != "" {
+		query["status"] = status
+	}
+	skip := (page - 1) * limit
+	options := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, query, options)
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	var claims []domain.ProvisioningClaim
+	if err = cursor.All(ctx, &claims); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return claims, nil
+}
+
+// GetByID retrieves a claim by its ID.
+//
+// ctx: the context for the operation.
+// id: the ID of the claim.
+//
+// Returns the claim, nil when it does not exist, and an error, if any.
+func (r *ClaimRepository) GetByID(ctx context.Context, id string) (*domain.ProvisioningClaim, error) {
+	ctx, span := tracer.Start(ctx, "mongo.ClaimRepository.GetByID")
+	defer span.End()
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	return r.findOne(ctx, bson.M{"_id": objectID})
+}
+
+// GetBySerialNumber retrieves the claim of a serial number.
+//
+// ctx: the context for the operation.
+// serialNumber: the factory serial number of the device.
+//
+// Returns the claim, nil when the serial number is unknown, and an error, if any.
+func (r *ClaimRepository) GetBySerialNumber(ctx context.Context, serialNumber string) (*domain.ProvisioningClaim, error) {
+	ctx, span := tracer.Start(ctx, "mongo.ClaimRepository.GetBySerialNumber")
+	defer span.End()
+	return r.findOne(ctx, bson.M{"serial_number": serialNumber})
+}
+
+// UpdateStatus stores a claim if it is still in the given status.
+//
+// ctx: the context for the operation.
+// c: the claim with its new status.
+// from: the status the stored claim must be in.
+//
+// Returns domain.ErrClaimTransition when the claim moved on in the meantime, or any other error.
+func (r *ClaimRepository) UpdateStatus(ctx context.Context, c *domain.ProvisioningClaim, from string) error {
+	ctx, span := tracer.Start(ctx, "mongo.ClaimRepository.UpdateStatus")
+	defer span.End()
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": c.ID, "status": from}, c)
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrClaimTransition
+	}
+	return nil
+}
+
+// findOne retrieves the claim matching a query.
+func (r *ClaimRepository) findOne(ctx context.Context, query bson.M) (*domain.ProvisioningClaim, error) {
+	var c domain.ProvisioningClaim
+	if err := r.collection.FindOne(ctx, query).Decode(&c); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return &c, nil
+}
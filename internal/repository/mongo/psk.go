@@ -0,0 +1,97 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PSKRepository is the implementation of the PSKRepositoryInterface.
+type PSKRepository struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+}
+
+// NewPSKRepository creates a new PSKRepository.
+//
+// The PSKRepository is used to interact with the device pre-shared key collection in the database.
+//
+// Parameters:
+// - client: a pointer to a mongo.Client.
+// - config: a pointer to a config.MongoConfig.
+// Returns a pointer to a PSKRepository.
+func NewPSKRepository(client *mongo.Client, config *config.MongoConfig) *PSKRepository {
+	// Get the collection from the database
+	collection := client.Database(config.Database).Collection(config.PSKCollection)
+
+	return &PSKRepository{
+		// The client used to interact with the database
+		client: client,
+		// The collection to interact with
+		collection: collection,
+	}
+}
+
+// EnsureIndexes creates the unique indexes on the device and the identity of the keys.
+//
+// ctx: the context for the operation.
+//
+// Returns an error if the indexes could not be created.
+func (r *PSKRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "mongo.PSKRepository.EnsureIndexes")
+	defer span.End()
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "device_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "identity", Value: 1}}, Options: options.Index().SetUnique(true)},
+	})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// Save creates or replaces the key of a device.
+//
+// ctx: the context for the operation.
+// p: the key to store.
+//
+// Returns an error if the operation was not successful.
+func (r *PSKRepository) Save(ctx context.Context, p *domain.DevicePSK) error {
+	ctx, span := tracer.Start(ctx, "mongo.PSKRepository.Save")
+	defer span.End()
+	options := options.FindOneAndReplace().SetUpsert(true).SetReturnDocument(options.After)
+	// The stored key keeps its ID
+	replacement := *p
+	replacement.ID = primitive.NilObjectID
+	if err := r.collection.FindOneAndReplace(ctx, bson.M{"device_id": p.DeviceID}, replacement, options).Decode(p); err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// GetByIdentity retrieves the key of a PSK identity.
+//
+// ctx: the context for the operation.
+// identity: the PSK identity the device sent.
+//
+// Returns the key, nil when the identity is unknown, and an error, if any.
+func (r *PSKRepository) GetByIdentity(ctx context.Context, identity string) (*domain.DevicePSK, error) {
+	ctx, span := tracer.Start(ctx, "mongo.PSKRepository.GetByIdentity")
+	defer span.End()
+	var p domain.DevicePSK
+	if err := r.collection.FindOne(ctx, bson.M{"identity": identity}).Decode(&p); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return &p, nil
+}
@@ -0,0 +1,103 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// QualityReviewRepository is the implementation of the ReviewRepositoryInterface.
+type QualityReviewRepository struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+}
+
+// NewQualityReviewRepository creates a new QualityReviewRepository.
+//
+// The QualityReviewRepository is used to interact with the quality review audit collection in the database.
+//
+// Parameters:
+// - client: a pointer to a mongo.Client.
+// - config: a pointer to a config.MongoConfig.
+// Returns a pointer to a QualityReviewRepository.
+func NewQualityReviewRepository(client *mongo.Client, config *config.MongoConfig) *QualityReviewRepository {
+	// Get the collection from the database
+	collection := client.Database(config.Database).Collection(config.QualityReviewCollection)
+
+	return &QualityReviewRepository{
+		// The client used to interact with the database
+		client: client,
+		// The collection to interact with
+		collection: collection,
+	}
+}
+
+// EnsureIndexes creates the index on the reviewed measurement.
+//
+// ctx: the context for the operation.
+//
+// Returns an error if the index could not be created.
+func (r *QualityReviewRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "mongo.QualityReviewRepository.EnsureIndexes")
+	defer span.End()
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "measurement_id", Value: 1}},
+	})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// CreateMany adds the reviews to the database.
+//
+// ctx: the context in which the operation is performed.
+// reviews: the reviews to be stored.
+//
+// Returns an error if the operation was not successful.
+func (r *QualityReviewRepository) CreateMany(ctx context.Context, reviews []domain.QualityReview) error {
+	ctx, span := tracer.Start(ctx, "mongo.QualityReviewRepository.CreateMany")
+	defer span.End()
+	if len(reviews) == 0 {
+		return nil
+	}
+	documents := make([]interface{}, 0, len(reviews))
+	for _, review := range reviews {
+		documents = append(documents, review)
+	}
+	if _, err := r.collection.InsertMany(ctx, documents); err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// GetByMeasurementID retrieves the reviews of a measurement, oldest first.
+//
+// ctx: the context for the operation.
+// measurementID: the ID of the reviewed measurement.
+//
+// Returns a list of reviews and an error, if any.
+func (r *QualityReviewRepository) GetByMeasurementID(ctx context.Context, measurementID primitive.ObjectID) ([]domain.QualityReview, error) {
+	ctx, span := tracer.Start(ctx, "mongo.QualityReviewRepository.GetByMeasurementID")
+	defer span.End()
+	options := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"measurement_id": measurementID}, options)
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	var reviews []domain.QualityReview
+	if err = cursor.All(ctx, &reviews); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return reviews, nil
+}
@@ -5,7 +5,6 @@ import (
 
 	"github.com/anggi-susanto/mrt-go/config"
 	"github.com/anggi-susanto/mrt-go/domain"
-	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -45,13 +44,18 @@ func NewSensorRepository(client *mongo.Client, config *config.MongoConfig) *Sens
 //
 // Returns an error if the operation was not successful.
 func (r *SensorRepository) Create(ctx context.Context, w *domain.SensorRequest) error {
+	ctx, span := tracer.Start(ctx, "mongo.SensorRepository.Create")
+	defer span.End()
 	// Insert the new waste water data into the database
-	_, err := r.collection.InsertOne(ctx, w)
+	result, err := r.collection.InsertOne(ctx, w)
 	if err != nil {
 		// Log the error and return it
-		logrus.Error(err)
+		log.WithContext(ctx).Error(err)
 		return err
 	}
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		w.ID = id
+	}
 	return nil
 }
 
@@ -63,6 +67,8 @@ func (r *SensorRepository) Create(ctx context.Context, w *domain.SensorRequest)
 //
 // Returns a list of waste water data and an error, if any.
 func (r *SensorRepository) GetAll(ctx context.Context, page, limit int) ([]domain.Sensor, error) {
+	ctx, span := tracer.Start(ctx, "mongo.SensorRepository.GetAll")
+	defer span.End()
 	// Calculate the skip value based on the page and limit values
 	skip := (page - 1) * limit
 
@@ -73,14 +79,14 @@ func (r *SensorRepository) GetAll(ctx context.Context, page, limit int) ([]domai
 	// Execute the query and get a cursor
 	cursor, err := r.collection.Find(ctx, filter, options)
 	if err != nil {
-		logrus.Error(err)
+		log.WithContext(ctx).Error(err)
 		return nil, err
 	}
 
 	// Decode all the documents in the cursor into a slice of WasteWaterData
 	var wastes []domain.Sensor
 	if err = cursor.All(ctx, &wastes); err != nil {
-		logrus.Error(err)
+		log.WithContext(ctx).Error(err)
 		return nil, err
 	}
 
@@ -100,6 +106,8 @@ func (r *SensorRepository) GetAll(ctx context.Context, page, limit int) ([]domai
 //	*domain.WasteWaterData - pointer to the retrieved WasteWaterData
 //	error - nil if successful, error if not found or any other error occurs
 func (r *SensorRepository) GetByID(ctx context.Context, id string) (*domain.Sensor, error) {
+	ctx, span := tracer.Start(ctx, "mongo.SensorRepository.GetByID")
+	defer span.End()
 	// Define the filter for querying the document by its ID
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -126,6 +134,8 @@ func (r *SensorRepository) GetByID(ctx context.Context, id string) (*domain.Sens
 //
 // Returns an error if the operation was not successful.
 func (r *SensorRepository) Update(ctx context.Context, w *domain.Sensor) error {
+	ctx, span := tracer.Start(ctx, "mongo.SensorRepository.Update")
+	defer span.End()
 	// Define the filter for querying the document by its ID
 	objectID, err := primitive.ObjectIDFromHex(w.ID.Hex())
 	if err != nil {
@@ -140,7 +150,7 @@ func (r *SensorRepository) Update(ctx context.Context, w *domain.Sensor) error {
 	_, err = r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
 		// Log the error and return it
-		logrus.Error(err)
+		log.WithContext(ctx).Error(err)
 		return err
 	}
 
@@ -152,6 +162,8 @@ func (r *SensorRepository) Update(ctx context.Context, w *domain.Sensor) error {
 // If the ID is not found, it returns a MongoDB exception with the NotFound error code.
 // It returns an error if any other error occurs.
 func (r *SensorRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "mongo.SensorRepository.Delete")
+	defer span.End()
 	// Define the filter for querying the document by its ID
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -164,10 +176,56 @@ func (r *SensorRepository) Delete(ctx context.Context, id string) error {
 
 	// If an error occurs, log it and return it
 	if err != nil {
-		logrus.Error(err)
+		log.WithContext(ctx).Error(err)
 		return err
 	}
 
 	// Return a nil error if the operation was successful
 	return nil
 }
+
+// GetByDeviceID retrieves all sensors attached to a device.
+//
+// ctx: the context for the operation.
+// deviceID: the ID of the device.
+//
+// Returns a list of sensors and an error, if any.
+func (r *SensorRepository) GetByDeviceID(ctx context.Context, deviceID primitive.ObjectID) ([]domain.Sensor, error) {
+	ctx, span := tracer.Start(ctx, "mongo.SensorRepository.GetByDeviceID")
+	defer span.End()
+	cursor, err := r.collection.Find(ctx, bson.M{"device_id": deviceID})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	var sensors []domain.Sensor
+	if err = cursor.All(ctx, &sensors); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return sensors, nil
+}
+
+// GetByDeviceIDs retrieves the sensors attached to any of the given devices.
+//
+// ctx: the context for the operation.
+// deviceIDs: the IDs of the devices.
+//
+// Returns a list of sensors and an error, if any.
+func (r *SensorRepository) GetByDeviceIDs(ctx context.Context, deviceIDs []primitive.ObjectID) ([]domain.Sensor, error) {
+	ctx, span := tracer.Start(ctx, "mongo.SensorRepository.GetByDeviceIDs")
+	defer span.End()
+	cursor, err := r.collection.Find(ctx, bson.M{"device_id": bson.M{"$in": deviceIDs}})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	var sensors []domain.Sensor
+	if err = cursor.All(ctx, &sensors); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return sensors, nil
+}
@@ -0,0 +1,238 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SensorTypeRepository is the implementation of the SensorTypeRepositoryInterface.
+type SensorTypeRepository struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+}
+
+// NewSensorTypeRepository creates a new SensorTypeRepository.
+//
+// The SensorTypeRepository is used to interact with the sensor type collection in the database.
+//
+// Parameters:
+// - client: a pointer to a mongo.Client.
+// - config: a pointer to a config.MongoConfig.
+// Returns a pointer to a SensorTypeRepository.
+func NewSensorTypeRepository(client *mongo.Client, config *config.MongoConfig) *SensorTypeRepository {
+	// Get the collection from the database
+	collection := client.Database(config.Database).Collection(config.SensorTypeCollection)
+
+	return &SensorTypeRepository{
+		// The client used to interact with the database
+		client: client,
+		// The collection to interact with
+		collection: collection,
+	}
+}
+
+// EnsureIndexes creates the unique index on the sensor type code.
+//
+// ctx: the context for the operation.
+//
+// Returns an error if the index could not be created.
+func (r *SensorTypeRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "mongo.SensorTypeRepository.EnsureIndexes")
+	defer span.End()
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "code", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// Create adds a new sensor type record to the database.
+//
+// ctx: the context in which the operation is performed.
+// w: the sensor type request to be stored.
+//
+// Returns an error if the operation was not successful.
+func (r *SensorTypeRepository) Create(ctx context.Context, w *domain.SensorTypeRequest) error {
+	ctx, span := tracer.Start(ctx, "mongo.SensorTypeRepository.Create")
+	defer span.End()
+	// Insert the new sensor type into the database
+	_, err := r.collection.InsertOne(ctx, w)
+	if err != nil {
+		// Log the error and return it
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// GetAll retrieves all sensor types with pagination from the SensorTypeRepository.
+//
+// ctx: the context for the operation.
+// page: the page number for pagination.
+// limit: the maximum number of items to return per page.
+//
+// Returns a list of sensor types and an error, if any.
+func (r *SensorTypeRepository) GetAll(ctx context.Context, page, limit int) ([]domain.SensorType, error) {
+	ctx, span := tracer.Start(ctx, "mongo.SensorTypeRepository.GetAll")
+	defer span.End()
+	// Calculate the skip value based on the page and limit values
+	skip := (page - 1) * limit
+
+	// Define the filter and options for the query
+	filter := bson.D{} // empty filter to retrieve all documents
+	options := options.Find().SetSkip(int64(skip)).SetLimit(int64(limit))
+
+	// Execute the query and get a cursor
+	cursor, err := r.collection.Find(ctx, filter, options)
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	// Decode all the documents in the cursor into a slice of SensorType
+	var types []domain.SensorType
+	if err = cursor.All(ctx, &types); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	// Return the slice of SensorType and nil error
+	return types, nil
+}
+
+// GetByID retrieves a SensorType document by its ID.
+//
+// Parameters:
+//
+//	ctx - context.Context: the context for the operation
+//	id - string: the ID of the document to retrieve
+//
+// Returns:
+//
+//	*domain.SensorType - pointer to the retrieved SensorType
+//	error - nil if successful, error if not found or any other error occurs
+func (r *SensorTypeRepository) GetByID(ctx context.Context, id string) (*domain.SensorType, error) {
+	ctx, span := tracer.Start(ctx, "mongo.SensorTypeRepository.GetByID")
+	defer span.End()
+	// Define the filter for querying the document by its ID
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	filter := bson.M{"_id": objectID}
+	// Use the FindOne function to retrieve the document
+	var sensorType domain.SensorType
+	if err := r.collection.FindOne(ctx, filter).Decode(&sensorType); err != nil {
+		// Return nil and the error if the document was not found
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// Return the pointer to the SensorType and a nil error
+	return &sensorType, nil
+}
+
+// Update updates a SensorType in the SensorTypeRepository.
+//
+// ctx: the context for the operation.
+// w: a pointer to the SensorType to update.
+//
+// Returns an error if the operation was not successful.
+func (r *SensorTypeRepository) Update(ctx context.Context, w *domain.SensorType) error {
+	ctx, span := tracer.Start(ctx, "mongo.SensorTypeRepository.Update")
+	defer span.End()
+	// Define the filter for querying the document by its ID
+	filter := bson.M{"_id": w.ID}
+
+	// Define the update operation for the document
+	update := bson.D{{Key: "$set", Value: w}}
+
+	// Use the UpdateOne function to update the document
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		// Log the error and return it
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+
+	// Return a nil error if the operation was successful
+	return nil
+}
+
+// Delete removes a single document from the SensorTypeRepository collection using the provided context and ID.
+// It returns an error if any other error occurs.
+func (r *SensorTypeRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "mongo.SensorTypeRepository.Delete")
+	defer span.End()
+	// Define the filter for querying the document by its ID
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	filter := bson.M{"_id": objectID}
+
+	// Use the DeleteOne function to delete the document
+	_, err = r.collection.DeleteOne(ctx, filter)
+
+	// If an error occurs, log it and return it
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+
+	// Return a nil error if the operation was successful
+	return nil
+}
+
+// GetByIDs retrieves the sensor types matching the given IDs.
+//
+// ctx: the context for the operation.
+// ids: the IDs of the sensor types.
+//
+// Returns a list of sensor types and an error, if any.
+func (r *SensorTypeRepository) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]domain.SensorType, error) {
+	ctx, span := tracer.Start(ctx, "mongo.SensorTypeRepository.GetByIDs")
+	defer span.End()
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	var types []domain.SensorType
+	if err = cursor.All(ctx, &types); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return types, nil
+}
+
+// Upsert inserts the sensor type unless a sensor type with the same code already exists.
+//
+// ctx: the context for the operation.
+// w: the sensor type request to be stored.
+//
+// Returns an error if the operation was not successful.
+func (r *SensorTypeRepository) Upsert(ctx context.Context, w *domain.SensorTypeRequest) error {
+	ctx, span := tracer.Start(ctx, "mongo.SensorTypeRepository.Upsert")
+	defer span.End()
+	filter := bson.M{"code": w.Code}
+	update := bson.D{{Key: "$setOnInsert", Value: w}}
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ShadowRepository is the implementation of the ShadowRepositoryInterface.
+type ShadowRepository struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+}
+
+// NewShadowRepository creates a new ShadowRepository.
+//
+// The ShadowRepository is used to interact with the device shadow collection in the database.
+//
+// Parameters:
+// - client: a pointer to a mongo.Client.
+// - config: a pointer to a config.MongoConfig.
+// Returns a pointer to a ShadowRepository.
+func NewShadowRepository(client *mongo.Client, config *config.MongoConfig) *ShadowRepository {
+	// Get the collection from the database
+	collection := client.Database(config.Database).Collection(config.ShadowCollection)
+
+	return &ShadowRepository{
+		// The client used to interact with the database
+		client: client,
+		// The collection to interact with
+		collection: collection,
+	}
+}
+
+// EnsureIndexes creates the unique index on the device of the shadows.
+//
+// ctx: the context for the operation.
+//
+// Returns an error if the index could not be created.
+func (r *ShadowRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "mongo.ShadowRepository.EnsureIndexes")
+	defer span.End()
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "device_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// GetByDeviceID retrieves the shadow of a device.
+//
+// ctx: the context for the operation.
+// deviceID: the device of the shadow.
+//
+// Returns the shadow, nil when the device has none yet, and an error, if any.
+func (r *ShadowRepository) GetByDeviceID(ctx context.Context, deviceID primitive.ObjectID) (*domain.Shadow, error) {
+	ctx, span := tracer.Start(ctx, "mongo.ShadowRepository.GetByDeviceID")
+	defer span.End()
+	var shadow domain.Shadow
+	if err := r.collection.FindOne(ctx, bson.M{"device_id": deviceID}).Decode(&shadow); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return &shadow, nil
+}
+
+// Save stores the shadow if the stored one is still at the expected version.
+//
+// The shadow is created when the expected version is 0 and the device has no shadow yet.
+//
+// ctx: the context for the operation.
+// shadow: the shadow to store.
+// expectedVersion: the version the shadow was read at.
+//
+// Returns domain.ErrShadowVersion when the shadow was changed in the meantime, or any other error.
+func (r *ShadowRepository) Save(ctx context.Context, shadow *domain.Shadow, expectedVersion int64) error {
+	ctx, span := tracer.Start(ctx, "mongo.ShadowRepository.Save")
+	defer span.End()
+	filter := bson.M{"device_id": shadow.DeviceID, "version": expectedVersion}
+	update := bson.D{{Key: "$set", Value: bson.M{
+		"desired":             shadow.Desired,
+		"reported":            shadow.Reported,
+		"version":             shadow.Version,
+		"desired_updated_at":  shadow.DesiredUpdatedAt,
+		"reported_updated_at": shadow.ReportedUpdatedAt,
+	}}}
+	options := options.Update().SetUpsert(expectedVersion == 0)
+
+	result, err := r.collection.UpdateOne(ctx, filter, update, options)
+	if err != nil {
+		// The upsert of a new shadow collides with one created in the meantime
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrShadowVersion
+		}
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	if result.MatchedCount == 0 && result.UpsertedCount == 0 {
+		return domain.ErrShadowVersion
+	}
+	if id, ok := result.UpsertedID.(primitive.ObjectID); ok {
+		shadow.ID = id
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SiteRepository is the implementation of the SiteRepositoryInterface.
+type SiteRepository struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+}
+
+// NewSiteRepository creates a new SiteRepository.
+//
+// The SiteRepository is used to interact with the site collection in the database.
+//
+// Parameters:
+// - client: a pointer to a mongo.Client.
+// - config: a pointer to a config.MongoConfig.
+// Returns a pointer to a SiteRepository.
+func NewSiteRepository(client *mongo.Client, config *config.MongoConfig) *SiteRepository {
+	// Get the collection from the database
+	collection := client.Database(config.Database).Collection(config.SiteCollection)
+
+	return &SiteRepository{
+		// The client used to interact with the database
+		client: client,
+		// The collection to interact with
+		collection: collection,
+	}
+}
+
+// EnsureIndexes creates the 2dsphere index on the site geometry.
+//
+// ctx: the context for the operation.
+//
+// Returns an error if the index could not be created.
+func (r *SiteRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "mongo.SiteRepository.EnsureIndexes")
+	defer span.End()
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "geometry", Value: "2dsphere"}},
+	})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// Create adds a new site record to the database.
+//
+// ctx: the context in which the operation is performed.
+// w: the site request to be stored.
+//
+// Returns an error if the operation was not successful.
+func (r *SiteRepository) Create(ctx context.Context, w *domain.SiteRequest) error {
+	ctx, span := tracer.Start(ctx, "mongo.SiteRepository.Create")
+	defer span.End()
+	// Insert the new site into the database
+	_, err := r.collection.InsertOne(ctx, w)
+	if err != nil {
+		// Log the error and return it
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// GetAll retrieves all sites with pagination from the SiteRepository.
+//
+// ctx: the context for the operation.
+// page: the page number for pagination.
+// limit: the maximum number of items to return per page.
+//
+// Returns a list of sites and an error, if any.
+func (r *SiteRepository) GetAll(ctx context.Context, page, limit int) ([]domain.Site, error) {
+	ctx, span := tracer.Start(ctx, "mongo.SiteRepository.GetAll")
+	defer span.End()
+	// Calculate the skip value based on the page and limit values
+	skip := (page - 1) * limit
+
+	// Define the filter and options for the query
+	filter := bson.D{} // empty filter to retrieve all documents
+	options := options.Find().SetSkip(int64(skip)).SetLimit(int64(limit))
+
+	// Execute the query and get a cursor
+	cursor, err := r.collection.Find(ctx, filter, options)
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	// Decode all the documents in the cursor into a slice of Site
+	var sites []domain.Site
+	if err = cursor.All(ctx, &sites); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	// Return the slice of Site and nil error
+	return sites, nil
+}
+
+// GetByID retrieves a Site document by its ID.
+//
+// Parameters:
+//
+//	ctx - context.Context: the context for the operation
+//	id - string: the ID of the document to retrieve
+//
+// Returns:
+//
+//	*domain.Site - pointer to the retrieved Site
+//	error - nil if successful, error if not found or any other error occurs
+func (r *SiteRepository) GetByID(ctx context.Context, id string) (*domain.Site, error) {
+	ctx, span := tracer.Start(ctx, "mongo.SiteRepository.GetByID")
+	defer span.End()
+	// Define the filter for querying the document by its ID
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	filter := bson.M{"_id": objectID}
+	// Use the FindOne function to retrieve the document
+	var site domain.Site
+	if err := r.collection.FindOne(ctx, filter).Decode(&site); err != nil {
+		// Return nil and the error if the document was not found
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// Return the pointer to the Site and a nil error
+	return &site, nil
+}
+
+// GetByIDs retrieves the sites with the given IDs.
+//
+// ctx: the context for the operation.
+// ids: the IDs of the sites.
+//
+// Returns the sites found, in no particular order, and an error, if any.
+func (r *SiteRepository) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]domain.Site, error) {
+	ctx, span := tracer.Start(ctx, "mongo.SiteRepository.GetByIDs")
+	defer span.End()
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	var sites []domain.Site
+	if err = cursor.All(ctx, &sites); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return sites, nil
+}
+
+// Update updates a Site in the SiteRepository.
+//
+// ctx: the context for the operation.
+// w: a pointer to the Site to update.
+//
+// Returns an error if the operation was not successful.
+func (r *SiteRepository) Update(ctx context.Context, w *domain.Site) error {
+	ctx, span := tracer.Start(ctx, "mongo.SiteRepository.Update")
+	defer span.End()
+	// Define the filter for querying the document by its ID
+	filter := bson.M{"_id": w.ID}
+
+	// Define the update operation for the document
+	update := bson.D{{Key: "$set", Value: w}}
+
+	// Use the UpdateOne function to update the document
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		// Log the error and return it
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+
+	// Return a nil error if the operation was successful
+	return nil
+}
+
+// Delete removes a single document from the SiteRepository collection using the provided context and ID.
+// It returns an error if any other error occurs.
+func (r *SiteRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "mongo.SiteRepository.Delete")
+	defer span.End()
+	// Define the filter for querying the document by its ID
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	filter := bson.M{"_id": objectID}
+
+	// Use the DeleteOne function to delete the document
+	_, err = r.collection.DeleteOne(ctx, filter)
+
+	// If an error occurs, log it and return it
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+
+	// Return a nil error if the operation was successful
+	return nil
+}
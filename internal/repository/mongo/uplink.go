@@ -0,0 +1,107 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// UplinkRepository is the implementation of the UplinkRepositoryInterface.
+type UplinkRepository struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+}
+
+// NewUplinkRepository creates a new UplinkRepository.
+//
+// The UplinkRepository is used to interact with the LoRaWAN uplink collection in the database.
+//
+// Parameters:
+// - client: a pointer to a mongo.Client.
+// - config: a pointer to a config.MongoConfig.
+// Returns a pointer to an UplinkRepository.
+func NewUplinkRepository(client *mongo.Client, config *config.MongoConfig) *UplinkRepository {
+	// Get the collection from the database
+	collection := client.Database(config.Database).Collection(config.UplinkCollection)
+
+	return &UplinkRepository{
+		// The client used to interact with the database
+		client: client,
+		// The collection to interact with
+		collection: collection,
+	}
+}
+
+// EnsureIndexes creates the index on the uplink history of a device.
+//
+// ctx: the context for the operation.
+//
+// Returns an error if the index could not be created.
+func (r *UplinkRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "mongo.UplinkRepository.EnsureIndexes")
+	defer span.End()
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "device_id", Value: 1}, {Key: "_id", Value: -1}},
+	})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// Create inserts a new uplink into the collection.
+//
+// ctx: the context in which the operation is performed.
+// u: the uplink to be stored.
+//
+// Returns an error if the operation was not successful.
+func (r *UplinkRepository) Create(ctx context.Context, u *domain.UplinkRecord) error {
+	ctx, span := tracer.Start(ctx, "mongo.UplinkRepository.Create")
+	defer span.End()
+	result, err := r.collection.InsertOne(ctx, u)
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return err
+	}
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		u.ID = id
+	}
+	return nil
+}
+
+// GetByDeviceID retrieves the uplinks of a device with pagination, newest first.
+//
+// ctx: the context for the operation.
+// deviceID: the ID of the device.
+// page: the page number for pagination.
+// limit: the maximum number of uplinks to retrieve.
+//
+// Returns a slice of uplinks and an error, if any.
+func (r *UplinkRepository) GetByDeviceID(ctx context.Context, deviceID primitive.ObjectID, page, limit int) ([]domain.UplinkRecord, error) {
+	ctx, span := tracer.Start(ctx, "mongo.UplinkRepository.GetByDeviceID")
+	defer span.End()
+	skip := (page - 1) * limit
+	options := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, bson.M{"device_id": deviceID}, options)
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	var uplinks []domain.UplinkRecord
+	if err = cursor.All(ctx, &uplinks); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	return uplinks, nil
+}
@@ -2,10 +2,10 @@ package mongo
 
 import (
 	"context"
+	"time"
 
 	"github.com/anggi-susanto/mrt-go/config"
 	"github.com/anggi-susanto/mrt-go/domain"
-	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -13,49 +13,119 @@ import (
 )
 
 // WasteWaterRepository is the implementation of the WasteWaterRepositoryInterface.
+//
+// Waste water data is a view over the measurement collection: the measurements
+// sharing a reading_id are pivoted into one WasteWaterData.
 type WasteWaterRepository struct {
 	client     *mongo.Client
 	collection *mongo.Collection
+	legacy     *mongo.Collection
+	migrations *mongo.Collection
+}
+
+// legacyMigration is the ID of the migration of the former waste water collection in the migration collection
+const legacyMigration = "waste_water_measurements"
+
+// readingGroup is a reading and its measurements as grouped by the view aggregations
+type readingGroup struct {
+	ID           primitive.ObjectID   `bson:"_id"`
+	Measurements []domain.Measurement `bson:"measurements"`
 }
 
 // NewWasteWaterRepository creates a new WasteWaterRepository.
 //
-// The WasteWaterRepository is used to interact with the waste water collection in the database.
+// The WasteWaterRepository is used to read waste water data from the measurement collection in the database.
 //
 // Parameters:
 // - client: a pointer to a mongo.Client.
 // - config: a pointer to a config.MongoConfig.
 // Returns a pointer to a WasteWaterRepository.
 func NewWasteWaterRepository(client *mongo.Client, config *config.MongoConfig) *WasteWaterRepository {
-	// Get the collection from the database
-	collection := client.Database(config.Database).Collection(config.WasteWaterCollection)
+	// Get the collections from the database
+	database := client.Database(config.Database)
 
 	return &WasteWaterRepository{
 		// The client used to interact with the database
 		client: client,
-		// The collection to interact with
-		collection: collection,
+		// The measurement collection backing the view
+		collection: database.Collection(config.MeasurementCollection),
+		// The collection holding waste water data stored before measurements
+		legacy: database.Collection(config.WasteWaterCollection),
+		// The collection recording the completed migrations
+		migrations: database.Collection(config.MigrationCollection),
 	}
 }
 
-// Create adds a new waste water record to the database.
+// MigrateLegacy copies the documents of the former waste water collection into measurements.
 //
-// ctx: the context in which the operation is performed.
-// w: the waste water data request to be stored.
+// The legacy document ID becomes the reading ID. Once every document is migrated the migration is
+// recorded and skipped on the next starts, documents already migrated by an interrupted run are
+// skipped. The legacy collection is left untouched.
 //
-// Returns an error if the operation was not successful.
-func (r *WasteWaterRepository) Create(ctx context.Context, w *domain.WastewaterDataRequest) error {
-	// Insert the new waste water data into the database
-	_, err := r.collection.InsertOne(ctx, w)
+// ctx: the context for the operation.
+//
+// Returns the number of migrated documents and an error, if any.
+func (r *WasteWaterRepository) MigrateLegacy(ctx context.Context) (int, error) {
+	ctx, span := tracer.Start(ctx, "mongo.WasteWaterRepository.MigrateLegacy")
+	defer span.End()
+	done, err := r.migrations.CountDocuments(ctx, bson.M{"_id": legacyMigration}, options.Count().SetLimit(1))
 	if err != nil {
-		// Log the error and return it
-		logrus.Error(err)
-		return err
+		log.WithContext(ctx).Error(err)
+		return 0, err
 	}
-	return nil
+	if done > 0 {
+		return 0, nil
+	}
+	cursor, err := r.legacy.Find(ctx, bson.D{})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	migrated := 0
+	for cursor.Next(ctx) {
+		var waste domain.WasteWaterData
+		if err = cursor.Decode(&waste); err != nil {
+			log.WithContext(ctx).Error(err)
+			return migrated, err
+		}
+		count, err := r.collection.CountDocuments(ctx, bson.M{"reading_id": waste.ID}, options.Count().SetLimit(1))
+		if err != nil {
+			log.WithContext(ctx).Error(err)
+			return migrated, err
+		}
+		if count > 0 {
+			continue
+		}
+
+		measurements := domain.NewMeasurements(waste.ID, waste.DeviceID, waste.Timestamp, waste.Values(), waste.Parameters)
+		documents := make([]interface{}, 0, len(measurements))
+		for _, m := range measurements {
+			m.Quality = domain.QualityRaw
+			documents = append(documents, m)
+		}
+		if _, err = r.collection.InsertMany(ctx, documents); err != nil {
+			log.WithContext(ctx).Error(err)
+			return migrated, err
+		}
+		migrated++
+	}
+	if err = cursor.Err(); err != nil {
+		log.WithContext(ctx).Error(err)
+		return migrated, err
+	}
+	_, err = r.migrations.UpdateOne(ctx, bson.M{"_id": legacyMigration},
+		bson.M{"$setOnInsert": bson.M{"completed_at": time.Now().UTC(), "migrated": migrated}},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return migrated, err
+	}
+	return migrated, nil
 }
 
-// GetAll retrieves all waste water data with pagination from the WasteWaterRepository.
+// GetAll retrieves all waste water data with pagination from the WasteWaterRepository, newest first.
 //
 // ctx: the context for the operation.
 // page: the page number for pagination.
@@ -63,84 +133,128 @@ func (r *WasteWaterRepository) Create(ctx context.Context, w *domain.WastewaterD
 //
 // Returns a list of waste water data and an error, if any.
 func (r *WasteWaterRepository) GetAll(ctx context.Context, page, limit int) ([]domain.WasteWaterData, error) {
+	ctx, span := tracer.Start(ctx, "mongo.WasteWaterRepository.GetAll")
+	defer span.End()
 	// Calculate the skip value based on the page and limit values
 	skip := (page - 1) * limit
 
-	// Define the filter and options for the query
-	filter := bson.D{} // empty filter to retrieve all documents
-	options := options.Find().SetSkip(int64(skip)).SetLimit(int64(limit))
-
-	// Execute the query and get a cursor
-	cursor, err := r.collection.Find(ctx, filter, options)
+	// The measurements of a reading share its timestamp, walking the index newest first meets the
+	// readings in order, the walk stops at the end of the page
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().
+		SetSort(bson.D{{Key: "timestamp", Value: -1}, {Key: "reading_id", Value: -1}}).
+		SetProjection(bson.M{"_id": 0, "reading_id": 1}))
 	if err != nil {
-		logrus.Error(err)
+		log.WithContext(ctx).Error(err)
 		return nil, err
 	}
+	defer cursor.Close(ctx)
 
-	// Decode all the documents in the cursor into a slice of WasteWaterData
-	var wastes []domain.WasteWaterData
-	if err = cursor.All(ctx, &wastes); err != nil {
-		logrus.Error(err)
+	seen := map[primitive.ObjectID]bool{}
+	readingIDs := make([]primitive.ObjectID, 0, limit)
+	for len(readingIDs) < limit && cursor.Next(ctx) {
+		var m struct {
+			ReadingID primitive.ObjectID `bson:"reading_id"`
+		}
+		if err = cursor.Decode(&m); err != nil {
+			log.WithContext(ctx).Error(err)
+			return nil, err
+		}
+		if seen[m.ReadingID] {
+			continue
+		}
+		seen[m.ReadingID] = true
+		if len(seen) > skip {
+			readingIDs = append(readingIDs, m.ReadingID)
+		}
+	}
+	if err = cursor.Err(); err != nil {
+		log.WithContext(ctx).Error(err)
 		return nil, err
 	}
+	if len(readingIDs) == 0 {
+		return []domain.WasteWaterData{}, nil
+	}
 
-	// Return the slice of WasteWaterData and nil error
+	// Fetch the measurements of the readings of the page only
+	measurementCursor, err := r.collection.Find(ctx, bson.M{"reading_id": bson.M{"$in": readingIDs}})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	var measurements []domain.Measurement
+	if err = measurementCursor.All(ctx, &measurements); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	byReading := make(map[primitive.ObjectID][]domain.Measurement, len(readingIDs))
+	for _, m := range measurements {
+		byReading[m.ReadingID] = append(byReading[m.ReadingID], m)
+	}
+	wastes := make([]domain.WasteWaterData, 0, len(readingIDs))
+	for _, readingID := range readingIDs {
+		if len(byReading[readingID]) > 0 {
+			wastes = append(wastes, domain.NewWasteWaterData(readingID, byReading[readingID]))
+		}
+	}
 	return wastes, nil
 }
 
-// GetByID retrieves a WasteWaterData document by its ID.
+// GetByID retrieves a WasteWaterData by its reading ID.
 //
 // Parameters:
 //
 //	ctx - context.Context: the context for the operation
-//	id - string: the ID of the document to retrieve
+//	id - string: the ID of the reading to retrieve
 //
 // Returns:
 //
 //	*domain.WasteWaterData - pointer to the retrieved WasteWaterData
 //	error - nil if successful, error if not found or any other error occurs
 func (r *WasteWaterRepository) GetByID(ctx context.Context, id string) (*domain.WasteWaterData, error) {
-	// Define the filter for querying the document by its ID
+	ctx, span := tracer.Start(ctx, "mongo.WasteWaterRepository.GetByID")
+	defer span.End()
+	// Define the filter for querying the measurements by reading ID
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, err
 	}
-	filter := bson.M{"_id": objectID}
-	// Use the FindOne function to retrieve the document
-	var waste domain.WasteWaterData
-	if err := r.collection.FindOne(ctx, filter).Decode(&waste); err != nil {
-		// Return nil and the error if the document was not found
-		if err == mongo.ErrNoDocuments {
-			return nil, nil
-		}
+	cursor, err := r.collection.Find(ctx, bson.M{"reading_id": objectID})
+	if err != nil {
+		log.WithContext(ctx).Error(err)
 		return nil, err
 	}
-	// Return the pointer to the WasteWaterData and a nil error
+
+	var measurements []domain.Measurement
+	if err = cursor.All(ctx, &measurements); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+	// Return nil if the reading was not found
+	if len(measurements) == 0 {
+		return nil, nil
+	}
+	waste := domain.NewWasteWaterData(objectID, measurements)
 	return &waste, nil
 }
 
-// Update updates a WasteWaterData in the WasteWaterRepository.
-//
-// ctx: the context for the operation.
-// w: a pointer to the WasteWaterData to update.
-//
-// Returns an error if the operation was not successful.
-func (r *WasteWaterRepository) Update(ctx context.Context, w *domain.WasteWaterData) error {
-	// Define the filter for querying the document by its ID
-	objectID, err := primitive.ObjectIDFromHex(w.ID.Hex())
+// Delete removes every measurement of a reading using the provided context and ID.
+// It returns an error if any other error occurs.
+func (r *WasteWaterRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "mongo.WasteWaterRepository.Delete")
+	defer span.End()
+	// Define the filter for querying the measurements by reading ID
+	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
-	filter := bson.M{"_id": objectID}
+	filter := bson.M{"reading_id": objectID}
 
-	// Define the update operation for the document
-	update := bson.D{{Key: "$set", Value: w}}
+	// Use the DeleteMany function to delete the measurements
+	_, err = r.collection.DeleteMany(ctx, filter)
 
-	// Use the UpdateOne function to update the document
-	_, err = r.collection.UpdateOne(ctx, filter, update)
+	// If an error occurs, log it and return it
 	if err != nil {
-		// Log the error and return it
-		logrus.Error(err)
+		log.WithContext(ctx).Error(err)
 		return err
 	}
 
@@ -148,26 +262,47 @@ func (r *WasteWaterRepository) Update(ctx context.Context, w *domain.WasteWaterD
 	return nil
 }
 
-// Delete removes a single document from the WasteWaterRepository collection using the provided context and ID.
-// If the ID is not found, it returns a MongoDB exception with the NotFound error code.
-// It returns an error if any other error occurs.
-func (r *WasteWaterRepository) Delete(ctx context.Context, id string) error {
-	// Define the filter for querying the document by its ID
-	objectID, err := primitive.ObjectIDFromHex(id)
-	if err != nil {
-		return err
+// GetLatestByDeviceIDs retrieves the most recent waste water data of each of the given devices.
+//
+// ctx: the context for the operation.
+// deviceIDs: the IDs of the devices.
+//
+// Returns at most one waste water data per device and an error, if any.
+func (r *WasteWaterRepository) GetLatestByDeviceIDs(ctx context.Context, deviceIDs []primitive.ObjectID) ([]domain.WasteWaterData, error) {
+	ctx, span := tracer.Start(ctx, "mongo.WasteWaterRepository.GetLatestByDeviceIDs")
+	defer span.End()
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"device_id": bson.M{"$in": deviceIDs}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "timestamp", Value: -1}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$device_id", "reading_id": bson.M{"$first": "$reading_id"}}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         r.collection.Name(),
+			"localField":   "reading_id",
+			"foreignField": "reading_id",
+			"as":           "measurements",
+		}}},
+		{{Key: "$project", Value: bson.M{"_id": "$reading_id", "measurements": 1}}},
 	}
-	filter := bson.M{"_id": objectID}
-
-	// Use the DeleteOne function to delete the document
-	_, err = r.collection.DeleteOne(ctx, filter)
+	return r.aggregateReadings(ctx, pipeline)
+}
 
-	// If an error occurs, log it and return it
+// aggregateReadings runs a pipeline producing reading groups and pivots them into waste water data.
+func (r *WasteWaterRepository) aggregateReadings(ctx context.Context, pipeline mongo.Pipeline) ([]domain.WasteWaterData, error) {
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
 	if err != nil {
-		logrus.Error(err)
-		return err
+		log.WithContext(ctx).Error(err)
+		return nil, err
 	}
 
-	// Return a nil error if the operation was successful
-	return nil
+	var groups []readingGroup
+	if err = cursor.All(ctx, &groups); err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, err
+	}
+
+	wastes := make([]domain.WasteWaterData, 0, len(groups))
+	for _, group := range groups {
+		wastes = append(wastes, domain.NewWasteWaterData(group.ID, group.Measurements))
+	}
+	return wastes, nil
 }
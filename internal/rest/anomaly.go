@@ -0,0 +1,68 @@
+package rest
+
+import (
+	"context"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+// AnomalyService is the interface that wraps the GetAll method.
+type AnomalyService interface {
+	GetAll(ctx context.Context, filter domain.AnomalyFilter, page, limit int) ([]domain.AnomalyEvent, error)
+}
+
+// AnomalyHandler is the handler for AnomalyService
+type AnomalyHandler struct {
+	service AnomalyService
+}
+
+// NewAnomalyHandler initializes a new AnomalyHandler with the provided Fiber app and AnomalyService.
+//
+// Parameters:
+// - app: The Fiber app instance.
+// - service: The AnomalyService instance.
+//
+// Return type: None.
+func NewAnomalyHandler(app *fiber.App, service AnomalyService) {
+	handler := &AnomalyHandler{service: service}
+	app.Get("/anomalies", handler.GetAll)
+}
+
+// GetAll retrieves the anomaly events matching the query, newest first.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary get anomalies
+// @Description get the anomaly events scored on incoming measurements, filtered by device, parameter and time range
+// @Tags anomaly
+// @Accept json
+// @Produce json
+// @Param device_id query string false "Device ID"
+// @Param parameter query string false "Parameter code"
+// @Param from query string false "Start of the time range (RFC 3339)"
+// @Param to query string false "End of the time range (RFC 3339)"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Success 200 {array} domain.AnomalyEvent "Anomaly events"
+// @Failure 400 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /anomalies [get]
+func (h *AnomalyHandler) GetAll(ctx *fiber.Ctx) error {
+	measurementFilter, err := parseMeasurementFilter(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	filter := domain.AnomalyFilter{
+		DeviceID:  measurementFilter.DeviceID,
+		Parameter: measurementFilter.Parameter,
+		From:      measurementFilter.From,
+		To:        measurementFilter.To,
+	}
+	page := ctx.QueryInt("page", 1)
+	limit := ctx.QueryInt("limit", 10)
+	events, err := h.service.GetAll(ctx.UserContext(), filter, page, limit)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: "Failed to get anomalies"})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(events)
+}
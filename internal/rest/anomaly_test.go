@@ -0,0 +1,55 @@
+package rest_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/rest"
+	"github.com/anggi-susanto/mrt-go/internal/rest/mocks"
+)
+
+func TestAnomalyHandlerGetAll(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	t.Run("Success with filter", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.AnomalyService)
+		rest.NewAnomalyHandler(app, mockService)
+		filter := domain.AnomalyFilter{DeviceID: deviceID, Parameter: domain.ParamCOD}
+		mockService.On("GetAll", mock.Anything, filter, 1, 10).Return([]domain.AnomalyEvent{{Parameter: domain.ParamCOD}}, nil)
+		req := httptest.NewRequest(http.MethodGet, "/anomalies?device_id="+deviceID.Hex()+"&parameter=COD", nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+	t.Run("Invalid device", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.AnomalyService)
+		rest.NewAnomalyHandler(app, mockService)
+		req := httptest.NewRequest(http.MethodGet, "/anomalies?device_id=1", nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+	t.Run("Error", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.AnomalyService)
+		rest.NewAnomalyHandler(app, mockService)
+		mockService.On("GetAll", mock.Anything, domain.AnomalyFilter{}, 1, 10).Return(nil, errors.New("error"))
+		req := httptest.NewRequest(http.MethodGet, "/anomalies", nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+	})
+}
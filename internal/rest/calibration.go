@@ -0,0 +1,117 @@
+package rest
+
+import (
+	"context"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CalibrationService is the interface that wraps the Create, GetHistory and GetOverdue methods.
+type CalibrationService interface {
+	Create(ctx context.Context, c *domain.Calibration) error
+	GetHistory(ctx context.Context, sensorID, parameter string) ([]domain.Calibration, error)
+	GetOverdue(ctx context.Context, at time.Time, page, limit int) ([]domain.Calibration, error)
+}
+
+// CalibrationHandler is the handler for CalibrationService
+type CalibrationHandler struct {
+	service CalibrationService
+}
+
+// NewCalibrationHandler initializes a new CalibrationHandler with the provided Fiber app and CalibrationService.
+//
+// Parameters:
+// - app: The Fiber app instance.
+// - service: The CalibrationService instance.
+//
+// Return type: None.
+func NewCalibrationHandler(app *fiber.App, service CalibrationService) {
+	handler := &CalibrationHandler{service: service}
+	app.Post(SensorIDEndpoint+"/calibration", handler.Create)
+	app.Get(SensorIDEndpoint+"/calibration", handler.GetHistory)
+	app.Get("/calibration/overdue", handler.GetOverdue)
+}
+
+// Create records a calibration of a sensor.
+//
+// @Summary record sensor calibration
+// @Description record a calibration (offset/slope or multi-point curve) applied to the values the sensor reports from calibrated_at onwards
+// @Tags sensor
+// @Accept json
+// @Produce json
+// @Param id path string true "Sensor ID"
+// @Param calibration body domain.Calibration true "calibration"
+// @Success 201 {object} domain.Calibration
+// @Failure 400 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /sensor/{id}/calibration [post]
+func (h *CalibrationHandler) Create(ctx *fiber.Ctx) error {
+	c := &domain.Calibration{}
+	if err := ctx.BodyParser(c); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	sensorID, err := primitive.ObjectIDFromHex(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	c.SensorID = sensorID
+	if err = h.service.Create(ctx.UserContext(), c); err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(c)
+}
+
+// GetHistory retrieves the calibrations of a sensor.
+//
+// @Summary get sensor calibrations
+// @Description get the calibration history of a sensor, latest first
+// @Tags sensor
+// @Accept json
+// @Produce json
+// @Param id path string true "Sensor ID"
+// @Param parameter query string false "Parameter code"
+// @Success 200 {array} domain.Calibration
+// @Failure 400 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /sensor/{id}/calibration [get]
+func (h *CalibrationHandler) GetHistory(ctx *fiber.Ctx) error {
+	calibrations, err := h.service.GetHistory(ctx.UserContext(), ctx.Params("id"), ctx.Query("parameter"))
+	if err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(calibrations)
+}
+
+// GetOverdue retrieves the sensor parameters whose calibration is overdue.
+//
+// @Summary get overdue calibrations
+// @Description get the latest calibration of each sensor parameter whose next calibration was due before the given time, most overdue first
+// @Tags sensor
+// @Accept json
+// @Produce json
+// @Param at query string false "Reference time (RFC 3339), defaults to now"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Success 200 {array} domain.Calibration
+// @Failure 400 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /calibration/overdue [get]
+func (h *CalibrationHandler) GetOverdue(ctx *fiber.Ctx) error {
+	at := time.Now()
+	if value := ctx.Query("at"); value != "" {
+		var err error
+		if at, err = time.Parse(time.RFC3339, value); err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+		}
+	}
+	page := ctx.QueryInt("page", 1)
+	limit := ctx.QueryInt("limit", 10)
+	calibrations, err := h.service.GetOverdue(ctx.UserContext(), at, page, limit)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(calibrations)
+}
@@ -0,0 +1,111 @@
+package rest_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/rest"
+	"github.com/anggi-susanto/mrt-go/internal/rest/mocks"
+)
+
+func TestCalibrationHandlerCreate(t *testing.T) {
+	sensorID := primitive.NewObjectID()
+	body := `{"parameter":"pH","calibrated_at":"2024-03-01T08:00:00Z","technician":"tech","slope":1,"offset":0.05}`
+	t.Run("Success", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.CalibrationService)
+		rest.NewCalibrationHandler(app, mockService)
+		mockService.On("Create", mock.Anything, mock.MatchedBy(func(c *domain.Calibration) bool {
+			return c.SensorID == sensorID && c.Parameter == domain.ParamPH && c.Offset == 0.05
+		})).Return(nil)
+		req := httptest.NewRequest(http.MethodPost, "/sensor/"+sensorID.Hex()+"/calibration", bytes.NewReader([]byte(body)))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.CalibrationService)
+		rest.NewCalibrationHandler(app, mockService)
+		mockService.On("Create", mock.Anything, mock.Anything).Return(fmt.Errorf("%w: slope or points are required", domain.ErrInvalidCalibration))
+		req := httptest.NewRequest(http.MethodPost, "/sensor/"+sensorID.Hex()+"/calibration", bytes.NewReader([]byte(body)))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+	t.Run("Invalid sensor", func(t *testing.T) {
+		app := fiber.New()
+		rest.NewCalibrationHandler(app, new(mocks.CalibrationService))
+		req := httptest.NewRequest(http.MethodPost, "/sensor/1/calibration", bytes.NewReader([]byte(body)))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestCalibrationHandlerGetHistory(t *testing.T) {
+	sensorID := primitive.NewObjectID()
+	app := fiber.New()
+	mockService := new(mocks.CalibrationService)
+	rest.NewCalibrationHandler(app, mockService)
+	mockService.On("GetHistory", mock.Anything, sensorID.Hex(), domain.ParamPH).Return([]domain.Calibration{{SensorID: sensorID}}, nil)
+	req := httptest.NewRequest(http.MethodGet, "/sensor/"+sensorID.Hex()+"/calibration?parameter=pH", nil)
+	resp, err := app.Test(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
+
+func TestCalibrationHandlerGetOverdue(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.CalibrationService)
+		rest.NewCalibrationHandler(app, mockService)
+		at := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+		mockService.On("GetOverdue", mock.Anything, at, 1, 10).Return([]domain.Calibration{{Parameter: domain.ParamPH}}, nil)
+		req := httptest.NewRequest(http.MethodGet, "/calibration/overdue?at=2024-03-01T00:00:00Z", nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+	t.Run("Invalid time", func(t *testing.T) {
+		app := fiber.New()
+		rest.NewCalibrationHandler(app, new(mocks.CalibrationService))
+		req := httptest.NewRequest(http.MethodGet, "/calibration/overdue?at=yesterday", nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+	t.Run("Error", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.CalibrationService)
+		rest.NewCalibrationHandler(app, mockService)
+		mockService.On("GetOverdue", mock.Anything, mock.Anything, 1, 10).Return(nil, errors.New("error"))
+		req := httptest.NewRequest(http.MethodGet, "/calibration/overdue", nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+	})
+}
@@ -0,0 +1,119 @@
+package rest
+
+import (
+	"context"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CommandService is the interface that wraps the device command methods.
+type CommandService interface {
+	Create(ctx context.Context, deviceID string, request domain.CommandRequest) (*domain.Command, error)
+	GetAll(ctx context.Context, deviceID string, status string, page int, limit int) ([]domain.Command, error)
+	GetByID(ctx context.Context, deviceID string, id string) (*domain.Command, error)
+}
+
+// CommandHandler is the handler for CommandService
+type CommandHandler struct {
+	service CommandService
+}
+
+// NewCommandHandler initializes a new CommandHandler with the provided Fiber app and CommandService.
+//
+// Parameters:
+// - app: The Fiber app instance.
+// - service: The CommandService instance.
+//
+// Return type: None.
+func NewCommandHandler(app *fiber.App, service CommandService) {
+	handler := &CommandHandler{service: service}
+	app.Post("/device/:id/commands", handler.Create)
+	app.Get("/device/:id/commands", handler.GetAll)
+	app.Get("/device/:id/commands/:commandID", handler.GetByID)
+}
+
+// Create queues a command for a device.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary send a command to a device
+// @Description queue a one-off command such as flush_sampler, reboot or grab_sample and publish it to the device over MQTT; the command expires when the device does not acknowledge it within its TTL and times out when no result follows within its timeout
+// @Tags device command
+// @Accept json
+// @Produce json
+// @Param id path string true "Device ID"
+// @Param command body domain.CommandRequest true "command name, parameters, TTL and timeout in seconds"
+// @Success 201 {object} domain.Command
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 409 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /device/{id}/commands [post]
+func (h *CommandHandler) Create(ctx *fiber.Ctx) error {
+	request := domain.CommandRequest{}
+	if err := ctx.BodyParser(&request); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	command, err := h.service.Create(ctx.UserContext(), ctx.Params("id"), request)
+	if err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	if command == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ResponseError{Message: "Data not found"})
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(command)
+}
+
+// GetAll retrieves the command history of a device.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary get device command history
+// @Description get the commands sent to a device, newest first
+// @Tags device command
+// @Accept json
+// @Produce json
+// @Param id path string true "Device ID"
+// @Param status query string false "Status (queued, sent, acknowledged, succeeded, failed, expired, timed_out)"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Success 200 {array} domain.Command
+// @Failure 404 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /device/{id}/commands [get]
+func (h *CommandHandler) GetAll(ctx *fiber.Ctx) error {
+	page := ctx.QueryInt("page", 1)
+	limit := ctx.QueryInt("limit", 10)
+	commands, err := h.service.GetAll(ctx.UserContext(), ctx.Params("id"), ctx.Query("status"), page, limit)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: "Failed to get all commands"})
+	}
+	if commands == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ResponseError{Message: "Data not found"})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(commands)
+}
+
+// GetByID retrieves a command of a device.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary get device command
+// @Description get a command with its delivery, acknowledgement and result
+// @Tags device command
+// @Accept json
+// @Produce json
+// @Param id path string true "Device ID"
+// @Param commandID path string true "Command ID"
+// @Success 200 {object} domain.Command
+// @Failure 404 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /device/{id}/commands/{commandID} [get]
+func (h *CommandHandler) GetByID(ctx *fiber.Ctx) error {
+	command, err := h.service.GetByID(ctx.UserContext(), ctx.Params("id"), ctx.Params("commandID"))
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
+	}
+	if command == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ResponseError{Message: "Data not found"})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(command)
+}
@@ -0,0 +1,123 @@
+package rest_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/rest"
+	"github.com/anggi-susanto/mrt-go/internal/rest/mocks"
+)
+
+func TestCommandHandlerCreate(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	body := `{"name":"grab_sample","params":{"bottle":3},"ttl":600}`
+	t.Run("Success", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.CommandService)
+		rest.NewCommandHandler(app, mockService)
+		mockService.On("Create", mock.Anything, deviceID.Hex(), mock.MatchedBy(func(r domain.CommandRequest) bool {
+			return r.Name == domain.CommandGrabSample && r.TTL == 600 && r.Params["bottle"] == 3.0
+		})).Return(&domain.Command{DeviceID: deviceID, Status: domain.CommandSent}, nil)
+		req := httptest.NewRequest(http.MethodPost, "/device/"+deviceID.Hex()+"/commands", bytes.NewReader([]byte(body)))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+	t.Run("Invalid command", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.CommandService)
+		rest.NewCommandHandler(app, mockService)
+		mockService.On("Create", mock.Anything, deviceID.Hex(), mock.Anything).Return(nil, domain.ErrInvalidCommand)
+		req := httptest.NewRequest(http.MethodPost, "/device/"+deviceID.Hex()+"/commands", bytes.NewReader([]byte(`{}`)))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+	t.Run("Decommissioned device", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.CommandService)
+		rest.NewCommandHandler(app, mockService)
+		mockService.On("Create", mock.Anything, deviceID.Hex(), mock.Anything).Return(nil, domain.ErrDeviceDecommissioned)
+		req := httptest.NewRequest(http.MethodPost, "/device/"+deviceID.Hex()+"/commands", bytes.NewReader([]byte(body)))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+	})
+	t.Run("Not found", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.CommandService)
+		rest.NewCommandHandler(app, mockService)
+		mockService.On("Create", mock.Anything, deviceID.Hex(), mock.Anything).Return(nil, nil)
+		req := httptest.NewRequest(http.MethodPost, "/device/"+deviceID.Hex()+"/commands", bytes.NewReader([]byte(body)))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestCommandHandlerGetAll(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	t.Run("Success", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.CommandService)
+		rest.NewCommandHandler(app, mockService)
+		mockService.On("GetAll", mock.Anything, deviceID.Hex(), domain.CommandFailed, 2, 5).Return([]domain.Command{{DeviceID: deviceID}}, nil)
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/device/"+deviceID.Hex()+"/commands?status=failed&page=2&limit=5", nil))
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+	t.Run("Not found", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.CommandService)
+		rest.NewCommandHandler(app, mockService)
+		mockService.On("GetAll", mock.Anything, deviceID.Hex(), "", 1, 10).Return(nil, nil)
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/device/"+deviceID.Hex()+"/commands", nil))
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestCommandHandlerGetByID(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	commandID := primitive.NewObjectID()
+	t.Run("Success", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.CommandService)
+		rest.NewCommandHandler(app, mockService)
+		mockService.On("GetByID", mock.Anything, deviceID.Hex(), commandID.Hex()).Return(&domain.Command{ID: commandID, DeviceID: deviceID}, nil)
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/device/"+deviceID.Hex()+"/commands/"+commandID.Hex(), nil))
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+	t.Run("Not found", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.CommandService)
+		rest.NewCommandHandler(app, mockService)
+		mockService.On("GetByID", mock.Anything, deviceID.Hex(), commandID.Hex()).Return(nil, nil)
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/device/"+deviceID.Hex()+"/commands/"+commandID.Hex(), nil))
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -2,9 +2,13 @@ package rest
 
 import (
 	"context"
+	"errors"
+	"strconv"
+	"strings"
 
 	"github.com/anggi-susanto/mrt-go/domain"
 	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // ResponseError represents an error response
@@ -13,10 +17,14 @@ import (
 
 type DeviceService interface {
 	Create(ctx context.Context, w *domain.DeviceRequest) error
-	GetAll(ctx context.Context, page int, limit int) ([]domain.Device, error)
+	GetAll(ctx context.Context, filter domain.DeviceFilter, page int, limit int) ([]domain.Device, error)
 	Delete(ctx context.Context, id string) error
 	Update(ctx context.Context, w *domain.Device) error
 	GetByID(ctx context.Context, id string) (*domain.Device, error)
+	GetNear(ctx context.Context, near domain.NearQuery, page int, limit int) ([]domain.Device, error)
+	GetFeatureCollection(ctx context.Context, near *domain.NearQuery, page int, limit int) (*domain.FeatureCollection, error)
+	Transition(ctx context.Context, id string, t domain.DeviceTransition) (*domain.Device, error)
+	GetInventory(ctx context.Context) ([]domain.InventoryCount, error)
 }
 
 // DeviceHandler is the handler for DeviceService
@@ -27,6 +35,12 @@ type DeviceHandler struct {
 // DeviceIDEndpoint is the endpoint for DeviceService
 const DeviceIDEndpoint = "/device/:id"
 
+// defaultNearRadius is the search radius in meters used when near is given without radius
+const defaultNearRadius = 1000
+
+// errInvalidNear is returned when the near query parameter is not a lat,lng pair
+var errInvalidNear = errors.New("near must be formatted as lat,lng")
+
 // NewDeviceHandler initializes a new DeviceHandler with the provided Fiber app and DeviceService.
 //
 // Parameters:
@@ -38,6 +52,9 @@ func NewDeviceHandler(app *fiber.App, service DeviceService) {
 	handler := &DeviceHandler{service: service}
 	app.Post("/device", handler.Create)
 	app.Get("/device", handler.GetAll)
+	app.Get("/device/geojson", handler.GetFeatureCollection)
+	app.Get("/device/inventory", handler.GetInventory)
+	app.Post("/device/:id/transition", handler.Transition)
 	app.Get(DeviceIDEndpoint, handler.GetByID)
 	app.Put(DeviceIDEndpoint, handler.Update)
 	app.Delete(DeviceIDEndpoint, handler.Delete)
@@ -60,8 +77,8 @@ func (h *DeviceHandler) Create(ctx *fiber.Ctx) error {
 	if err := ctx.BodyParser(w); err != nil {
 		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
 	}
-	if err := h.service.Create(ctx.Context(), w); err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
+	if err := h.service.Create(ctx.UserContext(), w); err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
 	}
 	return ctx.Status(fiber.StatusCreated).JSON(w)
 }
@@ -75,19 +92,124 @@ func (h *DeviceHandler) Create(ctx *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param page query int true "Page number"
+// @Param status query string false "Lifecycle status (procured, installed, active, maintenance, decommissioned)"
+// @Param model query string false "Device model"
+// @Param site_id query string false "Site ID"
+// @Param near query string false "Center point formatted as lat,lng"
+// @Param radius query number false "Search radius in meters, defaults to 1000"
 // @Success 200 {array} domain.Device "Device data"
+// @Failure 400 {object} ResponseError
 // @Failure 500 {object} ResponseError
 // @Router /device [get]
 func (h *DeviceHandler) GetAll(ctx *fiber.Ctx) error {
 	page := ctx.QueryInt("page", 1)
 	limit := ctx.QueryInt("limit", 10)
-	wastes, err := h.service.GetAll(ctx.Context(), page, limit)
+	near, err := parseNearQuery(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	if near != nil {
+		devices, err := h.service.GetNear(ctx.UserContext(), *near, page, limit)
+		if err != nil {
+			return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+		}
+		return ctx.Status(fiber.StatusOK).JSON(devices)
+	}
+	filter := domain.DeviceFilter{Status: ctx.Query("status"), Model: ctx.Query("model")}
+	if filter.Status != "" && !domain.IsDeviceStatus(filter.Status) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: "unknown status " + filter.Status})
+	}
+	if siteID := ctx.Query("site_id"); siteID != "" {
+		if filter.SiteID, err = primitive.ObjectIDFromHex(siteID); err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+		}
+	}
+	wastes, err := h.service.GetAll(ctx.UserContext(), filter, page, limit)
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: "Failed to get all device data"})
 	}
 	return ctx.Status(fiber.StatusOK).JSON(wastes)
 }
 
+// GetInventory counts the devices per lifecycle status and model.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary get device inventory
+// @Description count the devices per lifecycle status and model
+// @Tags device
+// @Accept json
+// @Produce json
+// @Success 200 {array} domain.InventoryCount
+// @Failure 500 {object} ResponseError
+// @Router /device/inventory [get]
+func (h *DeviceHandler) GetInventory(ctx *fiber.Ctx) error {
+	counts, err := h.service.GetInventory(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(counts)
+}
+
+// Transition moves a device to another lifecycle status.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary change device lifecycle status
+// @Description move a device to another lifecycle status, installing it records a new entry in its install history
+// @Tags device
+// @Accept json
+// @Produce json
+// @Param id path string true "Device data ID"
+// @Param transition body domain.DeviceTransition true "requested status"
+// @Success 200 {object} domain.Device
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 409 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /device/{id}/transition [post]
+func (h *DeviceHandler) Transition(ctx *fiber.Ctx) error {
+	t := domain.DeviceTransition{}
+	if err := ctx.BodyParser(&t); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	d, err := h.service.Transition(ctx.UserContext(), ctx.Params("id"), t)
+	if err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	if d == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ResponseError{Message: "Data not found"})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(d)
+}
+
+// GetFeatureCollection retrieves the located devices as GeoJSON.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary get devices as GeoJSON
+// @Description get located devices and their latest readings as a GeoJSON FeatureCollection
+// @Tags device
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number"
+// @Param near query string false "Center point formatted as lat,lng"
+// @Param radius query number false "Search radius in meters, defaults to 1000"
+// @Success 200 {object} domain.FeatureCollection
+// @Failure 400 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /device/geojson [get]
+func (h *DeviceHandler) GetFeatureCollection(ctx *fiber.Ctx) error {
+	page := ctx.QueryInt("page", 1)
+	limit := ctx.QueryInt("limit", 10)
+	near, err := parseNearQuery(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	collection, err := h.service.GetFeatureCollection(ctx.UserContext(), near, page, limit)
+	if err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(collection)
+}
+
 // GetByID retrieves a WasteWater object by ID.
 //
 // ctx *fiber.Ctx - Context object containing the request information.
@@ -104,7 +226,7 @@ func (h *DeviceHandler) GetAll(ctx *fiber.Ctx) error {
 // @Failure 404 {object} ResponseError
 func (h *DeviceHandler) GetByID(ctx *fiber.Ctx) error {
 	id := ctx.Params("id")
-	w, err := h.service.GetByID(ctx.Context(), id)
+	w, err := h.service.GetByID(ctx.UserContext(), id)
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
 	}
@@ -119,7 +241,7 @@ func (h *DeviceHandler) GetByID(ctx *fiber.Ctx) error {
 //
 // It takes a fiber context as a parameter and returns an error.
 // @Summary update device data
-// @Description update device data
+// @Description update device data, the site and location only change when the device is installed, use the transition endpoint to move it
 // @Tags device
 // @Accept json
 // @Produce json
@@ -129,13 +251,14 @@ func (h *DeviceHandler) GetByID(ctx *fiber.Ctx) error {
 // @Failure 500 {object} ResponseError
 // @Router /device/{id} [put]
 // @Failure 404 {object} ResponseError
+// @Failure 409 {object} ResponseError
 func (h *DeviceHandler) Update(ctx *fiber.Ctx) error {
 	w := &domain.Device{}
 	if err := ctx.BodyParser(w); err != nil {
 		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
 	}
-	if err := h.service.Update(ctx.Context(), w); err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
+	if err := h.service.Update(ctx.UserContext(), w); err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
 	}
 	return ctx.Status(fiber.StatusOK).JSON(w)
 }
@@ -155,8 +278,33 @@ func (h *DeviceHandler) Update(ctx *fiber.Ctx) error {
 // @Failure 404 {object} ResponseError
 func (h *DeviceHandler) Delete(ctx *fiber.Ctx) error {
 	id := ctx.Params("id")
-	if err := h.service.Delete(ctx.Context(), id); err != nil {
+	if err := h.service.Delete(ctx.UserContext(), id); err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
 	}
 	return ctx.SendStatus(fiber.StatusNoContent)
 }
+
+// parseNearQuery reads the near=lat,lng and radius query parameters, it returns nil when near is absent.
+func parseNearQuery(ctx *fiber.Ctx) (*domain.NearQuery, error) {
+	near := ctx.Query("near")
+	if near == "" {
+		return nil, nil
+	}
+	parts := strings.Split(near, ",")
+	if len(parts) != 2 {
+		return nil, errInvalidNear
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return nil, errInvalidNear
+	}
+	lng, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return nil, errInvalidNear
+	}
+	radius, err := strconv.ParseFloat(ctx.Query("radius", strconv.Itoa(defaultNearRadius)), 64)
+	if err != nil {
+		return nil, errors.New("radius must be a number of meters")
+	}
+	return &domain.NearQuery{Lat: lat, Lng: lng, Radius: radius}, nil
+}
@@ -0,0 +1,82 @@
+package rest
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DeviceAuthenticator is the interface that wraps the Authenticate method.
+type DeviceAuthenticator interface {
+	Authenticate(ctx context.Context, username, password string) (primitive.ObjectID, error)
+}
+
+// deviceLocal is the key of the authenticated device in the locals of a request
+const deviceLocal = "device"
+
+// DeviceAuth returns the middleware admitting the devices that authenticate with the credentials
+// issued when they were provisioned, over HTTP basic authentication.
+//
+// Parameters:
+// - authenticator: The DeviceAuthenticator checking the credentials.
+//
+// Returns the middleware, it answers 401 to a request without valid credentials.
+func DeviceAuth(authenticator DeviceAuthenticator) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		username, password, ok := basicAuth(ctx.Get(fiber.HeaderAuthorization))
+		if !ok {
+			return unauthorized(ctx)
+		}
+		deviceID, err := authenticator.Authenticate(ctx.UserContext(), username, password)
+		if err != nil {
+			return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
+		}
+		if deviceID.IsZero() {
+			return unauthorized(ctx)
+		}
+		ctx.Locals(deviceLocal, deviceID)
+		return ctx.Next()
+	}
+}
+
+// basicAuth parses the credentials of an HTTP basic Authorization header.
+func basicAuth(header string) (username, password string, ok bool) {
+	encoded, found := strings.CutPrefix(header, "Basic ")
+	if !found {
+		return "", "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", "", false
+	}
+	return strings.Cut(string(decoded), ":")
+}
+
+// unauthorized answers a request without valid device credentials.
+func unauthorized(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderWWWAuthenticate, `Basic realm="devices"`)
+	return ctx.Status(fiber.StatusUnauthorized).JSON(ResponseError{Message: "Invalid device credentials"})
+}
+
+// bindDevice attributes data to the device the request authenticated as: data without a device
+// gets the authenticated device and data of another device is refused with ErrDeviceMismatch.
+// Requests routed without device authentication keep the device they name.
+func bindDevice(ctx *fiber.Ctx, deviceID *primitive.ObjectID) error {
+	authenticated, ok := ctx.Locals(deviceLocal).(primitive.ObjectID)
+	if !ok {
+		return nil
+	}
+	if deviceID.IsZero() {
+		*deviceID = authenticated
+		return nil
+	}
+	if *deviceID != authenticated {
+		return fmt.Errorf("%w: authenticated as %s, not %s", domain.ErrDeviceMismatch, authenticated.Hex(), deviceID.Hex())
+	}
+	return nil
+}
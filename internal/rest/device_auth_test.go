@@ -0,0 +1,96 @@
+package rest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/rest"
+	"github.com/anggi-susanto/mrt-go/internal/rest/mocks"
+	"github.com/anggi-susanto/mrt-go/units"
+)
+
+func TestDeviceAuth(t *testing.T) {
+	deviceID, _ := primitive.ObjectIDFromHex("663200000000000000000001")
+	otherID, _ := primitive.ObjectIDFromHex("663200000000000000000002")
+	post := func(t *testing.T, service *mocks.WasteWaterServices, path, body string, basic bool) *http.Response {
+		app := fiber.New()
+		authenticator := new(mocks.DeviceAuthenticator)
+		authenticator.On("Authenticate", mock.Anything, deviceID.Hex(), "secret").Return(deviceID, nil)
+		rest.NewWasteWaterHandler(app, service, units.Default, rest.DeviceAuth(authenticator))
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader([]byte(body)))
+		req.Header.Set(contentType, applicationJson)
+		if basic {
+			req.SetBasicAuth(deviceID.Hex(), "secret")
+		}
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		return resp
+	}
+	t.Run("Without credentials", func(t *testing.T) {
+		resp := post(t, new(mocks.WasteWaterServices), "/waste-water", `{"bod":10}`, false)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+		assert.NotEmpty(t, resp.Header.Get(fiber.HeaderWWWAuthenticate))
+	})
+	t.Run("Wrong credentials", func(t *testing.T) {
+		for username, password := range map[string]string{deviceID.Hex(): "wrong", "broken": "secret"} {
+			app := fiber.New()
+			authenticator := new(mocks.DeviceAuthenticator)
+			expected := fiber.StatusUnauthorized
+			if username == "broken" {
+				expected = fiber.StatusInternalServerError
+				authenticator.On("Authenticate", mock.Anything, username, password).Return(primitive.NilObjectID, errors.New("database error"))
+			} else {
+				authenticator.On("Authenticate", mock.Anything, username, password).Return(primitive.NilObjectID, nil)
+			}
+			rest.NewWasteWaterHandler(app, new(mocks.WasteWaterServices), units.Default, rest.DeviceAuth(authenticator))
+			req := httptest.NewRequest(http.MethodPost, "/waste-water", bytes.NewReader([]byte(`{"bod":10}`)))
+			req.Header.Set(contentType, applicationJson)
+			req.SetBasicAuth(username, password)
+			resp, err := app.Test(req)
+			assert.Nil(t, err)
+			resp.Body.Close()
+			assert.Equal(t, expected, resp.StatusCode)
+		}
+	})
+	t.Run("Reading bound to the device", func(t *testing.T) {
+		service := new(mocks.WasteWaterServices)
+		service.On("Create", mock.Anything, mock.MatchedBy(func(w *domain.WastewaterDataRequest) bool {
+			return w.DeviceID == deviceID
+		})).Return(nil)
+		resp := post(t, service, "/waste-water", `{"bod":10}`, true)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		service.AssertExpectations(t)
+	})
+	t.Run("Reading of another device", func(t *testing.T) {
+		resp := post(t, new(mocks.WasteWaterServices), "/waste-water", `{"device_id":"`+otherID.Hex()+`"}`, true)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+	})
+	t.Run("Batch reading of another device", func(t *testing.T) {
+		service := new(mocks.WasteWaterServices)
+		service.On("Create", mock.Anything, mock.MatchedBy(func(w *domain.WastewaterDataRequest) bool {
+			return w.DeviceID == deviceID
+		})).Return(nil).Once()
+		body := `{"readings":[{"dedup_key":"a:1"},{"dedup_key":"a:2","device_id":"` + otherID.Hex() + `"}]}`
+		resp := post(t, service, "/waste-water/batch", body, true)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		result := domain.WasteWaterBatchResult{}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		assert.Equal(t, domain.BatchCreated, result.Items[0].Status)
+		assert.Equal(t, domain.BatchRejected, result.Items[1].Status)
+		service.AssertExpectations(t)
+	})
+}
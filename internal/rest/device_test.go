@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -97,7 +98,7 @@ func TestDeviceHandlerGetAll(t *testing.T) {
 		app := fiber.New()
 		mockService := new(mocks.DeviceService) // Implement a mock service for testing purposes
 		rest.NewDeviceHandler(app, mockService)
-		mockService.On("GetAll", mock.Anything, 1, 10).Return(device, nil)
+		mockService.On("GetAll", mock.Anything, domain.DeviceFilter{}, 1, 10).Return(device, nil)
 		req := httptest.NewRequest(http.MethodGet, "/device", nil)
 		resp, err := app.Test(req)
 		assert.Nil(t, err)
@@ -116,7 +117,7 @@ func TestDeviceHandlerGetAll(t *testing.T) {
 		app := fiber.New()
 		mockService := new(mocks.DeviceService) // Implement a mock service for testing purposes
 		rest.NewDeviceHandler(app, mockService)
-		mockService.On("GetAll", mock.Anything, 1, 10).Return(nil, errors.New("error"))
+		mockService.On("GetAll", mock.Anything, domain.DeviceFilter{}, 1, 10).Return(nil, errors.New("error"))
 		req := httptest.NewRequest(http.MethodGet, "/device", nil)
 		resp, err := app.Test(req)
 		assert.Nil(t, err)
@@ -189,3 +190,166 @@ func TestDeviceHandlerGetByID(t *testing.T) {
 		assert.Equal(t, "{\"message\":\"error\"}", string(data))
 	})
 }
+
+func TestDeviceHandlerGetNear(t *testing.T) {
+	device := []domain.Device{
+		{
+			Name:     "device",
+			Location: domain.NewGeoPoint(-6.1935, 106.8227),
+		},
+	}
+	t.Run("Success with default radius", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.DeviceService)
+		rest.NewDeviceHandler(app, mockService)
+		near := domain.NearQuery{Lat: -6.19, Lng: 106.82, Radius: 1000}
+		mockService.On("GetNear", mock.Anything, near, 1, 10).Return(device, nil)
+		req := httptest.NewRequest(http.MethodGet, "/device?near=-6.19,106.82", nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		mockService.AssertNotCalled(t, "GetAll")
+	})
+
+	t.Run("Invalid near", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.DeviceService)
+		rest.NewDeviceHandler(app, mockService)
+		req := httptest.NewRequest(http.MethodGet, "/device?near=somewhere&radius=500", nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+		data, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "{\"message\":\"near must be formatted as lat,lng\"}", string(data))
+	})
+}
+
+func TestDeviceHandlerGetFeatureCollection(t *testing.T) {
+	collection := &domain.FeatureCollection{
+		Type: domain.GeoJSONFeatureCollection,
+		Features: []domain.Feature{
+			{
+				Type:       domain.GeoJSONFeature,
+				Geometry:   domain.NewGeoPoint(-6.1935, 106.8227),
+				Properties: map[string]interface{}{"name": "device"},
+			},
+		},
+	}
+	t.Run("Success", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.DeviceService)
+		rest.NewDeviceHandler(app, mockService)
+		near := &domain.NearQuery{Lat: -6.19, Lng: 106.82, Radius: 250}
+		mockService.On("GetFeatureCollection", mock.Anything, near, 1, 10).Return(collection, nil)
+		req := httptest.NewRequest(http.MethodGet, "/device/geojson?near=-6.19,106.82&radius=250", nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var response domain.FeatureCollection
+		data, _ := io.ReadAll(resp.Body)
+		err = json.Unmarshal(data, &response)
+		assert.Nil(t, err)
+		assert.Equal(t, domain.GeoJSONFeatureCollection, response.Type)
+		assert.Len(t, response.Features, 1)
+	})
+
+	t.Run("Error case", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.DeviceService)
+		rest.NewDeviceHandler(app, mockService)
+		mockService.On("GetFeatureCollection", mock.Anything, mock.Anything, 1, 10).Return(nil, errors.New("error"))
+		req := httptest.NewRequest(http.MethodGet, "/device/geojson", nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+	})
+}
+
+func TestDeviceHandlerGetAllInventoryFilter(t *testing.T) {
+	siteID := primitive.NewObjectID()
+	t.Run("Success", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.DeviceService)
+		rest.NewDeviceHandler(app, mockService)
+		filter := domain.DeviceFilter{Status: domain.DeviceActive, Model: "MRT-100", SiteID: siteID}
+		mockService.On("GetAll", mock.Anything, filter, 1, 10).Return([]domain.Device{}, nil)
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/device?status=active&model=MRT-100&site_id="+siteID.Hex(), nil))
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+	t.Run("Unknown status", func(t *testing.T) {
+		app := fiber.New()
+		rest.NewDeviceHandler(app, new(mocks.DeviceService))
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/device?status=lost", nil))
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestDeviceHandlerGetInventory(t *testing.T) {
+	app := fiber.New()
+	mockService := new(mocks.DeviceService)
+	rest.NewDeviceHandler(app, mockService)
+	mockService.On("GetInventory", mock.Anything).Return([]domain.InventoryCount{{Status: domain.DeviceActive, Model: "MRT-100", Count: 3}}, nil)
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/device/inventory", nil))
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var counts []domain.InventoryCount
+	data, _ := io.ReadAll(resp.Body)
+	assert.Nil(t, json.Unmarshal(data, &counts))
+	assert.Equal(t, 3, counts[0].Count)
+}
+
+func TestDeviceHandlerTransition(t *testing.T) {
+	id := primitive.NewObjectID()
+	body := `{"status":"decommissioned","reason":"probe housing cracked"}`
+	t.Run("Success", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.DeviceService)
+		rest.NewDeviceHandler(app, mockService)
+		mockService.On("Transition", mock.Anything, id.Hex(), mock.MatchedBy(func(t domain.DeviceTransition) bool {
+			return t.Status == domain.DeviceDecommissioned
+		})).Return(&domain.Device{ID: id, Status: domain.DeviceDecommissioned}, nil)
+		req := httptest.NewRequest(http.MethodPost, "/device/"+id.Hex()+"/transition", bytes.NewReader([]byte(body)))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+	t.Run("Not allowed", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.DeviceService)
+		rest.NewDeviceHandler(app, mockService)
+		mockService.On("Transition", mock.Anything, id.Hex(), mock.Anything).Return(nil, fmt.Errorf("%w: from decommissioned to active", domain.ErrDeviceTransition))
+		req := httptest.NewRequest(http.MethodPost, "/device/"+id.Hex()+"/transition", bytes.NewReader([]byte(body)))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+	})
+	t.Run("Not found", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.DeviceService)
+		rest.NewDeviceHandler(app, mockService)
+		mockService.On("Transition", mock.Anything, id.Hex(), mock.Anything).Return(nil, nil)
+		req := httptest.NewRequest(http.MethodPost, "/device/"+id.Hex()+"/transition", bytes.NewReader([]byte(body)))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -0,0 +1,35 @@
+package rest
+
+import (
+	"errors"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+// statusFromError maps domain validation errors to 400, 403, 409 or 422 and anything else to 500.
+func statusFromError(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidGeometry), errors.Is(err, domain.ErrInvalidSensorType),
+		errors.Is(err, domain.ErrInvalidMeasurement), errors.Is(err, domain.ErrUnknownUnit),
+		errors.Is(err, domain.ErrUnknownUnitSystem), errors.Is(err, domain.ErrInvalidQuality),
+		errors.Is(err, domain.ErrInvalidLabSample), errors.Is(err, domain.ErrInvalidCalibration),
+		errors.Is(err, domain.ErrInvalidWorkOrder), errors.Is(err, domain.ErrInvalidDevice),
+		errors.Is(err, domain.ErrInvalidShadow), errors.Is(err, domain.ErrInvalidCommand),
+		errors.Is(err, domain.ErrInvalidProvisioning), errors.Is(err, domain.ErrInvalidModbus),
+		errors.Is(err, domain.ErrInvalidLoRaWAN),
+		errors.Is(err, domain.ErrInvalidIngest):
+		return fiber.StatusBadRequest
+	case errors.Is(err, domain.ErrQualityTransition), errors.Is(err, domain.ErrDeviceTransition),
+		errors.Is(err, domain.ErrDeviceDecommissioned), errors.Is(err, domain.ErrShadowVersion),
+		errors.Is(err, domain.ErrCommandTransition), errors.Is(err, domain.ErrClaimTransition),
+		errors.Is(err, domain.ErrReviewedMeasurement), errors.Is(err, domain.ErrWorkOrderTransition):
+		return fiber.StatusConflict
+	case errors.Is(err, domain.ErrClaimRefused), errors.Is(err, domain.ErrDeviceMismatch):
+		return fiber.StatusForbidden
+	case errors.Is(err, domain.ErrOutOfRange):
+		return fiber.StatusUnprocessableEntity
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,172 @@
+package rest
+
+import (
+	"context"
+	"strings"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+// IngestService is the interface that wraps the ingestion of third-party gateway formats.
+type IngestService interface {
+	IngestSenML(ctx context.Context, source string, payload []byte, contentType string) (*domain.IngestResult, error)
+	IngestLineProtocol(ctx context.Context, source string, payload []byte, precision string) (*domain.IngestResult, error)
+	SaveMapping(ctx context.Context, mapping domain.IngestMapping) (*domain.IngestMapping, error)
+	GetMappings(ctx context.Context) ([]domain.IngestMapping, error)
+	DeleteMapping(ctx context.Context, source string) error
+}
+
+// IngestHandler is the handler for IngestService
+type IngestHandler struct {
+	service IngestService
+}
+
+// senMLContentTypes maps the accepted content types onto the SenML encodings
+var senMLContentTypes = map[string]string{
+	domain.SenMLJSON:          domain.SenMLJSON,
+	fiber.MIMEApplicationJSON: domain.SenMLJSON,
+	domain.SenMLCBOR:          domain.SenMLCBOR,
+	"application/cbor":        domain.SenMLCBOR,
+}
+
+// NewIngestHandler initializes a new IngestHandler with the provided Fiber app and IngestService.
+//
+// Parameters:
+// - app: The Fiber app instance.
+// - service: The IngestService instance.
+//
+// Return type: None.
+func NewIngestHandler(app *fiber.App, service IngestService) {
+	handler := &IngestHandler{service: service}
+	app.Put("/ingest/mappings/:source", handler.SaveMapping)
+	app.Get("/ingest/mappings", handler.GetMappings)
+	app.Delete("/ingest/mappings/:source", handler.DeleteMapping)
+	app.Post("/ingest/:source/senml", handler.SenML)
+	app.Post("/ingest/:source/influx", handler.LineProtocol)
+}
+
+// SenML handles a SenML pack sent by a gateway.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary ingest a SenML pack
+// @Description store the records of a SenML (RFC 8428) pack in JSON or CBOR; records are mapped onto devices and parameters with the mapping of the gateway and grouped into one reading per device and time, records left out are reported with their position
+// @Tags ingest
+// @Accept json
+// @Accept application/senml+json
+// @Accept application/senml+cbor
+// @Produce json
+// @Param source path string true "Gateway name"
+// @Param pack body []domain.SenMLRecord true "SenML pack"
+// @Success 200 {object} domain.IngestResult
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 415 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /ingest/{source}/senml [post]
+func (h *IngestHandler) SenML(ctx *fiber.Ctx) error {
+	mediaType := strings.TrimSpace(strings.Split(ctx.Get(fiber.HeaderContentType), ";")[0])
+	contentType, ok := senMLContentTypes[mediaType]
+	if !ok {
+		return ctx.Status(fiber.StatusUnsupportedMediaType).JSON(ResponseError{Message: "a senml pack is sent as " + domain.SenMLJSON + " or " + domain.SenMLCBOR})
+	}
+	result, err := h.service.IngestSenML(ctx.UserContext(), ctx.Params("source"), ctx.Body(), contentType)
+	return h.respond(ctx, result, err)
+}
+
+// LineProtocol handles InfluxDB line protocol sent by a gateway.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary ingest InfluxDB line protocol
+// @Description store the numeric fields of InfluxDB line protocol; the device is named by a tag and each field is mapped onto a parameter with the mapping of the gateway, fields are grouped into one reading per device and time and lines left out are reported with their number
+// @Tags ingest
+// @Accept plain
+// @Produce json
+// @Param source path string true "Gateway name"
+// @Param precision query string false "Timestamp precision (ns, us, ms, s)"
+// @Param lines body string true "line protocol"
+// @Success 200 {object} domain.IngestResult
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /ingest/{source}/influx [post]
+func (h *IngestHandler) LineProtocol(ctx *fiber.Ctx) error {
+	result, err := h.service.IngestLineProtocol(ctx.UserContext(), ctx.Params("source"), ctx.Body(), ctx.Query("precision"))
+	return h.respond(ctx, result, err)
+}
+
+// SaveMapping creates or replaces the mapping of a gateway.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary save an ingest mapping
+// @Description set the devices, or sensors, and the parameters the names sent by a gateway are mapped onto
+// @Tags ingest
+// @Accept json
+// @Produce json
+// @Param source path string true "Gateway name"
+// @Param mapping body domain.IngestMapping true "device and parameter mappings"
+// @Success 200 {object} domain.IngestMapping
+// @Failure 400 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /ingest/mappings/{source} [put]
+func (h *IngestHandler) SaveMapping(ctx *fiber.Ctx) error {
+	mapping := domain.IngestMapping{}
+	if err := ctx.BodyParser(&mapping); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	mapping.Source = ctx.Params("source")
+	saved, err := h.service.SaveMapping(ctx.UserContext(), mapping)
+	if err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(saved)
+}
+
+// GetMappings retrieves the ingest mappings.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary get ingest mappings
+// @Description get the mapping of every gateway
+// @Tags ingest
+// @Accept json
+// @Produce json
+// @Success 200 {array} domain.IngestMapping
+// @Failure 500 {object} ResponseError
+// @Router /ingest/mappings [get]
+func (h *IngestHandler) GetMappings(ctx *fiber.Ctx) error {
+	mappings, err := h.service.GetMappings(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: "Failed to get all ingest mappings"})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(mappings)
+}
+
+// DeleteMapping removes the mapping of a gateway.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary delete an ingest mapping
+// @Description delete the mapping of a gateway, its payloads are no longer accepted
+// @Tags ingest
+// @Accept json
+// @Produce json
+// @Param source path string true "Gateway name"
+// @Success 204
+// @Failure 500 {object} ResponseError
+// @Router /ingest/mappings/{source} [delete]
+func (h *IngestHandler) DeleteMapping(ctx *fiber.Ctx) error {
+	if err := h.service.DeleteMapping(ctx.UserContext(), ctx.Params("source")); err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
+	}
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// respond answers with the outcome of a gateway payload, 404 when the gateway has no mapping.
+func (h *IngestHandler) respond(ctx *fiber.Ctx, result *domain.IngestResult, err error) error {
+	if err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	if result == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ResponseError{Message: "No ingest mapping for " + ctx.Params("source")})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(result)
+}
@@ -0,0 +1,115 @@
+package rest_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/rest"
+	"github.com/anggi-susanto/mrt-go/internal/rest/mocks"
+)
+
+func TestIngestHandlerSenML(t *testing.T) {
+	post := func(t *testing.T, mediaType string, result *domain.IngestResult, err error) (int, *mocks.IngestService) {
+		app := fiber.New()
+		mockService := new(mocks.IngestService)
+		rest.NewIngestHandler(app, mockService)
+		mockService.On("IngestSenML", mock.Anything, "depot-gw-1", mock.Anything, mock.Anything).Return(result, err)
+		req := httptest.NewRequest(http.MethodPost, "/ingest/depot-gw-1/senml", strings.NewReader(`[{"n": "temp", "v": 23.5}]`))
+		req.Header.Set(contentType, mediaType)
+		resp, testErr := app.Test(req)
+		assert.Nil(t, testErr)
+		defer resp.Body.Close()
+		return resp.StatusCode, mockService
+	}
+	t.Run("Success", func(t *testing.T) {
+		status, mockService := post(t, "application/senml+json; charset=utf-8", &domain.IngestResult{}, nil)
+		assert.Equal(t, fiber.StatusOK, status)
+		mockService.AssertCalled(t, "IngestSenML", mock.Anything, "depot-gw-1", mock.Anything, domain.SenMLJSON)
+	})
+	t.Run("CBOR", func(t *testing.T) {
+		status, mockService := post(t, "application/cbor", &domain.IngestResult{}, nil)
+		assert.Equal(t, fiber.StatusOK, status)
+		mockService.AssertCalled(t, "IngestSenML", mock.Anything, "depot-gw-1", mock.Anything, domain.SenMLCBOR)
+	})
+	t.Run("Unsupported media type", func(t *testing.T) {
+		status, mockService := post(t, "text/plain", nil, nil)
+		assert.Equal(t, fiber.StatusUnsupportedMediaType, status)
+		mockService.AssertNotCalled(t, "IngestSenML", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("Unknown source", func(t *testing.T) {
+		status, _ := post(t, applicationJson, nil, nil)
+		assert.Equal(t, fiber.StatusNotFound, status)
+	})
+	t.Run("Malformed pack", func(t *testing.T) {
+		status, _ := post(t, applicationJson, nil, domain.ErrInvalidIngest)
+		assert.Equal(t, fiber.StatusBadRequest, status)
+	})
+}
+
+func TestIngestHandlerLineProtocol(t *testing.T) {
+	post := func(t *testing.T, result *domain.IngestResult, err error) int {
+		app := fiber.New()
+		mockService := new(mocks.IngestService)
+		rest.NewIngestHandler(app, mockService)
+		mockService.On("IngestLineProtocol", mock.Anything, "depot-gw-1", []byte("water,device=inlet ph=6.9"), "s").Return(result, err)
+		req := httptest.NewRequest(http.MethodPost, "/ingest/depot-gw-1/influx?precision=s", strings.NewReader("water,device=inlet ph=6.9"))
+		req.Header.Set(contentType, "text/plain")
+		resp, testErr := app.Test(req)
+		assert.Nil(t, testErr)
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+	t.Run("Success", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusOK, post(t, &domain.IngestResult{Readings: []domain.IngestReadingResult{{}}}, nil))
+	})
+	t.Run("Unknown precision", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusBadRequest, post(t, nil, domain.ErrInvalidIngest))
+	})
+	t.Run("Service error", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusInternalServerError, post(t, nil, errors.New("database error")))
+	})
+}
+
+func TestIngestHandlerSaveMapping(t *testing.T) {
+	save := func(t *testing.T, err error) int {
+		app := fiber.New()
+		mockService := new(mocks.IngestService)
+		rest.NewIngestHandler(app, mockService)
+		mockService.On("SaveMapping", mock.Anything, mock.MatchedBy(func(m domain.IngestMapping) bool {
+			return m.Source == "depot-gw-1" && len(m.Devices) == 1
+		})).Return(&domain.IngestMapping{Source: "depot-gw-1"}, err)
+		body := `{"devices": [{"name": "inlet", "device_id": "663200000000000000000001"}]}`
+		req := httptest.NewRequest(http.MethodPut, "/ingest/mappings/depot-gw-1", strings.NewReader(body))
+		req.Header.Set(contentType, applicationJson)
+		resp, testErr := app.Test(req)
+		assert.Nil(t, testErr)
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+	t.Run("Success", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusOK, save(t, nil))
+	})
+	t.Run("Invalid mapping", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusBadRequest, save(t, domain.ErrInvalidIngest))
+	})
+}
+
+func TestIngestHandlerDeleteMapping(t *testing.T) {
+	app := fiber.New()
+	mockService := new(mocks.IngestService)
+	rest.NewIngestHandler(app, mockService)
+	mockService.On("DeleteMapping", mock.Anything, "depot-gw-1").Return(nil)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodDelete, "/ingest/mappings/depot-gw-1", nil))
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+}
@@ -0,0 +1,235 @@
+package rest
+
+import (
+	"context"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LabSampleService is the interface that wraps the lab sample CRUD and correlation methods.
+type LabSampleService interface {
+	Create(ctx context.Context, w *domain.LabSampleRequest) error
+	GetAll(ctx context.Context, filter domain.LabSampleFilter, page int, limit int) ([]domain.LabSample, error)
+	Delete(ctx context.Context, id string) error
+	Update(ctx context.Context, w *domain.LabSample) error
+	GetByID(ctx context.Context, id string) (*domain.LabSample, error)
+	Correlate(ctx context.Context, filter domain.LabCorrelationFilter) ([]domain.LabCorrelation, error)
+}
+
+// LabSampleHandler is the handler for LabSampleService
+type LabSampleHandler struct {
+	service LabSampleService
+}
+
+// LabSampleIDEndpoint is the endpoint for LabSampleService
+const LabSampleIDEndpoint = "/lab-sample/:id"
+
+// NewLabSampleHandler initializes a new LabSampleHandler with the provided Fiber app and LabSampleService.
+//
+// Parameters:
+// - app: The Fiber app instance.
+// - service: The LabSampleService instance.
+//
+// Return type: None.
+func NewLabSampleHandler(app *fiber.App, service LabSampleService) {
+	handler := &LabSampleHandler{service: service}
+	app.Post("/lab-sample", handler.Create)
+	app.Get("/lab-sample", handler.GetAll)
+	app.Get("/lab-sample/correlation", handler.Correlate)
+	app.Get(LabSampleIDEndpoint, handler.GetByID)
+	app.Put(LabSampleIDEndpoint, handler.Update)
+	app.Delete(LabSampleIDEndpoint, handler.Delete)
+}
+
+// Create handles the creation of lab sample data.
+//
+// @Summary create lab sample data
+// @Description create a lab sample with its results and chain-of-custody status
+// @Tags lab sample
+// @Accept json
+// @Produce json
+// @Param lab_sample body domain.LabSampleRequest true "lab sample data"
+// @Success 201 {object} domain.LabSampleRequest
+// @Failure 400 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /lab-sample [post]
+func (h *LabSampleHandler) Create(ctx *fiber.Ctx) error {
+	w := &domain.LabSampleRequest{}
+	if err := ctx.BodyParser(w); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	if err := h.service.Create(ctx.UserContext(), w); err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(w)
+}
+
+// GetAll retrieves the lab samples, latest sampled first.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary get all lab sample data
+// @Description get the lab samples, optionally of a site and sampling period
+// @Tags lab sample
+// @Accept json
+// @Produce json
+// @Param site_id query string false "Site ID"
+// @Param from query string false "Start of the sampling period (RFC 3339)"
+// @Param to query string false "End of the sampling period (RFC 3339)"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Success 200 {array} domain.LabSample "Lab sample data"
+// @Failure 400 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /lab-sample [get]
+func (h *LabSampleHandler) GetAll(ctx *fiber.Ctx) error {
+	filter := domain.LabSampleFilter{}
+	var err error
+	if filter.SiteID, filter.From, filter.To, err = parseSitePeriod(ctx); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	page := ctx.QueryInt("page", 1)
+	limit := ctx.QueryInt("limit", 10)
+	samples, err := h.service.GetAll(ctx.UserContext(), filter, page, limit)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: "Failed to get all lab sample data"})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(samples)
+}
+
+// Correlate pairs the lab samples of a site with the nearest sensor readings.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary correlate lab samples with sensor readings
+// @Description pair the lab results of a site with the nearest sensor readings and report bias and regression statistics per parameter
+// @Tags lab sample
+// @Accept json
+// @Produce json
+// @Param site_id query string true "Site ID"
+// @Param parameter query string false "Parameter code"
+// @Param from query string false "Start of the sampling period (RFC 3339)"
+// @Param to query string false "End of the sampling period (RFC 3339)"
+// @Param max_gap query string false "Largest time between a sample and its reading, e.g. 30m (default 1h)"
+// @Success 200 {array} domain.LabCorrelation
+// @Failure 400 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /lab-sample/correlation [get]
+func (h *LabSampleHandler) Correlate(ctx *fiber.Ctx) error {
+	filter := domain.LabCorrelationFilter{Parameter: ctx.Query("parameter")}
+	var err error
+	if filter.SiteID, filter.From, filter.To, err = parseSitePeriod(ctx); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	if maxGap := ctx.Query("max_gap"); maxGap != "" {
+		if filter.MaxGap, err = time.ParseDuration(maxGap); err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+		}
+	}
+	correlations, err := h.service.Correlate(ctx.UserContext(), filter)
+	if err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(correlations)
+}
+
+// GetByID retrieves a LabSample object by ID.
+//
+// ctx *fiber.Ctx - Context object containing the request information.
+// error - Returns an error if one occurs.
+// @Summary get lab sample data by id
+// @Description get lab sample data by id
+// @Tags lab sample
+// @Accept json
+// @Produce json
+// @Param id path string true "Lab sample data ID"
+// @Success 200 {object} domain.LabSample
+// @Failure 500 {object} ResponseError
+// @Router /lab-sample/{id} [get]
+// @Failure 404 {object} ResponseError
+func (h *LabSampleHandler) GetByID(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	w, err := h.service.GetByID(ctx.UserContext(), id)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
+	}
+
+	if w == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ResponseError{Message: "Data not found"})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(w)
+}
+
+// Update updates the LabSampleHandler.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary update lab sample data
+// @Description update lab sample data
+// @Tags lab sample
+// @Accept json
+// @Produce json
+// @Param id path string true "Lab sample data ID"
+// @Param lab_sample body domain.LabSample true "lab sample data"
+// @Success 200 {object} domain.LabSample
+// @Failure 400 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /lab-sample/{id} [put]
+// @Failure 404 {object} ResponseError
+func (h *LabSampleHandler) Update(ctx *fiber.Ctx) error {
+	w := &domain.LabSample{}
+	if err := ctx.BodyParser(w); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	// The sample is identified by the path when the body carries no ID
+	if w.ID.IsZero() {
+		id, err := primitive.ObjectIDFromHex(ctx.Params("id"))
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+		}
+		w.ID = id
+	}
+	if err := h.service.Update(ctx.UserContext(), w); err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(w)
+}
+
+// Delete deletes a LabSampleHandler item.
+//
+// ctx *fiber.Ctx parameter. Returns an error.
+// @Summary delete lab sample data
+// @Description delete lab sample data
+// @Tags lab sample
+// @Accept json
+// @Produce json
+// @Param id path string true "Lab sample data ID"
+// @Success 204
+// @Failure 500 {object} ResponseError
+// @Router /lab-sample/{id} [delete]
+// @Failure 404 {object} ResponseError
+func (h *LabSampleHandler) Delete(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if err := h.service.Delete(ctx.UserContext(), id); err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
+	}
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// parseSitePeriod reads the site_id, from and to query parameters.
+func parseSitePeriod(ctx *fiber.Ctx) (siteID primitive.ObjectID, from, to time.Time, err error) {
+	if id := ctx.Query("site_id"); id != "" {
+		if siteID, err = primitive.ObjectIDFromHex(id); err != nil {
+			return
+		}
+	}
+	if value := ctx.Query("from"); value != "" {
+		if from, err = time.Parse(time.RFC3339, value); err != nil {
+			return
+		}
+	}
+	if value := ctx.Query("to"); value != "" {
+		to, err = time.Parse(time.RFC3339, value)
+	}
+	return
+}
@@ -0,0 +1,187 @@
+package rest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/rest"
+	"github.com/anggi-susanto/mrt-go/internal/rest/mocks"
+)
+
+const labSampleEndpoint = "/lab-sample"
+
+func TestCreateLabSampleHandler(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.LabSampleService)
+		rest.NewLabSampleHandler(app, mockService)
+		mockService.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+		sample := domain.LabSampleRequest{
+			SampleID:  "LAB-1",
+			SiteID:    primitive.NewObjectID(),
+			SampledAt: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC),
+			Results:   []domain.LabResult{{Parameter: domain.ParamBOD, Value: 12, Unit: "mg/L"}},
+		}
+		body, _ := json.Marshal(sample)
+		req := httptest.NewRequest(http.MethodPost, labSampleEndpoint, bytes.NewReader(body))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		respData := domain.LabSampleRequest{}
+		_ = json.Unmarshal(data, &respData)
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		assert.Equal(t, sample.SampleID, respData.SampleID)
+		assert.Equal(t, sample.Results, respData.Results)
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.LabSampleService)
+		rest.NewLabSampleHandler(app, mockService)
+		mockService.On("Create", mock.Anything, mock.Anything).Return(fmt.Errorf("%w: sample_id is required", domain.ErrInvalidLabSample))
+
+		req := httptest.NewRequest(http.MethodPost, labSampleEndpoint, bytes.NewReader([]byte(`{"lab":"Lab"}`)))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestLabSampleHandlerGetAll(t *testing.T) {
+	siteID := primitive.NewObjectID()
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	t.Run("Success with filter", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.LabSampleService)
+		rest.NewLabSampleHandler(app, mockService)
+		filter := domain.LabSampleFilter{SiteID: siteID, From: from}
+		mockService.On("GetAll", mock.Anything, filter, 1, 10).Return([]domain.LabSample{{SampleID: "LAB-1"}}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, labSampleEndpoint+"?site_id="+siteID.Hex()+"&from=2024-03-01T00:00:00Z", nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+	t.Run("Invalid site", func(t *testing.T) {
+		app := fiber.New()
+		rest.NewLabSampleHandler(app, new(mocks.LabSampleService))
+		req := httptest.NewRequest(http.MethodGet, labSampleEndpoint+"?site_id=1", nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+	t.Run("Error", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.LabSampleService)
+		rest.NewLabSampleHandler(app, mockService)
+		mockService.On("GetAll", mock.Anything, domain.LabSampleFilter{}, 1, 10).Return(nil, errors.New("error"))
+		req := httptest.NewRequest(http.MethodGet, labSampleEndpoint, nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+	})
+}
+
+func TestLabSampleHandlerGetByID(t *testing.T) {
+	sample := domain.LabSample{ID: primitive.NewObjectID(), SampleID: "LAB-1"}
+	t.Run("Found", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.LabSampleService)
+		rest.NewLabSampleHandler(app, mockService)
+		mockService.On("GetByID", mock.Anything, sample.ID.Hex()).Return(&sample, nil)
+		req := httptest.NewRequest(http.MethodGet, labSampleEndpoint+"/"+sample.ID.Hex(), nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+	t.Run("Not found", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.LabSampleService)
+		rest.NewLabSampleHandler(app, mockService)
+		mockService.On("GetByID", mock.Anything, sample.ID.Hex()).Return(nil, nil)
+		req := httptest.NewRequest(http.MethodGet, labSampleEndpoint+"/"+sample.ID.Hex(), nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestLabSampleHandlerUpdate(t *testing.T) {
+	id := primitive.NewObjectID()
+	app := fiber.New()
+	mockService := new(mocks.LabSampleService)
+	rest.NewLabSampleHandler(app, mockService)
+	mockService.On("Update", mock.Anything, mock.MatchedBy(func(s *domain.LabSample) bool {
+		return s.ID == id && s.CustodyStatus == domain.CustodyReported
+	})).Return(nil)
+	req := httptest.NewRequest(http.MethodPut, labSampleEndpoint+"/"+id.Hex(), bytes.NewReader([]byte(`{"sample_id":"LAB-1","custody_status":"reported"}`)))
+	req.Header.Set(contentType, applicationJson)
+	resp, err := app.Test(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestLabSampleHandlerCorrelate(t *testing.T) {
+	siteID := primitive.NewObjectID()
+	t.Run("Success", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.LabSampleService)
+		rest.NewLabSampleHandler(app, mockService)
+		filter := domain.LabCorrelationFilter{SiteID: siteID, Parameter: domain.ParamBOD, MaxGap: 30 * time.Minute}
+		mockService.On("Correlate", mock.Anything, filter).Return([]domain.LabCorrelation{{Parameter: domain.ParamBOD, Count: 4, Bias: 2}}, nil)
+		req := httptest.NewRequest(http.MethodGet, labSampleEndpoint+"/correlation?site_id="+siteID.Hex()+"&parameter=BOD&max_gap=30m", nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var correlations []domain.LabCorrelation
+		data, _ := io.ReadAll(resp.Body)
+		assert.Nil(t, json.Unmarshal(data, &correlations))
+		assert.Equal(t, 2.0, correlations[0].Bias)
+	})
+	t.Run("Invalid max gap", func(t *testing.T) {
+		app := fiber.New()
+		rest.NewLabSampleHandler(app, new(mocks.LabSampleService))
+		req := httptest.NewRequest(http.MethodGet, labSampleEndpoint+"/correlation?site_id="+siteID.Hex()+"&max_gap=soon", nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+	t.Run("No site", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.LabSampleService)
+		rest.NewLabSampleHandler(app, mockService)
+		mockService.On("Correlate", mock.Anything, domain.LabCorrelationFilter{}).Return(nil, fmt.Errorf("%w: site_id is required", domain.ErrInvalidLabSample))
+		req := httptest.NewRequest(http.MethodGet, labSampleEndpoint+"/correlation", nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+}
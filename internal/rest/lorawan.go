@@ -0,0 +1,185 @@
+package rest
+
+import (
+	"context"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+// LoRaWANService is the interface that wraps the LoRaWAN ingestion methods.
+type LoRaWANService interface {
+	Ingest(ctx context.Context, uplink domain.Uplink) (*domain.UplinkRecord, error)
+	SaveCodec(ctx context.Context, codec domain.PayloadCodec) (*domain.PayloadCodec, error)
+	GetCodecs(ctx context.Context) ([]domain.PayloadCodec, error)
+	GetUplinks(ctx context.Context, deviceID string, page, limit int) ([]domain.UplinkRecord, error)
+}
+
+// LoRaWANHandler is the handler for LoRaWANService
+type LoRaWANHandler struct {
+	service LoRaWANService
+}
+
+// NewLoRaWANHandler initializes a new LoRaWANHandler with the provided Fiber app and LoRaWANService.
+//
+// Parameters:
+// - app: The Fiber app instance.
+// - service: The LoRaWANService instance.
+//
+// Return type: None.
+func NewLoRaWANHandler(app *fiber.App, service LoRaWANService) {
+	handler := &LoRaWANHandler{service: service}
+	app.Post("/lorawan/chirpstack", handler.ChirpStack)
+	app.Post("/lorawan/tts", handler.TTS)
+	app.Put("/lorawan/codecs/:model", handler.SaveCodec)
+	app.Get("/lorawan/codecs", handler.GetCodecs)
+	app.Get("/device/:id/uplinks", handler.GetUplinks)
+}
+
+// ChirpStack handles the webhook of a ChirpStack HTTP integration.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary receive a ChirpStack uplink
+// @Description webhook of a ChirpStack v4 HTTP integration with JSON marshaling; up events are decoded with the codec of the device model and stored with their radio metadata, other events are acknowledged and ignored
+// @Tags lorawan
+// @Accept json
+// @Produce json
+// @Param event query string false "Event type, up for uplinks"
+// @Param uplink body domain.ChirpStackUplink true "up event"
+// @Success 201 {object} domain.UplinkRecord "uplink stored with its reading"
+// @Success 202 {object} domain.UplinkRecord "uplink stored without reading, see its error"
+// @Success 204
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /lorawan/chirpstack [post]
+func (h *LoRaWANHandler) ChirpStack(ctx *fiber.Ctx) error {
+	if event := ctx.Query("event"); event != "" && event != "up" {
+		return ctx.SendStatus(fiber.StatusNoContent)
+	}
+	event := domain.ChirpStackUplink{}
+	if err := ctx.BodyParser(&event); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	uplink, err := event.Uplink()
+	if err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	return h.ingest(ctx, uplink)
+}
+
+// TTS handles the webhook of The Things Stack.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary receive a The Things Stack uplink
+// @Description webhook of The Things Stack with the JSON format; uplink messages are decoded with the codec of the device model and stored with their radio metadata, other messages are acknowledged and ignored
+// @Tags lorawan
+// @Accept json
+// @Produce json
+// @Param uplink body domain.TTSUplink true "uplink message"
+// @Success 201 {object} domain.UplinkRecord "uplink stored with its reading"
+// @Success 202 {object} domain.UplinkRecord "uplink stored without reading, see its error"
+// @Success 204
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /lorawan/tts [post]
+func (h *LoRaWANHandler) TTS(ctx *fiber.Ctx) error {
+	message := domain.TTSUplink{}
+	if err := ctx.BodyParser(&message); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	uplink, err := message.Uplink()
+	if err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	if uplink == nil {
+		return ctx.SendStatus(fiber.StatusNoContent)
+	}
+	return h.ingest(ctx, uplink)
+}
+
+// SaveCodec creates or replaces the payload codec of a model.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary save a payload codec
+// @Description set how the uplink payloads of the devices of a model are decoded, with Cayenne LPP channels or a byte layout
+// @Tags lorawan
+// @Accept json
+// @Produce json
+// @Param model path string true "Device model"
+// @Param codec body domain.PayloadCodec true "codec type with its channels or fields"
+// @Success 200 {object} domain.PayloadCodec
+// @Failure 400 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /lorawan/codecs/{model} [put]
+func (h *LoRaWANHandler) SaveCodec(ctx *fiber.Ctx) error {
+	codec := domain.PayloadCodec{}
+	if err := ctx.BodyParser(&codec); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	codec.Model = ctx.Params("model")
+	saved, err := h.service.SaveCodec(ctx.UserContext(), codec)
+	if err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(saved)
+}
+
+// GetCodecs retrieves the payload codecs.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary get payload codecs
+// @Description get the payload codecs of every device model
+// @Tags lorawan
+// @Accept json
+// @Produce json
+// @Success 200 {array} domain.PayloadCodec
+// @Failure 500 {object} ResponseError
+// @Router /lorawan/codecs [get]
+func (h *LoRaWANHandler) GetCodecs(ctx *fiber.Ctx) error {
+	codecs, err := h.service.GetCodecs(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: "Failed to get all payload codecs"})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(codecs)
+}
+
+// GetUplinks retrieves the uplinks of a device.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary get device uplinks
+// @Description get the LoRaWAN uplinks of a device with their radio metadata and decoded values, newest first
+// @Tags lorawan
+// @Accept json
+// @Produce json
+// @Param id path string true "Device ID"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Success 200 {array} domain.UplinkRecord
+// @Failure 500 {object} ResponseError
+// @Router /device/{id}/uplinks [get]
+func (h *LoRaWANHandler) GetUplinks(ctx *fiber.Ctx) error {
+	page := ctx.QueryInt("page", 1)
+	limit := ctx.QueryInt("limit", 10)
+	uplinks, err := h.service.GetUplinks(ctx.UserContext(), ctx.Params("id"), page, limit)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: "Failed to get device uplinks"})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(uplinks)
+}
+
+// ingest stores an uplink, 201 when it gave a reading and 202 when it did not.
+func (h *LoRaWANHandler) ingest(ctx *fiber.Ctx, uplink *domain.Uplink) error {
+	record, err := h.service.Ingest(ctx.UserContext(), *uplink)
+	if err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	if record == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ResponseError{Message: "No device with DevEUI " + uplink.DevEUI})
+	}
+	if record.ReadingID.IsZero() {
+		return ctx.Status(fiber.StatusAccepted).JSON(record)
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(record)
+}
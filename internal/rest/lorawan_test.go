@@ -0,0 +1,141 @@
+package rest_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/rest"
+	"github.com/anggi-susanto/mrt-go/internal/rest/mocks"
+)
+
+const chirpStackUp = `{
+	"deduplicationId": "3ac7e3c4-4401-4b8d-9386-a5c902f9202d",
+	"time": "2024-05-01T08:00:00Z",
+	"deviceInfo": {"devEui": "70b3d57ed0001234"},
+	"fCnt": 42,
+	"fPort": 2,
+	"data": "AsgAVQ==",
+	"rxInfo": [{"gatewayId": "0016c001f153a14c", "rssi": -57, "snr": 9.5}, {"gatewayId": "0016c001f153a14d", "rssi": -80, "snr": 2}],
+	"txInfo": {"frequency": 868100000, "modulation": {"lora": {"spreadingFactor": 7}}}
+}`
+
+func TestLoRaWANHandlerChirpStack(t *testing.T) {
+	post := func(t *testing.T, url string, record *domain.UplinkRecord, err error) (int, *mocks.LoRaWANService) {
+		app := fiber.New()
+		mockService := new(mocks.LoRaWANService)
+		rest.NewLoRaWANHandler(app, mockService)
+		mockService.On("Ingest", mock.Anything, mock.MatchedBy(func(u domain.Uplink) bool {
+			return u.DevEUI == "70B3D57ED0001234" && u.FCnt == 42 && len(u.Payload) == 4 && u.Radio.RSSI == -57 && u.Radio.Gateways == 2
+		})).Return(record, err)
+		req := httptest.NewRequest(http.MethodPost, url, strings.NewReader(chirpStackUp))
+		req.Header.Set(contentType, applicationJson)
+		resp, testErr := app.Test(req)
+		assert.Nil(t, testErr)
+		defer resp.Body.Close()
+		return resp.StatusCode, mockService
+	}
+	t.Run("Success", func(t *testing.T) {
+		status, _ := post(t, "/lorawan/chirpstack?event=up", &domain.UplinkRecord{ReadingID: primitive.NewObjectID()}, nil)
+		assert.Equal(t, fiber.StatusCreated, status)
+	})
+	t.Run("Stored without reading", func(t *testing.T) {
+		status, _ := post(t, "/lorawan/chirpstack", &domain.UplinkRecord{Error: "no codec"}, nil)
+		assert.Equal(t, fiber.StatusAccepted, status)
+	})
+	t.Run("Unknown device", func(t *testing.T) {
+		status, _ := post(t, "/lorawan/chirpstack", nil, nil)
+		assert.Equal(t, fiber.StatusNotFound, status)
+	})
+	t.Run("Other event", func(t *testing.T) {
+		status, mockService := post(t, "/lorawan/chirpstack?event=join", nil, nil)
+		assert.Equal(t, fiber.StatusNoContent, status)
+		mockService.AssertNotCalled(t, "Ingest", mock.Anything, mock.Anything)
+	})
+	t.Run("Service error", func(t *testing.T) {
+		status, _ := post(t, "/lorawan/chirpstack", nil, errors.New("database error"))
+		assert.Equal(t, fiber.StatusInternalServerError, status)
+	})
+}
+
+func TestLoRaWANHandlerTTS(t *testing.T) {
+	post := func(t *testing.T, body string) (int, *mocks.LoRaWANService) {
+		app := fiber.New()
+		mockService := new(mocks.LoRaWANService)
+		rest.NewLoRaWANHandler(app, mockService)
+		mockService.On("Ingest", mock.Anything, mock.MatchedBy(func(u domain.Uplink) bool {
+			return u.DevEUI == "70B3D57ED0001234" && u.FPort == 2 && u.Radio.Frequency == 868100000
+		})).Return(&domain.UplinkRecord{ReadingID: primitive.NewObjectID()}, nil)
+		req := httptest.NewRequest(http.MethodPost, "/lorawan/tts", strings.NewReader(body))
+		req.Header.Set(contentType, applicationJson)
+		resp, testErr := app.Test(req)
+		assert.Nil(t, testErr)
+		defer resp.Body.Close()
+		return resp.StatusCode, mockService
+	}
+	t.Run("Success", func(t *testing.T) {
+		status, _ := post(t, `{
+			"end_device_ids": {"device_id": "outfall-1", "dev_eui": "70B3D57ED0001234"},
+			"received_at": "2024-05-01T08:00:00Z",
+			"uplink_message": {
+				"f_port": 2,
+				"f_cnt": 42,
+				"frm_payload": "AsgAVQ==",
+				"rx_metadata": [{"gateway_ids": {"gateway_id": "gw-1", "eui": "0016C001F153A14C"}, "rssi": -57, "snr": 9.5}],
+				"settings": {"data_rate": {"lora": {"spreading_factor": 7}}, "frequency": "868100000"},
+				"received_at": "2024-05-01T08:00:00Z"
+			}
+		}`)
+		assert.Equal(t, fiber.StatusCreated, status)
+	})
+	t.Run("Not an uplink", func(t *testing.T) {
+		status, mockService := post(t, `{"end_device_ids": {"dev_eui": "70B3D57ED0001234"}, "join_accept": {}}`)
+		assert.Equal(t, fiber.StatusNoContent, status)
+		mockService.AssertNotCalled(t, "Ingest", mock.Anything, mock.Anything)
+	})
+}
+
+func TestLoRaWANHandlerSaveCodec(t *testing.T) {
+	save := func(t *testing.T, err error) int {
+		app := fiber.New()
+		mockService := new(mocks.LoRaWANService)
+		rest.NewLoRaWANHandler(app, mockService)
+		mockService.On("SaveCodec", mock.Anything, mock.MatchedBy(func(c domain.PayloadCodec) bool {
+			return c.Model == "OUT-LR1" && c.Type == domain.CodecCayenneLPP
+		})).Return(&domain.PayloadCodec{Model: "OUT-LR1"}, err)
+		req := httptest.NewRequest(http.MethodPut, "/lorawan/codecs/OUT-LR1", strings.NewReader(`{"type": "cayenne_lpp", "channels": [{"channel": 1, "parameter": "pH"}]}`))
+		req.Header.Set(contentType, applicationJson)
+		resp, testErr := app.Test(req)
+		assert.Nil(t, testErr)
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+	t.Run("Success", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusOK, save(t, nil))
+	})
+	t.Run("Invalid codec", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusBadRequest, save(t, domain.ErrInvalidLoRaWAN))
+	})
+}
+
+func TestLoRaWANHandlerGetUplinks(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	app := fiber.New()
+	mockService := new(mocks.LoRaWANService)
+	rest.NewLoRaWANHandler(app, mockService)
+	mockService.On("GetUplinks", mock.Anything, deviceID.Hex(), 2, 5).Return([]domain.UplinkRecord{{DeviceID: deviceID}}, nil)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/device/"+deviceID.Hex()+"/uplinks?page=2&limit=5", nil))
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,226 @@
+package rest
+
+import (
+	"context"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaintenanceService is the interface that wraps the work order CRUD and calendar methods.
+type MaintenanceService interface {
+	Create(ctx context.Context, w *domain.WorkOrder) error
+	GetAll(ctx context.Context, filter domain.MaintenanceFilter, page int, limit int) ([]domain.WorkOrder, error)
+	Delete(ctx context.Context, id string) error
+	Update(ctx context.Context, id string, w *domain.WorkOrder) (*domain.WorkOrder, error)
+	GetByID(ctx context.Context, id string) (*domain.WorkOrder, error)
+	Calendar(ctx context.Context, filter domain.MaintenanceFilter) ([]domain.CalendarEntry, error)
+}
+
+// MaintenanceHandler is the handler for MaintenanceService
+type MaintenanceHandler struct {
+	service MaintenanceService
+}
+
+// WorkOrderIDEndpoint is the endpoint for a single work order
+const WorkOrderIDEndpoint = "/work-order/:id"
+
+// NewMaintenanceHandler initializes a new MaintenanceHandler with the provided Fiber app and MaintenanceService.
+//
+// Parameters:
+// - app: The Fiber app instance.
+// - service: The MaintenanceService instance.
+//
+// Return type: None.
+func NewMaintenanceHandler(app *fiber.App, service MaintenanceService) {
+	handler := &MaintenanceHandler{service: service}
+	app.Post("/work-order", handler.Create)
+	app.Get("/work-order", handler.GetAll)
+	app.Get(WorkOrderIDEndpoint, handler.GetByID)
+	app.Put(WorkOrderIDEndpoint, handler.Update)
+	app.Delete(WorkOrderIDEndpoint, handler.Delete)
+	app.Get("/maintenance/calendar", handler.Calendar)
+}
+
+// Create handles the creation of a work order.
+//
+// @Summary create work order
+// @Description record a maintenance work order, the readings of the device taken during its downtime are marked as maintenance affected
+// @Tags maintenance
+// @Accept json
+// @Produce json
+// @Param work_order body domain.WorkOrder true "work order"
+// @Success 201 {object} domain.WorkOrder
+// @Failure 400 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /work-order [post]
+func (h *MaintenanceHandler) Create(ctx *fiber.Ctx) error {
+	w := &domain.WorkOrder{}
+	if err := ctx.BodyParser(w); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	if err := h.service.Create(ctx.UserContext(), w); err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(w)
+}
+
+// GetAll retrieves the work orders, earliest scheduled first.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary get all work orders
+// @Description get the work orders, optionally of a device, a status and a period their schedule or downtime overlaps
+// @Tags maintenance
+// @Accept json
+// @Produce json
+// @Param device_id query string false "Device ID"
+// @Param status query string false "Status (planned, in_progress, done)"
+// @Param from query string false "Start of the period (RFC 3339)"
+// @Param to query string false "End of the period (RFC 3339)"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Success 200 {array} domain.WorkOrder
+// @Failure 400 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /work-order [get]
+func (h *MaintenanceHandler) GetAll(ctx *fiber.Ctx) error {
+	filter, err := parseMaintenanceFilter(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	page := ctx.QueryInt("page", 1)
+	limit := ctx.QueryInt("limit", 10)
+	workOrders, err := h.service.GetAll(ctx.UserContext(), filter, page, limit)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: "Failed to get all work orders"})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(workOrders)
+}
+
+// GetByID retrieves a WorkOrder object by ID.
+//
+// ctx *fiber.Ctx - Context object containing the request information.
+// error - Returns an error if one occurs.
+// @Summary get work order by id
+// @Description get work order by id
+// @Tags maintenance
+// @Accept json
+// @Produce json
+// @Param id path string true "Work order ID"
+// @Success 200 {object} domain.WorkOrder
+// @Failure 404 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /work-order/{id} [get]
+func (h *MaintenanceHandler) GetByID(ctx *fiber.Ctx) error {
+	w, err := h.service.GetByID(ctx.UserContext(), ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
+	}
+	if w == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ResponseError{Message: "Data not found"})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(w)
+}
+
+// Update updates a work order.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary update work order
+// @Description update a work order, the maintenance marks of its downtime are recomputed. The status moves one step at a time from planned to in_progress to done, the creation, start and completion times are kept
+// @Tags maintenance
+// @Accept json
+// @Produce json
+// @Param id path string true "Work order ID"
+// @Param work_order body domain.WorkOrder true "work order"
+// @Success 200 {object} domain.WorkOrder
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 409 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /work-order/{id} [put]
+func (h *MaintenanceHandler) Update(ctx *fiber.Ctx) error {
+	body := &domain.WorkOrder{}
+	if err := ctx.BodyParser(body); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	w, err := h.service.Update(ctx.UserContext(), ctx.Params("id"), body)
+	if err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	if w == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ResponseError{Message: "Data not found"})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(w)
+}
+
+// Delete deletes a work order.
+//
+// ctx *fiber.Ctx parameter. Returns an error.
+// @Summary delete work order
+// @Description delete a work order and remove the maintenance marks of its downtime
+// @Tags maintenance
+// @Accept json
+// @Produce json
+// @Param id path string true "Work order ID"
+// @Success 204
+// @Failure 400 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /work-order/{id} [delete]
+func (h *MaintenanceHandler) Delete(ctx *fiber.Ctx) error {
+	if err := h.service.Delete(ctx.UserContext(), ctx.Params("id")); err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// Calendar lists the scheduled work and downtime windows of the devices.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary get maintenance calendar
+// @Description list the scheduled work and the downtime windows of the work orders overlapping a period, ordered by start
+// @Tags maintenance
+// @Accept json
+// @Produce json
+// @Param device_id query string false "Device ID"
+// @Param status query string false "Status (planned, in_progress, done)"
+// @Param from query string false "Start of the period (RFC 3339)"
+// @Param to query string false "End of the period (RFC 3339)"
+// @Success 200 {array} domain.CalendarEntry
+// @Failure 400 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /maintenance/calendar [get]
+func (h *MaintenanceHandler) Calendar(ctx *fiber.Ctx) error {
+	filter, err := parseMaintenanceFilter(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	entries, err := h.service.Calendar(ctx.UserContext(), filter)
+	if err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entries)
+}
+
+// parseMaintenanceFilter reads the device_id, status, from and to query parameters.
+func parseMaintenanceFilter(ctx *fiber.Ctx) (domain.MaintenanceFilter, error) {
+	filter := domain.MaintenanceFilter{Status: ctx.Query("status")}
+	var err error
+	if id := ctx.Query("device_id"); id != "" {
+		if filter.DeviceID, err = primitive.ObjectIDFromHex(id); err != nil {
+			return filter, err
+		}
+	}
+	if value := ctx.Query("from"); value != "" {
+		if filter.From, err = time.Parse(time.RFC3339, value); err != nil {
+			return filter, err
+		}
+	}
+	if value := ctx.Query("to"); value != "" {
+		if filter.To, err = time.Parse(time.RFC3339, value); err != nil {
+			return filter, err
+		}
+	}
+	return filter, nil
+}
@@ -0,0 +1,170 @@
+package rest_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/rest"
+	"github.com/anggi-susanto/mrt-go/internal/rest/mocks"
+)
+
+func TestMaintenanceHandlerCreate(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	body := fmt.Sprintf(`{"device_id":%q,"type":"cleaning","schedule":{"start":"2024-03-01T08:00:00Z"},"downtime":{"start":"2024-03-01T08:00:00Z","end":"2024-03-01T09:00:00Z"}}`, deviceID.Hex())
+	t.Run("Success", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.MaintenanceService)
+		rest.NewMaintenanceHandler(app, mockService)
+		mockService.On("Create", mock.Anything, mock.MatchedBy(func(w *domain.WorkOrder) bool {
+			return w.DeviceID == deviceID && w.Downtime != nil && w.Downtime.End.Sub(w.Downtime.Start) == time.Hour
+		})).Return(nil)
+		req := httptest.NewRequest(http.MethodPost, "/work-order", bytes.NewReader([]byte(body)))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.MaintenanceService)
+		rest.NewMaintenanceHandler(app, mockService)
+		mockService.On("Create", mock.Anything, mock.Anything).Return(fmt.Errorf("%w: unknown type", domain.ErrInvalidWorkOrder))
+		req := httptest.NewRequest(http.MethodPost, "/work-order", bytes.NewReader([]byte(body)))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestMaintenanceHandlerGetByID(t *testing.T) {
+	id := primitive.NewObjectID()
+	t.Run("Success", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.MaintenanceService)
+		rest.NewMaintenanceHandler(app, mockService)
+		mockService.On("GetByID", mock.Anything, id.Hex()).Return(&domain.WorkOrder{ID: id}, nil)
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/work-order/"+id.Hex(), nil))
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+	t.Run("Not found", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.MaintenanceService)
+		rest.NewMaintenanceHandler(app, mockService)
+		mockService.On("GetByID", mock.Anything, id.Hex()).Return(nil, nil)
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/work-order/"+id.Hex(), nil))
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestMaintenanceHandlerUpdate(t *testing.T) {
+	id := primitive.NewObjectID()
+	t.Run("Success", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.MaintenanceService)
+		rest.NewMaintenanceHandler(app, mockService)
+		mockService.On("Update", mock.Anything, id.Hex(), mock.MatchedBy(func(w *domain.WorkOrder) bool {
+			return w.Status == domain.WorkOrderDone
+		})).Return(&domain.WorkOrder{ID: id, Status: domain.WorkOrderDone}, nil)
+		body := fmt.Sprintf(`{"id":%q,"status":"done"}`, primitive.NewObjectID().Hex())
+		req := httptest.NewRequest(http.MethodPut, "/work-order/"+id.Hex(), bytes.NewReader([]byte(body)))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+	t.Run("Not found", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.MaintenanceService)
+		rest.NewMaintenanceHandler(app, mockService)
+		mockService.On("Update", mock.Anything, id.Hex(), mock.Anything).Return(nil, nil)
+		req := httptest.NewRequest(http.MethodPut, "/work-order/"+id.Hex(), bytes.NewReader([]byte(`{"status":"done"}`)))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+	t.Run("Transition", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.MaintenanceService)
+		rest.NewMaintenanceHandler(app, mockService)
+		mockService.On("Update", mock.Anything, id.Hex(), mock.Anything).Return(nil, domain.ErrWorkOrderTransition)
+		req := httptest.NewRequest(http.MethodPut, "/work-order/"+id.Hex(), bytes.NewReader([]byte(`{"status":"planned"}`)))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+	})
+}
+
+func TestMaintenanceHandlerDelete(t *testing.T) {
+	id := primitive.NewObjectID()
+	t.Run("Success", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.MaintenanceService)
+		rest.NewMaintenanceHandler(app, mockService)
+		mockService.On("Delete", mock.Anything, id.Hex()).Return(nil)
+		resp, err := app.Test(httptest.NewRequest(http.MethodDelete, "/work-order/"+id.Hex(), nil))
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	})
+}
+
+func TestMaintenanceHandlerCalendar(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	t.Run("Success", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.MaintenanceService)
+		rest.NewMaintenanceHandler(app, mockService)
+		from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+		filter := domain.MaintenanceFilter{DeviceID: deviceID, Status: domain.WorkOrderPlanned, From: from, To: from.Add(7 * 24 * time.Hour)}
+		mockService.On("Calendar", mock.Anything, filter).Return([]domain.CalendarEntry{{DeviceID: deviceID}}, nil)
+		url := "/maintenance/calendar?device_id=" + deviceID.Hex() + "&status=planned&from=2024-03-01T00:00:00Z&to=2024-03-08T00:00:00Z"
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, url, nil))
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+	t.Run("Invalid period", func(t *testing.T) {
+		app := fiber.New()
+		rest.NewMaintenanceHandler(app, new(mocks.MaintenanceService))
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/maintenance/calendar?from=yesterday", nil))
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+	t.Run("Error", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.MaintenanceService)
+		rest.NewMaintenanceHandler(app, mockService)
+		mockService.On("Calendar", mock.Anything, mock.Anything).Return(nil, errors.New("error"))
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/maintenance/calendar", nil))
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+	})
+}
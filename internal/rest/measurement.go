@@ -0,0 +1,153 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MeasurementService is the interface that wraps the Ingest and GetAll methods.
+type MeasurementService interface {
+	Ingest(ctx context.Context, measurements []domain.Measurement) ([]domain.Measurement, error)
+	GetAll(ctx context.Context, filter domain.MeasurementFilter, page, limit int) ([]domain.Measurement, error)
+}
+
+// MeasurementHandler is the handler for MeasurementService
+type MeasurementHandler struct {
+	service      MeasurementService
+	unitRegistry UnitRegistry
+}
+
+// NewMeasurementHandler initializes a new MeasurementHandler with the provided Fiber app and MeasurementService.
+//
+// Parameters:
+// - app: The Fiber app instance.
+// - service: The MeasurementService instance.
+// - unitRegistry: The UnitRegistry converting values to the unit system requested with units=.
+// - deviceAuth: The middleware authenticating the devices sending measurements.
+//
+// Return type: None.
+func NewMeasurementHandler(app *fiber.App, service MeasurementService, unitRegistry UnitRegistry, deviceAuth fiber.Handler) {
+	handler := &MeasurementHandler{service: service, unitRegistry: unitRegistry}
+	app.Post("/measurement", deviceAuth, handler.Create)
+	app.Get("/measurement", handler.GetAll)
+}
+
+// Create handles the ingestion of a batch of measurements.
+//
+// Measurements of the same device and timestamp are grouped into one reading,
+// values are stored in the canonical unit of their parameter.
+// @Summary create measurements
+// @Description create a batch of measurements of the device authenticated with its credentials, any parameter code is accepted
+// @Tags measurement
+// @Accept json
+// @Produce json
+// @Security DeviceAuth
+// @Param measurements body []domain.Measurement true "measurements"
+// @Success 201 {array} domain.Measurement
+// @Failure 400 {object} ResponseError
+// @Failure 401 {object} ResponseError
+// @Failure 403 {object} ResponseError
+// @Failure 422 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /measurement [post]
+func (h *MeasurementHandler) Create(ctx *fiber.Ctx) error {
+	var measurements []domain.Measurement
+	if err := ctx.BodyParser(&measurements); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	for i := range measurements {
+		if err := bindDevice(ctx, &measurements[i].DeviceID); err != nil {
+			return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+		}
+	}
+	stored, err := h.service.Ingest(ctx.UserContext(), measurements)
+	if err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(stored)
+}
+
+// GetAll retrieves the measurements matching the query, newest first.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary get measurements
+// @Description get measurements filtered by device, sensor, parameter, quality and time range
+// @Tags measurement
+// @Accept json
+// @Produce json
+// @Param device_id query string false "Device ID"
+// @Param sensor_id query string false "Sensor ID"
+// @Param parameter query string false "Parameter code"
+// @Param quality query string false "Comma separated quality flags, e.g. validated,approved"
+// @Param from query string false "Start of the time range (RFC 3339)"
+// @Param to query string false "End of the time range (RFC 3339)"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Param units query string false "Unit system of the returned values (metric, imperial)"
+// @Success 200 {array} domain.Measurement "Measurements"
+// @Failure 400 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /measurement [get]
+func (h *MeasurementHandler) GetAll(ctx *fiber.Ctx) error {
+	filter, err := parseMeasurementFilter(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	system, err := unitSystem(ctx, h.unitRegistry)
+	if err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	page := ctx.QueryInt("page", 1)
+	limit := ctx.QueryInt("limit", 10)
+	measurements, err := h.service.GetAll(ctx.UserContext(), filter, page, limit)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: "Failed to get measurements"})
+	}
+	if system != nil {
+		if err = h.unitRegistry.ConvertMeasurements(measurements, *system); err != nil {
+			return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
+		}
+	}
+	return ctx.Status(fiber.StatusOK).JSON(measurements)
+}
+
+// parseMeasurementFilter reads the measurement filter from the query string.
+func parseMeasurementFilter(ctx *fiber.Ctx) (domain.MeasurementFilter, error) {
+	filter := domain.MeasurementFilter{Parameter: ctx.Query("parameter")}
+	var err error
+	if id := ctx.Query("device_id"); id != "" {
+		if filter.DeviceID, err = primitive.ObjectIDFromHex(id); err != nil {
+			return filter, err
+		}
+	}
+	if id := ctx.Query("sensor_id"); id != "" {
+		if filter.SensorID, err = primitive.ObjectIDFromHex(id); err != nil {
+			return filter, err
+		}
+	}
+	if quality := ctx.Query("quality"); quality != "" {
+		for _, flag := range strings.Split(quality, ",") {
+			if !domain.IsQuality(flag) {
+				return filter, fmt.Errorf("%w: unknown quality %q", domain.ErrInvalidQuality, flag)
+			}
+			filter.Quality = append(filter.Quality, flag)
+		}
+	}
+	if from := ctx.Query("from"); from != "" {
+		if filter.From, err = time.Parse(time.RFC3339, from); err != nil {
+			return filter, err
+		}
+	}
+	if to := ctx.Query("to"); to != "" {
+		if filter.To, err = time.Parse(time.RFC3339, to); err != nil {
+			return filter, err
+		}
+	}
+	return filter, nil
+}
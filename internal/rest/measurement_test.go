@@ -0,0 +1,153 @@
+package rest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/rest"
+	"github.com/anggi-susanto/mrt-go/internal/rest/mocks"
+	"github.com/anggi-susanto/mrt-go/units"
+)
+
+const measurementEndpoint = "/measurement"
+
+func TestCreateMeasurementHandler(t *testing.T) {
+	measurements := []domain.Measurement{
+		{DeviceID: primitive.NewObjectID(), Parameter: "TSS", Value: 35, Unit: "mg/L"},
+	}
+	body, _ := json.Marshal(measurements)
+	t.Run("Success", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.MeasurementService)
+		rest.NewMeasurementHandler(app, mockService, units.Default, noAuth)
+		mockService.On("Ingest", mock.Anything, mock.Anything).Return(measurements, nil)
+		req := httptest.NewRequest(http.MethodPost, measurementEndpoint, bytes.NewReader(body))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		var response []domain.Measurement
+		data, _ := io.ReadAll(resp.Body)
+		assert.Nil(t, json.Unmarshal(data, &response))
+		assert.Equal(t, "TSS", response[0].Parameter)
+	})
+	t.Run("Invalid measurement", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.MeasurementService)
+		rest.NewMeasurementHandler(app, mockService, units.Default, noAuth)
+		mockService.On("Ingest", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("%w: parameter is required", domain.ErrInvalidMeasurement))
+		req := httptest.NewRequest(http.MethodPost, measurementEndpoint, bytes.NewReader([]byte(`[{"value":1}]`)))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+	t.Run("Out of range", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.MeasurementService)
+		rest.NewMeasurementHandler(app, mockService, units.Default, noAuth)
+		mockService.On("Ingest", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("%w: pH=15 outside [0, 14]", domain.ErrOutOfRange))
+		req := httptest.NewRequest(http.MethodPost, measurementEndpoint, bytes.NewReader(body))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusUnprocessableEntity, resp.StatusCode)
+	})
+}
+
+func TestMeasurementHandlerGetAll(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	t.Run("Success with filter", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.MeasurementService)
+		rest.NewMeasurementHandler(app, mockService, units.Default, noAuth)
+		filter := domain.MeasurementFilter{DeviceID: deviceID, Parameter: domain.ParamPH, From: from}
+		mockService.On("GetAll", mock.Anything, filter, 2, 5).Return([]domain.Measurement{{Parameter: domain.ParamPH}}, nil)
+		req := httptest.NewRequest(http.MethodGet, measurementEndpoint+"?device_id="+deviceID.Hex()+"&parameter=pH&from=2024-01-01T00:00:00Z&page=2&limit=5", nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+	t.Run("Imperial units", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.MeasurementService)
+		rest.NewMeasurementHandler(app, mockService, units.Default, noAuth)
+		mockService.On("GetAll", mock.Anything, domain.MeasurementFilter{}, 1, 10).
+			Return([]domain.Measurement{{Parameter: domain.ParamTemperature, Value: 20, Unit: "°C"}}, nil)
+		req := httptest.NewRequest(http.MethodGet, measurementEndpoint+"?units=imperial", nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var response []domain.Measurement
+		data, _ := io.ReadAll(resp.Body)
+		assert.Nil(t, json.Unmarshal(data, &response))
+		assert.InDelta(t, 68, response[0].Value, 1e-9)
+		assert.Equal(t, "°F", response[0].Unit)
+	})
+	t.Run("Unknown unit system", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.MeasurementService)
+		rest.NewMeasurementHandler(app, mockService, units.Default, noAuth)
+		req := httptest.NewRequest(http.MethodGet, measurementEndpoint+"?units=cgs", nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		mockService.AssertNotCalled(t, "GetAll")
+	})
+	t.Run("Validated only", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.MeasurementService)
+		rest.NewMeasurementHandler(app, mockService, units.Default, noAuth)
+		filter := domain.MeasurementFilter{Quality: []string{domain.QualityValidated, domain.QualityApproved}}
+		mockService.On("GetAll", mock.Anything, filter, 1, 10).Return([]domain.Measurement{}, nil)
+		req := httptest.NewRequest(http.MethodGet, measurementEndpoint+"?quality=validated,approved", nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+	t.Run("Invalid filter", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.MeasurementService)
+		rest.NewMeasurementHandler(app, mockService, units.Default, noAuth)
+		req := httptest.NewRequest(http.MethodGet, measurementEndpoint+"?from=yesterday", nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		mockService.AssertNotCalled(t, "GetAll")
+	})
+	t.Run("Error", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.MeasurementService)
+		rest.NewMeasurementHandler(app, mockService, units.Default, noAuth)
+		mockService.On("GetAll", mock.Anything, domain.MeasurementFilter{}, 1, 10).Return(nil, errors.New("error"))
+		req := httptest.NewRequest(http.MethodGet, measurementEndpoint, nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+	})
+}
@@ -0,0 +1,59 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AnomalyService is an autogenerated mock type for the AnomalyService type
+type AnomalyService struct {
+	mock.Mock
+}
+
+// GetAll provides a mock function with given fields: ctx, filter, page, limit
+func (_m *AnomalyService) GetAll(ctx context.Context, filter domain.AnomalyFilter, page int, limit int) ([]domain.AnomalyEvent, error) {
+	ret := _m.Called(ctx, filter, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAll")
+	}
+
+	var r0 []domain.AnomalyEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.AnomalyFilter, int, int) ([]domain.AnomalyEvent, error)); ok {
+		return rf(ctx, filter, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.AnomalyFilter, int, int) []domain.AnomalyEvent); ok {
+		r0 = rf(ctx, filter, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.AnomalyEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.AnomalyFilter, int, int) error); ok {
+		r1 = rf(ctx, filter, page, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewAnomalyService creates a new instance of AnomalyService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAnomalyService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AnomalyService {
+	mock := &AnomalyService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,109 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// CalibrationService is an autogenerated mock type for the CalibrationService type
+type CalibrationService struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, c
+func (_m *CalibrationService) Create(ctx context.Context, c *domain.Calibration) error {
+	ret := _m.Called(ctx, c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Calibration) error); ok {
+		r0 = rf(ctx, c)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetHistory provides a mock function with given fields: ctx, sensorID, parameter
+func (_m *CalibrationService) GetHistory(ctx context.Context, sensorID string, parameter string) ([]domain.Calibration, error) {
+	ret := _m.Called(ctx, sensorID, parameter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetHistory")
+	}
+
+	var r0 []domain.Calibration
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]domain.Calibration, error)); ok {
+		return rf(ctx, sensorID, parameter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []domain.Calibration); ok {
+		r0 = rf(ctx, sensorID, parameter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Calibration)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, sensorID, parameter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOverdue provides a mock function with given fields: ctx, at, page, limit
+func (_m *CalibrationService) GetOverdue(ctx context.Context, at time.Time, page int, limit int) ([]domain.Calibration, error) {
+	ret := _m.Called(ctx, at, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOverdue")
+	}
+
+	var r0 []domain.Calibration
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int, int) ([]domain.Calibration, error)); ok {
+		return rf(ctx, at, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int, int) []domain.Calibration); ok {
+		r0 = rf(ctx, at, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Calibration)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int, int) error); ok {
+		r1 = rf(ctx, at, page, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewCalibrationService creates a new instance of CalibrationService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCalibrationService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CalibrationService {
+	mock := &CalibrationService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
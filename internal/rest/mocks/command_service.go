@@ -0,0 +1,119 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// CommandService is an autogenerated mock type for the CommandService type
+type CommandService struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, deviceID, request
+func (_m *CommandService) Create(ctx context.Context, deviceID string, request domain.CommandRequest) (*domain.Command, error) {
+	ret := _m.Called(ctx, deviceID, request)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *domain.Command
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.CommandRequest) (*domain.Command, error)); ok {
+		return rf(ctx, deviceID, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.CommandRequest) *domain.Command); ok {
+		r0 = rf(ctx, deviceID, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Command)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, domain.CommandRequest) error); ok {
+		r1 = rf(ctx, deviceID, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAll provides a mock function with given fields: ctx, deviceID, status, page, limit
+func (_m *CommandService) GetAll(ctx context.Context, deviceID string, status string, page int, limit int) ([]domain.Command, error) {
+	ret := _m.Called(ctx, deviceID, status, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAll")
+	}
+
+	var r0 []domain.Command
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int, int) ([]domain.Command, error)); ok {
+		return rf(ctx, deviceID, status, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int, int) []domain.Command); ok {
+		r0 = rf(ctx, deviceID, status, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Command)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int, int) error); ok {
+		r1 = rf(ctx, deviceID, status, page, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByID provides a mock function with given fields: ctx, deviceID, id
+func (_m *CommandService) GetByID(ctx context.Context, deviceID string, id string) (*domain.Command, error) {
+	ret := _m.Called(ctx, deviceID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *domain.Command
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*domain.Command, error)); ok {
+		return rf(ctx, deviceID, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *domain.Command); ok {
+		r0 = rf(ctx, deviceID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Command)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, deviceID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewCommandService creates a new instance of CommandService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCommandService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CommandService {
+	mock := &CommandService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
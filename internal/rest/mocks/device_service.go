@@ -1,4 +1,4 @@
-// Code generated by mockery v2.42.1. DO NOT EDIT.
+// Code generated by mockery v2.53.3. DO NOT EDIT.
 
 package mocks
 
@@ -50,9 +50,9 @@ func (_m *DeviceService) Delete(ctx context.Context, id string) error {
 	return r0
 }
 
-// GetAll provides a mock function with given fields: ctx, page, limit
-func (_m *DeviceService) GetAll(ctx context.Context, page int, limit int) ([]domain.Device, error) {
-	ret := _m.Called(ctx, page, limit)
+// GetAll provides a mock function with given fields: ctx, filter, page, limit
+func (_m *DeviceService) GetAll(ctx context.Context, filter domain.DeviceFilter, page int, limit int) ([]domain.Device, error) {
+	ret := _m.Called(ctx, filter, page, limit)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetAll")
@@ -60,19 +60,19 @@ func (_m *DeviceService) GetAll(ctx context.Context, page int, limit int) ([]dom
 
 	var r0 []domain.Device
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]domain.Device, error)); ok {
-		return rf(ctx, page, limit)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.DeviceFilter, int, int) ([]domain.Device, error)); ok {
+		return rf(ctx, filter, page, limit)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int, int) []domain.Device); ok {
-		r0 = rf(ctx, page, limit)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.DeviceFilter, int, int) []domain.Device); ok {
+		r0 = rf(ctx, filter, page, limit)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]domain.Device)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
-		r1 = rf(ctx, page, limit)
+	if rf, ok := ret.Get(1).(func(context.Context, domain.DeviceFilter, int, int) error); ok {
+		r1 = rf(ctx, filter, page, limit)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -110,6 +110,126 @@ func (_m *DeviceService) GetByID(ctx context.Context, id string) (*domain.Device
 	return r0, r1
 }
 
+// GetFeatureCollection provides a mock function with given fields: ctx, near, page, limit
+func (_m *DeviceService) GetFeatureCollection(ctx context.Context, near *domain.NearQuery, page int, limit int) (*domain.FeatureCollection, error) {
+	ret := _m.Called(ctx, near, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFeatureCollection")
+	}
+
+	var r0 *domain.FeatureCollection
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.NearQuery, int, int) (*domain.FeatureCollection, error)); ok {
+		return rf(ctx, near, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.NearQuery, int, int) *domain.FeatureCollection); ok {
+		r0 = rf(ctx, near, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.FeatureCollection)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.NearQuery, int, int) error); ok {
+		r1 = rf(ctx, near, page, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetInventory provides a mock function with given fields: ctx
+func (_m *DeviceService) GetInventory(ctx context.Context) ([]domain.InventoryCount, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetInventory")
+	}
+
+	var r0 []domain.InventoryCount
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]domain.InventoryCount, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []domain.InventoryCount); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.InventoryCount)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetNear provides a mock function with given fields: ctx, near, page, limit
+func (_m *DeviceService) GetNear(ctx context.Context, near domain.NearQuery, page int, limit int) ([]domain.Device, error) {
+	ret := _m.Called(ctx, near, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetNear")
+	}
+
+	var r0 []domain.Device
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.NearQuery, int, int) ([]domain.Device, error)); ok {
+		return rf(ctx, near, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.NearQuery, int, int) []domain.Device); ok {
+		r0 = rf(ctx, near, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Device)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.NearQuery, int, int) error); ok {
+		r1 = rf(ctx, near, page, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Transition provides a mock function with given fields: ctx, id, t
+func (_m *DeviceService) Transition(ctx context.Context, id string, t domain.DeviceTransition) (*domain.Device, error) {
+	ret := _m.Called(ctx, id, t)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Transition")
+	}
+
+	var r0 *domain.Device
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.DeviceTransition) (*domain.Device, error)); ok {
+		return rf(ctx, id, t)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.DeviceTransition) *domain.Device); ok {
+		r0 = rf(ctx, id, t)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Device)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, domain.DeviceTransition) error); ok {
+		r1 = rf(ctx, id, t)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: ctx, w
 func (_m *DeviceService) Update(ctx context.Context, w *domain.Device) error {
 	ret := _m.Called(ctx, w)
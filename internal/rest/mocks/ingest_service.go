@@ -0,0 +1,167 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IngestService is an autogenerated mock type for the IngestService type
+type IngestService struct {
+	mock.Mock
+}
+
+// DeleteMapping provides a mock function with given fields: ctx, source
+func (_m *IngestService) DeleteMapping(ctx context.Context, source string) error {
+	ret := _m.Called(ctx, source)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteMapping")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, source)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetMappings provides a mock function with given fields: ctx
+func (_m *IngestService) GetMappings(ctx context.Context) ([]domain.IngestMapping, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMappings")
+	}
+
+	var r0 []domain.IngestMapping
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]domain.IngestMapping, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []domain.IngestMapping); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.IngestMapping)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IngestLineProtocol provides a mock function with given fields: ctx, source, payload, precision
+func (_m *IngestService) IngestLineProtocol(ctx context.Context, source string, payload []byte, precision string) (*domain.IngestResult, error) {
+	ret := _m.Called(ctx, source, payload, precision)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IngestLineProtocol")
+	}
+
+	var r0 *domain.IngestResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []byte, string) (*domain.IngestResult, error)); ok {
+		return rf(ctx, source, payload, precision)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []byte, string) *domain.IngestResult); ok {
+		r0 = rf(ctx, source, payload, precision)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.IngestResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []byte, string) error); ok {
+		r1 = rf(ctx, source, payload, precision)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IngestSenML provides a mock function with given fields: ctx, source, payload, contentType
+func (_m *IngestService) IngestSenML(ctx context.Context, source string, payload []byte, contentType string) (*domain.IngestResult, error) {
+	ret := _m.Called(ctx, source, payload, contentType)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IngestSenML")
+	}
+
+	var r0 *domain.IngestResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []byte, string) (*domain.IngestResult, error)); ok {
+		return rf(ctx, source, payload, contentType)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []byte, string) *domain.IngestResult); ok {
+		r0 = rf(ctx, source, payload, contentType)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.IngestResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []byte, string) error); ok {
+		r1 = rf(ctx, source, payload, contentType)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveMapping provides a mock function with given fields: ctx, mapping
+func (_m *IngestService) SaveMapping(ctx context.Context, mapping domain.IngestMapping) (*domain.IngestMapping, error) {
+	ret := _m.Called(ctx, mapping)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveMapping")
+	}
+
+	var r0 *domain.IngestMapping
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.IngestMapping) (*domain.IngestMapping, error)); ok {
+		return rf(ctx, mapping)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.IngestMapping) *domain.IngestMapping); ok {
+		r0 = rf(ctx, mapping)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.IngestMapping)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.IngestMapping) error); ok {
+		r1 = rf(ctx, mapping)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewIngestService creates a new instance of IngestService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIngestService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IngestService {
+	mock := &IngestService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
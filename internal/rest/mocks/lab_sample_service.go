@@ -0,0 +1,173 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// LabSampleService is an autogenerated mock type for the LabSampleService type
+type LabSampleService struct {
+	mock.Mock
+}
+
+// Correlate provides a mock function with given fields: ctx, filter
+func (_m *LabSampleService) Correlate(ctx context.Context, filter domain.LabCorrelationFilter) ([]domain.LabCorrelation, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Correlate")
+	}
+
+	var r0 []domain.LabCorrelation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.LabCorrelationFilter) ([]domain.LabCorrelation, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.LabCorrelationFilter) []domain.LabCorrelation); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.LabCorrelation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.LabCorrelationFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: ctx, w
+func (_m *LabSampleService) Create(ctx context.Context, w *domain.LabSampleRequest) error {
+	ret := _m.Called(ctx, w)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.LabSampleRequest) error); ok {
+		r0 = rf(ctx, w)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: ctx, id
+func (_m *LabSampleService) Delete(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAll provides a mock function with given fields: ctx, filter, page, limit
+func (_m *LabSampleService) GetAll(ctx context.Context, filter domain.LabSampleFilter, page int, limit int) ([]domain.LabSample, error) {
+	ret := _m.Called(ctx, filter, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAll")
+	}
+
+	var r0 []domain.LabSample
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.LabSampleFilter, int, int) ([]domain.LabSample, error)); ok {
+		return rf(ctx, filter, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.LabSampleFilter, int, int) []domain.LabSample); ok {
+		r0 = rf(ctx, filter, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.LabSample)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.LabSampleFilter, int, int) error); ok {
+		r1 = rf(ctx, filter, page, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByID provides a mock function with given fields: ctx, id
+func (_m *LabSampleService) GetByID(ctx context.Context, id string) (*domain.LabSample, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *domain.LabSample
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.LabSample, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.LabSample); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.LabSample)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, w
+func (_m *LabSampleService) Update(ctx context.Context, w *domain.LabSample) error {
+	ret := _m.Called(ctx, w)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.LabSample) error); ok {
+		r0 = rf(ctx, w)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewLabSampleService creates a new instance of LabSampleService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLabSampleService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LabSampleService {
+	mock := &LabSampleService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,149 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// LoRaWANService is an autogenerated mock type for the LoRaWANService type
+type LoRaWANService struct {
+	mock.Mock
+}
+
+// GetCodecs provides a mock function with given fields: ctx
+func (_m *LoRaWANService) GetCodecs(ctx context.Context) ([]domain.PayloadCodec, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCodecs")
+	}
+
+	var r0 []domain.PayloadCodec
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]domain.PayloadCodec, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []domain.PayloadCodec); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.PayloadCodec)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUplinks provides a mock function with given fields: ctx, deviceID, page, limit
+func (_m *LoRaWANService) GetUplinks(ctx context.Context, deviceID string, page int, limit int) ([]domain.UplinkRecord, error) {
+	ret := _m.Called(ctx, deviceID, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUplinks")
+	}
+
+	var r0 []domain.UplinkRecord
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) ([]domain.UplinkRecord, error)); ok {
+		return rf(ctx, deviceID, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) []domain.UplinkRecord); ok {
+		r0 = rf(ctx, deviceID, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.UplinkRecord)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, int) error); ok {
+		r1 = rf(ctx, deviceID, page, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Ingest provides a mock function with given fields: ctx, uplink
+func (_m *LoRaWANService) Ingest(ctx context.Context, uplink domain.Uplink) (*domain.UplinkRecord, error) {
+	ret := _m.Called(ctx, uplink)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Ingest")
+	}
+
+	var r0 *domain.UplinkRecord
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Uplink) (*domain.UplinkRecord, error)); ok {
+		return rf(ctx, uplink)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Uplink) *domain.UplinkRecord); ok {
+		r0 = rf(ctx, uplink)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.UplinkRecord)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.Uplink) error); ok {
+		r1 = rf(ctx, uplink)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveCodec provides a mock function with given fields: ctx, codec
+func (_m *LoRaWANService) SaveCodec(ctx context.Context, codec domain.PayloadCodec) (*domain.PayloadCodec, error) {
+	ret := _m.Called(ctx, codec)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveCodec")
+	}
+
+	var r0 *domain.PayloadCodec
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.PayloadCodec) (*domain.PayloadCodec, error)); ok {
+		return rf(ctx, codec)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.PayloadCodec) *domain.PayloadCodec); ok {
+		r0 = rf(ctx, codec)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.PayloadCodec)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.PayloadCodec) error); ok {
+		r1 = rf(ctx, codec)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewLoRaWANService creates a new instance of LoRaWANService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLoRaWANService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LoRaWANService {
+	mock := &LoRaWANService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
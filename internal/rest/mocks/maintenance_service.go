@@ -0,0 +1,185 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MaintenanceService is an autogenerated mock type for the MaintenanceService type
+type MaintenanceService struct {
+	mock.Mock
+}
+
+// Calendar provides a mock function with given fields: ctx, filter
+func (_m *MaintenanceService) Calendar(ctx context.Context, filter domain.MaintenanceFilter) ([]domain.CalendarEntry, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Calendar")
+	}
+
+	var r0 []domain.CalendarEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.MaintenanceFilter) ([]domain.CalendarEntry, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.MaintenanceFilter) []domain.CalendarEntry); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.CalendarEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.MaintenanceFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: ctx, w
+func (_m *MaintenanceService) Create(ctx context.Context, w *domain.WorkOrder) error {
+	ret := _m.Called(ctx, w)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.WorkOrder) error); ok {
+		r0 = rf(ctx, w)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: ctx, id
+func (_m *MaintenanceService) Delete(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAll provides a mock function with given fields: ctx, filter, page, limit
+func (_m *MaintenanceService) GetAll(ctx context.Context, filter domain.MaintenanceFilter, page int, limit int) ([]domain.WorkOrder, error) {
+	ret := _m.Called(ctx, filter, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAll")
+	}
+
+	var r0 []domain.WorkOrder
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.MaintenanceFilter, int, int) ([]domain.WorkOrder, error)); ok {
+		return rf(ctx, filter, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.MaintenanceFilter, int, int) []domain.WorkOrder); ok {
+		r0 = rf(ctx, filter, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.WorkOrder)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.MaintenanceFilter, int, int) error); ok {
+		r1 = rf(ctx, filter, page, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByID provides a mock function with given fields: ctx, id
+func (_m *MaintenanceService) GetByID(ctx context.Context, id string) (*domain.WorkOrder, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *domain.WorkOrder
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.WorkOrder, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.WorkOrder); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.WorkOrder)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, id, w
+func (_m *MaintenanceService) Update(ctx context.Context, id string, w *domain.WorkOrder) (*domain.WorkOrder, error) {
+	ret := _m.Called(ctx, id, w)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 *domain.WorkOrder
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *domain.WorkOrder) (*domain.WorkOrder, error)); ok {
+		return rf(ctx, id, w)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *domain.WorkOrder) *domain.WorkOrder); ok {
+		r0 = rf(ctx, id, w)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.WorkOrder)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *domain.WorkOrder) error); ok {
+		r1 = rf(ctx, id, w)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewMaintenanceService creates a new instance of MaintenanceService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMaintenanceService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MaintenanceService {
+	mock := &MaintenanceService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,89 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MeasurementService is an autogenerated mock type for the MeasurementService type
+type MeasurementService struct {
+	mock.Mock
+}
+
+// GetAll provides a mock function with given fields: ctx, filter, page, limit
+func (_m *MeasurementService) GetAll(ctx context.Context, filter domain.MeasurementFilter, page int, limit int) ([]domain.Measurement, error) {
+	ret := _m.Called(ctx, filter, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAll")
+	}
+
+	var r0 []domain.Measurement
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.MeasurementFilter, int, int) ([]domain.Measurement, error)); ok {
+		return rf(ctx, filter, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.MeasurementFilter, int, int) []domain.Measurement); ok {
+		r0 = rf(ctx, filter, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Measurement)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.MeasurementFilter, int, int) error); ok {
+		r1 = rf(ctx, filter, page, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Ingest provides a mock function with given fields: ctx, measurements
+func (_m *MeasurementService) Ingest(ctx context.Context, measurements []domain.Measurement) ([]domain.Measurement, error) {
+	ret := _m.Called(ctx, measurements)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Ingest")
+	}
+
+	var r0 []domain.Measurement
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.Measurement) ([]domain.Measurement, error)); ok {
+		return rf(ctx, measurements)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.Measurement) []domain.Measurement); ok {
+		r0 = rf(ctx, measurements)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Measurement)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []domain.Measurement) error); ok {
+		r1 = rf(ctx, measurements)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewMeasurementService creates a new instance of MeasurementService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMeasurementService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MeasurementService {
+	mock := &MeasurementService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
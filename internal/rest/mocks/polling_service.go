@@ -0,0 +1,59 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PollingService is an autogenerated mock type for the PollingService type
+type PollingService struct {
+	mock.Mock
+}
+
+// Poll provides a mock function with given fields: ctx, id
+func (_m *PollingService) Poll(ctx context.Context, id string) (*domain.WastewaterDataRequest, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Poll")
+	}
+
+	var r0 *domain.WastewaterDataRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.WastewaterDataRequest, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.WastewaterDataRequest); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.WastewaterDataRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewPollingService creates a new instance of PollingService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPollingService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PollingService {
+	mock := &PollingService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,239 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ProvisioningService is an autogenerated mock type for the ProvisioningService type
+type ProvisioningService struct {
+	mock.Mock
+}
+
+// Approve provides a mock function with given fields: ctx, id, decision
+func (_m *ProvisioningService) Approve(ctx context.Context, id string, decision domain.ClaimDecision) (*domain.ProvisioningClaim, error) {
+	ret := _m.Called(ctx, id, decision)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Approve")
+	}
+
+	var r0 *domain.ProvisioningClaim
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.ClaimDecision) (*domain.ProvisioningClaim, error)); ok {
+		return rf(ctx, id, decision)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.ClaimDecision) *domain.ProvisioningClaim); ok {
+		r0 = rf(ctx, id, decision)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.ProvisioningClaim)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, domain.ClaimDecision) error); ok {
+		r1 = rf(ctx, id, decision)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateClaim provides a mock function with given fields: ctx, request
+func (_m *ProvisioningService) CreateClaim(ctx context.Context, request domain.ClaimRequest) (*domain.ProvisioningClaim, error) {
+	ret := _m.Called(ctx, request)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateClaim")
+	}
+
+	var r0 *domain.ProvisioningClaim
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ClaimRequest) (*domain.ProvisioningClaim, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ClaimRequest) *domain.ProvisioningClaim); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.ProvisioningClaim)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.ClaimRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAll provides a mock function with given fields: ctx, status, page, limit
+func (_m *ProvisioningService) GetAll(ctx context.Context, status string, page int, limit int) ([]domain.ProvisioningClaim, error) {
+	ret := _m.Called(ctx, status, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAll")
+	}
+
+	var r0 []domain.ProvisioningClaim
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) ([]domain.ProvisioningClaim, error)); ok {
+		return rf(ctx, status, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) []domain.ProvisioningClaim); ok {
+		r0 = rf(ctx, status, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.ProvisioningClaim)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, int) error); ok {
+		r1 = rf(ctx, status, page, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTemplates provides a mock function with given fields: ctx
+func (_m *ProvisioningService) GetTemplates(ctx context.Context) ([]domain.DeviceTemplate, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTemplates")
+	}
+
+	var r0 []domain.DeviceTemplate
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]domain.DeviceTemplate, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []domain.DeviceTemplate); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.DeviceTemplate)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Register provides a mock function with given fields: ctx, request
+func (_m *ProvisioningService) Register(ctx context.Context, request domain.RegistrationRequest) (*domain.Registration, error) {
+	ret := _m.Called(ctx, request)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Register")
+	}
+
+	var r0 *domain.Registration
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.RegistrationRequest) (*domain.Registration, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.RegistrationRequest) *domain.Registration); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Registration)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.RegistrationRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Reject provides a mock function with given fields: ctx, id, decision
+func (_m *ProvisioningService) Reject(ctx context.Context, id string, decision domain.ClaimDecision) (*domain.ProvisioningClaim, error) {
+	ret := _m.Called(ctx, id, decision)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reject")
+	}
+
+	var r0 *domain.ProvisioningClaim
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.ClaimDecision) (*domain.ProvisioningClaim, error)); ok {
+		return rf(ctx, id, decision)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.ClaimDecision) *domain.ProvisioningClaim); ok {
+		r0 = rf(ctx, id, decision)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.ProvisioningClaim)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, domain.ClaimDecision) error); ok {
+		r1 = rf(ctx, id, decision)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveTemplate provides a mock function with given fields: ctx, template
+func (_m *ProvisioningService) SaveTemplate(ctx context.Context, template domain.DeviceTemplate) (*domain.DeviceTemplate, error) {
+	ret := _m.Called(ctx, template)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveTemplate")
+	}
+
+	var r0 *domain.DeviceTemplate
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.DeviceTemplate) (*domain.DeviceTemplate, error)); ok {
+		return rf(ctx, template)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.DeviceTemplate) *domain.DeviceTemplate); ok {
+		r0 = rf(ctx, template)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.DeviceTemplate)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.DeviceTemplate) error); ok {
+		r1 = rf(ctx, template)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewProvisioningService creates a new instance of ProvisioningService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewProvisioningService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ProvisioningService {
+	mock := &ProvisioningService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
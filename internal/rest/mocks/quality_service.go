@@ -0,0 +1,89 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// QualityService is an autogenerated mock type for the QualityService type
+type QualityService struct {
+	mock.Mock
+}
+
+// GetReviews provides a mock function with given fields: ctx, measurementID
+func (_m *QualityService) GetReviews(ctx context.Context, measurementID string) ([]domain.QualityReview, error) {
+	ret := _m.Called(ctx, measurementID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetReviews")
+	}
+
+	var r0 []domain.QualityReview
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.QualityReview, error)); ok {
+		return rf(ctx, measurementID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.QualityReview); ok {
+		r0 = rf(ctx, measurementID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.QualityReview)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, measurementID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Review provides a mock function with given fields: ctx, r
+func (_m *QualityService) Review(ctx context.Context, r *domain.QualityReviewRequest) ([]domain.QualityReview, error) {
+	ret := _m.Called(ctx, r)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Review")
+	}
+
+	var r0 []domain.QualityReview
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.QualityReviewRequest) ([]domain.QualityReview, error)); ok {
+		return rf(ctx, r)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.QualityReviewRequest) []domain.QualityReview); ok {
+		r0 = rf(ctx, r)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.QualityReview)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.QualityReviewRequest) error); ok {
+		r1 = rf(ctx, r)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewQualityService creates a new instance of QualityService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewQualityService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *QualityService {
+	mock := &QualityService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
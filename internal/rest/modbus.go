@@ -0,0 +1,55 @@
+package rest
+
+import (
+	"context"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+// PollingService is the interface that wraps the Poll method.
+type PollingService interface {
+	Poll(ctx context.Context, id string) (*domain.WastewaterDataRequest, error)
+}
+
+// PollingHandler is the handler for PollingService
+type PollingHandler struct {
+	service PollingService
+}
+
+// NewPollingHandler initializes a new PollingHandler with the provided Fiber app and PollingService.
+//
+// Parameters:
+// - app: The Fiber app instance.
+// - service: The PollingService instance.
+//
+// Return type: None.
+func NewPollingHandler(app *fiber.App, service PollingService) {
+	handler := &PollingHandler{service: service}
+	app.Post("/device/:id/modbus/poll", handler.Poll)
+}
+
+// Poll reads the Modbus instruments of a device now.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary poll a Modbus device
+// @Description read the registers mapped by the sensor types of the device over its Modbus endpoint and store them as a waste water reading, e.g. to check a register map while commissioning
+// @Tags device
+// @Produce json
+// @Param id path string true "Device ID"
+// @Success 201 {object} domain.WastewaterDataRequest
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 422 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /device/{id}/modbus/poll [post]
+func (h *PollingHandler) Poll(ctx *fiber.Ctx) error {
+	reading, err := h.service.Poll(ctx.UserContext(), ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	if reading == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ResponseError{Message: "Data not found"})
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(reading)
+}
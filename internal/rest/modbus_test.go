@@ -0,0 +1,43 @@
+package rest_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/rest"
+	"github.com/anggi-susanto/mrt-go/internal/rest/mocks"
+)
+
+func TestPollingHandlerPoll(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	poll := func(t *testing.T, reading *domain.WastewaterDataRequest, err error) int {
+		app := fiber.New()
+		mockService := new(mocks.PollingService)
+		rest.NewPollingHandler(app, mockService)
+		mockService.On("Poll", mock.Anything, deviceID.Hex()).Return(reading, err)
+		resp, testErr := app.Test(httptest.NewRequest(http.MethodPost, "/device/"+deviceID.Hex()+"/modbus/poll", nil))
+		assert.Nil(t, testErr)
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+	t.Run("Success", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusCreated, poll(t, &domain.WastewaterDataRequest{DeviceID: deviceID, PH: 7.1}, nil))
+	})
+	t.Run("Not found", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusNotFound, poll(t, nil, nil))
+	})
+	t.Run("No register map", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusBadRequest, poll(t, nil, domain.ErrInvalidModbus))
+	})
+	t.Run("Instrument unreachable", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusInternalServerError, poll(t, nil, errors.New("connection refused")))
+	})
+}
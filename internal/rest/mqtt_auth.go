@@ -0,0 +1,115 @@
+package rest
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MQTTAuthService is the interface that wraps the BrokerLogin, Superuser and Allowed methods.
+type MQTTAuthService interface {
+	BrokerLogin(ctx context.Context, username, password, clientID string) (bool, error)
+	Superuser(username string) bool
+	Allowed(username, topic string) bool
+}
+
+// MQTTAuthHandler is the handler for MQTTAuthService
+type MQTTAuthHandler struct {
+	service MQTTAuthService
+}
+
+// MQTTAuthRequest is the request of the HTTP backend of the broker authentication plugin
+type MQTTAuthRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	ClientID string `json:"clientid"`
+	Topic    string `json:"topic"`
+	Acc      int    `json:"acc"`
+}
+
+// NewMQTTAuthHandler initializes a new MQTTAuthHandler with the provided Fiber app and MQTTAuthService.
+//
+// The broker asks these endpoints whether a client may connect and reach a topic, they answer
+// 200 to allow and 403 to deny.
+//
+// Parameters:
+// - app: The Fiber app instance.
+// - service: The MQTTAuthService instance.
+//
+// Return type: None.
+func NewMQTTAuthHandler(app *fiber.App, service MQTTAuthService) {
+	handler := &MQTTAuthHandler{service: service}
+	app.Post("/mqtt/auth/user", handler.User)
+	app.Post("/mqtt/auth/superuser", handler.Superuser)
+	app.Post("/mqtt/auth/acl", handler.ACL)
+}
+
+// User checks the credentials a broker client connects with.
+//
+// @Summary check MQTT client credentials
+// @Description called by the broker when a client connects: the API with its own credentials, a device with its provisioned credentials and its ID as client ID
+// @Tags mqtt
+// @Accept json
+// @Param request body MQTTAuthRequest true "username, password and clientid"
+// @Success 200
+// @Failure 400 {object} ResponseError
+// @Failure 403
+// @Failure 500 {object} ResponseError
+// @Router /mqtt/auth/user [post]
+func (h *MQTTAuthHandler) User(ctx *fiber.Ctx) error {
+	req := MQTTAuthRequest{}
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	ok, err := h.service.BrokerLogin(ctx.UserContext(), req.Username, req.Password, req.ClientID)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
+	}
+	return allow(ctx, ok)
+}
+
+// Superuser checks whether a broker client reaches every topic.
+//
+// @Summary check MQTT superuser
+// @Description called by the broker to learn whether a client is the API, which reaches every topic
+// @Tags mqtt
+// @Accept json
+// @Param request body MQTTAuthRequest true "username"
+// @Success 200
+// @Failure 400 {object} ResponseError
+// @Failure 403
+// @Router /mqtt/auth/superuser [post]
+func (h *MQTTAuthHandler) Superuser(ctx *fiber.Ctx) error {
+	req := MQTTAuthRequest{}
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	return allow(ctx, h.service.Superuser(req.Username))
+}
+
+// ACL checks whether a broker client may publish or subscribe to a topic.
+//
+// @Summary check MQTT topic access
+// @Description called by the broker when a device publishes or subscribes, a device only reaches the topics under its own ID
+// @Tags mqtt
+// @Accept json
+// @Param request body MQTTAuthRequest true "username, clientid, topic and acc"
+// @Success 200
+// @Failure 400 {object} ResponseError
+// @Failure 403
+// @Router /mqtt/auth/acl [post]
+func (h *MQTTAuthHandler) ACL(ctx *fiber.Ctx) error {
+	req := MQTTAuthRequest{}
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	return allow(ctx, req.ClientID == req.Username && h.service.Allowed(req.Username, req.Topic))
+}
+
+// allow answers the broker with 200 to allow and 403 to deny.
+func allow(ctx *fiber.Ctx, ok bool) error {
+	if !ok {
+		return ctx.SendStatus(fiber.StatusForbidden)
+	}
+	return ctx.SendStatus(fiber.StatusOK)
+}
@@ -0,0 +1,66 @@
+package rest_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/anggi-susanto/mrt-go/internal/rest"
+	"github.com/anggi-susanto/mrt-go/internal/rest/mocks"
+)
+
+func TestMQTTAuthHandler(t *testing.T) {
+	post := func(t *testing.T, service *mocks.MQTTAuthService, path, body string) int {
+		app := fiber.New()
+		rest.NewMQTTAuthHandler(app, service)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader([]byte(body)))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+	const login = `{"username":"663200000000000000000001","password":"secret","clientid":"663200000000000000000001"}`
+	t.Run("Login allowed", func(t *testing.T) {
+		service := new(mocks.MQTTAuthService)
+		service.On("BrokerLogin", mock.Anything, "663200000000000000000001", "secret", "663200000000000000000001").Return(true, nil)
+		assert.Equal(t, fiber.StatusOK, post(t, service, "/mqtt/auth/user", login))
+	})
+	t.Run("Login denied", func(t *testing.T) {
+		service := new(mocks.MQTTAuthService)
+		service.On("BrokerLogin", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+		assert.Equal(t, fiber.StatusForbidden, post(t, service, "/mqtt/auth/user", login))
+	})
+	t.Run("Login error", func(t *testing.T) {
+		service := new(mocks.MQTTAuthService)
+		service.On("BrokerLogin", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(false, errors.New("database error"))
+		assert.Equal(t, fiber.StatusInternalServerError, post(t, service, "/mqtt/auth/user", login))
+	})
+	t.Run("Superuser", func(t *testing.T) {
+		service := new(mocks.MQTTAuthService)
+		service.On("Superuser", "mrt-api").Return(true)
+		service.On("Superuser", "663200000000000000000001").Return(false)
+		assert.Equal(t, fiber.StatusOK, post(t, service, "/mqtt/auth/superuser", `{"username":"mrt-api"}`))
+		assert.Equal(t, fiber.StatusForbidden, post(t, service, "/mqtt/auth/superuser", `{"username":"663200000000000000000001"}`))
+	})
+	t.Run("Topic access", func(t *testing.T) {
+		service := new(mocks.MQTTAuthService)
+		service.On("Allowed", "663200000000000000000001", "mrt/devices/663200000000000000000001/readings").Return(true)
+		service.On("Allowed", "663200000000000000000001", "mrt/devices/663200000000000000000002/readings").Return(false)
+		own := `{"username":"663200000000000000000001","clientid":"663200000000000000000001","topic":"mrt/devices/663200000000000000000001/readings","acc":2}`
+		other := `{"username":"663200000000000000000001","clientid":"663200000000000000000001","topic":"mrt/devices/663200000000000000000002/readings","acc":2}`
+		otherClient := `{"username":"663200000000000000000001","clientid":"other","topic":"mrt/devices/663200000000000000000001/readings","acc":2}`
+		assert.Equal(t, fiber.StatusOK, post(t, service, "/mqtt/auth/acl", own))
+		assert.Equal(t, fiber.StatusForbidden, post(t, service, "/mqtt/auth/acl", other))
+		assert.Equal(t, fiber.StatusForbidden, post(t, service, "/mqtt/auth/acl", otherClient))
+	})
+	t.Run("Malformed request", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusBadRequest, post(t, new(mocks.MQTTAuthService), "/mqtt/auth/acl", "{"))
+	})
+}
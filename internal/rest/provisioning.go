@@ -0,0 +1,227 @@
+package rest
+
+import (
+	"context"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ProvisioningService is the interface that wraps the device provisioning methods.
+type ProvisioningService interface {
+	Register(ctx context.Context, request domain.RegistrationRequest) (*domain.Registration, error)
+	CreateClaim(ctx context.Context, request domain.ClaimRequest) (*domain.ProvisioningClaim, error)
+	GetAll(ctx context.Context, status string, page int, limit int) ([]domain.ProvisioningClaim, error)
+	Approve(ctx context.Context, id string, decision domain.ClaimDecision) (*domain.ProvisioningClaim, error)
+	Reject(ctx context.Context, id string, decision domain.ClaimDecision) (*domain.ProvisioningClaim, error)
+	SaveTemplate(ctx context.Context, template domain.DeviceTemplate) (*domain.DeviceTemplate, error)
+	GetTemplates(ctx context.Context) ([]domain.DeviceTemplate, error)
+}
+
+// ProvisioningHandler is the handler for ProvisioningService
+type ProvisioningHandler struct {
+	service ProvisioningService
+}
+
+// NewProvisioningHandler initializes a new ProvisioningHandler with the provided Fiber app and ProvisioningService.
+//
+// Parameters:
+// - app: The Fiber app instance.
+// - service: The ProvisioningService instance.
+//
+// Return type: None.
+func NewProvisioningHandler(app *fiber.App, service ProvisioningService) {
+	handler := &ProvisioningHandler{service: service}
+	app.Post("/provisioning/register", handler.Register)
+	app.Post("/provisioning/claims", handler.CreateClaim)
+	app.Get("/provisioning/claims", handler.GetAll)
+	app.Post("/provisioning/claims/:id/approve", handler.Approve)
+	app.Post("/provisioning/claims/:id/reject", handler.Reject)
+	app.Put("/provisioning/templates/:model", handler.SaveTemplate)
+	app.Get("/provisioning/templates", handler.GetTemplates)
+}
+
+// Register provisions a device presenting its serial number and claim token.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary register a device
+// @Description called by a device with its factory serial number and one-time claim token; an approved claim creates the device, its sensors and credentials and returns its configuration, an unknown serial number is queued for approval and answered 202 until approved
+// @Tags provisioning
+// @Accept json
+// @Produce json
+// @Param registration body domain.RegistrationRequest true "serial number, claim token and hardware of the device"
+// @Success 201 {object} domain.Registration
+// @Success 202 {object} domain.Registration
+// @Failure 400 {object} ResponseError
+// @Failure 403 {object} ResponseError
+// @Failure 409 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /provisioning/register [post]
+func (h *ProvisioningHandler) Register(ctx *fiber.Ctx) error {
+	request := domain.RegistrationRequest{}
+	if err := ctx.BodyParser(&request); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	registration, err := h.service.Register(ctx.UserContext(), request)
+	if err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	if registration.Status == domain.ClaimPending {
+		return ctx.Status(fiber.StatusAccepted).JSON(registration)
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(registration)
+}
+
+// CreateClaim pre-registers a serial number and its claim token.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary pre-register a device
+// @Description register a factory serial number and its claim token so that the device is provisioned without approval
+// @Tags provisioning
+// @Accept json
+// @Produce json
+// @Param claim body domain.ClaimRequest true "serial number, claim token and the name, model and site of the device"
+// @Success 201 {object} domain.ProvisioningClaim
+// @Failure 400 {object} ResponseError
+// @Failure 409 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /provisioning/claims [post]
+func (h *ProvisioningHandler) CreateClaim(ctx *fiber.Ctx) error {
+	request := domain.ClaimRequest{}
+	if err := ctx.BodyParser(&request); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	claim, err := h.service.CreateClaim(ctx.UserContext(), request)
+	if err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(claim)
+}
+
+// GetAll retrieves the provisioning claims.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary get provisioning claims
+// @Description get the provisioning claims oldest first, status=pending lists the approval queue
+// @Tags provisioning
+// @Accept json
+// @Produce json
+// @Param status query string false "Status (pending, approved, rejected, claimed)"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Success 200 {array} domain.ProvisioningClaim
+// @Failure 500 {object} ResponseError
+// @Router /provisioning/claims [get]
+func (h *ProvisioningHandler) GetAll(ctx *fiber.Ctx) error {
+	page := ctx.QueryInt("page", 1)
+	limit := ctx.QueryInt("limit", 10)
+	claims, err := h.service.GetAll(ctx.UserContext(), ctx.Query("status"), page, limit)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: "Failed to get all provisioning claims"})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(claims)
+}
+
+// Approve approves a pending claim.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary approve a provisioning claim
+// @Description approve a device waiting in the approval queue, it is provisioned the next time it registers
+// @Tags provisioning
+// @Accept json
+// @Produce json
+// @Param id path string true "Claim ID"
+// @Param decision body domain.ClaimDecision false "name, model and site of the device"
+// @Success 200 {object} domain.ProvisioningClaim
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 409 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /provisioning/claims/{id}/approve [post]
+func (h *ProvisioningHandler) Approve(ctx *fiber.Ctx) error {
+	return h.decide(ctx, h.service.Approve)
+}
+
+// Reject rejects a pending claim.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary reject a provisioning claim
+// @Description reject a device waiting in the approval queue, its registrations are refused from then on
+// @Tags provisioning
+// @Accept json
+// @Produce json
+// @Param id path string true "Claim ID"
+// @Param decision body domain.ClaimDecision false "who rejected the claim"
+// @Success 200 {object} domain.ProvisioningClaim
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 409 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /provisioning/claims/{id}/reject [post]
+func (h *ProvisioningHandler) Reject(ctx *fiber.Ctx) error {
+	return h.decide(ctx, h.service.Reject)
+}
+
+// SaveTemplate creates or replaces the template of a model.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary save a device template
+// @Description set the sensors created on and the initial configuration sent to the devices of a model when they register
+// @Tags provisioning
+// @Accept json
+// @Produce json
+// @Param model path string true "Device model"
+// @Param template body domain.DeviceTemplate true "sensors and configuration of the model"
+// @Success 200 {object} domain.DeviceTemplate
+// @Failure 400 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /provisioning/templates/{model} [put]
+func (h *ProvisioningHandler) SaveTemplate(ctx *fiber.Ctx) error {
+	template := domain.DeviceTemplate{}
+	if err := ctx.BodyParser(&template); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	template.Model = ctx.Params("model")
+	saved, err := h.service.SaveTemplate(ctx.UserContext(), template)
+	if err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(saved)
+}
+
+// GetTemplates retrieves the device templates.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary get device templates
+// @Description get the templates of every device model
+// @Tags provisioning
+// @Accept json
+// @Produce json
+// @Success 200 {array} domain.DeviceTemplate
+// @Failure 500 {object} ResponseError
+// @Router /provisioning/templates [get]
+func (h *ProvisioningHandler) GetTemplates(ctx *fiber.Ctx) error {
+	templates, err := h.service.GetTemplates(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: "Failed to get all device templates"})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(templates)
+}
+
+// decide applies an approval or a rejection, the decision body is optional.
+func (h *ProvisioningHandler) decide(ctx *fiber.Ctx, decide func(context.Context, string, domain.ClaimDecision) (*domain.ProvisioningClaim, error)) error {
+	decision := domain.ClaimDecision{}
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&decision); err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+		}
+	}
+	claim, err := decide(ctx.UserContext(), ctx.Params("id"), decision)
+	if err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	if claim == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ResponseError{Message: "Data not found"})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(claim)
+}
@@ -0,0 +1,117 @@
+package rest_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/rest"
+	"github.com/anggi-susanto/mrt-go/internal/rest/mocks"
+)
+
+func TestProvisioningHandlerRegister(t *testing.T) {
+	body := `{"serial_number":"WQ-0001","claim_token":"s3cret","model":"WQ-5"}`
+	register := func(t *testing.T, registration *domain.Registration, err error) int {
+		app := fiber.New()
+		mockService := new(mocks.ProvisioningService)
+		rest.NewProvisioningHandler(app, mockService)
+		mockService.On("Register", mock.Anything, domain.RegistrationRequest{SerialNumber: "WQ-0001", ClaimToken: "s3cret", Model: "WQ-5"}).Return(registration, err)
+		req := httptest.NewRequest(http.MethodPost, "/provisioning/register", bytes.NewReader([]byte(body)))
+		req.Header.Set(contentType, applicationJson)
+		resp, testErr := app.Test(req)
+		assert.Nil(t, testErr)
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+	t.Run("Provisioned", func(t *testing.T) {
+		status := register(t, &domain.Registration{Status: domain.ClaimClaimed, Device: &domain.Device{ID: primitive.NewObjectID()}}, nil)
+		assert.Equal(t, fiber.StatusCreated, status)
+	})
+	t.Run("Pending approval", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusAccepted, register(t, &domain.Registration{Status: domain.ClaimPending}, nil))
+	})
+	t.Run("Refused", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusForbidden, register(t, nil, domain.ErrClaimRefused))
+	})
+	t.Run("Token already used", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusConflict, register(t, nil, domain.ErrClaimTransition))
+	})
+	t.Run("Error", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusInternalServerError, register(t, nil, errors.New("error")))
+	})
+}
+
+func TestProvisioningHandlerGetAll(t *testing.T) {
+	app := fiber.New()
+	mockService := new(mocks.ProvisioningService)
+	rest.NewProvisioningHandler(app, mockService)
+	mockService.On("GetAll", mock.Anything, domain.ClaimPending, 1, 10).Return([]domain.ProvisioningClaim{{SerialNumber: "WQ-0001"}}, nil)
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/provisioning/claims?status=pending", nil))
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestProvisioningHandlerApprove(t *testing.T) {
+	claimID := primitive.NewObjectID()
+	t.Run("Success", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.ProvisioningService)
+		rest.NewProvisioningHandler(app, mockService)
+		mockService.On("Approve", mock.Anything, claimID.Hex(), domain.ClaimDecision{Model: "WQ-5", DecidedBy: "ops"}).
+			Return(&domain.ProvisioningClaim{ID: claimID, Status: domain.ClaimApproved}, nil)
+		req := httptest.NewRequest(http.MethodPost, "/provisioning/claims/"+claimID.Hex()+"/approve", bytes.NewReader([]byte(`{"model":"WQ-5","decided_by":"ops"}`)))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+	t.Run("Without body", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.ProvisioningService)
+		rest.NewProvisioningHandler(app, mockService)
+		mockService.On("Reject", mock.Anything, claimID.Hex(), domain.ClaimDecision{}).Return(&domain.ProvisioningClaim{ID: claimID}, nil)
+		resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/provisioning/claims/"+claimID.Hex()+"/reject", nil))
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+	t.Run("Not found", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.ProvisioningService)
+		rest.NewProvisioningHandler(app, mockService)
+		mockService.On("Approve", mock.Anything, claimID.Hex(), mock.Anything).Return(nil, nil)
+		resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/provisioning/claims/"+claimID.Hex()+"/approve", nil))
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestProvisioningHandlerSaveTemplate(t *testing.T) {
+	app := fiber.New()
+	mockService := new(mocks.ProvisioningService)
+	rest.NewProvisioningHandler(app, mockService)
+	mockService.On("SaveTemplate", mock.Anything, mock.MatchedBy(func(t domain.DeviceTemplate) bool {
+		return t.Model == "WQ-5" && len(t.Sensors) == 1 && t.Config["interval"] == 300.0
+	})).Return(&domain.DeviceTemplate{Model: "WQ-5"}, nil)
+	body := `{"sensors":[{"name":"pH probe"}],"config":{"interval":300}}`
+	req := httptest.NewRequest(http.MethodPut, "/provisioning/templates/WQ-5", bytes.NewReader([]byte(body)))
+	req.Header.Set(contentType, applicationJson)
+	resp, err := app.Test(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,54 @@
+package rest
+
+import (
+	"context"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+// PSKService is the interface that wraps the Rotate method.
+type PSKService interface {
+	Rotate(ctx context.Context, deviceID string) (*domain.PSKSecret, error)
+}
+
+// PSKHandler is the handler for PSKService
+type PSKHandler struct {
+	service PSKService
+}
+
+// NewPSKHandler initializes a new PSKHandler with the provided Fiber app and PSKService.
+//
+// Parameters:
+// - app: The Fiber app instance.
+// - service: The PSKService instance.
+//
+// Return type: None.
+func NewPSKHandler(app *fiber.App, service PSKService) {
+	handler := &PSKHandler{service: service}
+	app.Post("/device/:id/psk", handler.Rotate)
+}
+
+// Rotate issues a new CoAP pre-shared key to a device.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary issue device pre-shared key
+// @Description issue a new DTLS pre-shared key for CoAP to a device, replacing the previous one; the key is only returned here
+// @Tags device
+// @Produce json
+// @Param id path string true "Device ID"
+// @Success 201 {object} domain.PSKSecret
+// @Failure 404 {object} ResponseError
+// @Failure 409 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /device/{id}/psk [post]
+func (h *PSKHandler) Rotate(ctx *fiber.Ctx) error {
+	secret, err := h.service.Rotate(ctx.UserContext(), ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	if secret == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ResponseError{Message: "Data not found"})
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(secret)
+}
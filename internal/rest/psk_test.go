@@ -0,0 +1,42 @@
+package rest_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/rest"
+	"github.com/anggi-susanto/mrt-go/internal/rest/mocks"
+)
+
+func TestPSKHandlerRotate(t *testing.T) {
+	rotate := func(t *testing.T, secret *domain.PSKSecret, err error) int {
+		app := fiber.New()
+		mockService := new(mocks.PSKService)
+		rest.NewPSKHandler(app, mockService)
+		mockService.On("Rotate", mock.Anything, "663200000000000000000001").Return(secret, err)
+		resp, testErr := app.Test(httptest.NewRequest(http.MethodPost, "/device/663200000000000000000001/psk", nil))
+		assert.Nil(t, testErr)
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+	t.Run("Success", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusCreated, rotate(t, &domain.PSKSecret{Identity: "663200000000000000000001"}, nil))
+	})
+	t.Run("Device not found", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusNotFound, rotate(t, nil, nil))
+	})
+	t.Run("Decommissioned", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusConflict, rotate(t, nil, fmt.Errorf("%w: device", domain.ErrDeviceDecommissioned)))
+	})
+	t.Run("Service error", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusInternalServerError, rotate(t, nil, errors.New("database error")))
+	})
+}
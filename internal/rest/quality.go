@@ -0,0 +1,77 @@
+package rest
+
+import (
+	"context"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+// QualityService is the interface that wraps the Review and GetReviews methods.
+type QualityService interface {
+	Review(ctx context.Context, r *domain.QualityReviewRequest) ([]domain.QualityReview, error)
+	GetReviews(ctx context.Context, measurementID string) ([]domain.QualityReview, error)
+}
+
+// QualityHandler is the handler for QualityService
+type QualityHandler struct {
+	service QualityService
+}
+
+// NewQualityHandler initializes a new QualityHandler with the provided Fiber app and QualityService.
+//
+// Parameters:
+// - app: The Fiber app instance.
+// - service: The QualityService instance.
+//
+// Return type: None.
+func NewQualityHandler(app *fiber.App, service QualityService) {
+	handler := &QualityHandler{service: service}
+	app.Post("/measurement/review", handler.Review)
+	app.Get("/measurement/:id/review", handler.GetReviews)
+}
+
+// Review sets the quality flag of measurements.
+//
+// @Summary review measurement quality
+// @Description set the quality flag (suspect, invalid, validated, approved) of measurements with a reason, only validated measurements can be approved
+// @Tags measurement
+// @Accept json
+// @Produce json
+// @Param review body domain.QualityReviewRequest true "quality review"
+// @Success 200 {array} domain.QualityReview
+// @Failure 400 {object} ResponseError
+// @Failure 409 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /measurement/review [post]
+func (h *QualityHandler) Review(ctx *fiber.Ctx) error {
+	r := &domain.QualityReviewRequest{}
+	if err := ctx.BodyParser(r); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	reviews, err := h.service.Review(ctx.UserContext(), r)
+	if err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(reviews)
+}
+
+// GetReviews retrieves the quality review history of a measurement.
+//
+// @Summary get measurement quality reviews
+// @Description get the quality review audit trail of a measurement, oldest first
+// @Tags measurement
+// @Accept json
+// @Produce json
+// @Param id path string true "Measurement ID"
+// @Success 200 {array} domain.QualityReview
+// @Failure 400 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /measurement/{id}/review [get]
+func (h *QualityHandler) GetReviews(ctx *fiber.Ctx) error {
+	reviews, err := h.service.GetReviews(ctx.UserContext(), ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(reviews)
+}
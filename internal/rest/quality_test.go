@@ -0,0 +1,66 @@
+package rest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/rest"
+	"github.com/anggi-susanto/mrt-go/internal/rest/mocks"
+)
+
+func TestQualityHandlerReview(t *testing.T) {
+	review := domain.QualityReviewRequest{
+		MeasurementIDs: []primitive.ObjectID{primitive.NewObjectID()},
+		Quality:        domain.QualityApproved,
+		Reason:         "monthly sign off",
+	}
+	body, _ := json.Marshal(review)
+	t.Run("Success", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.QualityService)
+		rest.NewQualityHandler(app, mockService)
+		mockService.On("Review", mock.Anything, &review).Return([]domain.QualityReview{{To: domain.QualityApproved}}, nil)
+		req := httptest.NewRequest(http.MethodPost, "/measurement/review", bytes.NewReader(body))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+	t.Run("Transition not allowed", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.QualityService)
+		rest.NewQualityHandler(app, mockService)
+		mockService.On("Review", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("%w: from raw to approved", domain.ErrQualityTransition))
+		req := httptest.NewRequest(http.MethodPost, "/measurement/review", bytes.NewReader(body))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+	})
+}
+
+func TestQualityHandlerGetReviews(t *testing.T) {
+	id := primitive.NewObjectID()
+	app := fiber.New()
+	mockService := new(mocks.QualityService)
+	rest.NewQualityHandler(app, mockService)
+	mockService.On("GetReviews", mock.Anything, id.Hex()).Return([]domain.QualityReview{{MeasurementID: id}}, nil)
+	req := httptest.NewRequest(http.MethodGet, "/measurement/"+id.Hex()+"/review", nil)
+	resp, err := app.Test(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
@@ -60,7 +60,7 @@ func (h *SensorHandler) Create(ctx *fiber.Ctx) error {
 	if err := ctx.BodyParser(w); err != nil {
 		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
 	}
-	if err := h.service.Create(ctx.Context(), w); err != nil {
+	if err := h.service.Create(ctx.UserContext(), w); err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
 	}
 	return ctx.Status(fiber.StatusCreated).JSON(w)
@@ -81,7 +81,7 @@ func (h *SensorHandler) Create(ctx *fiber.Ctx) error {
 func (h *SensorHandler) GetAll(ctx *fiber.Ctx) error {
 	page := ctx.QueryInt("page", 1)
 	limit := ctx.QueryInt("limit", 10)
-	wastes, err := h.service.GetAll(ctx.Context(), page, limit)
+	wastes, err := h.service.GetAll(ctx.UserContext(), page, limit)
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: "Failed to get all sensor data"})
 	}
@@ -93,7 +93,7 @@ func (h *SensorHandler) GetAll(ctx *fiber.Ctx) error {
 // ctx *fiber.Ctx - Context object containing the request information.
 // error - Returns an error if one occurs.
 // @Summary get sensor data by id
-// @Description get sensor data by id
+// @Description get sensor data by id with its health score and fault diagnostics
 // @Tags sensor
 // @Accept json
 // @Produce json
@@ -104,7 +104,7 @@ func (h *SensorHandler) GetAll(ctx *fiber.Ctx) error {
 // @Failure 404 {object} ResponseError
 func (h *SensorHandler) GetByID(ctx *fiber.Ctx) error {
 	id := ctx.Params("id")
-	w, err := h.service.GetByID(ctx.Context(), id)
+	w, err := h.service.GetByID(ctx.UserContext(), id)
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
 	}
@@ -134,7 +134,7 @@ func (h *SensorHandler) Update(ctx *fiber.Ctx) error {
 	if err := ctx.BodyParser(w); err != nil {
 		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
 	}
-	if err := h.service.Update(ctx.Context(), w); err != nil {
+	if err := h.service.Update(ctx.UserContext(), w); err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
 	}
 	return ctx.Status(fiber.StatusOK).JSON(w)
@@ -155,7 +155,7 @@ func (h *SensorHandler) Update(ctx *fiber.Ctx) error {
 // @Failure 404 {object} ResponseError
 func (h *SensorHandler) Delete(ctx *fiber.Ctx) error {
 	id := ctx.Params("id")
-	if err := h.service.Delete(ctx.Context(), id); err != nil {
+	if err := h.service.Delete(ctx.UserContext(), id); err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
 	}
 	return ctx.SendStatus(fiber.StatusNoContent)
@@ -0,0 +1,160 @@
+package rest
+
+import (
+	"context"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+// SensorTypeService is the interface that wraps the Create, GetAll, GetByID, Update, and Delete methods.
+type SensorTypeService interface {
+	Create(ctx context.Context, w *domain.SensorTypeRequest) error
+	GetAll(ctx context.Context, page int, limit int) ([]domain.SensorType, error)
+	Delete(ctx context.Context, id string) error
+	Update(ctx context.Context, w *domain.SensorType) error
+	GetByID(ctx context.Context, id string) (*domain.SensorType, error)
+}
+
+// SensorTypeHandler is the handler for SensorTypeService
+type SensorTypeHandler struct {
+	service SensorTypeService
+}
+
+// SensorTypeIDEndpoint is the endpoint for SensorTypeService
+const SensorTypeIDEndpoint = "/sensor-type/:id"
+
+// NewSensorTypeHandler initializes a new SensorTypeHandler with the provided Fiber app and SensorTypeService.
+//
+// Parameters:
+// - app: The Fiber app instance.
+// - service: The SensorTypeService instance.
+//
+// Return type: None.
+func NewSensorTypeHandler(app *fiber.App, service SensorTypeService) {
+	handler := &SensorTypeHandler{service: service}
+	app.Post("/sensor-type", handler.Create)
+	app.Get("/sensor-type", handler.GetAll)
+	app.Get(SensorTypeIDEndpoint, handler.GetByID)
+	app.Put(SensorTypeIDEndpoint, handler.Update)
+	app.Delete(SensorTypeIDEndpoint, handler.Delete)
+}
+
+// Create handles the creation of sensor type data.
+//
+// @Summary create sensor type data
+// @Description create sensor type data
+// @Tags sensor type
+// @Accept json
+// @Produce json
+// @Param sensor_type body domain.SensorTypeRequest true "sensor type data"
+// @Success 201 {object} domain.SensorTypeRequest
+// @Failure 400 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /sensor-type [post]
+func (h *SensorTypeHandler) Create(ctx *fiber.Ctx) error {
+	w := &domain.SensorTypeRequest{}
+	if err := ctx.BodyParser(w); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	if err := h.service.Create(ctx.UserContext(), w); err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(w)
+}
+
+// GetAll retrieves all sensor type data.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary get all sensor type data
+// @Description get all sensor type data
+// @Tags sensor type
+// @Accept json
+// @Produce json
+// @Param page query int true "Page number"
+// @Success 200 {array} domain.SensorType "Sensor type data"
+// @Failure 500 {object} ResponseError
+// @Router /sensor-type [get]
+func (h *SensorTypeHandler) GetAll(ctx *fiber.Ctx) error {
+	page := ctx.QueryInt("page", 1)
+	limit := ctx.QueryInt("limit", 10)
+	types, err := h.service.GetAll(ctx.UserContext(), page, limit)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: "Failed to get all sensor type data"})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(types)
+}
+
+// GetByID retrieves a SensorType object by ID.
+//
+// ctx *fiber.Ctx - Context object containing the request information.
+// error - Returns an error if one occurs.
+// @Summary get sensor type data by id
+// @Description get sensor type data by id
+// @Tags sensor type
+// @Accept json
+// @Produce json
+// @Param id path string true "Sensor type data ID"
+// @Success 200 {object} domain.SensorType
+// @Failure 500 {object} ResponseError
+// @Router /sensor-type/{id} [get]
+// @Failure 404 {object} ResponseError
+func (h *SensorTypeHandler) GetByID(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	w, err := h.service.GetByID(ctx.UserContext(), id)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
+	}
+
+	if w == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ResponseError{Message: "Data not found"})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(w)
+}
+
+// Update updates the SensorTypeHandler.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary update sensor type data
+// @Description update sensor type data
+// @Tags sensor type
+// @Accept json
+// @Produce json
+// @Param id path string true "Sensor type data ID"
+// @Param sensor_type body domain.SensorType true "sensor type data"
+// @Success 200 {object} domain.SensorType
+// @Failure 400 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /sensor-type/{id} [put]
+// @Failure 404 {object} ResponseError
+func (h *SensorTypeHandler) Update(ctx *fiber.Ctx) error {
+	w := &domain.SensorType{}
+	if err := ctx.BodyParser(w); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	if err := h.service.Update(ctx.UserContext(), w); err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(w)
+}
+
+// Delete deletes a SensorTypeHandler item.
+//
+// ctx *fiber.Ctx parameter. Returns an error.
+// @Summary delete sensor type data
+// @Description delete sensor type data
+// @Tags sensor type
+// @Accept json
+// @Produce json
+// @Param id path string true "Sensor type data ID"
+// @Success 204
+// @Failure 500 {object} ResponseError
+// @Router /sensor-type/{id} [delete]
+// @Failure 404 {object} ResponseError
+func (h *SensorTypeHandler) Delete(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if err := h.service.Delete(ctx.UserContext(), id); err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
+	}
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
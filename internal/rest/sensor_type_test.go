@@ -0,0 +1,138 @@
+package rest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/rest"
+	"github.com/anggi-susanto/mrt-go/internal/rest/mocks"
+)
+
+const sensorTypeEnpoint = "/sensor-type"
+
+func TestCreateSensorTypeHandlerSuccess(t *testing.T) {
+	app := fiber.New()
+	mockService := new(mocks.SensorTypeService)
+	rest.NewSensorTypeHandler(app, mockService)
+
+	mockService.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	sensorType := domain.SensorTypeRequest{
+		Code: "ph-probe",
+		Parameters: []domain.ParameterSpec{
+			{Parameter: domain.ParamPH, Unit: "pH", Min: 0, Max: 14},
+		},
+	}
+	body, _ := json.Marshal(sensorType)
+
+	req := httptest.NewRequest(http.MethodPost, sensorTypeEnpoint, bytes.NewReader(body))
+	req.Header.Set(contentType, applicationJson)
+	resp, err := app.Test(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	respData := domain.SensorTypeRequest{}
+	_ = json.Unmarshal(data, &respData)
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	assert.Equal(t, sensorType.Parameters, respData.Parameters)
+}
+
+func TestCreateSensorTypeHandlerInvalid(t *testing.T) {
+	app := fiber.New()
+	mockService := new(mocks.SensorTypeService)
+	rest.NewSensorTypeHandler(app, mockService)
+
+	mockService.On("Create", mock.Anything, mock.Anything).Return(fmt.Errorf("%w: code is required", domain.ErrInvalidSensorType))
+
+	req := httptest.NewRequest(http.MethodPost, sensorTypeEnpoint, bytes.NewReader([]byte("{}")))
+	req.Header.Set(contentType, applicationJson)
+	resp, err := app.Test(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "{\"message\":\"invalid sensor type: code is required\"}", string(data))
+}
+
+func TestSensorTypeHandlerGetAll(t *testing.T) {
+	sensorTypes := []domain.SensorType{
+		{
+			Code: "ph-probe",
+		},
+	}
+	t.Run("Success with default values", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.SensorTypeService)
+		rest.NewSensorTypeHandler(app, mockService)
+		mockService.On("GetAll", mock.Anything, 1, 10).Return(sensorTypes, nil)
+		req := httptest.NewRequest(http.MethodGet, sensorTypeEnpoint, nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var response []domain.SensorType
+		data, _ := io.ReadAll(resp.Body)
+
+		err = json.Unmarshal(data, &response)
+		assert.Nil(t, err)
+		assert.Equal(t, response[0].Code, sensorTypes[0].Code)
+	})
+
+	t.Run("Error case", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.SensorTypeService)
+		rest.NewSensorTypeHandler(app, mockService)
+		mockService.On("GetAll", mock.Anything, 1, 10).Return(nil, errors.New("error"))
+		req := httptest.NewRequest(http.MethodGet, sensorTypeEnpoint, nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+
+		data, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "{\"message\":\"Failed to get all sensor type data\"}", string(data))
+	})
+}
+
+func TestSensorTypeHandlerGetByID(t *testing.T) {
+	sensorType := domain.SensorType{
+		ID:   primitive.NewObjectID(),
+		Code: "ph-probe",
+	}
+	t.Run("Valid ID", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.SensorTypeService)
+		rest.NewSensorTypeHandler(app, mockService)
+		mockService.On("GetByID", mock.Anything, sensorType.ID.Hex()).Return(&sensorType, nil)
+		req := httptest.NewRequest(http.MethodGet, sensorTypeEnpoint+"/"+sensorType.ID.Hex(), nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.SensorTypeService)
+		rest.NewSensorTypeHandler(app, mockService)
+		mockService.On("GetByID", mock.Anything, sensorType.ID.Hex()).Return(nil, nil)
+		req := httptest.NewRequest(http.MethodGet, sensorTypeEnpoint+"/"+sensorType.ID.Hex(), nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -0,0 +1,87 @@
+package rest
+
+import (
+	"context"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ShadowService is the interface that wraps the device shadow methods.
+type ShadowService interface {
+	Get(ctx context.Context, deviceID string) (*domain.Shadow, error)
+	UpdateDesired(ctx context.Context, deviceID string, update domain.ShadowUpdate) (*domain.Shadow, error)
+}
+
+// ShadowHandler is the handler for ShadowService
+type ShadowHandler struct {
+	service ShadowService
+}
+
+// NewShadowHandler initializes a new ShadowHandler with the provided Fiber app and ShadowService.
+//
+// Parameters:
+// - app: The Fiber app instance.
+// - service: The ShadowService instance.
+//
+// Return type: None.
+func NewShadowHandler(app *fiber.App, service ShadowService) {
+	handler := &ShadowHandler{service: service}
+	app.Get("/device/:id/shadow", handler.Get)
+	app.Put("/device/:id/shadow/desired", handler.UpdateDesired)
+}
+
+// Get retrieves the shadow of a device.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary get device shadow
+// @Description get the desired and reported configuration of a device and the delta between them
+// @Tags device shadow
+// @Accept json
+// @Produce json
+// @Param id path string true "Device ID"
+// @Success 200 {object} domain.Shadow
+// @Failure 404 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /device/{id}/shadow [get]
+func (h *ShadowHandler) Get(ctx *fiber.Ctx) error {
+	shadow, err := h.service.Get(ctx.UserContext(), ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
+	}
+	if shadow == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ResponseError{Message: "Data not found"})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(shadow)
+}
+
+// UpdateDesired changes the desired configuration of a device.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary update desired device configuration
+// @Description merge keys into the desired configuration of a device, a null value removes a key; the resulting delta is delivered to the device over MQTT and to its CoAP observers
+// @Tags device shadow
+// @Accept json
+// @Produce json
+// @Param id path string true "Device ID"
+// @Param update body domain.ShadowUpdate true "desired keys and the shadow version they were made against"
+// @Success 200 {object} domain.Shadow
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 409 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /device/{id}/shadow/desired [put]
+func (h *ShadowHandler) UpdateDesired(ctx *fiber.Ctx) error {
+	update := domain.ShadowUpdate{}
+	if err := ctx.BodyParser(&update); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	shadow, err := h.service.UpdateDesired(ctx.UserContext(), ctx.Params("id"), update)
+	if err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	if shadow == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ResponseError{Message: "Data not found"})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(shadow)
+}
@@ -0,0 +1,85 @@
+package rest_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/rest"
+	"github.com/anggi-susanto/mrt-go/internal/rest/mocks"
+)
+
+func TestShadowHandlerGet(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	t.Run("Success", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.ShadowService)
+		rest.NewShadowHandler(app, mockService)
+		mockService.On("Get", mock.Anything, deviceID.Hex()).Return(&domain.Shadow{DeviceID: deviceID}, nil)
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/device/"+deviceID.Hex()+"/shadow", nil))
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+	t.Run("Not found", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.ShadowService)
+		rest.NewShadowHandler(app, mockService)
+		mockService.On("Get", mock.Anything, deviceID.Hex()).Return(nil, nil)
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/device/"+deviceID.Hex()+"/shadow", nil))
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestShadowHandlerUpdateDesired(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	body := `{"state":{"interval":60,"thresholds":{"pH":9}},"version":2}`
+	t.Run("Success", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.ShadowService)
+		rest.NewShadowHandler(app, mockService)
+		mockService.On("UpdateDesired", mock.Anything, deviceID.Hex(), mock.MatchedBy(func(u domain.ShadowUpdate) bool {
+			return u.Version == 2 && u.State["interval"] == 60.0
+		})).Return(&domain.Shadow{DeviceID: deviceID, Version: 3}, nil)
+		req := httptest.NewRequest(http.MethodPut, "/device/"+deviceID.Hex()+"/shadow/desired", bytes.NewReader([]byte(body)))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+	t.Run("Version conflict", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.ShadowService)
+		rest.NewShadowHandler(app, mockService)
+		mockService.On("UpdateDesired", mock.Anything, deviceID.Hex(), mock.Anything).Return(nil, domain.ErrShadowVersion)
+		req := httptest.NewRequest(http.MethodPut, "/device/"+deviceID.Hex()+"/shadow/desired", bytes.NewReader([]byte(body)))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+	})
+	t.Run("Not found", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.ShadowService)
+		rest.NewShadowHandler(app, mockService)
+		mockService.On("UpdateDesired", mock.Anything, deviceID.Hex(), mock.Anything).Return(nil, nil)
+		req := httptest.NewRequest(http.MethodPut, "/device/"+deviceID.Hex()+"/shadow/desired", bytes.NewReader([]byte(body)))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -0,0 +1,186 @@
+package rest
+
+import (
+	"context"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+// SiteService is the interface that wraps the Create, GetAll, GetByID, Update, Delete and GetDevices methods.
+type SiteService interface {
+	Create(ctx context.Context, w *domain.SiteRequest) error
+	GetAll(ctx context.Context, page int, limit int) ([]domain.Site, error)
+	Delete(ctx context.Context, id string) error
+	Update(ctx context.Context, w *domain.Site) error
+	GetByID(ctx context.Context, id string) (*domain.Site, error)
+	GetDevices(ctx context.Context, id string, page int, limit int) ([]domain.Device, error)
+}
+
+// SiteHandler is the handler for SiteService
+type SiteHandler struct {
+	service SiteService
+}
+
+// SiteIDEndpoint is the endpoint for SiteService
+const SiteIDEndpoint = "/site/:id"
+
+// NewSiteHandler initializes a new SiteHandler with the provided Fiber app and SiteService.
+//
+// Parameters:
+// - app: The Fiber app instance.
+// - service: The SiteService instance.
+//
+// Return type: None.
+func NewSiteHandler(app *fiber.App, service SiteService) {
+	handler := &SiteHandler{service: service}
+	app.Post("/site", handler.Create)
+	app.Get("/site", handler.GetAll)
+	app.Get(SiteIDEndpoint, handler.GetByID)
+	app.Put(SiteIDEndpoint, handler.Update)
+	app.Delete(SiteIDEndpoint, handler.Delete)
+	app.Get(SiteIDEndpoint+"/devices", handler.GetDevices)
+}
+
+// Create handles the creation of site data.
+//
+// @Summary create site data
+// @Description create site data
+// @Tags site
+// @Accept json
+// @Produce json
+// @Param site body domain.SiteRequest true "site data"
+// @Success 201 {object} domain.SiteRequest
+// @Failure 400 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /site [post]
+func (h *SiteHandler) Create(ctx *fiber.Ctx) error {
+	w := &domain.SiteRequest{}
+	if err := ctx.BodyParser(w); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	if err := h.service.Create(ctx.UserContext(), w); err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(w)
+}
+
+// GetAll retrieves all site data.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary get all site data
+// @Description get all site data
+// @Tags site
+// @Accept json
+// @Produce json
+// @Param page query int true "Page number"
+// @Success 200 {array} domain.Site "Site data"
+// @Failure 500 {object} ResponseError
+// @Router /site [get]
+func (h *SiteHandler) GetAll(ctx *fiber.Ctx) error {
+	page := ctx.QueryInt("page", 1)
+	limit := ctx.QueryInt("limit", 10)
+	sites, err := h.service.GetAll(ctx.UserContext(), page, limit)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: "Failed to get all site data"})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(sites)
+}
+
+// GetByID retrieves a Site object by ID.
+//
+// ctx *fiber.Ctx - Context object containing the request information.
+// error - Returns an error if one occurs.
+// @Summary get site data by id
+// @Description get site data by id
+// @Tags site
+// @Accept json
+// @Produce json
+// @Param id path string true "Site data ID"
+// @Success 200 {object} domain.Site
+// @Failure 500 {object} ResponseError
+// @Router /site/{id} [get]
+// @Failure 404 {object} ResponseError
+func (h *SiteHandler) GetByID(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	w, err := h.service.GetByID(ctx.UserContext(), id)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
+	}
+
+	if w == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(ResponseError{Message: "Data not found"})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(w)
+}
+
+// Update updates the SiteHandler.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary update site data
+// @Description update site data
+// @Tags site
+// @Accept json
+// @Produce json
+// @Param id path string true "Site data ID"
+// @Param site body domain.Site true "site data"
+// @Success 200 {object} domain.Site
+// @Failure 400 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /site/{id} [put]
+// @Failure 404 {object} ResponseError
+func (h *SiteHandler) Update(ctx *fiber.Ctx) error {
+	w := &domain.Site{}
+	if err := ctx.BodyParser(w); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	if err := h.service.Update(ctx.UserContext(), w); err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(w)
+}
+
+// Delete deletes a SiteHandler item.
+//
+// ctx *fiber.Ctx parameter. Returns an error.
+// @Summary delete site data
+// @Description delete site data
+// @Tags site
+// @Accept json
+// @Produce json
+// @Param id path string true "Site data ID"
+// @Success 204
+// @Failure 500 {object} ResponseError
+// @Router /site/{id} [delete]
+// @Failure 404 {object} ResponseError
+func (h *SiteHandler) Delete(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	if err := h.service.Delete(ctx.UserContext(), id); err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
+	}
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// GetDevices retrieves the devices assigned to a site.
+//
+// It takes a fiber context as a parameter and returns an error.
+// @Summary get devices of a site
+// @Description get the devices assigned to a site
+// @Tags site
+// @Accept json
+// @Produce json
+// @Param id path string true "Site data ID"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Success 200 {array} domain.Device "Device data"
+// @Failure 500 {object} ResponseError
+// @Router /site/{id}/devices [get]
+func (h *SiteHandler) GetDevices(ctx *fiber.Ctx) error {
+	page := ctx.QueryInt("page", 1)
+	limit := ctx.QueryInt("limit", 10)
+	devices, err := h.service.GetDevices(ctx.UserContext(), ctx.Params("id"), page, limit)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(devices)
+}
@@ -0,0 +1,174 @@
+package rest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/rest"
+	"github.com/anggi-susanto/mrt-go/internal/rest/mocks"
+)
+
+const siteEnpoint = "/site"
+
+func TestCreateSiteHandlerSuccess(t *testing.T) {
+	app := fiber.New()
+	mockService := new(mocks.SiteService)
+	rest.NewSiteHandler(app, mockService)
+
+	mockService.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	site := domain.SiteRequest{
+		Name:     "Site",
+		Operator: "Operator",
+	}
+	body, _ := json.Marshal(site)
+
+	req := httptest.NewRequest(http.MethodPost, siteEnpoint, bytes.NewReader(body))
+	req.Header.Set(contentType, applicationJson)
+	resp, err := app.Test(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	respData := domain.SiteRequest{}
+	_ = json.Unmarshal(data, &respData)
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	assert.Equal(t, respData.Name, site.Name)
+}
+
+func TestCreateSiteHandlerInvalidGeometry(t *testing.T) {
+	app := fiber.New()
+	mockService := new(mocks.SiteService)
+	rest.NewSiteHandler(app, mockService)
+
+	mockService.On("Create", mock.Anything, mock.Anything).Return(domain.ErrInvalidGeometry)
+
+	req := httptest.NewRequest(http.MethodPost, siteEnpoint, bytes.NewReader([]byte(`{"name":"Site","geometry":{"type":"Line"}}`)))
+	req.Header.Set(contentType, applicationJson)
+	resp, err := app.Test(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "{\"message\":\"invalid GeoJSON geometry\"}", string(data))
+}
+
+func TestSiteHandlerGetAll(t *testing.T) {
+	site := []domain.Site{
+		{
+			Name: "site",
+		},
+	}
+	t.Run("Success with default values", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.SiteService)
+		rest.NewSiteHandler(app, mockService)
+		mockService.On("GetAll", mock.Anything, 1, 10).Return(site, nil)
+		req := httptest.NewRequest(http.MethodGet, siteEnpoint, nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var response []map[string]interface{}
+		data, _ := io.ReadAll(resp.Body)
+
+		err = json.Unmarshal(data, &response)
+		assert.Nil(t, err)
+		assert.Equal(t, response[0]["name"], site[0].Name)
+	})
+
+	t.Run("Error case", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.SiteService)
+		rest.NewSiteHandler(app, mockService)
+		mockService.On("GetAll", mock.Anything, 1, 10).Return(nil, errors.New("error"))
+		req := httptest.NewRequest(http.MethodGet, siteEnpoint, nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+
+		data, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "{\"message\":\"Failed to get all site data\"}", string(data))
+	})
+}
+
+func TestSiteHandlerGetByID(t *testing.T) {
+	site := domain.Site{
+		ID:   primitive.NewObjectID(),
+		Name: "site",
+	}
+	t.Run("Valid ID", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.SiteService)
+		rest.NewSiteHandler(app, mockService)
+		mockService.On("GetByID", mock.Anything, site.ID.Hex()).Return(&site, nil)
+		req := httptest.NewRequest(http.MethodGet, siteEnpoint+"/"+site.ID.Hex(), nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.SiteService)
+		rest.NewSiteHandler(app, mockService)
+		mockService.On("GetByID", mock.Anything, site.ID.Hex()).Return(nil, nil)
+		req := httptest.NewRequest(http.MethodGet, siteEnpoint+"/"+site.ID.Hex(), nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestSiteHandlerGetDevices(t *testing.T) {
+	siteID := primitive.NewObjectID()
+	devices := []domain.Device{
+		{
+			Name:   "device",
+			SiteID: siteID,
+		},
+	}
+	t.Run("Success", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.SiteService)
+		rest.NewSiteHandler(app, mockService)
+		mockService.On("GetDevices", mock.Anything, siteID.Hex(), 1, 10).Return(devices, nil)
+		req := httptest.NewRequest(http.MethodGet, siteEnpoint+"/"+siteID.Hex()+"/devices", nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var response []map[string]interface{}
+		data, _ := io.ReadAll(resp.Body)
+		err = json.Unmarshal(data, &response)
+		assert.Nil(t, err)
+		assert.Equal(t, siteID.Hex(), response[0]["site_id"])
+	})
+
+	t.Run("Error case", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.SiteService)
+		rest.NewSiteHandler(app, mockService)
+		mockService.On("GetDevices", mock.Anything, siteID.Hex(), 1, 10).Return(nil, errors.New("error"))
+		req := httptest.NewRequest(http.MethodGet, siteEnpoint+"/"+siteID.Hex()+"/devices", nil)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+	})
+}
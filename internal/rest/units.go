@@ -0,0 +1,27 @@
+package rest
+
+import (
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/units"
+	"github.com/gofiber/fiber/v2"
+)
+
+// UnitRegistry is the interface that wraps the unit system lookup and conversion methods.
+type UnitRegistry interface {
+	System(name string) (units.System, error)
+	ConvertMeasurements(measurements []domain.Measurement, system units.System) error
+	ConvertWasteWater(w *domain.WasteWaterData, system units.System) error
+}
+
+// unitSystem returns the unit system requested with the units query parameter, or nil when none was requested.
+func unitSystem(ctx *fiber.Ctx, registry UnitRegistry) (*units.System, error) {
+	name := ctx.Query("units")
+	if name == "" {
+		return nil, nil
+	}
+	system, err := registry.System(name)
+	if err != nil {
+		return nil, err
+	}
+	return &system, nil
+}
@@ -2,9 +2,13 @@ package rest
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/anggi-susanto/mrt-go/domain"
 	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // ResponseError represents an error response
@@ -23,22 +27,29 @@ type WasteWaterServices interface {
 
 // WasteWaterHandler is the handler for WasteWaterServices
 type WasteWaterHandler struct {
-	service WasteWaterServices
+	service      WasteWaterServices
+	unitRegistry UnitRegistry
 }
 
 // WasteWaterIDEndpoint is the endpoint for WasteWaterServices
 const WasteWaterIDEndpoint = "/waste-water/:id"
 
+// maxBatchReadings is the largest number of readings accepted in a batch
+const maxBatchReadings = 500
+
 // NewWasteWaterHandler initializes a new WasteWaterHandler with the provided Fiber app and WasteWaterServices.
 //
 // Parameters:
 // - app: The Fiber app instance.
 // - service: The WasteWaterServices instance.
+// - unitRegistry: The UnitRegistry converting values to the unit system requested with units=.
+// - deviceAuth: The middleware authenticating the devices sending readings.
 //
 // Return type: None.
-func NewWasteWaterHandler(app *fiber.App, service WasteWaterServices) {
-	handler := &WasteWaterHandler{service: service}
-	app.Post("/waste-water", handler.Create)
+func NewWasteWaterHandler(app *fiber.App, service WasteWaterServices, unitRegistry UnitRegistry, deviceAuth fiber.Handler) {
+	handler := &WasteWaterHandler{service: service, unitRegistry: unitRegistry}
+	app.Post("/waste-water", deviceAuth, handler.Create)
+	app.Post("/waste-water/batch", deviceAuth, handler.CreateBatch)
 	app.Get("/waste-water", handler.GetAll)
 	app.Get(WasteWaterIDEndpoint, handler.GetByID)
 	app.Put(WasteWaterIDEndpoint, handler.Update)
@@ -48,13 +59,17 @@ func NewWasteWaterHandler(app *fiber.App, service WasteWaterServices) {
 // Create handles the creation of waste water data.
 //
 // @Summary create waste water data
-// @Description create waste water data
+// @Description create waste water data of the device authenticated with its credentials, a reading without device_id belongs to that device
 // @Tags waste water
 // @Accept json
 // @Produce json
+// @Security DeviceAuth
 // @Param waste_water body domain.WasteWaterData true "waste water data"
 // @Success 201 {object} domain.WasteWaterData
 // @Failure 400 {object} ResponseError
+// @Failure 401 {object} ResponseError
+// @Failure 403 {object} ResponseError
+// @Failure 422 {object} ResponseError
 // @Failure 500 {object} ResponseError
 // @Router /waste-water [post]
 func (h *WasteWaterHandler) Create(ctx *fiber.Ctx) error {
@@ -62,12 +77,66 @@ func (h *WasteWaterHandler) Create(ctx *fiber.Ctx) error {
 	if err := ctx.BodyParser(w); err != nil {
 		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
 	}
-	if err := h.service.Create(ctx.Context(), w); err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
+	if err := bindDevice(ctx, &w.DeviceID); err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
+	if err := h.service.Create(ctx.UserContext(), w); err != nil {
+		// A reading sent again with its dedup key is answered with the stored reading ID
+		if errors.Is(err, domain.ErrDuplicateReading) {
+			return ctx.Status(fiber.StatusOK).JSON(w)
+		}
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
 	}
 	return ctx.Status(fiber.StatusCreated).JSON(w)
 }
 
+// CreateBatch handles the creation of a batch of waste water data.
+//
+// @Summary create a batch of waste water data
+// @Description store up to 500 readings of the authenticated device at once and report the outcome of each, readings with a dedup key already stored are reported as duplicate; rejected readings are invalid or of another device while failed ones can be sent again
+// @Tags waste water
+// @Accept json
+// @Produce json
+// @Security DeviceAuth
+// @Param batch body domain.WasteWaterBatch true "readings with their dedup keys"
+// @Success 200 {object} domain.WasteWaterBatchResult
+// @Failure 400 {object} ResponseError
+// @Failure 401 {object} ResponseError
+// @Router /waste-water/batch [post]
+func (h *WasteWaterHandler) CreateBatch(ctx *fiber.Ctx) error {
+	// The clock is read on arrival, the time spent storing the readings would bias the offset the sender measures
+	arrived := time.Now()
+	batch := domain.WasteWaterBatch{}
+	if err := ctx.BodyParser(&batch); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+	}
+	if len(batch.Readings) > maxBatchReadings {
+		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: fmt.Sprintf("a batch holds at most %d readings", maxBatchReadings)})
+	}
+	result := domain.WasteWaterBatchResult{ServerTime: arrived, Items: make([]domain.BatchItemResult, 0, len(batch.Readings))}
+	for i := range batch.Readings {
+		w := &batch.Readings[i]
+		item := domain.BatchItemResult{DedupKey: w.DedupKey, Status: domain.BatchCreated}
+		err := bindDevice(ctx, &w.DeviceID)
+		if err == nil {
+			err = h.service.Create(ctx.UserContext(), w)
+		}
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrDuplicateReading):
+				item.Status = domain.BatchDuplicate
+			case statusFromError(err) < fiber.StatusInternalServerError:
+				item.Status, item.Error = domain.BatchRejected, err.Error()
+			default:
+				item.Status, item.Error = domain.BatchFailed, err.Error()
+			}
+		}
+		item.ID = w.ID
+		result.Items = append(result.Items, item)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(result)
+}
+
 // GetAll retrieves all waste water data.
 //
 // It takes a fiber context as a parameter and returns an error.
@@ -77,16 +146,29 @@ func (h *WasteWaterHandler) Create(ctx *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param page query int true "Page number"
+// @Param units query string false "Unit system of the returned values (metric, imperial)"
 // @Success 200 {array} domain.WasteWaterData "Waste water data"
+// @Failure 400 {object} ResponseError
 // @Failure 500 {object} ResponseError
 // @Router /waste-water [get]
 func (h *WasteWaterHandler) GetAll(ctx *fiber.Ctx) error {
+	system, err := unitSystem(ctx, h.unitRegistry)
+	if err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
 	page := ctx.QueryInt("page", 1)
 	limit := ctx.QueryInt("limit", 10)
-	wastes, err := h.service.GetAll(ctx.Context(), page, limit)
+	wastes, err := h.service.GetAll(ctx.UserContext(), page, limit)
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: "Failed to get all waste water data"})
 	}
+	if system != nil {
+		for i := range wastes {
+			if err = h.unitRegistry.ConvertWasteWater(&wastes[i], *system); err != nil {
+				return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
+			}
+		}
+	}
 	return ctx.Status(fiber.StatusOK).JSON(wastes)
 }
 
@@ -100,13 +182,19 @@ func (h *WasteWaterHandler) GetAll(ctx *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path string true "Waste water data ID"
+// @Param units query string false "Unit system of the returned values (metric, imperial)"
 // @Success 200 {object} domain.WasteWaterData
+// @Failure 400 {object} ResponseError
 // @Failure 500 {object} ResponseError
 // @Router /waste-water/{id} [get]
 // @Failure 404 {object} ResponseError
 func (h *WasteWaterHandler) GetByID(ctx *fiber.Ctx) error {
+	system, err := unitSystem(ctx, h.unitRegistry)
+	if err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
+	}
 	id := ctx.Params("id")
-	w, err := h.service.GetByID(ctx.Context(), id)
+	w, err := h.service.GetByID(ctx.UserContext(), id)
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
 	}
@@ -114,6 +202,11 @@ func (h *WasteWaterHandler) GetByID(ctx *fiber.Ctx) error {
 	if w == nil {
 		return ctx.Status(fiber.StatusNotFound).JSON(ResponseError{Message: "Data not found"})
 	}
+	if system != nil {
+		if err = h.unitRegistry.ConvertWasteWater(w, *system); err != nil {
+			return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
+		}
+	}
 	return ctx.Status(fiber.StatusOK).JSON(w)
 }
 
@@ -121,14 +214,17 @@ func (h *WasteWaterHandler) GetByID(ctx *fiber.Ctx) error {
 //
 // It takes a fiber context as a parameter and returns an error.
 // @Summary update waste water data
-// @Description update waste water data
+// @Description update waste water data, values are stored as sent without calibration; unchanged values keep their quality flag; changing or removing a value a reviewer flagged is refused
 // @Tags waste water
 // @Accept json
 // @Produce json
+// @Param id path string true "Waste water data ID"
 // @Param waste_water body domain.WasteWaterData true "waste water data"
 // @Success 200 {object} domain.WasteWaterData
 // @Failure 400 {object} ResponseError
+// @Failure 409 {object} ResponseError
 // @Failure 500 {object} ResponseError
+// @Failure 422 {object} ResponseError
 // @Router /waste-water/{id} [put]
 // @Failure 404 {object} ResponseError
 func (h *WasteWaterHandler) Update(ctx *fiber.Ctx) error {
@@ -136,8 +232,16 @@ func (h *WasteWaterHandler) Update(ctx *fiber.Ctx) error {
 	if err := ctx.BodyParser(w); err != nil {
 		return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
 	}
-	if err := h.service.Update(ctx.Context(), w); err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
+	// The reading is identified by the path when the body carries no ID
+	if w.ID.IsZero() {
+		id, err := primitive.ObjectIDFromHex(ctx.Params("id"))
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(ResponseError{Message: err.Error()})
+		}
+		w.ID = id
+	}
+	if err := h.service.Update(ctx.UserContext(), w); err != nil {
+		return ctx.Status(statusFromError(err)).JSON(ResponseError{Message: err.Error()})
 	}
 	return ctx.Status(fiber.StatusOK).JSON(w)
 }
@@ -157,7 +261,7 @@ func (h *WasteWaterHandler) Update(ctx *fiber.Ctx) error {
 // @Failure 404 {object} ResponseError
 func (h *WasteWaterHandler) Delete(ctx *fiber.Ctx) error {
 	id := ctx.Params("id")
-	if err := h.service.Delete(ctx.Context(), id); err != nil {
+	if err := h.service.Delete(ctx.UserContext(), id); err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(ResponseError{Message: err.Error()})
 	}
 	return ctx.SendStatus(fiber.StatusNoContent)
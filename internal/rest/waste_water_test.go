@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -17,16 +18,22 @@ import (
 	"github.com/anggi-susanto/mrt-go/domain"
 	"github.com/anggi-susanto/mrt-go/internal/rest"
 	"github.com/anggi-susanto/mrt-go/internal/rest/mocks"
+	"github.com/anggi-susanto/mrt-go/units"
 )
 
 const wasteWaterEnpoint = "/waste-water"
 const contentType = "Content-Type"
 const applicationJson = "application/json"
 
+// noAuth admits every request, the handlers are tested without device authentication
+func noAuth(ctx *fiber.Ctx) error {
+	return ctx.Next()
+}
+
 func TestCreateWasteWaterHandlerSuccess(t *testing.T) {
 	app := fiber.New()
 	mockService := new(mocks.WasteWaterServices)
-	rest.NewWasteWaterHandler(app, mockService)
+	rest.NewWasteWaterHandler(app, mockService, units.Default, noAuth)
 
 	mockService.On("Create", mock.Anything, mock.Anything).Return(nil)
 
@@ -51,7 +58,7 @@ func TestCreateWasteWaterHandlerSuccess(t *testing.T) {
 func TestCreateWasteWaterHandlerErrorParsingRequestBody(t *testing.T) {
 	app := fiber.New()
 	mockService := new(mocks.WasteWaterServices)
-	rest.NewWasteWaterHandler(app, mockService)
+	rest.NewWasteWaterHandler(app, mockService, units.Default, noAuth)
 
 	mockService.On("Create", mock.Anything, mock.Anything).Return(nil)
 
@@ -69,7 +76,7 @@ func TestCreateWasteWaterHandlerErrorParsingRequestBody(t *testing.T) {
 func TestCreateWasteWaterHandlerErrorCreatingData(t *testing.T) {
 	app := fiber.New()
 	mockService := new(mocks.WasteWaterServices)
-	rest.NewWasteWaterHandler(app, mockService)
+	rest.NewWasteWaterHandler(app, mockService, units.Default, noAuth)
 
 	// Create a mock request body
 	waterData := domain.WasteWaterData{
@@ -88,6 +95,93 @@ func TestCreateWasteWaterHandlerErrorCreatingData(t *testing.T) {
 	assert.Equal(t, "{\"message\":\"error\"}", string(data))
 }
 
+func TestCreateWasteWaterHandlerOutOfRange(t *testing.T) {
+	app := fiber.New()
+	mockService := new(mocks.WasteWaterServices)
+	rest.NewWasteWaterHandler(app, mockService, units.Default, noAuth)
+
+	body, _ := json.Marshal(domain.WastewaterDataRequest{PH: 15})
+	mockService.On("Create", mock.Anything, mock.Anything).Return(fmt.Errorf("%w: pH=15 outside [0, 14]", domain.ErrOutOfRange))
+
+	req := httptest.NewRequest(http.MethodPost, wasteWaterEnpoint, bytes.NewReader(body))
+	req.Header.Set(contentType, applicationJson)
+	resp, err := app.Test(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, fiber.StatusUnprocessableEntity, resp.StatusCode)
+	assert.Equal(t, "{\"message\":\"value outside sensor range: pH=15 outside [0, 14]\"}", string(data))
+}
+
+func TestCreateWasteWaterHandlerDuplicate(t *testing.T) {
+	app := fiber.New()
+	mockService := new(mocks.WasteWaterServices)
+	rest.NewWasteWaterHandler(app, mockService, units.Default, noAuth)
+
+	existing := primitive.NewObjectID()
+	body, _ := json.Marshal(domain.WastewaterDataRequest{BOD: 10, DedupKey: "edge-1:42"})
+	mockService.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.WastewaterDataRequest).ID = existing
+	}).Return(domain.ErrDuplicateReading)
+
+	req := httptest.NewRequest(http.MethodPost, wasteWaterEnpoint, bytes.NewReader(body))
+	req.Header.Set(contentType, applicationJson)
+	resp, err := app.Test(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	respData := domain.WasteWaterData{}
+	_ = json.Unmarshal(data, &respData)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, existing, respData.ID)
+}
+
+func TestWasteWaterHandlerCreateBatch(t *testing.T) {
+	t.Run("Outcome of each reading", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.WasteWaterServices)
+		rest.NewWasteWaterHandler(app, mockService, units.Default, noAuth)
+		outcomes := map[string]error{
+			"edge-1:1": nil,
+			"edge-1:2": domain.ErrDuplicateReading,
+			"edge-1:3": fmt.Errorf("%w: pH=15 outside [0, 14]", domain.ErrOutOfRange),
+			"edge-1:4": errors.New("connection refused"),
+		}
+		for key, outcome := range outcomes {
+			key := key
+			mockService.On("Create", mock.Anything, mock.MatchedBy(func(w *domain.WastewaterDataRequest) bool {
+				return w.DedupKey == key
+			})).Return(outcome)
+		}
+		body := `{"readings":[{"dedup_key":"edge-1:1"},{"dedup_key":"edge-1:2"},{"dedup_key":"edge-1:3"},{"dedup_key":"edge-1:4"}]}`
+		req := httptest.NewRequest(http.MethodPost, "/waste-water/batch", bytes.NewReader([]byte(body)))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		result := domain.WasteWaterBatchResult{}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		assert.False(t, result.ServerTime.IsZero())
+		statuses := []string{}
+		for _, item := range result.Items {
+			statuses = append(statuses, item.Status)
+		}
+		assert.Equal(t, []string{domain.BatchCreated, domain.BatchDuplicate, domain.BatchRejected, domain.BatchFailed}, statuses)
+	})
+	t.Run("Too many readings", func(t *testing.T) {
+		app := fiber.New()
+		rest.NewWasteWaterHandler(app, new(mocks.WasteWaterServices), units.Default, noAuth)
+		body, _ := json.Marshal(domain.WasteWaterBatch{Readings: make([]domain.WastewaterDataRequest, 501)})
+		req := httptest.NewRequest(http.MethodPost, "/waste-water/batch", bytes.NewReader(body))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+}
+
 func TestWasteWaterHandlerGetAll(t *testing.T) {
 
 	waterData := []domain.WasteWaterData{
@@ -98,7 +192,7 @@ func TestWasteWaterHandlerGetAll(t *testing.T) {
 	t.Run("Success with default values", func(t *testing.T) {
 		app := fiber.New()
 		mockService := new(mocks.WasteWaterServices) // Implement a mock service for testing purposes
-		rest.NewWasteWaterHandler(app, mockService)
+		rest.NewWasteWaterHandler(app, mockService, units.Default, noAuth)
 		mockService.On("GetAll", mock.Anything, 1, 10).Return(waterData, nil)
 		req := httptest.NewRequest(http.MethodGet, "/waste-water", nil)
 		resp, err := app.Test(req)
@@ -117,7 +211,7 @@ func TestWasteWaterHandlerGetAll(t *testing.T) {
 	t.Run("Error case", func(t *testing.T) {
 		app := fiber.New()
 		mockService := new(mocks.WasteWaterServices) // Implement a mock service for testing purposes
-		rest.NewWasteWaterHandler(app, mockService)
+		rest.NewWasteWaterHandler(app, mockService, units.Default, noAuth)
 		mockService.On("GetAll", mock.Anything, 1, 10).Return(nil, errors.New("error"))
 		req := httptest.NewRequest(http.MethodGet, "/waste-water", nil)
 		resp, err := app.Test(req)
@@ -140,7 +234,7 @@ func TestWasteWaterHandlerGetByID(t *testing.T) {
 	t.Run("Valid ID", func(t *testing.T) {
 		app := fiber.New()
 		mockService := new(mocks.WasteWaterServices) // Implement a mock service for testing purposes
-		rest.NewWasteWaterHandler(app, mockService)
+		rest.NewWasteWaterHandler(app, mockService, units.Default, noAuth)
 		mockService.On("GetByID", mock.Anything, waterData.ID.String()).Return(&waterData, nil)
 		req := httptest.NewRequest(http.MethodGet, "/waste-water/"+waterData.ID.String(), nil)
 		resp, err := app.Test(req)
@@ -160,7 +254,7 @@ func TestWasteWaterHandlerGetByID(t *testing.T) {
 	t.Run("Invalid ID", func(t *testing.T) {
 		app := fiber.New()
 		mockService := new(mocks.WasteWaterServices) // Implement a mock service for testing purposes
-		rest.NewWasteWaterHandler(app, mockService)
+		rest.NewWasteWaterHandler(app, mockService, units.Default, noAuth)
 		mockService.On("GetByID", mock.Anything, waterData.ID.String()).Return(nil, nil)
 		req := httptest.NewRequest(http.MethodGet, "/waste-water/"+waterData.ID.String(), nil)
 		resp, err := app.Test(req)
@@ -179,7 +273,7 @@ func TestWasteWaterHandlerGetByID(t *testing.T) {
 	t.Run("Error handling", func(t *testing.T) {
 		app := fiber.New()
 		mockService := new(mocks.WasteWaterServices) // Implement a mock service for testing purposes
-		rest.NewWasteWaterHandler(app, mockService)
+		rest.NewWasteWaterHandler(app, mockService, units.Default, noAuth)
 		mockService.On("GetByID", mock.Anything, waterData.ID.String()).Return(nil, errors.New("error"))
 		req := httptest.NewRequest(http.MethodGet, "/waste-water/"+waterData.ID.String(), nil)
 		resp, err := app.Test(req)
@@ -191,3 +285,34 @@ func TestWasteWaterHandlerGetByID(t *testing.T) {
 		assert.Equal(t, "{\"message\":\"error\"}", string(data))
 	})
 }
+
+func TestWasteWaterHandlerUpdate(t *testing.T) {
+	id := primitive.NewObjectID()
+	t.Run("ID from path", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.WasteWaterServices)
+		rest.NewWasteWaterHandler(app, mockService, units.Default, noAuth)
+		mockService.On("Update", mock.Anything, mock.MatchedBy(func(w *domain.WasteWaterData) bool {
+			return w.ID == id && w.BOD == 10
+		})).Return(nil)
+		req := httptest.NewRequest(http.MethodPut, "/waste-water/"+id.Hex(), bytes.NewReader([]byte(`{"BOD":10}`)))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+	t.Run("Invalid ID", func(t *testing.T) {
+		app := fiber.New()
+		mockService := new(mocks.WasteWaterServices)
+		rest.NewWasteWaterHandler(app, mockService, units.Default, noAuth)
+		req := httptest.NewRequest(http.MethodPut, "/waste-water/1", bytes.NewReader([]byte(`{"BOD":10}`)))
+		req.Header.Set(contentType, applicationJson)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		mockService.AssertNotCalled(t, "Update")
+	})
+}
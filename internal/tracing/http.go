@@ -0,0 +1,84 @@
+package tracing
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware starts a server span per request, continuing the trace of the W3C traceparent
+// header when the request has one.
+//
+// The span is in the user context of the request, the handlers pass ctx.UserContext() on to the
+// services for their spans to be its children. It must be registered before the routes it traces.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// The method and path are backed by the request buffer, which is reused before the span is exported
+		method := utils.CopyString(c.Method())
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), headerCarrier{&c.Request().Header})
+		ctx, span := tracer.Start(ctx, method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPRequestMethodKey.String(method), semconv.URLPath(utils.CopyString(c.Path()))),
+		)
+		defer span.End()
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		route := c.Route().Path
+		// The error is turned into the response by the error handler only after the middleware returns
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+				// The router answers 404 and 405 itself when no route matches, the handlers of the API never do
+				if status == fiber.StatusNotFound || status == fiber.StatusMethodNotAllowed {
+					route = ""
+				}
+			}
+			span.RecordError(err)
+		}
+
+		if route != "" {
+			span.SetName(method + " " + route)
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, utils.StatusMessage(status))
+		}
+		return err
+	}
+}
+
+// headerCarrier reads the propagated trace context from the request headers.
+type headerCarrier struct {
+	header *fasthttp.RequestHeader
+}
+
+// Get returns the value of a header.
+func (h headerCarrier) Get(key string) string {
+	return string(h.header.Peek(key))
+}
+
+// Set sets the value of a header.
+func (h headerCarrier) Set(key, value string) {
+	h.header.Set(key, value)
+}
+
+// Keys returns the names of the headers.
+func (h headerCarrier) Keys() []string {
+	var keys []string
+	h.header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
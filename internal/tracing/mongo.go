@@ -0,0 +1,78 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// commandMonitor starts a client span per MongoDB command, a child of the span of the
+// repository method that sent it.
+type commandMonitor struct {
+	// spans keeps the spans of the commands in flight by request ID
+	spans sync.Map
+}
+
+// CommandMonitor returns the monitor to set on the MongoDB client options.
+func CommandMonitor() *event.CommandMonitor {
+	cm := &commandMonitor{}
+	return &event.CommandMonitor{
+		Started:   cm.started,
+		Succeeded: cm.succeeded,
+		Failed:    cm.failed,
+	}
+}
+
+// started starts the span of a command, named after the command and its collection, e.g. find devices.
+func (cm *commandMonitor) started(ctx context.Context, e *event.CommandStartedEvent) {
+	name := e.CommandName
+	attributes := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemMongoDB, semconv.DBName(e.DatabaseName), semconv.DBOperation(e.CommandName)),
+	}
+	if collection := collectionOf(e.CommandName, e.Command); collection != "" {
+		name += " " + collection
+		attributes = append(attributes, trace.WithAttributes(semconv.DBMongoDBCollection(collection)))
+	}
+	_, span := tracer.Start(ctx, name, attributes...)
+	cm.spans.Store(e.RequestID, span)
+}
+
+// succeeded ends the span of a command that succeeded.
+func (cm *commandMonitor) succeeded(_ context.Context, e *event.CommandSucceededEvent) {
+	if span, ok := cm.spans.LoadAndDelete(e.RequestID); ok {
+		span.(trace.Span).End()
+	}
+}
+
+// failed ends the span of a command that failed with its failure.
+func (cm *commandMonitor) failed(_ context.Context, e *event.CommandFailedEvent) {
+	if s, ok := cm.spans.LoadAndDelete(e.RequestID); ok {
+		span := s.(trace.Span)
+		span.RecordError(errors.New(e.Failure))
+		span.SetStatus(codes.Error, e.Failure)
+		span.End()
+	}
+}
+
+// collectionOf returns the collection a command runs on, empty for the commands on the database.
+//
+// The collection is the value of the command name for most commands, e.g. {find: "devices"},
+// getMore names it in a field of its own.
+func collectionOf(commandName string, command bson.Raw) string {
+	key := commandName
+	if commandName == "getMore" {
+		key = "collection"
+	}
+	collection, ok := command.Lookup(key).StringValueOK()
+	if !ok {
+		return ""
+	}
+	return collection
+}
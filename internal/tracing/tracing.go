@@ -0,0 +1,94 @@
+// Package tracing sets up OpenTelemetry tracing: the tracer provider and its exporter, the
+// W3C trace context propagation of the HTTP requests and the spans of the MongoDB commands.
+//
+// The services and repositories start their spans on the global tracer provider, so they
+// record nothing until Setup installs one.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+// The exporters the spans may be sent to
+const (
+	ExporterNone   = ""
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+// instrumentation names the tracer of the spans started by this package
+const instrumentation = "github.com/anggi-susanto/mrt-go/internal/tracing"
+
+// tracer starts the HTTP and MongoDB spans, on the provider installed by Setup
+var tracer = otel.Tracer(instrumentation)
+
+// Setup installs the tracer provider exporting to the configured exporter and the W3C trace
+// context and baggage propagators.
+//
+// The propagators are installed without an exporter too, so the trace context of the requests
+// still reaches the spans of the services they call.
+//
+// Parameters:
+// - ctx: the context the exporter connects with.
+// - cfg: the exporter, its endpoint, the service name and the sampling ratio.
+//
+// Returns the shutdown function flushing the spans left and an error if the exporter is unknown
+// or cannot be created.
+func Setup(ctx context.Context, cfg config.TracingConfig) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	provider, err := NewProvider(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if provider == nil {
+		return func(context.Context) error { return nil }, nil
+	}
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// NewProvider creates the tracer provider batching the spans to the configured exporter.
+//
+// Parameters:
+// - ctx: the context the exporter connects with.
+// - cfg: the exporter, its endpoint, the service name and the sampling ratio.
+//
+// Returns nil without an exporter and an error if the exporter is unknown or cannot be created.
+func NewProvider(ctx context.Context, cfg config.TracingConfig) (*sdktrace.TracerProvider, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case ExporterNone:
+		return nil, nil
+	case ExporterOTLP:
+		options := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			options = append(options, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(ctx, options...)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(cfg.ServiceName))),
+	), nil
+}
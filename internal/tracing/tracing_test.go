@@ -0,0 +1,136 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/internal/tracing"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// recorder records the spans of the tests, the global tracer provider can only be installed once
+var recorder = tracetest.NewSpanRecorder()
+
+func TestMain(m *testing.M) {
+	if _, err := tracing.Setup(context.Background(), config.TracingConfig{}); err != nil {
+		panic(err)
+	}
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	os.Exit(m.Run())
+}
+
+// ended returns the spans ended since the mark
+func ended(mark int) []sdktrace.ReadOnlySpan {
+	return recorder.Ended()[mark:]
+}
+
+// attributeOf returns the value of an attribute of a span
+func attributeOf(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestMiddleware(t *testing.T) {
+	app := fiber.New()
+	app.Use(tracing.Middleware())
+	app.Get("/devices/:id", func(c *fiber.Ctx) error {
+		_, span := otel.Tracer("test").Start(c.UserContext(), "device.Service.GetByID")
+		span.End()
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Post("/devices", func(c *fiber.Ctx) error {
+		return errors.New("database down")
+	})
+
+	t.Run("Propagation", func(t *testing.T) {
+		mark := len(recorder.Ended())
+		req := httptest.NewRequest("GET", "/devices/42", nil)
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		_, err := app.Test(req)
+		require.NoError(t, err)
+
+		spans := ended(mark)
+		require.Len(t, spans, 2)
+		service, server := spans[0], spans[1]
+		assert.Equal(t, "GET /devices/:id", server.Name())
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+		assert.True(t, server.Parent().IsRemote())
+		assert.Equal(t, "/devices/:id", attributeOf(server, "http.route").AsString())
+		assert.Equal(t, int64(200), attributeOf(server, "http.response.status_code").AsInt64())
+		assert.Equal(t, server.SpanContext().SpanID(), service.Parent().SpanID())
+	})
+	t.Run("Error", func(t *testing.T) {
+		mark := len(recorder.Ended())
+		_, err := app.Test(httptest.NewRequest("POST", "/devices", nil))
+		require.NoError(t, err)
+
+		spans := ended(mark)
+		require.Len(t, spans, 1)
+		server := spans[0]
+		assert.Equal(t, "POST /devices", server.Name())
+		assert.False(t, server.Parent().IsValid())
+		assert.Equal(t, codes.Error, server.Status().Code)
+		assert.Equal(t, int64(500), attributeOf(server, "http.response.status_code").AsInt64())
+	})
+}
+
+func TestCommandMonitor(t *testing.T) {
+	mark := len(recorder.Ended())
+	monitor := tracing.CommandMonitor()
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "mongo.DeviceRepository.GetByID")
+
+	command, err := bson.Marshal(bson.D{{Key: "find", Value: "devices"}})
+	require.NoError(t, err)
+	monitor.Started(ctx, &event.CommandStartedEvent{Command: command, CommandName: "find", DatabaseName: "mrt", RequestID: 1})
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 1}})
+	monitor.Started(ctx, &event.CommandStartedEvent{Command: command, CommandName: "find", DatabaseName: "mrt", RequestID: 2})
+	monitor.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 2}, Failure: "connection reset"})
+	parent.End()
+
+	spans := ended(mark)
+	require.Len(t, spans, 3)
+	for _, span := range spans[:2] {
+		assert.Equal(t, "find devices", span.Name())
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+		assert.Equal(t, "devices", attributeOf(span, "db.mongodb.collection").AsString())
+		assert.Equal(t, "mrt", attributeOf(span, "db.name").AsString())
+	}
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "connection reset", spans[1].Status().Description)
+}
+
+func TestNewProvider(t *testing.T) {
+	t.Run("None", func(t *testing.T) {
+		provider, err := tracing.NewProvider(context.Background(), config.TracingConfig{})
+		assert.NoError(t, err)
+		assert.Nil(t, provider)
+	})
+	t.Run("Stdout", func(t *testing.T) {
+		provider, err := tracing.NewProvider(context.Background(), config.TracingConfig{Exporter: tracing.ExporterStdout, ServiceName: "mrt-api", SampleRatio: 1})
+		require.NoError(t, err)
+		assert.NoError(t, provider.Shutdown(context.Background()))
+	})
+	t.Run("Unknown", func(t *testing.T) {
+		_, err := tracing.NewProvider(context.Background(), config.TracingConfig{Exporter: "zipkin"})
+		assert.EqualError(t, err, `unknown trace exporter "zipkin"`)
+	})
+}
@@ -0,0 +1,60 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// DeviceRepositoryInterface is an autogenerated mock type for the DeviceRepositoryInterface type
+type DeviceRepositoryInterface struct {
+	mock.Mock
+}
+
+// GetBySiteID provides a mock function with given fields: ctx, siteID, page, limit
+func (_m *DeviceRepositoryInterface) GetBySiteID(ctx context.Context, siteID string, page int, limit int) ([]domain.Device, error) {
+	ret := _m.Called(ctx, siteID, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBySiteID")
+	}
+
+	var r0 []domain.Device
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) ([]domain.Device, error)); ok {
+		return rf(ctx, siteID, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) []domain.Device); ok {
+		r0 = rf(ctx, siteID, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Device)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, int) error); ok {
+		r1 = rf(ctx, siteID, page, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewDeviceRepositoryInterface creates a new instance of DeviceRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewDeviceRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *DeviceRepositoryInterface {
+	mock := &DeviceRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,90 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MeasurementRepositoryInterface is an autogenerated mock type for the MeasurementRepositoryInterface type
+type MeasurementRepositoryInterface struct {
+	mock.Mock
+}
+
+// GetAll provides a mock function with given fields: ctx, filter, page, limit
+func (_m *MeasurementRepositoryInterface) GetAll(ctx context.Context, filter domain.MeasurementFilter, page int, limit int) ([]domain.Measurement, error) {
+	ret := _m.Called(ctx, filter, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAll")
+	}
+
+	var r0 []domain.Measurement
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.MeasurementFilter, int, int) ([]domain.Measurement, error)); ok {
+		return rf(ctx, filter, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.MeasurementFilter, int, int) []domain.Measurement); ok {
+		r0 = rf(ctx, filter, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Measurement)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.MeasurementFilter, int, int) error); ok {
+		r1 = rf(ctx, filter, page, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetFirst provides a mock function with given fields: ctx, filter
+func (_m *MeasurementRepositoryInterface) GetFirst(ctx context.Context, filter domain.MeasurementFilter) (*domain.Measurement, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFirst")
+	}
+
+	var r0 *domain.Measurement
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.MeasurementFilter) (*domain.Measurement, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.MeasurementFilter) *domain.Measurement); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Measurement)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.MeasurementFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewMeasurementRepositoryInterface creates a new instance of MeasurementRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMeasurementRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MeasurementRepositoryInterface {
+	mock := &MeasurementRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
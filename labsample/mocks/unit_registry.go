@@ -0,0 +1,59 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+
+// UnitRegistryInterface is an autogenerated mock type for the UnitRegistryInterface type
+type UnitRegistryInterface struct {
+	mock.Mock
+}
+
+// ToCanonical provides a mock function with given fields: parameter, unit, value
+func (_m *UnitRegistryInterface) ToCanonical(parameter string, unit string, value float64) (float64, string, error) {
+	ret := _m.Called(parameter, unit, value)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ToCanonical")
+	}
+
+	var r0 float64
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(string, string, float64) (float64, string, error)); ok {
+		return rf(parameter, unit, value)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, float64) float64); ok {
+		r0 = rf(parameter, unit, value)
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, float64) string); ok {
+		r1 = rf(parameter, unit, value)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(string, string, float64) error); ok {
+		r2 = rf(parameter, unit, value)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// NewUnitRegistryInterface creates a new instance of UnitRegistryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUnitRegistryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UnitRegistryInterface {
+	mock := &UnitRegistryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,363 @@
+package labsample
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+)
+
+// tracer starts the spans of the service methods
+var tracer = otel.Tracer("github.com/anggi-susanto/mrt-go/labsample")
+
+// defaultMaxGap is the pairing window used when the correlation filter has none
+const defaultMaxGap = time.Hour
+
+// maxCorrelationSamples is the largest number of lab samples a correlation looks at
+const maxCorrelationSamples = 1000
+
+// maxSiteDevices is the largest number of devices of a site searched for readings
+const maxSiteDevices = 100
+
+// pairedQualities are the quality flags of the measurements a lab result may be paired with
+var pairedQualities = []string{domain.QualityRaw, domain.QualitySuspect, domain.QualityValidated, domain.QualityApproved}
+
+// sensorQualities are the quality flags of the measurements a sensor is checked against the lab with
+var sensorQualities = []string{domain.QualityRaw, domain.QualityValidated, domain.QualityApproved}
+
+// LabSampleRepositoryInterface is the interface that wraps the lab sample storage methods.
+type LabSampleRepositoryInterface interface {
+	Create(ctx context.Context, w *domain.LabSampleRequest) error
+	GetAll(ctx context.Context, filter domain.LabSampleFilter, page, limit int) ([]domain.LabSample, error)
+	GetByID(ctx context.Context, id string) (*domain.LabSample, error)
+	Update(ctx context.Context, w *domain.LabSample) error
+	Delete(ctx context.Context, id string) error
+}
+
+// DeviceRepositoryInterface is the interface that wraps the GetBySiteID method.
+type DeviceRepositoryInterface interface {
+	GetBySiteID(ctx context.Context, siteID string, page, limit int) ([]domain.Device, error)
+}
+
+// MeasurementRepositoryInterface is the interface that wraps the GetAll and GetFirst methods of the measurement store.
+type MeasurementRepositoryInterface interface {
+	GetAll(ctx context.Context, filter domain.MeasurementFilter, page, limit int) ([]domain.Measurement, error)
+	GetFirst(ctx context.Context, filter domain.MeasurementFilter) (*domain.Measurement, error)
+}
+
+// UnitRegistryInterface is the interface that wraps the conversion of lab results to canonical units.
+type UnitRegistryInterface interface {
+	ToCanonical(parameter, unit string, value float64) (float64, string, error)
+}
+
+// Service is the interface that wraps the lab sample methods and the lab-vs-sensor correlation.
+type Service struct {
+	labSampleRepository   LabSampleRepositoryInterface
+	deviceRepository      DeviceRepositoryInterface
+	measurementRepository MeasurementRepositoryInterface
+	unitRegistry          UnitRegistryInterface
+}
+
+// NewService creates a new instance of the Service struct, initializing it with the provided repositories.
+//
+// Parameters:
+// - labSampleRepository: The LabSampleRepositoryInterface implementation used by the Service.
+// - deviceRepository: The DeviceRepositoryInterface implementation used to find the devices of a site.
+// - measurementRepository: The MeasurementRepositoryInterface implementation holding the sensor readings.
+// - unitRegistry: The UnitRegistryInterface implementation converting lab results to the unit of the readings.
+//
+// Returns:
+// - A pointer to the newly created Service instance.
+func NewService(labSampleRepository LabSampleRepositoryInterface, deviceRepository DeviceRepositoryInterface, measurementRepository MeasurementRepositoryInterface, unitRegistry UnitRegistryInterface) *Service {
+	return &Service{
+		labSampleRepository:   labSampleRepository,
+		deviceRepository:      deviceRepository,
+		measurementRepository: measurementRepository,
+		unitRegistry:          unitRegistry,
+	}
+}
+
+// Create creates a new lab sample.
+//
+// ctx: The context.Context object for the request.
+// w: The lab sample to be created.
+// Returns an error if the lab sample is invalid or there was a problem creating the record.
+func (s *Service) Create(ctx context.Context, w *domain.LabSampleRequest) error {
+	ctx, span := tracer.Start(ctx, "labsample.Service.Create")
+	defer span.End()
+	if err := w.Validate(); err != nil {
+		return err
+	}
+	return s.labSampleRepository.Create(ctx, w)
+}
+
+// GetAll retrieves the lab samples matching the filter with pagination.
+//
+// ctx context.Context, filter domain.LabSampleFilter, page int, limit int
+// []domain.LabSample, error
+func (s *Service) GetAll(ctx context.Context, filter domain.LabSampleFilter, page, limit int) ([]domain.LabSample, error) {
+	ctx, span := tracer.Start(ctx, "labsample.Service.GetAll")
+	defer span.End()
+	return s.labSampleRepository.GetAll(ctx, filter, page, limit)
+}
+
+// GetByID retrieves a LabSample by ID.
+//
+// ctx - context.Context for the operation.
+// id - string representing the ID of the lab sample.
+// Returns a pointer to domain.LabSample and an error.
+func (s *Service) GetByID(ctx context.Context, id string) (*domain.LabSample, error) {
+	ctx, span := tracer.Start(ctx, "labsample.Service.GetByID")
+	defer span.End()
+	return s.labSampleRepository.GetByID(ctx, id)
+}
+
+// Delete deletes a LabSample by ID.
+//
+// ctx - context.Context for the operation.
+// id - string representing the ID of the lab sample to be deleted.
+// Returns an error if there was a problem deleting the lab sample.
+func (s *Service) Delete(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "labsample.Service.Delete")
+	defer span.End()
+	return s.labSampleRepository.Delete(ctx, id)
+}
+
+// Update updates a LabSample.
+//
+// ctx - context.Context for the operation.
+// w - pointer to domain.LabSample representing the lab sample to be updated.
+// Returns an error if the lab sample is invalid or there was a problem updating it.
+func (s *Service) Update(ctx context.Context, w *domain.LabSample) error {
+	ctx, span := tracer.Start(ctx, "labsample.Service.Update")
+	defer span.End()
+	if err := w.Validate(); err != nil {
+		return err
+	}
+	return s.labSampleRepository.Update(ctx, w)
+}
+
+// Correlate pairs the lab results of a site with the nearest sensor readings and reports the
+// bias and regression statistics per parameter.
+//
+// Rejected samples and invalid measurements are left out. A result is paired with the nearest
+// measurement of the sampled device, or of any device of the site when the sample has none,
+// within MaxGap of the sampling time.
+//
+// ctx - context.Context for the operation.
+// filter - the site, parameter, period and pairing window of the correlation.
+// Returns the statistics ordered by parameter and an error.
+func (s *Service) Correlate(ctx context.Context, filter domain.LabCorrelationFilter) ([]domain.LabCorrelation, error) {
+	ctx, span := tracer.Start(ctx, "labsample.Service.Correlate")
+	defer span.End()
+	if filter.SiteID.IsZero() {
+		return nil, fmt.Errorf("%w: site_id is required", domain.ErrInvalidLabSample)
+	}
+	if filter.MaxGap <= 0 {
+		filter.MaxGap = defaultMaxGap
+	}
+	samples, err := s.labSampleRepository.GetAll(ctx, domain.LabSampleFilter{SiteID: filter.SiteID, From: filter.From, To: filter.To}, 1, maxCorrelationSamples)
+	if err != nil {
+		return nil, err
+	}
+	devices, err := s.deviceRepository.GetBySiteID(ctx, filter.SiteID.Hex(), 1, maxSiteDevices)
+	if err != nil {
+		return nil, err
+	}
+	siteDevices := make([]primitive.ObjectID, 0, len(devices))
+	for _, d := range devices {
+		siteDevices = append(siteDevices, d.ID)
+	}
+
+	pairs := map[string][]domain.LabSensorPair{}
+	units := map[string]string{}
+	for _, sample := range samples {
+		if sample.CustodyStatus == domain.CustodyRejected {
+			continue
+		}
+		devices := siteDevices
+		if !sample.DeviceID.IsZero() {
+			devices = []primitive.ObjectID{sample.DeviceID}
+		}
+		for _, result := range sample.Results {
+			if filter.Parameter != "" && result.Parameter != filter.Parameter {
+				continue
+			}
+			value, unit, err := s.unitRegistry.ToCanonical(result.Parameter, result.Unit, result.Value)
+			if err != nil {
+				return nil, err
+			}
+			candidates := make([]domain.MeasurementFilter, 0, len(devices))
+			for _, deviceID := range devices {
+				candidates = append(candidates, domain.MeasurementFilter{DeviceID: deviceID, Parameter: result.Parameter, Quality: pairedQualities})
+			}
+			nearest, ok, err := s.nearest(ctx, candidates, sample.SampledAt, filter.MaxGap)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			if units[result.Parameter] == "" {
+				units[result.Parameter] = unit
+			}
+			pairs[result.Parameter] = append(pairs[result.Parameter], domain.LabSensorPair{
+				SampleID:      sample.SampleID,
+				SampledAt:     sample.SampledAt,
+				MeasurementID: nearest.ID,
+				DeviceID:      nearest.DeviceID,
+				MeasuredAt:    nearest.Timestamp,
+				LabValue:      value,
+				SensorValue:   nearest.Value,
+			})
+		}
+	}
+
+	correlations := make([]domain.LabCorrelation, 0, len(pairs))
+	for parameter, p := range pairs {
+		correlations = append(correlations, correlate(parameter, units[parameter], p))
+	}
+	sort.Slice(correlations, func(i, j int) bool { return correlations[i].Parameter < correlations[j].Parameter })
+	return correlations, nil
+}
+
+// SensorPairs pairs the results of the latest lab samples taken at the device of a sensor with the
+// nearest measurement of the sensor within the default pairing window.
+//
+// Rejected samples and suspect measurements are left out.
+//
+// ctx - context.Context for the operation.
+// sensorID - the sensor the measurements are taken from.
+// deviceID - the device the samples were taken at.
+// limit - the number of latest samples to pair.
+// Returns the pairs by parameter, oldest first, and an error.
+func (s *Service) SensorPairs(ctx context.Context, sensorID, deviceID primitive.ObjectID, limit int) (map[string][]domain.LabSensorPair, error) {
+	ctx, span := tracer.Start(ctx, "labsample.Service.SensorPairs")
+	defer span.End()
+	samples, err := s.labSampleRepository.GetAll(ctx, domain.LabSampleFilter{DeviceID: deviceID}, 1, limit)
+	if err != nil {
+		return nil, err
+	}
+	pairs := map[string][]domain.LabSensorPair{}
+	// The samples are newest first
+	for i := len(samples) - 1; i >= 0; i-- {
+		sample := samples[i]
+		if sample.CustodyStatus == domain.CustodyRejected {
+			continue
+		}
+		for _, result := range sample.Results {
+			value, _, err := s.unitRegistry.ToCanonical(result.Parameter, result.Unit, result.Value)
+			if err != nil {
+				return nil, err
+			}
+			candidate := domain.MeasurementFilter{SensorID: sensorID, Parameter: result.Parameter, Quality: sensorQualities}
+			nearest, ok, err := s.nearest(ctx, []domain.MeasurementFilter{candidate}, sample.SampledAt, defaultMaxGap)
+			if err != nil {
+				return nil, err
+			}
+			if !ok || nearest.Maintenance {
+				continue
+			}
+			pairs[result.Parameter] = append(pairs[result.Parameter], domain.LabSensorPair{
+				SampleID:      sample.SampleID,
+				SampledAt:     sample.SampledAt,
+				MeasurementID: nearest.ID,
+				DeviceID:      nearest.DeviceID,
+				MeasuredAt:    nearest.Timestamp,
+				LabValue:      value,
+				SensorValue:   nearest.Value,
+			})
+		}
+	}
+	return pairs, nil
+}
+
+// nearest returns the measurement taken closest to the sampling time matching one of the candidate filters.
+//
+// For each candidate only the latest measurement at or before the sampling time and the earliest at
+// or after it are read, the nearest one is always one of them.
+func (s *Service) nearest(ctx context.Context, candidates []domain.MeasurementFilter, sampledAt time.Time, maxGap time.Duration) (domain.Measurement, bool, error) {
+	var nearest domain.Measurement
+	found := false
+	consider := func(m domain.Measurement) {
+		if !found || gap(m.Timestamp, sampledAt) < gap(nearest.Timestamp, sampledAt) {
+			nearest = m
+			found = true
+		}
+	}
+	for _, filter := range candidates {
+		filter.From = sampledAt.Add(-maxGap)
+		filter.To = sampledAt
+		before, err := s.measurementRepository.GetAll(ctx, filter, 1, 1)
+		if err != nil {
+			return domain.Measurement{}, false, err
+		}
+		for _, m := range before {
+			consider(m)
+		}
+
+		filter.From = sampledAt
+		filter.To = sampledAt.Add(maxGap)
+		after, err := s.measurementRepository.GetFirst(ctx, filter)
+		if err != nil {
+			return domain.Measurement{}, false, err
+		}
+		if after != nil {
+			consider(*after)
+		}
+	}
+	return nearest, found, nil
+}
+
+// gap returns the absolute time between two instants.
+func gap(a, b time.Time) time.Duration {
+	d := a.Sub(b)
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// correlate computes the bias and the least squares fit of the sensor values on the lab values.
+func correlate(parameter, unit string, pairs []domain.LabSensorPair) domain.LabCorrelation {
+	c := domain.LabCorrelation{Parameter: parameter, Unit: unit, Count: len(pairs), Pairs: pairs}
+	n := float64(len(pairs))
+	var sumLab, sumSensor, sumDiff, sumSquaredDiff float64
+	for _, p := range pairs {
+		diff := p.SensorValue - p.LabValue
+		sumLab += p.LabValue
+		sumSensor += p.SensorValue
+		sumDiff += diff
+		sumSquaredDiff += diff * diff
+	}
+	meanLab := sumLab / n
+	meanSensor := sumSensor / n
+	c.Bias = sumDiff / n
+	c.RMSE = math.Sqrt(sumSquaredDiff / n)
+	if meanLab != 0 {
+		c.RelativeBias = c.Bias / meanLab * 100
+	}
+
+	var sxx, syy, sxy float64
+	for _, p := range pairs {
+		dx := p.LabValue - meanLab
+		dy := p.SensorValue - meanSensor
+		sxx += dx * dx
+		syy += dy * dy
+		sxy += dx * dy
+	}
+	if sxx == 0 {
+		return c
+	}
+	c.Slope = sxy / sxx
+	c.Intercept = meanSensor - c.Slope*meanLab
+	if syy != 0 {
+		c.R2 = sxy * sxy / (sxx * syy)
+	}
+	return c
+}
@@ -0,0 +1,237 @@
+package labsample_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/labsample"
+	"github.com/anggi-susanto/mrt-go/labsample/mocks"
+	"github.com/anggi-susanto/mrt-go/units"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newService(repo *mocks.LabSampleRepositoryInterface) *labsample.Service {
+	return labsample.NewService(repo, new(mocks.DeviceRepositoryInterface), new(mocks.MeasurementRepositoryInterface), units.Default)
+}
+
+func TestServiceCreate(t *testing.T) {
+	sampledAt := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	t.Run("Success", func(t *testing.T) {
+		sample := domain.LabSampleRequest{SampleID: "LAB-1", SiteID: primitive.NewObjectID(), SampledAt: sampledAt}
+		mockRepo := new(mocks.LabSampleRepositoryInterface)
+		mockRepo.On("Create", mock.Anything, &sample).Return(nil)
+		err := newService(mockRepo).Create(context.Background(), &sample)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.CustodyCollected, sample.CustodyStatus)
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		for name, sample := range map[string]domain.LabSampleRequest{
+			"No sample ID":    {SiteID: primitive.NewObjectID(), SampledAt: sampledAt},
+			"No site":         {SampleID: "LAB-1", SampledAt: sampledAt},
+			"No sampled at":   {SampleID: "LAB-1", SiteID: primitive.NewObjectID()},
+			"Analysed before": {SampleID: "LAB-1", SiteID: primitive.NewObjectID(), SampledAt: sampledAt, AnalysedAt: sampledAt.Add(-time.Hour)},
+			"Unknown custody": {SampleID: "LAB-1", SiteID: primitive.NewObjectID(), SampledAt: sampledAt, CustodyStatus: "lost"},
+			"No parameter":    {SampleID: "LAB-1", SiteID: primitive.NewObjectID(), SampledAt: sampledAt, Results: []domain.LabResult{{Value: 1}}},
+		} {
+			t.Run(name, func(t *testing.T) {
+				err := newService(new(mocks.LabSampleRepositoryInterface)).Create(context.Background(), &sample)
+				assert.ErrorIs(t, err, domain.ErrInvalidLabSample)
+			})
+		}
+	})
+	t.Run("Error", func(t *testing.T) {
+		sample := domain.LabSampleRequest{SampleID: "LAB-1", SiteID: primitive.NewObjectID(), SampledAt: sampledAt}
+		mockRepo := new(mocks.LabSampleRepositoryInterface)
+		mockRepo.On("Create", mock.Anything, &sample).Return(errors.New("error"))
+		err := newService(mockRepo).Create(context.Background(), &sample)
+		assert.Error(t, err)
+	})
+}
+
+func TestServiceUpdate(t *testing.T) {
+	sample := domain.LabSample{ID: primitive.NewObjectID(), SampleID: "LAB-1", SiteID: primitive.NewObjectID(), SampledAt: time.Now(), CustodyStatus: domain.CustodyReported}
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.LabSampleRepositoryInterface)
+		mockRepo.On("Update", mock.Anything, &sample).Return(nil)
+		assert.NoError(t, newService(mockRepo).Update(context.Background(), &sample))
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		invalid := sample
+		invalid.SampleID = ""
+		err := newService(new(mocks.LabSampleRepositoryInterface)).Update(context.Background(), &invalid)
+		assert.ErrorIs(t, err, domain.ErrInvalidLabSample)
+	})
+}
+
+func TestServiceGetAll(t *testing.T) {
+	filter := domain.LabSampleFilter{SiteID: primitive.NewObjectID()}
+	mockRepo := new(mocks.LabSampleRepositoryInterface)
+	mockRepo.On("GetAll", mock.Anything, filter, 1, 10).Return([]domain.LabSample{{SampleID: "LAB-1"}}, nil)
+	samples, err := newService(mockRepo).GetAll(context.Background(), filter, 1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, samples, 1)
+}
+
+func TestServiceGetByIDAndDelete(t *testing.T) {
+	mockRepo := new(mocks.LabSampleRepositoryInterface)
+	mockRepo.On("GetByID", mock.Anything, "1").Return(&domain.LabSample{SampleID: "LAB-1"}, nil)
+	mockRepo.On("Delete", mock.Anything, "1").Return(nil)
+	s := newService(mockRepo)
+	sample, err := s.GetByID(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "LAB-1", sample.SampleID)
+	assert.NoError(t, s.Delete(context.Background(), "1"))
+}
+
+func TestServiceCorrelate(t *testing.T) {
+	siteID := primitive.NewObjectID()
+	deviceID := primitive.NewObjectID()
+	otherDeviceID := primitive.NewObjectID()
+	base := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	// sensor BOD reads 2 mg/L above the lab, the lab reports BOD in µg/L
+	labValues := []float64{10, 20, 30, 40}
+	var samples []domain.LabSample
+	for i, v := range labValues {
+		samples = append(samples, domain.LabSample{
+			SampleID:  "LAB-" + string(rune('A'+i)),
+			SiteID:    siteID,
+			SampledAt: base.Add(time.Duration(i) * 24 * time.Hour),
+			Results:   []domain.LabResult{{Parameter: domain.ParamBOD, Value: v * 1000, Unit: "µg/L"}, {Parameter: domain.ParamCOD, Value: 3 * v, Unit: "mg/L"}},
+		})
+	}
+	samples = append(samples, domain.LabSample{SampleID: "LAB-X", SiteID: siteID, SampledAt: base, CustodyStatus: domain.CustodyRejected,
+		Results: []domain.LabResult{{Parameter: domain.ParamBOD, Value: 1000}}})
+
+	setup := func() (*mocks.LabSampleRepositoryInterface, *mocks.DeviceRepositoryInterface, *mocks.MeasurementRepositoryInterface) {
+		mockRepo := new(mocks.LabSampleRepositoryInterface)
+		mockRepo.On("GetAll", mock.Anything, domain.LabSampleFilter{SiteID: siteID}, 1, mock.Anything).Return(samples, nil)
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetBySiteID", mock.Anything, siteID.Hex(), 1, mock.Anything).Return([]domain.Device{{ID: deviceID}, {ID: otherDeviceID}}, nil)
+		mockMeasurementRepo := new(mocks.MeasurementRepositoryInterface)
+		for i, v := range labValues {
+			sampledAt := base.Add(time.Duration(i) * 24 * time.Hour)
+			window := func(device primitive.ObjectID, parameter string, from, to time.Time) domain.MeasurementFilter {
+				return domain.MeasurementFilter{
+					DeviceID:  device,
+					Parameter: parameter,
+					Quality:   []string{domain.QualityRaw, domain.QualitySuspect, domain.QualityValidated, domain.QualityApproved},
+					From:      from,
+					To:        to,
+				}
+			}
+			before := func(device primitive.ObjectID, parameter string) domain.MeasurementFilter {
+				return window(device, parameter, sampledAt.Add(-30*time.Minute), sampledAt)
+			}
+			after := func(device primitive.ObjectID, parameter string) domain.MeasurementFilter {
+				return window(device, parameter, sampledAt, sampledAt.Add(30*time.Minute))
+			}
+			mockMeasurementRepo.On("GetAll", mock.Anything, before(deviceID, domain.ParamBOD), 1, 1).Return([]domain.Measurement{
+				{DeviceID: deviceID, Parameter: domain.ParamBOD, Value: v + 50, Timestamp: sampledAt.Add(-20 * time.Minute)},
+			}, nil)
+			mockMeasurementRepo.On("GetFirst", mock.Anything, after(deviceID, domain.ParamBOD)).Return(
+				&domain.Measurement{DeviceID: deviceID, Parameter: domain.ParamBOD, Value: v + 2, Timestamp: sampledAt.Add(5 * time.Minute)}, nil)
+			mockMeasurementRepo.On("GetAll", mock.Anything, before(otherDeviceID, domain.ParamBOD), 1, 1).Return(nil, nil)
+			mockMeasurementRepo.On("GetFirst", mock.Anything, after(otherDeviceID, domain.ParamBOD)).Return(
+				&domain.Measurement{DeviceID: otherDeviceID, Parameter: domain.ParamBOD, Value: v + 80, Timestamp: sampledAt.Add(10 * time.Minute)}, nil)
+			for _, device := range []primitive.ObjectID{deviceID, otherDeviceID} {
+				mockMeasurementRepo.On("GetAll", mock.Anything, before(device, domain.ParamCOD), 1, 1).Return(nil, nil)
+				mockMeasurementRepo.On("GetFirst", mock.Anything, after(device, domain.ParamCOD)).Return(nil, nil)
+			}
+		}
+		return mockRepo, mockDeviceRepo, mockMeasurementRepo
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo, mockDeviceRepo, mockMeasurementRepo := setup()
+		s := labsample.NewService(mockRepo, mockDeviceRepo, mockMeasurementRepo, units.Default)
+		correlations, err := s.Correlate(context.Background(), domain.LabCorrelationFilter{SiteID: siteID, MaxGap: 30 * time.Minute})
+		assert.NoError(t, err)
+		if assert.Len(t, correlations, 1) {
+			c := correlations[0]
+			assert.Equal(t, domain.ParamBOD, c.Parameter)
+			assert.Equal(t, "mg/L", c.Unit)
+			assert.Equal(t, 4, c.Count)
+			assert.InDelta(t, 2, c.Bias, 1e-9)
+			assert.InDelta(t, 8, c.RelativeBias, 1e-9)
+			assert.InDelta(t, 2, c.RMSE, 1e-9)
+			assert.InDelta(t, 1, c.Slope, 1e-9)
+			assert.InDelta(t, 2, c.Intercept, 1e-9)
+			assert.InDelta(t, 1, c.R2, 1e-9)
+			assert.Equal(t, deviceID, c.Pairs[0].DeviceID)
+		}
+	})
+	t.Run("Parameter filter", func(t *testing.T) {
+		mockRepo, mockDeviceRepo, mockMeasurementRepo := setup()
+		s := labsample.NewService(mockRepo, mockDeviceRepo, mockMeasurementRepo, units.Default)
+		correlations, err := s.Correlate(context.Background(), domain.LabCorrelationFilter{SiteID: siteID, Parameter: domain.ParamCOD, MaxGap: 30 * time.Minute})
+		assert.NoError(t, err)
+		assert.Empty(t, correlations)
+	})
+	t.Run("No site", func(t *testing.T) {
+		_, err := newService(new(mocks.LabSampleRepositoryInterface)).Correlate(context.Background(), domain.LabCorrelationFilter{})
+		assert.ErrorIs(t, err, domain.ErrInvalidLabSample)
+	})
+	t.Run("Error", func(t *testing.T) {
+		mockRepo := new(mocks.LabSampleRepositoryInterface)
+		mockRepo.On("GetAll", mock.Anything, mock.Anything, 1, mock.Anything).Return(nil, errors.New("error"))
+		_, err := newService(mockRepo).Correlate(context.Background(), domain.LabCorrelationFilter{SiteID: siteID})
+		assert.Error(t, err)
+	})
+}
+
+func TestServiceSensorPairs(t *testing.T) {
+	sensorID := primitive.NewObjectID()
+	deviceID := primitive.NewObjectID()
+	base := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	// the samples are newest first
+	samples := []domain.LabSample{
+		{SampleID: "LAB-C", DeviceID: deviceID, SampledAt: base.Add(48 * time.Hour), Results: []domain.LabResult{{Parameter: domain.ParamCOD, Value: 90, Unit: "mg/L"}}},
+		{SampleID: "LAB-X", DeviceID: deviceID, SampledAt: base.Add(24 * time.Hour), CustodyStatus: domain.CustodyRejected, Results: []domain.LabResult{{Parameter: domain.ParamCOD, Value: 1}}},
+		{SampleID: "LAB-A", DeviceID: deviceID, SampledAt: base, Results: []domain.LabResult{{Parameter: domain.ParamCOD, Value: 100000, Unit: "µg/L"}}},
+	}
+	window := func(from, to time.Time) domain.MeasurementFilter {
+		return domain.MeasurementFilter{
+			SensorID:  sensorID,
+			Parameter: domain.ParamCOD,
+			Quality:   []string{domain.QualityRaw, domain.QualityValidated, domain.QualityApproved},
+			From:      from,
+			To:        to,
+		}
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.LabSampleRepositoryInterface)
+		mockRepo.On("GetAll", mock.Anything, domain.LabSampleFilter{DeviceID: deviceID}, 1, 12).Return(samples, nil)
+		mockMeasurementRepo := new(mocks.MeasurementRepositoryInterface)
+		mockMeasurementRepo.On("GetAll", mock.Anything, window(base.Add(-time.Hour), base), 1, 1).Return([]domain.Measurement{
+			{DeviceID: deviceID, SensorID: sensorID, Parameter: domain.ParamCOD, Value: 102, Timestamp: base.Add(-5 * time.Minute)},
+		}, nil)
+		mockMeasurementRepo.On("GetFirst", mock.Anything, window(base, base.Add(time.Hour))).Return(nil, nil)
+		last := base.Add(48 * time.Hour)
+		mockMeasurementRepo.On("GetAll", mock.Anything, window(last.Add(-time.Hour), last), 1, 1).Return([]domain.Measurement{
+			{DeviceID: deviceID, SensorID: sensorID, Parameter: domain.ParamCOD, Value: 0, Maintenance: true, Timestamp: last},
+		}, nil)
+		mockMeasurementRepo.On("GetFirst", mock.Anything, window(last, last.Add(time.Hour))).Return(nil, nil)
+		s := labsample.NewService(mockRepo, new(mocks.DeviceRepositoryInterface), mockMeasurementRepo, units.Default)
+
+		pairs, err := s.SensorPairs(context.Background(), sensorID, deviceID, 12)
+		assert.NoError(t, err)
+		if assert.Len(t, pairs[domain.ParamCOD], 1) {
+			pair := pairs[domain.ParamCOD][0]
+			assert.Equal(t, "LAB-A", pair.SampleID)
+			assert.InDelta(t, 100, pair.LabValue, 1e-9)
+			assert.Equal(t, float64(102), pair.SensorValue)
+		}
+	})
+	t.Run("Error", func(t *testing.T) {
+		mockRepo := new(mocks.LabSampleRepositoryInterface)
+		mockRepo.On("GetAll", mock.Anything, mock.Anything, 1, mock.Anything).Return(nil, errors.New("error"))
+		_, err := newService(mockRepo).SensorPairs(context.Background(), sensorID, deviceID, 12)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,162 @@
+package lorawan
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+)
+
+// lppType describes the data of a Cayenne LPP type
+type lppType struct {
+	size int
+	// resolution of each value, one per axis
+	resolutions []float64
+	signed      bool
+	suffixes    []string
+}
+
+// lppTypes are the Cayenne LPP types keyed by their code
+var lppTypes = map[byte]lppType{
+	// digital input
+	0: {size: 1, resolutions: []float64{1}},
+	// digital output
+	1: {size: 1, resolutions: []float64{1}},
+	// analog input
+	2: {size: 2, resolutions: []float64{0.01}, signed: true},
+	// analog output
+	3: {size: 2, resolutions: []float64{0.01}, signed: true},
+	// illuminance
+	101: {size: 2, resolutions: []float64{1}},
+	// presence
+	102: {size: 1, resolutions: []float64{1}},
+	// temperature
+	103: {size: 2, resolutions: []float64{0.1}, signed: true},
+	// humidity
+	104: {size: 1, resolutions: []float64{0.5}},
+	// accelerometer
+	113: {size: 6, resolutions: []float64{0.001, 0.001, 0.001}, signed: true, suffixes: []string{"_x", "_y", "_z"}},
+	// barometer
+	115: {size: 2, resolutions: []float64{0.1}},
+	// gyrometer
+	134: {size: 6, resolutions: []float64{0.01, 0.01, 0.01}, signed: true, suffixes: []string{"_x", "_y", "_z"}},
+	// GPS
+	136: {size: 9, resolutions: []float64{0.0001, 0.0001, 0.01}, signed: true, suffixes: []string{"_lat", "_lng", "_alt"}},
+}
+
+// Decode decodes the payload of an uplink with the codec of the device model.
+//
+// Parameters:
+// - codec: the codec of the device model.
+// - payload: the application payload of the uplink.
+// Returns the values keyed by parameter and an error wrapping domain.ErrInvalidLoRaWAN when the
+// payload does not match the codec.
+func Decode(codec domain.PayloadCodec, payload []byte) (map[string]float64, error) {
+	switch codec.Type {
+	case domain.CodecCayenneLPP:
+		return decodeLPP(codec.Channels, payload)
+	case domain.CodecByteLayout:
+		return decodeByteLayout(codec.Fields, payload)
+	}
+	return nil, fmt.Errorf("%w: unknown codec type %q", domain.ErrInvalidLoRaWAN, codec.Type)
+}
+
+// decodeLPP decodes the channel, type and data frames of a Cayenne LPP payload, unmapped channels are skipped.
+func decodeLPP(channels []domain.LPPChannel, payload []byte) (map[string]float64, error) {
+	parameters := make(map[uint8]string, len(channels))
+	for _, c := range channels {
+		parameters[c.Channel] = c.Parameter
+	}
+	values := map[string]float64{}
+	for i := 0; i < len(payload); {
+		if i+2 > len(payload) {
+			return nil, fmt.Errorf("%w: truncated cayenne lpp frame at byte %d", domain.ErrInvalidLoRaWAN, i)
+		}
+		channel, code := payload[i], payload[i+1]
+		t, ok := lppTypes[code]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown cayenne lpp type %d on channel %d", domain.ErrInvalidLoRaWAN, code, channel)
+		}
+		data := payload[i+2:]
+		if len(data) < t.size {
+			return nil, fmt.Errorf("%w: truncated cayenne lpp value on channel %d", domain.ErrInvalidLoRaWAN, channel)
+		}
+		i += 2 + t.size
+
+		parameter, ok := parameters[channel]
+		if !ok {
+			continue
+		}
+		width := t.size / len(t.resolutions)
+		for axis, resolution := range t.resolutions {
+			value := readInt(data[axis*width:(axis+1)*width], t.signed) * resolution
+			name := parameter
+			if t.suffixes != nil {
+				name += t.suffixes[axis]
+			}
+			values[name] = value
+		}
+	}
+	return values, nil
+}
+
+// readInt reads a big-endian integer of 1 to 4 bytes.
+func readInt(data []byte, signed bool) float64 {
+	var raw uint32
+	for _, b := range data {
+		raw = raw<<8 | uint32(b)
+	}
+	if !signed {
+		return float64(raw)
+	}
+	// Sign-extend from the width of the data
+	shift := 32 - 8*uint(len(data))
+	return float64(int32(raw<<shift) >> shift)
+}
+
+// decodeByteLayout decodes the fields at fixed positions of the payload.
+func decodeByteLayout(fields []domain.ByteField, payload []byte) (map[string]float64, error) {
+	values := map[string]float64{}
+	for _, f := range fields {
+		size := f.Size()
+		if size == 0 {
+			return nil, fmt.Errorf("%w: %s has an unknown data type %q", domain.ErrInvalidLoRaWAN, f.Parameter, f.DataType)
+		}
+		if f.Start < 0 || f.Start+size > len(payload) {
+			return nil, fmt.Errorf("%w: %s is beyond the %d bytes of the payload", domain.ErrInvalidLoRaWAN, f.Parameter, len(payload))
+		}
+		data := payload[f.Start : f.Start+size]
+		var order binary.ByteOrder = binary.BigEndian
+		if f.ByteOrder == domain.LittleEndian {
+			order = binary.LittleEndian
+		}
+
+		var raw float64
+		switch f.DataType {
+		case domain.ByteUint8:
+			raw = float64(data[0])
+		case domain.ByteInt8:
+			raw = float64(int8(data[0]))
+		case domain.ByteUint16:
+			raw = float64(order.Uint16(data))
+		case domain.ByteInt16:
+			raw = float64(int16(order.Uint16(data)))
+		case domain.ByteUint32:
+			raw = float64(order.Uint32(data))
+		case domain.ByteInt32:
+			raw = float64(int32(order.Uint32(data)))
+		case domain.ByteFloat32:
+			raw = float64(math.Float32frombits(order.Uint32(data)))
+			if math.IsNaN(raw) || math.IsInf(raw, 0) {
+				return nil, fmt.Errorf("%w: %s is %v", domain.ErrInvalidLoRaWAN, f.Parameter, raw)
+			}
+		}
+		scale := f.Scale
+		if scale == 0 {
+			scale = 1
+		}
+		values[f.Parameter] = raw*scale + f.Offset
+	}
+	return values, nil
+}
@@ -0,0 +1,74 @@
+package lorawan_test
+
+import (
+	"testing"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/lorawan"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode(t *testing.T) {
+	t.Run("Cayenne LPP", func(t *testing.T) {
+		codec := domain.PayloadCodec{Type: domain.CodecCayenneLPP, Channels: []domain.LPPChannel{
+			{Channel: 1, Parameter: "Temperature"},
+			{Channel: 2, Parameter: domain.ParamPH},
+			{Channel: 3, Parameter: "Tilt"},
+		}}
+		payload := []byte{
+			// channel 1 temperature -4.1
+			0x01, 0x67, 0xFF, 0xD7,
+			// channel 2 analog input 7.12
+			0x02, 0x02, 0x02, 0xC8,
+			// channel 3 accelerometer 1, -1, 0
+			0x03, 0x71, 0x03, 0xE8, 0xFC, 0x18, 0x00, 0x00,
+			// channel 9 humidity is not mapped
+			0x09, 0x68, 0x50,
+		}
+		values, err := lorawan.Decode(codec, payload)
+		assert.NoError(t, err)
+		assert.InDelta(t, -4.1, values["Temperature"], 1e-9)
+		assert.InDelta(t, 7.12, values[domain.ParamPH], 1e-9)
+		assert.InDelta(t, 1, values["Tilt_x"], 1e-9)
+		assert.InDelta(t, -1, values["Tilt_y"], 1e-9)
+		assert.Len(t, values, 5)
+	})
+
+	t.Run("Cayenne LPP truncated", func(t *testing.T) {
+		codec := domain.PayloadCodec{Type: domain.CodecCayenneLPP, Channels: []domain.LPPChannel{{Channel: 1, Parameter: "Temperature"}}}
+		_, err := lorawan.Decode(codec, []byte{0x01, 0x67, 0xFF})
+		assert.ErrorIs(t, err, domain.ErrInvalidLoRaWAN)
+	})
+
+	t.Run("Cayenne LPP unknown type", func(t *testing.T) {
+		codec := domain.PayloadCodec{Type: domain.CodecCayenneLPP}
+		_, err := lorawan.Decode(codec, []byte{0x01, 0x10, 0x00})
+		assert.ErrorIs(t, err, domain.ErrInvalidLoRaWAN)
+	})
+
+	t.Run("Byte layout", func(t *testing.T) {
+		codec := domain.PayloadCodec{Type: domain.CodecByteLayout, Fields: []domain.ByteField{
+			{Parameter: domain.ParamPH, Start: 0, DataType: domain.ByteUint16, Scale: 0.01},
+			{Parameter: domain.ParamCOD, Start: 2, DataType: domain.ByteInt16, ByteOrder: domain.LittleEndian},
+			{Parameter: "Level", Start: 4, DataType: domain.ByteUint8, Scale: 0.1, Offset: -2},
+		}}
+		values, err := lorawan.Decode(codec, []byte{0x02, 0xC8, 0x55, 0x00, 0x32})
+		assert.NoError(t, err)
+		assert.InDelta(t, 7.12, values[domain.ParamPH], 1e-9)
+		assert.InDelta(t, 85, values[domain.ParamCOD], 1e-9)
+		assert.InDelta(t, 3, values["Level"], 1e-9)
+	})
+
+	t.Run("Byte layout beyond payload", func(t *testing.T) {
+		codec := domain.PayloadCodec{Type: domain.CodecByteLayout, Fields: []domain.ByteField{
+			{Parameter: domain.ParamPH, Start: 1, DataType: domain.ByteUint16},
+		}}
+		_, err := lorawan.Decode(codec, []byte{0x02, 0xC8})
+		assert.ErrorIs(t, err, domain.ErrInvalidLoRaWAN)
+	})
+
+	t.Run("Unknown codec type", func(t *testing.T) {
+		_, err := lorawan.Decode(domain.PayloadCodec{Type: "javascript"}, []byte{0x00})
+		assert.ErrorIs(t, err, domain.ErrInvalidLoRaWAN)
+	})
+}
@@ -0,0 +1,108 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// CodecRepositoryInterface is an autogenerated mock type for the CodecRepositoryInterface type
+type CodecRepositoryInterface struct {
+	mock.Mock
+}
+
+// GetAll provides a mock function with given fields: ctx
+func (_m *CodecRepositoryInterface) GetAll(ctx context.Context) ([]domain.PayloadCodec, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAll")
+	}
+
+	var r0 []domain.PayloadCodec
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]domain.PayloadCodec, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []domain.PayloadCodec); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.PayloadCodec)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByModel provides a mock function with given fields: ctx, model
+func (_m *CodecRepositoryInterface) GetByModel(ctx context.Context, model string) (*domain.PayloadCodec, error) {
+	ret := _m.Called(ctx, model)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByModel")
+	}
+
+	var r0 *domain.PayloadCodec
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.PayloadCodec, error)); ok {
+		return rf(ctx, model)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.PayloadCodec); ok {
+		r0 = rf(ctx, model)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.PayloadCodec)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, model)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Save provides a mock function with given fields: ctx, c
+func (_m *CodecRepositoryInterface) Save(ctx context.Context, c *domain.PayloadCodec) error {
+	ret := _m.Called(ctx, c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.PayloadCodec) error); ok {
+		r0 = rf(ctx, c)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewCodecRepositoryInterface creates a new instance of CodecRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCodecRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CodecRepositoryInterface {
+	mock := &CodecRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,80 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UplinkRepositoryInterface is an autogenerated mock type for the UplinkRepositoryInterface type
+type UplinkRepositoryInterface struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, u
+func (_m *UplinkRepositoryInterface) Create(ctx context.Context, u *domain.UplinkRecord) error {
+	ret := _m.Called(ctx, u)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.UplinkRecord) error); ok {
+		r0 = rf(ctx, u)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetByDeviceID provides a mock function with given fields: ctx, deviceID, page, limit
+func (_m *UplinkRepositoryInterface) GetByDeviceID(ctx context.Context, deviceID primitive.ObjectID, page int, limit int) ([]domain.UplinkRecord, error) {
+	ret := _m.Called(ctx, deviceID, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByDeviceID")
+	}
+
+	var r0 []domain.UplinkRecord
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID, int, int) ([]domain.UplinkRecord, error)); ok {
+		return rf(ctx, deviceID, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID, int, int) []domain.UplinkRecord); ok {
+		r0 = rf(ctx, deviceID, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.UplinkRecord)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, primitive.ObjectID, int, int) error); ok {
+		r1 = rf(ctx, deviceID, page, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewUplinkRepositoryInterface creates a new instance of UplinkRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUplinkRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UplinkRepositoryInterface {
+	mock := &UplinkRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
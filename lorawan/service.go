@@ -0,0 +1,205 @@
+package lorawan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+)
+
+// tracer starts the spans of the service methods
+var tracer = otel.Tracer("github.com/anggi-susanto/mrt-go/lorawan")
+
+// DeviceRepositoryInterface is the interface that wraps the GetByDevEUI method.
+type DeviceRepositoryInterface interface {
+	GetByDevEUI(ctx context.Context, devEUI string) (*domain.Device, error)
+}
+
+// CodecRepositoryInterface is the interface that wraps the payload codec storage methods.
+type CodecRepositoryInterface interface {
+	Save(ctx context.Context, c *domain.PayloadCodec) error
+	GetAll(ctx context.Context) ([]domain.PayloadCodec, error)
+	GetByModel(ctx context.Context, model string) (*domain.PayloadCodec, error)
+}
+
+// UplinkRepositoryInterface is the interface that wraps the uplink storage methods.
+type UplinkRepositoryInterface interface {
+	Create(ctx context.Context, u *domain.UplinkRecord) error
+	GetByDeviceID(ctx context.Context, deviceID primitive.ObjectID, page, limit int) ([]domain.UplinkRecord, error)
+}
+
+// WasteWaterServiceInterface is the interface that wraps the Create method of the waste water ingestion.
+type WasteWaterServiceInterface interface {
+	Create(ctx context.Context, w *domain.WastewaterDataRequest) error
+}
+
+// Service is the interface that wraps the LoRaWAN uplink ingestion.
+type Service struct {
+	deviceRepository  DeviceRepositoryInterface
+	codecRepository   CodecRepositoryInterface
+	uplinkRepository  UplinkRepositoryInterface
+	wasteWaterService WasteWaterServiceInterface
+}
+
+// NewService creates a new instance of the Service struct, initializing it with the provided repositories.
+//
+// Parameters:
+// - deviceRepository: The DeviceRepositoryInterface implementation used to map DevEUIs to devices.
+// - codecRepository: The CodecRepositoryInterface implementation holding the codecs of the device models.
+// - uplinkRepository: The UplinkRepositoryInterface implementation storing the uplinks and their radio metadata.
+// - wasteWaterService: The WasteWaterServiceInterface implementation the readings are pushed through.
+//
+// Returns:
+// - A pointer to the newly created Service instance.
+func NewService(deviceRepository DeviceRepositoryInterface, codecRepository CodecRepositoryInterface, uplinkRepository UplinkRepositoryInterface, wasteWaterService WasteWaterServiceInterface) *Service {
+	return &Service{
+		deviceRepository:  deviceRepository,
+		codecRepository:   codecRepository,
+		uplinkRepository:  uplinkRepository,
+		wasteWaterService: wasteWaterService,
+	}
+}
+
+// Ingest decodes an uplink with the codec of the device model and stores the reading.
+//
+// The uplink is stored with its radio metadata and the ID of the reading, or the reason it gave no
+// reading, e.g. the device model has no codec or the payload does not match it. An uplink forwarded
+// again by the network server is not stored twice.
+//
+// ctx - context.Context for the operation.
+// uplink - the uplink received from the network server.
+// Returns the stored uplink, nil when no device has the DevEUI, and an error when it could not be stored.
+func (s *Service) Ingest(ctx context.Context, uplink domain.Uplink) (*domain.UplinkRecord, error) {
+	ctx, span := tracer.Start(ctx, "lorawan.Service.Ingest")
+	defer span.End()
+	device, err := s.deviceRepository.GetByDevEUI(ctx, uplink.DevEUI)
+	if err != nil || device == nil {
+		return nil, err
+	}
+	record := &domain.UplinkRecord{
+		DeviceID:   device.ID,
+		DevEUI:     uplink.DevEUI,
+		FPort:      uplink.FPort,
+		FCnt:       uplink.FCnt,
+		Payload:    uplink.Payload,
+		Radio:      uplink.Radio,
+		ReceivedAt: uplink.ReceivedAt,
+	}
+
+	values, decodeErr := s.decode(ctx, device, uplink)
+	if decodeErr != nil && !errors.Is(decodeErr, domain.ErrInvalidLoRaWAN) {
+		return nil, decodeErr
+	}
+	if decodeErr == nil && len(values) > 0 {
+		w := &domain.WastewaterDataRequest{DeviceID: device.ID, Timestamp: uplink.ReceivedAt}
+		if uplink.ID != "" {
+			w.DedupKey = "lorawan-" + uplink.ID
+		}
+		for parameter, value := range values {
+			if !w.SetValue(parameter, value) {
+				if w.Parameters == nil {
+					w.Parameters = map[string]float64{}
+				}
+				w.Parameters[parameter] = value
+			}
+		}
+		if err = s.wasteWaterService.Create(ctx, w); err != nil {
+			if errors.Is(err, domain.ErrDuplicateReading) {
+				record.ReadingID, record.Values = w.ID, values
+				return record, nil
+			}
+			// A rejected reading is kept with the uplink, the network server retries on other errors
+			if !errors.Is(err, domain.ErrOutOfRange) && !errors.Is(err, domain.ErrInvalidMeasurement) &&
+				!errors.Is(err, domain.ErrUnknownUnit) && !errors.Is(err, domain.ErrDeviceDecommissioned) {
+				return nil, err
+			}
+			decodeErr = err
+		} else {
+			record.ReadingID, record.Values = w.ID, values
+		}
+	}
+	if decodeErr != nil {
+		record.Error = decodeErr.Error()
+	}
+	if err = s.uplinkRepository.Create(ctx, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// decode decodes the payload of an uplink, nil values for an uplink on another port than the measurements.
+func (s *Service) decode(ctx context.Context, device *domain.Device, uplink domain.Uplink) (map[string]float64, error) {
+	codec, err := s.codecRepository.GetByModel(ctx, device.Model)
+	if err != nil {
+		return nil, err
+	}
+	if codec == nil {
+		return nil, fmt.Errorf("%w: no codec for model %q", domain.ErrInvalidLoRaWAN, device.Model)
+	}
+	if codec.FPort != 0 && codec.FPort != uplink.FPort {
+		return nil, nil
+	}
+	return Decode(*codec, uplink.Payload)
+}
+
+// SaveCodec creates or replaces the payload codec of a device model.
+//
+// ctx - context.Context for the operation.
+// codec - the codec, its model is the key.
+// Returns the stored codec and an error wrapping domain.ErrInvalidLoRaWAN when the codec is invalid.
+func (s *Service) SaveCodec(ctx context.Context, codec domain.PayloadCodec) (*domain.PayloadCodec, error) {
+	ctx, span := tracer.Start(ctx, "lorawan.Service.SaveCodec")
+	defer span.End()
+	if err := codec.Validate(); err != nil {
+		return nil, err
+	}
+	codec.UpdatedAt = domain.MyTime{Time: time.Now()}
+	if err := s.codecRepository.Save(ctx, &codec); err != nil {
+		return nil, err
+	}
+	return &codec, nil
+}
+
+// GetCodecs retrieves the payload codecs of every model.
+//
+// ctx - context.Context for the operation.
+// Returns the codecs and an error.
+func (s *Service) GetCodecs(ctx context.Context) ([]domain.PayloadCodec, error) {
+	ctx, span := tracer.Start(ctx, "lorawan.Service.GetCodecs")
+	defer span.End()
+	codecs, err := s.codecRepository.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if codecs == nil {
+		codecs = []domain.PayloadCodec{}
+	}
+	return codecs, nil
+}
+
+// GetUplinks retrieves the uplinks of a device, newest first.
+//
+// ctx - context.Context for the operation.
+// deviceID - the ID of the device.
+// page, limit - the pagination.
+// Returns the uplinks and an error.
+func (s *Service) GetUplinks(ctx context.Context, deviceID string, page, limit int) ([]domain.UplinkRecord, error) {
+	ctx, span := tracer.Start(ctx, "lorawan.Service.GetUplinks")
+	defer span.End()
+	objectID, err := primitive.ObjectIDFromHex(deviceID)
+	if err != nil {
+		return nil, err
+	}
+	uplinks, err := s.uplinkRepository.GetByDeviceID(ctx, objectID, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	if uplinks == nil {
+		uplinks = []domain.UplinkRecord{}
+	}
+	return uplinks, nil
+}
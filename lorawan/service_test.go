@@ -0,0 +1,196 @@
+package lorawan_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/lorawan"
+	"github.com/anggi-susanto/mrt-go/lorawan/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// outfall returns a device whose model has a byte layout codec for pH and COD on port 2
+func outfall() (domain.Device, *mocks.DeviceRepositoryInterface, *mocks.CodecRepositoryInterface) {
+	device := domain.Device{ID: primitive.NewObjectID(), Model: "OUT-LR1", DevEUI: "70B3D57ED0001234"}
+	mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+	mockDeviceRepo.On("GetByDevEUI", mock.Anything, device.DevEUI).Return(&device, nil)
+	mockCodecRepo := new(mocks.CodecRepositoryInterface)
+	mockCodecRepo.On("GetByModel", mock.Anything, device.Model).Return(&domain.PayloadCodec{
+		Model: device.Model,
+		Type:  domain.CodecByteLayout,
+		FPort: 2,
+		Fields: []domain.ByteField{
+			{Parameter: domain.ParamPH, Start: 0, DataType: domain.ByteUint16, Scale: 0.01},
+			{Parameter: domain.ParamCOD, Start: 2, DataType: domain.ByteUint16},
+		},
+	}, nil)
+	return device, mockDeviceRepo, mockCodecRepo
+}
+
+func TestServiceIngest(t *testing.T) {
+	receivedAt := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	uplink := domain.Uplink{
+		ID:         "b9f1a1e2",
+		DevEUI:     "70B3D57ED0001234",
+		FPort:      2,
+		FCnt:       42,
+		Payload:    []byte{0x02, 0xC8, 0x00, 0x55},
+		ReceivedAt: receivedAt,
+		Radio:      domain.RadioMetadata{GatewayID: "0016c001f153a14c", RSSI: -57, SNR: 9.5, Gateways: 2},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		device, mockDeviceRepo, mockCodecRepo := outfall()
+		readingID := primitive.NewObjectID()
+		mockWasteWater := new(mocks.WasteWaterServiceInterface)
+		mockWasteWater.On("Create", mock.Anything, mock.MatchedBy(func(w *domain.WastewaterDataRequest) bool {
+			return w.DeviceID == device.ID && w.PH == 7.12 && w.COD == 85 && w.DedupKey == "lorawan-b9f1a1e2" && w.Timestamp.Equal(receivedAt)
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.WastewaterDataRequest).ID = readingID
+		}).Return(nil)
+		mockUplinkRepo := new(mocks.UplinkRepositoryInterface)
+		mockUplinkRepo.On("Create", mock.Anything, mock.MatchedBy(func(u *domain.UplinkRecord) bool {
+			return u.DeviceID == device.ID && u.ReadingID == readingID && u.Radio.RSSI == -57 && u.Error == ""
+		})).Return(nil)
+
+		service := lorawan.NewService(mockDeviceRepo, mockCodecRepo, mockUplinkRepo, mockWasteWater)
+		record, err := service.Ingest(context.Background(), uplink)
+		assert.NoError(t, err)
+		assert.Equal(t, readingID, record.ReadingID)
+		assert.Equal(t, 85.0, record.Values[domain.ParamCOD])
+		mockWasteWater.AssertExpectations(t)
+		mockUplinkRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unknown device", func(t *testing.T) {
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByDevEUI", mock.Anything, uplink.DevEUI).Return(nil, nil)
+
+		service := lorawan.NewService(mockDeviceRepo, new(mocks.CodecRepositoryInterface), new(mocks.UplinkRepositoryInterface), new(mocks.WasteWaterServiceInterface))
+		record, err := service.Ingest(context.Background(), uplink)
+		assert.NoError(t, err)
+		assert.Nil(t, record)
+	})
+
+	t.Run("Retried uplink", func(t *testing.T) {
+		_, mockDeviceRepo, mockCodecRepo := outfall()
+		readingID := primitive.NewObjectID()
+		mockWasteWater := new(mocks.WasteWaterServiceInterface)
+		mockWasteWater.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.WastewaterDataRequest).ID = readingID
+		}).Return(domain.ErrDuplicateReading)
+		mockUplinkRepo := new(mocks.UplinkRepositoryInterface)
+
+		service := lorawan.NewService(mockDeviceRepo, mockCodecRepo, mockUplinkRepo, mockWasteWater)
+		record, err := service.Ingest(context.Background(), uplink)
+		assert.NoError(t, err)
+		assert.Equal(t, readingID, record.ReadingID)
+		mockUplinkRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Payload not matching the codec", func(t *testing.T) {
+		_, mockDeviceRepo, mockCodecRepo := outfall()
+		mockWasteWater := new(mocks.WasteWaterServiceInterface)
+		mockUplinkRepo := new(mocks.UplinkRepositoryInterface)
+		mockUplinkRepo.On("Create", mock.Anything, mock.MatchedBy(func(u *domain.UplinkRecord) bool {
+			return u.ReadingID.IsZero() && u.Error != ""
+		})).Return(nil)
+
+		short := uplink
+		short.Payload = []byte{0x02}
+		service := lorawan.NewService(mockDeviceRepo, mockCodecRepo, mockUplinkRepo, mockWasteWater)
+		record, err := service.Ingest(context.Background(), short)
+		assert.NoError(t, err)
+		assert.Contains(t, record.Error, "beyond")
+		mockWasteWater.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Other port", func(t *testing.T) {
+		_, mockDeviceRepo, mockCodecRepo := outfall()
+		mockWasteWater := new(mocks.WasteWaterServiceInterface)
+		mockUplinkRepo := new(mocks.UplinkRepositoryInterface)
+		mockUplinkRepo.On("Create", mock.Anything, mock.MatchedBy(func(u *domain.UplinkRecord) bool {
+			return u.ReadingID.IsZero() && u.Error == ""
+		})).Return(nil)
+
+		status := uplink
+		status.FPort = 5
+		service := lorawan.NewService(mockDeviceRepo, mockCodecRepo, mockUplinkRepo, mockWasteWater)
+		_, err := service.Ingest(context.Background(), status)
+		assert.NoError(t, err)
+		mockWasteWater.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Reading rejected", func(t *testing.T) {
+		_, mockDeviceRepo, mockCodecRepo := outfall()
+		mockWasteWater := new(mocks.WasteWaterServiceInterface)
+		mockWasteWater.On("Create", mock.Anything, mock.Anything).Return(domain.ErrOutOfRange)
+		mockUplinkRepo := new(mocks.UplinkRepositoryInterface)
+		mockUplinkRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+		service := lorawan.NewService(mockDeviceRepo, mockCodecRepo, mockUplinkRepo, mockWasteWater)
+		record, err := service.Ingest(context.Background(), uplink)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.ErrOutOfRange.Error(), record.Error)
+	})
+
+	t.Run("Device decommissioned", func(t *testing.T) {
+		_, mockDeviceRepo, mockCodecRepo := outfall()
+		mockWasteWater := new(mocks.WasteWaterServiceInterface)
+		mockWasteWater.On("Create", mock.Anything, mock.Anything).Return(fmt.Errorf("%w: outfall", domain.ErrDeviceDecommissioned))
+		mockUplinkRepo := new(mocks.UplinkRepositoryInterface)
+		mockUplinkRepo.On("Create", mock.Anything, mock.MatchedBy(func(r *domain.UplinkRecord) bool {
+			return r.ReadingID.IsZero() && r.Error != ""
+		})).Return(nil)
+
+		service := lorawan.NewService(mockDeviceRepo, mockCodecRepo, mockUplinkRepo, mockWasteWater)
+		record, err := service.Ingest(context.Background(), uplink)
+		assert.NoError(t, err)
+		assert.Contains(t, record.Error, domain.ErrDeviceDecommissioned.Error())
+		mockUplinkRepo.AssertExpectations(t)
+	})
+
+	t.Run("Storage error", func(t *testing.T) {
+		_, mockDeviceRepo, mockCodecRepo := outfall()
+		mockWasteWater := new(mocks.WasteWaterServiceInterface)
+		mockWasteWater.On("Create", mock.Anything, mock.Anything).Return(errors.New("database error"))
+
+		service := lorawan.NewService(mockDeviceRepo, mockCodecRepo, new(mocks.UplinkRepositoryInterface), mockWasteWater)
+		record, err := service.Ingest(context.Background(), uplink)
+		assert.Error(t, err)
+		assert.Nil(t, record)
+	})
+}
+
+func TestServiceSaveCodec(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockCodecRepo := new(mocks.CodecRepositoryInterface)
+		mockCodecRepo.On("Save", mock.Anything, mock.MatchedBy(func(c *domain.PayloadCodec) bool {
+			return c.Model == "OUT-LR1" && !c.UpdatedAt.IsZero()
+		})).Return(nil)
+
+		service := lorawan.NewService(new(mocks.DeviceRepositoryInterface), mockCodecRepo, new(mocks.UplinkRepositoryInterface), new(mocks.WasteWaterServiceInterface))
+		_, err := service.SaveCodec(context.Background(), domain.PayloadCodec{
+			Model:    "OUT-LR1",
+			Type:     domain.CodecCayenneLPP,
+			Channels: []domain.LPPChannel{{Channel: 1, Parameter: domain.ParamPH}},
+		})
+		assert.NoError(t, err)
+		mockCodecRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid codec", func(t *testing.T) {
+		mockCodecRepo := new(mocks.CodecRepositoryInterface)
+
+		service := lorawan.NewService(new(mocks.DeviceRepositoryInterface), mockCodecRepo, new(mocks.UplinkRepositoryInterface), new(mocks.WasteWaterServiceInterface))
+		_, err := service.SaveCodec(context.Background(), domain.PayloadCodec{Model: "OUT-LR1", Type: domain.CodecCayenneLPP})
+		assert.ErrorIs(t, err, domain.ErrInvalidLoRaWAN)
+		mockCodecRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+}
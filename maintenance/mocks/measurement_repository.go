@@ -0,0 +1,68 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MeasurementRepositoryInterface is an autogenerated mock type for the MeasurementRepositoryInterface type
+type MeasurementRepositoryInterface struct {
+	mock.Mock
+}
+
+// ClearMaintenance provides a mock function with given fields: ctx, workOrderID
+func (_m *MeasurementRepositoryInterface) ClearMaintenance(ctx context.Context, workOrderID primitive.ObjectID) error {
+	ret := _m.Called(ctx, workOrderID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClearMaintenance")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID) error); ok {
+		r0 = rf(ctx, workOrderID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetMaintenance provides a mock function with given fields: ctx, deviceID, window, workOrderID
+func (_m *MeasurementRepositoryInterface) SetMaintenance(ctx context.Context, deviceID primitive.ObjectID, window domain.TimeWindow, workOrderID primitive.ObjectID) error {
+	ret := _m.Called(ctx, deviceID, window, workOrderID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetMaintenance")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID, domain.TimeWindow, primitive.ObjectID) error); ok {
+		r0 = rf(ctx, deviceID, window, workOrderID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewMeasurementRepositoryInterface creates a new instance of MeasurementRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMeasurementRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MeasurementRepositoryInterface {
+	mock := &MeasurementRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
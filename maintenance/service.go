@@ -0,0 +1,236 @@
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+)
+
+// tracer starts the spans of the service methods
+var tracer = otel.Tracer("github.com/anggi-susanto/mrt-go/maintenance")
+
+// maxCalendarWorkOrders bounds the work orders read to build a calendar
+const maxCalendarWorkOrders = 1000
+
+// WorkOrderRepositoryInterface is the interface that wraps the work order storage methods.
+type WorkOrderRepositoryInterface interface {
+	Create(ctx context.Context, w *domain.WorkOrder) error
+	GetAll(ctx context.Context, filter domain.MaintenanceFilter, page, limit int) ([]domain.WorkOrder, error)
+	GetByID(ctx context.Context, id string) (*domain.WorkOrder, error)
+	Update(ctx context.Context, w *domain.WorkOrder, status string) error
+	Delete(ctx context.Context, id string) error
+}
+
+// MeasurementRepositoryInterface is the interface that wraps the maintenance marking of the measurements.
+type MeasurementRepositoryInterface interface {
+	SetMaintenance(ctx context.Context, deviceID primitive.ObjectID, window domain.TimeWindow, workOrderID primitive.ObjectID) error
+	ClearMaintenance(ctx context.Context, workOrderID primitive.ObjectID) error
+}
+
+// Service is the interface that wraps the maintenance log of the devices.
+type Service struct {
+	workOrderRepository   WorkOrderRepositoryInterface
+	measurementRepository MeasurementRepositoryInterface
+}
+
+// NewService creates a new instance of the Service struct, initializing it with the provided repositories.
+//
+// Parameters:
+// - workOrderRepository: The WorkOrderRepositoryInterface implementation used by the Service.
+// - measurementRepository: The MeasurementRepositoryInterface implementation used to mark the readings taken during a downtime.
+//
+// Returns:
+// - A pointer to the newly created Service instance.
+func NewService(workOrderRepository WorkOrderRepositoryInterface, measurementRepository MeasurementRepositoryInterface) *Service {
+	return &Service{
+		workOrderRepository:   workOrderRepository,
+		measurementRepository: measurementRepository,
+	}
+}
+
+// Create records a work order and marks the readings of its device taken during its downtime.
+//
+// ctx - context.Context for the operation.
+// w - the work order to record.
+// Returns an error if the work order is invalid or there was a problem storing it.
+func (s *Service) Create(ctx context.Context, w *domain.WorkOrder) error {
+	ctx, span := tracer.Start(ctx, "maintenance.Service.Create")
+	defer span.End()
+	if err := w.Validate(); err != nil {
+		return err
+	}
+	now := time.Now()
+	stamp(w, now)
+	w.CreatedAt = domain.MyTime{Time: now}
+	w.UpdatedAt = domain.MyTime{Time: now}
+	if err := s.workOrderRepository.Create(ctx, w); err != nil {
+		return err
+	}
+	return s.markDowntime(ctx, w)
+}
+
+// GetAll retrieves the work orders matching the filter with pagination.
+//
+// ctx context.Context, filter domain.MaintenanceFilter, page int, limit int
+// []domain.WorkOrder, error
+func (s *Service) GetAll(ctx context.Context, filter domain.MaintenanceFilter, page, limit int) ([]domain.WorkOrder, error) {
+	ctx, span := tracer.Start(ctx, "maintenance.Service.GetAll")
+	defer span.End()
+	return s.workOrderRepository.GetAll(ctx, filter, page, limit)
+}
+
+// GetByID retrieves a WorkOrder by ID.
+//
+// ctx - context.Context for the operation.
+// id - string representing the ID of the work order.
+// Returns a pointer to domain.WorkOrder and an error.
+func (s *Service) GetByID(ctx context.Context, id string) (*domain.WorkOrder, error) {
+	ctx, span := tracer.Start(ctx, "maintenance.Service.GetByID")
+	defer span.End()
+	return s.workOrderRepository.GetByID(ctx, id)
+}
+
+// Update updates a WorkOrder and marks the readings of its downtime again.
+//
+// The status moves one step at a time from planned to in progress to done and is kept when
+// omitted. The creation, start and completion times are kept from the stored work order.
+//
+// ctx - context.Context for the operation.
+// id - string representing the ID of the work order.
+// w - pointer to domain.WorkOrder representing the work order to be updated.
+// Returns the updated work order, nil when it does not exist, and an error.
+func (s *Service) Update(ctx context.Context, id string, w *domain.WorkOrder) (*domain.WorkOrder, error) {
+	ctx, span := tracer.Start(ctx, "maintenance.Service.Update")
+	defer span.End()
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidWorkOrder, err)
+	}
+	current, err := s.workOrderRepository.GetByID(ctx, id)
+	if err != nil || current == nil {
+		return nil, err
+	}
+	w.ID = current.ID
+	if w.Status == "" {
+		w.Status = current.Status
+	}
+	if err = w.Validate(); err != nil {
+		return nil, err
+	}
+	if !domain.CanAdvance(current.Status, w.Status) {
+		return nil, fmt.Errorf("%w: %s to %s", domain.ErrWorkOrderTransition, current.Status, w.Status)
+	}
+	w.CreatedAt = current.CreatedAt
+	w.StartedAt = current.StartedAt
+	w.CompletedAt = current.CompletedAt
+	now := time.Now()
+	stamp(w, now)
+	w.UpdatedAt = domain.MyTime{Time: now}
+	if err = s.workOrderRepository.Update(ctx, w, current.Status); err != nil {
+		return nil, err
+	}
+	if err = s.measurementRepository.ClearMaintenance(ctx, w.ID); err != nil {
+		return nil, err
+	}
+	if err = s.markDowntime(ctx, w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Delete deletes a WorkOrder by ID and removes the maintenance mark it set on the readings.
+//
+// ctx - context.Context for the operation.
+// id - string representing the ID of the work order to be deleted.
+// Returns an error if there was a problem deleting the work order.
+func (s *Service) Delete(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "maintenance.Service.Delete")
+	defer span.End()
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidWorkOrder, err)
+	}
+	if err := s.measurementRepository.ClearMaintenance(ctx, objectID); err != nil {
+		return err
+	}
+	return s.workOrderRepository.Delete(ctx, id)
+}
+
+// Calendar lists the scheduled work and the downtime windows of the work orders overlapping the filter period.
+//
+// A work order with a downtime appears twice, once for its schedule and once for its downtime.
+// An open downtime ends at the end of the period, or now when the period is open.
+//
+// ctx - context.Context for the operation.
+// filter - the device, status and period of the calendar.
+// Returns the calendar entries ordered by start and an error.
+func (s *Service) Calendar(ctx context.Context, filter domain.MaintenanceFilter) ([]domain.CalendarEntry, error) {
+	ctx, span := tracer.Start(ctx, "maintenance.Service.Calendar")
+	defer span.End()
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return nil, fmt.Errorf("%w: to is before from", domain.ErrInvalidWorkOrder)
+	}
+	workOrders, err := s.workOrderRepository.GetAll(ctx, filter, 1, maxCalendarWorkOrders)
+	if err != nil {
+		return nil, err
+	}
+
+	openEnd := filter.To
+	if openEnd.IsZero() {
+		openEnd = time.Now()
+	}
+	entries := []domain.CalendarEntry{}
+	for _, w := range workOrders {
+		if w.Schedule.Overlaps(filter.From, filter.To) {
+			entries = append(entries, entry(w, w.Schedule, false))
+		}
+		if w.Downtime != nil && w.Downtime.Overlaps(filter.From, filter.To) {
+			window := *w.Downtime
+			if window.End.IsZero() {
+				window.End = openEnd
+			}
+			entries = append(entries, entry(w, window, true))
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Start.Before(entries[j].Start)
+	})
+	return entries, nil
+}
+
+// markDowntime marks the readings of the device taken during the downtime of the work order.
+func (s *Service) markDowntime(ctx context.Context, w *domain.WorkOrder) error {
+	if w.Downtime == nil {
+		return nil
+	}
+	return s.measurementRepository.SetMaintenance(ctx, w.DeviceID, *w.Downtime, w.ID)
+}
+
+// stamp records when the work order was started and completed from its status.
+func stamp(w *domain.WorkOrder, now time.Time) {
+	if w.Status != domain.WorkOrderPlanned && w.StartedAt.IsZero() {
+		w.StartedAt = now
+	}
+	if w.Status == domain.WorkOrderDone && w.CompletedAt.IsZero() {
+		w.CompletedAt = now
+	}
+}
+
+// entry builds the calendar entry of a window of the work order.
+func entry(w domain.WorkOrder, window domain.TimeWindow, downtime bool) domain.CalendarEntry {
+	return domain.CalendarEntry{
+		WorkOrderID: w.ID,
+		DeviceID:    w.DeviceID,
+		Type:        w.Type,
+		Title:       w.Title,
+		Status:      w.Status,
+		Assignees:   w.Assignees,
+		Downtime:    downtime,
+		Start:       window.Start,
+		End:         window.End,
+	}
+}
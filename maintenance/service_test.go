@@ -0,0 +1,207 @@
+package maintenance_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/maintenance"
+	"github.com/anggi-susanto/mrt-go/maintenance/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestServiceCreate(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	start := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	t.Run("Success", func(t *testing.T) {
+		downtime := domain.TimeWindow{Start: start, End: start.Add(2 * time.Hour)}
+		w := domain.WorkOrder{
+			DeviceID:  deviceID,
+			Type:      domain.WorkOrderProbeReplacement,
+			Status:    domain.WorkOrderDone,
+			Assignees: []string{"tech"},
+			Schedule:  domain.TimeWindow{Start: start},
+			Downtime:  &downtime,
+		}
+		workOrderID := primitive.NewObjectID()
+		mockRepo := new(mocks.WorkOrderRepositoryInterface)
+		mockRepo.On("Create", mock.Anything, &w).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.WorkOrder).ID = workOrderID
+		}).Return(nil)
+		mockMeasurementRepo := new(mocks.MeasurementRepositoryInterface)
+		mockMeasurementRepo.On("SetMaintenance", mock.Anything, deviceID, downtime, workOrderID).Return(nil)
+		err := maintenance.NewService(mockRepo, mockMeasurementRepo).Create(context.Background(), &w)
+		assert.NoError(t, err)
+		assert.Equal(t, start, w.Schedule.End)
+		assert.False(t, w.StartedAt.IsZero())
+		assert.False(t, w.CompletedAt.IsZero())
+		mockRepo.AssertExpectations(t)
+		mockMeasurementRepo.AssertExpectations(t)
+	})
+	t.Run("No downtime", func(t *testing.T) {
+		w := domain.WorkOrder{DeviceID: deviceID, Type: domain.WorkOrderInspection, Schedule: domain.TimeWindow{Start: start}}
+		mockRepo := new(mocks.WorkOrderRepositoryInterface)
+		mockRepo.On("Create", mock.Anything, &w).Return(nil)
+		err := maintenance.NewService(mockRepo, new(mocks.MeasurementRepositoryInterface)).Create(context.Background(), &w)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.WorkOrderPlanned, w.Status)
+		assert.True(t, w.StartedAt.IsZero())
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		for name, w := range map[string]domain.WorkOrder{
+			"No device":       {Type: domain.WorkOrderCleaning, Schedule: domain.TimeWindow{Start: start}},
+			"Unknown type":    {DeviceID: deviceID, Type: "painting", Schedule: domain.TimeWindow{Start: start}},
+			"Unknown status":  {DeviceID: deviceID, Type: domain.WorkOrderCleaning, Status: "cancelled", Schedule: domain.TimeWindow{Start: start}},
+			"No schedule":     {DeviceID: deviceID, Type: domain.WorkOrderCleaning},
+			"Schedule before": {DeviceID: deviceID, Type: domain.WorkOrderCleaning, Schedule: domain.TimeWindow{Start: start, End: start.Add(-time.Hour)}},
+			"Downtime before": {DeviceID: deviceID, Type: domain.WorkOrderOutage, Schedule: domain.TimeWindow{Start: start}, Downtime: &domain.TimeWindow{Start: start, End: start.Add(-time.Hour)}},
+		} {
+			t.Run(name, func(t *testing.T) {
+				s := maintenance.NewService(new(mocks.WorkOrderRepositoryInterface), new(mocks.MeasurementRepositoryInterface))
+				assert.ErrorIs(t, s.Create(context.Background(), &w), domain.ErrInvalidWorkOrder)
+			})
+		}
+	})
+	t.Run("Error", func(t *testing.T) {
+		w := domain.WorkOrder{DeviceID: deviceID, Type: domain.WorkOrderOutage, Schedule: domain.TimeWindow{Start: start}, Downtime: &domain.TimeWindow{Start: start}}
+		mockRepo := new(mocks.WorkOrderRepositoryInterface)
+		mockRepo.On("Create", mock.Anything, &w).Return(errors.New("error"))
+		mockMeasurementRepo := new(mocks.MeasurementRepositoryInterface)
+		assert.Error(t, maintenance.NewService(mockRepo, mockMeasurementRepo).Create(context.Background(), &w))
+		mockMeasurementRepo.AssertNotCalled(t, "SetMaintenance", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestServiceUpdate(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	start := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	id := primitive.NewObjectID()
+	created := domain.MyTime{Time: start.Add(-24 * time.Hour)}
+	stored := func(status string) *domain.WorkOrder {
+		return &domain.WorkOrder{ID: id, DeviceID: deviceID, Type: domain.WorkOrderOutage, Status: status, Schedule: domain.TimeWindow{Start: start}, CreatedAt: created}
+	}
+	t.Run("Success", func(t *testing.T) {
+		downtime := domain.TimeWindow{Start: start}
+		w := domain.WorkOrder{ID: primitive.NewObjectID(), DeviceID: deviceID, Type: domain.WorkOrderOutage, Status: domain.WorkOrderInProgress, Schedule: domain.TimeWindow{Start: start}, Downtime: &downtime}
+		mockRepo := new(mocks.WorkOrderRepositoryInterface)
+		mockRepo.On("GetByID", mock.Anything, id.Hex()).Return(stored(domain.WorkOrderPlanned), nil)
+		mockRepo.On("Update", mock.Anything, &w, domain.WorkOrderPlanned).Return(nil)
+		mockMeasurementRepo := new(mocks.MeasurementRepositoryInterface)
+		clear := mockMeasurementRepo.On("ClearMaintenance", mock.Anything, id).Return(nil)
+		mockMeasurementRepo.On("SetMaintenance", mock.Anything, deviceID, downtime, id).Return(nil).NotBefore(clear)
+		updated, err := maintenance.NewService(mockRepo, mockMeasurementRepo).Update(context.Background(), id.Hex(), &w)
+		assert.NoError(t, err)
+		assert.Equal(t, id, updated.ID)
+		assert.Equal(t, created, updated.CreatedAt)
+		assert.False(t, updated.StartedAt.IsZero())
+		assert.True(t, updated.CompletedAt.IsZero())
+		mockRepo.AssertExpectations(t)
+		mockMeasurementRepo.AssertExpectations(t)
+	})
+	t.Run("Times kept", func(t *testing.T) {
+		current := stored(domain.WorkOrderInProgress)
+		current.StartedAt = start.Add(time.Hour)
+		w := domain.WorkOrder{DeviceID: deviceID, Type: domain.WorkOrderOutage, Schedule: domain.TimeWindow{Start: start}, StartedAt: start.Add(48 * time.Hour)}
+		mockRepo := new(mocks.WorkOrderRepositoryInterface)
+		mockRepo.On("GetByID", mock.Anything, id.Hex()).Return(current, nil)
+		mockRepo.On("Update", mock.Anything, &w, domain.WorkOrderInProgress).Return(nil)
+		mockMeasurementRepo := new(mocks.MeasurementRepositoryInterface)
+		mockMeasurementRepo.On("ClearMaintenance", mock.Anything, id).Return(nil)
+		updated, err := maintenance.NewService(mockRepo, mockMeasurementRepo).Update(context.Background(), id.Hex(), &w)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.WorkOrderInProgress, updated.Status)
+		assert.Equal(t, start.Add(time.Hour), updated.StartedAt)
+	})
+	t.Run("Not found", func(t *testing.T) {
+		w := domain.WorkOrder{DeviceID: deviceID, Type: domain.WorkOrderCleaning, Schedule: domain.TimeWindow{Start: start}}
+		mockRepo := new(mocks.WorkOrderRepositoryInterface)
+		mockRepo.On("GetByID", mock.Anything, id.Hex()).Return(nil, nil)
+		updated, err := maintenance.NewService(mockRepo, new(mocks.MeasurementRepositoryInterface)).Update(context.Background(), id.Hex(), &w)
+		assert.NoError(t, err)
+		assert.Nil(t, updated)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("Transition", func(t *testing.T) {
+		for name, move := range map[string][2]string{
+			"Skipped":  {domain.WorkOrderPlanned, domain.WorkOrderDone},
+			"Reversed": {domain.WorkOrderDone, domain.WorkOrderInProgress},
+		} {
+			t.Run(name, func(t *testing.T) {
+				w := domain.WorkOrder{DeviceID: deviceID, Type: domain.WorkOrderOutage, Status: move[1], Schedule: domain.TimeWindow{Start: start}}
+				mockRepo := new(mocks.WorkOrderRepositoryInterface)
+				mockRepo.On("GetByID", mock.Anything, id.Hex()).Return(stored(move[0]), nil)
+				_, err := maintenance.NewService(mockRepo, new(mocks.MeasurementRepositoryInterface)).Update(context.Background(), id.Hex(), &w)
+				assert.ErrorIs(t, err, domain.ErrWorkOrderTransition)
+			})
+		}
+	})
+	t.Run("Invalid ID", func(t *testing.T) {
+		w := domain.WorkOrder{DeviceID: deviceID, Type: domain.WorkOrderCleaning, Schedule: domain.TimeWindow{Start: start}}
+		s := maintenance.NewService(new(mocks.WorkOrderRepositoryInterface), new(mocks.MeasurementRepositoryInterface))
+		_, err := s.Update(context.Background(), "invalid", &w)
+		assert.ErrorIs(t, err, domain.ErrInvalidWorkOrder)
+	})
+}
+
+func TestServiceDelete(t *testing.T) {
+	id := primitive.NewObjectID()
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.WorkOrderRepositoryInterface)
+		mockRepo.On("Delete", mock.Anything, id.Hex()).Return(nil)
+		mockMeasurementRepo := new(mocks.MeasurementRepositoryInterface)
+		mockMeasurementRepo.On("ClearMaintenance", mock.Anything, id).Return(nil)
+		assert.NoError(t, maintenance.NewService(mockRepo, mockMeasurementRepo).Delete(context.Background(), id.Hex()))
+		mockRepo.AssertExpectations(t)
+		mockMeasurementRepo.AssertExpectations(t)
+	})
+	t.Run("Invalid ID", func(t *testing.T) {
+		s := maintenance.NewService(new(mocks.WorkOrderRepositoryInterface), new(mocks.MeasurementRepositoryInterface))
+		assert.ErrorIs(t, s.Delete(context.Background(), "1"), domain.ErrInvalidWorkOrder)
+	})
+}
+
+func TestServiceCalendar(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(7 * 24 * time.Hour)
+	filter := domain.MaintenanceFilter{DeviceID: deviceID, From: from, To: to}
+	t.Run("Success", func(t *testing.T) {
+		cleaning := domain.WorkOrder{
+			ID:       primitive.NewObjectID(),
+			DeviceID: deviceID,
+			Type:     domain.WorkOrderCleaning,
+			Schedule: domain.TimeWindow{Start: from.Add(48 * time.Hour), End: from.Add(50 * time.Hour)},
+		}
+		outage := domain.WorkOrder{
+			ID:       primitive.NewObjectID(),
+			DeviceID: deviceID,
+			Type:     domain.WorkOrderOutage,
+			Schedule: domain.TimeWindow{Start: from.Add(-48 * time.Hour), End: from.Add(-47 * time.Hour)},
+			Downtime: &domain.TimeWindow{Start: from.Add(-48 * time.Hour)},
+		}
+		mockRepo := new(mocks.WorkOrderRepositoryInterface)
+		mockRepo.On("GetAll", mock.Anything, filter, 1, mock.Anything).Return([]domain.WorkOrder{outage, cleaning}, nil)
+		entries, err := maintenance.NewService(mockRepo, nil).Calendar(context.Background(), filter)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 2)
+		assert.Equal(t, outage.ID, entries[0].WorkOrderID)
+		assert.True(t, entries[0].Downtime)
+		assert.Equal(t, to, entries[0].End)
+		assert.Equal(t, cleaning.ID, entries[1].WorkOrderID)
+		assert.False(t, entries[1].Downtime)
+	})
+	t.Run("Invalid period", func(t *testing.T) {
+		_, err := maintenance.NewService(new(mocks.WorkOrderRepositoryInterface), nil).Calendar(context.Background(), domain.MaintenanceFilter{From: to, To: from})
+		assert.ErrorIs(t, err, domain.ErrInvalidWorkOrder)
+	})
+	t.Run("Error", func(t *testing.T) {
+		mockRepo := new(mocks.WorkOrderRepositoryInterface)
+		mockRepo.On("GetAll", mock.Anything, filter, 1, mock.Anything).Return(nil, errors.New("error"))
+		_, err := maintenance.NewService(mockRepo, nil).Calendar(context.Background(), filter)
+		assert.Error(t, err)
+	})
+}
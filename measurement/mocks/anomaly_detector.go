@@ -0,0 +1,78 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AnomalyDetectorInterface is an autogenerated mock type for the AnomalyDetectorInterface type
+type AnomalyDetectorInterface struct {
+	mock.Mock
+}
+
+// Detect provides a mock function with given fields: ctx, measurements
+func (_m *AnomalyDetectorInterface) Detect(ctx context.Context, measurements []domain.Measurement) ([]domain.AnomalyEvent, error) {
+	ret := _m.Called(ctx, measurements)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Detect")
+	}
+
+	var r0 []domain.AnomalyEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.Measurement) ([]domain.AnomalyEvent, error)); ok {
+		return rf(ctx, measurements)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.Measurement) []domain.AnomalyEvent); ok {
+		r0 = rf(ctx, measurements)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.AnomalyEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []domain.Measurement) error); ok {
+		r1 = rf(ctx, measurements)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Record provides a mock function with given fields: ctx, measurements, events
+func (_m *AnomalyDetectorInterface) Record(ctx context.Context, measurements []domain.Measurement, events []domain.AnomalyEvent) error {
+	ret := _m.Called(ctx, measurements, events)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Record")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.Measurement, []domain.AnomalyEvent) error); ok {
+		r0 = rf(ctx, measurements, events)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewAnomalyDetectorInterface creates a new instance of AnomalyDetectorInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAnomalyDetectorInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AnomalyDetectorInterface {
+	mock := &AnomalyDetectorInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,62 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CalibrationRepositoryInterface is an autogenerated mock type for the CalibrationRepositoryInterface type
+type CalibrationRepositoryInterface struct {
+	mock.Mock
+}
+
+// GetBySensor provides a mock function with given fields: ctx, sensorID, parameter
+func (_m *CalibrationRepositoryInterface) GetBySensor(ctx context.Context, sensorID primitive.ObjectID, parameter string) ([]domain.Calibration, error) {
+	ret := _m.Called(ctx, sensorID, parameter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBySensor")
+	}
+
+	var r0 []domain.Calibration
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID, string) ([]domain.Calibration, error)); ok {
+		return rf(ctx, sensorID, parameter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID, string) []domain.Calibration); ok {
+		r0 = rf(ctx, sensorID, parameter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Calibration)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, primitive.ObjectID, string) error); ok {
+		r1 = rf(ctx, sensorID, parameter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewCalibrationRepositoryInterface creates a new instance of CalibrationRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCalibrationRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CalibrationRepositoryInterface {
+	mock := &CalibrationRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
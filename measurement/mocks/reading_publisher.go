@@ -0,0 +1,35 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ReadingPublisherInterface is an autogenerated mock type for the ReadingPublisherInterface type
+type ReadingPublisherInterface struct {
+	mock.Mock
+}
+
+// PublishMeasurements provides a mock function with given fields: ctx, measurements
+func (_m *ReadingPublisherInterface) PublishMeasurements(ctx context.Context, measurements []domain.Measurement) {
+	_m.Called(ctx, measurements)
+}
+
+// NewReadingPublisherInterface creates a new instance of ReadingPublisherInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewReadingPublisherInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ReadingPublisherInterface {
+	mock := &ReadingPublisherInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,62 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SensorCatalogInterface is an autogenerated mock type for the SensorCatalogInterface type
+type SensorCatalogInterface struct {
+	mock.Mock
+}
+
+// GetDeviceParameters provides a mock function with given fields: ctx, deviceID
+func (_m *SensorCatalogInterface) GetDeviceParameters(ctx context.Context, deviceID primitive.ObjectID) (map[string][]domain.DeviceParameter, error) {
+	ret := _m.Called(ctx, deviceID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDeviceParameters")
+	}
+
+	var r0 map[string][]domain.DeviceParameter
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID) (map[string][]domain.DeviceParameter, error)); ok {
+		return rf(ctx, deviceID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID) map[string][]domain.DeviceParameter); ok {
+		r0 = rf(ctx, deviceID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string][]domain.DeviceParameter)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, primitive.ObjectID) error); ok {
+		r1 = rf(ctx, deviceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewSensorCatalogInterface creates a new instance of SensorCatalogInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSensorCatalogInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SensorCatalogInterface {
+	mock := &SensorCatalogInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,545 @@
+package measurement
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+)
+
+// tracer starts the spans of the service methods
+var tracer = otel.Tracer("github.com/anggi-susanto/mrt-go/measurement")
+
+// MeasurementRepositoryInterface is the interface that wraps the measurement storage methods.
+type MeasurementRepositoryInterface interface {
+	InsertMany(ctx context.Context, measurements []domain.Measurement) error
+	GetAll(ctx context.Context, filter domain.MeasurementFilter, page, limit int) ([]domain.Measurement, error)
+	GetRecent(ctx context.Context, deviceID primitive.ObjectID, parameter string, before time.Time, limit int) ([]domain.Measurement, error)
+	ReplaceReading(ctx context.Context, readingID primitive.ObjectID, measurements []domain.Measurement) error
+}
+
+// SensorCatalogInterface is the interface that wraps the GetDeviceParameters method.
+type SensorCatalogInterface interface {
+	GetDeviceParameters(ctx context.Context, deviceID primitive.ObjectID) (map[string][]domain.DeviceParameter, error)
+}
+
+// UnitRegistryInterface is the interface that wraps the Normalize and Convert methods.
+type UnitRegistryInterface interface {
+	Normalize(m *domain.Measurement) error
+	Convert(parameter, from, to string, value float64) (float64, error)
+}
+
+// QualityCheckerInterface is the interface that wraps the HistorySize and Check methods.
+type QualityCheckerInterface interface {
+	HistorySize() int
+	Check(m domain.Measurement, history []domain.Measurement) []string
+}
+
+// AnomalyDetectorInterface is the interface that wraps the Detect and Record methods.
+type AnomalyDetectorInterface interface {
+	Detect(ctx context.Context, measurements []domain.Measurement) ([]domain.AnomalyEvent, error)
+	Record(ctx context.Context, measurements []domain.Measurement, events []domain.AnomalyEvent) error
+}
+
+// CalibrationRepositoryInterface is the interface that wraps the GetBySensor method.
+type CalibrationRepositoryInterface interface {
+	GetBySensor(ctx context.Context, sensorID primitive.ObjectID, parameter string) ([]domain.Calibration, error)
+}
+
+// MaintenanceRepositoryInterface is the interface that wraps the GetAll method of the work order store.
+type MaintenanceRepositoryInterface interface {
+	GetAll(ctx context.Context, filter domain.MaintenanceFilter, page, limit int) ([]domain.WorkOrder, error)
+}
+
+// DeviceRepositoryInterface is the interface that wraps the GetByID method.
+type DeviceRepositoryInterface interface {
+	GetByID(ctx context.Context, id string) (*domain.Device, error)
+}
+
+// ReadingPublisherInterface is the interface that wraps the PublishMeasurements method.
+type ReadingPublisherInterface interface {
+	PublishMeasurements(ctx context.Context, measurements []domain.Measurement)
+}
+
+// Publishers hands the stored measurements to every publisher, e.g. the GraphQL subscriptions and the metrics
+type Publishers []ReadingPublisherInterface
+
+// PublishMeasurements hands the measurements to each publisher in turn.
+//
+// ctx - context.Context for the operation.
+// measurements - the stored measurements.
+func (p Publishers) PublishMeasurements(ctx context.Context, measurements []domain.Measurement) {
+	for _, publisher := range p {
+		publisher.PublishMeasurements(ctx, measurements)
+	}
+}
+
+// maxDowntimeWorkOrders is the largest number of work orders of a device looked up per batch
+const maxDowntimeWorkOrders = 100
+
+// maxReadingMeasurements is the largest number of measurements of a reading looked up on update
+const maxReadingMeasurements = 1000
+
+// Service is the ingestion pipeline shared by every measurement source.
+type Service struct {
+	measurementRepository MeasurementRepositoryInterface
+	sensorCatalog         SensorCatalogInterface
+	unitRegistry          UnitRegistryInterface
+	qualityChecker        QualityCheckerInterface
+	anomalyDetector       AnomalyDetectorInterface
+	calibrations          CalibrationRepositoryInterface
+	maintenance           MaintenanceRepositoryInterface
+	devices               DeviceRepositoryInterface
+	publisher             ReadingPublisherInterface
+	rangeMode             string
+}
+
+// NewService creates a new instance of the Service struct, initializing it with the provided dependencies.
+//
+// Parameters:
+// - measurementRepository: The MeasurementRepositoryInterface implementation used by the Service.
+// - sensorCatalog: The SensorCatalogInterface implementation resolving the sensor and range of each parameter.
+// - unitRegistry: The UnitRegistryInterface implementation converting values to the canonical unit of their parameter.
+// - qualityChecker: The QualityCheckerInterface implementation running the automated quality checks.
+// - anomalyDetector: The AnomalyDetectorInterface implementation scoring the measurements against their baselines.
+// - calibrations: The CalibrationRepositoryInterface implementation holding the calibrations of the sensors.
+// - maintenance: The MaintenanceRepositoryInterface implementation holding the downtime windows of the devices.
+// - devices: The DeviceRepositoryInterface implementation holding the lifecycle status of the devices.
+// - publisher: The ReadingPublisherInterface implementation notified of the stored measurements.
+// - rangeMode: domain.RangeModeReject to refuse out of range values or domain.RangeModeFlag to store them flagged.
+//
+// Returns:
+// - A pointer to the newly created Service instance.
+func NewService(measurementRepository MeasurementRepositoryInterface, sensorCatalog SensorCatalogInterface, unitRegistry UnitRegistryInterface, qualityChecker QualityCheckerInterface, anomalyDetector AnomalyDetectorInterface, calibrations CalibrationRepositoryInterface, maintenance MaintenanceRepositoryInterface, devices DeviceRepositoryInterface, publisher ReadingPublisherInterface, rangeMode string) *Service {
+	return &Service{
+		measurementRepository: measurementRepository,
+		sensorCatalog:         sensorCatalog,
+		unitRegistry:          unitRegistry,
+		qualityChecker:        qualityChecker,
+		anomalyDetector:       anomalyDetector,
+		calibrations:          calibrations,
+		maintenance:           maintenance,
+		devices:               devices,
+		publisher:             publisher,
+		rangeMode:             rangeMode,
+	}
+}
+
+// Ingest processes and stores a batch of measurements.
+//
+// Measurements of a decommissioned device taken after its decommissioning are refused.
+// Measurements of the same device and timestamp without a reading ID are grouped
+// into one reading. Each measurement is attributed to the device sensor measuring
+// its parameter, converted to the canonical unit of the parameter, corrected by
+// the calibration of the sensor active at its timestamp and checked against
+// that sensor's range and its recent values. Measurements failing a
+// check are stored with the suspect quality flag, the others as raw.
+// Measurements taken during the downtime of a work order of their device are
+// marked as maintenance affected and left out of anomaly detection. Finally
+// the measurements are scored for anomalies against the baseline of their series,
+// and once stored they are added to the baselines and published to the live subscribers.
+//
+// ctx - context.Context for the operation.
+// measurements - the measurements to store.
+// Returns the stored measurements and an error.
+func (s *Service) Ingest(ctx context.Context, measurements []domain.Measurement) ([]domain.Measurement, error) {
+	ctx, span := tracer.Start(ctx, "measurement.Service.Ingest")
+	defer span.End()
+	if err := s.process(ctx, measurements, false); err != nil {
+		return nil, err
+	}
+	events, err := s.anomalyDetector.Detect(ctx, measurements)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.measurementRepository.InsertMany(ctx, measurements); err != nil {
+		return nil, err
+	}
+	if err = s.anomalyDetector.Record(ctx, measurements, events); err != nil {
+		return nil, err
+	}
+	s.publisher.PublishMeasurements(ctx, measurements)
+	return measurements, nil
+}
+
+// ReplaceReading replaces the measurements of the reading with the given ones.
+//
+// A measurement sent with the device, timestamp, unit and value of the stored measurement of its
+// parameter is unchanged and kept as stored, with its ID and quality flag. The changed ones keep
+// the ID of the measurement they replace and are processed like new ones, except that their
+// values are stored as sent without calibration: a reading is updated from the corrected values
+// the API returns, calibrating them again would apply the calibration twice. Changing or removing
+// a measurement a reviewer flagged returns domain.ErrReviewedMeasurement, its review would no
+// longer apply.
+//
+// ctx - context.Context for the operation.
+// readingID - the reading to replace.
+// measurements - the new measurements of the reading.
+// Returns the stored measurements and an error.
+func (s *Service) ReplaceReading(ctx context.Context, readingID primitive.ObjectID, measurements []domain.Measurement) ([]domain.Measurement, error) {
+	ctx, span := tracer.Start(ctx, "measurement.Service.ReplaceReading")
+	defer span.End()
+	stored, err := s.measurementRepository.GetAll(ctx, domain.MeasurementFilter{ReadingID: readingID}, 1, maxReadingMeasurements)
+	if err != nil {
+		return nil, err
+	}
+	previous := make(map[string]domain.Measurement, len(stored))
+	for _, m := range stored {
+		previous[m.Parameter] = m
+	}
+
+	kept := make([]domain.Measurement, 0, len(measurements))
+	changed := make([]domain.Measurement, 0, len(measurements))
+	for _, m := range measurements {
+		m.ReadingID = readingID
+		old, ok := previous[m.Parameter]
+		delete(previous, m.Parameter)
+		if !ok {
+			m.ID = primitive.NilObjectID
+			changed = append(changed, m)
+			continue
+		}
+		if s.unchanged(old, m) {
+			kept = append(kept, old)
+			continue
+		}
+		if domain.Reviewed(old.Quality) {
+			return nil, fmt.Errorf("%w: %s is %s", domain.ErrReviewedMeasurement, old.Parameter, old.Quality)
+		}
+		m.ID = old.ID
+		changed = append(changed, m)
+	}
+	for _, old := range previous {
+		if domain.Reviewed(old.Quality) {
+			return nil, fmt.Errorf("%w: %s is %s", domain.ErrReviewedMeasurement, old.Parameter, old.Quality)
+		}
+	}
+
+	if err = s.process(ctx, changed, true); err != nil {
+		return nil, err
+	}
+	measurements = append(kept, changed...)
+	if err = s.measurementRepository.ReplaceReading(ctx, readingID, measurements); err != nil {
+		return nil, err
+	}
+	return measurements, nil
+}
+
+// unchanged reports whether a measurement sent again for a reading has the device, timestamp
+// and value of the stored one, a value without unit is in the unit of the stored one.
+func (s *Service) unchanged(stored, m domain.Measurement) bool {
+	if m.DeviceID != stored.DeviceID || !m.Timestamp.Equal(stored.Timestamp) {
+		return false
+	}
+	if m.Unit == "" {
+		m.Unit = stored.Unit
+	}
+	if err := s.unitRegistry.Normalize(&m); err != nil || m.Unit != stored.Unit {
+		return false
+	}
+	// Unit conversions may not give back the stored value to the last bit
+	return math.Abs(m.Value-stored.Value) <= 1e-9*math.Max(1, math.Abs(stored.Value))
+}
+
+// GetAll retrieves the measurements matching the filter, newest first.
+//
+// ctx context.Context, filter domain.MeasurementFilter, page int, limit int
+// []domain.Measurement, error
+func (s *Service) GetAll(ctx context.Context, filter domain.MeasurementFilter, page, limit int) ([]domain.Measurement, error) {
+	ctx, span := tracer.Start(ctx, "measurement.Service.GetAll")
+	defer span.End()
+	return s.measurementRepository.GetAll(ctx, filter, page, limit)
+}
+
+// process validates the measurements and fills in reading, sensor, unit, quality and range information.
+//
+// The values are calibrated unless corrected is set, when they are already corrected.
+func (s *Service) process(ctx context.Context, measurements []domain.Measurement, corrected bool) error {
+	type readingKey struct {
+		deviceID  primitive.ObjectID
+		timestamp time.Time
+	}
+	now := time.Now().UTC()
+	readings := map[readingKey]primitive.ObjectID{}
+	parameters := map[primitive.ObjectID]map[string][]domain.DeviceParameter{}
+	histories := map[historyKey][]domain.Measurement{}
+	calibrations := map[calibrationKey][]domain.Calibration{}
+	devices := map[primitive.ObjectID]*domain.Device{}
+	var violations []string
+
+	for i := range measurements {
+		m := &measurements[i]
+		if m.Parameter == "" {
+			return fmt.Errorf("%w: parameter is required", domain.ErrInvalidMeasurement)
+		}
+		if m.ID.IsZero() {
+			m.ID = primitive.NewObjectID()
+		}
+		if m.Timestamp.IsZero() {
+			m.Timestamp = now
+		}
+		if err := s.checkDevice(ctx, devices, m); err != nil {
+			return err
+		}
+		if m.ReadingID.IsZero() {
+			key := readingKey{deviceID: m.DeviceID, timestamp: m.Timestamp}
+			if _, ok := readings[key]; !ok {
+				readings[key] = primitive.NewObjectID()
+			}
+			m.ReadingID = readings[key]
+		}
+		// Quality is only ever raised by reviewers, ingestion starts from raw
+		m.Quality = domain.QualityRaw
+		m.Checks = nil
+		m.OutOfRange = false
+		m.RawValue = nil
+		m.CalibrationID = primitive.NilObjectID
+		m.Maintenance = false
+		m.WorkOrderID = primitive.NilObjectID
+
+		parameter, ok, err := s.deviceParameter(ctx, parameters, m)
+		if err != nil {
+			return err
+		}
+		if ok {
+			if m.SensorID.IsZero() {
+				m.SensorID = parameter.SensorID
+			}
+			if m.Unit == "" {
+				m.Unit = parameter.Unit
+			}
+		}
+		if err = s.unitRegistry.Normalize(m); err != nil {
+			return err
+		}
+		if !corrected {
+			if err = s.calibrate(ctx, calibrations, m); err != nil {
+				return err
+			}
+		}
+
+		if ok {
+			spec := s.canonicalSpec(parameter.ParameterSpec, m.Unit)
+			if !spec.InRange(m.Value) {
+				violation := domain.RangeViolation{Parameter: m.Parameter, Value: m.Value, Min: spec.Min, Max: spec.Max}
+				violations = append(violations, violation.String())
+				m.OutOfRange = true
+				m.Checks = append(m.Checks, domain.CheckRange)
+			}
+		}
+		if err = s.checkHistory(ctx, histories, m); err != nil {
+			return err
+		}
+		if len(m.Checks) > 0 {
+			m.Quality = domain.QualitySuspect
+		}
+	}
+
+	if err := s.markMaintenance(ctx, measurements); err != nil {
+		return err
+	}
+	if len(violations) > 0 && s.rangeMode == domain.RangeModeReject {
+		return fmt.Errorf("%w: %s", domain.ErrOutOfRange, strings.Join(violations, ", "))
+	}
+	return nil
+}
+
+// checkDevice refuses the measurement when its device is decommissioned, caching the devices of the batch.
+//
+// Measurements of unknown devices are accepted.
+func (s *Service) checkDevice(ctx context.Context, cache map[primitive.ObjectID]*domain.Device, m *domain.Measurement) error {
+	if m.DeviceID.IsZero() {
+		return nil
+	}
+	device, ok := cache[m.DeviceID]
+	if !ok {
+		var err error
+		device, err = s.devices.GetByID(ctx, m.DeviceID.Hex())
+		if err != nil {
+			return err
+		}
+		cache[m.DeviceID] = device
+	}
+	if device != nil && !device.AcceptsData(m.Timestamp) {
+		return fmt.Errorf("%w: %s", domain.ErrDeviceDecommissioned, m.DeviceID.Hex())
+	}
+	return nil
+}
+
+// historyKey identifies the series of a parameter of a device
+type historyKey struct {
+	deviceID  primitive.ObjectID
+	parameter string
+}
+
+// checkHistory runs the automated checks against the recent values of the series
+// and appends the measurement to the series for the next measurements of the batch.
+func (s *Service) checkHistory(ctx context.Context, histories map[historyKey][]domain.Measurement, m *domain.Measurement) error {
+	size := s.qualityChecker.HistorySize()
+	if m.DeviceID.IsZero() || size <= 0 {
+		return nil
+	}
+	key := historyKey{deviceID: m.DeviceID, parameter: m.Parameter}
+	history, ok := histories[key]
+	if !ok {
+		recent, err := s.measurementRepository.GetRecent(ctx, m.DeviceID, m.Parameter, m.Timestamp, size)
+		if err != nil {
+			return err
+		}
+		// The repository returns the newest first, the checks expect the oldest first
+		history = make([]domain.Measurement, len(recent))
+		for i, r := range recent {
+			history[len(recent)-1-i] = r
+		}
+	}
+	m.Checks = append(m.Checks, s.qualityChecker.Check(*m, history)...)
+
+	history = append(history, *m)
+	if len(history) > size {
+		history = history[len(history)-size:]
+	}
+	histories[key] = history
+	return nil
+}
+
+// markMaintenance marks the measurements taken during the downtime of a work order of their device.
+func (s *Service) markMaintenance(ctx context.Context, measurements []domain.Measurement) error {
+	periods := map[primitive.ObjectID]*domain.TimeWindow{}
+	var devices []primitive.ObjectID
+	for _, m := range measurements {
+		if m.DeviceID.IsZero() {
+			continue
+		}
+		period, ok := periods[m.DeviceID]
+		if !ok {
+			periods[m.DeviceID] = &domain.TimeWindow{Start: m.Timestamp, End: m.Timestamp}
+			devices = append(devices, m.DeviceID)
+			continue
+		}
+		if m.Timestamp.Before(period.Start) {
+			period.Start = m.Timestamp
+		}
+		if m.Timestamp.After(period.End) {
+			period.End = m.Timestamp
+		}
+	}
+
+	downtimes := map[primitive.ObjectID][]domain.WorkOrder{}
+	for _, deviceID := range devices {
+		period := periods[deviceID]
+		filter := domain.MaintenanceFilter{DeviceID: deviceID, From: period.Start, To: period.End}
+		workOrders, err := s.maintenance.GetAll(ctx, filter, 1, maxDowntimeWorkOrders)
+		if err != nil {
+			return err
+		}
+		for _, w := range workOrders {
+			if w.Downtime != nil {
+				downtimes[deviceID] = append(downtimes[deviceID], w)
+			}
+		}
+	}
+
+	for i := range measurements {
+		m := &measurements[i]
+		for _, w := range downtimes[m.DeviceID] {
+			if w.Downtime.Contains(m.Timestamp) {
+				m.Maintenance = true
+				m.WorkOrderID = w.ID
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// calibrationKey identifies the calibrations of a parameter of a sensor
+type calibrationKey struct {
+	sensorID  primitive.ObjectID
+	parameter string
+}
+
+// calibrate applies the calibration of the sensor active at the timestamp of the
+// measurement, caching the calibrations of each sensor parameter.
+func (s *Service) calibrate(ctx context.Context, cache map[calibrationKey][]domain.Calibration, m *domain.Measurement) error {
+	if m.SensorID.IsZero() {
+		return nil
+	}
+	key := calibrationKey{sensorID: m.SensorID, parameter: m.Parameter}
+	calibrations, ok := cache[key]
+	if !ok {
+		var err error
+		calibrations, err = s.calibrations.GetBySensor(ctx, m.SensorID, m.Parameter)
+		if err != nil {
+			return err
+		}
+		cache[key] = calibrations
+	}
+	active := domain.ActiveCalibration(calibrations, m.Timestamp)
+	if active == nil {
+		return nil
+	}
+	raw := m.Value
+	m.RawValue = &raw
+	m.Value = active.Apply(raw)
+	m.CalibrationID = active.ID
+	return nil
+}
+
+// deviceParameter resolves the device parameter of a measurement, caching the parameters of each device.
+//
+// A measurement with a sensor gets the spec of that sensor. Without a sensor it gets the spec of
+// the only sensor of the device measuring its parameter, when several do the sensor is required.
+func (s *Service) deviceParameter(ctx context.Context, cache map[primitive.ObjectID]map[string][]domain.DeviceParameter, m *domain.Measurement) (domain.DeviceParameter, bool, error) {
+	if m.DeviceID.IsZero() {
+		return domain.DeviceParameter{}, false, nil
+	}
+	deviceParameters, ok := cache[m.DeviceID]
+	if !ok {
+		var err error
+		deviceParameters, err = s.sensorCatalog.GetDeviceParameters(ctx, m.DeviceID)
+		if err != nil {
+			return domain.DeviceParameter{}, false, err
+		}
+		cache[m.DeviceID] = deviceParameters
+	}
+	candidates := deviceParameters[m.Parameter]
+	if !m.SensorID.IsZero() {
+		for _, parameter := range candidates {
+			if parameter.SensorID == m.SensorID {
+				return parameter, true, nil
+			}
+		}
+		return domain.DeviceParameter{}, false, nil
+	}
+	switch len(candidates) {
+	case 0:
+		return domain.DeviceParameter{}, false, nil
+	case 1:
+		return candidates[0], true, nil
+	default:
+		return domain.DeviceParameter{}, false, fmt.Errorf("%w: %s is measured by %d sensors of device %s, sensor_id is required", domain.ErrInvalidMeasurement, m.Parameter, len(candidates), m.DeviceID.Hex())
+	}
+}
+
+// canonicalSpec converts the range of a spec to the unit of the measurement,
+// specs in a unit the registry cannot convert are returned unchanged.
+func (s *Service) canonicalSpec(spec domain.ParameterSpec, unit string) domain.ParameterSpec {
+	if spec.Unit == "" || spec.Unit == unit {
+		return spec
+	}
+	lower, err := s.unitRegistry.Convert(spec.Parameter, spec.Unit, unit, spec.Min)
+	if err != nil {
+		return spec
+	}
+	upper, err := s.unitRegistry.Convert(spec.Parameter, spec.Unit, unit, spec.Max)
+	if err != nil {
+		return spec
+	}
+	spec.Min, spec.Max, spec.Unit = lower, upper, unit
+	return spec
+}
@@ -0,0 +1,543 @@
+package measurement_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/measurement"
+	"github.com/anggi-susanto/mrt-go/measurement/mocks"
+	"github.com/anggi-susanto/mrt-go/quality"
+	"github.com/anggi-susanto/mrt-go/units"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// noChecks disables the history based quality checks
+var noChecks = quality.NewChecker(config.QualityConfig{})
+
+// noAnomalies returns an anomaly detector scoring nothing
+func noAnomalies() *mocks.AnomalyDetectorInterface {
+	detector := new(mocks.AnomalyDetectorInterface)
+	detector.On("Detect", mock.Anything, mock.Anything).Return(nil, nil)
+	detector.On("Record", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	return detector
+}
+
+func noCalibrations() *mocks.CalibrationRepositoryInterface {
+	calibrations := new(mocks.CalibrationRepositoryInterface)
+	calibrations.On("GetBySensor", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	return calibrations
+}
+
+func noMaintenance() *mocks.MaintenanceRepositoryInterface {
+	maintenance := new(mocks.MaintenanceRepositoryInterface)
+	maintenance.On("GetAll", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	return maintenance
+}
+
+func noDevices() *mocks.DeviceRepositoryInterface {
+	devices := new(mocks.DeviceRepositoryInterface)
+	devices.On("GetByID", mock.Anything, mock.Anything).Return(nil, nil)
+	return devices
+}
+
+func noPublisher() *mocks.ReadingPublisherInterface {
+	publisher := new(mocks.ReadingPublisherInterface)
+	publisher.On("PublishMeasurements", mock.Anything, mock.Anything).Return()
+	return publisher
+}
+
+func TestServiceIngest(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	sensorID := primitive.NewObjectID()
+	parameters := map[string][]domain.DeviceParameter{
+		domain.ParamPH: {{SensorID: sensorID, ParameterSpec: domain.ParameterSpec{Parameter: domain.ParamPH, Unit: "pH", Min: 0, Max: 14}}},
+	}
+	timestamp := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MeasurementRepositoryInterface)
+		mockCatalog := new(mocks.SensorCatalogInterface)
+		mockCatalog.On("GetDeviceParameters", mock.Anything, deviceID).Return(parameters, nil).Once()
+		mockRepo.On("InsertMany", mock.Anything, mock.Anything).Return(nil)
+		mockPublisher := new(mocks.ReadingPublisherInterface)
+		mockPublisher.On("PublishMeasurements", mock.Anything, mock.MatchedBy(func(measurements []domain.Measurement) bool {
+			return len(measurements) == 3 && !measurements[0].ReadingID.IsZero()
+		})).Return().Once()
+		s := measurement.NewService(mockRepo, mockCatalog, units.Default, noChecks, noAnomalies(), noCalibrations(), noMaintenance(), noDevices(), mockPublisher, domain.RangeModeFlag)
+		stored, err := s.Ingest(context.Background(), []domain.Measurement{
+			{DeviceID: deviceID, Timestamp: timestamp, Parameter: domain.ParamPH, Value: 7},
+			{DeviceID: deviceID, Timestamp: timestamp, Parameter: "TSS", Value: 35, Unit: "mg/L"},
+			{DeviceID: deviceID, Timestamp: timestamp.Add(time.Minute), Parameter: domain.ParamPH, Value: 7.1},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, sensorID, stored[0].SensorID)
+		assert.Equal(t, "pH", stored[0].Unit)
+		assert.Equal(t, domain.QualityRaw, stored[0].Quality)
+		assert.True(t, stored[1].SensorID.IsZero())
+		assert.Equal(t, "mg/L", stored[1].Unit)
+		assert.Equal(t, stored[0].ReadingID, stored[1].ReadingID)
+		assert.NotEqual(t, stored[0].ReadingID, stored[2].ReadingID)
+		mockCatalog.AssertExpectations(t)
+		mockPublisher.AssertExpectations(t)
+	})
+	t.Run("Missing parameter", func(t *testing.T) {
+		mockRepo := new(mocks.MeasurementRepositoryInterface)
+		s := measurement.NewService(mockRepo, new(mocks.SensorCatalogInterface), units.Default, noChecks, noAnomalies(), noCalibrations(), noMaintenance(), noDevices(), noPublisher(), domain.RangeModeFlag)
+		_, err := s.Ingest(context.Background(), []domain.Measurement{{Value: 1}})
+		assert.ErrorIs(t, err, domain.ErrInvalidMeasurement)
+		mockRepo.AssertNotCalled(t, "InsertMany")
+	})
+	t.Run("Error", func(t *testing.T) {
+		mockRepo := new(mocks.MeasurementRepositoryInterface)
+		mockRepo.On("InsertMany", mock.Anything, mock.Anything).Return(errors.New("error"))
+		mockPublisher := noPublisher()
+		s := measurement.NewService(mockRepo, new(mocks.SensorCatalogInterface), units.Default, noChecks, noAnomalies(), noCalibrations(), noMaintenance(), noDevices(), mockPublisher, domain.RangeModeFlag)
+		stored, err := s.Ingest(context.Background(), []domain.Measurement{{Parameter: domain.ParamPH, Value: 7}})
+		assert.Error(t, err)
+		assert.Nil(t, stored)
+		mockPublisher.AssertNotCalled(t, "PublishMeasurements", mock.Anything, mock.Anything)
+	})
+}
+
+func TestServiceIngestOutOfRange(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	parameters := map[string][]domain.DeviceParameter{
+		domain.ParamPH: {{ParameterSpec: domain.ParameterSpec{Parameter: domain.ParamPH, Min: 0, Max: 14}}},
+	}
+	t.Run("Reject", func(t *testing.T) {
+		mockRepo := new(mocks.MeasurementRepositoryInterface)
+		mockCatalog := new(mocks.SensorCatalogInterface)
+		mockCatalog.On("GetDeviceParameters", mock.Anything, deviceID).Return(parameters, nil)
+		s := measurement.NewService(mockRepo, mockCatalog, units.Default, noChecks, noAnomalies(), noCalibrations(), noMaintenance(), noDevices(), noPublisher(), domain.RangeModeReject)
+		_, err := s.Ingest(context.Background(), []domain.Measurement{{DeviceID: deviceID, Parameter: domain.ParamPH, Value: 15}})
+		assert.ErrorIs(t, err, domain.ErrOutOfRange)
+		assert.Contains(t, err.Error(), "pH=15 outside [0, 14]")
+		mockRepo.AssertNotCalled(t, "InsertMany")
+	})
+	t.Run("Flag", func(t *testing.T) {
+		mockRepo := new(mocks.MeasurementRepositoryInterface)
+		mockCatalog := new(mocks.SensorCatalogInterface)
+		mockCatalog.On("GetDeviceParameters", mock.Anything, deviceID).Return(parameters, nil)
+		mockRepo.On("InsertMany", mock.Anything, mock.Anything).Return(nil)
+		s := measurement.NewService(mockRepo, mockCatalog, units.Default, noChecks, noAnomalies(), noCalibrations(), noMaintenance(), noDevices(), noPublisher(), domain.RangeModeFlag)
+		stored, err := s.Ingest(context.Background(), []domain.Measurement{{DeviceID: deviceID, Parameter: domain.ParamPH, Value: 15}})
+		assert.NoError(t, err)
+		assert.True(t, stored[0].OutOfRange)
+		assert.Equal(t, []string{domain.CheckRange}, stored[0].Checks)
+		assert.Equal(t, domain.QualitySuspect, stored[0].Quality)
+	})
+}
+
+func TestServiceIngestSeveralSensors(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	oxygenSensorID := primitive.NewObjectID()
+	conductivitySensorID := primitive.NewObjectID()
+	parameters := map[string][]domain.DeviceParameter{
+		domain.ParamTemperature: {
+			{SensorID: oxygenSensorID, ParameterSpec: domain.ParameterSpec{Parameter: domain.ParamTemperature, Min: 0, Max: 50}},
+			{SensorID: conductivitySensorID, ParameterSpec: domain.ParameterSpec{Parameter: domain.ParamTemperature, Min: -5, Max: 60}},
+		},
+	}
+	t.Run("Spec of the sensor", func(t *testing.T) {
+		mockRepo := new(mocks.MeasurementRepositoryInterface)
+		mockCatalog := new(mocks.SensorCatalogInterface)
+		mockCatalog.On("GetDeviceParameters", mock.Anything, deviceID).Return(parameters, nil)
+		mockRepo.On("InsertMany", mock.Anything, mock.Anything).Return(nil)
+		s := measurement.NewService(mockRepo, mockCatalog, units.Default, noChecks, noAnomalies(), noCalibrations(), noMaintenance(), noDevices(), noPublisher(), domain.RangeModeFlag)
+		stored, err := s.Ingest(context.Background(), []domain.Measurement{
+			{DeviceID: deviceID, SensorID: oxygenSensorID, Parameter: domain.ParamTemperature, Value: 55},
+			{DeviceID: deviceID, SensorID: conductivitySensorID, Parameter: domain.ParamTemperature, Value: 55},
+		})
+		assert.NoError(t, err)
+		assert.True(t, stored[0].OutOfRange)
+		assert.False(t, stored[1].OutOfRange)
+	})
+	t.Run("Sensor required", func(t *testing.T) {
+		mockRepo := new(mocks.MeasurementRepositoryInterface)
+		mockCatalog := new(mocks.SensorCatalogInterface)
+		mockCatalog.On("GetDeviceParameters", mock.Anything, deviceID).Return(parameters, nil)
+		s := measurement.NewService(mockRepo, mockCatalog, units.Default, noChecks, noAnomalies(), noCalibrations(), noMaintenance(), noDevices(), noPublisher(), domain.RangeModeFlag)
+		_, err := s.Ingest(context.Background(), []domain.Measurement{{DeviceID: deviceID, Parameter: domain.ParamTemperature, Value: 20}})
+		assert.ErrorIs(t, err, domain.ErrInvalidMeasurement)
+		assert.Contains(t, err.Error(), "sensor_id is required")
+		mockRepo.AssertNotCalled(t, "InsertMany")
+	})
+}
+
+func TestServiceIngestUnits(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	parameters := map[string][]domain.DeviceParameter{
+		domain.ParamTemperature:   {{ParameterSpec: domain.ParameterSpec{Parameter: domain.ParamTemperature, Unit: "°C", Min: 0, Max: 50}}},
+		domain.ParamECSalinityTDS: {{ParameterSpec: domain.ParameterSpec{Parameter: domain.ParamECSalinityTDS, Unit: "mS/cm", Min: 0, Max: 200}}},
+	}
+	t.Run("Converted to canonical units", func(t *testing.T) {
+		mockRepo := new(mocks.MeasurementRepositoryInterface)
+		mockCatalog := new(mocks.SensorCatalogInterface)
+		mockCatalog.On("GetDeviceParameters", mock.Anything, deviceID).Return(parameters, nil)
+		mockRepo.On("InsertMany", mock.Anything, mock.Anything).Return(nil)
+		s := measurement.NewService(mockRepo, mockCatalog, units.Default, noChecks, noAnomalies(), noCalibrations(), noMaintenance(), noDevices(), noPublisher(), domain.RangeModeFlag)
+		stored, err := s.Ingest(context.Background(), []domain.Measurement{
+			{DeviceID: deviceID, Parameter: domain.ParamTemperature, Value: 77, Unit: "°F"},
+			{DeviceID: deviceID, Parameter: domain.ParamECSalinityTDS, Value: 1.2},
+		})
+		assert.NoError(t, err)
+		assert.InDelta(t, 25, stored[0].Value, 1e-9)
+		assert.Equal(t, "°C", stored[0].Unit)
+		assert.InDelta(t, 1200, stored[1].Value, 1e-9)
+		assert.Equal(t, "µS/cm", stored[1].Unit)
+		assert.False(t, stored[1].OutOfRange)
+	})
+	t.Run("Range checked in canonical units", func(t *testing.T) {
+		mockCatalog := new(mocks.SensorCatalogInterface)
+		mockCatalog.On("GetDeviceParameters", mock.Anything, deviceID).Return(parameters, nil)
+		s := measurement.NewService(new(mocks.MeasurementRepositoryInterface), mockCatalog, units.Default, noChecks, noAnomalies(), noCalibrations(), noMaintenance(), noDevices(), noPublisher(), domain.RangeModeReject)
+		_, err := s.Ingest(context.Background(), []domain.Measurement{
+			{DeviceID: deviceID, Parameter: domain.ParamECSalinityTDS, Value: 250000, Unit: "µS/cm"},
+		})
+		assert.ErrorIs(t, err, domain.ErrOutOfRange)
+		assert.Contains(t, err.Error(), "outside [0, 200000]")
+	})
+	t.Run("Unknown unit", func(t *testing.T) {
+		mockRepo := new(mocks.MeasurementRepositoryInterface)
+		s := measurement.NewService(mockRepo, new(mocks.SensorCatalogInterface), units.Default, noChecks, noAnomalies(), noCalibrations(), noMaintenance(), noDevices(), noPublisher(), domain.RangeModeFlag)
+		_, err := s.Ingest(context.Background(), []domain.Measurement{{Parameter: domain.ParamTemperature, Value: 10, Unit: "°R"}})
+		assert.ErrorIs(t, err, domain.ErrUnknownUnit)
+		mockRepo.AssertNotCalled(t, "InsertMany")
+	})
+}
+
+func TestServiceIngestQualityChecks(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	checker := quality.NewChecker(config.QualityConfig{
+		HistorySize:     10,
+		SpikeFactor:     4,
+		FlatlineCount:   3,
+		MaxRateOfChange: map[string]float64{domain.ParamTemperature: 1},
+	})
+	// recent temperatures, newest first as returned by the repository
+	recent := []domain.Measurement{
+		{Parameter: domain.ParamTemperature, Timestamp: start.Add(-1 * time.Minute), Value: 20.2},
+		{Parameter: domain.ParamTemperature, Timestamp: start.Add(-2 * time.Minute), Value: 20.1},
+		{Parameter: domain.ParamTemperature, Timestamp: start.Add(-3 * time.Minute), Value: 20.3},
+		{Parameter: domain.ParamTemperature, Timestamp: start.Add(-4 * time.Minute), Value: 20.0},
+		{Parameter: domain.ParamTemperature, Timestamp: start.Add(-5 * time.Minute), Value: 20.2},
+	}
+	t.Run("Spike and rate of change", func(t *testing.T) {
+		mockRepo := new(mocks.MeasurementRepositoryInterface)
+		mockCatalog := new(mocks.SensorCatalogInterface)
+		mockCatalog.On("GetDeviceParameters", mock.Anything, deviceID).Return(map[string][]domain.DeviceParameter{}, nil)
+		mockRepo.On("GetRecent", mock.Anything, deviceID, domain.ParamTemperature, start, 10).Return(recent, nil).Once()
+		mockRepo.On("InsertMany", mock.Anything, mock.Anything).Return(nil)
+		s := measurement.NewService(mockRepo, mockCatalog, units.Default, checker, noAnomalies(), noCalibrations(), noMaintenance(), noDevices(), noPublisher(), domain.RangeModeFlag)
+		stored, err := s.Ingest(context.Background(), []domain.Measurement{
+			{DeviceID: deviceID, Timestamp: start, Parameter: domain.ParamTemperature, Value: 35},
+			{DeviceID: deviceID, Timestamp: start.Add(time.Minute), Parameter: domain.ParamTemperature, Value: 35},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{domain.CheckSpike, domain.CheckRateOfChange}, stored[0].Checks)
+		assert.Equal(t, domain.QualitySuspect, stored[0].Quality)
+		assert.NotContains(t, stored[1].Checks, domain.CheckRateOfChange)
+		mockRepo.AssertExpectations(t)
+	})
+	t.Run("Flatline", func(t *testing.T) {
+		mockRepo := new(mocks.MeasurementRepositoryInterface)
+		mockCatalog := new(mocks.SensorCatalogInterface)
+		mockCatalog.On("GetDeviceParameters", mock.Anything, deviceID).Return(map[string][]domain.DeviceParameter{}, nil)
+		mockRepo.On("GetRecent", mock.Anything, deviceID, domain.ParamPH, start, 10).Return([]domain.Measurement{
+			{Parameter: domain.ParamPH, Timestamp: start.Add(-time.Minute), Value: 7},
+			{Parameter: domain.ParamPH, Timestamp: start.Add(-2 * time.Minute), Value: 7},
+		}, nil)
+		mockRepo.On("InsertMany", mock.Anything, mock.Anything).Return(nil)
+		s := measurement.NewService(mockRepo, mockCatalog, units.Default, checker, noAnomalies(), noCalibrations(), noMaintenance(), noDevices(), noPublisher(), domain.RangeModeFlag)
+		stored, err := s.Ingest(context.Background(), []domain.Measurement{
+			{DeviceID: deviceID, Timestamp: start, Parameter: domain.ParamPH, Value: 7, Quality: domain.QualityApproved},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{domain.CheckFlatline}, stored[0].Checks)
+		assert.Equal(t, domain.QualitySuspect, stored[0].Quality)
+	})
+	t.Run("History error", func(t *testing.T) {
+		mockRepo := new(mocks.MeasurementRepositoryInterface)
+		mockCatalog := new(mocks.SensorCatalogInterface)
+		mockCatalog.On("GetDeviceParameters", mock.Anything, deviceID).Return(map[string][]domain.DeviceParameter{}, nil)
+		mockRepo.On("GetRecent", mock.Anything, deviceID, domain.ParamPH, start, 10).Return(nil, errors.New("error"))
+		s := measurement.NewService(mockRepo, mockCatalog, units.Default, checker, noAnomalies(), noCalibrations(), noMaintenance(), noDevices(), noPublisher(), domain.RangeModeFlag)
+		_, err := s.Ingest(context.Background(), []domain.Measurement{{DeviceID: deviceID, Timestamp: start, Parameter: domain.ParamPH, Value: 7}})
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "InsertMany")
+	})
+}
+
+func TestServiceIngestAnomalies(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	events := []domain.AnomalyEvent{{Parameter: domain.ParamCOD, Method: domain.AnomalyMAD}}
+	t.Run("Events recorded after storing", func(t *testing.T) {
+		mockRepo := new(mocks.MeasurementRepositoryInterface)
+		mockCatalog := new(mocks.SensorCatalogInterface)
+		mockDetector := new(mocks.AnomalyDetectorInterface)
+		mockCatalog.On("GetDeviceParameters", mock.Anything, deviceID).Return(map[string][]domain.DeviceParameter{}, nil)
+		mockDetector.On("Detect", mock.Anything, mock.MatchedBy(func(ms []domain.Measurement) bool {
+			return len(ms) == 1 && !ms[0].ID.IsZero()
+		})).Return(events, nil)
+		mockRepo.On("InsertMany", mock.Anything, mock.Anything).Return(nil)
+		mockDetector.On("Record", mock.Anything, mock.Anything, events).Return(nil)
+		s := measurement.NewService(mockRepo, mockCatalog, units.Default, noChecks, mockDetector, noCalibrations(), noMaintenance(), noDevices(), noPublisher(), domain.RangeModeFlag)
+		_, err := s.Ingest(context.Background(), []domain.Measurement{{DeviceID: deviceID, Parameter: domain.ParamCOD, Value: 900}})
+		assert.NoError(t, err)
+		mockDetector.AssertExpectations(t)
+	})
+	t.Run("Detect error", func(t *testing.T) {
+		mockRepo := new(mocks.MeasurementRepositoryInterface)
+		mockDetector := new(mocks.AnomalyDetectorInterface)
+		mockDetector.On("Detect", mock.Anything, mock.Anything).Return(nil, errors.New("error"))
+		s := measurement.NewService(mockRepo, new(mocks.SensorCatalogInterface), units.Default, noChecks, mockDetector, noCalibrations(), noMaintenance(), noDevices(), noPublisher(), domain.RangeModeFlag)
+		_, err := s.Ingest(context.Background(), []domain.Measurement{{Parameter: domain.ParamCOD, Value: 900}})
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "InsertMany")
+	})
+	t.Run("Store error", func(t *testing.T) {
+		mockRepo := new(mocks.MeasurementRepositoryInterface)
+		mockDetector := new(mocks.AnomalyDetectorInterface)
+		mockDetector.On("Detect", mock.Anything, mock.Anything).Return(events, nil)
+		mockRepo.On("InsertMany", mock.Anything, mock.Anything).Return(errors.New("duplicate key"))
+		s := measurement.NewService(mockRepo, new(mocks.SensorCatalogInterface), units.Default, noChecks, mockDetector, noCalibrations(), noMaintenance(), noDevices(), noPublisher(), domain.RangeModeFlag)
+		_, err := s.Ingest(context.Background(), []domain.Measurement{{Parameter: domain.ParamCOD, Value: 900}})
+		assert.Error(t, err)
+		mockDetector.AssertNotCalled(t, "Record")
+	})
+}
+
+func TestServiceReplaceReading(t *testing.T) {
+	readingID := primitive.NewObjectID()
+	deviceID := primitive.NewObjectID()
+	timestamp := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	filter := domain.MeasurementFilter{ReadingID: readingID}
+	stored := []domain.Measurement{
+		{ID: primitive.NewObjectID(), ReadingID: readingID, DeviceID: deviceID, Timestamp: timestamp, Parameter: domain.ParamPH, Value: 7.2, Unit: "pH", Quality: domain.QualityValidated},
+		{ID: primitive.NewObjectID(), ReadingID: readingID, DeviceID: deviceID, Timestamp: timestamp, Parameter: domain.ParamBOD, Value: 10, Unit: "mg/L", Quality: domain.QualitySuspect},
+	}
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MeasurementRepositoryInterface)
+		mockRepo.On("GetAll", mock.Anything, filter, 1, mock.Anything).Return(nil, nil)
+		mockRepo.On("ReplaceReading", mock.Anything, readingID, mock.MatchedBy(func(ms []domain.Measurement) bool {
+			return len(ms) == 1 && ms[0].ReadingID == readingID
+		})).Return(nil)
+		s := measurement.NewService(mockRepo, new(mocks.SensorCatalogInterface), units.Default, noChecks, noAnomalies(), noCalibrations(), noMaintenance(), noDevices(), noPublisher(), domain.RangeModeFlag)
+		_, err := s.ReplaceReading(context.Background(), readingID, []domain.Measurement{{Parameter: domain.ParamBOD, Value: 10}})
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+	t.Run("Unchanged measurements kept", func(t *testing.T) {
+		mockRepo := new(mocks.MeasurementRepositoryInterface)
+		mockRepo.On("GetAll", mock.Anything, filter, 1, mock.Anything).Return(stored, nil)
+		mockRepo.On("ReplaceReading", mock.Anything, readingID, mock.Anything).Return(nil)
+		mockCatalog := new(mocks.SensorCatalogInterface)
+		mockCatalog.On("GetDeviceParameters", mock.Anything, deviceID).Return(map[string][]domain.DeviceParameter{}, nil)
+		s := measurement.NewService(mockRepo, mockCatalog, units.Default, noChecks, noAnomalies(), noCalibrations(), noMaintenance(), noDevices(), noPublisher(), domain.RangeModeFlag)
+		replaced, err := s.ReplaceReading(context.Background(), readingID, []domain.Measurement{
+			{DeviceID: deviceID, Timestamp: timestamp, Parameter: domain.ParamPH, Value: 7.2},
+			{DeviceID: deviceID, Timestamp: timestamp, Parameter: domain.ParamBOD, Value: 12},
+		})
+		assert.NoError(t, err)
+		require.Len(t, replaced, 2)
+		assert.Equal(t, stored[0], replaced[0])
+		assert.Equal(t, stored[1].ID, replaced[1].ID)
+		assert.Equal(t, float64(12), replaced[1].Value)
+		assert.Equal(t, domain.QualityRaw, replaced[1].Quality)
+	})
+	t.Run("Changed measurement not calibrated again", func(t *testing.T) {
+		sensorID := primitive.NewObjectID()
+		raw := 9.0
+		calibrated := domain.Measurement{ID: primitive.NewObjectID(), ReadingID: readingID, DeviceID: deviceID, SensorID: sensorID, Timestamp: timestamp,
+			Parameter: domain.ParamBOD, Value: 10, Unit: "mg/L", RawValue: &raw, CalibrationID: primitive.NewObjectID(), Quality: domain.QualityRaw}
+		mockRepo := new(mocks.MeasurementRepositoryInterface)
+		mockRepo.On("GetAll", mock.Anything, filter, 1, mock.Anything).Return([]domain.Measurement{calibrated}, nil)
+		mockRepo.On("ReplaceReading", mock.Anything, readingID, mock.Anything).Return(nil)
+		mockCatalog := new(mocks.SensorCatalogInterface)
+		mockCatalog.On("GetDeviceParameters", mock.Anything, deviceID).Return(map[string][]domain.DeviceParameter{}, nil)
+		// The calibrations are not read, the mock has no expectations
+		s := measurement.NewService(mockRepo, mockCatalog, units.Default, noChecks, noAnomalies(), new(mocks.CalibrationRepositoryInterface), noMaintenance(), noDevices(), noPublisher(), domain.RangeModeFlag)
+		replaced, err := s.ReplaceReading(context.Background(), readingID, []domain.Measurement{
+			{DeviceID: deviceID, SensorID: sensorID, Timestamp: timestamp, Parameter: domain.ParamBOD, Value: 11},
+		})
+		assert.NoError(t, err)
+		require.Len(t, replaced, 1)
+		assert.Equal(t, float64(11), replaced[0].Value)
+		assert.Nil(t, replaced[0].RawValue)
+		assert.True(t, replaced[0].CalibrationID.IsZero())
+	})
+	t.Run("Reviewed measurement changed", func(t *testing.T) {
+		mockRepo := new(mocks.MeasurementRepositoryInterface)
+		mockRepo.On("GetAll", mock.Anything, filter, 1, mock.Anything).Return(stored, nil)
+		s := measurement.NewService(mockRepo, new(mocks.SensorCatalogInterface), units.Default, noChecks, noAnomalies(), noCalibrations(), noMaintenance(), noDevices(), noPublisher(), domain.RangeModeFlag)
+		_, err := s.ReplaceReading(context.Background(), readingID, []domain.Measurement{
+			{DeviceID: deviceID, Timestamp: timestamp, Parameter: domain.ParamPH, Value: 7.4},
+			{DeviceID: deviceID, Timestamp: timestamp, Parameter: domain.ParamBOD, Value: 10},
+		})
+		assert.ErrorIs(t, err, domain.ErrReviewedMeasurement)
+		mockRepo.AssertNotCalled(t, "ReplaceReading")
+	})
+	t.Run("Reviewed measurement removed", func(t *testing.T) {
+		mockRepo := new(mocks.MeasurementRepositoryInterface)
+		mockRepo.On("GetAll", mock.Anything, filter, 1, mock.Anything).Return(stored, nil)
+		s := measurement.NewService(mockRepo, new(mocks.SensorCatalogInterface), units.Default, noChecks, noAnomalies(), noCalibrations(), noMaintenance(), noDevices(), noPublisher(), domain.RangeModeFlag)
+		_, err := s.ReplaceReading(context.Background(), readingID, []domain.Measurement{
+			{DeviceID: deviceID, Timestamp: timestamp, Parameter: domain.ParamBOD, Value: 10},
+		})
+		assert.ErrorIs(t, err, domain.ErrReviewedMeasurement)
+		mockRepo.AssertNotCalled(t, "ReplaceReading")
+	})
+	t.Run("Error", func(t *testing.T) {
+		mockRepo := new(mocks.MeasurementRepositoryInterface)
+		mockRepo.On("GetAll", mock.Anything, filter, 1, mock.Anything).Return(nil, nil)
+		mockRepo.On("ReplaceReading", mock.Anything, readingID, mock.Anything).Return(errors.New("error"))
+		s := measurement.NewService(mockRepo, new(mocks.SensorCatalogInterface), units.Default, noChecks, noAnomalies(), noCalibrations(), noMaintenance(), noDevices(), noPublisher(), domain.RangeModeFlag)
+		_, err := s.ReplaceReading(context.Background(), readingID, []domain.Measurement{{Parameter: domain.ParamBOD, Value: 10}})
+		assert.Error(t, err)
+	})
+}
+
+func TestServiceGetAll(t *testing.T) {
+	filter := domain.MeasurementFilter{Parameter: domain.ParamPH}
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MeasurementRepositoryInterface)
+		mockRepo.On("GetAll", mock.Anything, filter, 1, 10).Return([]domain.Measurement{{Parameter: domain.ParamPH}}, nil)
+		s := measurement.NewService(mockRepo, new(mocks.SensorCatalogInterface), units.Default, noChecks, noAnomalies(), noCalibrations(), noMaintenance(), noDevices(), noPublisher(), domain.RangeModeFlag)
+		data, err := s.GetAll(context.Background(), filter, 1, 10)
+		assert.NoError(t, err)
+		assert.Len(t, data, 1)
+	})
+	t.Run("Error", func(t *testing.T) {
+		mockRepo := new(mocks.MeasurementRepositoryInterface)
+		mockRepo.On("GetAll", mock.Anything, filter, 1, 10).Return(nil, errors.New("error"))
+		s := measurement.NewService(mockRepo, new(mocks.SensorCatalogInterface), units.Default, noChecks, noAnomalies(), noCalibrations(), noMaintenance(), noDevices(), noPublisher(), domain.RangeModeFlag)
+		data, err := s.GetAll(context.Background(), filter, 1, 10)
+		assert.Error(t, err)
+		assert.Nil(t, data)
+	})
+}
+
+func TestServiceIngestCalibration(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	sensorID := primitive.NewObjectID()
+	parameters := map[string][]domain.DeviceParameter{
+		domain.ParamPH: {{SensorID: sensorID, ParameterSpec: domain.ParameterSpec{Parameter: domain.ParamPH, Unit: "pH", Min: 0, Max: 14}}},
+	}
+	calibratedAt := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	older := domain.Calibration{ID: primitive.NewObjectID(), SensorID: sensorID, Parameter: domain.ParamPH, CalibratedAt: calibratedAt.Add(-30 * 24 * time.Hour), Slope: 1, Offset: 1}
+	latest := domain.Calibration{ID: primitive.NewObjectID(), SensorID: sensorID, Parameter: domain.ParamPH, CalibratedAt: calibratedAt, Slope: 1, Offset: 0.1}
+
+	mockCatalog := new(mocks.SensorCatalogInterface)
+	mockCatalog.On("GetDeviceParameters", mock.Anything, deviceID).Return(parameters, nil)
+	mockCalibrations := new(mocks.CalibrationRepositoryInterface)
+	mockCalibrations.On("GetBySensor", mock.Anything, sensorID, domain.ParamPH).Return([]domain.Calibration{latest, older}, nil).Once()
+	mockRepo := new(mocks.MeasurementRepositoryInterface)
+	mockRepo.On("InsertMany", mock.Anything, mock.Anything).Return(nil)
+	s := measurement.NewService(mockRepo, mockCatalog, units.Default, noChecks, noAnomalies(), mockCalibrations, noMaintenance(), noDevices(), noPublisher(), domain.RangeModeFlag)
+
+	measurements, err := s.Ingest(context.Background(), []domain.Measurement{
+		{DeviceID: deviceID, Parameter: domain.ParamPH, Value: 7, Timestamp: calibratedAt.Add(time.Hour)},
+		{DeviceID: deviceID, Parameter: domain.ParamPH, Value: 7, Timestamp: calibratedAt.Add(-time.Hour)},
+		{DeviceID: deviceID, Parameter: domain.ParamPH, Value: 7, Timestamp: calibratedAt.Add(-60 * 24 * time.Hour)},
+	})
+	assert.NoError(t, err)
+	assert.InDelta(t, 7.1, measurements[0].Value, 1e-9)
+	assert.Equal(t, 7.0, *measurements[0].RawValue)
+	assert.Equal(t, latest.ID, measurements[0].CalibrationID)
+	assert.InDelta(t, 8, measurements[1].Value, 1e-9)
+	assert.Equal(t, older.ID, measurements[1].CalibrationID)
+	assert.Equal(t, 7.0, measurements[2].Value)
+	assert.Nil(t, measurements[2].RawValue)
+	mockCalibrations.AssertExpectations(t)
+}
+
+func TestServiceIngestMaintenance(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	start := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	workOrder := domain.WorkOrder{
+		ID:       primitive.NewObjectID(),
+		DeviceID: deviceID,
+		Type:     domain.WorkOrderCleaning,
+		Downtime: &domain.TimeWindow{Start: start, End: start.Add(time.Hour)},
+	}
+	planned := domain.WorkOrder{ID: primitive.NewObjectID(), DeviceID: deviceID, Type: domain.WorkOrderInspection}
+
+	mockMaintenance := new(mocks.MaintenanceRepositoryInterface)
+	filter := domain.MaintenanceFilter{DeviceID: deviceID, From: start.Add(-time.Hour), To: start.Add(30 * time.Minute)}
+	mockMaintenance.On("GetAll", mock.Anything, filter, 1, mock.Anything).Return([]domain.WorkOrder{planned, workOrder}, nil).Once()
+	mockCatalog := new(mocks.SensorCatalogInterface)
+	mockCatalog.On("GetDeviceParameters", mock.Anything, deviceID).Return(map[string][]domain.DeviceParameter{}, nil)
+	mockRepo := new(mocks.MeasurementRepositoryInterface)
+	mockRepo.On("InsertMany", mock.Anything, mock.Anything).Return(nil)
+	mockDetector := new(mocks.AnomalyDetectorInterface)
+	mockDetector.On("Detect", mock.Anything, mock.MatchedBy(func(ms []domain.Measurement) bool {
+		return ms[0].Maintenance && !ms[1].Maintenance
+	})).Return(nil, nil)
+	mockDetector.On("Record", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	s := measurement.NewService(mockRepo, mockCatalog, units.Default, noChecks, mockDetector, noCalibrations(), mockMaintenance, noDevices(), noPublisher(), domain.RangeModeFlag)
+
+	measurements, err := s.Ingest(context.Background(), []domain.Measurement{
+		{DeviceID: deviceID, Parameter: domain.ParamPH, Value: 7, Timestamp: start.Add(30 * time.Minute)},
+		{DeviceID: deviceID, Parameter: domain.ParamPH, Value: 7, Timestamp: start.Add(-time.Hour)},
+	})
+	assert.NoError(t, err)
+	assert.True(t, measurements[0].Maintenance)
+	assert.Equal(t, workOrder.ID, measurements[0].WorkOrderID)
+	assert.False(t, measurements[1].Maintenance)
+	assert.True(t, measurements[1].WorkOrderID.IsZero())
+	mockMaintenance.AssertExpectations(t)
+	mockDetector.AssertExpectations(t)
+}
+
+func TestServiceIngestDecommissioned(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	decommissionedAt := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	device := &domain.Device{ID: deviceID, Status: domain.DeviceDecommissioned, DecommissionedAt: decommissionedAt}
+	t.Run("Refused", func(t *testing.T) {
+		mockDevices := new(mocks.DeviceRepositoryInterface)
+		mockDevices.On("GetByID", mock.Anything, deviceID.Hex()).Return(device, nil).Once()
+		mockRepo := new(mocks.MeasurementRepositoryInterface)
+		s := measurement.NewService(mockRepo, new(mocks.SensorCatalogInterface), units.Default, noChecks, noAnomalies(), noCalibrations(), noMaintenance(), mockDevices, noPublisher(), domain.RangeModeFlag)
+		_, err := s.Ingest(context.Background(), []domain.Measurement{
+			{DeviceID: deviceID, Parameter: domain.ParamPH, Value: 7, Timestamp: decommissionedAt.Add(time.Hour)},
+		})
+		assert.ErrorIs(t, err, domain.ErrDeviceDecommissioned)
+		mockRepo.AssertNotCalled(t, "InsertMany", mock.Anything, mock.Anything)
+	})
+	t.Run("Before decommissioning", func(t *testing.T) {
+		mockDevices := new(mocks.DeviceRepositoryInterface)
+		mockDevices.On("GetByID", mock.Anything, deviceID.Hex()).Return(device, nil).Once()
+		mockCatalog := new(mocks.SensorCatalogInterface)
+		mockCatalog.On("GetDeviceParameters", mock.Anything, deviceID).Return(map[string][]domain.DeviceParameter{}, nil)
+		mockRepo := new(mocks.MeasurementRepositoryInterface)
+		mockRepo.On("InsertMany", mock.Anything, mock.Anything).Return(nil)
+		s := measurement.NewService(mockRepo, mockCatalog, units.Default, noChecks, noAnomalies(), noCalibrations(), noMaintenance(), mockDevices, noPublisher(), domain.RangeModeFlag)
+		measurements, err := s.Ingest(context.Background(), []domain.Measurement{
+			{DeviceID: deviceID, Parameter: domain.ParamPH, Value: 7, Timestamp: decommissionedAt.Add(-time.Hour)},
+			{DeviceID: deviceID, Parameter: domain.ParamTemperature, Value: 20, Timestamp: decommissionedAt.Add(-time.Hour)},
+		})
+		assert.NoError(t, err)
+		assert.Len(t, measurements, 2)
+		mockDevices.AssertExpectations(t)
+	})
+}
+
+func TestPublishersPublishMeasurements(t *testing.T) {
+	measurements := []domain.Measurement{{ReadingID: primitive.NewObjectID(), Parameter: domain.ParamPH, Value: 7.2}}
+	first := new(mocks.ReadingPublisherInterface)
+	first.On("PublishMeasurements", mock.Anything, measurements).Return()
+	second := new(mocks.ReadingPublisherInterface)
+	second.On("PublishMeasurements", mock.Anything, measurements).Return()
+
+	measurement.Publishers{first, second}.PublishMeasurements(context.Background(), measurements)
+	first.AssertExpectations(t)
+	second.AssertExpectations(t)
+}
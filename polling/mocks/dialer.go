@@ -0,0 +1,58 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	domain "github.com/anggi-susanto/mrt-go/domain"
+	polling "github.com/anggi-susanto/mrt-go/polling"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// DialerInterface is an autogenerated mock type for the DialerInterface type
+type DialerInterface struct {
+	mock.Mock
+}
+
+// Dial provides a mock function with given fields: endpoint
+func (_m *DialerInterface) Dial(endpoint domain.ModbusEndpoint) (polling.RegisterReaderInterface, error) {
+	ret := _m.Called(endpoint)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Dial")
+	}
+
+	var r0 polling.RegisterReaderInterface
+	var r1 error
+	if rf, ok := ret.Get(0).(func(domain.ModbusEndpoint) (polling.RegisterReaderInterface, error)); ok {
+		return rf(endpoint)
+	}
+	if rf, ok := ret.Get(0).(func(domain.ModbusEndpoint) polling.RegisterReaderInterface); ok {
+		r0 = rf(endpoint)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(polling.RegisterReaderInterface)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(domain.ModbusEndpoint) error); ok {
+		r1 = rf(endpoint)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewDialerInterface creates a new instance of DialerInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewDialerInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *DialerInterface {
+	mock := &DialerInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
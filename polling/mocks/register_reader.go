@@ -0,0 +1,72 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+
+// RegisterReaderInterface is an autogenerated mock type for the RegisterReaderInterface type
+type RegisterReaderInterface struct {
+	mock.Mock
+}
+
+// Close provides a mock function with no fields
+func (_m *RegisterReaderInterface) Close() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Close")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReadRegisters provides a mock function with given fields: unitID, table, address, count
+func (_m *RegisterReaderInterface) ReadRegisters(unitID byte, table string, address uint16, count uint16) ([]uint16, error) {
+	ret := _m.Called(unitID, table, address, count)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReadRegisters")
+	}
+
+	var r0 []uint16
+	var r1 error
+	if rf, ok := ret.Get(0).(func(byte, string, uint16, uint16) ([]uint16, error)); ok {
+		return rf(unitID, table, address, count)
+	}
+	if rf, ok := ret.Get(0).(func(byte, string, uint16, uint16) []uint16); ok {
+		r0 = rf(unitID, table, address, count)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]uint16)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(byte, string, uint16, uint16) error); ok {
+		r1 = rf(unitID, table, address, count)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewRegisterReaderInterface creates a new instance of RegisterReaderInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRegisterReaderInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *RegisterReaderInterface {
+	mock := &RegisterReaderInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
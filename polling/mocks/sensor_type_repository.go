@@ -0,0 +1,61 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SensorTypeRepositoryInterface is an autogenerated mock type for the SensorTypeRepositoryInterface type
+type SensorTypeRepositoryInterface struct {
+	mock.Mock
+}
+
+// GetByIDs provides a mock function with given fields: ctx, ids
+func (_m *SensorTypeRepositoryInterface) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]domain.SensorType, error) {
+	ret := _m.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByIDs")
+	}
+
+	var r0 []domain.SensorType
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []primitive.ObjectID) ([]domain.SensorType, error)); ok {
+		return rf(ctx, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []primitive.ObjectID) []domain.SensorType); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.SensorType)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []primitive.ObjectID) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewSensorTypeRepositoryInterface creates a new instance of SensorTypeRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSensorTypeRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SensorTypeRepositoryInterface {
+	mock := &SensorTypeRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,301 @@
+package polling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+)
+
+// tracer starts the spans of the service methods
+var tracer = otel.Tracer("github.com/anggi-susanto/mrt-go/polling")
+
+// DeviceRepositoryInterface is the interface that wraps the device lookups of the polling.
+type DeviceRepositoryInterface interface {
+	GetByID(ctx context.Context, id string) (*domain.Device, error)
+	GetModbusDevices(ctx context.Context) ([]domain.Device, error)
+}
+
+// SensorRepositoryInterface is the interface that wraps the GetByDeviceID method.
+type SensorRepositoryInterface interface {
+	GetByDeviceID(ctx context.Context, deviceID primitive.ObjectID) ([]domain.Sensor, error)
+}
+
+// SensorTypeRepositoryInterface is the interface that wraps the GetByIDs method.
+type SensorTypeRepositoryInterface interface {
+	GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]domain.SensorType, error)
+}
+
+// WasteWaterServiceInterface is the interface that wraps the Create method of the waste water ingestion.
+type WasteWaterServiceInterface interface {
+	Create(ctx context.Context, w *domain.WastewaterDataRequest) error
+}
+
+// RegisterReaderInterface is the interface that wraps the register reads of a Modbus connection.
+type RegisterReaderInterface interface {
+	ReadRegisters(unitID byte, table string, address, count uint16) ([]uint16, error)
+	Close() error
+}
+
+// DialerInterface is the interface that wraps the Dial method opening Modbus connections.
+type DialerInterface interface {
+	Dial(endpoint domain.ModbusEndpoint) (RegisterReaderInterface, error)
+}
+
+// Service polls the Modbus instruments and ingests their values as waste water readings.
+type Service struct {
+	deviceRepository     DeviceRepositoryInterface
+	sensorRepository     SensorRepositoryInterface
+	sensorTypeRepository SensorTypeRepositoryInterface
+	wasteWaterService    WasteWaterServiceInterface
+	dialer               DialerInterface
+	config               config.ModbusConfig
+
+	mu sync.Mutex
+	// polledAt is when each device was last polled by Sweep
+	polledAt map[primitive.ObjectID]time.Time
+}
+
+// NewService creates a new instance of the Service struct, initializing it with the provided repositories.
+//
+// Parameters:
+// - deviceRepository: The DeviceRepositoryInterface implementation used to find the devices to poll.
+// - sensorRepository: The SensorRepositoryInterface implementation used to resolve the sensors of a device.
+// - sensorTypeRepository: The SensorTypeRepositoryInterface implementation holding the register maps.
+// - wasteWaterService: The WasteWaterServiceInterface implementation the readings are pushed through.
+// - dialer: The DialerInterface implementation opening the Modbus connections.
+// - config: The default poll interval.
+//
+// Returns:
+// - A pointer to the newly created Service instance.
+func NewService(deviceRepository DeviceRepositoryInterface, sensorRepository SensorRepositoryInterface, sensorTypeRepository SensorTypeRepositoryInterface, wasteWaterService WasteWaterServiceInterface, dialer DialerInterface, config config.ModbusConfig) *Service {
+	return &Service{
+		deviceRepository:     deviceRepository,
+		sensorRepository:     sensorRepository,
+		sensorTypeRepository: sensorTypeRepository,
+		wasteWaterService:    wasteWaterService,
+		dialer:               dialer,
+		config:               config,
+		polledAt:             map[primitive.ObjectID]time.Time{},
+	}
+}
+
+// Poll reads a device now and ingests its values.
+//
+// ctx: The context.Context object for the request.
+// id: The ID of the device.
+// Returns the stored reading, nil when the device does not exist, and an error wrapping
+// domain.ErrInvalidModbus when the device has no endpoint or no register map.
+func (s *Service) Poll(ctx context.Context, id string) (*domain.WastewaterDataRequest, error) {
+	ctx, span := tracer.Start(ctx, "polling.Service.Poll")
+	defer span.End()
+	device, err := s.deviceRepository.GetByID(ctx, id)
+	if err != nil || device == nil {
+		return nil, err
+	}
+	if device.Modbus == nil {
+		return nil, fmt.Errorf("%w: device %s has no modbus endpoint", domain.ErrInvalidModbus, id)
+	}
+	reader, err := s.dialer.Dial(*device.Modbus)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return s.poll(ctx, reader, device)
+}
+
+// Sweep polls the devices whose poll interval has elapsed.
+//
+// Devices sharing an endpoint address, e.g. the instruments of an RTU bus, are polled one after the
+// other over a single connection while distinct addresses are polled concurrently. A device that
+// fails is polled again after its interval.
+//
+// ctx: The context.Context object for the request.
+// now: The time of the sweep.
+// Returns the number of devices polled and the errors of the others.
+func (s *Service) Sweep(ctx context.Context, now time.Time) (int, error) {
+	ctx, span := tracer.Start(ctx, "polling.Service.Sweep")
+	defer span.End()
+	devices, err := s.deviceRepository.GetModbusDevices(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	polledAt := make(map[primitive.ObjectID]time.Time, len(devices))
+	groups := map[string][]domain.Device{}
+	var addresses []string
+	for _, device := range devices {
+		last, ok := s.polledAt[device.ID]
+		polledAt[device.ID] = last
+		if ok && now.Sub(last) < device.Modbus.PollInterval(s.config.PollInterval) {
+			continue
+		}
+		polledAt[device.ID] = now
+		key := device.Modbus.Transport + "://" + device.Modbus.Address
+		if groups[key] == nil {
+			addresses = append(addresses, key)
+		}
+		groups[key] = append(groups[key], device)
+	}
+	// Devices no longer polled are forgotten
+	s.polledAt = polledAt
+	s.mu.Unlock()
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		polled int
+		errs   []error
+	)
+	for _, key := range addresses {
+		wg.Add(1)
+		go func(group []domain.Device) {
+			defer wg.Done()
+			endpoint := group[0].Modbus
+			reader, err := s.dialer.Dial(*endpoint)
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("modbus %s: %w", endpoint.Address, err))
+				mu.Unlock()
+				return
+			}
+			defer reader.Close()
+			for i := range group {
+				_, err := s.poll(ctx, reader, &group[i])
+				mu.Lock()
+				if err != nil {
+					errs = append(errs, fmt.Errorf("device %s: %w", group[i].ID.Hex(), err))
+				} else {
+					polled++
+				}
+				mu.Unlock()
+			}
+		}(groups[key])
+	}
+	wg.Wait()
+	return polled, errors.Join(errs...)
+}
+
+// poll reads the registers of a device and ingests them as a reading.
+func (s *Service) poll(ctx context.Context, reader RegisterReaderInterface, device *domain.Device) (*domain.WastewaterDataRequest, error) {
+	registers, err := s.registers(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+	values, err := read(reader, device.Modbus.UnitID, registers)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &domain.WastewaterDataRequest{DeviceID: device.ID, Timestamp: time.Now()}
+	for parameter, value := range values {
+		if !w.SetValue(parameter, value) {
+			if w.Parameters == nil {
+				w.Parameters = map[string]float64{}
+			}
+			w.Parameters[parameter] = value
+		}
+	}
+	if err = s.wasteWaterService.Create(ctx, w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// registers returns the register maps of the sensor types of the sensors of a device.
+func (s *Service) registers(ctx context.Context, device *domain.Device) ([]domain.ModbusRegister, error) {
+	sensors, err := s.sensorRepository.GetByDeviceID(ctx, device.ID)
+	if err != nil {
+		return nil, err
+	}
+	var typeIDs []primitive.ObjectID
+	for _, sensor := range sensors {
+		if !sensor.TypeID.IsZero() {
+			typeIDs = append(typeIDs, sensor.TypeID)
+		}
+	}
+	var registers []domain.ModbusRegister
+	if len(typeIDs) > 0 {
+		types, err := s.sensorTypeRepository.GetByIDs(ctx, typeIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range types {
+			registers = append(registers, t.Registers...)
+		}
+	}
+	if len(registers) == 0 {
+		return nil, fmt.Errorf("%w: the sensor types of device %s have no register map", domain.ErrInvalidModbus, device.ID.Hex())
+	}
+	return registers, nil
+}
+
+// block is a range of consecutive registers read at once.
+type block struct {
+	table   string
+	address uint16
+	count   uint16
+}
+
+// read reads the registers in as few requests as possible and decodes the value of each.
+func read(reader RegisterReaderInterface, unitID byte, registers []domain.ModbusRegister) (map[string]float64, error) {
+	sorted := make([]domain.ModbusRegister, len(registers))
+	copy(sorted, registers)
+	for i := range sorted {
+		if sorted[i].Table == "" {
+			sorted[i].Table = domain.ModbusHolding
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Table != sorted[j].Table {
+			return sorted[i].Table < sorted[j].Table
+		}
+		return sorted[i].Address < sorted[j].Address
+	})
+
+	// Consecutive or overlapping registers of a table share a block, gaps are not read since
+	// instruments refuse unmapped addresses
+	var blocks []block
+	for _, r := range sorted {
+		end := uint32(r.Address) + uint32(r.Count())
+		if n := len(blocks); n > 0 {
+			last := &blocks[n-1]
+			lastEnd := uint32(last.address) + uint32(last.count)
+			if last.table == r.Table && uint32(r.Address) <= lastEnd && end-uint32(last.address) <= domain.ModbusMaxRegisters {
+				if end > lastEnd {
+					last.count = uint16(end - uint32(last.address))
+				}
+				continue
+			}
+		}
+		blocks = append(blocks, block{table: r.Table, address: r.Address, count: r.Count()})
+	}
+
+	values := map[string]float64{}
+	for _, b := range blocks {
+		data, err := reader.ReadRegisters(unitID, b.table, b.address, b.count)
+		if err != nil {
+			return nil, fmt.Errorf("reading %d %s registers at %d: %w", b.count, b.table, b.address, err)
+		}
+		for _, r := range sorted {
+			if r.Table != b.table || r.Address < b.address || uint32(r.Address)+uint32(r.Count()) > uint32(b.address)+uint32(b.count) {
+				continue
+			}
+			offset := r.Address - b.address
+			value, err := r.Decode(data[offset : offset+r.Count()])
+			if err != nil {
+				return nil, err
+			}
+			values[r.Parameter] = value
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,125 @@
+package polling_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/polling"
+	"github.com/anggi-susanto/mrt-go/polling/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var modbusConfig = config.ModbusConfig{PollInterval: time.Minute}
+
+// analyzer returns a device polled over TCP with a sensor whose type maps pH, COD and TSS registers
+func analyzer(t *testing.T, address string) (domain.Device, *mocks.SensorRepositoryInterface, *mocks.SensorTypeRepositoryInterface) {
+	typeID := primitive.NewObjectID()
+	device := domain.Device{ID: primitive.NewObjectID(), Modbus: &domain.ModbusEndpoint{Transport: domain.ModbusTCP, Address: address, UnitID: 3}}
+	mockSensorRepo := new(mocks.SensorRepositoryInterface)
+	mockSensorRepo.On("GetByDeviceID", mock.Anything, device.ID).Return([]domain.Sensor{{DeviceID: device.ID, TypeID: typeID}}, nil)
+	mockSensorTypeRepo := new(mocks.SensorTypeRepositoryInterface)
+	mockSensorTypeRepo.On("GetByIDs", mock.Anything, []primitive.ObjectID{typeID}).Return([]domain.SensorType{{
+		ID: typeID,
+		Registers: []domain.ModbusRegister{
+			{Parameter: domain.ParamPH, Address: 0, DataType: domain.ModbusUint16, Scale: 0.01},
+			{Parameter: domain.ParamCOD, Address: 1, DataType: domain.ModbusInt32, ByteOrder: domain.ModbusCDAB},
+			{Parameter: "TSS", Address: 10, Table: domain.ModbusInput, DataType: domain.ModbusInt16},
+		},
+	}}, nil)
+	return device, mockSensorRepo, mockSensorTypeRepo
+}
+
+func TestServicePoll(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		device, mockSensorRepo, mockSensorTypeRepo := analyzer(t, "10.0.0.5:502")
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, device.ID.Hex()).Return(&device, nil)
+		mockReader := new(mocks.RegisterReaderInterface)
+		// pH and COD are consecutive holding registers read at once
+		mockReader.On("ReadRegisters", byte(3), domain.ModbusHolding, uint16(0), uint16(3)).Return([]uint16{712, 85, 0}, nil).Once()
+		mockReader.On("ReadRegisters", byte(3), domain.ModbusInput, uint16(10), uint16(1)).Return([]uint16{42}, nil).Once()
+		mockReader.On("Close").Return(nil)
+		mockDialer := new(mocks.DialerInterface)
+		mockDialer.On("Dial", *device.Modbus).Return(mockReader, nil)
+		mockWasteWater := new(mocks.WasteWaterServiceInterface)
+		mockWasteWater.On("Create", mock.Anything, mock.MatchedBy(func(w *domain.WastewaterDataRequest) bool {
+			return w.DeviceID == device.ID && w.PH == 7.12 && w.COD == 85 && w.Parameters["TSS"] == 42
+		})).Return(nil)
+
+		service := polling.NewService(mockDeviceRepo, mockSensorRepo, mockSensorTypeRepo, mockWasteWater, mockDialer, modbusConfig)
+		reading, err := service.Poll(context.Background(), device.ID.Hex())
+		assert.NoError(t, err)
+		assert.Equal(t, 7.12, reading.PH)
+		mockReader.AssertExpectations(t)
+		mockWasteWater.AssertExpectations(t)
+	})
+	t.Run("No endpoint", func(t *testing.T) {
+		device := domain.Device{ID: primitive.NewObjectID()}
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, device.ID.Hex()).Return(&device, nil)
+		service := polling.NewService(mockDeviceRepo, nil, nil, nil, nil, modbusConfig)
+		_, err := service.Poll(context.Background(), device.ID.Hex())
+		assert.ErrorIs(t, err, domain.ErrInvalidModbus)
+	})
+	t.Run("Not found", func(t *testing.T) {
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, "missing").Return(nil, nil)
+		service := polling.NewService(mockDeviceRepo, nil, nil, nil, nil, modbusConfig)
+		reading, err := service.Poll(context.Background(), "missing")
+		assert.NoError(t, err)
+		assert.Nil(t, reading)
+	})
+	t.Run("Read fails", func(t *testing.T) {
+		device, mockSensorRepo, mockSensorTypeRepo := analyzer(t, "10.0.0.5:502")
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, device.ID.Hex()).Return(&device, nil)
+		mockReader := new(mocks.RegisterReaderInterface)
+		mockReader.On("ReadRegisters", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("timeout"))
+		mockReader.On("Close").Return(nil)
+		mockDialer := new(mocks.DialerInterface)
+		mockDialer.On("Dial", mock.Anything).Return(mockReader, nil)
+		service := polling.NewService(mockDeviceRepo, mockSensorRepo, mockSensorTypeRepo, nil, mockDialer, modbusConfig)
+		_, err := service.Poll(context.Background(), device.ID.Hex())
+		assert.Error(t, err)
+		mockReader.AssertCalled(t, "Close")
+	})
+}
+
+func TestServiceSweep(t *testing.T) {
+	device, mockSensorRepo, mockSensorTypeRepo := analyzer(t, "10.0.0.5:502")
+	unreachable := domain.Device{ID: primitive.NewObjectID(), Modbus: &domain.ModbusEndpoint{Transport: domain.ModbusTCP, Address: "10.0.0.6:502", Interval: 600}}
+	mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+	mockDeviceRepo.On("GetModbusDevices", mock.Anything).Return([]domain.Device{device, unreachable}, nil)
+	mockReader := new(mocks.RegisterReaderInterface)
+	mockReader.On("ReadRegisters", byte(3), domain.ModbusHolding, uint16(0), uint16(3)).Return([]uint16{712, 85, 0}, nil)
+	mockReader.On("ReadRegisters", byte(3), domain.ModbusInput, uint16(10), uint16(1)).Return([]uint16{42}, nil)
+	mockReader.On("Close").Return(nil)
+	mockDialer := new(mocks.DialerInterface)
+	mockDialer.On("Dial", *device.Modbus).Return(mockReader, nil)
+	mockDialer.On("Dial", *unreachable.Modbus).Return(nil, errors.New("connection refused"))
+	mockWasteWater := new(mocks.WasteWaterServiceInterface)
+	mockWasteWater.On("Create", mock.Anything, mock.Anything).Return(nil)
+	service := polling.NewService(mockDeviceRepo, mockSensorRepo, mockSensorTypeRepo, mockWasteWater, mockDialer, modbusConfig)
+
+	now := time.Now()
+	polled, err := service.Sweep(context.Background(), now)
+	assert.Equal(t, 1, polled)
+	assert.ErrorContains(t, err, "connection refused")
+
+	// Neither device is due again before its interval
+	polled, err = service.Sweep(context.Background(), now.Add(30*time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, polled)
+
+	// The first device follows the default interval while the other one has its own
+	polled, err = service.Sweep(context.Background(), now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, polled)
+	mockDialer.AssertNumberOfCalls(t, "Dial", 3)
+}
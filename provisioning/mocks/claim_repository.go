@@ -0,0 +1,155 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ClaimRepositoryInterface is an autogenerated mock type for the ClaimRepositoryInterface type
+type ClaimRepositoryInterface struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, c
+func (_m *ClaimRepositoryInterface) Create(ctx context.Context, c *domain.ProvisioningClaim) error {
+	ret := _m.Called(ctx, c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.ProvisioningClaim) error); ok {
+		r0 = rf(ctx, c)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAll provides a mock function with given fields: ctx, status, page, limit
+func (_m *ClaimRepositoryInterface) GetAll(ctx context.Context, status string, page int, limit int) ([]domain.ProvisioningClaim, error) {
+	ret := _m.Called(ctx, status, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAll")
+	}
+
+	var r0 []domain.ProvisioningClaim
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) ([]domain.ProvisioningClaim, error)); ok {
+		return rf(ctx, status, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) []domain.ProvisioningClaim); ok {
+		r0 = rf(ctx, status, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.ProvisioningClaim)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, int) error); ok {
+		r1 = rf(ctx, status, page, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByID provides a mock function with given fields: ctx, id
+func (_m *ClaimRepositoryInterface) GetByID(ctx context.Context, id string) (*domain.ProvisioningClaim, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *domain.ProvisioningClaim
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.ProvisioningClaim, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.ProvisioningClaim); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.ProvisioningClaim)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBySerialNumber provides a mock function with given fields: ctx, serialNumber
+func (_m *ClaimRepositoryInterface) GetBySerialNumber(ctx context.Context, serialNumber string) (*domain.ProvisioningClaim, error) {
+	ret := _m.Called(ctx, serialNumber)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBySerialNumber")
+	}
+
+	var r0 *domain.ProvisioningClaim
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.ProvisioningClaim, error)); ok {
+		return rf(ctx, serialNumber)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.ProvisioningClaim); ok {
+		r0 = rf(ctx, serialNumber)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.ProvisioningClaim)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, serialNumber)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateStatus provides a mock function with given fields: ctx, c, from
+func (_m *ClaimRepositoryInterface) UpdateStatus(ctx context.Context, c *domain.ProvisioningClaim, from string) error {
+	ret := _m.Called(ctx, c, from)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateStatus")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.ProvisioningClaim, string) error); ok {
+		r0 = rf(ctx, c, from)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewClaimRepositoryInterface creates a new instance of ClaimRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewClaimRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ClaimRepositoryInterface {
+	mock := &ClaimRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,107 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TemplateRepositoryInterface is an autogenerated mock type for the TemplateRepositoryInterface type
+type TemplateRepositoryInterface struct {
+	mock.Mock
+}
+
+// GetAll provides a mock function with given fields: ctx
+func (_m *TemplateRepositoryInterface) GetAll(ctx context.Context) ([]domain.DeviceTemplate, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAll")
+	}
+
+	var r0 []domain.DeviceTemplate
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]domain.DeviceTemplate, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []domain.DeviceTemplate); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.DeviceTemplate)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByModel provides a mock function with given fields: ctx, model
+func (_m *TemplateRepositoryInterface) GetByModel(ctx context.Context, model string) (*domain.DeviceTemplate, error) {
+	ret := _m.Called(ctx, model)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByModel")
+	}
+
+	var r0 *domain.DeviceTemplate
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.DeviceTemplate, error)); ok {
+		return rf(ctx, model)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.DeviceTemplate); ok {
+		r0 = rf(ctx, model)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.DeviceTemplate)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, model)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Save provides a mock function with given fields: ctx, t
+func (_m *TemplateRepositoryInterface) Save(ctx context.Context, t *domain.DeviceTemplate) error {
+	ret := _m.Called(ctx, t)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.DeviceTemplate) error); ok {
+		r0 = rf(ctx, t)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewTemplateRepositoryInterface creates a new instance of TemplateRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTemplateRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TemplateRepositoryInterface {
+	mock := &TemplateRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,478 @@
+package provisioning
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+)
+
+// tracer starts the spans of the service methods
+var tracer = otel.Tracer("github.com/anggi-susanto/mrt-go/provisioning")
+
+// passwordBytes is the number of random bytes of a generated device password
+const passwordBytes = 24
+
+// ClaimRepositoryInterface is the interface that wraps the provisioning claim storage methods.
+type ClaimRepositoryInterface interface {
+	Create(ctx context.Context, c *domain.ProvisioningClaim) error
+	GetAll(ctx context.Context, status string, page, limit int) ([]domain.ProvisioningClaim, error)
+	GetByID(ctx context.Context, id string) (*domain.ProvisioningClaim, error)
+	GetBySerialNumber(ctx context.Context, serialNumber string) (*domain.ProvisioningClaim, error)
+	UpdateStatus(ctx context.Context, c *domain.ProvisioningClaim, from string) error
+}
+
+// TemplateRepositoryInterface is the interface that wraps the device template storage methods.
+type TemplateRepositoryInterface interface {
+	Save(ctx context.Context, t *domain.DeviceTemplate) error
+	GetAll(ctx context.Context) ([]domain.DeviceTemplate, error)
+	GetByModel(ctx context.Context, model string) (*domain.DeviceTemplate, error)
+}
+
+// DeviceRepositoryInterface is the interface that wraps the Create and Delete methods.
+type DeviceRepositoryInterface interface {
+	Create(ctx context.Context, device *domain.DeviceRequest) error
+	Delete(ctx context.Context, id string) error
+}
+
+// SensorRepositoryInterface is the interface that wraps the Create and Delete methods.
+type SensorRepositoryInterface interface {
+	Create(ctx context.Context, sensor *domain.SensorRequest) error
+	Delete(ctx context.Context, id string) error
+}
+
+// CredentialRepositoryInterface is the interface that wraps the Create and Delete methods.
+type CredentialRepositoryInterface interface {
+	Create(ctx context.Context, credential *domain.DeviceCredential) error
+	Delete(ctx context.Context, id string) error
+}
+
+// PSKIssuerInterface is the interface that wraps the Rotate method.
+type PSKIssuerInterface interface {
+	Rotate(ctx context.Context, deviceID string) (*domain.PSKSecret, error)
+}
+
+// ShadowServiceInterface is the interface that wraps the UpdateDesired method.
+type ShadowServiceInterface interface {
+	UpdateDesired(ctx context.Context, deviceID string, update domain.ShadowUpdate) (*domain.Shadow, error)
+}
+
+// Service is the interface that wraps the device provisioning.
+type Service struct {
+	claimRepository      ClaimRepositoryInterface
+	templateRepository   TemplateRepositoryInterface
+	deviceRepository     DeviceRepositoryInterface
+	sensorRepository     SensorRepositoryInterface
+	credentialRepository CredentialRepositoryInterface
+	pskIssuer            PSKIssuerInterface
+	shadows              ShadowServiceInterface
+	config               config.ProvisioningConfig
+}
+
+// NewService creates a new instance of the Service struct, initializing it with the provided dependencies.
+//
+// Parameters:
+// - claimRepository: The ClaimRepositoryInterface implementation storing the claims and the approval queue.
+// - templateRepository: The TemplateRepositoryInterface implementation storing the device templates.
+// - deviceRepository: The DeviceRepositoryInterface implementation the devices are created in.
+// - sensorRepository: The SensorRepositoryInterface implementation the template sensors are created in.
+// - credentialRepository: The CredentialRepositoryInterface implementation storing the device credentials.
+// - pskIssuer: The PSKIssuerInterface implementation issuing the CoAP pre-shared key of the devices.
+// - shadows: The ShadowServiceInterface implementation receiving the initial configuration of the devices.
+// - config: the broker address and topic prefix given to the devices.
+//
+// Returns:
+// - A pointer to the newly created Service instance.
+func NewService(claimRepository ClaimRepositoryInterface, templateRepository TemplateRepositoryInterface, deviceRepository DeviceRepositoryInterface, sensorRepository SensorRepositoryInterface, credentialRepository CredentialRepositoryInterface, pskIssuer PSKIssuerInterface, shadows ShadowServiceInterface, config config.ProvisioningConfig) *Service {
+	return &Service{
+		claimRepository:      claimRepository,
+		templateRepository:   templateRepository,
+		deviceRepository:     deviceRepository,
+		sensorRepository:     sensorRepository,
+		credentialRepository: credentialRepository,
+		pskIssuer:            pskIssuer,
+		shadows:              shadows,
+		config:               config,
+	}
+}
+
+// CreateClaim pre-registers a serial number so that its device is provisioned without approval.
+//
+// ctx - context.Context for the operation.
+// request - the serial number, its claim token and the name, model and site of the device.
+// Returns the approved claim and domain.ErrClaimTransition when the serial number already has a claim.
+func (s *Service) CreateClaim(ctx context.Context, request domain.ClaimRequest) (*domain.ProvisioningClaim, error) {
+	ctx, span := tracer.Start(ctx, "provisioning.Service.CreateClaim")
+	defer span.End()
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	claim := &domain.ProvisioningClaim{
+		SerialNumber: request.SerialNumber,
+		TokenHash:    domain.HashSecret(request.ClaimToken),
+		Status:       domain.ClaimApproved,
+		Name:         request.Name,
+		Model:        request.Model,
+		SiteID:       request.SiteID,
+		DecidedBy:    request.DecidedBy,
+		DecidedAt:    now,
+		CreatedAt:    domain.MyTime{Time: now},
+	}
+	if err := s.claimRepository.Create(ctx, claim); err != nil {
+		return nil, err
+	}
+	return claim, nil
+}
+
+// GetAll retrieves the claims in a status, e.g. the pending ones of the approval queue.
+//
+// ctx - context.Context for the operation.
+// status - the status of the claims, all claims when empty.
+// page - int representing the page number.
+// limit - int representing the maximum number of items per page.
+// Returns the claims and an error.
+func (s *Service) GetAll(ctx context.Context, status string, page, limit int) ([]domain.ProvisioningClaim, error) {
+	ctx, span := tracer.Start(ctx, "provisioning.Service.GetAll")
+	defer span.End()
+	claims, err := s.claimRepository.GetAll(ctx, status, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	if claims == nil {
+		claims = []domain.ProvisioningClaim{}
+	}
+	return claims, nil
+}
+
+// Approve approves a pending claim, its device is provisioned the next time it registers.
+//
+// ctx - context.Context for the operation.
+// id - string representing the ID of the claim.
+// decision - the name, model and site of the device, empty values keep what the device reported.
+// Returns the claim, nil when it does not exist, and domain.ErrClaimTransition when it is not pending.
+func (s *Service) Approve(ctx context.Context, id string, decision domain.ClaimDecision) (*domain.ProvisioningClaim, error) {
+	ctx, span := tracer.Start(ctx, "provisioning.Service.Approve")
+	defer span.End()
+	return s.decide(ctx, id, domain.ClaimApproved, decision)
+}
+
+// Reject rejects a pending claim, its device is refused from then on.
+//
+// ctx - context.Context for the operation.
+// id - string representing the ID of the claim.
+// decision - who rejected the claim.
+// Returns the claim, nil when it does not exist, and domain.ErrClaimTransition when it is not pending.
+func (s *Service) Reject(ctx context.Context, id string, decision domain.ClaimDecision) (*domain.ProvisioningClaim, error) {
+	ctx, span := tracer.Start(ctx, "provisioning.Service.Reject")
+	defer span.End()
+	return s.decide(ctx, id, domain.ClaimRejected, decision)
+}
+
+// Register provisions a device presenting its serial number and claim token.
+//
+// An unknown serial number is queued for approval and the registration stays pending until an
+// admin approves it. An approved claim creates the device, its sensors from the template of its
+// model and its credentials, and seeds its shadow with the template configuration. The claim
+// token can only be used once. When provisioning fails the documents it created are removed and
+// the claim is given back, so that the device can retry with the same serial number.
+//
+// ctx - context.Context for the operation.
+// request - the serial number, claim token and hardware of the device.
+// Returns the registration, domain.ErrClaimRefused when the token is wrong or the claim was
+// rejected and domain.ErrClaimTransition when the claim was already used.
+func (s *Service) Register(ctx context.Context, request domain.RegistrationRequest) (*domain.Registration, error) {
+	ctx, span := tracer.Start(ctx, "provisioning.Service.Register")
+	defer span.End()
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	claim, err := s.claimRepository.GetBySerialNumber(ctx, request.SerialNumber)
+	if err != nil {
+		return nil, err
+	}
+	if claim == nil {
+		return s.queue(ctx, request)
+	}
+	if !claim.Matches(request.ClaimToken) {
+		return nil, fmt.Errorf("%w: wrong claim token for %s", domain.ErrClaimRefused, request.SerialNumber)
+	}
+	switch claim.Status {
+	case domain.ClaimPending:
+		return &domain.Registration{Status: domain.ClaimPending}, nil
+	case domain.ClaimRejected:
+		return nil, fmt.Errorf("%w: %s was rejected", domain.ErrClaimRefused, request.SerialNumber)
+	case domain.ClaimClaimed:
+		return nil, fmt.Errorf("%w: the claim token of %s was already used", domain.ErrClaimTransition, request.SerialNumber)
+	}
+
+	// The claim is taken before provisioning so that a token used twice concurrently provisions one device
+	claim.Status = domain.ClaimClaimed
+	claim.ClaimedAt = time.Now()
+	claim.Firmware = request.Firmware
+	claim.HardwareRevision = request.HardwareRevision
+	if claim.Model == "" {
+		claim.Model = request.Model
+	}
+	if err = s.claimRepository.UpdateStatus(ctx, claim, domain.ClaimApproved); err != nil {
+		return nil, err
+	}
+	created := &provisioned{}
+	registration, err := s.provision(ctx, claim, created)
+	if err == nil {
+		claim.DeviceID = registration.Device.ID
+		err = s.claimRepository.UpdateStatus(ctx, claim, domain.ClaimClaimed)
+	}
+	if err != nil {
+		// The device left behind would hold its serial number and fail every retry
+		if removeErr := s.remove(ctx, created); removeErr != nil {
+			err = errors.Join(err, removeErr)
+		}
+		// Give the claim back so that the device can retry
+		claim.Status = domain.ClaimApproved
+		claim.ClaimedAt = time.Time{}
+		claim.DeviceID = primitive.NilObjectID
+		if giveBackErr := s.claimRepository.UpdateStatus(ctx, claim, domain.ClaimClaimed); giveBackErr != nil {
+			err = errors.Join(err, giveBackErr)
+		}
+		return nil, err
+	}
+	return registration, nil
+}
+
+// SaveTemplate creates or replaces the template of a model.
+//
+// ctx - context.Context for the operation.
+// template - the sensors and initial configuration of the model.
+// Returns the stored template and an error.
+func (s *Service) SaveTemplate(ctx context.Context, template domain.DeviceTemplate) (*domain.DeviceTemplate, error) {
+	ctx, span := tracer.Start(ctx, "provisioning.Service.SaveTemplate")
+	defer span.End()
+	if err := template.Validate(); err != nil {
+		return nil, err
+	}
+	template.UpdatedAt = domain.MyTime{Time: time.Now()}
+	if err := s.templateRepository.Save(ctx, &template); err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// GetTemplates retrieves the templates of every model.
+//
+// ctx - context.Context for the operation.
+// Returns the templates and an error.
+func (s *Service) GetTemplates(ctx context.Context) ([]domain.DeviceTemplate, error) {
+	ctx, span := tracer.Start(ctx, "provisioning.Service.GetTemplates")
+	defer span.End()
+	templates, err := s.templateRepository.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if templates == nil {
+		templates = []domain.DeviceTemplate{}
+	}
+	return templates, nil
+}
+
+// decide moves a pending claim to approved or rejected.
+func (s *Service) decide(ctx context.Context, id string, status string, decision domain.ClaimDecision) (*domain.ProvisioningClaim, error) {
+	claim, err := s.claimRepository.GetByID(ctx, id)
+	if err != nil || claim == nil {
+		return nil, err
+	}
+	if claim.Status != domain.ClaimPending {
+		return nil, fmt.Errorf("%w: the claim is %s", domain.ErrClaimTransition, claim.Status)
+	}
+	claim.Status = status
+	claim.DecidedBy = decision.DecidedBy
+	claim.DecidedAt = time.Now()
+	if decision.Name != "" {
+		claim.Name = decision.Name
+	}
+	if decision.Model != "" {
+		claim.Model = decision.Model
+	}
+	if !decision.SiteID.IsZero() {
+		claim.SiteID = decision.SiteID
+	}
+	if err = s.claimRepository.UpdateStatus(ctx, claim, domain.ClaimPending); err != nil {
+		return nil, err
+	}
+	return claim, nil
+}
+
+// queue creates a pending claim for an unknown serial number.
+func (s *Service) queue(ctx context.Context, request domain.RegistrationRequest) (*domain.Registration, error) {
+	claim := &domain.ProvisioningClaim{
+		SerialNumber:     request.SerialNumber,
+		TokenHash:        domain.HashSecret(request.ClaimToken),
+		Status:           domain.ClaimPending,
+		Model:            request.Model,
+		Firmware:         request.Firmware,
+		HardwareRevision: request.HardwareRevision,
+		CreatedAt:        domain.MyTime{Time: time.Now()},
+	}
+	// A concurrent registration of the same serial number already queued it
+	if err := s.claimRepository.Create(ctx, claim); err != nil && err != domain.ErrClaimTransition {
+		return nil, err
+	}
+	return &domain.Registration{Status: domain.ClaimPending}, nil
+}
+
+// provisioned lists the documents created by a provisioning
+type provisioned struct {
+	deviceID     primitive.ObjectID
+	sensorIDs    []primitive.ObjectID
+	credentialID primitive.ObjectID
+}
+
+// provision creates the device of a claim with its sensors, credentials and initial configuration,
+// recording what it created.
+func (s *Service) provision(ctx context.Context, claim *domain.ProvisioningClaim, created *provisioned) (*domain.Registration, error) {
+	template, err := s.templateRepository.GetByModel(ctx, claim.Model)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	name := claim.Name
+	if name == "" {
+		name = claim.SerialNumber
+	}
+	request := &domain.DeviceRequest{
+		Name:             name,
+		SiteID:           claim.SiteID,
+		SerialNumber:     claim.SerialNumber,
+		Model:            claim.Model,
+		Firmware:         claim.Firmware,
+		HardwareRevision: claim.HardwareRevision,
+		CreatedAt:        domain.MyTime{Time: now},
+		UpdatedAt:        domain.MyTime{Time: now},
+	}
+	// A device claimed for a site is installed there, otherwise it waits in stock
+	if !claim.SiteID.IsZero() {
+		request.Status = domain.DeviceInstalled
+	}
+	if err = request.Validate(); err != nil {
+		return nil, err
+	}
+	if err = s.deviceRepository.Create(ctx, request); err != nil {
+		return nil, err
+	}
+	created.deviceID = request.ID
+	registration := &domain.Registration{
+		Status: domain.ClaimClaimed,
+		Device: &domain.Device{
+			ID:               request.ID,
+			Name:             request.Name,
+			SiteID:           request.SiteID,
+			Status:           request.Status,
+			SerialNumber:     request.SerialNumber,
+			Model:            request.Model,
+			Firmware:         request.Firmware,
+			HardwareRevision: request.HardwareRevision,
+			InstalledAt:      request.InstalledAt,
+			Installations:    request.Installations,
+			CreatedAt:        request.CreatedAt,
+			UpdatedAt:        request.UpdatedAt,
+		},
+		Config: &domain.DeviceConfig{
+			Broker:      s.config.Broker,
+			TopicPrefix: s.config.TopicPrefix + "/" + request.ID.Hex(),
+		},
+	}
+
+	if template != nil {
+		for _, sensor := range template.Sensors {
+			sensorRequest := &domain.SensorRequest{
+				Name:        sensor.Name,
+				Description: sensor.Description,
+				DeviceID:    request.ID,
+				TypeID:      sensor.TypeID,
+				CreatedAt:   domain.MyTime{Time: now},
+				UpdatedAt:   domain.MyTime{Time: now},
+			}
+			if err = s.sensorRepository.Create(ctx, sensorRequest); err != nil {
+				return nil, err
+			}
+			created.sensorIDs = append(created.sensorIDs, sensorRequest.ID)
+			registration.Sensors = append(registration.Sensors, domain.Sensor{
+				ID:          sensorRequest.ID,
+				Name:        sensorRequest.Name,
+				Description: sensorRequest.Description,
+				DeviceID:    sensorRequest.DeviceID,
+				TypeID:      sensorRequest.TypeID,
+				CreatedAt:   sensorRequest.CreatedAt,
+				UpdatedAt:   sensorRequest.UpdatedAt,
+			})
+		}
+		if len(template.Config) > 0 {
+			shadow, err := s.shadows.UpdateDesired(ctx, request.ID.Hex(), domain.ShadowUpdate{State: template.Config})
+			if err != nil {
+				return nil, err
+			}
+			registration.Config.Desired = shadow.Desired
+		}
+	}
+
+	password, err := newPassword()
+	if err != nil {
+		return nil, err
+	}
+	credential := &domain.DeviceCredential{
+		DeviceID:     request.ID,
+		Username:     request.ID.Hex(),
+		PasswordHash: domain.HashSecret(password),
+		CreatedAt:    domain.MyTime{Time: now},
+	}
+	if err = s.credentialRepository.Create(ctx, credential); err != nil {
+		return nil, err
+	}
+	created.credentialID = credential.ID
+	// The pre-shared key comes last, nothing can fail after it is stored
+	secret, err := s.pskIssuer.Rotate(ctx, request.ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	registration.Credentials = &domain.DeviceCredentials{
+		ClientID:    request.ID.Hex(),
+		Username:    credential.Username,
+		Password:    password,
+		PSKIdentity: secret.Identity,
+		PSK:         secret.Key,
+	}
+	return registration, nil
+}
+
+// remove deletes the documents created by a failed provisioning.
+//
+// The shadow seeded for the device is left, no device will have its ID again.
+func (s *Service) remove(ctx context.Context, created *provisioned) error {
+	var errs []error
+	if !created.credentialID.IsZero() {
+		errs = append(errs, s.credentialRepository.Delete(ctx, created.credentialID.Hex()))
+	}
+	for _, id := range created.sensorIDs {
+		errs = append(errs, s.sensorRepository.Delete(ctx, id.Hex()))
+	}
+	if !created.deviceID.IsZero() {
+		errs = append(errs, s.deviceRepository.Delete(ctx, created.deviceID.Hex()))
+	}
+	return errors.Join(errs...)
+}
+
+// newPassword generates a random device password.
+func newPassword() (string, error) {
+	b := make([]byte, passwordBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,260 @@
+package provisioning_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/provisioning"
+	"github.com/anggi-susanto/mrt-go/provisioning/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var provisioningConfig = config.ProvisioningConfig{Broker: "tcp://mqtt.example.com:1883", TopicPrefix: "mrt/devices"}
+
+func TestServiceRegister(t *testing.T) {
+	siteID := primitive.NewObjectID()
+	request := domain.RegistrationRequest{SerialNumber: "WQ-0001", ClaimToken: "s3cret", Model: "WQ-5", Firmware: "1.4.2"}
+	approved := func() *domain.ProvisioningClaim {
+		return &domain.ProvisioningClaim{
+			ID:           primitive.NewObjectID(),
+			SerialNumber: "WQ-0001",
+			TokenHash:    domain.HashSecret("s3cret"),
+			Status:       domain.ClaimApproved,
+			SiteID:       siteID,
+		}
+	}
+	t.Run("Provisioned", func(t *testing.T) {
+		deviceID := primitive.NewObjectID()
+		mockClaimRepo := new(mocks.ClaimRepositoryInterface)
+		mockClaimRepo.On("GetBySerialNumber", mock.Anything, "WQ-0001").Return(approved(), nil)
+		mockClaimRepo.On("UpdateStatus", mock.Anything, mock.MatchedBy(func(c *domain.ProvisioningClaim) bool {
+			return c.Status == domain.ClaimClaimed && c.DeviceID.IsZero()
+		}), domain.ClaimApproved).Return(nil).Once()
+		mockClaimRepo.On("UpdateStatus", mock.Anything, mock.MatchedBy(func(c *domain.ProvisioningClaim) bool {
+			return c.DeviceID == deviceID
+		}), domain.ClaimClaimed).Return(nil).Once()
+		mockTemplateRepo := new(mocks.TemplateRepositoryInterface)
+		mockTemplateRepo.On("GetByModel", mock.Anything, "WQ-5").Return(&domain.DeviceTemplate{
+			Model:   "WQ-5",
+			Sensors: []domain.TemplateSensor{{Name: "pH probe"}, {Name: "COD probe"}},
+			Config:  domain.ShadowState{"interval": 300.0},
+		}, nil)
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("Create", mock.Anything, mock.MatchedBy(func(d *domain.DeviceRequest) bool {
+			return d.SerialNumber == "WQ-0001" && d.Status == domain.DeviceInstalled && d.SiteID == siteID
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.DeviceRequest).ID = deviceID
+		}).Return(nil)
+		mockSensorRepo := new(mocks.SensorRepositoryInterface)
+		mockSensorRepo.On("Create", mock.Anything, mock.MatchedBy(func(s *domain.SensorRequest) bool {
+			return s.DeviceID == deviceID
+		})).Return(nil).Twice()
+		mockCredentialRepo := new(mocks.CredentialRepositoryInterface)
+		mockCredentialRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+		mockPSKIssuer := new(mocks.PSKIssuerInterface)
+		mockPSKIssuer.On("Rotate", mock.Anything, deviceID.Hex()).Return(&domain.PSKSecret{Identity: deviceID.Hex(), Key: "000102030405060708090a0b0c0d0e0f"}, nil)
+		mockShadows := new(mocks.ShadowServiceInterface)
+		mockShadows.On("UpdateDesired", mock.Anything, deviceID.Hex(), domain.ShadowUpdate{State: domain.ShadowState{"interval": 300.0}}).
+			Return(&domain.Shadow{Desired: domain.ShadowState{"interval": 300.0}}, nil)
+
+		service := provisioning.NewService(mockClaimRepo, mockTemplateRepo, mockDeviceRepo, mockSensorRepo, mockCredentialRepo, mockPSKIssuer, mockShadows, provisioningConfig)
+		registration, err := service.Register(context.Background(), request)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.ClaimClaimed, registration.Status)
+		assert.Equal(t, deviceID, registration.Device.ID)
+		assert.Len(t, registration.Sensors, 2)
+		assert.Equal(t, deviceID.Hex(), registration.Credentials.Username)
+		assert.NotEmpty(t, registration.Credentials.Password)
+		assert.Equal(t, deviceID.Hex(), registration.Credentials.PSKIdentity)
+		assert.Equal(t, "000102030405060708090a0b0c0d0e0f", registration.Credentials.PSK)
+		assert.Equal(t, "mrt/devices/"+deviceID.Hex(), registration.Config.TopicPrefix)
+		assert.Equal(t, domain.ShadowState{"interval": 300.0}, registration.Config.Desired)
+		// Only the hash of the password is stored
+		mockCredentialRepo.AssertCalled(t, "Create", mock.Anything, mock.MatchedBy(func(c *domain.DeviceCredential) bool {
+			return c.PasswordHash == domain.HashSecret(registration.Credentials.Password)
+		}))
+		mockClaimRepo.AssertExpectations(t)
+		mockSensorRepo.AssertExpectations(t)
+	})
+	t.Run("Unknown serial queued", func(t *testing.T) {
+		mockClaimRepo := new(mocks.ClaimRepositoryInterface)
+		mockClaimRepo.On("GetBySerialNumber", mock.Anything, "WQ-0001").Return(nil, nil)
+		mockClaimRepo.On("Create", mock.Anything, mock.MatchedBy(func(c *domain.ProvisioningClaim) bool {
+			return c.Status == domain.ClaimPending && c.Model == "WQ-5" && c.Matches("s3cret")
+		})).Return(nil)
+		service := provisioning.NewService(mockClaimRepo, nil, nil, nil, nil, nil, nil, provisioningConfig)
+		registration, err := service.Register(context.Background(), request)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.ClaimPending, registration.Status)
+		assert.Nil(t, registration.Device)
+		mockClaimRepo.AssertExpectations(t)
+	})
+	t.Run("Wrong token", func(t *testing.T) {
+		mockClaimRepo := new(mocks.ClaimRepositoryInterface)
+		mockClaimRepo.On("GetBySerialNumber", mock.Anything, "WQ-0001").Return(approved(), nil)
+		service := provisioning.NewService(mockClaimRepo, nil, nil, nil, nil, nil, nil, provisioningConfig)
+		_, err := service.Register(context.Background(), domain.RegistrationRequest{SerialNumber: "WQ-0001", ClaimToken: "guess"})
+		assert.ErrorIs(t, err, domain.ErrClaimRefused)
+	})
+	t.Run("Token already used", func(t *testing.T) {
+		claim := approved()
+		claim.Status = domain.ClaimClaimed
+		mockClaimRepo := new(mocks.ClaimRepositoryInterface)
+		mockClaimRepo.On("GetBySerialNumber", mock.Anything, "WQ-0001").Return(claim, nil)
+		service := provisioning.NewService(mockClaimRepo, nil, nil, nil, nil, nil, nil, provisioningConfig)
+		_, err := service.Register(context.Background(), request)
+		assert.ErrorIs(t, err, domain.ErrClaimTransition)
+	})
+	t.Run("Device creation fails", func(t *testing.T) {
+		mockClaimRepo := new(mocks.ClaimRepositoryInterface)
+		mockClaimRepo.On("GetBySerialNumber", mock.Anything, "WQ-0001").Return(approved(), nil)
+		mockClaimRepo.On("UpdateStatus", mock.Anything, mock.Anything, domain.ClaimApproved).Return(nil)
+		// The claim is given back so that the device can retry
+		mockClaimRepo.On("UpdateStatus", mock.Anything, mock.MatchedBy(func(c *domain.ProvisioningClaim) bool {
+			return c.Status == domain.ClaimApproved
+		}), domain.ClaimClaimed).Return(nil)
+		mockTemplateRepo := new(mocks.TemplateRepositoryInterface)
+		mockTemplateRepo.On("GetByModel", mock.Anything, "WQ-5").Return(nil, nil)
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("Create", mock.Anything, mock.Anything).Return(errors.New("error"))
+		service := provisioning.NewService(mockClaimRepo, mockTemplateRepo, mockDeviceRepo, nil, nil, nil, nil, provisioningConfig)
+		_, err := service.Register(context.Background(), request)
+		assert.Error(t, err)
+		mockClaimRepo.AssertExpectations(t)
+	})
+	t.Run("Claim give-back fails", func(t *testing.T) {
+		mockClaimRepo := new(mocks.ClaimRepositoryInterface)
+		mockClaimRepo.On("GetBySerialNumber", mock.Anything, "WQ-0001").Return(approved(), nil)
+		mockClaimRepo.On("UpdateStatus", mock.Anything, mock.Anything, domain.ClaimApproved).Return(nil)
+		giveBackErr := errors.New("give back")
+		mockClaimRepo.On("UpdateStatus", mock.Anything, mock.Anything, domain.ClaimClaimed).Return(giveBackErr)
+		mockTemplateRepo := new(mocks.TemplateRepositoryInterface)
+		mockTemplateRepo.On("GetByModel", mock.Anything, "WQ-5").Return(nil, nil)
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		createErr := errors.New("create")
+		mockDeviceRepo.On("Create", mock.Anything, mock.Anything).Return(createErr)
+		service := provisioning.NewService(mockClaimRepo, mockTemplateRepo, mockDeviceRepo, nil, nil, nil, nil, provisioningConfig)
+		_, err := service.Register(context.Background(), request)
+		assert.ErrorIs(t, err, createErr)
+		assert.ErrorIs(t, err, giveBackErr)
+	})
+	t.Run("Sensor creation fails", func(t *testing.T) {
+		deviceID := primitive.NewObjectID()
+		sensorID := primitive.NewObjectID()
+		mockClaimRepo := new(mocks.ClaimRepositoryInterface)
+		mockClaimRepo.On("GetBySerialNumber", mock.Anything, "WQ-0001").Return(approved(), nil)
+		mockClaimRepo.On("UpdateStatus", mock.Anything, mock.Anything, domain.ClaimApproved).Return(nil)
+		mockClaimRepo.On("UpdateStatus", mock.Anything, mock.MatchedBy(func(c *domain.ProvisioningClaim) bool {
+			return c.Status == domain.ClaimApproved
+		}), domain.ClaimClaimed).Return(nil).Once()
+		mockTemplateRepo := new(mocks.TemplateRepositoryInterface)
+		mockTemplateRepo.On("GetByModel", mock.Anything, "WQ-5").Return(&domain.DeviceTemplate{
+			Model:   "WQ-5",
+			Sensors: []domain.TemplateSensor{{Name: "pH probe"}, {Name: "COD probe"}},
+		}, nil)
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.DeviceRequest).ID = deviceID
+		}).Return(nil)
+		mockDeviceRepo.On("Delete", mock.Anything, deviceID.Hex()).Return(nil).Once()
+		mockSensorRepo := new(mocks.SensorRepositoryInterface)
+		mockSensorRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.SensorRequest).ID = sensorID
+		}).Return(nil).Once()
+		mockSensorRepo.On("Create", mock.Anything, mock.Anything).Return(errors.New("error")).Once()
+		mockSensorRepo.On("Delete", mock.Anything, sensorID.Hex()).Return(nil).Once()
+		service := provisioning.NewService(mockClaimRepo, mockTemplateRepo, mockDeviceRepo, mockSensorRepo, nil, nil, nil, provisioningConfig)
+		_, err := service.Register(context.Background(), request)
+		assert.Error(t, err)
+		mockClaimRepo.AssertExpectations(t)
+		mockDeviceRepo.AssertExpectations(t)
+		mockSensorRepo.AssertExpectations(t)
+	})
+	t.Run("Claim update fails", func(t *testing.T) {
+		deviceID := primitive.NewObjectID()
+		credentialID := primitive.NewObjectID()
+		mockClaimRepo := new(mocks.ClaimRepositoryInterface)
+		mockClaimRepo.On("GetBySerialNumber", mock.Anything, "WQ-0001").Return(approved(), nil)
+		mockClaimRepo.On("UpdateStatus", mock.Anything, mock.Anything, domain.ClaimApproved).Return(nil)
+		mockClaimRepo.On("UpdateStatus", mock.Anything, mock.MatchedBy(func(c *domain.ProvisioningClaim) bool {
+			return c.Status == domain.ClaimClaimed
+		}), domain.ClaimClaimed).Return(errors.New("error")).Once()
+		mockClaimRepo.On("UpdateStatus", mock.Anything, mock.MatchedBy(func(c *domain.ProvisioningClaim) bool {
+			return c.Status == domain.ClaimApproved && c.DeviceID.IsZero()
+		}), domain.ClaimClaimed).Return(nil).Once()
+		mockTemplateRepo := new(mocks.TemplateRepositoryInterface)
+		mockTemplateRepo.On("GetByModel", mock.Anything, "WQ-5").Return(nil, nil)
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.DeviceRequest).ID = deviceID
+		}).Return(nil)
+		mockDeviceRepo.On("Delete", mock.Anything, deviceID.Hex()).Return(nil).Once()
+		mockCredentialRepo := new(mocks.CredentialRepositoryInterface)
+		mockCredentialRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.DeviceCredential).ID = credentialID
+		}).Return(nil)
+		mockCredentialRepo.On("Delete", mock.Anything, credentialID.Hex()).Return(nil).Once()
+		mockPSKIssuer := new(mocks.PSKIssuerInterface)
+		mockPSKIssuer.On("Rotate", mock.Anything, deviceID.Hex()).Return(&domain.PSKSecret{Identity: deviceID.Hex()}, nil)
+		service := provisioning.NewService(mockClaimRepo, mockTemplateRepo, mockDeviceRepo, nil, mockCredentialRepo, mockPSKIssuer, nil, provisioningConfig)
+		_, err := service.Register(context.Background(), request)
+		assert.Error(t, err)
+		mockClaimRepo.AssertExpectations(t)
+		mockDeviceRepo.AssertExpectations(t)
+		mockCredentialRepo.AssertExpectations(t)
+	})
+}
+
+func TestServiceApprove(t *testing.T) {
+	claimID := primitive.NewObjectID()
+	siteID := primitive.NewObjectID()
+	t.Run("Success", func(t *testing.T) {
+		mockClaimRepo := new(mocks.ClaimRepositoryInterface)
+		mockClaimRepo.On("GetByID", mock.Anything, claimID.Hex()).Return(&domain.ProvisioningClaim{ID: claimID, Status: domain.ClaimPending, Model: "WQ-5"}, nil)
+		mockClaimRepo.On("UpdateStatus", mock.Anything, mock.Anything, domain.ClaimPending).Return(nil)
+		service := provisioning.NewService(mockClaimRepo, nil, nil, nil, nil, nil, nil, provisioningConfig)
+		claim, err := service.Approve(context.Background(), claimID.Hex(), domain.ClaimDecision{SiteID: siteID, DecidedBy: "ops"})
+		assert.NoError(t, err)
+		assert.Equal(t, domain.ClaimApproved, claim.Status)
+		assert.Equal(t, siteID, claim.SiteID)
+		assert.Equal(t, "WQ-5", claim.Model)
+	})
+	t.Run("Already decided", func(t *testing.T) {
+		mockClaimRepo := new(mocks.ClaimRepositoryInterface)
+		mockClaimRepo.On("GetByID", mock.Anything, claimID.Hex()).Return(&domain.ProvisioningClaim{ID: claimID, Status: domain.ClaimRejected}, nil)
+		service := provisioning.NewService(mockClaimRepo, nil, nil, nil, nil, nil, nil, provisioningConfig)
+		_, err := service.Approve(context.Background(), claimID.Hex(), domain.ClaimDecision{})
+		assert.ErrorIs(t, err, domain.ErrClaimTransition)
+	})
+	t.Run("Not found", func(t *testing.T) {
+		mockClaimRepo := new(mocks.ClaimRepositoryInterface)
+		mockClaimRepo.On("GetByID", mock.Anything, claimID.Hex()).Return(nil, nil)
+		service := provisioning.NewService(mockClaimRepo, nil, nil, nil, nil, nil, nil, provisioningConfig)
+		claim, err := service.Reject(context.Background(), claimID.Hex(), domain.ClaimDecision{})
+		assert.NoError(t, err)
+		assert.Nil(t, claim)
+	})
+}
+
+func TestServiceCreateClaim(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockClaimRepo := new(mocks.ClaimRepositoryInterface)
+		mockClaimRepo.On("Create", mock.Anything, mock.MatchedBy(func(c *domain.ProvisioningClaim) bool {
+			return c.Status == domain.ClaimApproved && c.TokenHash != "s3cret" && c.Matches("s3cret")
+		})).Return(nil)
+		service := provisioning.NewService(mockClaimRepo, nil, nil, nil, nil, nil, nil, provisioningConfig)
+		claim, err := service.CreateClaim(context.Background(), domain.ClaimRequest{SerialNumber: "WQ-0001", ClaimToken: "s3cret"})
+		assert.NoError(t, err)
+		assert.Equal(t, domain.ClaimApproved, claim.Status)
+	})
+	t.Run("No token", func(t *testing.T) {
+		service := provisioning.NewService(nil, nil, nil, nil, nil, nil, nil, provisioningConfig)
+		_, err := service.CreateClaim(context.Background(), domain.ClaimRequest{SerialNumber: "WQ-0001"})
+		assert.ErrorIs(t, err, domain.ErrInvalidProvisioning)
+	})
+}
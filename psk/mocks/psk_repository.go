@@ -0,0 +1,77 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PSKRepositoryInterface is an autogenerated mock type for the PSKRepositoryInterface type
+type PSKRepositoryInterface struct {
+	mock.Mock
+}
+
+// GetByIdentity provides a mock function with given fields: ctx, identity
+func (_m *PSKRepositoryInterface) GetByIdentity(ctx context.Context, identity string) (*domain.DevicePSK, error) {
+	ret := _m.Called(ctx, identity)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByIdentity")
+	}
+
+	var r0 *domain.DevicePSK
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.DevicePSK, error)); ok {
+		return rf(ctx, identity)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.DevicePSK); ok {
+		r0 = rf(ctx, identity)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.DevicePSK)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, identity)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Save provides a mock function with given fields: ctx, _a1
+func (_m *PSKRepositoryInterface) Save(ctx context.Context, _a1 *domain.DevicePSK) error {
+	ret := _m.Called(ctx, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.DevicePSK) error); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewPSKRepositoryInterface creates a new instance of PSKRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPSKRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PSKRepositoryInterface {
+	mock := &PSKRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,100 @@
+package psk
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.opentelemetry.io/otel"
+)
+
+// tracer starts the spans of the service methods
+var tracer = otel.Tracer("github.com/anggi-susanto/mrt-go/psk")
+
+// keySize is the size of the issued keys, 128 bits as the AES PSK cipher suites use
+const keySize = 16
+
+// PSKRepositoryInterface is the interface that wraps the Save and GetByIdentity methods.
+type PSKRepositoryInterface interface {
+	Save(ctx context.Context, psk *domain.DevicePSK) error
+	GetByIdentity(ctx context.Context, identity string) (*domain.DevicePSK, error)
+}
+
+// DeviceRepositoryInterface is the interface that wraps the GetByID method.
+type DeviceRepositoryInterface interface {
+	GetByID(ctx context.Context, id string) (*domain.Device, error)
+}
+
+// Service is the interface that wraps the pre-shared keys of the devices.
+type Service struct {
+	pskRepository    PSKRepositoryInterface
+	deviceRepository DeviceRepositoryInterface
+}
+
+// NewService creates a new instance of the Service struct, initializing it with the provided dependencies.
+//
+// Parameters:
+// - pskRepository: The PSKRepositoryInterface implementation storing the keys.
+// - deviceRepository: The DeviceRepositoryInterface implementation used to check the device exists.
+//
+// Returns:
+// - A pointer to the newly created Service instance.
+func NewService(pskRepository PSKRepositoryInterface, deviceRepository DeviceRepositoryInterface) *Service {
+	return &Service{
+		pskRepository:    pskRepository,
+		deviceRepository: deviceRepository,
+	}
+}
+
+// Rotate issues a new pre-shared key to a device, replacing the previous one.
+//
+// ctx - context.Context for the operation.
+// deviceID - string representing the ID of the device.
+// Returns the key, nil when the device does not exist, and an error.
+func (s *Service) Rotate(ctx context.Context, deviceID string) (*domain.PSKSecret, error) {
+	ctx, span := tracer.Start(ctx, "psk.Service.Rotate")
+	defer span.End()
+	device, err := s.deviceRepository.GetByID(ctx, deviceID)
+	if err != nil || device == nil {
+		return nil, err
+	}
+	if device.Status == domain.DeviceDecommissioned {
+		return nil, fmt.Errorf("%w: device %s", domain.ErrDeviceDecommissioned, device.ID.Hex())
+	}
+	key := make([]byte, keySize)
+	if _, err = rand.Read(key); err != nil {
+		return nil, err
+	}
+	psk := &domain.DevicePSK{
+		DeviceID:  device.ID,
+		Identity:  device.ID.Hex(),
+		Key:       key,
+		CreatedAt: domain.MyTime{Time: time.Now()},
+	}
+	if err = s.pskRepository.Save(ctx, psk); err != nil {
+		return nil, err
+	}
+	return &domain.PSKSecret{Identity: psk.Identity, Key: hex.EncodeToString(key), CreatedAt: psk.CreatedAt}, nil
+}
+
+// PSK returns the key of a PSK identity for a DTLS handshake.
+//
+// ctx - context.Context for the operation.
+// identity - the PSK identity the device sent.
+// Returns the key, nil when the identity is unknown or its device was decommissioned, and an error.
+func (s *Service) PSK(ctx context.Context, identity string) ([]byte, error) {
+	ctx, span := tracer.Start(ctx, "psk.Service.PSK")
+	defer span.End()
+	psk, err := s.pskRepository.GetByIdentity(ctx, identity)
+	if err != nil || psk == nil {
+		return nil, err
+	}
+	device, err := s.deviceRepository.GetByID(ctx, psk.DeviceID.Hex())
+	if err != nil || device == nil || device.Status == domain.DeviceDecommissioned {
+		return nil, err
+	}
+	return psk.Key, nil
+}
@@ -0,0 +1,84 @@
+package psk_test
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/psk"
+	"github.com/anggi-susanto/mrt-go/psk/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestServiceRotate(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	t.Run("Success", func(t *testing.T) {
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, deviceID.Hex()).Return(&domain.Device{ID: deviceID, Status: domain.DeviceActive}, nil)
+		var stored *domain.DevicePSK
+		mockRepo := new(mocks.PSKRepositoryInterface)
+		mockRepo.On("Save", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(1).(*domain.DevicePSK)
+		}).Return(nil)
+
+		secret, err := psk.NewService(mockRepo, mockDeviceRepo).Rotate(context.Background(), deviceID.Hex())
+		assert.NoError(t, err)
+		assert.Equal(t, deviceID.Hex(), secret.Identity)
+		assert.Equal(t, deviceID, stored.DeviceID)
+		assert.Equal(t, hex.EncodeToString(stored.Key), secret.Key)
+		assert.Len(t, stored.Key, 16)
+	})
+	t.Run("Device not found", func(t *testing.T) {
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, deviceID.Hex()).Return(nil, nil)
+		secret, err := psk.NewService(new(mocks.PSKRepositoryInterface), mockDeviceRepo).Rotate(context.Background(), deviceID.Hex())
+		assert.NoError(t, err)
+		assert.Nil(t, secret)
+	})
+	t.Run("Decommissioned", func(t *testing.T) {
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, deviceID.Hex()).Return(&domain.Device{ID: deviceID, Status: domain.DeviceDecommissioned}, nil)
+		mockRepo := new(mocks.PSKRepositoryInterface)
+		_, err := psk.NewService(mockRepo, mockDeviceRepo).Rotate(context.Background(), deviceID.Hex())
+		assert.ErrorIs(t, err, domain.ErrDeviceDecommissioned)
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+}
+
+func TestServicePSK(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	key := []byte("0123456789abcdef")
+	lookup := func(status string) *psk.Service {
+		mockRepo := new(mocks.PSKRepositoryInterface)
+		mockRepo.On("GetByIdentity", mock.Anything, deviceID.Hex()).Return(&domain.DevicePSK{DeviceID: deviceID, Identity: deviceID.Hex(), Key: key}, nil)
+		mockRepo.On("GetByIdentity", mock.Anything, mock.Anything).Return(nil, nil)
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, deviceID.Hex()).Return(&domain.Device{ID: deviceID, Status: status}, nil)
+		return psk.NewService(mockRepo, mockDeviceRepo)
+	}
+	t.Run("Success", func(t *testing.T) {
+		got, err := lookup(domain.DeviceActive).PSK(context.Background(), deviceID.Hex())
+		assert.NoError(t, err)
+		assert.Equal(t, key, got)
+	})
+	t.Run("Unknown identity", func(t *testing.T) {
+		got, err := lookup(domain.DeviceActive).PSK(context.Background(), "logger-1")
+		assert.NoError(t, err)
+		assert.Nil(t, got)
+	})
+	t.Run("Decommissioned", func(t *testing.T) {
+		got, err := lookup(domain.DeviceDecommissioned).PSK(context.Background(), deviceID.Hex())
+		assert.NoError(t, err)
+		assert.Nil(t, got)
+	})
+	t.Run("Error", func(t *testing.T) {
+		mockRepo := new(mocks.PSKRepositoryInterface)
+		mockRepo.On("GetByIdentity", mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+		_, err := psk.NewService(mockRepo, new(mocks.DeviceRepositoryInterface)).PSK(context.Background(), deviceID.Hex())
+		assert.Error(t, err)
+	})
+}
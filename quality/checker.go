@@ -0,0 +1,99 @@
+package quality
+
+import (
+	"math"
+
+	"github.com/anggi-susanto/mrt-go/config"
+	"github.com/anggi-susanto/mrt-go/domain"
+)
+
+// minSpikeHistory is the number of previous values needed before spikes are detected
+const minSpikeHistory = 5
+
+// Checker runs the automated spike, flatline and rate of change checks on a measurement.
+//
+// The range check needs the sensor catalog and is run by the measurement pipeline.
+type Checker struct {
+	config config.QualityConfig
+}
+
+// NewChecker creates a new Checker.
+//
+// Parameters:
+// - config: the thresholds of the checks, zero thresholds disable the matching check.
+//
+// Returns a pointer to a Checker.
+func NewChecker(config config.QualityConfig) *Checker {
+	return &Checker{config: config}
+}
+
+// HistorySize returns the number of previous measurements Check expects.
+func (c *Checker) HistorySize() int {
+	return c.config.HistorySize
+}
+
+// Check returns the names of the checks the measurement fails.
+//
+// m: the measurement to check.
+// history: the previous measurements of the same device and parameter, oldest first.
+func (c *Checker) Check(m domain.Measurement, history []domain.Measurement) []string {
+	var failed []string
+	if c.isSpike(m, history) {
+		failed = append(failed, domain.CheckSpike)
+	}
+	if c.isFlatline(m, history) {
+		failed = append(failed, domain.CheckFlatline)
+	}
+	if c.isRateOfChangeExceeded(m, history) {
+		failed = append(failed, domain.CheckRateOfChange)
+	}
+	return failed
+}
+
+// isSpike reports whether the value lies more than SpikeFactor standard deviations from the recent mean.
+func (c *Checker) isSpike(m domain.Measurement, history []domain.Measurement) bool {
+	if c.config.SpikeFactor <= 0 || len(history) < minSpikeHistory {
+		return false
+	}
+	var sum float64
+	for _, h := range history {
+		sum += h.Value
+	}
+	mean := sum / float64(len(history))
+	var squares float64
+	for _, h := range history {
+		squares += (h.Value - mean) * (h.Value - mean)
+	}
+	deviation := math.Sqrt(squares / float64(len(history)))
+	if deviation == 0 {
+		return false
+	}
+	return math.Abs(m.Value-mean) > c.config.SpikeFactor*deviation
+}
+
+// isFlatline reports whether the value repeats the last FlatlineCount-1 values exactly.
+func (c *Checker) isFlatline(m domain.Measurement, history []domain.Measurement) bool {
+	if c.config.FlatlineCount < 2 || len(history) < c.config.FlatlineCount-1 {
+		return false
+	}
+	for _, h := range history[len(history)-c.config.FlatlineCount+1:] {
+		if h.Value != m.Value {
+			return false
+		}
+	}
+	return true
+}
+
+// isRateOfChangeExceeded reports whether the value changed faster than the parameter allows since the previous value.
+func (c *Checker) isRateOfChangeExceeded(m domain.Measurement, history []domain.Measurement) bool {
+	maxRate, ok := c.config.MaxRateOfChange[m.Parameter]
+	if !ok || len(history) == 0 {
+		return false
+	}
+	previous := history[len(history)-1]
+	minutes := m.Timestamp.Sub(previous.Timestamp).Minutes()
+	if minutes <= 0 {
+		return false
+	}
+	return math.Abs(m.Value-previous.Value)/minutes > maxRate
+}
@@ -0,0 +1,79 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MeasurementRepositoryInterface is an autogenerated mock type for the MeasurementRepositoryInterface type
+type MeasurementRepositoryInterface struct {
+	mock.Mock
+}
+
+// GetByIDs provides a mock function with given fields: ctx, ids
+func (_m *MeasurementRepositoryInterface) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]domain.Measurement, error) {
+	ret := _m.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByIDs")
+	}
+
+	var r0 []domain.Measurement
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []primitive.ObjectID) ([]domain.Measurement, error)); ok {
+		return rf(ctx, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []primitive.ObjectID) []domain.Measurement); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Measurement)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []primitive.ObjectID) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateQuality provides a mock function with given fields: ctx, ids, _a2
+func (_m *MeasurementRepositoryInterface) UpdateQuality(ctx context.Context, ids []primitive.ObjectID, _a2 string) error {
+	ret := _m.Called(ctx, ids, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateQuality")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []primitive.ObjectID, string) error); ok {
+		r0 = rf(ctx, ids, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewMeasurementRepositoryInterface creates a new instance of MeasurementRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMeasurementRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MeasurementRepositoryInterface {
+	mock := &MeasurementRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,79 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReviewRepositoryInterface is an autogenerated mock type for the ReviewRepositoryInterface type
+type ReviewRepositoryInterface struct {
+	mock.Mock
+}
+
+// CreateMany provides a mock function with given fields: ctx, reviews
+func (_m *ReviewRepositoryInterface) CreateMany(ctx context.Context, reviews []domain.QualityReview) error {
+	ret := _m.Called(ctx, reviews)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateMany")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.QualityReview) error); ok {
+		r0 = rf(ctx, reviews)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetByMeasurementID provides a mock function with given fields: ctx, measurementID
+func (_m *ReviewRepositoryInterface) GetByMeasurementID(ctx context.Context, measurementID primitive.ObjectID) ([]domain.QualityReview, error) {
+	ret := _m.Called(ctx, measurementID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByMeasurementID")
+	}
+
+	var r0 []domain.QualityReview
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID) ([]domain.QualityReview, error)); ok {
+		return rf(ctx, measurementID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID) []domain.QualityReview); ok {
+		r0 = rf(ctx, measurementID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.QualityReview)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, primitive.ObjectID) error); ok {
+		r1 = rf(ctx, measurementID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewReviewRepositoryInterface creates a new instance of ReviewRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewReviewRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ReviewRepositoryInterface {
+	mock := &ReviewRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
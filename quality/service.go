@@ -0,0 +1,116 @@
+package quality
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+)
+
+// tracer starts the spans of the service methods
+var tracer = otel.Tracer("github.com/anggi-susanto/mrt-go/quality")
+
+// MeasurementRepositoryInterface is the interface that wraps the GetByIDs and UpdateQuality methods.
+type MeasurementRepositoryInterface interface {
+	GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]domain.Measurement, error)
+	UpdateQuality(ctx context.Context, ids []primitive.ObjectID, quality string) error
+}
+
+// ReviewRepositoryInterface is the interface that wraps the quality review audit storage methods.
+type ReviewRepositoryInterface interface {
+	CreateMany(ctx context.Context, reviews []domain.QualityReview) error
+	GetByMeasurementID(ctx context.Context, measurementID primitive.ObjectID) ([]domain.QualityReview, error)
+}
+
+// Service is the QA/QC workflow letting reviewers change the quality flag of measurements.
+type Service struct {
+	measurementRepository MeasurementRepositoryInterface
+	reviewRepository      ReviewRepositoryInterface
+}
+
+// NewService creates a new instance of the Service struct, initializing it with the provided repositories.
+//
+// Parameters:
+// - measurementRepository: The MeasurementRepositoryInterface implementation holding the measurements.
+// - reviewRepository: The ReviewRepositoryInterface implementation storing the audit trail.
+//
+// Returns:
+// - A pointer to the newly created Service instance.
+func NewService(measurementRepository MeasurementRepositoryInterface, reviewRepository ReviewRepositoryInterface) *Service {
+	return &Service{
+		measurementRepository: measurementRepository,
+		reviewRepository:      reviewRepository,
+	}
+}
+
+// Review sets the quality flag of the measurements and records one audit review per measurement.
+//
+// The review is refused as a whole when one of the measurements does not exist or
+// cannot move to the requested flag.
+//
+// ctx - context.Context for the operation.
+// r - the review to apply.
+// Returns the recorded reviews and an error.
+func (s *Service) Review(ctx context.Context, r *domain.QualityReviewRequest) ([]domain.QualityReview, error) {
+	ctx, span := tracer.Start(ctx, "quality.Service.Review")
+	defer span.End()
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	measurements, err := s.measurementRepository.GetByIDs(ctx, r.MeasurementIDs)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[primitive.ObjectID]bool, len(measurements))
+	for _, m := range measurements {
+		found[m.ID] = true
+		if !domain.CanTransition(m.Quality, r.Quality) {
+			return nil, fmt.Errorf("%w: %s from %s to %s", domain.ErrQualityTransition, m.ID.Hex(), m.Quality, r.Quality)
+		}
+	}
+	for _, id := range r.MeasurementIDs {
+		if !found[id] {
+			return nil, fmt.Errorf("%w: measurement %s not found", domain.ErrInvalidQuality, id.Hex())
+		}
+	}
+
+	if err = s.measurementRepository.UpdateQuality(ctx, r.MeasurementIDs, r.Quality); err != nil {
+		return nil, err
+	}
+	now := domain.MyTime{Time: time.Now()}
+	reviews := make([]domain.QualityReview, 0, len(measurements))
+	for _, m := range measurements {
+		reviews = append(reviews, domain.QualityReview{
+			MeasurementID: m.ID,
+			ReadingID:     m.ReadingID,
+			Parameter:     m.Parameter,
+			From:          m.Quality,
+			To:            r.Quality,
+			Reason:        r.Reason,
+			Reviewer:      r.Reviewer,
+			CreatedAt:     now,
+		})
+	}
+	if err = s.reviewRepository.CreateMany(ctx, reviews); err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// GetReviews retrieves the audit trail of a measurement, oldest first.
+//
+// ctx - context.Context for the operation.
+// measurementID - string representing the ID of the measurement.
+// Returns the reviews and an error.
+func (s *Service) GetReviews(ctx context.Context, measurementID string) ([]domain.QualityReview, error) {
+	ctx, span := tracer.Start(ctx, "quality.Service.GetReviews")
+	defer span.End()
+	id, err := primitive.ObjectIDFromHex(measurementID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidQuality, err)
+	}
+	return s.reviewRepository.GetByMeasurementID(ctx, id)
+}
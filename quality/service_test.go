@@ -0,0 +1,93 @@
+package quality_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/quality"
+	"github.com/anggi-susanto/mrt-go/quality/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestServiceReview(t *testing.T) {
+	suspect := domain.Measurement{ID: primitive.NewObjectID(), ReadingID: primitive.NewObjectID(), Parameter: domain.ParamPH, Quality: domain.QualitySuspect}
+	raw := domain.Measurement{ID: primitive.NewObjectID(), ReadingID: suspect.ReadingID, Parameter: domain.ParamBOD, Quality: domain.QualityRaw}
+	ids := []primitive.ObjectID{suspect.ID, raw.ID}
+
+	t.Run("Success", func(t *testing.T) {
+		mockMeasurementRepo := new(mocks.MeasurementRepositoryInterface)
+		mockReviewRepo := new(mocks.ReviewRepositoryInterface)
+		mockMeasurementRepo.On("GetByIDs", mock.Anything, ids).Return([]domain.Measurement{suspect, raw}, nil)
+		mockMeasurementRepo.On("UpdateQuality", mock.Anything, ids, domain.QualityValidated).Return(nil)
+		mockReviewRepo.On("CreateMany", mock.Anything, mock.Anything).Return(nil)
+		s := quality.NewService(mockMeasurementRepo, mockReviewRepo)
+		reviews, err := s.Review(context.Background(), &domain.QualityReviewRequest{
+			MeasurementIDs: ids,
+			Quality:        domain.QualityValidated,
+			Reason:         "checked against lab sample",
+			Reviewer:       "operator",
+		})
+		assert.NoError(t, err)
+		assert.Len(t, reviews, 2)
+		assert.Equal(t, domain.QualitySuspect, reviews[0].From)
+		assert.Equal(t, domain.QualityValidated, reviews[0].To)
+		assert.Equal(t, "checked against lab sample", reviews[1].Reason)
+		mockMeasurementRepo.AssertExpectations(t)
+		mockReviewRepo.AssertExpectations(t)
+	})
+	t.Run("Reason required", func(t *testing.T) {
+		mockMeasurementRepo := new(mocks.MeasurementRepositoryInterface)
+		s := quality.NewService(mockMeasurementRepo, new(mocks.ReviewRepositoryInterface))
+		_, err := s.Review(context.Background(), &domain.QualityReviewRequest{MeasurementIDs: ids, Quality: domain.QualityInvalid})
+		assert.ErrorIs(t, err, domain.ErrInvalidQuality)
+		mockMeasurementRepo.AssertNotCalled(t, "GetByIDs")
+	})
+	t.Run("Approve unvalidated", func(t *testing.T) {
+		mockMeasurementRepo := new(mocks.MeasurementRepositoryInterface)
+		mockMeasurementRepo.On("GetByIDs", mock.Anything, ids).Return([]domain.Measurement{suspect, raw}, nil)
+		s := quality.NewService(mockMeasurementRepo, new(mocks.ReviewRepositoryInterface))
+		_, err := s.Review(context.Background(), &domain.QualityReviewRequest{MeasurementIDs: ids, Quality: domain.QualityApproved, Reason: "ok"})
+		assert.ErrorIs(t, err, domain.ErrQualityTransition)
+		mockMeasurementRepo.AssertNotCalled(t, "UpdateQuality")
+	})
+	t.Run("Unknown measurement", func(t *testing.T) {
+		mockMeasurementRepo := new(mocks.MeasurementRepositoryInterface)
+		mockMeasurementRepo.On("GetByIDs", mock.Anything, ids).Return([]domain.Measurement{suspect}, nil)
+		s := quality.NewService(mockMeasurementRepo, new(mocks.ReviewRepositoryInterface))
+		_, err := s.Review(context.Background(), &domain.QualityReviewRequest{MeasurementIDs: ids, Quality: domain.QualityInvalid, Reason: "fouled probe"})
+		assert.ErrorIs(t, err, domain.ErrInvalidQuality)
+		mockMeasurementRepo.AssertNotCalled(t, "UpdateQuality")
+	})
+	t.Run("Error", func(t *testing.T) {
+		mockMeasurementRepo := new(mocks.MeasurementRepositoryInterface)
+		mockMeasurementRepo.On("GetByIDs", mock.Anything, ids).Return([]domain.Measurement{suspect, raw}, nil)
+		mockMeasurementRepo.On("UpdateQuality", mock.Anything, ids, domain.QualityInvalid).Return(errors.New("error"))
+		mockReviewRepo := new(mocks.ReviewRepositoryInterface)
+		s := quality.NewService(mockMeasurementRepo, mockReviewRepo)
+		_, err := s.Review(context.Background(), &domain.QualityReviewRequest{MeasurementIDs: ids, Quality: domain.QualityInvalid, Reason: "fouled probe"})
+		assert.Error(t, err)
+		mockReviewRepo.AssertNotCalled(t, "CreateMany")
+	})
+}
+
+func TestServiceGetReviews(t *testing.T) {
+	id := primitive.NewObjectID()
+	t.Run("Success", func(t *testing.T) {
+		mockReviewRepo := new(mocks.ReviewRepositoryInterface)
+		mockReviewRepo.On("GetByMeasurementID", mock.Anything, id).Return([]domain.QualityReview{{MeasurementID: id}}, nil)
+		s := quality.NewService(new(mocks.MeasurementRepositoryInterface), mockReviewRepo)
+		reviews, err := s.GetReviews(context.Background(), id.Hex())
+		assert.NoError(t, err)
+		assert.Len(t, reviews, 1)
+	})
+	t.Run("Invalid ID", func(t *testing.T) {
+		mockReviewRepo := new(mocks.ReviewRepositoryInterface)
+		s := quality.NewService(new(mocks.MeasurementRepositoryInterface), mockReviewRepo)
+		_, err := s.GetReviews(context.Background(), "1")
+		assert.ErrorIs(t, err, domain.ErrInvalidQuality)
+	})
+}
@@ -4,8 +4,16 @@ import (
 	"context"
 
 	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
 )
 
+// tracer starts the spans of the service methods
+var tracer = otel.Tracer("github.com/anggi-susanto/mrt-go/sensor")
+
+// labSamples is the number of latest lab samples of its device a sensor is checked against
+const labSamples = 12
+
 // SensorRepositoryInterface is an autogenerated interface for SensorRepository
 type SensorRepositoryInterface interface {
 	Create(ctx context.Context, w *domain.SensorRequest) error
@@ -15,21 +23,46 @@ type SensorRepositoryInterface interface {
 	GetByID(ctx context.Context, id string) (*domain.Sensor, error)
 }
 
+// MeasurementRepositoryInterface is the interface that wraps the GetAll method of the measurement store.
+type MeasurementRepositoryInterface interface {
+	GetAll(ctx context.Context, filter domain.MeasurementFilter, page, limit int) ([]domain.Measurement, error)
+}
+
+// LabPairerInterface is the interface that wraps the SensorPairs method of the lab samples.
+type LabPairerInterface interface {
+	SensorPairs(ctx context.Context, sensorID, deviceID primitive.ObjectID, limit int) (map[string][]domain.LabSensorPair, error)
+}
+
+// DiagnoserInterface is the interface that wraps the sensor fault diagnostics.
+type DiagnoserInterface interface {
+	SampleSize() int
+	Diagnose(sensorID primitive.ObjectID, measurements []domain.Measurement, lab map[string][]domain.LabSensorPair) domain.SensorHealth
+}
+
 // Service is the interface that wraps the Create, GetAll, GetByID, Update, and Delete methods.
 type Service struct {
-	sensorRepository SensorRepositoryInterface
+	sensorRepository      SensorRepositoryInterface
+	measurementRepository MeasurementRepositoryInterface
+	labPairer             LabPairerInterface
+	diagnoser             DiagnoserInterface
 }
 
 // NewService creates a new instance of the Service struct, initializing it with the provided SensorRepositoryInterface.
 //
 // Parameters:
 // - sensorRepository: The SensorRepositoryInterface implementation used by the Service.
+// - measurementRepository: The MeasurementRepositoryInterface implementation holding the measurements diagnosed.
+// - labPairer: The LabPairerInterface implementation pairing the lab results with the measurements of a sensor.
+// - diagnoser: The DiagnoserInterface implementation computing the health of a sensor.
 //
 // Returns:
 // - A pointer to the newly created Service instance.
-func NewService(sensorRepository SensorRepositoryInterface) *Service {
+func NewService(sensorRepository SensorRepositoryInterface, measurementRepository MeasurementRepositoryInterface, labPairer LabPairerInterface, diagnoser DiagnoserInterface) *Service {
 	return &Service{
-		sensorRepository: sensorRepository,
+		sensorRepository:      sensorRepository,
+		measurementRepository: measurementRepository,
+		labPairer:             labPairer,
+		diagnoser:             diagnoser,
 	}
 }
 
@@ -39,6 +72,8 @@ func NewService(sensorRepository SensorRepositoryInterface) *Service {
 // w: The waste water data to be created.
 // Returns an error if there was a problem creating the record.
 func (s *Service) Create(ctx context.Context, w *domain.SensorRequest) error {
+	ctx, span := tracer.Start(ctx, "sensor.Service.Create")
+	defer span.End()
 	return s.sensorRepository.Create(ctx, w)
 }
 
@@ -47,16 +82,57 @@ func (s *Service) Create(ctx context.Context, w *domain.SensorRequest) error {
 // ctx context.Context, page int, limit int
 // []domain.SensorData, error
 func (s *Service) GetAll(ctx context.Context, page, limit int) ([]domain.Sensor, error) {
+	ctx, span := tracer.Start(ctx, "sensor.Service.GetAll")
+	defer span.End()
 	return s.sensorRepository.GetAll(ctx, page, limit)
 }
 
-// GetByID retrieves a SensorData by ID.
+// GetByID retrieves a SensorData by ID along with its health.
 //
 // ctx - context.Context for the operation.
 // id - string representing the ID of the data.
 // Returns a pointer to domain.SensorData and an error.
 func (s *Service) GetByID(ctx context.Context, id string) (*domain.Sensor, error) {
-	return s.sensorRepository.GetByID(ctx, id)
+	ctx, span := tracer.Start(ctx, "sensor.Service.GetByID")
+	defer span.End()
+	sensor, err := s.sensorRepository.GetByID(ctx, id)
+	if err != nil || sensor == nil {
+		return sensor, err
+	}
+	health, err := s.health(ctx, sensor)
+	if err != nil {
+		return nil, err
+	}
+	sensor.Health = &health
+	return sensor, nil
+}
+
+// health diagnoses the recent measurements of the sensor against those of its co-located sensors
+// and the latest lab samples of its device.
+func (s *Service) health(ctx context.Context, sensor *domain.Sensor) (domain.SensorHealth, error) {
+	sampleSize := s.diagnoser.SampleSize()
+	own, err := s.measurementRepository.GetAll(ctx, domain.MeasurementFilter{SensorID: sensor.ID}, 1, sampleSize)
+	if err != nil {
+		return domain.SensorHealth{}, err
+	}
+	if len(own) == 0 {
+		return s.diagnoser.Diagnose(sensor.ID, nil, nil), nil
+	}
+	// own is newest first, the device measurements cover the same period
+	filter := domain.MeasurementFilter{
+		DeviceID: sensor.DeviceID,
+		From:     own[len(own)-1].Timestamp,
+		To:       own[0].Timestamp,
+	}
+	measurements, err := s.measurementRepository.GetAll(ctx, filter, 1, sampleSize*len(domain.WasteWaterParameters))
+	if err != nil {
+		return domain.SensorHealth{}, err
+	}
+	lab, err := s.labPairer.SensorPairs(ctx, sensor.ID, sensor.DeviceID, labSamples)
+	if err != nil {
+		return domain.SensorHealth{}, err
+	}
+	return s.diagnoser.Diagnose(sensor.ID, measurements, lab), nil
 }
 
 // Delete deletes a SensorData by ID.
@@ -65,6 +141,8 @@ func (s *Service) GetByID(ctx context.Context, id string) (*domain.Sensor, error
 // id - string representing the ID of the data to be deleted.
 // Returns an error if there was a problem deleting the data.
 func (s *Service) Delete(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "sensor.Service.Delete")
+	defer span.End()
 	return s.sensorRepository.Delete(ctx, id)
 }
 
@@ -74,5 +152,7 @@ func (s *Service) Delete(ctx context.Context, id string) error {
 // w - pointer to domain.SensorData representing the data to be updated.
 // Returns an error if there was a problem updating the data.
 func (s *Service) Update(ctx context.Context, w *domain.Sensor) error {
+	ctx, span := tracer.Start(ctx, "sensor.Service.Update")
+	defer span.End()
 	return s.sensorRepository.Update(ctx, w)
 }
@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/anggi-susanto/mrt-go/domain"
 	"github.com/anggi-susanto/mrt-go/sensor"
 	"github.com/anggi-susanto/mrt-go/sensor/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func TestServiceCreate(t *testing.T) {
@@ -19,14 +21,14 @@ func TestServiceCreate(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		mockSensorRepo := new(mocks.SensorRepositoryInterface)
 		mockSensorRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
-		s := sensor.NewService(mockSensorRepo)
+		s := sensor.NewService(mockSensorRepo, nil, nil, nil)
 		err := s.Create(context.Background(), &mockSensor)
 		assert.NoError(t, err)
 	})
 	t.Run("Error", func(t *testing.T) {
 		mockSensorRepo := new(mocks.SensorRepositoryInterface)
 		mockSensorRepo.On("Create", mock.Anything, mock.Anything).Return(errors.New("error")).Once()
-		s := sensor.NewService(mockSensorRepo)
+		s := sensor.NewService(mockSensorRepo, nil, nil, nil)
 		err := s.Create(context.Background(), &mockSensor)
 		assert.Error(t, err)
 	})
@@ -41,7 +43,7 @@ func TestServiceGetAll(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		mockSensorRepo := new(mocks.SensorRepositoryInterface)
 		mockSensorRepo.On("GetAll", mock.Anything, mock.Anything, mock.Anything).Return(mockSensor, nil)
-		s := sensor.NewService(mockSensorRepo)
+		s := sensor.NewService(mockSensorRepo, nil, nil, nil)
 		data, err := s.GetAll(context.Background(), 1, 10)
 		assert.Len(t, data, len(mockSensor))
 		assert.NoError(t, err)
@@ -49,7 +51,7 @@ func TestServiceGetAll(t *testing.T) {
 	t.Run("Error", func(t *testing.T) {
 		mockSensorRepo := new(mocks.SensorRepositoryInterface)
 		mockSensorRepo.On("GetAll", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("error")).Once()
-		s := sensor.NewService(mockSensorRepo)
+		s := sensor.NewService(mockSensorRepo, nil, nil, nil)
 		data, err := s.GetAll(context.Background(), 1, 10)
 		assert.Nil(t, data)
 		assert.Error(t, err)
@@ -63,35 +65,92 @@ func TestServiceUpdate(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		mockSensorRepo := new(mocks.SensorRepositoryInterface)
 		mockSensorRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
-		s := sensor.NewService(mockSensorRepo)
+		s := sensor.NewService(mockSensorRepo, nil, nil, nil)
 		err := s.Update(context.Background(), &mockSensor)
 		assert.NoError(t, err)
 	})
 	t.Run("Error", func(t *testing.T) {
 		mockSensorRepo := new(mocks.SensorRepositoryInterface)
 		mockSensorRepo.On("Update", mock.Anything, mock.Anything).Return(errors.New("error")).Once()
-		s := sensor.NewService(mockSensorRepo)
+		s := sensor.NewService(mockSensorRepo, nil, nil, nil)
 		err := s.Update(context.Background(), &mockSensor)
 		assert.Error(t, err)
 	})
 }
 
 func TestServiceGetByID(t *testing.T) {
+	now := time.Now()
 	mockSensor := domain.Sensor{
-		Name: "sensor",
+		ID:       primitive.NewObjectID(),
+		Name:     "sensor",
+		DeviceID: primitive.NewObjectID(),
+	}
+	own := []domain.Measurement{
+		{SensorID: mockSensor.ID, Parameter: domain.ParamCOD, Value: 0, Timestamp: now},
+		{SensorID: mockSensor.ID, Parameter: domain.ParamCOD, Value: 0, Timestamp: now.Add(-time.Hour)},
 	}
+	health := domain.SensorHealth{Score: 50, Status: domain.HealthDegraded, Samples: 2}
 	t.Run("Success", func(t *testing.T) {
+		found := mockSensor
+		mockSensorRepo := new(mocks.SensorRepositoryInterface)
+		mockSensorRepo.On("GetByID", mock.Anything, "1").Return(&found, nil)
+		mockMeasurementRepo := new(mocks.MeasurementRepositoryInterface)
+		mockMeasurementRepo.On("GetAll", mock.Anything, domain.MeasurementFilter{SensorID: mockSensor.ID}, 1, 10).Return(own, nil).Once()
+		deviceFilter := domain.MeasurementFilter{DeviceID: mockSensor.DeviceID, From: own[1].Timestamp, To: own[0].Timestamp}
+		mockMeasurementRepo.On("GetAll", mock.Anything, deviceFilter, 1, 10*len(domain.WasteWaterParameters)).Return(own, nil).Once()
+		lab := map[string][]domain.LabSensorPair{domain.ParamCOD: {{SampleID: "LAB-1", LabValue: 80, SensorValue: 0}}}
+		mockLabPairer := new(mocks.LabPairerInterface)
+		mockLabPairer.On("SensorPairs", mock.Anything, mockSensor.ID, mockSensor.DeviceID, mock.Anything).Return(lab, nil)
+		mockDiagnoser := new(mocks.DiagnoserInterface)
+		mockDiagnoser.On("SampleSize").Return(10)
+		mockDiagnoser.On("Diagnose", mockSensor.ID, own, lab).Return(health)
+		s := sensor.NewService(mockSensorRepo, mockMeasurementRepo, mockLabPairer, mockDiagnoser)
+		data, err := s.GetByID(context.Background(), "1")
+		assert.NoError(t, err)
+		assert.Equal(t, mockSensor.Name, data.Name)
+		assert.Equal(t, &health, data.Health)
+		mockMeasurementRepo.AssertExpectations(t)
+	})
+	t.Run("No measurements", func(t *testing.T) {
+		found := mockSensor
+		mockSensorRepo := new(mocks.SensorRepositoryInterface)
+		mockSensorRepo.On("GetByID", mock.Anything, "1").Return(&found, nil)
+		mockMeasurementRepo := new(mocks.MeasurementRepositoryInterface)
+		mockMeasurementRepo.On("GetAll", mock.Anything, mock.Anything, 1, 10).Return(nil, nil).Once()
+		mockDiagnoser := new(mocks.DiagnoserInterface)
+		mockDiagnoser.On("SampleSize").Return(10)
+		mockDiagnoser.On("Diagnose", mockSensor.ID, []domain.Measurement(nil), map[string][]domain.LabSensorPair(nil)).Return(domain.SensorHealth{Status: domain.HealthUnknown})
+		s := sensor.NewService(mockSensorRepo, mockMeasurementRepo, nil, mockDiagnoser)
+		data, err := s.GetByID(context.Background(), "1")
+		assert.NoError(t, err)
+		assert.Equal(t, domain.HealthUnknown, data.Health.Status)
+		mockMeasurementRepo.AssertExpectations(t)
+	})
+	t.Run("Not found", func(t *testing.T) {
 		mockSensorRepo := new(mocks.SensorRepositoryInterface)
-		mockSensorRepo.On("GetByID", mock.Anything, mock.Anything, mock.Anything).Return(&mockSensor, nil)
-		s := sensor.NewService(mockSensorRepo)
+		mockSensorRepo.On("GetByID", mock.Anything, "1").Return(nil, nil)
+		s := sensor.NewService(mockSensorRepo, nil, nil, nil)
 		data, err := s.GetByID(context.Background(), "1")
-		assert.Equal(t, data.Name, mockSensor.Name)
+		assert.Nil(t, data)
 		assert.NoError(t, err)
 	})
 	t.Run("Error", func(t *testing.T) {
 		mockSensorRepo := new(mocks.SensorRepositoryInterface)
 		mockSensorRepo.On("GetByID", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("error")).Once()
-		s := sensor.NewService(mockSensorRepo)
+		s := sensor.NewService(mockSensorRepo, nil, nil, nil)
+		data, err := s.GetByID(context.Background(), "1")
+		assert.Nil(t, data)
+		assert.Error(t, err)
+	})
+	t.Run("Measurement error", func(t *testing.T) {
+		found := mockSensor
+		mockSensorRepo := new(mocks.SensorRepositoryInterface)
+		mockSensorRepo.On("GetByID", mock.Anything, "1").Return(&found, nil)
+		mockMeasurementRepo := new(mocks.MeasurementRepositoryInterface)
+		mockMeasurementRepo.On("GetAll", mock.Anything, mock.Anything, 1, 10).Return(nil, errors.New("error"))
+		mockDiagnoser := new(mocks.DiagnoserInterface)
+		mockDiagnoser.On("SampleSize").Return(10)
+		s := sensor.NewService(mockSensorRepo, mockMeasurementRepo, nil, mockDiagnoser)
 		data, err := s.GetByID(context.Background(), "1")
 		assert.Nil(t, data)
 		assert.Error(t, err)
@@ -102,14 +161,14 @@ func TestServiceDelete(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		mockSensorRepo := new(mocks.SensorRepositoryInterface)
 		mockSensorRepo.On("Delete", mock.Anything, mock.Anything, mock.Anything).Return(nil)
-		s := sensor.NewService(mockSensorRepo)
+		s := sensor.NewService(mockSensorRepo, nil, nil, nil)
 		err := s.Delete(context.Background(), "1")
 		assert.NoError(t, err)
 	})
 	t.Run("Error", func(t *testing.T) {
 		mockSensorRepo := new(mocks.SensorRepositoryInterface)
 		mockSensorRepo.On("Delete", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("error")).Once()
-		s := sensor.NewService(mockSensorRepo)
+		s := sensor.NewService(mockSensorRepo, nil, nil, nil)
 		err := s.Delete(context.Background(), "1")
 		assert.Error(t, err)
 	})
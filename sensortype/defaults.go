@@ -0,0 +1,51 @@
+package sensortype
+
+import (
+	"github.com/anggi-susanto/mrt-go/domain"
+)
+
+// DefaultSensorTypes are the common probes seeded into an empty catalog
+var DefaultSensorTypes = []domain.SensorTypeRequest{
+	{
+		Code:        "ph-probe",
+		Name:        "pH probe",
+		Description: "Combination glass electrode pH probe",
+		Parameters: []domain.ParameterSpec{
+			{Parameter: domain.ParamPH, Unit: "pH", Resolution: 0.01, Min: 0, Max: 14, Accuracy: 0.1},
+		},
+	},
+	{
+		Code:        "do-optical",
+		Name:        "Optical dissolved oxygen probe",
+		Description: "Luminescent dissolved oxygen probe with temperature sensor",
+		Parameters: []domain.ParameterSpec{
+			{Parameter: domain.ParamDissolvedOxygen, Unit: "mg/L", Resolution: 0.01, Min: 0, Max: 20, Accuracy: 0.1},
+			{Parameter: domain.ParamTemperature, Unit: "°C", Resolution: 0.1, Min: 0, Max: 50, Accuracy: 0.2},
+		},
+	},
+	{
+		Code:        "turbidity-optical",
+		Name:        "Turbidity probe",
+		Description: "Nephelometric turbidity probe",
+		Parameters: []domain.ParameterSpec{
+			{Parameter: domain.ParamTurbidity, Unit: "NTU", Resolution: 0.01, Min: 0, Max: 4000, Accuracy: 0.3},
+		},
+	},
+	{
+		Code:        "conductivity-4e",
+		Name:        "Conductivity probe",
+		Description: "Four electrode conductivity probe",
+		Parameters: []domain.ParameterSpec{
+			{Parameter: domain.ParamECSalinityTDS, Unit: "µS/cm", Resolution: 1, Min: 0, Max: 200000, Accuracy: 5},
+			{Parameter: domain.ParamTemperature, Unit: "°C", Resolution: 0.1, Min: -5, Max: 60, Accuracy: 0.2},
+		},
+	},
+	{
+		Code:        "orp-probe",
+		Name:        "ORP probe",
+		Description: "Platinum electrode oxidation reduction potential probe",
+		Parameters: []domain.ParameterSpec{
+			{Parameter: domain.ParamORPRedox, Unit: "mV", Resolution: 1, Min: -2000, Max: 2000, Accuracy: 5},
+		},
+	},
+}
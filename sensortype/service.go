@@ -0,0 +1,167 @@
+package sensortype
+
+import (
+	"context"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+)
+
+// tracer starts the spans of the service methods
+var tracer = otel.Tracer("github.com/anggi-susanto/mrt-go/sensortype")
+
+// SensorTypeRepositoryInterface is the interface that wraps the sensor type catalog storage methods.
+type SensorTypeRepositoryInterface interface {
+	Create(ctx context.Context, w *domain.SensorTypeRequest) error
+	GetAll(ctx context.Context, page, limit int) ([]domain.SensorType, error)
+	GetByID(ctx context.Context, id string) (*domain.SensorType, error)
+	GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]domain.SensorType, error)
+	Update(ctx context.Context, w *domain.SensorType) error
+	Delete(ctx context.Context, id string) error
+	Upsert(ctx context.Context, w *domain.SensorTypeRequest) error
+}
+
+// SensorRepositoryInterface is the interface that wraps the GetByDeviceID method.
+type SensorRepositoryInterface interface {
+	GetByDeviceID(ctx context.Context, deviceID primitive.ObjectID) ([]domain.Sensor, error)
+}
+
+// Service is the interface that wraps the sensor type catalog methods.
+type Service struct {
+	sensorTypeRepository SensorTypeRepositoryInterface
+	sensorRepository     SensorRepositoryInterface
+}
+
+// NewService creates a new instance of the Service struct, initializing it with the provided repositories.
+//
+// Parameters:
+// - sensorTypeRepository: The SensorTypeRepositoryInterface implementation used by the Service.
+// - sensorRepository: The SensorRepositoryInterface implementation used to resolve the sensors of a device.
+//
+// Returns:
+// - A pointer to the newly created Service instance.
+func NewService(sensorTypeRepository SensorTypeRepositoryInterface, sensorRepository SensorRepositoryInterface) *Service {
+	return &Service{
+		sensorTypeRepository: sensorTypeRepository,
+		sensorRepository:     sensorRepository,
+	}
+}
+
+// Create creates a new sensor type in the catalog.
+//
+// ctx: The context.Context object for the request.
+// w: The sensor type to be created.
+// Returns an error if the sensor type is invalid or there was a problem creating the record.
+func (s *Service) Create(ctx context.Context, w *domain.SensorTypeRequest) error {
+	ctx, span := tracer.Start(ctx, "sensortype.Service.Create")
+	defer span.End()
+	if err := w.Validate(); err != nil {
+		return err
+	}
+	return s.sensorTypeRepository.Create(ctx, w)
+}
+
+// GetAll retrieves all sensor types with pagination.
+//
+// ctx context.Context, page int, limit int
+// []domain.SensorType, error
+func (s *Service) GetAll(ctx context.Context, page, limit int) ([]domain.SensorType, error) {
+	ctx, span := tracer.Start(ctx, "sensortype.Service.GetAll")
+	defer span.End()
+	return s.sensorTypeRepository.GetAll(ctx, page, limit)
+}
+
+// GetByID retrieves a SensorType by ID.
+//
+// ctx - context.Context for the operation.
+// id - string representing the ID of the sensor type.
+// Returns a pointer to domain.SensorType and an error.
+func (s *Service) GetByID(ctx context.Context, id string) (*domain.SensorType, error) {
+	ctx, span := tracer.Start(ctx, "sensortype.Service.GetByID")
+	defer span.End()
+	return s.sensorTypeRepository.GetByID(ctx, id)
+}
+
+// Delete deletes a SensorType by ID.
+//
+// ctx - context.Context for the operation.
+// id - string representing the ID of the sensor type to be deleted.
+// Returns an error if there was a problem deleting the sensor type.
+func (s *Service) Delete(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "sensortype.Service.Delete")
+	defer span.End()
+	return s.sensorTypeRepository.Delete(ctx, id)
+}
+
+// Update updates a SensorType.
+//
+// ctx - context.Context for the operation.
+// w - pointer to domain.SensorType representing the sensor type to be updated.
+// Returns an error if the sensor type is invalid or there was a problem updating it.
+func (s *Service) Update(ctx context.Context, w *domain.SensorType) error {
+	ctx, span := tracer.Start(ctx, "sensortype.Service.Update")
+	defer span.End()
+	if err := w.Validate(); err != nil {
+		return err
+	}
+	return s.sensorTypeRepository.Update(ctx, w)
+}
+
+// Seed inserts the default probes into the catalog, existing codes are left untouched.
+//
+// ctx - context.Context for the operation.
+// Returns an error if one of the defaults could not be stored.
+func (s *Service) Seed(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "sensortype.Service.Seed")
+	defer span.End()
+	for i := range DefaultSensorTypes {
+		if err := s.sensorTypeRepository.Upsert(ctx, &DefaultSensorTypes[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetDeviceParameters resolves the parameters measured by the sensors attached to a device.
+//
+// A parameter measured by several sensors of the device is listed once per sensor, in the order
+// of the sensors, each with the spec of its own sensor type.
+//
+// ctx - context.Context for the operation.
+// deviceID - the ID of the device.
+// Returns the parameters keyed by parameter code and an error.
+func (s *Service) GetDeviceParameters(ctx context.Context, deviceID primitive.ObjectID) (map[string][]domain.DeviceParameter, error) {
+	ctx, span := tracer.Start(ctx, "sensortype.Service.GetDeviceParameters")
+	defer span.End()
+	sensors, err := s.sensorRepository.GetByDeviceID(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(sensors))
+	for _, sensor := range sensors {
+		if !sensor.TypeID.IsZero() {
+			ids = append(ids, sensor.TypeID)
+		}
+	}
+	parameters := map[string][]domain.DeviceParameter{}
+	if len(ids) == 0 {
+		return parameters, nil
+	}
+
+	types, err := s.sensorTypeRepository.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	typesByID := make(map[primitive.ObjectID]domain.SensorType, len(types))
+	for _, sensorType := range types {
+		typesByID[sensorType.ID] = sensorType
+	}
+	for _, sensor := range sensors {
+		for _, spec := range typesByID[sensor.TypeID].Parameters {
+			parameters[spec.Parameter] = append(parameters[spec.Parameter], domain.DeviceParameter{SensorID: sensor.ID, ParameterSpec: spec})
+		}
+	}
+	return parameters, nil
+}
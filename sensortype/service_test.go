@@ -0,0 +1,185 @@
+package sensortype_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/sensortype"
+	"github.com/anggi-susanto/mrt-go/sensortype/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestServiceCreate(t *testing.T) {
+	mockSensorType := domain.SensorTypeRequest{
+		Code: "ph-probe",
+		Parameters: []domain.ParameterSpec{
+			{Parameter: domain.ParamPH, Unit: "pH", Min: 0, Max: 14},
+		},
+	}
+	t.Run("Success", func(t *testing.T) {
+		mockSensorTypeRepo := new(mocks.SensorTypeRepositoryInterface)
+		mockSensorTypeRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+		s := sensortype.NewService(mockSensorTypeRepo, new(mocks.SensorRepositoryInterface))
+		err := s.Create(context.Background(), &mockSensorType)
+		assert.NoError(t, err)
+	})
+	t.Run("Error", func(t *testing.T) {
+		mockSensorTypeRepo := new(mocks.SensorTypeRepositoryInterface)
+		mockSensorTypeRepo.On("Create", mock.Anything, mock.Anything).Return(errors.New("error")).Once()
+		s := sensortype.NewService(mockSensorTypeRepo, new(mocks.SensorRepositoryInterface))
+		err := s.Create(context.Background(), &mockSensorType)
+		assert.Error(t, err)
+	})
+	t.Run("Invalid range", func(t *testing.T) {
+		mockSensorTypeRepo := new(mocks.SensorTypeRepositoryInterface)
+		s := sensortype.NewService(mockSensorTypeRepo, new(mocks.SensorRepositoryInterface))
+		err := s.Create(context.Background(), &domain.SensorTypeRequest{
+			Code:       "broken",
+			Parameters: []domain.ParameterSpec{{Parameter: domain.ParamPH, Min: 14, Max: 0}},
+		})
+		assert.ErrorIs(t, err, domain.ErrInvalidSensorType)
+		mockSensorTypeRepo.AssertNotCalled(t, "Create")
+	})
+	t.Run("Invalid register map", func(t *testing.T) {
+		mockSensorTypeRepo := new(mocks.SensorTypeRepositoryInterface)
+		s := sensortype.NewService(mockSensorTypeRepo, new(mocks.SensorRepositoryInterface))
+		err := s.Create(context.Background(), &domain.SensorTypeRequest{
+			Code:      "analyzer",
+			Registers: []domain.ModbusRegister{{Parameter: domain.ParamPH, Address: 100, DataType: "float16"}},
+		})
+		assert.ErrorIs(t, err, domain.ErrInvalidModbus)
+		mockSensorTypeRepo.AssertNotCalled(t, "Create")
+	})
+}
+
+func TestServiceGetAll(t *testing.T) {
+	mockSensorType := []domain.SensorType{
+		{
+			Code: "ph-probe",
+		},
+	}
+	t.Run("Success", func(t *testing.T) {
+		mockSensorTypeRepo := new(mocks.SensorTypeRepositoryInterface)
+		mockSensorTypeRepo.On("GetAll", mock.Anything, mock.Anything, mock.Anything).Return(mockSensorType, nil)
+		s := sensortype.NewService(mockSensorTypeRepo, new(mocks.SensorRepositoryInterface))
+		data, err := s.GetAll(context.Background(), 1, 10)
+		assert.Len(t, data, len(mockSensorType))
+		assert.NoError(t, err)
+	})
+	t.Run("Error", func(t *testing.T) {
+		mockSensorTypeRepo := new(mocks.SensorTypeRepositoryInterface)
+		mockSensorTypeRepo.On("GetAll", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("error")).Once()
+		s := sensortype.NewService(mockSensorTypeRepo, new(mocks.SensorRepositoryInterface))
+		data, err := s.GetAll(context.Background(), 1, 10)
+		assert.Nil(t, data)
+		assert.Error(t, err)
+	})
+}
+
+func TestServiceUpdate(t *testing.T) {
+	mockSensorType := domain.SensorType{
+		Code: "ph-probe",
+	}
+	t.Run("Success", func(t *testing.T) {
+		mockSensorTypeRepo := new(mocks.SensorTypeRepositoryInterface)
+		mockSensorTypeRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+		s := sensortype.NewService(mockSensorTypeRepo, new(mocks.SensorRepositoryInterface))
+		err := s.Update(context.Background(), &mockSensorType)
+		assert.NoError(t, err)
+	})
+	t.Run("Missing code", func(t *testing.T) {
+		mockSensorTypeRepo := new(mocks.SensorTypeRepositoryInterface)
+		s := sensortype.NewService(mockSensorTypeRepo, new(mocks.SensorRepositoryInterface))
+		err := s.Update(context.Background(), &domain.SensorType{})
+		assert.ErrorIs(t, err, domain.ErrInvalidSensorType)
+	})
+}
+
+func TestServiceSeed(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockSensorTypeRepo := new(mocks.SensorTypeRepositoryInterface)
+		mockSensorTypeRepo.On("Upsert", mock.Anything, mock.Anything).Return(nil)
+		s := sensortype.NewService(mockSensorTypeRepo, new(mocks.SensorRepositoryInterface))
+		err := s.Seed(context.Background())
+		assert.NoError(t, err)
+		mockSensorTypeRepo.AssertNumberOfCalls(t, "Upsert", len(sensortype.DefaultSensorTypes))
+	})
+	t.Run("Error", func(t *testing.T) {
+		mockSensorTypeRepo := new(mocks.SensorTypeRepositoryInterface)
+		mockSensorTypeRepo.On("Upsert", mock.Anything, mock.Anything).Return(errors.New("error")).Once()
+		s := sensortype.NewService(mockSensorTypeRepo, new(mocks.SensorRepositoryInterface))
+		err := s.Seed(context.Background())
+		assert.Error(t, err)
+	})
+}
+
+func TestServiceGetDeviceParameters(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	phType := domain.SensorType{
+		ID: primitive.NewObjectID(),
+		Parameters: []domain.ParameterSpec{
+			{Parameter: domain.ParamPH, Min: 0, Max: 14},
+		},
+	}
+	doType := domain.SensorType{
+		ID: primitive.NewObjectID(),
+		Parameters: []domain.ParameterSpec{
+			{Parameter: domain.ParamDissolvedOxygen, Min: 0, Max: 20},
+			{Parameter: domain.ParamTemperature, Min: 0, Max: 50},
+		},
+	}
+	conductivityType := domain.SensorType{
+		ID: primitive.NewObjectID(),
+		Parameters: []domain.ParameterSpec{
+			{Parameter: domain.ParamTemperature, Min: -5, Max: 60},
+		},
+	}
+	sensors := []domain.Sensor{
+		{ID: primitive.NewObjectID(), DeviceID: deviceID, TypeID: phType.ID},
+		{ID: primitive.NewObjectID(), DeviceID: deviceID, TypeID: doType.ID},
+		{ID: primitive.NewObjectID(), DeviceID: deviceID, TypeID: conductivityType.ID},
+		{ID: primitive.NewObjectID(), DeviceID: deviceID},
+	}
+	t.Run("Success", func(t *testing.T) {
+		mockSensorTypeRepo := new(mocks.SensorTypeRepositoryInterface)
+		mockSensorRepo := new(mocks.SensorRepositoryInterface)
+		mockSensorRepo.On("GetByDeviceID", mock.Anything, deviceID).Return(sensors, nil)
+		mockSensorTypeRepo.On("GetByIDs", mock.Anything, []primitive.ObjectID{phType.ID, doType.ID, conductivityType.ID}).
+			Return([]domain.SensorType{conductivityType, phType, doType}, nil)
+		s := sensortype.NewService(mockSensorTypeRepo, mockSensorRepo)
+		parameters, err := s.GetDeviceParameters(context.Background(), deviceID)
+		assert.NoError(t, err)
+		assert.Len(t, parameters, 3)
+		require.Len(t, parameters[domain.ParamPH], 1)
+		assert.Equal(t, sensors[0].ID, parameters[domain.ParamPH][0].SensorID)
+		// Both sensors measuring the temperature are kept, each with its own range
+		require.Len(t, parameters[domain.ParamTemperature], 2)
+		assert.Equal(t, sensors[1].ID, parameters[domain.ParamTemperature][0].SensorID)
+		assert.Equal(t, float64(50), parameters[domain.ParamTemperature][0].Max)
+		assert.Equal(t, sensors[2].ID, parameters[domain.ParamTemperature][1].SensorID)
+		assert.Equal(t, float64(60), parameters[domain.ParamTemperature][1].Max)
+	})
+	t.Run("Device without typed sensors", func(t *testing.T) {
+		mockSensorTypeRepo := new(mocks.SensorTypeRepositoryInterface)
+		mockSensorRepo := new(mocks.SensorRepositoryInterface)
+		mockSensorRepo.On("GetByDeviceID", mock.Anything, deviceID).Return([]domain.Sensor{{DeviceID: deviceID}}, nil)
+		s := sensortype.NewService(mockSensorTypeRepo, mockSensorRepo)
+		parameters, err := s.GetDeviceParameters(context.Background(), deviceID)
+		assert.NoError(t, err)
+		assert.Empty(t, parameters)
+		mockSensorTypeRepo.AssertNotCalled(t, "GetByIDs")
+	})
+	t.Run("Error", func(t *testing.T) {
+		mockSensorRepo := new(mocks.SensorRepositoryInterface)
+		mockSensorRepo.On("GetByDeviceID", mock.Anything, deviceID).Return(nil, errors.New("error"))
+		s := sensortype.NewService(new(mocks.SensorTypeRepositoryInterface), mockSensorRepo)
+		parameters, err := s.GetDeviceParameters(context.Background(), deviceID)
+		assert.Error(t, err)
+		assert.Nil(t, parameters)
+	})
+}
@@ -0,0 +1,47 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// DeltaPublisherInterface is an autogenerated mock type for the DeltaPublisherInterface type
+type DeltaPublisherInterface struct {
+	mock.Mock
+}
+
+// PublishDelta provides a mock function with given fields: ctx, delta
+func (_m *DeltaPublisherInterface) PublishDelta(ctx context.Context, delta domain.ShadowDelta) error {
+	ret := _m.Called(ctx, delta)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PublishDelta")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ShadowDelta) error); ok {
+		r0 = rf(ctx, delta)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewDeltaPublisherInterface creates a new instance of DeltaPublisherInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewDeltaPublisherInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *DeltaPublisherInterface {
+	mock := &DeltaPublisherInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
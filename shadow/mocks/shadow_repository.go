@@ -0,0 +1,79 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ShadowRepositoryInterface is an autogenerated mock type for the ShadowRepositoryInterface type
+type ShadowRepositoryInterface struct {
+	mock.Mock
+}
+
+// GetByDeviceID provides a mock function with given fields: ctx, deviceID
+func (_m *ShadowRepositoryInterface) GetByDeviceID(ctx context.Context, deviceID primitive.ObjectID) (*domain.Shadow, error) {
+	ret := _m.Called(ctx, deviceID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByDeviceID")
+	}
+
+	var r0 *domain.Shadow
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID) (*domain.Shadow, error)); ok {
+		return rf(ctx, deviceID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID) *domain.Shadow); ok {
+		r0 = rf(ctx, deviceID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Shadow)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, primitive.ObjectID) error); ok {
+		r1 = rf(ctx, deviceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Save provides a mock function with given fields: ctx, _a1, expectedVersion
+func (_m *ShadowRepositoryInterface) Save(ctx context.Context, _a1 *domain.Shadow, expectedVersion int64) error {
+	ret := _m.Called(ctx, _a1, expectedVersion)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Shadow, int64) error); ok {
+		r0 = rf(ctx, _a1, expectedVersion)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewShadowRepositoryInterface creates a new instance of ShadowRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewShadowRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ShadowRepositoryInterface {
+	mock := &ShadowRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
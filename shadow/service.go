@@ -0,0 +1,218 @@
+package shadow
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/logging"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+)
+
+// tracer starts the spans of the service methods
+var tracer = otel.Tracer("github.com/anggi-susanto/mrt-go/shadow")
+
+// log is the logger of the delta deliveries
+var log = logging.Logger("shadow")
+
+// maxReportedRetries is the number of times a reported update is retried when the shadow changed concurrently
+const maxReportedRetries = 3
+
+// ShadowRepositoryInterface is the interface that wraps the shadow storage methods.
+type ShadowRepositoryInterface interface {
+	GetByDeviceID(ctx context.Context, deviceID primitive.ObjectID) (*domain.Shadow, error)
+	Save(ctx context.Context, shadow *domain.Shadow, expectedVersion int64) error
+}
+
+// DeviceRepositoryInterface is the interface that wraps the GetByID method.
+type DeviceRepositoryInterface interface {
+	GetByID(ctx context.Context, id string) (*domain.Device, error)
+}
+
+// DeltaPublisherInterface is the interface that wraps the PublishDelta method.
+type DeltaPublisherInterface interface {
+	PublishDelta(ctx context.Context, delta domain.ShadowDelta) error
+}
+
+// Publishers delivers the deltas through every transport a device may be reached on, e.g. MQTT and CoAP
+type Publishers []DeltaPublisherInterface
+
+// PublishDelta delivers the delta through each publisher, a failing publisher does not hold up the others.
+//
+// ctx - context.Context for the operation.
+// delta - the delta to deliver.
+// Returns the errors of the publishers joined.
+func (p Publishers) PublishDelta(ctx context.Context, delta domain.ShadowDelta) error {
+	var errs []error
+	for _, publisher := range p {
+		errs = append(errs, publisher.PublishDelta(ctx, delta))
+	}
+	return errors.Join(errs...)
+}
+
+// Service is the interface that wraps the device shadows.
+type Service struct {
+	shadowRepository ShadowRepositoryInterface
+	deviceRepository DeviceRepositoryInterface
+	publisher        DeltaPublisherInterface
+}
+
+// NewService creates a new instance of the Service struct, initializing it with the provided dependencies.
+//
+// Parameters:
+// - shadowRepository: The ShadowRepositoryInterface implementation used by the Service.
+// - deviceRepository: The DeviceRepositoryInterface implementation used to check the device exists.
+// - publisher: The DeltaPublisherInterface implementation delivering the deltas to the devices.
+//
+// Returns:
+// - A pointer to the newly created Service instance.
+func NewService(shadowRepository ShadowRepositoryInterface, deviceRepository DeviceRepositoryInterface, publisher DeltaPublisherInterface) *Service {
+	return &Service{
+		shadowRepository: shadowRepository,
+		deviceRepository: deviceRepository,
+		publisher:        publisher,
+	}
+}
+
+// Get retrieves the shadow of a device with its delta.
+//
+// A device without a shadow yet gets an empty one at version 0.
+//
+// ctx - context.Context for the operation.
+// deviceID - string representing the ID of the device.
+// Returns the shadow, nil when the device does not exist, and an error.
+func (s *Service) Get(ctx context.Context, deviceID string) (*domain.Shadow, error) {
+	ctx, span := tracer.Start(ctx, "shadow.Service.Get")
+	defer span.End()
+	device, err := s.deviceRepository.GetByID(ctx, deviceID)
+	if err != nil || device == nil {
+		return nil, err
+	}
+	shadow, err := s.load(ctx, device.ID)
+	if err != nil {
+		return nil, err
+	}
+	shadow.Delta = domain.Delta(shadow.Desired, shadow.Reported)
+	return shadow, nil
+}
+
+// UpdateDesired merges an update into the desired state of a device and delivers the resulting delta to the device.
+//
+// A delta that could not be delivered is logged, the device gets it with its next report or on reconnect.
+//
+// ctx - context.Context for the operation.
+// deviceID - string representing the ID of the device.
+// update - the desired keys to change and the version the update was made against.
+// Returns the updated shadow, nil when the device does not exist, and an error.
+func (s *Service) UpdateDesired(ctx context.Context, deviceID string, update domain.ShadowUpdate) (*domain.Shadow, error) {
+	ctx, span := tracer.Start(ctx, "shadow.Service.UpdateDesired")
+	defer span.End()
+	if err := update.Validate(); err != nil {
+		return nil, err
+	}
+	device, err := s.deviceRepository.GetByID(ctx, deviceID)
+	if err != nil || device == nil {
+		return nil, err
+	}
+	shadow, err := s.load(ctx, device.ID)
+	if err != nil {
+		return nil, err
+	}
+	if update.Version != 0 && update.Version != shadow.Version {
+		return nil, domain.ErrShadowVersion
+	}
+
+	expected := shadow.Version
+	shadow.Desired = shadow.Desired.Merge(update.State)
+	shadow.DesiredUpdatedAt = time.Now()
+	shadow.Version++
+	shadow.Delta = domain.Delta(shadow.Desired, shadow.Reported)
+	if err = s.shadowRepository.Save(ctx, shadow, expected); err != nil {
+		return nil, err
+	}
+	s.publish(ctx, shadow)
+	return shadow, nil
+}
+
+// UpdateReported merges the state a device reports into its reported state.
+//
+// Reports are retried when the shadow changed concurrently unless they name a version.
+// The delta left after the report is delivered again, or cleared when the device caught up.
+// A delta that could not be delivered is logged.
+//
+// ctx - context.Context for the operation.
+// deviceID - the ID of the reporting device.
+// update - the reported keys.
+// Returns the updated shadow and an error.
+func (s *Service) UpdateReported(ctx context.Context, deviceID primitive.ObjectID, update domain.ShadowUpdate) (*domain.Shadow, error) {
+	ctx, span := tracer.Start(ctx, "shadow.Service.UpdateReported")
+	defer span.End()
+	if err := update.Validate(); err != nil {
+		return nil, err
+	}
+	device, err := s.deviceRepository.GetByID(ctx, deviceID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	if device == nil {
+		return nil, domain.ErrInvalidShadow
+	}
+
+	for attempt := 0; ; attempt++ {
+		shadow, err := s.load(ctx, deviceID)
+		if err != nil {
+			return nil, err
+		}
+		if update.Version != 0 && update.Version != shadow.Version {
+			return nil, domain.ErrShadowVersion
+		}
+		expected := shadow.Version
+		shadow.Reported = shadow.Reported.Merge(update.State)
+		shadow.ReportedUpdatedAt = time.Now()
+		shadow.Version++
+		shadow.Delta = domain.Delta(shadow.Desired, shadow.Reported)
+		err = s.shadowRepository.Save(ctx, shadow, expected)
+		if errors.Is(err, domain.ErrShadowVersion) && update.Version == 0 && attempt < maxReportedRetries {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.publish(ctx, shadow)
+		return shadow, nil
+	}
+}
+
+// load retrieves the shadow of a device, or an empty one when it has none yet.
+func (s *Service) load(ctx context.Context, deviceID primitive.ObjectID) (*domain.Shadow, error) {
+	shadow, err := s.shadowRepository.GetByDeviceID(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	if shadow == nil {
+		shadow = &domain.Shadow{DeviceID: deviceID}
+	}
+	if shadow.Desired == nil {
+		shadow.Desired = domain.ShadowState{}
+	}
+	if shadow.Reported == nil {
+		shadow.Reported = domain.ShadowState{}
+	}
+	return shadow, nil
+}
+
+// publish delivers the delta of the saved shadow to the device, a failure is only logged as the
+// update is already stored.
+func (s *Service) publish(ctx context.Context, shadow *domain.Shadow) {
+	err := s.publisher.PublishDelta(ctx, domain.ShadowDelta{
+		DeviceID:  shadow.DeviceID,
+		State:     shadow.Delta,
+		Version:   shadow.Version,
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		log.WithContext(ctx).WithField(logging.FieldDeviceID, shadow.DeviceID.Hex()).Error(err)
+	}
+}
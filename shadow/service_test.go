@@ -0,0 +1,188 @@
+package shadow_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/shadow"
+	"github.com/anggi-susanto/mrt-go/shadow/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestServiceGet(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	t.Run("Success", func(t *testing.T) {
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, deviceID.Hex()).Return(&domain.Device{ID: deviceID}, nil)
+		mockRepo := new(mocks.ShadowRepositoryInterface)
+		mockRepo.On("GetByDeviceID", mock.Anything, deviceID).Return(&domain.Shadow{
+			DeviceID: deviceID,
+			// Reported values decoded from BSON are int32 while JSON values are float64
+			Desired:  domain.ShadowState{"interval": 60.0, "thresholds": map[string]interface{}{"pH": 9.0, "COD": 120.0}},
+			Reported: domain.ShadowState{"interval": int32(300), "thresholds": map[string]interface{}{"pH": int32(9)}},
+			Version:  4,
+		}, nil)
+		s, err := shadow.NewService(mockRepo, mockDeviceRepo, nil).Get(context.Background(), deviceID.Hex())
+		assert.NoError(t, err)
+		assert.Equal(t, domain.ShadowState{"interval": 60.0, "thresholds": domain.ShadowState{"COD": 120.0}}, s.Delta)
+	})
+	t.Run("No shadow yet", func(t *testing.T) {
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, deviceID.Hex()).Return(&domain.Device{ID: deviceID}, nil)
+		mockRepo := new(mocks.ShadowRepositoryInterface)
+		mockRepo.On("GetByDeviceID", mock.Anything, deviceID).Return(nil, nil)
+		s, err := shadow.NewService(mockRepo, mockDeviceRepo, nil).Get(context.Background(), deviceID.Hex())
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), s.Version)
+		assert.Empty(t, s.Delta)
+	})
+	t.Run("Device not found", func(t *testing.T) {
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, deviceID.Hex()).Return(nil, nil)
+		s, err := shadow.NewService(new(mocks.ShadowRepositoryInterface), mockDeviceRepo, nil).Get(context.Background(), deviceID.Hex())
+		assert.NoError(t, err)
+		assert.Nil(t, s)
+	})
+}
+
+func TestServiceUpdateDesired(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	current := func() *domain.Shadow {
+		return &domain.Shadow{
+			DeviceID: deviceID,
+			Desired:  domain.ShadowState{"interval": 300.0, "alarm": true},
+			Reported: domain.ShadowState{"interval": 300.0, "alarm": true},
+			Version:  2,
+		}
+	}
+	t.Run("Success", func(t *testing.T) {
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, deviceID.Hex()).Return(&domain.Device{ID: deviceID}, nil)
+		mockRepo := new(mocks.ShadowRepositoryInterface)
+		mockRepo.On("GetByDeviceID", mock.Anything, deviceID).Return(current(), nil)
+		mockRepo.On("Save", mock.Anything, mock.Anything, int64(2)).Return(nil)
+		mockPublisher := new(mocks.DeltaPublisherInterface)
+		mockPublisher.On("PublishDelta", mock.Anything, mock.MatchedBy(func(d domain.ShadowDelta) bool {
+			return d.DeviceID == deviceID && d.Version == 3 && len(d.State) == 1 && d.State["interval"] == 60.0
+		})).Return(nil)
+		update := domain.ShadowUpdate{State: domain.ShadowState{"interval": 60.0, "alarm": nil}, Version: 2}
+		s, err := shadow.NewService(mockRepo, mockDeviceRepo, mockPublisher).UpdateDesired(context.Background(), deviceID.Hex(), update)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), s.Version)
+		assert.Equal(t, domain.ShadowState{"interval": 60.0}, s.Desired)
+		assert.False(t, s.DesiredUpdatedAt.IsZero())
+		mockPublisher.AssertExpectations(t)
+	})
+	t.Run("Not delivered", func(t *testing.T) {
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, deviceID.Hex()).Return(&domain.Device{ID: deviceID}, nil)
+		mockRepo := new(mocks.ShadowRepositoryInterface)
+		mockRepo.On("GetByDeviceID", mock.Anything, deviceID).Return(current(), nil)
+		mockRepo.On("Save", mock.Anything, mock.MatchedBy(func(s *domain.Shadow) bool {
+			return s.Delta["interval"] == 60.0
+		}), int64(2)).Return(nil)
+		mockPublisher := new(mocks.DeltaPublisherInterface)
+		mockPublisher.On("PublishDelta", mock.Anything, mock.Anything).Return(errors.New("broker down"))
+		update := domain.ShadowUpdate{State: domain.ShadowState{"interval": 60.0}}
+		s, err := shadow.NewService(mockRepo, mockDeviceRepo, mockPublisher).UpdateDesired(context.Background(), deviceID.Hex(), update)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), s.Version)
+		assert.Equal(t, domain.ShadowState{"interval": 60.0}, s.Delta)
+		mockRepo.AssertExpectations(t)
+	})
+	t.Run("Outdated version", func(t *testing.T) {
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, deviceID.Hex()).Return(&domain.Device{ID: deviceID}, nil)
+		mockRepo := new(mocks.ShadowRepositoryInterface)
+		mockRepo.On("GetByDeviceID", mock.Anything, deviceID).Return(current(), nil)
+		update := domain.ShadowUpdate{State: domain.ShadowState{"interval": 60.0}, Version: 1}
+		_, err := shadow.NewService(mockRepo, mockDeviceRepo, nil).UpdateDesired(context.Background(), deviceID.Hex(), update)
+		assert.ErrorIs(t, err, domain.ErrShadowVersion)
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("No state", func(t *testing.T) {
+		_, err := shadow.NewService(nil, nil, nil).UpdateDesired(context.Background(), deviceID.Hex(), domain.ShadowUpdate{})
+		assert.ErrorIs(t, err, domain.ErrInvalidShadow)
+	})
+	t.Run("Error", func(t *testing.T) {
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, deviceID.Hex()).Return(&domain.Device{ID: deviceID}, nil)
+		mockRepo := new(mocks.ShadowRepositoryInterface)
+		mockRepo.On("GetByDeviceID", mock.Anything, deviceID).Return(current(), nil)
+		mockRepo.On("Save", mock.Anything, mock.Anything, int64(2)).Return(errors.New("error"))
+		update := domain.ShadowUpdate{State: domain.ShadowState{"interval": 60.0}}
+		_, err := shadow.NewService(mockRepo, mockDeviceRepo, nil).UpdateDesired(context.Background(), deviceID.Hex(), update)
+		assert.Error(t, err)
+	})
+}
+
+func TestServiceUpdateReported(t *testing.T) {
+	deviceID := primitive.NewObjectID()
+	current := func(version int64) *domain.Shadow {
+		return &domain.Shadow{
+			DeviceID: deviceID,
+			Desired:  domain.ShadowState{"interval": 60.0},
+			Reported: domain.ShadowState{"interval": 300.0},
+			Version:  version,
+		}
+	}
+	t.Run("Caught up", func(t *testing.T) {
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, deviceID.Hex()).Return(&domain.Device{ID: deviceID}, nil)
+		mockRepo := new(mocks.ShadowRepositoryInterface)
+		// The first save races with a desired update and is retried on the new version
+		mockRepo.On("GetByDeviceID", mock.Anything, deviceID).Return(current(3), nil).Once()
+		mockRepo.On("Save", mock.Anything, mock.Anything, int64(3)).Return(domain.ErrShadowVersion).Once()
+		mockRepo.On("GetByDeviceID", mock.Anything, deviceID).Return(current(4), nil).Once()
+		mockRepo.On("Save", mock.Anything, mock.Anything, int64(4)).Return(nil).Once()
+		mockPublisher := new(mocks.DeltaPublisherInterface)
+		mockPublisher.On("PublishDelta", mock.Anything, mock.MatchedBy(func(d domain.ShadowDelta) bool {
+			return len(d.State) == 0
+		})).Return(nil)
+		update := domain.ShadowUpdate{State: domain.ShadowState{"interval": 60}}
+		s, err := shadow.NewService(mockRepo, mockDeviceRepo, mockPublisher).UpdateReported(context.Background(), deviceID, update)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), s.Version)
+		assert.Empty(t, s.Delta)
+		mockRepo.AssertExpectations(t)
+		mockPublisher.AssertExpectations(t)
+	})
+	t.Run("Not delivered", func(t *testing.T) {
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, deviceID.Hex()).Return(&domain.Device{ID: deviceID}, nil)
+		mockRepo := new(mocks.ShadowRepositoryInterface)
+		mockRepo.On("GetByDeviceID", mock.Anything, deviceID).Return(current(3), nil)
+		mockRepo.On("Save", mock.Anything, mock.Anything, int64(3)).Return(nil).Once()
+		mockPublisher := new(mocks.DeltaPublisherInterface)
+		mockPublisher.On("PublishDelta", mock.Anything, mock.Anything).Return(errors.New("broker down"))
+		update := domain.ShadowUpdate{State: domain.ShadowState{"alarm": true}}
+		s, err := shadow.NewService(mockRepo, mockDeviceRepo, mockPublisher).UpdateReported(context.Background(), deviceID, update)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), s.Version)
+		assert.Equal(t, domain.ShadowState{"interval": 60.0}, s.Delta)
+		mockRepo.AssertExpectations(t)
+	})
+	t.Run("Unknown device", func(t *testing.T) {
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetByID", mock.Anything, deviceID.Hex()).Return(nil, nil)
+		update := domain.ShadowUpdate{State: domain.ShadowState{"interval": 60}}
+		_, err := shadow.NewService(nil, mockDeviceRepo, nil).UpdateReported(context.Background(), deviceID, update)
+		assert.ErrorIs(t, err, domain.ErrInvalidShadow)
+	})
+}
+
+func TestPublishersPublishDelta(t *testing.T) {
+	delta := domain.ShadowDelta{DeviceID: primitive.NewObjectID(), State: domain.ShadowState{"interval": 60.0}}
+	failing := new(mocks.DeltaPublisherInterface)
+	failing.On("PublishDelta", mock.Anything, delta).Return(errors.New("broker down"))
+	working := new(mocks.DeltaPublisherInterface)
+	working.On("PublishDelta", mock.Anything, delta).Return(nil)
+
+	err := shadow.Publishers{failing, working}.PublishDelta(context.Background(), delta)
+	assert.EqualError(t, err, "broker down")
+	working.AssertExpectations(t)
+}
@@ -0,0 +1,121 @@
+package site
+
+import (
+	"context"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"go.opentelemetry.io/otel"
+)
+
+// tracer starts the spans of the service methods
+var tracer = otel.Tracer("github.com/anggi-susanto/mrt-go/site")
+
+// SiteRepositoryInterface is the interface that wraps the Create, GetAll, GetByID, Update, and Delete methods.
+type SiteRepositoryInterface interface {
+	Create(ctx context.Context, w *domain.SiteRequest) error
+	GetAll(ctx context.Context, page, limit int) ([]domain.Site, error)
+	GetByID(ctx context.Context, id string) (*domain.Site, error)
+	Update(ctx context.Context, w *domain.Site) error
+	Delete(ctx context.Context, id string) error
+}
+
+// DeviceRepositoryInterface is the interface that wraps the GetBySiteID method.
+type DeviceRepositoryInterface interface {
+	GetBySiteID(ctx context.Context, siteID string, page, limit int) ([]domain.Device, error)
+}
+
+// Service is the interface that wraps the Create, GetAll, GetByID, Update, Delete and GetDevices methods.
+type Service struct {
+	siteRepository   SiteRepositoryInterface
+	deviceRepository DeviceRepositoryInterface
+}
+
+// NewService creates a new instance of the Service struct, initializing it with the provided repositories.
+//
+// Parameters:
+// - siteRepository: The SiteRepositoryInterface implementation used by the Service.
+// - deviceRepository: The DeviceRepositoryInterface implementation used to list the devices of a site.
+//
+// Returns:
+// - A pointer to the newly created Service instance.
+func NewService(siteRepository SiteRepositoryInterface, deviceRepository DeviceRepositoryInterface) *Service {
+	return &Service{
+		siteRepository:   siteRepository,
+		deviceRepository: deviceRepository,
+	}
+}
+
+// Create creates a new site record in the service.
+//
+// ctx: The context.Context object for the request.
+// w: The site to be created.
+// Returns an error if the geometry is invalid or there was a problem creating the record.
+func (s *Service) Create(ctx context.Context, w *domain.SiteRequest) error {
+	ctx, span := tracer.Start(ctx, "site.Service.Create")
+	defer span.End()
+	if w.Geometry != nil {
+		if err := w.Geometry.Validate(); err != nil {
+			return err
+		}
+	}
+	return s.siteRepository.Create(ctx, w)
+}
+
+// GetAll retrieves all sites with pagination.
+//
+// ctx context.Context, page int, limit int
+// []domain.Site, error
+func (s *Service) GetAll(ctx context.Context, page, limit int) ([]domain.Site, error) {
+	ctx, span := tracer.Start(ctx, "site.Service.GetAll")
+	defer span.End()
+	return s.siteRepository.GetAll(ctx, page, limit)
+}
+
+// GetByID retrieves a Site by ID.
+//
+// ctx - context.Context for the operation.
+// id - string representing the ID of the site.
+// Returns a pointer to domain.Site and an error.
+func (s *Service) GetByID(ctx context.Context, id string) (*domain.Site, error) {
+	ctx, span := tracer.Start(ctx, "site.Service.GetByID")
+	defer span.End()
+	return s.siteRepository.GetByID(ctx, id)
+}
+
+// Delete deletes a Site by ID.
+//
+// ctx - context.Context for the operation.
+// id - string representing the ID of the site to be deleted.
+// Returns an error if there was a problem deleting the site.
+func (s *Service) Delete(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "site.Service.Delete")
+	defer span.End()
+	return s.siteRepository.Delete(ctx, id)
+}
+
+// Update updates a Site.
+//
+// ctx - context.Context for the operation.
+// w - pointer to domain.Site representing the site to be updated.
+// Returns an error if the geometry is invalid or there was a problem updating the site.
+func (s *Service) Update(ctx context.Context, w *domain.Site) error {
+	ctx, span := tracer.Start(ctx, "site.Service.Update")
+	defer span.End()
+	if w.Geometry != nil {
+		if err := w.Geometry.Validate(); err != nil {
+			return err
+		}
+	}
+	return s.siteRepository.Update(ctx, w)
+}
+
+// GetDevices retrieves the devices assigned to a site with pagination.
+//
+// ctx - context.Context for the operation.
+// id - string representing the ID of the site.
+// Returns the devices of the site and an error.
+func (s *Service) GetDevices(ctx context.Context, id string, page, limit int) ([]domain.Device, error) {
+	ctx, span := tracer.Start(ctx, "site.Service.GetDevices")
+	defer span.End()
+	return s.deviceRepository.GetBySiteID(ctx, id, page, limit)
+}
@@ -0,0 +1,174 @@
+package site_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/site"
+	"github.com/anggi-susanto/mrt-go/site/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestServiceCreate(t *testing.T) {
+	mockSite := domain.SiteRequest{
+		Name: "site",
+		Geometry: &domain.Geometry{
+			Type:        domain.GeoJSONPoint,
+			Coordinates: []interface{}{106.8227, -6.1935},
+		},
+	}
+	t.Run("Success", func(t *testing.T) {
+		mockSiteRepo := new(mocks.SiteRepositoryInterface)
+		mockSiteRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+		s := site.NewService(mockSiteRepo, new(mocks.DeviceRepositoryInterface))
+		err := s.Create(context.Background(), &mockSite)
+		assert.NoError(t, err)
+	})
+	t.Run("Error", func(t *testing.T) {
+		mockSiteRepo := new(mocks.SiteRepositoryInterface)
+		mockSiteRepo.On("Create", mock.Anything, mock.Anything).Return(errors.New("error")).Once()
+		s := site.NewService(mockSiteRepo, new(mocks.DeviceRepositoryInterface))
+		err := s.Create(context.Background(), &mockSite)
+		assert.Error(t, err)
+	})
+	t.Run("Invalid polygon", func(t *testing.T) {
+		mockSiteRepo := new(mocks.SiteRepositoryInterface)
+		s := site.NewService(mockSiteRepo, new(mocks.DeviceRepositoryInterface))
+		err := s.Create(context.Background(), &domain.SiteRequest{
+			Name: "site",
+			Geometry: &domain.Geometry{
+				Type: domain.GeoJSONPolygon,
+				Coordinates: []interface{}{
+					[]interface{}{
+						[]interface{}{106.0, -6.0},
+						[]interface{}{107.0, -6.0},
+						[]interface{}{107.0, -7.0},
+					},
+				},
+			},
+		})
+		assert.ErrorIs(t, err, domain.ErrInvalidGeometry)
+		mockSiteRepo.AssertNotCalled(t, "Create")
+	})
+}
+
+func TestServiceGetAll(t *testing.T) {
+	mockSite := []domain.Site{
+		{
+			Name: "site",
+		},
+	}
+	t.Run("Success", func(t *testing.T) {
+		mockSiteRepo := new(mocks.SiteRepositoryInterface)
+		mockSiteRepo.On("GetAll", mock.Anything, mock.Anything, mock.Anything).Return(mockSite, nil)
+		s := site.NewService(mockSiteRepo, new(mocks.DeviceRepositoryInterface))
+		data, err := s.GetAll(context.Background(), 1, 10)
+		assert.Len(t, data, len(mockSite))
+		assert.NoError(t, err)
+	})
+	t.Run("Error", func(t *testing.T) {
+		mockSiteRepo := new(mocks.SiteRepositoryInterface)
+		mockSiteRepo.On("GetAll", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("error")).Once()
+		s := site.NewService(mockSiteRepo, new(mocks.DeviceRepositoryInterface))
+		data, err := s.GetAll(context.Background(), 1, 10)
+		assert.Nil(t, data)
+		assert.Error(t, err)
+	})
+}
+
+func TestServiceUpdate(t *testing.T) {
+	mockSite := domain.Site{
+		Name: "site",
+		Geometry: &domain.Geometry{
+			Type: domain.GeoJSONPolygon,
+			Coordinates: []interface{}{
+				[]interface{}{
+					[]interface{}{106.0, -6.0},
+					[]interface{}{107.0, -6.0},
+					[]interface{}{107.0, -7.0},
+					[]interface{}{106.0, -6.0},
+				},
+			},
+		},
+	}
+	t.Run("Success", func(t *testing.T) {
+		mockSiteRepo := new(mocks.SiteRepositoryInterface)
+		mockSiteRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+		s := site.NewService(mockSiteRepo, new(mocks.DeviceRepositoryInterface))
+		err := s.Update(context.Background(), &mockSite)
+		assert.NoError(t, err)
+	})
+	t.Run("Error", func(t *testing.T) {
+		mockSiteRepo := new(mocks.SiteRepositoryInterface)
+		mockSiteRepo.On("Update", mock.Anything, mock.Anything).Return(errors.New("error")).Once()
+		s := site.NewService(mockSiteRepo, new(mocks.DeviceRepositoryInterface))
+		err := s.Update(context.Background(), &mockSite)
+		assert.Error(t, err)
+	})
+}
+
+func TestServiceGetByID(t *testing.T) {
+	mockSite := domain.Site{
+		Name: "site",
+	}
+	t.Run("Success", func(t *testing.T) {
+		mockSiteRepo := new(mocks.SiteRepositoryInterface)
+		mockSiteRepo.On("GetByID", mock.Anything, mock.Anything).Return(&mockSite, nil)
+		s := site.NewService(mockSiteRepo, new(mocks.DeviceRepositoryInterface))
+		data, err := s.GetByID(context.Background(), "1")
+		assert.Equal(t, data.Name, mockSite.Name)
+		assert.NoError(t, err)
+	})
+	t.Run("Error", func(t *testing.T) {
+		mockSiteRepo := new(mocks.SiteRepositoryInterface)
+		mockSiteRepo.On("GetByID", mock.Anything, mock.Anything).Return(nil, errors.New("error")).Once()
+		s := site.NewService(mockSiteRepo, new(mocks.DeviceRepositoryInterface))
+		data, err := s.GetByID(context.Background(), "1")
+		assert.Nil(t, data)
+		assert.Error(t, err)
+	})
+}
+
+func TestServiceDelete(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockSiteRepo := new(mocks.SiteRepositoryInterface)
+		mockSiteRepo.On("Delete", mock.Anything, mock.Anything).Return(nil)
+		s := site.NewService(mockSiteRepo, new(mocks.DeviceRepositoryInterface))
+		err := s.Delete(context.Background(), "1")
+		assert.NoError(t, err)
+	})
+	t.Run("Error", func(t *testing.T) {
+		mockSiteRepo := new(mocks.SiteRepositoryInterface)
+		mockSiteRepo.On("Delete", mock.Anything, mock.Anything).Return(errors.New("error")).Once()
+		s := site.NewService(mockSiteRepo, new(mocks.DeviceRepositoryInterface))
+		err := s.Delete(context.Background(), "1")
+		assert.Error(t, err)
+	})
+}
+
+func TestServiceGetDevices(t *testing.T) {
+	mockDevice := []domain.Device{
+		{
+			Name: "device",
+		},
+	}
+	t.Run("Success", func(t *testing.T) {
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetBySiteID", mock.Anything, "1", 1, 10).Return(mockDevice, nil)
+		s := site.NewService(new(mocks.SiteRepositoryInterface), mockDeviceRepo)
+		data, err := s.GetDevices(context.Background(), "1", 1, 10)
+		assert.Len(t, data, len(mockDevice))
+		assert.NoError(t, err)
+	})
+	t.Run("Error", func(t *testing.T) {
+		mockDeviceRepo := new(mocks.DeviceRepositoryInterface)
+		mockDeviceRepo.On("GetBySiteID", mock.Anything, "1", 1, 10).Return(nil, errors.New("error")).Once()
+		s := site.NewService(new(mocks.SiteRepositoryInterface), mockDeviceRepo)
+		data, err := s.GetDevices(context.Background(), "1", 1, 10)
+		assert.Nil(t, data)
+		assert.Error(t, err)
+	})
+}
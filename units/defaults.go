@@ -0,0 +1,105 @@
+package units
+
+import (
+	"github.com/anggi-susanto/mrt-go/domain"
+)
+
+// Unit system names accepted by the units= query parameter
+const (
+	SystemMetric   = "metric"
+	SystemImperial = "imperial"
+)
+
+// Molar mass ratios converting ammonium and nitrate to their nitrogen content
+const (
+	nitrogenPerAmmonium = 14.0067 / 18.0385
+	nitrogenPerNitrate  = 14.0067 / 62.0049
+)
+
+// unitAliases maps ASCII and alternative spellings to registry units
+var unitAliases = map[string]string{
+	"uS/cm": "µS/cm",
+	"μS/cm": "µS/cm",
+	"C":     "°C",
+	"degC":  "°C",
+	"Cel":   "°C",
+	"F":     "°F",
+	"degF":  "°F",
+	"ppm":   "mg/L",
+	"mg/l":  "mg/L",
+	"ug/L":  "µg/L",
+	"ug/l":  "µg/L",
+}
+
+// concentration are the conversions of a parameter measured in mg/L
+var concentration = map[string]Conversion{
+	"g/L":  {Factor: 1000},
+	"µg/L": {Factor: 0.001},
+}
+
+// traceConcentration are the conversions of a parameter measured in ppb
+var traceConcentration = map[string]Conversion{
+	"µg/L": {Factor: 1},
+	"mg/L": {Factor: 1000},
+}
+
+// DefaultParameters are the canonical units of the waste water parameters
+var DefaultParameters = []Parameter{
+	{Code: domain.ParamBOD, Canonical: "mg/L", Units: concentration},
+	{Code: domain.ParamCOD, Canonical: "mg/L", Units: concentration},
+	{Code: domain.ParamTOC, Canonical: "mg/L", Units: concentration},
+	{Code: domain.ParamDOC, Canonical: "mg/L", Units: concentration},
+	{Code: domain.ParamOpticalBrighteners, Canonical: "ppb", Units: traceConcentration},
+	{Code: domain.ParamAmmonium, Canonical: "mg/L NH4-N", Units: map[string]Conversion{
+		"mg/L NH4": {Factor: nitrogenPerAmmonium},
+		"mmol/L":   {Factor: 14.0067},
+	}},
+	{Code: domain.ParamDissolvedOxygen, Canonical: "mg/L", Units: map[string]Conversion{
+		"µg/L": {Factor: 0.001},
+	}},
+	{Code: domain.ParamNitrate, Canonical: "mg/L NO3-N", Units: map[string]Conversion{
+		"mg/L NO3": {Factor: nitrogenPerNitrate},
+		"mmol/L":   {Factor: 14.0067},
+	}},
+	{Code: domain.ParamECSalinityTDS, Canonical: "µS/cm", Units: map[string]Conversion{
+		"mS/cm": {Factor: 1000},
+		"S/m":   {Factor: 10000},
+	}},
+	{Code: domain.ParamPressure, Canonical: "kPa", Units: map[string]Conversion{
+		"Pa":   {Factor: 0.001},
+		"mbar": {Factor: 0.1},
+		"bar":  {Factor: 100},
+		"psi":  {Factor: 6.894757},
+	}},
+	{Code: domain.ParamORPRedox, Canonical: "mV", Units: map[string]Conversion{
+		"V": {Factor: 1000},
+	}},
+	{Code: domain.ParamTurbidity, Canonical: "NTU", Units: map[string]Conversion{
+		"FNU": {Factor: 1},
+	}},
+	{Code: domain.ParamChloride, Canonical: "mg/L", Units: concentration},
+	{Code: domain.ParamColiformsFecal, Canonical: "CFU/100mL", Units: map[string]Conversion{"CFU/mL": {Factor: 100}}},
+	{Code: domain.ParamColiformsEColi, Canonical: "CFU/100mL", Units: map[string]Conversion{"CFU/mL": {Factor: 100}}},
+	{Code: domain.ParamColiformsTotal, Canonical: "CFU/100mL", Units: map[string]Conversion{"CFU/mL": {Factor: 100}}},
+	{Code: domain.ParamCrudeOils, Canonical: "ppb", Units: traceConcentration},
+	{Code: domain.ParamPH, Canonical: "pH"},
+	{Code: domain.ParamTryptophan, Canonical: "ppb", Units: traceConcentration},
+	{Code: domain.ParamCDOM, Canonical: "ppb", Units: traceConcentration},
+	{Code: domain.ParamTemperature, Canonical: "°C", Units: map[string]Conversion{
+		"°F": {Factor: 5.0 / 9.0, Offset: -32 * 5.0 / 9.0},
+		"K":  {Factor: 1, Offset: -273.15},
+	}},
+	{Code: domain.ParamRefinedOils, Canonical: "ppb", Units: traceConcentration},
+}
+
+// DefaultSystems are the unit systems values can be requested in
+var DefaultSystems = []System{
+	{Name: SystemMetric},
+	{Name: SystemImperial, Units: map[string]string{
+		domain.ParamTemperature: "°F",
+		domain.ParamPressure:    "psi",
+	}},
+}
+
+// Default is the registry of the default parameters and systems
+var Default = NewRegistry(DefaultParameters, DefaultSystems)
@@ -0,0 +1,196 @@
+package units
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+)
+
+// Conversion is the linear conversion of a unit to the canonical unit of its parameter:
+// canonical = value*Factor + Offset
+type Conversion struct {
+	Factor float64
+	Offset float64
+}
+
+// ToCanonical converts a value in the unit to the canonical unit.
+func (c Conversion) ToCanonical(value float64) float64 {
+	return value*c.Factor + c.Offset
+}
+
+// FromCanonical converts a value in the canonical unit to the unit.
+func (c Conversion) FromCanonical(value float64) float64 {
+	return (value - c.Offset) / c.Factor
+}
+
+// Parameter is a parameter code with its canonical unit and the units it can be converted from
+type Parameter struct {
+	Code      string
+	Canonical string
+	Units     map[string]Conversion
+}
+
+// System maps parameter codes to the unit their values are returned in,
+// parameters missing from the system stay in their canonical unit
+type System struct {
+	Name  string
+	Units map[string]string
+}
+
+// Registry holds the known parameters and unit systems
+type Registry struct {
+	parameters map[string]Parameter
+	systems    map[string]System
+}
+
+// NewRegistry creates a Registry of the given parameters and unit systems.
+//
+// Parameters:
+// - parameters: the parameters, the canonical unit of each is added to its units.
+// - systems: the unit systems values can be requested in.
+//
+// Returns a pointer to a Registry.
+func NewRegistry(parameters []Parameter, systems []System) *Registry {
+	r := &Registry{
+		parameters: make(map[string]Parameter, len(parameters)),
+		systems:    make(map[string]System, len(systems)),
+	}
+	for _, parameter := range parameters {
+		conversions := map[string]Conversion{parameter.Canonical: {Factor: 1}}
+		for unit, conversion := range parameter.Units {
+			conversions[unit] = conversion
+		}
+		parameter.Units = conversions
+		r.parameters[parameter.Code] = parameter
+	}
+	for _, system := range systems {
+		r.systems[system.Name] = system
+	}
+	return r
+}
+
+// Canonical returns the canonical unit of a parameter and whether the parameter is registered.
+func (r *Registry) Canonical(parameter string) (string, bool) {
+	p, ok := r.parameters[parameter]
+	return p.Canonical, ok
+}
+
+// ToCanonical converts a value of a parameter from the unit to the canonical unit.
+//
+// Values of unregistered parameters and values without unit are returned unchanged.
+//
+// Returns the converted value, its unit and an error wrapping domain.ErrUnknownUnit
+// when the parameter cannot be converted from the unit.
+func (r *Registry) ToCanonical(parameter, unit string, value float64) (float64, string, error) {
+	p, ok := r.parameters[parameter]
+	if !ok || unit == "" {
+		return value, unit, nil
+	}
+	conversion, ok := p.Units[normalizeUnit(unit)]
+	if !ok {
+		return 0, "", fmt.Errorf("%w: %s in %s", domain.ErrUnknownUnit, parameter, unit)
+	}
+	return conversion.ToCanonical(value), p.Canonical, nil
+}
+
+// Convert converts a value of a parameter between two of its units.
+func (r *Registry) Convert(parameter, from, to string, value float64) (float64, error) {
+	canonical, _, err := r.ToCanonical(parameter, from, value)
+	if err != nil {
+		return 0, err
+	}
+	p, ok := r.parameters[parameter]
+	if !ok || to == "" {
+		return value, nil
+	}
+	conversion, ok := p.Units[normalizeUnit(to)]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s in %s", domain.ErrUnknownUnit, parameter, to)
+	}
+	return conversion.FromCanonical(canonical), nil
+}
+
+// Normalize converts the value of a measurement to the canonical unit of its parameter.
+//
+// Measurements without unit are assumed to be in the canonical unit already.
+func (r *Registry) Normalize(m *domain.Measurement) error {
+	value, unit, err := r.ToCanonical(m.Parameter, m.Unit, m.Value)
+	if err != nil {
+		return err
+	}
+	if unit == "" {
+		unit, _ = r.Canonical(m.Parameter)
+	}
+	m.Value = value
+	m.Unit = unit
+	return nil
+}
+
+// System returns the unit system with the given name.
+//
+// Returns an error wrapping domain.ErrUnknownUnitSystem when there is no such system.
+func (r *Registry) System(name string) (System, error) {
+	system, ok := r.systems[name]
+	if !ok {
+		return System{}, fmt.Errorf("%w: %s", domain.ErrUnknownUnitSystem, name)
+	}
+	return system, nil
+}
+
+// ConvertMeasurements converts canonical measurement values to the units of the system.
+func (r *Registry) ConvertMeasurements(measurements []domain.Measurement, system System) error {
+	for i := range measurements {
+		m := &measurements[i]
+		unit, ok := system.Units[m.Parameter]
+		if !ok {
+			continue
+		}
+		value, err := r.Convert(m.Parameter, m.Unit, unit, m.Value)
+		if err != nil {
+			return err
+		}
+		m.Value = value
+		m.Unit = unit
+	}
+	return nil
+}
+
+// ConvertWasteWater converts canonical waste water values to the units of the system
+// and records the unit of every registered parameter in Units.
+func (r *Registry) ConvertWasteWater(w *domain.WasteWaterData, system System) error {
+	values := w.Values()
+	w.Units = map[string]string{}
+	for code, p := range r.parameters {
+		value, ok := values[code]
+		if !ok {
+			value, ok = w.Parameters[code]
+		}
+		if !ok {
+			continue
+		}
+		unit, ok := system.Units[code]
+		if !ok {
+			w.Units[code] = p.Canonical
+			continue
+		}
+		converted, err := r.Convert(code, p.Canonical, unit, value)
+		if err != nil {
+			return err
+		}
+		if !w.SetValue(code, converted) {
+			w.Parameters[code] = converted
+		}
+		w.Units[code] = unit
+	}
+	return nil
+}
+
+// normalizeUnit maps the common spellings of a unit to the one used by the registry.
+func normalizeUnit(unit string) string {
+	unit = strings.TrimSpace(unit)
+	if alias, ok := unitAliases[unit]; ok {
+		return alias
+	}
+	return unit
+}
@@ -0,0 +1,59 @@
+package units_test
+
+import (
+	"testing"
+
+	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/units"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryNormalize(t *testing.T) {
+	tests := []struct {
+		name      string
+		in        domain.Measurement
+		wantValue float64
+		wantUnit  string
+	}{
+		{"Fahrenheit", domain.Measurement{Parameter: domain.ParamTemperature, Value: 68, Unit: "°F"}, 20, "°C"},
+		{"Fahrenheit alias", domain.Measurement{Parameter: domain.ParamTemperature, Value: 212, Unit: "degF"}, 100, "°C"},
+		{"Kelvin", domain.Measurement{Parameter: domain.ParamTemperature, Value: 273.15, Unit: "K"}, 0, "°C"},
+		{"Millisiemens", domain.Measurement{Parameter: domain.ParamECSalinityTDS, Value: 1.5, Unit: "mS/cm"}, 1500, "µS/cm"},
+		{"Microsiemens ASCII", domain.Measurement{Parameter: domain.ParamECSalinityTDS, Value: 800, Unit: "uS/cm"}, 800, "µS/cm"},
+		{"Ammonium as NH4", domain.Measurement{Parameter: domain.ParamAmmonium, Value: 18.0385, Unit: "mg/L NH4"}, 14.0067, "mg/L NH4-N"},
+		{"Ammonium as NH4-N", domain.Measurement{Parameter: domain.ParamAmmonium, Value: 2, Unit: "mg/L NH4-N"}, 2, "mg/L NH4-N"},
+		{"No unit", domain.Measurement{Parameter: domain.ParamPH, Value: 7}, 7, "pH"},
+		{"Unregistered parameter", domain.Measurement{Parameter: "TSS", Value: 35, Unit: "mg/L"}, 35, "mg/L"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := tt.in
+			err := units.Default.Normalize(&m)
+			assert.NoError(t, err)
+			assert.InDelta(t, tt.wantValue, m.Value, 1e-9)
+			assert.Equal(t, tt.wantUnit, m.Unit)
+		})
+	}
+	t.Run("Unknown unit", func(t *testing.T) {
+		m := domain.Measurement{Parameter: domain.ParamTemperature, Value: 10, Unit: "°R"}
+		err := units.Default.Normalize(&m)
+		assert.ErrorIs(t, err, domain.ErrUnknownUnit)
+	})
+}
+
+func TestRegistryConvertWasteWater(t *testing.T) {
+	imperial, err := units.Default.System(units.SystemImperial)
+	assert.NoError(t, err)
+
+	w := domain.WasteWaterData{Temperature: 100, Pressure: 6.894757, PH: 7}
+	err = units.Default.ConvertWasteWater(&w, imperial)
+	assert.NoError(t, err)
+	assert.InDelta(t, 212, w.Temperature, 1e-9)
+	assert.InDelta(t, 1, w.Pressure, 1e-9)
+	assert.Equal(t, float64(7), w.PH)
+	assert.Equal(t, "°F", w.Units[domain.ParamTemperature])
+	assert.Equal(t, "pH", w.Units[domain.ParamPH])
+
+	_, err = units.Default.System("cgs")
+	assert.ErrorIs(t, err, domain.ErrUnknownUnitSystem)
+}
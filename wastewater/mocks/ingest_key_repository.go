@@ -0,0 +1,77 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IngestKeyRepositoryInterface is an autogenerated mock type for the IngestKeyRepositoryInterface type
+type IngestKeyRepositoryInterface struct {
+	mock.Mock
+}
+
+// Claim provides a mock function with given fields: ctx, key, readingID
+func (_m *IngestKeyRepositoryInterface) Claim(ctx context.Context, key string, readingID primitive.ObjectID) (primitive.ObjectID, error) {
+	ret := _m.Called(ctx, key, readingID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Claim")
+	}
+
+	var r0 primitive.ObjectID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, primitive.ObjectID) (primitive.ObjectID, error)); ok {
+		return rf(ctx, key, readingID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, primitive.ObjectID) primitive.ObjectID); ok {
+		r0 = rf(ctx, key, readingID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(primitive.ObjectID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, primitive.ObjectID) error); ok {
+		r1 = rf(ctx, key, readingID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Release provides a mock function with given fields: ctx, key
+func (_m *IngestKeyRepositoryInterface) Release(ctx context.Context, key string) error {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Release")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewIngestKeyRepositoryInterface creates a new instance of IngestKeyRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIngestKeyRepositoryInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IngestKeyRepositoryInterface {
+	mock := &IngestKeyRepositoryInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
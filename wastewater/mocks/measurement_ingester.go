@@ -0,0 +1,91 @@
+// Code generated by mockery v2.53.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/anggi-susanto/mrt-go/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MeasurementIngesterInterface is an autogenerated mock type for the MeasurementIngesterInterface type
+type MeasurementIngesterInterface struct {
+	mock.Mock
+}
+
+// Ingest provides a mock function with given fields: ctx, measurements
+func (_m *MeasurementIngesterInterface) Ingest(ctx context.Context, measurements []domain.Measurement) ([]domain.Measurement, error) {
+	ret := _m.Called(ctx, measurements)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Ingest")
+	}
+
+	var r0 []domain.Measurement
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.Measurement) ([]domain.Measurement, error)); ok {
+		return rf(ctx, measurements)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.Measurement) []domain.Measurement); ok {
+		r0 = rf(ctx, measurements)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Measurement)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []domain.Measurement) error); ok {
+		r1 = rf(ctx, measurements)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReplaceReading provides a mock function with given fields: ctx, readingID, measurements
+func (_m *MeasurementIngesterInterface) ReplaceReading(ctx context.Context, readingID primitive.ObjectID, measurements []domain.Measurement) ([]domain.Measurement, error) {
+	ret := _m.Called(ctx, readingID, measurements)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplaceReading")
+	}
+
+	var r0 []domain.Measurement
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID, []domain.Measurement) ([]domain.Measurement, error)); ok {
+		return rf(ctx, readingID, measurements)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID, []domain.Measurement) []domain.Measurement); ok {
+		r0 = rf(ctx, readingID, measurements)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Measurement)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, primitive.ObjectID, []domain.Measurement) error); ok {
+		r1 = rf(ctx, readingID, measurements)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewMeasurementIngesterInterface creates a new instance of MeasurementIngesterInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMeasurementIngesterInterface(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MeasurementIngesterInterface {
+	mock := &MeasurementIngesterInterface{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -2,44 +2,110 @@ package wastewater
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/anggi-susanto/mrt-go/domain"
+	"github.com/anggi-susanto/mrt-go/internal/logging"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
 )
 
-// WasteWaterRepositoryInterface is the interface that wraps the Create, GetAll, GetByID, Update, and Delete methods.
+// tracer starts the spans of the service methods
+var tracer = otel.Tracer("github.com/anggi-susanto/mrt-go/wastewater")
+
+// WasteWaterRepositoryInterface is the interface that wraps the GetAll, GetByID, and Delete methods of the waste water view.
 type WasteWaterRepositoryInterface interface {
-	Create(ctx context.Context, w *domain.WastewaterDataRequest) error
 	GetAll(ctx context.Context, page, limit int) ([]domain.WasteWaterData, error)
 	GetByID(ctx context.Context, id string) (*domain.WasteWaterData, error)
-	Update(ctx context.Context, w *domain.WasteWaterData) error
 	Delete(ctx context.Context, id string) error
 }
 
+// MeasurementIngesterInterface is the interface that wraps the Ingest and ReplaceReading methods.
+type MeasurementIngesterInterface interface {
+	Ingest(ctx context.Context, measurements []domain.Measurement) ([]domain.Measurement, error)
+	ReplaceReading(ctx context.Context, readingID primitive.ObjectID, measurements []domain.Measurement) ([]domain.Measurement, error)
+}
+
+// IngestKeyRepositoryInterface is the interface that wraps the Claim and Release methods of the dedup keys.
+type IngestKeyRepositoryInterface interface {
+	Claim(ctx context.Context, key string, readingID primitive.ObjectID) (primitive.ObjectID, error)
+	Release(ctx context.Context, key string) error
+}
+
 // Service is the interface that wraps the Create, GetAll, GetByID, Update, and Delete methods.
+//
+// Waste water data is stored as measurements, the service pivots between both shapes.
 type Service struct {
 	wasteWaterRepository WasteWaterRepositoryInterface
+	measurementIngester  MeasurementIngesterInterface
+	ingestKeys           IngestKeyRepositoryInterface
 }
 
-// NewService creates a new instance of the Service struct, initializing it with the provided WasteWaterRepositoryInterface.
+// NewService creates a new instance of the Service struct, initializing it with the provided dependencies.
 //
 // Parameters:
-// - wasteWaterRepository: The WasteWaterRepositoryInterface implementation used by the Service.
+// - wasteWaterRepository: The WasteWaterRepositoryInterface implementation reading the waste water view.
+// - measurementIngester: The MeasurementIngesterInterface implementation storing the measurements.
+// - ingestKeys: The IngestKeyRepositoryInterface implementation remembering the dedup keys of the readings.
 //
 // Returns:
 // - A pointer to the newly created Service instance.
-func NewService(wasteWaterRepository WasteWaterRepositoryInterface) *Service {
+func NewService(wasteWaterRepository WasteWaterRepositoryInterface, measurementIngester MeasurementIngesterInterface, ingestKeys IngestKeyRepositoryInterface) *Service {
 	return &Service{
 		wasteWaterRepository: wasteWaterRepository,
+		measurementIngester:  measurementIngester,
+		ingestKeys:           ingestKeys,
 	}
 }
 
 // Create creates a new waste water data record in the service.
 //
+// The reading is split into one measurement per parameter in the unit declared
+// in w.Units, w is updated with the ID of the reading and the parameters
+// flagged out of range.
+//
+// A reading with a dedup key already stored is not stored again, w gets the ID of
+// the stored reading and domain.ErrDuplicateReading is returned.
+//
 // ctx: The context.Context object for the request.
 // w: The waste water data to be created.
 // Returns an error if there was a problem creating the record.
 func (s *Service) Create(ctx context.Context, w *domain.WastewaterDataRequest) error {
-	return s.wasteWaterRepository.Create(ctx, w)
+	ctx, span := tracer.Start(ctx, "wastewater.Service.Create")
+	defer span.End()
+	// The lines logged while the reading is stored name its device
+	ctx = logging.WithFields(ctx, logrus.Fields{logging.FieldDeviceID: w.DeviceID.Hex()})
+	readingID := primitive.NewObjectID()
+	if w.DedupKey != "" {
+		existing, err := s.ingestKeys.Claim(ctx, w.DedupKey, readingID)
+		if err != nil {
+			return err
+		}
+		if !existing.IsZero() {
+			w.ID = existing
+			return domain.ErrDuplicateReading
+		}
+	}
+	measurements := withUnits(domain.NewMeasurements(readingID, w.DeviceID, w.Timestamp, w.Values(), w.Parameters), w.Units)
+	if len(measurements) == 0 {
+		if w.DedupKey != "" {
+			_ = s.ingestKeys.Release(ctx, w.DedupKey)
+		}
+		return fmt.Errorf("%w: the reading has no parameter", domain.ErrInvalidMeasurement)
+	}
+	stored, err := s.measurementIngester.Ingest(ctx, measurements)
+	if err != nil {
+		// The reading was not stored, sending it again must not be taken for a duplicate
+		if w.DedupKey != "" {
+			_ = s.ingestKeys.Release(ctx, w.DedupKey)
+		}
+		return err
+	}
+	w.ID = readingID
+	w.Timestamp = stored[0].Timestamp
+	w.OutOfRange = outOfRange(stored)
+	return nil
 }
 
 // GetAll retrieves all waste water data with pagination.
@@ -47,6 +113,8 @@ func (s *Service) Create(ctx context.Context, w *domain.WastewaterDataRequest) e
 // ctx context.Context, page int, limit int
 // []domain.WasteWaterData, error
 func (s *Service) GetAll(ctx context.Context, page, limit int) ([]domain.WasteWaterData, error) {
+	ctx, span := tracer.Start(ctx, "wastewater.Service.GetAll")
+	defer span.End()
 	return s.wasteWaterRepository.GetAll(ctx, page, limit)
 }
 
@@ -56,6 +124,8 @@ func (s *Service) GetAll(ctx context.Context, page, limit int) ([]domain.WasteWa
 // id - string representing the ID of the data.
 // Returns a pointer to domain.WasteWaterData and an error.
 func (s *Service) GetByID(ctx context.Context, id string) (*domain.WasteWaterData, error) {
+	ctx, span := tracer.Start(ctx, "wastewater.Service.GetByID")
+	defer span.End()
 	return s.wasteWaterRepository.GetByID(ctx, id)
 }
 
@@ -65,6 +135,8 @@ func (s *Service) GetByID(ctx context.Context, id string) (*domain.WasteWaterDat
 // id - string representing the ID of the data to be deleted.
 // Returns an error if there was a problem deleting the data.
 func (s *Service) Delete(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "wastewater.Service.Delete")
+	defer span.End()
 	return s.wasteWaterRepository.Delete(ctx, id)
 }
 
@@ -74,5 +146,39 @@ func (s *Service) Delete(ctx context.Context, id string) error {
 // w - pointer to domain.WasteWaterData representing the data to be updated.
 // Returns an error if there was a problem updating the data.
 func (s *Service) Update(ctx context.Context, w *domain.WasteWaterData) error {
-	return s.wasteWaterRepository.Update(ctx, w)
+	ctx, span := tracer.Start(ctx, "wastewater.Service.Update")
+	defer span.End()
+	if w.ID.IsZero() {
+		return fmt.Errorf("%w: reading id is required", domain.ErrInvalidMeasurement)
+	}
+	measurements := withUnits(domain.NewMeasurements(w.ID, w.DeviceID, w.Timestamp, w.Values(), w.Parameters), w.Units)
+	if len(measurements) == 0 {
+		return fmt.Errorf("%w: the reading has no parameter", domain.ErrInvalidMeasurement)
+	}
+	stored, err := s.measurementIngester.ReplaceReading(ctx, w.ID, measurements)
+	if err != nil {
+		return err
+	}
+	w.Timestamp = stored[0].Timestamp
+	w.OutOfRange = outOfRange(stored)
+	return nil
+}
+
+// outOfRange lists the parameters of the measurements flagged out of range.
+func outOfRange(measurements []domain.Measurement) []string {
+	var parameters []string
+	for _, m := range measurements {
+		if m.OutOfRange {
+			parameters = append(parameters, m.Parameter)
+		}
+	}
+	return parameters
+}
+
+// withUnits sets the declared unit of each measurement, keyed by parameter code.
+func withUnits(measurements []domain.Measurement, units map[string]string) []domain.Measurement {
+	for i := range measurements {
+		measurements[i].Unit = units[measurements[i].Parameter]
+	}
+	return measurements
 }
@@ -2,6 +2,7 @@ package wastewater_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"testing"
 
@@ -10,6 +11,8 @@ import (
 	"github.com/anggi-susanto/mrt-go/wastewater/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func TestServiceCreate(t *testing.T) {
@@ -18,17 +21,125 @@ func TestServiceCreate(t *testing.T) {
 	}
 	t.Run("Success", func(t *testing.T) {
 		mockWasteWaterRepo := new(mocks.WasteWaterRepositoryInterface)
-		mockWasteWaterRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
-		s := wastewater.NewService(mockWasteWaterRepo)
+		mockIngester := new(mocks.MeasurementIngesterInterface)
+		mockIngester.On("Ingest", mock.Anything, mock.MatchedBy(func(ms []domain.Measurement) bool {
+			return len(ms) == len(domain.WasteWaterParameters) && ms[0].Parameter == domain.ParamBOD && ms[0].Value == 10
+		})).Return(func(_ context.Context, ms []domain.Measurement) []domain.Measurement { return ms }, nil)
+		s := wastewater.NewService(mockWasteWaterRepo, mockIngester, nil)
 		err := s.Create(context.Background(), &mockWasteWater)
 		assert.NoError(t, err)
+		assert.False(t, mockWasteWater.ID.IsZero())
+		mockIngester.AssertExpectations(t)
+	})
+	t.Run("Partial reading", func(t *testing.T) {
+		mockIngester := new(mocks.MeasurementIngesterInterface)
+		mockIngester.On("Ingest", mock.Anything, mock.MatchedBy(func(ms []domain.Measurement) bool {
+			return len(ms) == 2 && ms[0].Parameter == domain.ParamPH && ms[1].Parameter == domain.ParamTemperature
+		})).Return(func(_ context.Context, ms []domain.Measurement) []domain.Measurement { return ms }, nil)
+		s := wastewater.NewService(new(mocks.WasteWaterRepositoryInterface), mockIngester, nil)
+		w := &domain.WastewaterDataRequest{}
+		w.SetValue(domain.ParamTemperature, 18)
+		w.SetValue(domain.ParamPH, 0)
+		err := s.Create(context.Background(), w)
+		assert.NoError(t, err)
+		mockIngester.AssertExpectations(t)
+	})
+	t.Run("Decoded reading", func(t *testing.T) {
+		mockIngester := new(mocks.MeasurementIngesterInterface)
+		mockIngester.On("Ingest", mock.Anything, mock.MatchedBy(func(ms []domain.Measurement) bool {
+			return len(ms) == 2 && ms[0].Parameter == domain.ParamColiformsFecal && ms[1].Parameter == domain.ParamPH
+		})).Return(func(_ context.Context, ms []domain.Measurement) []domain.Measurement { return ms }, nil)
+		s := wastewater.NewService(new(mocks.WasteWaterRepositoryInterface), mockIngester, nil)
+		w := &domain.WastewaterDataRequest{}
+		require.NoError(t, json.Unmarshal([]byte(`{"pH":7.1,"COD":null,"Coliforms":{"fecal":3}}`), w))
+		err := s.Create(context.Background(), w)
+		assert.NoError(t, err)
+		mockIngester.AssertExpectations(t)
+
+		// The absent parameters stay absent when the reading is sent on, e.g. by the edge agent
+		data, err := json.Marshal(w)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), `"COD"`)
+		assert.Contains(t, string(data), `"Coliforms":{"fecal":3}`)
+	})
+	t.Run("No parameter", func(t *testing.T) {
+		mockIngester := new(mocks.MeasurementIngesterInterface)
+		s := wastewater.NewService(new(mocks.WasteWaterRepositoryInterface), mockIngester, nil)
+		w := &domain.WastewaterDataRequest{}
+		require.NoError(t, json.Unmarshal([]byte(`{"device_id":"65a000000000000000000001"}`), w))
+		err := s.Create(context.Background(), w)
+		assert.ErrorIs(t, err, domain.ErrInvalidMeasurement)
+		mockIngester.AssertNotCalled(t, "Ingest")
+	})
+	t.Run("Extra parameters", func(t *testing.T) {
+		mockIngester := new(mocks.MeasurementIngesterInterface)
+		mockIngester.On("Ingest", mock.Anything, mock.MatchedBy(func(ms []domain.Measurement) bool {
+			last := ms[len(ms)-1]
+			return len(ms) == len(domain.WasteWaterParameters)+1 && last.Parameter == "TSS" && last.Value == 35
+		})).Return(func(_ context.Context, ms []domain.Measurement) []domain.Measurement { return ms }, nil)
+		s := wastewater.NewService(new(mocks.WasteWaterRepositoryInterface), mockIngester, nil)
+		err := s.Create(context.Background(), &domain.WastewaterDataRequest{Parameters: map[string]float64{"TSS": 35}})
+		assert.NoError(t, err)
+		mockIngester.AssertExpectations(t)
+	})
+	t.Run("Declared units", func(t *testing.T) {
+		mockIngester := new(mocks.MeasurementIngesterInterface)
+		mockIngester.On("Ingest", mock.Anything, mock.MatchedBy(func(ms []domain.Measurement) bool {
+			for _, m := range ms {
+				if m.Parameter == domain.ParamTemperature && m.Unit != "°F" || m.Parameter == domain.ParamPH && m.Unit != "" {
+					return false
+				}
+			}
+			return true
+		})).Return(func(_ context.Context, ms []domain.Measurement) []domain.Measurement { return ms }, nil)
+		s := wastewater.NewService(new(mocks.WasteWaterRepositoryInterface), mockIngester, nil)
+		err := s.Create(context.Background(), &domain.WastewaterDataRequest{Temperature: 68, Units: map[string]string{domain.ParamTemperature: "°F"}})
+		assert.NoError(t, err)
+		mockIngester.AssertExpectations(t)
 	})
 	t.Run("Error", func(t *testing.T) {
-		mockWasteWaterRepo := new(mocks.WasteWaterRepositoryInterface)
-		mockWasteWaterRepo.On("Create", mock.Anything, mock.Anything).Return(errors.New("error")).Once()
-		s := wastewater.NewService(mockWasteWaterRepo)
-		err := s.Create(context.Background(), &mockWasteWater)
+		mockIngester := new(mocks.MeasurementIngesterInterface)
+		mockIngester.On("Ingest", mock.Anything, mock.Anything).Return(nil, errors.New("error")).Once()
+		s := wastewater.NewService(new(mocks.WasteWaterRepositoryInterface), mockIngester, nil)
+		err := s.Create(context.Background(), &domain.WastewaterDataRequest{BOD: 10})
+		assert.Error(t, err)
+	})
+	t.Run("Out of range", func(t *testing.T) {
+		mockIngester := new(mocks.MeasurementIngesterInterface)
+		mockIngester.On("Ingest", mock.Anything, mock.Anything).Return(func(_ context.Context, ms []domain.Measurement) []domain.Measurement {
+			for i := range ms {
+				ms[i].OutOfRange = ms[i].Parameter == domain.ParamPH
+			}
+			return ms
+		}, nil)
+		s := wastewater.NewService(new(mocks.WasteWaterRepositoryInterface), mockIngester, nil)
+		w := &domain.WastewaterDataRequest{PH: 15}
+		err := s.Create(context.Background(), w)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{domain.ParamPH}, w.OutOfRange)
+	})
+	t.Run("Duplicate", func(t *testing.T) {
+		existing := primitive.NewObjectID()
+		mockIngestKeys := new(mocks.IngestKeyRepositoryInterface)
+		mockIngestKeys.On("Claim", mock.Anything, "edge-1:42", mock.Anything).Return(existing, nil)
+		mockIngester := new(mocks.MeasurementIngesterInterface)
+		s := wastewater.NewService(new(mocks.WasteWaterRepositoryInterface), mockIngester, mockIngestKeys)
+		w := &domain.WastewaterDataRequest{BOD: 10, DedupKey: "edge-1:42"}
+		err := s.Create(context.Background(), w)
+		assert.ErrorIs(t, err, domain.ErrDuplicateReading)
+		assert.Equal(t, existing, w.ID)
+		mockIngester.AssertNotCalled(t, "Ingest", mock.Anything, mock.Anything)
+	})
+	t.Run("Dedup key released on error", func(t *testing.T) {
+		mockIngestKeys := new(mocks.IngestKeyRepositoryInterface)
+		mockIngestKeys.On("Claim", mock.Anything, "edge-1:43", mock.Anything).Return(primitive.NilObjectID, nil)
+		mockIngestKeys.On("Release", mock.Anything, "edge-1:43").Return(nil)
+		mockIngester := new(mocks.MeasurementIngesterInterface)
+		mockIngester.On("Ingest", mock.Anything, mock.Anything).Return(nil, errors.New("error"))
+		s := wastewater.NewService(new(mocks.WasteWaterRepositoryInterface), mockIngester, mockIngestKeys)
+		err := s.Create(context.Background(), &domain.WastewaterDataRequest{BOD: 10, DedupKey: "edge-1:43"})
 		assert.Error(t, err)
+		mockIngestKeys.AssertExpectations(t)
 	})
 }
 
@@ -41,7 +152,7 @@ func TestServiceGetAll(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		mockWasteWaterRepo := new(mocks.WasteWaterRepositoryInterface)
 		mockWasteWaterRepo.On("GetAll", mock.Anything, mock.Anything, mock.Anything).Return(mockWasteWater, nil)
-		s := wastewater.NewService(mockWasteWaterRepo)
+		s := wastewater.NewService(mockWasteWaterRepo, new(mocks.MeasurementIngesterInterface), nil)
 		data, err := s.GetAll(context.Background(), 1, 10)
 		assert.Len(t, data, len(mockWasteWater))
 		assert.NoError(t, err)
@@ -49,7 +160,7 @@ func TestServiceGetAll(t *testing.T) {
 	t.Run("Error", func(t *testing.T) {
 		mockWasteWaterRepo := new(mocks.WasteWaterRepositoryInterface)
 		mockWasteWaterRepo.On("GetAll", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("error")).Once()
-		s := wastewater.NewService(mockWasteWaterRepo)
+		s := wastewater.NewService(mockWasteWaterRepo, new(mocks.MeasurementIngesterInterface), nil)
 		data, err := s.GetAll(context.Background(), 1, 10)
 		assert.Nil(t, data)
 		assert.Error(t, err)
@@ -58,22 +169,32 @@ func TestServiceGetAll(t *testing.T) {
 
 func TestServiceUpdate(t *testing.T) {
 	mockWasteWater := domain.WasteWaterData{
+		ID:  primitive.NewObjectID(),
 		BOD: 10,
 	}
 	t.Run("Success", func(t *testing.T) {
-		mockWasteWaterRepo := new(mocks.WasteWaterRepositoryInterface)
-		mockWasteWaterRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
-		s := wastewater.NewService(mockWasteWaterRepo)
+		mockIngester := new(mocks.MeasurementIngesterInterface)
+		mockIngester.On("ReplaceReading", mock.Anything, mockWasteWater.ID, mock.Anything).
+			Return(func(_ context.Context, _ primitive.ObjectID, ms []domain.Measurement) []domain.Measurement { return ms }, nil)
+		s := wastewater.NewService(new(mocks.WasteWaterRepositoryInterface), mockIngester, nil)
 		err := s.Update(context.Background(), &mockWasteWater)
 		assert.NoError(t, err)
+		mockIngester.AssertExpectations(t)
 	})
 	t.Run("Error", func(t *testing.T) {
-		mockWasteWaterRepo := new(mocks.WasteWaterRepositoryInterface)
-		mockWasteWaterRepo.On("Update", mock.Anything, mock.Anything).Return(errors.New("error")).Once()
-		s := wastewater.NewService(mockWasteWaterRepo)
+		mockIngester := new(mocks.MeasurementIngesterInterface)
+		mockIngester.On("ReplaceReading", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("error")).Once()
+		s := wastewater.NewService(new(mocks.WasteWaterRepositoryInterface), mockIngester, nil)
 		err := s.Update(context.Background(), &mockWasteWater)
 		assert.Error(t, err)
 	})
+	t.Run("Missing ID", func(t *testing.T) {
+		mockIngester := new(mocks.MeasurementIngesterInterface)
+		s := wastewater.NewService(new(mocks.WasteWaterRepositoryInterface), mockIngester, nil)
+		err := s.Update(context.Background(), &domain.WasteWaterData{BOD: 10})
+		assert.ErrorIs(t, err, domain.ErrInvalidMeasurement)
+		mockIngester.AssertNotCalled(t, "ReplaceReading")
+	})
 }
 
 func TestServiceGetByID(t *testing.T) {
@@ -83,7 +204,7 @@ func TestServiceGetByID(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		mockWasteWaterRepo := new(mocks.WasteWaterRepositoryInterface)
 		mockWasteWaterRepo.On("GetByID", mock.Anything, mock.Anything, mock.Anything).Return(&mockWasteWater, nil)
-		s := wastewater.NewService(mockWasteWaterRepo)
+		s := wastewater.NewService(mockWasteWaterRepo, new(mocks.MeasurementIngesterInterface), nil)
 		data, err := s.GetByID(context.Background(), "1")
 		assert.Equal(t, data.BOD, mockWasteWater.BOD)
 		assert.NoError(t, err)
@@ -91,7 +212,7 @@ func TestServiceGetByID(t *testing.T) {
 	t.Run("Error", func(t *testing.T) {
 		mockWasteWaterRepo := new(mocks.WasteWaterRepositoryInterface)
 		mockWasteWaterRepo.On("GetByID", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("error")).Once()
-		s := wastewater.NewService(mockWasteWaterRepo)
+		s := wastewater.NewService(mockWasteWaterRepo, new(mocks.MeasurementIngesterInterface), nil)
 		data, err := s.GetByID(context.Background(), "1")
 		assert.Nil(t, data)
 		assert.Error(t, err)
@@ -102,14 +223,14 @@ func TestServiceDelete(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		mockWasteWaterRepo := new(mocks.WasteWaterRepositoryInterface)
 		mockWasteWaterRepo.On("Delete", mock.Anything, mock.Anything, mock.Anything).Return(nil)
-		s := wastewater.NewService(mockWasteWaterRepo)
+		s := wastewater.NewService(mockWasteWaterRepo, new(mocks.MeasurementIngesterInterface), nil)
 		err := s.Delete(context.Background(), "1")
 		assert.NoError(t, err)
 	})
 	t.Run("Error", func(t *testing.T) {
 		mockWasteWaterRepo := new(mocks.WasteWaterRepositoryInterface)
 		mockWasteWaterRepo.On("Delete", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("error")).Once()
-		s := wastewater.NewService(mockWasteWaterRepo)
+		s := wastewater.NewService(mockWasteWaterRepo, new(mocks.MeasurementIngesterInterface), nil)
 		err := s.Delete(context.Background(), "1")
 		assert.Error(t, err)
 	})